@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// EmailTokenPurpose is the action that an email token allows, so that a token sent for one action cannot be used
+// for another.
+type EmailTokenPurpose string
+
+// The purposes of email tokens.
+const (
+	// VerifyEmailPurpose tokens confirm that the user owns their email address.
+	VerifyEmailPurpose EmailTokenPurpose = "verify-email"
+
+	// ResetPasswordPurpose tokens allow the user to choose a new password.
+	ResetPasswordPurpose EmailTokenPurpose = "reset-password"
+)
+
+// EmailVerificationLifetime is how long an email verification token remains valid.
+const EmailVerificationLifetime = 48 * time.Hour
+
+// PasswordResetLifetime is how long a password reset token remains valid.
+const PasswordResetLifetime = time.Hour
+
+// EmailToken contains the claims of a token that is emailed to a user. The nonce is stored with the user when the
+// token is created and removed when the token is used, which makes the token single-use and lets a newer token
+// replace an older one.
+type EmailToken struct {
+	Purpose EmailTokenPurpose
+	Email   string
+	Nonce   string
+	Expires time.Time
+}
+
+// GenerateEmailToken returns a token with the given purpose for the user with the given email, which expires after
+// lifetime, along with its random nonce. The token has the following format
+//		base64(keyID\npurpose\nexpires\nnonce\nemail).base64(mac)
+// where mac is the SHA256 HMAC of the first part under the newest signing key, so that it can be safely sent in a
+// link. If an error occurs, GenerateEmailToken returns empty strings along with the error.
+func GenerateEmailToken(purpose EmailTokenPurpose, email string, lifetime time.Duration) (token string, nonce string, err error) {
+	b := make([]byte, 16)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Failed to generate nonce")
+	}
+	nonce = hex.EncodeToString(b)
+
+	expires := strconv.FormatInt(now().Add(lifetime).Unix(), 10)
+	token, err = signFields(string(purpose), expires, nonce, email)
+	if err != nil {
+		return "", "", err
+	}
+	return token, nonce, nil
+}
+
+// VerifyEmailToken checks that token was generated by GenerateEmailToken with the given purpose, that its mac is
+// correct under the active signing key it names and that it has not expired, and returns its claims. VerifyEmailToken
+// does not check whether the token has already been used, since only the database knows the current nonce.
+func VerifyEmailToken(token string, purpose EmailTokenPurpose) (*EmailToken, error) {
+	invalid := errors.NewClient("Invalid or expired token")
+
+	fields, err := verifyFields(token, 4)
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil || fields[0] != string(purpose) || fields[2] == "" || fields[3] == "" {
+		return nil, invalid
+	}
+
+	expires, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || !now().Before(time.Unix(expires, 0)) {
+		return nil, invalid
+	}
+	return &EmailToken{
+		Purpose: purpose,
+		Email:   fields[3],
+		Nonce:   fields[2],
+		Expires: time.Unix(expires, 0).UTC(),
+	}, nil
+}
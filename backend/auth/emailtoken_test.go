@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func TestEmailToken(t *testing.T) {
+	current := currentTime
+	now = func() time.Time { return current }
+	defer func() {
+		now = time.Now
+	}()
+	defer useKeys(testKey("k1", 1))()
+
+	token, nonce, err := GenerateEmailToken(ResetPasswordPurpose, "test@example.com", time.Hour)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if len(nonce) != 32 || strings.Contains(token, nonce) {
+		t.Errorf("Got nonce %s in token %s; want 32 hex characters encoded in the token", nonce, token)
+	}
+
+	claims, err := VerifyEmailToken(token, ResetPasswordPurpose)
+	want := EmailToken{Purpose: ResetPasswordPurpose, Email: "test@example.com", Nonce: nonce, Expires: currentTime.Add(time.Hour)}
+	if err != nil || claims == nil || *claims != want {
+		t.Errorf("Got claims %+v and err %v; want %+v", claims, err, want)
+	}
+
+	// Tokens of another purpose are rejected
+	if _, err := VerifyEmailToken(token, VerifyEmailPurpose); !errors.Equal(err, errors.NewClient("Invalid or expired token")) {
+		t.Errorf("Got err %v for a token of another purpose; want `Invalid or expired token`", err)
+	}
+
+	// Tokens signed by an older key remain valid after a rotation
+	restore := useKeys(testKey("k1", 1), testKey("k2", 2))
+	if _, err := VerifyEmailToken(token, ResetPasswordPurpose); err != nil {
+		t.Errorf("Got err %v after adding a key; want the token to be valid", err)
+	}
+	restore()
+
+	// Tokens expire
+	current = currentTime.Add(time.Hour)
+	if _, err := VerifyEmailToken(token, ResetPasswordPurpose); !errors.Equal(err, errors.NewClient("Invalid or expired token")) {
+		t.Errorf("Got err %v for an expired token; want `Invalid or expired token`", err)
+	}
+}
+
+func TestInvalidEmailToken(t *testing.T) {
+	now = func() time.Time { return currentTime }
+	defer func() {
+		now = time.Now
+	}()
+	defer useKeys(testKey("k1", 1))()
+
+	token, _, err := GenerateEmailToken(VerifyEmailPurpose, "test@example.com", time.Hour)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	parts := strings.Split(token, ".")
+	claims, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	encode := func(claims string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(claims))
+	}
+
+	tokens := map[string]string{
+		"Empty":          "",
+		"MissingMAC":     parts[0],
+		"ExtraPart":      token + ".extra",
+		"InvalidBase64":  "!!!." + parts[1],
+		"ChangedEmail":   encode(strings.Replace(string(claims), "test@example.com", "other@example.com", 1)) + "." + parts[1],
+		"ChangedExpiry":  encode(strings.Replace(string(claims), "\n1588", "\n1999", 1)) + "." + parts[1],
+		"UnknownKey":     encode(strings.Replace(string(claims), "k1\n", "k2\n", 1)) + "." + parts[1],
+		"MissingClaims":  encode("k1\nverify-email\n1588339800") + "." + parts[1],
+		"TruncatedMAC":   parts[0] + "." + parts[1][:10],
+		"CookieAsToken":  "test@example.com#token#k1#mac",
+		"AccessToken":    testAccessToken,
+		"SwappedPurpose": encode(strings.Replace(string(claims), "verify-email", "reset-password", 1)) + "." + parts[1],
+	}
+	for name, token := range tokens {
+		t.Run(name, func(t *testing.T) {
+			purpose := VerifyEmailPurpose
+			if name == "SwappedPurpose" {
+				purpose = ResetPasswordPurpose
+			}
+			claims, err := VerifyEmailToken(token, purpose)
+			if claims != nil || !errors.Equal(err, errors.NewClient("Invalid or expired token")) {
+				t.Errorf("Got claims %+v and err %v; want `Invalid or expired token`", claims, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// minKeySize is the minimum length in bytes of a signing key, which matches the output size of SHA256.
+const minKeySize = 32
+
+// keyCacheDuration is how long loaded keys are reused before they are loaded again, so that rotated keys
+// reach warm Lambda containers without loading the keys on every request.
+const keyCacheDuration = 5 * time.Minute
+
+// keyIDPattern matches valid key IDs. Key IDs are embedded in cookies, so they cannot contain `#`.
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// Key is a secret used to sign session cookies. Its ID is embedded in every cookie it signs, so the cookie
+// can still be verified after newer keys are added.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// KeySource loads the active signing keys, ordered from oldest to newest. Cookies are signed with the newest
+// key and verified with any active key, so a key is rotated by appending a new key and removing the old one
+// once the sessions of the cookies it signed have expired.
+type KeySource interface {
+	Keys() ([]Key, error)
+}
+
+// parseKeys parses a list of keys in the following format
+//		id:base64value,id:base64value
+// Keys may also be separated by newlines, and blank lines and lines starting with `#` are ignored. The
+// decoded value of each key is passed to decode, which returns the secret of the key.
+func parseKeys(list string, decode func([]byte) ([]byte, error)) ([]Key, error) {
+	var keys []Key
+	ids := make(map[string]bool)
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, entry := range strings.Split(line, ",") {
+			parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+			if len(parts) != 2 || !keyIDPattern.MatchString(parts[0]) {
+				return nil, errors.NewServer("Invalid signing key entry. Entries must have the format `id:base64value`")
+			}
+			id := parts[0]
+			if ids[id] {
+				return nil, errors.NewServer(fmt.Sprintf("Duplicate signing key `%s`", id))
+			}
+			ids[id] = true
+
+			value, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, errors.NewServer(fmt.Sprintf("Invalid base64 value of signing key `%s`", id))
+			}
+			secret, err := decode(value)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("Failed to decode signing key `%s`", id))
+			}
+			if len(secret) < minKeySize {
+				return nil, errors.NewServer(fmt.Sprintf("Signing key `%s` is shorter than %d bytes", id, minKeySize))
+			}
+			keys = append(keys, Key{ID: id, Secret: secret})
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.NewServer("No signing keys configured")
+	}
+	return keys, nil
+}
+
+// plaintext returns the given key value unchanged. It is the decode function of key sources that store
+// their secrets in plaintext.
+func plaintext(value []byte) ([]byte, error) {
+	return value, nil
+}
+
+// EnvKeySource loads plaintext keys from the environment variable with the given name.
+type EnvKeySource string
+
+// Keys returns the keys in the environment variable.
+func (source EnvKeySource) Keys() ([]Key, error) {
+	keys, err := parseKeys(os.Getenv(string(source)), plaintext)
+	return keys, errors.Wrap(err, fmt.Sprintf("Invalid keys in environment variable `%s`", string(source)))
+}
+
+// FileKeySource loads plaintext keys from the file at the given path, one or more keys per line.
+type FileKeySource string
+
+// Keys returns the keys in the file.
+func (source FileKeySource) Keys() ([]Key, error) {
+	contents, err := ioutil.ReadFile(string(source))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read signing key file")
+	}
+	keys, err := parseKeys(string(contents), plaintext)
+	return keys, errors.Wrap(err, fmt.Sprintf("Invalid keys in file `%s`", string(source)))
+}
+
+// KMS wraps the KMS Decrypt method used by KMSKeySource, so that LocalKMS can replace AWS KMS.
+type KMS interface {
+	Decrypt(*kms.DecryptInput) (*kms.DecryptOutput, error)
+}
+
+// KMSKeySource loads keys whose values are KMS ciphertexts from the environment variable with the given name,
+// and decrypts them with the given KMS client. Only the ciphertexts are stored in the configuration, so reading
+// the configuration alone is not enough to forge cookies.
+type KMSKeySource struct {
+	Variable string
+	Client   KMS
+}
+
+// Keys returns the decrypted keys in the environment variable.
+func (source KMSKeySource) Keys() ([]Key, error) {
+	keys, err := parseKeys(os.Getenv(source.Variable), func(ciphertext []byte) ([]byte, error) {
+		output, err := source.Client.Decrypt(&kms.DecryptInput{CiphertextBlob: ciphertext})
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed KMS Decrypt call")
+		}
+		return output.Plaintext, nil
+	})
+	return keys, errors.Wrap(err, fmt.Sprintf("Invalid keys in environment variable `%s`", source.Variable))
+}
+
+// LocalKMS is a stand-in for AWS KMS that encrypts and decrypts with a local AES-GCM master key, so that the
+// KMS key source can be tested and run without AWS. It offers no protection beyond that of the master key and
+// must not be used in production.
+type LocalKMS struct {
+	MasterKey []byte
+}
+
+// aead returns the AES-GCM cipher of the master key.
+func (local LocalKMS) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(local.MasterKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid master key")
+	}
+	aead, err := cipher.NewGCM(block)
+	return aead, errors.Wrap(err, "Failed to create AES-GCM cipher")
+}
+
+// Encrypt returns the ciphertext of the given plaintext, which Decrypt accepts as its ciphertext blob.
+func (local LocalKMS) Encrypt(plaintext []byte) ([]byte, error) {
+	aead, err := local.aead()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to generate nonce")
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt returns the plaintext of the ciphertext blob of the input, which must have been returned by Encrypt.
+func (local LocalKMS) Decrypt(input *kms.DecryptInput) (*kms.DecryptOutput, error) {
+	aead, err := local.aead()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := input.CiphertextBlob
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.NewServer("Invalid ciphertext")
+	}
+	plaintext, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to decrypt ciphertext")
+	}
+	return &kms.DecryptOutput{Plaintext: plaintext}, nil
+}
+
+// DefaultKeySource returns the key source selected by the environment. If `SESSION_KEYS_KMS` is set, its keys
+// are decrypted with AWS KMS. Otherwise, if `SESSION_KEYS_FILE` is set, keys are read from the file at that
+// path. Otherwise, plaintext keys are read from `SESSION_KEYS`.
+func DefaultKeySource() KeySource {
+	if os.Getenv("SESSION_KEYS_KMS") != "" {
+		return KMSKeySource{Variable: "SESSION_KEYS_KMS", Client: kms.New(session.New())}
+	}
+	if path := os.Getenv("SESSION_KEYS_FILE"); path != "" {
+		return FileKeySource(path)
+	}
+	return EnvKeySource("SESSION_KEYS")
+}
+
+// keyCache loads keys from a source at most once every keyCacheDuration. The source itself is created on first
+// use, since creating it may read the environment or create an AWS client.
+type keyCache struct {
+	mutex     sync.Mutex
+	newSource func() KeySource
+	source    KeySource
+	keys      []Key
+	expires   time.Time
+}
+
+// Keys returns the cached keys, loading them again if they have expired. If loading fails, the error is
+// returned and the keys are loaded again on the next call.
+func (cache *keyCache) Keys() ([]Key, error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	current := now()
+	if cache.keys != nil && current.Before(cache.expires) {
+		return cache.keys, nil
+	}
+	if cache.source == nil {
+		cache.source = cache.newSource()
+	}
+	keys, err := cache.source.Keys()
+	if err != nil {
+		return nil, err
+	}
+	cache.keys = keys
+	cache.expires = current.Add(keyCacheDuration)
+	return keys, nil
+}
+
+// keySource loads the keys used to sign and verify cookies. It should only be changed inside a test.
+var keySource KeySource = &keyCache{newSource: DefaultKeySource}
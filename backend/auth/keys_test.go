@@ -0,0 +1,320 @@
+package auth
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// encodedKey returns the entry of the given key in a key list.
+func encodedKey(key Key) string {
+	return key.ID + ":" + base64.StdEncoding.EncodeToString(key.Secret)
+}
+
+var parseKeysTests = []struct {
+	name string
+	list string
+
+	// Expected output
+	wantKeys []Key
+	wantErr  error
+}{
+	{
+		name:    "Empty",
+		list:    " \n# comment\n",
+		wantErr: errors.NewServer("No signing keys configured"),
+	},
+	{
+		name:     "SingleKey",
+		list:     encodedKey(testKey("k1", 1)),
+		wantKeys: []Key{testKey("k1", 1)},
+	},
+	{
+		name:     "CommaSeparated",
+		list:     encodedKey(testKey("k1", 1)) + ", " + encodedKey(testKey("k2", 2)),
+		wantKeys: []Key{testKey("k1", 1), testKey("k2", 2)},
+	},
+	{
+		name:     "LineSeparated",
+		list:     "# Oldest first\n" + encodedKey(testKey("2020-01", 1)) + "\n\n" + encodedKey(testKey("2020-02", 2)) + "\n",
+		wantKeys: []Key{testKey("2020-01", 1), testKey("2020-02", 2)},
+	},
+	{
+		name:    "MissingID",
+		list:    base64.StdEncoding.EncodeToString(testKey("k1", 1).Secret),
+		wantErr: errors.NewServer("Invalid signing key entry. Entries must have the format `id:base64value`"),
+	},
+	{
+		name:    "InvalidID",
+		list:    "k#1:" + base64.StdEncoding.EncodeToString(testKey("k1", 1).Secret),
+		wantErr: errors.NewServer("Invalid signing key entry. Entries must have the format `id:base64value`"),
+	},
+	{
+		name:    "DuplicateID",
+		list:    encodedKey(testKey("k1", 1)) + "," + encodedKey(testKey("k1", 2)),
+		wantErr: errors.NewServer("Duplicate signing key `k1`"),
+	},
+	{
+		name:    "InvalidBase64",
+		list:    "k1:not base64",
+		wantErr: errors.NewServer("Invalid base64 value of signing key `k1`"),
+	},
+	{
+		name:    "ShortKey",
+		list:    "k1:" + base64.StdEncoding.EncodeToString([]byte("short")),
+		wantErr: errors.NewServer("Signing key `k1` is shorter than 32 bytes"),
+	},
+}
+
+func TestParseKeys(t *testing.T) {
+	for _, test := range parseKeysTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			keys, err := parseKeys(test.list, plaintext)
+
+			// Verify
+			if !reflect.DeepEqual(keys, test.wantKeys) {
+				t.Errorf("Got keys %v; want %v", keys, test.wantKeys)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnvKeySource(t *testing.T) {
+	os.Setenv("TEST_SESSION_KEYS", encodedKey(testKey("k1", 1)))
+	defer os.Unsetenv("TEST_SESSION_KEYS")
+
+	keys, err := EnvKeySource("TEST_SESSION_KEYS").Keys()
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if want := []Key{testKey("k1", 1)}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("Got keys %v; want %v", keys, want)
+	}
+
+	_, err = EnvKeySource("TEST_MISSING_SESSION_KEYS").Keys()
+	wantErr := errors.Wrap(errors.NewServer("No signing keys configured"), "Invalid keys in environment variable `TEST_MISSING_SESSION_KEYS`")
+	if !errors.Equal(err, wantErr) {
+		t.Errorf("Got err %v; want %v", err, wantErr)
+	}
+}
+
+func TestFileKeySource(t *testing.T) {
+	file, err := ioutil.TempFile("", "session-keys")
+	if err != nil {
+		t.Fatalf("Failed to create key file: %v", err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(encodedKey(testKey("k1", 1)) + "\n" + encodedKey(testKey("k2", 2)) + "\n")
+	file.Close()
+	if err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	keys, err := FileKeySource(file.Name()).Keys()
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if want := []Key{testKey("k1", 1), testKey("k2", 2)}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("Got keys %v; want %v", keys, want)
+	}
+
+	_, err = FileKeySource(file.Name() + "-missing").Keys()
+	if errors.Message(err) != "Failed to read signing key file" {
+		t.Errorf("Got err %v; want a read failure", err)
+	}
+}
+
+func TestKMSKeySource(t *testing.T) {
+	local := LocalKMS{MasterKey: testKey("master", 9).Secret}
+	ciphertext, err := local.Encrypt(testKey("k1", 1).Secret)
+	if err != nil {
+		t.Fatalf("Failed to encrypt key: %v", err)
+	}
+	os.Setenv("TEST_SESSION_KEYS_KMS", "k1:"+base64.StdEncoding.EncodeToString(ciphertext))
+	defer os.Unsetenv("TEST_SESSION_KEYS_KMS")
+
+	t.Run("SuccessfulInvocation", func(t *testing.T) {
+		keys, err := KMSKeySource{Variable: "TEST_SESSION_KEYS_KMS", Client: local}.Keys()
+		if err != nil {
+			t.Fatalf("Got unexpected error: %v", err)
+		}
+		if want := []Key{testKey("k1", 1)}; !reflect.DeepEqual(keys, want) {
+			t.Errorf("Got keys %v; want %v", keys, want)
+		}
+	})
+
+	t.Run("WrongMasterKey", func(t *testing.T) {
+		other := LocalKMS{MasterKey: testKey("master", 8).Secret}
+		keys, err := KMSKeySource{Variable: "TEST_SESSION_KEYS_KMS", Client: other}.Keys()
+		if keys != nil {
+			t.Errorf("Got keys %v; want nil", keys)
+		}
+		if err == nil || !strings.Contains(err.Error(), "Failed KMS Decrypt call: Failed to decrypt ciphertext") {
+			t.Errorf("Got err %v; want a decryption failure", err)
+		}
+	})
+}
+
+func TestLocalKMS(t *testing.T) {
+	local := LocalKMS{MasterKey: testKey("master", 9).Secret}
+	ciphertext, err := local.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if strings.Contains(string(ciphertext), "secret") {
+		t.Errorf("Got ciphertext containing the plaintext")
+	}
+
+	output, err := local.Decrypt(&kms.DecryptInput{CiphertextBlob: ciphertext})
+	if err != nil || string(output.Plaintext) != "secret" {
+		t.Errorf("Got plaintext %v and err %v; want `secret`", output, err)
+	}
+
+	ciphertext[len(ciphertext)-1] ^= 1
+	_, err = local.Decrypt(&kms.DecryptInput{CiphertextBlob: ciphertext})
+	if err == nil {
+		t.Errorf("Tampered ciphertext decrypted successfully")
+	}
+
+	_, err = local.Decrypt(&kms.DecryptInput{CiphertextBlob: []byte("x")})
+	if !errors.Equal(err, errors.NewServer("Invalid ciphertext")) {
+		t.Errorf("Got err %v; want `Invalid ciphertext`", err)
+	}
+}
+
+// countingSource returns its keys and counts how many times they were loaded.
+type countingSource struct {
+	keys  []Key
+	err   error
+	loads int
+}
+
+func (source *countingSource) Keys() ([]Key, error) {
+	source.loads++
+	return source.keys, source.err
+}
+
+func TestKeyCache(t *testing.T) {
+	current := currentTime
+	now = func() time.Time { return current }
+	defer func() {
+		now = time.Now
+	}()
+
+	source := &countingSource{err: errors.NewServer("Source failure")}
+	created := 0
+	cache := &keyCache{newSource: func() KeySource {
+		created++
+		return source
+	}}
+
+	// A failed load is not cached
+	_, err := cache.Keys()
+	if !errors.Equal(err, errors.NewServer("Source failure")) {
+		t.Errorf("Got err %v; want the source failure", err)
+	}
+
+	source.keys, source.err = []Key{testKey("k1", 1)}, nil
+	keys, err := cache.Keys()
+	if err != nil || !reflect.DeepEqual(keys, source.keys) {
+		t.Errorf("Got keys %v and err %v; want %v", keys, err, source.keys)
+	}
+
+	// Keys are reused until they expire
+	source.keys = []Key{testKey("k1", 1), testKey("k2", 2)}
+	current = current.Add(keyCacheDuration - time.Second)
+	keys, _ = cache.Keys()
+	if len(keys) != 1 {
+		t.Errorf("Got keys %v; want the cached keys", keys)
+	}
+
+	current = current.Add(time.Second)
+	keys, _ = cache.Keys()
+	if len(keys) != 2 {
+		t.Errorf("Got keys %v; want the reloaded keys", keys)
+	}
+
+	if source.loads != 3 || created != 1 {
+		t.Errorf("Got %d loads from %d sources; want 3 loads from 1 source", source.loads, created)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	now = func() time.Time { return currentTime }
+	defer func() {
+		now = time.Now
+	}()
+	db := &sessionStoreMock{id: SessionID("token"), session: &dao.Session{
+		ID:       SessionID("token"),
+		Email:    "test@example.com",
+		LastSeen: currentTime,
+		Expires:  currentTime.Add(time.Hour),
+	}}
+
+	// Sign a cookie with the only key
+	restore := useKeys(testKey("k1", 1))
+	oldCookie, err := GenerateCookie("test@example.com", "token")
+	restore()
+	if err != nil || !strings.HasPrefix(oldCookie, "test@example.com#token#k1#") {
+		t.Fatalf("Got cookie %s and err %v; want a cookie signed by k1", oldCookie, err)
+	}
+
+	// Add a newer key
+	restore = useKeys(testKey("k1", 1), testKey("k2", 2))
+	newCookie, err := GenerateCookie("test@example.com", "token")
+	if err != nil || !strings.HasPrefix(newCookie, "test@example.com#token#k2#") {
+		t.Errorf("Got cookie %s and err %v; want a cookie signed by k2", newCookie, err)
+	}
+	for _, cookie := range []string{oldCookie, newCookie} {
+		if email, err := VerifyCookie(cookie, db); email != "test@example.com" || err != nil {
+			t.Errorf("Got email %s and err %v for cookie %s; want the cookie to be valid", email, err, cookie)
+		}
+	}
+	restore()
+
+	// Retire the older key
+	defer useKeys(testKey("k2", 2))()
+	if _, err := VerifyCookie(oldCookie, db); !errors.Equal(err, errors.NewClient("Not authenticated")) {
+		t.Errorf("Got err %v for the cookie of a retired key; want `Not authenticated`", err)
+	}
+	if email, err := VerifyCookie(newCookie, db); email != "test@example.com" || err != nil {
+		t.Errorf("Got email %s and err %v; want the cookie of the newest key to be valid", email, err)
+	}
+
+	// A cookie cannot be moved to another key
+	forged := strings.Replace(oldCookie, "#k1#", "#k2#", 1)
+	if _, err := VerifyCookie(forged, db); !errors.Equal(err, errors.NewClient("Not authenticated")) {
+		t.Errorf("Got err %v for a cookie with a swapped key ID; want `Not authenticated`", err)
+	}
+}
+
+func TestKeySourceFailure(t *testing.T) {
+	original := keySource
+	keySource = &countingSource{err: errors.NewServer("Source failure")}
+	defer func() {
+		keySource = original
+	}()
+
+	cookie, err := GenerateCookie("test@example.com", "token")
+	wantErr := errors.Wrap(errors.NewServer("Source failure"), "Failed to load signing keys")
+	if cookie != "" || !errors.Equal(err, wantErr) {
+		t.Errorf("Got cookie %s and err %v; want err %v", cookie, err, wantErr)
+	}
+
+	email, err := VerifyCookie("test@example.com#token#k1#00", nil)
+	if email != "" || !errors.Equal(err, wantErr) {
+		t.Errorf("Got email %s and err %v; want err %v", email, err, wantErr)
+	}
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// LoginStateLifetime is how long a user has to complete a login with an external identity provider.
+const LoginStateLifetime = 10 * time.Minute
+
+// loginStatePurpose distinguishes signed login states from other signed values, such as email tokens.
+const loginStatePurpose = "oidc-login"
+
+// LoginState contains the values that a login with an external identity provider must remember between sending
+// the user to the provider and the provider sending the user back. The Lambda functions are stateless, so it is
+// signed and kept in a short-lived cookie of the user's browser.
+type LoginState struct {
+	// Provider is the name of the identity provider.
+	Provider string
+
+	// State is sent to the provider and must be returned by it unchanged, which prevents cross-site request
+	// forgery of the callback.
+	State string
+
+	// Nonce is sent to the provider and must be contained in the ID token it issues, which prevents the replay
+	// of ID tokens.
+	Nonce string
+
+	// Verifier is the PKCE code verifier, whose hash is sent to the provider along with the authorization
+	// request and which must be presented when the authorization code is exchanged.
+	Verifier string
+
+	// Link is the email of the logged in user that the external identity will be linked to, or the empty string
+	// if the user is logging in with the external identity.
+	Link string
+
+	// Expires is the time after which the login can no longer be completed.
+	Expires time.Time
+}
+
+// randomString returns a base64url encoded random array of n bytes.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewLoginState returns a login state with the given provider and link, random state, nonce and code verifier,
+// which expires after LoginStateLifetime.
+func NewLoginState(provider string, link string) (*LoginState, error) {
+	values := make([]string, 3)
+	for i := range values {
+		value, err := randomString(32)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to generate login state")
+		}
+		values[i] = value
+	}
+	return &LoginState{
+		Provider: provider,
+		State:    values[0],
+		Nonce:    values[1],
+		Verifier: values[2],
+		Link:     link,
+		Expires:  now().Add(LoginStateLifetime).UTC().Truncate(time.Second),
+	}, nil
+}
+
+// SignLoginState returns the given login state signed with the newest signing key, so that it can be stored in a
+// cookie. If an error occurs, SignLoginState returns the empty string along with the error.
+func SignLoginState(state *LoginState) (string, error) {
+	expires := strconv.FormatInt(state.Expires.Unix(), 10)
+	return signFields(loginStatePurpose, expires, state.Provider, state.State, state.Nonce, state.Verifier, state.Link)
+}
+
+// VerifyLoginState checks that value was returned by SignLoginState for the given provider, that it has not
+// expired and that its state equals the given state, which was returned by the provider. It returns the login
+// state if it is valid and a client error otherwise.
+func VerifyLoginState(value string, provider string, state string) (*LoginState, error) {
+	invalid := errors.NewClient("Invalid or expired login. Please try again")
+
+	fields, err := verifyFields(value, 7)
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil || fields[0] != loginStatePurpose || fields[2] != provider || fields[3] == "" {
+		return nil, invalid
+	}
+	if subtle.ConstantTimeCompare([]byte(fields[3]), []byte(state)) != 1 {
+		return nil, invalid
+	}
+
+	expires, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || !now().Before(time.Unix(expires, 0)) {
+		return nil, invalid
+	}
+	return &LoginState{
+		Provider: fields[2],
+		State:    fields[3],
+		Nonce:    fields[4],
+		Verifier: fields[5],
+		Link:     fields[6],
+		Expires:  time.Unix(expires, 0).UTC(),
+	}, nil
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func TestLoginState(t *testing.T) {
+	current := currentTime
+	now = func() time.Time { return current }
+	defer func() {
+		now = time.Now
+	}()
+	defer useKeys(testKey("k1", 1))()
+
+	state, err := NewLoginState("github", "test@example.com")
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if len(state.State) != 43 || len(state.Nonce) != 43 || len(state.Verifier) != 43 || state.State == state.Nonce {
+		t.Errorf("Got state %+v; want distinct random values of 43 characters", state)
+	}
+	if !state.Expires.Equal(currentTime.Add(LoginStateLifetime)) {
+		t.Errorf("Got expiry %v; want %v", state.Expires, currentTime.Add(LoginStateLifetime))
+	}
+
+	value, err := SignLoginState(state)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	got, err := VerifyLoginState(value, "github", state.State)
+	if err != nil || !reflect.DeepEqual(got, state) {
+		t.Errorf("Got state %+v and err %v; want %+v", got, err, state)
+	}
+
+	invalid := errors.NewClient("Invalid or expired login. Please try again")
+	tests := []struct {
+		name     string
+		value    string
+		provider string
+		state    string
+	}{
+		{name: "OtherProvider", value: value, provider: "google", state: state.State},
+		{name: "OtherState", value: value, provider: "github", state: state.Nonce},
+		{name: "EmptyState", value: value, provider: "github"},
+		{name: "Tampered", value: value[:len(value)-2] + "AA", provider: "github", state: state.State},
+		{name: "Empty", provider: "github", state: state.State},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := VerifyLoginState(test.value, test.provider, test.state)
+			if got != nil || !errors.Equal(err, invalid) {
+				t.Errorf("Got state %+v and err %v; want %v", got, err, invalid)
+			}
+		})
+	}
+
+	t.Run("EmailToken", func(t *testing.T) {
+		token, _, _ := GenerateEmailToken(VerifyEmailPurpose, "test@example.com", time.Hour)
+		if got, err := VerifyLoginState(token, "github", state.State); got != nil || !errors.Equal(err, invalid) {
+			t.Errorf("Got state %+v and err %v for an email token; want %v", got, err, invalid)
+		}
+		if claims, err := VerifyEmailToken(value, VerifyEmailPurpose); claims != nil || err == nil {
+			t.Errorf("Got claims %+v for a login state; want an error", claims)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		current = currentTime.Add(LoginStateLifetime)
+		if got, err := VerifyLoginState(value, "github", state.State); got != nil || !errors.Equal(err, invalid) {
+			t.Errorf("Got state %+v and err %v for an expired login; want %v", got, err, invalid)
+		}
+	})
+}
@@ -7,40 +7,49 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"strings"
+	"time"
 
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
 
-// TODO: dynamically pull this key from AWS KMS
-const key = "TODO:changeThisKey"
+// SessionLifetime is how long a session remains valid after the user logs in.
+const SessionLifetime = 30 * 24 * time.Hour
 
-// UserGetter wraps the GetUser function for database dependency injection when verifying cookies.
-type UserGetter interface {
-	GetUserInfo(string) (*dao.User, error)
+// touchInterval is how often the last-seen time of a session is updated. Updating it on every request would
+// double the number of database calls made to authenticate a user.
+const touchInterval = 5 * time.Minute
+
+// now returns the current time. It should only be changed inside a test.
+var now = time.Now
+
+// SessionStore wraps the database functions used to check and refresh sessions when verifying cookies.
+type SessionStore interface {
+	GetSession(string) (*dao.Session, error)
+	TouchSession(string, time.Time) error
 }
 
-// VerifyCookieFunc wraps the function type used to check the validity of the user's cookie.
+// VerifyCookieFunc wraps the function type used to check the validity of the user's cookie or access token.
 // This allows for dependency injection of the function when verifying cookies.
-type VerifyCookieFunc func(string, UserGetter) (string, error)
+type VerifyCookieFunc func(string, CredentialStore) (string, error)
 
 // splitCookie takes a cookie in the following format
-//		email#token#mac
+//		email#token#keyID#mac
 // and separates it into its component parts. If the cookie is not in the correct format,
 // splitCookie returns emtpy strings and an error.
-func splitCookie(cookie string) (email string, token string, mac string, err error) {
+func splitCookie(cookie string) (email string, token string, keyID string, mac string, err error) {
 	slice := strings.Split(cookie, "#")
-	if len(slice) != 3 || len(slice[0]) == 0 || len(slice[1]) == 0 || len(slice[2]) == 0 {
-		return "", "", "", errors.NewClient("Incorrect cookie format")
+	if len(slice) != 4 || len(slice[0]) == 0 || len(slice[1]) == 0 || len(slice[2]) == 0 || len(slice[3]) == 0 {
+		return "", "", "", "", errors.NewClient("Incorrect cookie format")
 	}
-	return slice[0], slice[1], slice[2], nil
+	return slice[0], slice[1], slice[2], slice[3], nil
 }
 
-// GenerateToken returns a session token created by hex encoding a random array of 8 bytes.
+// GenerateToken returns a session token created by hex encoding a random array of 16 bytes.
 // The array is created using the crypto/rand package. If an error occurs, GenerateToken
 // returns the empty string along with the error.
 func GenerateToken() (token string, err error) {
-	b := make([]byte, 8)
+	b := make([]byte, 16)
 	_, err = rand.Read(b)
 	if err != nil {
 		return "", err
@@ -48,13 +57,52 @@ func GenerateToken() (token string, err error) {
 	return hex.EncodeToString(b), nil
 }
 
+// SessionID returns the ID of the session with the given token, which is the hex encoded SHA256 hash of the
+// token. Sessions are stored and listed by ID, so a leaked session record cannot be used to forge a cookie.
+func SessionID(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// NewSession returns a session of the user with the given email for the given token, which expires after
+// SessionLifetime. userAgent and ip describe the device that created the session.
+func NewSession(email string, token string, userAgent string, ip string) *dao.Session {
+	created := now().UTC()
+	return &dao.Session{
+		ID:        SessionID(token),
+		Email:     email,
+		Created:   created,
+		LastSeen:  created,
+		Expires:   created.Add(SessionLifetime),
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+}
+
+// CookieSessionID returns the ID of the session contained in the given cookie, or the empty string if the cookie
+// is not in the correct format. CookieSessionID does not verify the cookie.
+func CookieSessionID(cookie string) string {
+	_, token, _, _, err := splitCookie(cookie)
+	if err != nil {
+		return ""
+	}
+	return SessionID(token)
+}
+
 // GenerateCookie returns a cookie created using the following format:
-// 		email#token#mac
-// where mac is the SHA256 hash of email#token. If an error occurs, GenerateCookie returns
-// the empty string along with the error.
+// 		email#token#keyID#mac
+// where mac is the SHA256 HMAC of email#token#keyID under the newest signing key, and keyID
+// is the ID of that key. If an error occurs, GenerateCookie returns the empty string along
+// with the error.
 func GenerateCookie(email string, token string) (cookie string, err error) {
-	macString := email + "#" + token
-	macBytes, err := computeMAC([]byte(macString))
+	keys, err := keySource.Keys()
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to load signing keys")
+	}
+	key := keys[len(keys)-1]
+
+	macString := email + "#" + token + "#" + key.ID
+	macBytes, err := computeMAC(key.Secret, []byte(macString))
 	if err != nil {
 		return "", err
 	}
@@ -73,19 +121,25 @@ func ExtractCookie(cookieHeader string) string {
 	return cookieHeader[len("session="):]
 }
 
-// VerifyCookie checks that cookie is in the correct format, its mac is correct, and its
-// contained (email, token) tuple matches the (email, token) tuple stored in db. VerifyCookie
-// returns the email contained in the cookie if the cookie is valid. If the cookie is invalid,
-// an error is returned and email is the empty string.
-//
-// db must implement the GetUser(string) (*dao.User, error) method
-func VerifyCookie(cookie string, db UserGetter) (email string, err error) {
-	email, token, mac, err := splitCookie(cookie)
+// VerifyCookie checks that cookie is in the correct format, its mac is correct under the active signing key it
+// names, and its contained token belongs to an unexpired session of the contained email in db. The last-seen time
+// of the session is updated at most once every touchInterval. VerifyCookie returns the email contained in the
+// cookie if the cookie is valid. If the cookie is invalid, an error is returned and email is the empty string.
+func VerifyCookie(cookie string, db SessionStore) (email string, err error) {
+	email, token, keyID, mac, err := splitCookie(cookie)
 	if err != nil {
 		return "", errors.NewClient("Not authenticated")
 	}
 
-	expectedMac, err := computeMAC([]byte(email + "#" + token))
+	key, err := findKey(keyID)
+	if err != nil {
+		return "", err
+	}
+	if key == nil {
+		return "", errors.NewClient("Not authenticated")
+	}
+
+	expectedMac, err := computeMAC(key.Secret, []byte(email+"#"+token+"#"+keyID))
 	if err != nil {
 		return "", errors.Wrap(err, "Failed to compute verification MAC")
 	}
@@ -98,19 +152,44 @@ func VerifyCookie(cookie string, db UserGetter) (email string, err error) {
 		return "", errors.NewClient("Not authenticated")
 	}
 
-	user, err := db.GetUserInfo(email)
+	session, err := db.GetSession(SessionID(token))
 	if err != nil {
-		return "", errors.Wrap(err, "Failed to get user from database")
+		if errors.UserError(err) != nil {
+			return "", errors.NewClient("Not authenticated")
+		}
+		return "", errors.Wrap(err, "Failed to get session from database")
 	}
-	if user.Token != token {
+	current := now()
+	if session.Email != email || !current.Before(session.Expires) {
 		return "", errors.NewClient("Not authenticated")
 	}
+
+	if current.Sub(session.LastSeen) >= touchInterval {
+		err = db.TouchSession(session.ID, current.UTC())
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to update session")
+		}
+	}
 	return email, nil
 }
 
-// computeMAC returns the sha256 hmac of the given byte slice.
-func computeMAC(b []byte) ([]byte, error) {
-	mac := hmac.New(sha256.New, []byte(key))
+// findKey returns the active signing key with the given ID, or nil if no active key has the ID.
+func findKey(keyID string) (*Key, error) {
+	keys, err := keySource.Keys()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to load signing keys")
+	}
+	for i := range keys {
+		if keys[i].ID == keyID {
+			return &keys[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// computeMAC returns the sha256 hmac of the given byte slice under the given key.
+func computeMAC(key []byte, b []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, key)
 	_, err := mac.Write(b)
 	return mac.Sum(nil), err
 }
@@ -1,72 +1,153 @@
 package auth
 
 import (
+	"reflect"
+	"testing"
+	"time"
+
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
-	"testing"
 )
 
-type userGetterFunc func(string) (*dao.User, error)
+// sessionStoreMock returns the given session from GetSession and records the calls to TouchSession.
+type sessionStoreMock struct {
+	id       string
+	session  *dao.Session
+	getErr   error
+	touchErr error
+	touched  []time.Time
+}
 
-func (f userGetterFunc) GetUserInfo(email string) (*dao.User, error) {
-	return f(email)
+func (mock *sessionStoreMock) GetSession(id string) (*dao.Session, error) {
+	if id != mock.id {
+		return nil, errors.NewServer("Incorrect input to GetSession mock")
+	}
+	return mock.session, mock.getErr
 }
 
-func getUserMock(email string, token string, tokenMatches bool, mockErr error) userGetterFunc {
-	return func(input string) (*dao.User, error) {
-		if input == email {
-			if !tokenMatches {
-				token = ""
-			}
-			return &dao.User{Email: email, Token: token}, mockErr
-		}
-		return nil, errors.NewServer("Incorrect input to getUserMock")
+func (mock *sessionStoreMock) TouchSession(id string, lastSeen time.Time) error {
+	if id != mock.id {
+		return errors.NewServer("Incorrect input to TouchSession mock")
+	}
+	mock.touched = append(mock.touched, lastSeen)
+	return mock.touchErr
+}
+
+// staticKeys is a key source that always returns the same keys.
+type staticKeys []Key
+
+func (keys staticKeys) Keys() ([]Key, error) {
+	return keys, nil
+}
+
+// testKey returns a key with the given ID whose secret repeats the given byte.
+func testKey(id string, b byte) Key {
+	secret := make([]byte, minKeySize)
+	for i := range secret {
+		secret[i] = b
+	}
+	return Key{ID: id, Secret: secret}
+}
+
+// useKeys makes the given keys the active signing keys until the returned function is called.
+func useKeys(keys ...Key) func() {
+	original := keySource
+	keySource = staticKeys(keys)
+	return func() {
+		keySource = original
+	}
+}
+
+var currentTime = time.Date(2020, time.May, 1, 12, 30, 0, 0, time.UTC)
+
+// testSession returns a session of the given email that was last seen and expires at the given offsets from
+// currentTime.
+func testSession(email string, lastSeen time.Duration, expires time.Duration) *dao.Session {
+	return &dao.Session{
+		Email:    email,
+		Created:  currentTime.Add(-time.Hour),
+		LastSeen: currentTime.Add(lastSeen),
+		Expires:  currentTime.Add(expires),
 	}
 }
 
 var sessionTests = []struct {
-	name         string
-	email        string
-	tokenMatches bool
-	mockErr      error
-	wantErr      error
-	wantEmail    string
+	name     string
+	session  *dao.Session
+	getErr   error
+	touchErr error
+
+	// Expected output
+	wantTouched []time.Time
+	wantErr     error
+	wantEmail   string
 }{
 	{
-		name:         "DatabaseError",
-		email:        "test@example.com",
-		tokenMatches: true,
-		mockErr:      errors.NewServer("Database failure"),
-		wantErr:      errors.Wrap(errors.NewServer("Database failure"), "Failed to get user from database"),
+		name:    "DatabaseError",
+		getErr:  errors.NewServer("Database failure"),
+		wantErr: errors.Wrap(errors.NewServer("Database failure"), "Failed to get session from database"),
+	},
+	{
+		name:    "SessionNotFound",
+		getErr:  errors.NewClient("Session not found"),
+		wantErr: errors.NewClient("Not authenticated"),
+	},
+	{
+		name:    "DifferentEmail",
+		session: testSession("other@example.com", -time.Minute, time.Hour),
+		wantErr: errors.NewClient("Not authenticated"),
+	},
+	{
+		name:    "ExpiredSession",
+		session: testSession("test@example.com", -time.Minute, -time.Second),
+		wantErr: errors.NewClient("Not authenticated"),
+	},
+	{
+		name:        "TouchError",
+		session:     testSession("test@example.com", -time.Hour, time.Hour),
+		touchErr:    errors.NewServer("Database failure"),
+		wantTouched: []time.Time{currentTime},
+		wantErr:     errors.Wrap(errors.NewServer("Database failure"), "Failed to update session"),
 	},
 	{
-		name:         "IncorrectToken",
-		email:        "test@example.com",
-		tokenMatches: false,
-		wantErr:      errors.NewClient("Not authenticated"),
+		name:      "RecentlySeen",
+		session:   testSession("test@example.com", -time.Minute, time.Hour),
+		wantEmail: "test@example.com",
 	},
 	{
-		name:         "SuccessfulInvocation",
-		email:        "test@example.com",
-		tokenMatches: true,
-		wantEmail:    "test@example.com",
+		name:        "SuccessfulInvocation",
+		session:     testSession("test@example.com", -time.Hour, time.Hour),
+		wantTouched: []time.Time{currentTime},
+		wantEmail:   "test@example.com",
 	},
 }
 
 func TestAllMethods(t *testing.T) {
 	for _, test := range sessionTests {
 		t.Run(test.name, func(t *testing.T) {
+			now = func() time.Time { return currentTime }
+			defer func() {
+				now = time.Now
+			}()
+			defer useKeys(testKey("k1", 1))()
+
 			token, err := GenerateToken()
 			if err != nil {
 				t.Errorf("Got unexpected error: %v", err)
 			}
 
-			cookie, err := GenerateCookie(test.email, token)
+			cookie, err := GenerateCookie("test@example.com", token)
 			if err != nil {
 				t.Errorf("Got unexpected error: %v", err)
 			}
+			if id := CookieSessionID(cookie); id != SessionID(token) {
+				t.Errorf("Got session ID %s; want %s", id, SessionID(token))
+			}
 
-			db := getUserMock(test.email, token, test.tokenMatches, test.mockErr)
+			if test.session != nil {
+				test.session.ID = SessionID(token)
+			}
+			db := &sessionStoreMock{id: SessionID(token), session: test.session, getErr: test.getErr, touchErr: test.touchErr}
 			email, err := VerifyCookie(cookie, db)
 			if !errors.Equal(err, test.wantErr) {
 				t.Errorf("Got err %v; want %v", err, test.wantErr)
@@ -74,10 +155,38 @@ func TestAllMethods(t *testing.T) {
 			if email != test.wantEmail {
 				t.Errorf("Got email %s; want email %s", email, test.wantEmail)
 			}
+			if !reflect.DeepEqual(db.touched, test.wantTouched) {
+				t.Errorf("Got last-seen updates %v; want %v", db.touched, test.wantTouched)
+			}
 		})
 	}
 }
 
+func TestNewSession(t *testing.T) {
+	now = func() time.Time { return currentTime }
+	defer func() {
+		now = time.Now
+	}()
+
+	session := NewSession("test@example.com", "token", "Mozilla/5.0", "203.0.113.1")
+
+	want := &dao.Session{
+		ID:        SessionID("token"),
+		Email:     "test@example.com",
+		Created:   currentTime,
+		LastSeen:  currentTime,
+		Expires:   currentTime.Add(SessionLifetime),
+		UserAgent: "Mozilla/5.0",
+		IP:        "203.0.113.1",
+	}
+	if !reflect.DeepEqual(session, want) {
+		t.Errorf("Got session %+v; want %+v", session, want)
+	}
+	if session.ID == "token" || len(session.ID) != 64 {
+		t.Errorf("Got session ID %s; want the hex SHA256 hash of the token", session.ID)
+	}
+}
+
 var extractCookieTests = []struct {
 	name       string
 	header     string
@@ -117,43 +226,48 @@ func TestExtractCookie(t *testing.T) {
 }
 
 func TestInvalidCookie(t *testing.T) {
+	defer useKeys(testKey("k1", 1))()
+
 	t.Run("IncorrectFormat", func(t *testing.T) {
-		_, _, _, err := splitCookie("")
+		_, _, _, _, err := splitCookie("")
 		if err == nil {
 			t.Errorf("Empty string cookie considered valid")
 		}
 
-		_, _, _, err = splitCookie("#token#mac")
+		_, _, _, _, err = splitCookie("#token#k1#mac")
 		if err == nil {
-			t.Errorf("Cookie `#token#mac` considered valid")
+			t.Errorf("Cookie `#token#k1#mac` considered valid")
 		}
 
-		_, _, _, err = splitCookie("token#mac#")
+		_, _, _, _, err = splitCookie("token#k1#mac#")
 		if err == nil {
-			t.Errorf("Cookie `token#mac#` considered valid")
+			t.Errorf("Cookie `token#k1#mac#` considered valid")
 		}
 
-		_, _, _, err = splitCookie("email#token#mac#")
+		_, _, _, _, err = splitCookie("email#token#k1#mac#")
 		if err == nil {
-			t.Errorf("Cookie `email#token#mac#` considered valid")
+			t.Errorf("Cookie `email#token#k1#mac#` considered valid")
 		}
 
-		_, _, _, err = splitCookie("email#token##mac")
+		_, _, _, _, err = splitCookie("email#token##mac")
 		if err == nil {
 			t.Errorf("Cookie `email#token##mac` considered valid")
 		}
 
-		email, err := VerifyCookie("email#token##mac", nil)
+		email, err := VerifyCookie("email#token#mac", nil)
 		if email != "" {
 			t.Errorf("Got email %s; want empty string", email)
 		}
 		if errors.Message(err) != "Not authenticated" {
 			t.Errorf("Got error %v", err)
 		}
+		if id := CookieSessionID("email#token##mac"); id != "" {
+			t.Errorf("Got session ID %s; want empty string", id)
+		}
 	})
 
 	t.Run("IncorrectEncoding", func(t *testing.T) {
-		email, err := VerifyCookie("email#token#mac", nil)
+		email, err := VerifyCookie("email#token#k1#mac", nil)
 		if email != "" {
 			t.Errorf("Got email %s; want empty string", email)
 		}
@@ -161,7 +275,17 @@ func TestInvalidCookie(t *testing.T) {
 			t.Errorf("Got error %v", err)
 		}
 
-		email, err = VerifyCookie("email#token#0123456789abcdef", nil)
+		email, err = VerifyCookie("email#token#k1#0123456789abcdef", nil)
+		if email != "" {
+			t.Errorf("Got email %s; want empty string", email)
+		}
+		if errors.Message(err) != "Not authenticated" {
+			t.Errorf("Got error %v", err)
+		}
+	})
+
+	t.Run("UnknownKey", func(t *testing.T) {
+		email, err := VerifyCookie("email#token#k2#0123456789abcdef", nil)
 		if email != "" {
 			t.Errorf("Got email %s; want empty string", email)
 		}
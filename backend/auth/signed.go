@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// signFields returns the given fields signed with the newest signing key, in the following format
+//		base64(keyID\nfield\nfield...).base64(mac)
+// where mac is the SHA256 HMAC of the first part. The value only contains URL-safe characters, so it can be sent in
+// links and cookies. Only the last field may contain newlines.
+func signFields(fields ...string) (string, error) {
+	keys, err := keySource.Keys()
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to load signing keys")
+	}
+	key := keys[len(keys)-1]
+
+	claims := key.ID + "\n" + strings.Join(fields, "\n")
+	payload := base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac, err := computeMAC(key.Secret, []byte(payload))
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to compute MAC")
+	}
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac), nil
+}
+
+// verifyFields checks that value was returned by signFields with count fields and that its mac is correct under
+// the active signing key it names, and returns its fields. If value is invalid, verifyFields returns nil and a nil
+// error; an error is only returned if the signing keys cannot be loaded.
+func verifyFields(value string, count int) ([]string, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 2 {
+		return nil, nil
+	}
+	claims, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil
+	}
+	fields := strings.SplitN(string(claims), "\n", count+1)
+	if len(fields) != count+1 {
+		return nil, nil
+	}
+
+	key, err := findKey(fields[0])
+	if err != nil || key == nil {
+		return nil, err
+	}
+	expectedMac, err := computeMAC(key.Secret, []byte(parts[0]))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to compute verification MAC")
+	}
+	if !hmac.Equal(expectedMac, mac) {
+		return nil, nil
+	}
+	return fields[1:], nil
+}
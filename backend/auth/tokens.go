@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// Scope restricts the endpoints that an access token can be used for.
+type Scope string
+
+// The scopes that an access token can have. An access token without scopes is unrestricted.
+const (
+	// ScopeReadOnly allows reading the user, their projects and their versions.
+	ScopeReadOnly Scope = "read-only"
+
+	// ScopeCodegen allows downloading the generated code and OpenAPI document of a project.
+	ScopeCodegen Scope = "codegen"
+
+	// ScopeDeploy allows deploying and undeploying a project and reading its deployment.
+	ScopeDeploy Scope = "deploy"
+)
+
+// Scopes contains every valid scope.
+var Scopes = []Scope{ScopeReadOnly, ScopeCodegen, ScopeDeploy}
+
+// AccessTokenPrefix is the prefix of every access token, so that leaked tokens are easy to recognize.
+const AccessTokenPrefix = "apic_"
+
+// bearerPrefix is the prefix of an `Authorization` header value that contains an access token.
+const bearerPrefix = "Bearer "
+
+// AccessTokenStore wraps the database functions used to check and refresh access tokens when verifying them.
+type AccessTokenStore interface {
+	GetAccessToken(string) (*dao.AccessToken, error)
+	TouchAccessToken(string, time.Time) error
+}
+
+// CredentialStore wraps the database functions used to verify both session cookies and access tokens.
+type CredentialStore interface {
+	SessionStore
+	AccessTokenStore
+}
+
+// ValidScope returns true if the given string is a valid scope.
+func ValidScope(scope string) bool {
+	for _, valid := range Scopes {
+		if scope == string(valid) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateAccessToken returns a new access token created by hex encoding a random array of 32 bytes and adding
+// AccessTokenPrefix. If an error occurs, GenerateAccessToken returns the empty string along with the error.
+func GenerateAccessToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return AccessTokenPrefix + hex.EncodeToString(b), nil
+}
+
+// AccessTokenID returns the ID of the given access token, which is its hex encoded SHA256 hash. Only the ID is
+// stored, so the access tokens table cannot be used to authenticate.
+func AccessTokenID(token string) string {
+	return SessionID(token)
+}
+
+// NewAccessToken returns an access token record of the user with the given email for the given token, which
+// expires after lifetime. An empty list of scopes makes the token unrestricted.
+func NewAccessToken(email string, token string, name string, scopes []Scope, lifetime time.Duration) *dao.AccessToken {
+	created := now().UTC()
+	record := &dao.AccessToken{
+		ID:      AccessTokenID(token),
+		Email:   email,
+		Name:    name,
+		Created: created,
+		Expires: created.Add(lifetime),
+	}
+	for _, scope := range scopes {
+		record.Scopes = append(record.Scopes, string(scope))
+	}
+	return record
+}
+
+// ExtractCredential extracts the credential of a request from its headers. If the request has an `Authorization`
+// header in the following format
+//		Bearer <access token>
+// the header value is returned, so that verifiers can tell access tokens from cookies. Otherwise, the session
+// cookie is extracted from the `Cookie` header as in ExtractCookie.
+func ExtractCredential(headers map[string]string) string {
+	for name, value := range headers {
+		if strings.EqualFold(name, "Authorization") && len(value) > len(bearerPrefix) &&
+			strings.EqualFold(value[:len(bearerPrefix)], bearerPrefix) {
+			return bearerPrefix + strings.TrimSpace(value[len(bearerPrefix):])
+		}
+	}
+	return ExtractCookie(headers["Cookie"])
+}
+
+// Verifier returns a VerifyCookieFunc that accepts both session cookies and access tokens, in the format returned
+// by ExtractCredential. Session cookies are checked by VerifyCookie and access tokens by VerifyAccessToken with the
+// given scopes, so an endpoint lists the scopes that allow calling it. Endpoints that list no scopes only accept
+// unrestricted access tokens.
+func Verifier(scopes ...Scope) VerifyCookieFunc {
+	return func(credential string, db CredentialStore) (string, error) {
+		if strings.HasPrefix(credential, bearerPrefix) {
+			return VerifyAccessToken(credential[len(bearerPrefix):], scopes, db)
+		}
+		return VerifyCookie(credential, db)
+	}
+}
+
+// VerifySession is a VerifyCookieFunc that only accepts session cookies. It protects the endpoints that manage
+// sessions and access tokens, so that an access token cannot be used to create other access tokens.
+func VerifySession(cookie string, db CredentialStore) (string, error) {
+	return VerifyCookie(cookie, db)
+}
+
+// VerifyAccessToken checks that token belongs to an unexpired access token in db that is either unrestricted or
+// has one of the given scopes. The last-used time of the access token is updated at most once every touchInterval.
+// VerifyAccessToken returns the email of the owner of the token if the token is valid. If the token is invalid, an
+// error is returned and email is the empty string.
+func VerifyAccessToken(token string, scopes []Scope, db AccessTokenStore) (email string, err error) {
+	if !strings.HasPrefix(token, AccessTokenPrefix) {
+		return "", errors.NewClient("Not authenticated")
+	}
+
+	record, err := db.GetAccessToken(AccessTokenID(token))
+	if err != nil {
+		if errors.UserError(err) != nil {
+			return "", errors.NewClient("Not authenticated")
+		}
+		return "", errors.Wrap(err, "Failed to get access token from database")
+	}
+	current := now()
+	if !current.Before(record.Expires) || !hasScope(record, scopes) {
+		return "", errors.NewClient("Not authenticated")
+	}
+
+	if record.LastUsed == nil || current.Sub(*record.LastUsed) >= touchInterval {
+		err = db.TouchAccessToken(record.ID, current.UTC())
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to update access token")
+		}
+	}
+	return record.Email, nil
+}
+
+// hasScope returns true if the given access token is unrestricted or has at least one of the given scopes.
+func hasScope(record *dao.AccessToken, scopes []Scope) bool {
+	if len(record.Scopes) == 0 {
+		return true
+	}
+	for _, scope := range scopes {
+		for _, granted := range record.Scopes {
+			if granted == string(scope) {
+				return true
+			}
+		}
+	}
+	return false
+}
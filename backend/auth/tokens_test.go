@@ -0,0 +1,279 @@
+package auth
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// credentialStoreMock returns the given access token from GetAccessToken and records the calls to
+// TouchAccessToken. Its session methods are those of sessionStoreMock.
+type credentialStoreMock struct {
+	sessionStoreMock
+	tokenID  string
+	token    *dao.AccessToken
+	getErr   error
+	touchErr error
+	touched  []time.Time
+}
+
+func (mock *credentialStoreMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	if id != mock.tokenID {
+		return nil, errors.NewServer("Incorrect input to GetAccessToken mock")
+	}
+	return mock.token, mock.getErr
+}
+
+func (mock *credentialStoreMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	if id != mock.tokenID {
+		return errors.NewServer("Incorrect input to TouchAccessToken mock")
+	}
+	mock.touched = append(mock.touched, lastUsed)
+	return mock.touchErr
+}
+
+const testAccessToken = AccessTokenPrefix + "0123456789abcdef"
+
+// testTokenRecord returns an access token of test@example.com with the given scopes that was last used and
+// expires at the given offsets from currentTime.
+func testTokenRecord(scopes []string, lastUsed time.Duration, expires time.Duration) *dao.AccessToken {
+	used := currentTime.Add(lastUsed)
+	return &dao.AccessToken{
+		ID:       AccessTokenID(testAccessToken),
+		Email:    "test@example.com",
+		Name:     "CI",
+		Scopes:   scopes,
+		Created:  currentTime.Add(-time.Hour),
+		LastUsed: &used,
+		Expires:  currentTime.Add(expires),
+	}
+}
+
+func TestGenerateAccessToken(t *testing.T) {
+	token, err := GenerateAccessToken()
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(token, AccessTokenPrefix) || len(token) != len(AccessTokenPrefix)+64 {
+		t.Errorf("Got token %s; want %s followed by 64 hex characters", token, AccessTokenPrefix)
+	}
+	if AccessTokenID(token) == token {
+		t.Errorf("Got the token itself as its ID")
+	}
+}
+
+func TestNewAccessToken(t *testing.T) {
+	now = func() time.Time { return currentTime }
+	defer func() {
+		now = time.Now
+	}()
+
+	token := NewAccessToken("test@example.com", testAccessToken, "CI", []Scope{ScopeCodegen, ScopeDeploy}, time.Hour)
+	want := &dao.AccessToken{
+		ID:      AccessTokenID(testAccessToken),
+		Email:   "test@example.com",
+		Name:    "CI",
+		Scopes:  []string{"codegen", "deploy"},
+		Created: currentTime,
+		Expires: currentTime.Add(time.Hour),
+	}
+	if !reflect.DeepEqual(token, want) {
+		t.Errorf("Got token %+v; want %+v", token, want)
+	}
+}
+
+func TestValidScope(t *testing.T) {
+	for _, scope := range []string{"read-only", "codegen", "deploy"} {
+		if !ValidScope(scope) {
+			t.Errorf("Got scope %s invalid; want valid", scope)
+		}
+	}
+	for _, scope := range []string{"", "read", "Deploy"} {
+		if ValidScope(scope) {
+			t.Errorf("Got scope %s valid; want invalid", scope)
+		}
+	}
+}
+
+var extractCredentialTests = []struct {
+	name    string
+	headers map[string]string
+	want    string
+}{
+	{
+		name: "NoHeaders",
+	},
+	{
+		name:    "Cookie",
+		headers: map[string]string{"Cookie": "session=cookievalue"},
+		want:    "cookievalue",
+	},
+	{
+		name:    "BearerToken",
+		headers: map[string]string{"Authorization": "Bearer " + testAccessToken, "Cookie": "session=cookievalue"},
+		want:    "Bearer " + testAccessToken,
+	},
+	{
+		name:    "LowercaseHeader",
+		headers: map[string]string{"authorization": "bearer  " + testAccessToken},
+		want:    "Bearer " + testAccessToken,
+	},
+	{
+		name:    "OtherScheme",
+		headers: map[string]string{"Authorization": "Basic dXNlcjpwYXNz", "Cookie": "session=cookievalue"},
+		want:    "cookievalue",
+	},
+	{
+		name:    "EmptyBearer",
+		headers: map[string]string{"Authorization": "Bearer "},
+	},
+}
+
+func TestExtractCredential(t *testing.T) {
+	for _, test := range extractCredentialTests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ExtractCredential(test.headers); got != test.want {
+				t.Errorf("Got credential %q; want %q", got, test.want)
+			}
+		})
+	}
+}
+
+var verifyAccessTokenTests = []struct {
+	name     string
+	token    string
+	scopes   []Scope
+	record   *dao.AccessToken
+	getErr   error
+	touchErr error
+
+	// Expected output
+	wantTouched []time.Time
+	wantEmail   string
+	wantErr     error
+}{
+	{
+		name:    "MissingPrefix",
+		token:   "0123456789abcdef",
+		wantErr: errors.NewClient("Not authenticated"),
+	},
+	{
+		name:    "DatabaseError",
+		getErr:  errors.NewServer("Database failure"),
+		wantErr: errors.Wrap(errors.NewServer("Database failure"), "Failed to get access token from database"),
+	},
+	{
+		name:    "TokenNotFound",
+		getErr:  errors.NewClient("Access token not found"),
+		wantErr: errors.NewClient("Not authenticated"),
+	},
+	{
+		name:    "ExpiredToken",
+		record:  testTokenRecord(nil, -time.Minute, -time.Second),
+		wantErr: errors.NewClient("Not authenticated"),
+	},
+	{
+		name:    "MissingScope",
+		scopes:  []Scope{ScopeDeploy},
+		record:  testTokenRecord([]string{"read-only", "codegen"}, -time.Minute, time.Hour),
+		wantErr: errors.NewClient("Not authenticated"),
+	},
+	{
+		name:    "RestrictedTokenForUnscopedEndpoint",
+		record:  testTokenRecord([]string{"read-only", "codegen", "deploy"}, -time.Minute, time.Hour),
+		wantErr: errors.NewClient("Not authenticated"),
+	},
+	{
+		name:        "TouchError",
+		record:      testTokenRecord(nil, -time.Hour, time.Hour),
+		touchErr:    errors.NewServer("Database failure"),
+		wantTouched: []time.Time{currentTime},
+		wantErr:     errors.Wrap(errors.NewServer("Database failure"), "Failed to update access token"),
+	},
+	{
+		name:      "RecentlyUsed",
+		scopes:    []Scope{ScopeReadOnly, ScopeDeploy},
+		record:    testTokenRecord([]string{"deploy"}, -time.Minute, time.Hour),
+		wantEmail: "test@example.com",
+	},
+	{
+		name:        "UnrestrictedToken",
+		scopes:      []Scope{ScopeCodegen},
+		record:      testTokenRecord(nil, -time.Hour, time.Hour),
+		wantTouched: []time.Time{currentTime},
+		wantEmail:   "test@example.com",
+	},
+}
+
+func TestVerifyAccessToken(t *testing.T) {
+	for _, test := range verifyAccessTokenTests {
+		t.Run(test.name, func(t *testing.T) {
+			now = func() time.Time { return currentTime }
+			defer func() {
+				now = time.Now
+			}()
+			token := test.token
+			if token == "" {
+				token = testAccessToken
+			}
+			db := &credentialStoreMock{tokenID: AccessTokenID(testAccessToken), token: test.record, getErr: test.getErr, touchErr: test.touchErr}
+
+			email, err := VerifyAccessToken(token, test.scopes, db)
+
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+			if email != test.wantEmail {
+				t.Errorf("Got email %s; want email %s", email, test.wantEmail)
+			}
+			if !reflect.DeepEqual(db.touched, test.wantTouched) {
+				t.Errorf("Got touched %v; want %v", db.touched, test.wantTouched)
+			}
+		})
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	now = func() time.Time { return currentTime }
+	defer func() {
+		now = time.Now
+	}()
+	defer useKeys(testKey("k1", 1))()
+
+	cookie, err := GenerateCookie("test@example.com", "token")
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	db := &credentialStoreMock{
+		sessionStoreMock: sessionStoreMock{id: SessionID("token"), session: &dao.Session{
+			ID:       SessionID("token"),
+			Email:    "test@example.com",
+			LastSeen: currentTime,
+			Expires:  currentTime.Add(time.Hour),
+		}},
+		tokenID: AccessTokenID(testAccessToken),
+		token:   testTokenRecord([]string{"codegen"}, 0, time.Hour),
+	}
+
+	// Session cookies are accepted by every verifier
+	for _, verify := range []VerifyCookieFunc{Verifier(), Verifier(ScopeDeploy), VerifySession} {
+		if email, err := verify(cookie, db); email != "test@example.com" || err != nil {
+			t.Errorf("Got email %s and err %v for a session cookie; want the cookie to be valid", email, err)
+		}
+	}
+
+	// Access tokens are only accepted with a matching scope
+	bearer := ExtractCredential(map[string]string{"Authorization": "Bearer " + testAccessToken})
+	if email, err := Verifier(ScopeReadOnly, ScopeCodegen)(bearer, db); email != "test@example.com" || err != nil {
+		t.Errorf("Got email %s and err %v for a codegen token; want the token to be valid", email, err)
+	}
+	for _, verify := range []VerifyCookieFunc{Verifier(), Verifier(ScopeDeploy), VerifySession} {
+		if _, err := verify(bearer, db); !errors.Equal(err, errors.NewClient("Not authenticated")) {
+			t.Errorf("Got err %v for a codegen token; want `Not authenticated`", err)
+		}
+	}
+}
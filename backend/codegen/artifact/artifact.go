@@ -0,0 +1,107 @@
+// Package artifact builds the zipped code of a project and stores it in S3, where it can be downloaded by users
+// or by the instances that run deployed projects.
+package artifact
+
+import (
+	"os"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/ddl"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/migration"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/s3"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/zip"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// These variables wrap the different functions that Build relies upon. They should
+// not be changed except for dependency injection within unit tests.
+var clean = resetDir
+var download = s3.Download
+var unzip = zip.Unzip
+var generateSchema = ddl.Generate
+var generateMigration = migration.Generate
+var zipper = zip.Zip
+var upload = s3.Upload
+
+// resetDir removes the given directory along with its contents and then recreates it empty. This
+// prevents files generated by an earlier invocation of a warm Lambda from leaking into the new code.
+func resetDir(dir string) error {
+	err := os.RemoveAll(dir)
+	if err != nil {
+		return errors.Wrap(err, "Failed to remove directory")
+	}
+	err = os.MkdirAll(dir, os.ModePerm)
+	return errors.Wrap(err, "Failed to create directory")
+}
+
+// Build performs the following steps:
+//		1. Download the blank project template from S3, if the generator requires one
+//		2. Unzip the template
+//		3. Generate the code for the given project
+//		4. Generate the SQL schema files of the project in the db directory
+//		5. Generate the migration from the base version of the project in db/migrations, if base is not nil
+//		6. Zip the generated code
+//		7. Upload the generated zip to S3
+// The project is the project with the given ID owned by the user with the given email, and base is an older
+// version of it, such as the version from which a deployed database was created. The S3 key of the uploaded
+// zip is returned, or an empty string if an error occurred.
+func Build(generator codegen.Generator, email string, projectID string, project *dao.Project, base *dao.Project) (string, error) {
+	name := generator.Name()
+	workDir := "/tmp/" + name
+	projectDir := workDir + "/" + codegen.TemplateRoot
+	err := clean(projectDir)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to prepare working directory")
+	}
+
+	if templateKey := generator.TemplateKey(); templateKey != "" {
+		// Download the project template from S3
+		templatePath := "/tmp/blank-" + name + ".zip"
+		err = download(templatePath, templateKey)
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to get project template from S3")
+		}
+
+		// Unzip the template
+		err = unzip(templatePath, workDir)
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to unzip project template")
+		}
+	}
+
+	// Generate the code
+	err = generator.Generate(project, projectDir)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to generate project code")
+	}
+
+	// Generate the database schemas
+	err = generateSchema(project, projectDir)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to generate database schema")
+	}
+
+	// Generate the migration from the base version
+	if base != nil {
+		err = generateMigration(base, project, projectDir)
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to generate database migration")
+		}
+	}
+
+	// Zip the generated code
+	zipPath := "/tmp/generated-" + name + ".zip"
+	err = zipper(zipPath, projectDir)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to zip generated code")
+	}
+
+	// Upload generated zip to S3
+	key := s3.ProjectPrefix(email, projectID) + name + ".zip"
+	err = upload(zipPath, key)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to upload generated zip to S3")
+	}
+	return key, nil
+}
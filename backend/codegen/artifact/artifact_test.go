@@ -0,0 +1,250 @@
+package artifact
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func createMock(mock1 string, mock2 string, mockErr error) func(string, string) error {
+	return func(in1 string, in2 string) error {
+		if in1 != mock1 || in2 != mock2 {
+			return errors.NewServer("Incorrect input to mock")
+		}
+		return mockErr
+	}
+}
+
+type generatorMock struct {
+	name        string
+	templateKey string
+	project     *dao.Project
+	rootDir     string
+	err         error
+}
+
+func (mock *generatorMock) Name() string {
+	return mock.name
+}
+
+func (mock *generatorMock) TemplateKey() string {
+	return mock.templateKey
+}
+
+func (mock *generatorMock) Generate(project *dao.Project, rootDir string) error {
+	if !reflect.DeepEqual(project, mock.project) || rootDir != mock.rootDir {
+		return errors.NewServer("Incorrect input to generate mock")
+	}
+	return mock.err
+}
+
+func cleanMock(wantDir string, err error) func(string) error {
+	return func(dir string) error {
+		if dir != wantDir {
+			return errors.NewServer("Incorrect input to clean mock")
+		}
+		return err
+	}
+}
+
+func schemaMock(mockProject *dao.Project, mockPath string, mockErr error) func(*dao.Project, string) error {
+	return func(project *dao.Project, dirPath string) error {
+		if !reflect.DeepEqual(project, mockProject) || dirPath != mockPath {
+			return errors.NewServer("Incorrect input to schema mock")
+		}
+		return mockErr
+	}
+}
+
+func migrationMock(mockOld *dao.Project, mockNew *dao.Project, mockPath string, mockErr error) func(*dao.Project, *dao.Project, string) error {
+	return func(oldProject *dao.Project, newProject *dao.Project, dirPath string) error {
+		if oldProject != mockOld || newProject != mockNew || dirPath != mockPath {
+			return errors.NewServer("Incorrect input to migration mock")
+		}
+		return mockErr
+	}
+}
+
+var defaultProject = &dao.Project{Name: "Default Project", ID: "defaultProject"}
+
+var previousProject = &dao.Project{Name: "Default Project", ID: "defaultProject", Version: 2}
+
+var versionedProject = &dao.Project{Name: "Default Project", ID: "defaultProject", Version: 3}
+
+var versionedGenerator = &generatorMock{
+	name:    "go",
+	project: versionedProject,
+	rootDir: "/tmp/go/defaultProject",
+}
+
+var sailsGenerator = &generatorMock{
+	name:        "sails",
+	templateKey: "templates/sails.zip",
+	project:     defaultProject,
+	rootDir:     "/tmp/sails/defaultProject",
+}
+
+var buildTests = []struct {
+	name      string
+	generator codegen.Generator
+
+	// Mock data
+	clean      func(string) error
+	downloader func(string, string) error
+	unzipper   func(string, string) error
+	schema     func(*dao.Project, string) error
+	project    *dao.Project
+	base       *dao.Project
+	migration  func(*dao.Project, *dao.Project, string) error
+	zipper     func(string, string) error
+	uploader   func(string, string) error
+
+	// Expected output
+	wantKey string
+	wantErr error
+}{
+	{
+		name:      "CleanError",
+		generator: sailsGenerator,
+		clean:     cleanMock("/tmp/sails/defaultProject", errors.NewServer("Filesystem failure")),
+		wantErr:   errors.Wrap(errors.NewServer("Filesystem failure"), "Failed to prepare working directory"),
+	},
+	{
+		name:       "DownloadError",
+		generator:  sailsGenerator,
+		clean:      cleanMock("/tmp/sails/defaultProject", nil),
+		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", errors.NewServer("S3 failure")),
+		wantErr:    errors.Wrap(errors.NewServer("S3 failure"), "Failed to get project template from S3"),
+	},
+	{
+		name:       "UnzipError",
+		generator:  sailsGenerator,
+		clean:      cleanMock("/tmp/sails/defaultProject", nil),
+		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", nil),
+		unzipper:   createMock("/tmp/blank-sails.zip", "/tmp/sails", errors.NewServer("Unzip failure")),
+		wantErr:    errors.Wrap(errors.NewServer("Unzip failure"), "Failed to unzip project template"),
+	},
+	{
+		name: "GenerateError",
+		generator: &generatorMock{
+			name:        "sails",
+			templateKey: "templates/sails.zip",
+			project:     defaultProject,
+			rootDir:     "/tmp/sails/defaultProject",
+			err:         errors.NewServer("Generate failure"),
+		},
+		clean:      cleanMock("/tmp/sails/defaultProject", nil),
+		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", nil),
+		unzipper:   createMock("/tmp/blank-sails.zip", "/tmp/sails", nil),
+		wantErr:    errors.Wrap(errors.NewServer("Generate failure"), "Failed to generate project code"),
+	},
+	{
+		name:       "SchemaError",
+		generator:  sailsGenerator,
+		clean:      cleanMock("/tmp/sails/defaultProject", nil),
+		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", nil),
+		unzipper:   createMock("/tmp/blank-sails.zip", "/tmp/sails", nil),
+		schema:     schemaMock(defaultProject, "/tmp/sails/defaultProject", errors.NewServer("Schema failure")),
+		wantErr:    errors.Wrap(errors.NewServer("Schema failure"), "Failed to generate database schema"),
+	},
+	{
+		name:       "ZipError",
+		generator:  sailsGenerator,
+		clean:      cleanMock("/tmp/sails/defaultProject", nil),
+		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", nil),
+		unzipper:   createMock("/tmp/blank-sails.zip", "/tmp/sails", nil),
+		schema:     schemaMock(defaultProject, "/tmp/sails/defaultProject", nil),
+		zipper:     createMock("/tmp/generated-sails.zip", "/tmp/sails/defaultProject", errors.NewServer("Zip failure")),
+		wantErr:    errors.Wrap(errors.NewServer("Zip failure"), "Failed to zip generated code"),
+	},
+	{
+		name:       "UploadError",
+		generator:  sailsGenerator,
+		clean:      cleanMock("/tmp/sails/defaultProject", nil),
+		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", nil),
+		unzipper:   createMock("/tmp/blank-sails.zip", "/tmp/sails", nil),
+		schema:     schemaMock(defaultProject, "/tmp/sails/defaultProject", nil),
+		zipper:     createMock("/tmp/generated-sails.zip", "/tmp/sails/defaultProject", nil),
+		uploader:   createMock("/tmp/generated-sails.zip", "test@example.com/projectID/sails.zip", errors.NewServer("Upload failure")),
+		wantErr:    errors.Wrap(errors.NewServer("Upload failure"), "Failed to upload generated zip to S3"),
+	},
+	{
+		name:       "SuccessfulInvocation",
+		generator:  sailsGenerator,
+		clean:      cleanMock("/tmp/sails/defaultProject", nil),
+		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", nil),
+		unzipper:   createMock("/tmp/blank-sails.zip", "/tmp/sails", nil),
+		schema:     schemaMock(defaultProject, "/tmp/sails/defaultProject", nil),
+		zipper:     createMock("/tmp/generated-sails.zip", "/tmp/sails/defaultProject", nil),
+		uploader:   createMock("/tmp/generated-sails.zip", "test@example.com/projectID/sails.zip", nil),
+		wantKey:    "test@example.com/projectID/sails.zip",
+	},
+	{
+		name: "GeneratorWithoutTemplate",
+		generator: &generatorMock{
+			name:    "go",
+			project: defaultProject,
+			rootDir: "/tmp/go/defaultProject",
+		},
+		clean:    cleanMock("/tmp/go/defaultProject", nil),
+		schema:   schemaMock(defaultProject, "/tmp/go/defaultProject", nil),
+		zipper:   createMock("/tmp/generated-go.zip", "/tmp/go/defaultProject", nil),
+		uploader: createMock("/tmp/generated-go.zip", "test@example.com/projectID/go.zip", nil),
+		wantKey:  "test@example.com/projectID/go.zip",
+	},
+	{
+		name:      "MigrationError",
+		generator: versionedGenerator,
+		clean:     cleanMock("/tmp/go/defaultProject", nil),
+		schema:    schemaMock(versionedProject, "/tmp/go/defaultProject", nil),
+		project:   versionedProject,
+		base:      previousProject,
+		migration: migrationMock(previousProject, versionedProject, "/tmp/go/defaultProject", errors.NewServer("Migration failure")),
+		wantErr:   errors.Wrap(errors.NewServer("Migration failure"), "Failed to generate database migration"),
+	},
+	{
+		name:      "ProjectWithBaseVersion",
+		generator: versionedGenerator,
+		clean:     cleanMock("/tmp/go/defaultProject", nil),
+		schema:    schemaMock(versionedProject, "/tmp/go/defaultProject", nil),
+		project:   versionedProject,
+		base:      previousProject,
+		migration: migrationMock(previousProject, versionedProject, "/tmp/go/defaultProject", nil),
+		zipper:    createMock("/tmp/generated-go.zip", "/tmp/go/defaultProject", nil),
+		uploader:  createMock("/tmp/generated-go.zip", "test@example.com/projectID/go.zip", nil),
+		wantKey:   "test@example.com/projectID/go.zip",
+	},
+}
+
+func TestBuild(t *testing.T) {
+	for _, test := range buildTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			clean = test.clean
+			download = test.downloader
+			unzip = test.unzipper
+			generateSchema = test.schema
+			generateMigration = test.migration
+			zipper = test.zipper
+			upload = test.uploader
+			project := test.project
+			if project == nil {
+				project = defaultProject
+			}
+
+			// Execute
+			key, err := Build(test.generator, "test@example.com", "projectID", project, test.base)
+
+			// Verify
+			if key != test.wantKey {
+				t.Errorf("Got key %v; want %v", key, test.wantKey)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Package codegen defines the interface implemented by every code generator and a registry that allows
+// generators to be selected by name. Each target framework lives in its own package next to this one and
+// registers its generator from an init function, so that importing the package makes the target available.
+package codegen
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// DefaultTarget is the name of the generator used when the caller does not request a specific target.
+const DefaultTarget = "sails"
+
+// TemplateRoot is the name of the top-level directory contained in every template archive. The generated
+// project is written inside this directory.
+const TemplateRoot = "defaultProject"
+
+// Generator creates the code for a project in a specific target framework.
+type Generator interface {
+	// Name returns the unique name used to select the generator, such as "sails".
+	Name() string
+
+	// TemplateKey returns the S3 key of the zipped blank project that Generate expects to find in rootDir.
+	// If the generator does not need a template, TemplateKey returns an empty string and Generate is passed
+	// an empty directory.
+	TemplateKey() string
+
+	// Generate creates the code for the given project inside the directory specified by rootDir.
+	Generate(project *dao.Project, rootDir string) error
+}
+
+// generators maps the name of each registered generator to the generator itself.
+var generators = make(map[string]Generator)
+
+// Register makes the given generator available by its name. It is intended to be called from the init
+// function of the generator's package. Register panics if the generator is nil or if a generator with the
+// same name has already been registered.
+func Register(generator Generator) {
+	if generator == nil {
+		panic("codegen: Register generator is nil")
+	}
+	name := generator.Name()
+	if _, ok := generators[name]; ok {
+		panic("codegen: Register called twice for generator " + name)
+	}
+	generators[name] = generator
+}
+
+// Lookup returns the registered generator with the given name. If name is empty, the generator for
+// DefaultTarget is returned. If no generator has the given name, a client error is returned.
+func Lookup(name string) (Generator, error) {
+	if name == "" {
+		name = DefaultTarget
+	}
+	generator, ok := generators[name]
+	if !ok {
+		return nil, errors.NewClient("Unsupported target `" + name + "`. Supported targets are: " + strings.Join(Names(), ", "))
+	}
+	return generator, nil
+}
+
+// Names returns the sorted names of every registered generator.
+func Names() []string {
+	names := make([]string, 0, len(generators))
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SortedIDs returns the IDs of the objects of the given project in sorted order, so that generated files that
+// list every object do not depend on map iteration order. A nil project has no objects.
+func SortedIDs(project *dao.Project) []string {
+	if project == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(project.Objects))
+	for id := range project.Objects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SortedObjects returns the objects of the given project sorted by ID. A nil project has no objects.
+func SortedObjects(project *dao.Project) []*dao.Object {
+	ids := SortedIDs(project)
+	objects := make([]*dao.Object, 0, len(ids))
+	for _, id := range ids {
+		objects = append(objects, project.Objects[id])
+	}
+	return objects
+}
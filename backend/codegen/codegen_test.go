@@ -0,0 +1,126 @@
+package codegen
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type mockGenerator struct {
+	name string
+}
+
+func (mock mockGenerator) Name() string {
+	return mock.name
+}
+
+func (mock mockGenerator) TemplateKey() string {
+	return "templates/" + mock.name + ".zip"
+}
+
+func (mock mockGenerator) Generate(project *dao.Project, rootDir string) error {
+	return nil
+}
+
+var lookupTests = []struct {
+	name string
+
+	// Input
+	registered []Generator
+	target     string
+
+	// Expected output
+	wantGenerator Generator
+	wantErr       error
+}{
+	{
+		name:          "DefaultTarget",
+		registered:    []Generator{mockGenerator{"sails"}, mockGenerator{"express"}},
+		wantGenerator: mockGenerator{"sails"},
+	},
+	{
+		name:          "NamedTarget",
+		registered:    []Generator{mockGenerator{"sails"}, mockGenerator{"express"}},
+		target:        "express",
+		wantGenerator: mockGenerator{"express"},
+	},
+	{
+		name:       "UnsupportedTarget",
+		registered: []Generator{mockGenerator{"sails"}, mockGenerator{"express"}},
+		target:     "rails",
+		wantErr:    errors.NewClient("Unsupported target `rails`. Supported targets are: express, sails"),
+	},
+}
+
+func TestLookup(t *testing.T) {
+	for _, test := range lookupTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			generators = make(map[string]Generator)
+			for _, generator := range test.registered {
+				Register(generator)
+			}
+
+			// Execute
+			generator, err := Lookup(test.target)
+
+			// Verify
+			if !reflect.DeepEqual(generator, test.wantGenerator) {
+				t.Errorf("Got generator %v; want %v", generator, test.wantGenerator)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	// Setup
+	generators = make(map[string]Generator)
+	Register(mockGenerator{"sails"})
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Register did not panic on duplicate generator")
+		}
+	}()
+
+	// Execute
+	Register(mockGenerator{"sails"})
+}
+
+var sortedObjectsTests = []struct {
+	name        string
+	project     *dao.Project
+	wantObjects []*dao.Object
+}{
+	{
+		name:        "NilProject",
+		wantObjects: []*dao.Object{},
+	},
+	{
+		name: "SortedByID",
+		project: &dao.Project{Objects: map[string]*dao.Object{
+			"book":   {ID: "book"},
+			"author": {ID: "author"},
+			"review": {ID: "review"},
+		}},
+		wantObjects: []*dao.Object{{ID: "author"}, {ID: "book"}, {ID: "review"}},
+	},
+}
+
+func TestSortedObjects(t *testing.T) {
+	for _, test := range sortedObjectsTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			objects := SortedObjects(test.project)
+
+			// Verify
+			if !reflect.DeepEqual(objects, test.wantObjects) {
+				t.Errorf("Got objects %v; want %v", objects, test.wantObjects)
+			}
+		})
+	}
+}
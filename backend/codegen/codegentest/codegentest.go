@@ -0,0 +1,42 @@
+// Package codegentest provides utilities for testing code generators.
+package codegentest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// CompareGolden reports an error through t for every file in goldenDir whose contents differ from the file
+// at the same relative path in rootDir, or that is missing from rootDir. Files in rootDir that have no golden
+// counterpart are ignored.
+func CompareGolden(t *testing.T, goldenDir string, rootDir string) {
+	t.Helper()
+	err := filepath.Walk(goldenDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(goldenDir, path)
+		if err != nil {
+			return err
+		}
+
+		want, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		got, err := ioutil.ReadFile(filepath.Join(rootDir, relPath))
+		if err != nil {
+			t.Errorf("Failed to read generated file %s: %v", relPath, err)
+			return nil
+		}
+		if string(got) != string(want) {
+			t.Errorf("Got incorrect text from %s:\n%s\nwant:\n%s", relPath, got, want)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Error reading golden files: %v", err)
+	}
+}
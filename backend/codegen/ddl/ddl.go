@@ -0,0 +1,111 @@
+// Package ddl generates the SQL statements that create the tables of a project in PostgreSQL, MySQL and
+// SQLite. The generated schema files can be applied with any migration tooling, for teams that do not let
+// their framework create the tables automatically.
+package ddl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// Dir is the directory, relative to the root of the generated code, that contains the schema files.
+const Dir = "db"
+
+// WriteTable writes the CREATE TABLE statement of the given object to the given writer. The table has an
+// auto-incrementing `id` primary key column followed by a column for each attribute.
+func WriteTable(writer io.Writer, object *dao.Object, dialect *Dialect) error {
+	if object == nil {
+		return errors.NewServer("Object cannot be nil")
+	}
+	return WriteTableAs(writer, object, dialect, TableName(object))
+}
+
+// WriteTableAs writes the CREATE TABLE statement of the given object to the given writer, using the given
+// table name instead of the object's usual table name. Attributes that are not stored are skipped.
+func WriteTableAs(writer io.Writer, object *dao.Object, dialect *Dialect, table string) error {
+	if object == nil {
+		return errors.NewServer("Object cannot be nil")
+	}
+
+	_, err := fmt.Fprintf(writer, "CREATE TABLE %s (\n    %s %s", dialect.Quote(table), dialect.Quote("id"), dialect.primaryKey)
+	if err != nil {
+		return errors.Wrap(err, "Failed to write string")
+	}
+
+	for _, attribute := range object.Attributes {
+		if attribute != nil && !Stored(attribute) {
+			continue
+		}
+		column, err := dialect.Column(TableName(object), attribute)
+		if err != nil {
+			return errors.Wrap(err, "Failed to create column")
+		}
+		_, err = io.WriteString(writer, ",\n    "+column)
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+
+	_, err = io.WriteString(writer, "\n);\n")
+	return errors.Wrap(err, "Failed to write string")
+}
+
+// WriteSchema writes the statements that create every table of the given project in the given dialect
+// to the given writer.
+func WriteSchema(writer io.Writer, project *dao.Project, dialect *Dialect) error {
+	if project == nil {
+		return errors.NewServer("Project cannot be nil")
+	}
+
+	_, err := fmt.Fprintf(writer, "-- %s/%s.sql\n-- %s schema generated by CRUD Creator.\n", Dir, dialect.Name, dialect.Title)
+	if err != nil {
+		return errors.Wrap(err, "Failed to write string")
+	}
+
+	for _, object := range codegen.SortedObjects(project) {
+		_, err = io.WriteString(writer, "\n")
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+		err = WriteTable(writer, object, dialect)
+		if err != nil {
+			return errors.Wrap(err, "Failed to write table for object "+object.Name)
+		}
+	}
+	return nil
+}
+
+// Generate writes the schema of the given project in every supported dialect to the db directory inside
+// rootDir. Each dialect's schema is written to its own file, such as db/postgres.sql.
+func Generate(project *dao.Project, rootDir string) error {
+	dir := filepath.Join(rootDir, Dir)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create "+Dir+" directory")
+	}
+
+	for _, dialect := range Dialects() {
+		err = generateSchema(project, dialect, dir)
+		if err != nil {
+			return errors.Wrap(err, "Failed to generate "+dialect.Title+" schema")
+		}
+	}
+	return nil
+}
+
+func generateSchema(project *dao.Project, dialect *Dialect, dir string) error {
+	file, err := os.Create(filepath.Join(dir, dialect.Name+".sql"))
+	if err != nil {
+		return errors.Wrap(err, "Failed to create schema file")
+	}
+	defer file.Close()
+
+	err = WriteSchema(file, project, dialect)
+	return errors.Wrap(err, "Failed to write schema file")
+}
@@ -0,0 +1,198 @@
+package ddl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/codegentest"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+var minLength, maxLength = 1, 80
+var minAge, maxAge = 0.0, 150.0
+
+var project = &dao.Project{
+	Name:        "Default Project",
+	Description: "Test project",
+	Objects: map[string]*dao.Object{
+		"testobject": &dao.Object{
+			ID:          "testobject",
+			Name:        "TestObject",
+			CodeName:    "TestObject",
+			Description: "This is a description of testobject.",
+			Attributes: []*dao.Attribute{
+				{
+					Name:     "testAttribute",
+					CodeName: "testAttribute",
+					Type:     "Integer",
+				},
+				{
+					Name:     "author",
+					CodeName: "author",
+					Type:     "BelongsTo",
+					Target:   "author",
+				},
+			},
+		},
+		"author": &dao.Object{
+			ID:          "author",
+			Name:        "Author",
+			CodeName:    "Author",
+			Description: "This is a description of author.",
+			Attributes: []*dao.Attribute{
+				{
+					Name:     "testObjects",
+					CodeName: "testObjects",
+					Type:     "HasMany",
+					Target:   "testobject",
+					Via:      "author",
+				},
+				{
+					Name:      "name",
+					CodeName:  "name",
+					Type:      "Text",
+					Required:  true,
+					MinLength: &minLength,
+					MaxLength: &maxLength,
+					Pattern:   `^[A-Z]\w*'?`,
+					Unique:    true,
+				},
+				{
+					Name:     "age",
+					CodeName: "age",
+					Type:     "Integer",
+					Required: true,
+					Min:      &minAge,
+					Max:      &maxAge,
+					Default:  "18",
+				},
+				{
+					Name:     "active",
+					CodeName: "active",
+					Type:     "Boolean",
+					Default:  "true",
+				},
+				{
+					Name:     "joined",
+					CodeName: "joined",
+					Type:     "DateTime",
+					Default:  "2020-01-02T03:04:05-05:00",
+				},
+				{
+					Name:     "email",
+					CodeName: "email",
+					Type:     "Email",
+				},
+				{
+					Name:     "profile",
+					CodeName: "profile",
+					Type:     "JSON",
+				},
+				{
+					Name:     "genre",
+					CodeName: "genre",
+					Type:     "Enum",
+					Values:   []string{"fiction", "non-fiction"},
+					Required: true,
+				},
+			},
+		},
+	},
+}
+
+func TestGenerate(t *testing.T) {
+	// Setup
+	rootDir, err := ioutil.TempDir("", "ddl")
+	if err != nil {
+		t.Fatalf("Error creating temp directory: %v", err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	// Execute
+	err = Generate(project, rootDir)
+	if err != nil {
+		t.Errorf("Got error generating schemas: %v", err)
+	}
+
+	// Verify
+	codegentest.CompareGolden(t, filepath.Join("testdata", "golden"), rootDir)
+}
+
+var writeTableTests = []struct {
+	name       string
+	object     *dao.Object
+	dialect    *Dialect
+	wantErr    error
+	wantString string
+}{
+	{
+		name:    "NilObject",
+		dialect: Postgres,
+		wantErr: errors.NewServer("Object cannot be nil"),
+	},
+	{
+		name:    "NilAttribute",
+		object:  &dao.Object{ID: "book", Name: "Book", CodeName: "Book", Attributes: []*dao.Attribute{nil}},
+		dialect: Postgres,
+		wantErr: errors.Wrap(errors.NewServer("Attribute cannot be nil"), "Failed to create column"),
+	},
+	{
+		name: "InvalidAttributeType",
+		object: &dao.Object{ID: "book", Name: "Book", CodeName: "Book", Attributes: []*dao.Attribute{
+			{Name: "title", CodeName: "title", Type: "InvalidType"},
+		}},
+		dialect: Postgres,
+		wantErr: errors.Wrap(errors.NewServer("Invalid attribute type: InvalidType"), "Failed to create column"),
+	},
+	{
+		name: "EnumWithoutValues",
+		object: &dao.Object{ID: "book", Name: "Book", CodeName: "Book", Attributes: []*dao.Attribute{
+			{Name: "genre", CodeName: "genre", Type: "Enum"},
+		}},
+		dialect: Postgres,
+		wantErr: errors.Wrap(errors.NewServer("Attribute genre does not have allowed values"), "Failed to create column"),
+	},
+	{
+		name:       "NoAttributes",
+		object:     &dao.Object{ID: "book", Name: "Book", CodeName: "Book"},
+		dialect:    SQLite,
+		wantString: "CREATE TABLE \"book\" (\n    \"id\" INTEGER PRIMARY KEY AUTOINCREMENT\n);\n",
+	},
+	{
+		name: "MySQL",
+		object: &dao.Object{ID: "book", Name: "Book", CodeName: "Book", Attributes: []*dao.Attribute{
+			{Name: "title", CodeName: "title", Type: "Text", Required: true},
+			{Name: "pages", CodeName: "pages", Type: "Integer"},
+		}},
+		dialect: MySQL,
+		wantString: "CREATE TABLE `book` (\n" +
+			"    `id` BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,\n" +
+			"    `title` TEXT NOT NULL,\n" +
+			"    `pages` BIGINT\n" +
+			");\n",
+	},
+}
+
+func TestWriteTable(t *testing.T) {
+	for _, test := range writeTableTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			builder := &strings.Builder{}
+
+			// Execute
+			err := WriteTable(builder, test.object, test.dialect)
+
+			// Verify
+			if test.wantErr == nil && builder.String() != test.wantString {
+				t.Errorf("Got string:\n%s\nwant:\n%s", builder.String(), test.wantString)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
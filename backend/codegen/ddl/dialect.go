@@ -0,0 +1,324 @@
+package ddl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/types"
+)
+
+// Dialect describes how a specific SQL database spells the parts of a schema.
+type Dialect struct {
+	// Name is the name of the dialect, which is also the base name of its schema file.
+	Name string
+
+	// Title is the human-readable name of the database.
+	Title string
+
+	// quote is the character used to quote identifiers.
+	quote string
+
+	// primaryKey is the type and constraints of the auto-incrementing primary key column.
+	primaryKey string
+
+	// column selects the representation of an attribute type in the dialect from the types catalog.
+	column func(types.SQL) types.Column
+
+	// length is the function that returns the number of characters in a string.
+	length string
+
+	// match returns the condition that the given column matches the given quoted pattern. It is nil if the
+	// dialect does not support regular expressions without an extension.
+	match func(column, pattern string) string
+
+	// booleans are the literals of false and true.
+	booleans [2]string
+
+	// escapeBackslashes is true if backslashes in string literals must be escaped.
+	escapeBackslashes bool
+
+	// expressionDefaults is true if default values must be written as expressions, which also allows
+	// TEXT columns to have default values.
+	expressionDefaults bool
+
+	// timestamp is the layout of DateTime literals in UTC, or empty if they are written as given.
+	timestamp string
+
+	// uniqueText is the column type of unique TEXT columns, or empty if TEXT columns can be unique.
+	uniqueText string
+}
+
+// The dialects for which schemas are generated.
+var (
+	Postgres = &Dialect{
+		Name:       "postgres",
+		Title:      "PostgreSQL",
+		quote:      `"`,
+		primaryKey: "BIGSERIAL PRIMARY KEY",
+		column:     func(sql types.SQL) types.Column { return sql.Postgres },
+		length:     "char_length",
+		match:      func(column, pattern string) string { return column + " ~ " + pattern },
+		booleans:   [2]string{"FALSE", "TRUE"},
+	}
+	MySQL = &Dialect{
+		Name:       "mysql",
+		Title:      "MySQL",
+		quote:      "`",
+		primaryKey: "BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY",
+		column:     func(sql types.SQL) types.Column { return sql.MySQL },
+		length:     "CHAR_LENGTH",
+		match:      func(column, pattern string) string { return "REGEXP_LIKE(" + column + ", " + pattern + ")" },
+		booleans:   [2]string{"FALSE", "TRUE"},
+
+		escapeBackslashes:  true,
+		expressionDefaults: true,
+		timestamp:          "2006-01-02 15:04:05",
+		uniqueText:         "VARCHAR(255)",
+	}
+	SQLite = &Dialect{
+		Name:       "sqlite",
+		Title:      "SQLite",
+		quote:      `"`,
+		primaryKey: "INTEGER PRIMARY KEY AUTOINCREMENT",
+		column:     func(sql types.SQL) types.Column { return sql.SQLite },
+		length:     "length",
+		booleans:   [2]string{"0", "1"},
+	}
+)
+
+// Dialects returns every supported dialect.
+func Dialects() []*Dialect {
+	return []*Dialect{Postgres, MySQL, SQLite}
+}
+
+// Quote returns the given identifier quoted for use in a statement of the dialect.
+func (dialect *Dialect) Quote(identifier string) string {
+	return dialect.quote + strings.Replace(identifier, dialect.quote, dialect.quote+dialect.quote, -1) + dialect.quote
+}
+
+// lookup returns the column of the given attribute type in the dialect.
+func (dialect *Dialect) lookup(attributeType string) (*types.Type, types.Column, error) {
+	t, err := types.Lookup(attributeType)
+	if err != nil {
+		return nil, types.Column{}, errors.NewServer("Invalid attribute type: " + attributeType)
+	}
+	return t, dialect.column(t.SQL), nil
+}
+
+// ColumnType returns the column type of the given attribute type.
+func (dialect *Dialect) ColumnType(attributeType string) (string, error) {
+	_, column, err := dialect.lookup(attributeType)
+	return column.Type, err
+}
+
+// columnType returns the column type of the given attribute, which differs from the column type of its
+// attribute type when the dialect cannot enforce the uniqueness of that type.
+func (dialect *Dialect) columnType(attribute *dao.Attribute) (string, error) {
+	_, column, err := dialect.lookup(attribute.Type)
+	if err != nil {
+		return "", err
+	}
+	if attribute.Unique && column.Type == "TEXT" && dialect.uniqueText != "" {
+		return dialect.uniqueText, nil
+	}
+	return column.Type, nil
+}
+
+// QuoteString returns the given value as a string literal of the dialect.
+func (dialect *Dialect) QuoteString(value string) string {
+	if dialect.escapeBackslashes {
+		value = strings.Replace(value, `\`, `\\`, -1)
+	}
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
+
+// Default returns the literal of the default value of the given attribute, or an empty string if the
+// attribute does not have a default value.
+func (dialect *Dialect) Default(attribute *dao.Attribute) (string, error) {
+	t, _, err := dialect.lookup(attribute.Type)
+	if err != nil || attribute.Default == "" {
+		return "", err
+	}
+	value, ok := t.Default(attribute)
+	if !ok {
+		return "", errors.NewServer("Attribute " + attribute.Name + " has an invalid default value")
+	}
+
+	var literal string
+	switch v := value.(type) {
+	case int64:
+		literal = strconv.FormatInt(v, 10)
+	case float64:
+		literal = types.FormatNumber(v)
+	case bool:
+		literal = dialect.booleans[0]
+		if v {
+			literal = dialect.booleans[1]
+		}
+	case string:
+		if t.Name == "DateTime" && dialect.timestamp != "" {
+			timestamp, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return "", errors.NewServer("Attribute " + attribute.Name + " has an invalid default value")
+			}
+			v = timestamp.UTC().Format(dialect.timestamp)
+		}
+		literal = dialect.QuoteString(v)
+	default:
+		return "", errors.NewServer("Attribute " + attribute.Name + " has an invalid default value")
+	}
+
+	if dialect.expressionDefaults {
+		literal = "(" + literal + ")"
+	}
+	return literal, nil
+}
+
+// DefaultValue returns the default value given to existing rows when the column of the given attribute
+// becomes NOT NULL. Attributes with a default value use it, and attributes with allowed values otherwise
+// default to their first allowed value. MySQL requires TEXT defaults to be written as expressions.
+func (dialect *Dialect) DefaultValue(attribute *dao.Attribute) (string, error) {
+	t, column, err := dialect.lookup(attribute.Type)
+	if err != nil {
+		return "", err
+	}
+	if attribute.Default != "" {
+		return dialect.Default(attribute)
+	}
+	if t.HasValues {
+		if len(attribute.Values) == 0 {
+			return "", errors.NewServer("Attribute " + attribute.Name + " does not have allowed values")
+		}
+		return dialect.QuoteString(attribute.Values[0]), nil
+	}
+	return column.Default, nil
+}
+
+// conditions returns the conditions that the column of the given attribute must satisfy: membership in its
+// allowed values, its range, its length and its pattern. Patterns are not enforced by dialects without
+// regular expressions.
+func (dialect *Dialect) conditions(t *types.Type, attribute *dao.Attribute) ([]string, error) {
+	column := dialect.Quote(attribute.CodeName)
+	var conditions []string
+
+	if t.HasValues {
+		if len(attribute.Values) == 0 {
+			return nil, errors.NewServer("Attribute " + attribute.Name + " does not have allowed values")
+		}
+		values := make([]string, 0, len(attribute.Values))
+		for _, value := range attribute.Values {
+			values = append(values, dialect.QuoteString(value))
+		}
+		conditions = append(conditions, fmt.Sprintf("%s IN (%s)", column, strings.Join(values, ", ")))
+	}
+	if attribute.Min != nil {
+		conditions = append(conditions, fmt.Sprintf("%s >= %s", column, types.FormatNumber(*attribute.Min)))
+	}
+	if attribute.Max != nil {
+		conditions = append(conditions, fmt.Sprintf("%s <= %s", column, types.FormatNumber(*attribute.Max)))
+	}
+	if attribute.MinLength != nil {
+		conditions = append(conditions, fmt.Sprintf("%s(%s) >= %d", dialect.length, column, *attribute.MinLength))
+	}
+	if attribute.MaxLength != nil {
+		conditions = append(conditions, fmt.Sprintf("%s(%s) <= %d", dialect.length, column, *attribute.MaxLength))
+	}
+	if attribute.Pattern != "" && dialect.match != nil {
+		conditions = append(conditions, dialect.match(column, dialect.QuoteString(attribute.Pattern)))
+	}
+	return conditions, nil
+}
+
+// Check returns the named CHECK constraint that restricts the column of the given attribute in the given
+// table to its allowed values, range, length and pattern, or an empty string if the attribute does not have
+// any of them. The constraint is named after the table and column so that migrations can drop it.
+func (dialect *Dialect) Check(table string, attribute *dao.Attribute) (string, error) {
+	t, _, err := dialect.lookup(attribute.Type)
+	if err != nil {
+		return "", err
+	}
+	conditions, err := dialect.conditions(t, attribute)
+	if err != nil || len(conditions) == 0 {
+		return "", err
+	}
+	name := dialect.Quote(table + "_" + attribute.CodeName + "_check")
+	return fmt.Sprintf("CONSTRAINT %s CHECK (%s)", name, strings.Join(conditions, " AND ")), nil
+}
+
+// Definition returns the name, type, default value and nullability of the column that stores the given
+// attribute, without its UNIQUE and CHECK constraints.
+func (dialect *Dialect) Definition(attribute *dao.Attribute) (string, error) {
+	if attribute == nil {
+		return "", errors.NewServer("Attribute cannot be nil")
+	}
+
+	columnType, err := dialect.columnType(attribute)
+	if err != nil {
+		return "", err
+	}
+	column := dialect.Quote(attribute.CodeName) + " " + columnType
+
+	value, err := dialect.Default(attribute)
+	if err != nil {
+		return "", err
+	}
+	if value != "" {
+		column += " DEFAULT " + value
+	}
+	if attribute.Required {
+		column += " NOT NULL"
+	}
+	return column, nil
+}
+
+// Constraints returns the UNIQUE and CHECK constraints of the column that stores the given attribute in the
+// given table, or an empty string if the column does not have any.
+func (dialect *Dialect) Constraints(table string, attribute *dao.Attribute) (string, error) {
+	var constraints []string
+	if attribute.Unique {
+		constraints = append(constraints, "UNIQUE")
+	}
+	check, err := dialect.Check(table, attribute)
+	if err != nil {
+		return "", err
+	}
+	if check != "" {
+		constraints = append(constraints, check)
+	}
+	return strings.Join(constraints, " "), nil
+}
+
+// Column returns the definition of the column that stores the given attribute in the given table, including
+// its UNIQUE and CHECK constraints.
+func (dialect *Dialect) Column(table string, attribute *dao.Attribute) (string, error) {
+	column, err := dialect.Definition(attribute)
+	if err != nil {
+		return "", err
+	}
+	constraints, err := dialect.Constraints(table, attribute)
+	if err != nil {
+		return "", err
+	}
+	if constraints != "" {
+		column += " " + constraints
+	}
+	return column, nil
+}
+
+// Stored returns true if the given attribute is stored in a column. Collection attributes are derived from the
+// BelongsTo attributes of other objects, so they do not have a column. Attributes of unknown types are reported
+// as stored so that generating their column fails.
+func Stored(attribute *dao.Attribute) bool {
+	t, err := types.Lookup(attribute.Type)
+	return err != nil || !t.Collection()
+}
+
+// TableName returns the name of the table that stores the records of the given object. It matches the
+// table names used by the generated code.
+func TableName(object *dao.Object) string {
+	return strings.ToLower(object.CodeName)
+}
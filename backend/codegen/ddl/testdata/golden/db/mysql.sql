@@ -0,0 +1,19 @@
+-- db/mysql.sql
+-- MySQL schema generated by CRUD Creator.
+
+CREATE TABLE `author` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `name` VARCHAR(255) NOT NULL UNIQUE CONSTRAINT `author_name_check` CHECK (CHAR_LENGTH(`name`) >= 1 AND CHAR_LENGTH(`name`) <= 80 AND REGEXP_LIKE(`name`, '^[A-Z]\\w*''?')),
+    `age` BIGINT DEFAULT (18) NOT NULL CONSTRAINT `author_age_check` CHECK (`age` >= 0 AND `age` <= 150),
+    `active` BOOLEAN DEFAULT (TRUE),
+    `joined` DATETIME DEFAULT ('2020-01-02 08:04:05'),
+    `email` VARCHAR(320),
+    `profile` JSON,
+    `genre` VARCHAR(255) NOT NULL CONSTRAINT `author_genre_check` CHECK (`genre` IN ('fiction', 'non-fiction'))
+);
+
+CREATE TABLE `testobject` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `testAttribute` BIGINT,
+    `author` BIGINT
+);
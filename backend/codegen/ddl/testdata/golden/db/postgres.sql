@@ -0,0 +1,19 @@
+-- db/postgres.sql
+-- PostgreSQL schema generated by CRUD Creator.
+
+CREATE TABLE "author" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "name" TEXT NOT NULL UNIQUE CONSTRAINT "author_name_check" CHECK (char_length("name") >= 1 AND char_length("name") <= 80 AND "name" ~ '^[A-Z]\w*''?'),
+    "age" BIGINT DEFAULT 18 NOT NULL CONSTRAINT "author_age_check" CHECK ("age" >= 0 AND "age" <= 150),
+    "active" BOOLEAN DEFAULT TRUE,
+    "joined" TIMESTAMP WITH TIME ZONE DEFAULT '2020-01-02T03:04:05-05:00',
+    "email" TEXT,
+    "profile" JSONB,
+    "genre" TEXT NOT NULL CONSTRAINT "author_genre_check" CHECK ("genre" IN ('fiction', 'non-fiction'))
+);
+
+CREATE TABLE "testobject" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "testAttribute" BIGINT,
+    "author" BIGINT
+);
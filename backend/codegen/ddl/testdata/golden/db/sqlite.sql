@@ -0,0 +1,19 @@
+-- db/sqlite.sql
+-- SQLite schema generated by CRUD Creator.
+
+CREATE TABLE "author" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" TEXT NOT NULL UNIQUE CONSTRAINT "author_name_check" CHECK (length("name") >= 1 AND length("name") <= 80),
+    "age" INTEGER DEFAULT 18 NOT NULL CONSTRAINT "author_age_check" CHECK ("age" >= 0 AND "age" <= 150),
+    "active" INTEGER DEFAULT 1,
+    "joined" TEXT DEFAULT '2020-01-02T03:04:05-05:00',
+    "email" TEXT,
+    "profile" TEXT,
+    "genre" TEXT NOT NULL CONSTRAINT "author_genre_check" CHECK ("genre" IN ('fiction', 'non-fiction'))
+);
+
+CREATE TABLE "testobject" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "testAttribute" INTEGER,
+    "author" INTEGER
+);
@@ -0,0 +1,120 @@
+package express
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// appHeader is written at the start of app.js, before the routers of every object are mounted.
+const appHeader = `// app.js
+
+const express = require('express');
+const mongoose = require('mongoose');
+
+const app = express();
+app.use(express.json());
+
+// Routes generated by CRUD Creator
+`
+
+// appFooterTemplate is written at the end of app.js, after the routers of every object are mounted. The
+// package name of the project is substituted for the %[1]s verb and used as the name of the default database.
+const appFooterTemplate = `
+app.use((err, req, res, next) => {
+	if (err instanceof mongoose.Error.ValidationError || err instanceof mongoose.Error.CastError) {
+		return res.status(400).json({ error: err.message });
+	}
+	console.error(err);
+	res.status(500).json({ error: 'Internal server error' });
+});
+
+const port = process.env.PORT || 3000;
+const databaseURL = process.env.MONGODB_URI || 'mongodb://localhost:27017/%[1]s';
+
+mongoose.connect(databaseURL, { useNewUrlParser: true, useUnifiedTopology: true })
+	.then(() => {
+		app.listen(port, () => console.log('Listening on port ' + port));
+	})
+	.catch((err) => {
+		console.error(err);
+		process.exit(1);
+	});
+`
+
+// packageTemplate is the package.json written for the project. The package name of the project is
+// substituted for the %[1]s verb and its JSON encoded description for the %[2]s verb.
+const packageTemplate = `{
+  "name": "%[1]s",
+  "version": "1.0.0",
+  "description": %[2]s,
+  "main": "app.js",
+  "private": true,
+  "scripts": {
+    "start": "node app.js"
+  },
+  "dependencies": {
+    "express": "^4.17.1",
+    "mongoose": "^5.9.10"
+  }
+}
+`
+
+// invalidPackageChars matches every run of characters that may not appear in an npm package name.
+var invalidPackageChars = regexp.MustCompile("[^a-z0-9]+")
+
+// packageName returns the npm package name of the given project, which is derived from its name.
+func packageName(project *dao.Project) string {
+	name := invalidPackageChars.ReplaceAllString(strings.ToLower(project.Name), "-")
+	name = strings.Trim(name, "-")
+	if name == "" {
+		return "crud-creator-app"
+	}
+	return name
+}
+
+// writeApp writes the app.js entry point of the project to the given writer. The app mounts the router
+// of every given object and connects to MongoDB before listening for requests.
+func writeApp(writer io.Writer, project *dao.Project, objects []*dao.Object) error {
+	if project == nil {
+		return errors.NewServer("Project cannot be nil")
+	}
+
+	_, err := io.WriteString(writer, appHeader)
+	if err != nil {
+		return errors.Wrap(err, "Failed to write string")
+	}
+
+	for _, object := range objects {
+		if object == nil {
+			return errors.NewServer("Object cannot be nil")
+		}
+		_, err = fmt.Fprintf(writer, "app.use('/%[1]s', require('./routes/%[1]s'));\n", routePath(object))
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+
+	_, err = fmt.Fprintf(writer, appFooterTemplate, packageName(project))
+	return errors.Wrap(err, "Failed to write string")
+}
+
+// writePackage writes the package.json of the project to the given writer.
+func writePackage(writer io.Writer, project *dao.Project) error {
+	if project == nil {
+		return errors.NewServer("Project cannot be nil")
+	}
+
+	description, err := json.Marshal(project.Description)
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode project description")
+	}
+
+	_, err = fmt.Fprintf(writer, packageTemplate, packageName(project), description)
+	return errors.Wrap(err, "Failed to write string")
+}
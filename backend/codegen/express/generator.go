@@ -0,0 +1,121 @@
+// Package express generates the code for a project using the Express framework and Mongoose.
+package express
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func init() {
+	codegen.Register(generator{})
+}
+
+// generator implements the codegen.Generator interface for the Express framework.
+type generator struct{}
+
+// Name returns the name used to select the Express generator.
+func (generator) Name() string {
+	return "express"
+}
+
+// TemplateKey returns an empty string, as the Express generator writes the entire app itself.
+func (generator) TemplateKey() string {
+	return ""
+}
+
+// Generate creates the Express code for the given project. See the package-level Generate function.
+func (generator) Generate(project *dao.Project, rootDir string) error {
+	return Generate(project, rootDir)
+}
+
+// Generate creates a runnable Express app for the given project in the directory specified by rootDir. The
+// app contains a Mongoose model and an Express router for each object, an app.js entry point and a package.json.
+func Generate(project *dao.Project, rootDir string) error {
+	objects := codegen.SortedObjects(project)
+
+	err := os.MkdirAll(filepath.Join(rootDir, "models"), os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create models directory")
+	}
+	err = os.MkdirAll(filepath.Join(rootDir, "routes"), os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create routes directory")
+	}
+
+	// Generate objects
+	for _, object := range objects {
+		err = generateModel(object, rootDir)
+		if err != nil {
+			return errors.Wrap(err, "Failed to generate model for object "+object.Name)
+		}
+
+		err = generateRouter(object, rootDir)
+		if err != nil {
+			return errors.Wrap(err, "Failed to generate router for object "+object.Name)
+		}
+	}
+
+	// Generate the app entry point
+	err = generateApp(project, objects, rootDir)
+	if err != nil {
+		return errors.Wrap(err, "Failed to generate app.js")
+	}
+
+	err = generatePackage(project, rootDir)
+	return errors.Wrap(err, "Failed to generate package.json")
+}
+
+// routePath returns the URL path segment under which the CRUD endpoints of the given object are mounted.
+// It matches the paths used by the Sails.js generator.
+func routePath(object *dao.Object) string {
+	return strings.ToLower(object.CodeName)
+}
+
+func generateModel(object *dao.Object, rootDir string) error {
+	file, err := os.Create(filepath.Join(rootDir, "models", object.CodeName+".js"))
+	if err != nil {
+		return errors.Wrap(err, "Failed to create model file for object: "+object.CodeName)
+	}
+	defer file.Close()
+
+	err = writeModel(file, object)
+	return errors.Wrap(err, "Failed to write model file for object: "+object.CodeName)
+}
+
+func generateRouter(object *dao.Object, rootDir string) error {
+	file, err := os.Create(filepath.Join(rootDir, "routes", routePath(object)+".js"))
+	if err != nil {
+		return errors.Wrap(err, "Failed to create router file for object: "+object.CodeName)
+	}
+	defer file.Close()
+
+	err = writeRouter(file, object)
+	return errors.Wrap(err, "Failed to write router file for object: "+object.CodeName)
+}
+
+func generateApp(project *dao.Project, objects []*dao.Object, rootDir string) error {
+	file, err := os.Create(filepath.Join(rootDir, "app.js"))
+	if err != nil {
+		return errors.Wrap(err, "Failed to create app.js file")
+	}
+	defer file.Close()
+
+	err = writeApp(file, project, objects)
+	return errors.Wrap(err, "Failed to write app.js file")
+}
+
+func generatePackage(project *dao.Project, rootDir string) error {
+	file, err := os.Create(filepath.Join(rootDir, "package.json"))
+	if err != nil {
+		return errors.Wrap(err, "Failed to create package.json file")
+	}
+	defer file.Close()
+
+	err = writePackage(file, project)
+	return errors.Wrap(err, "Failed to write package.json file")
+}
@@ -0,0 +1,74 @@
+package express
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/codegentest"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+)
+
+var project = &dao.Project{
+	Name:        "Default Project",
+	Description: "Test project",
+	Objects: map[string]*dao.Object{
+		"testobject": &dao.Object{
+			ID:          "testobject",
+			Name:        "TestObject",
+			CodeName:    "TestObject",
+			Description: "This is a description of testobject.",
+			Attributes: []*dao.Attribute{
+				{
+					Name:     "testAttribute",
+					CodeName: "testAttribute",
+					Type:     "Integer",
+				},
+			},
+		},
+		"author": &dao.Object{
+			ID:          "author",
+			Name:        "Author",
+			CodeName:    "Author",
+			Description: "This is a description of author.",
+			Attributes: []*dao.Attribute{
+				{
+					Name:     "name",
+					CodeName: "name",
+					Type:     "Text",
+					Required: true,
+				},
+			},
+		},
+	},
+}
+
+func TestGenerate(t *testing.T) {
+	// Setup
+	rootDir, err := ioutil.TempDir("", "express")
+	if err != nil {
+		t.Fatalf("Error creating temp directory: %v", err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	// Execute
+	err = Generate(project, rootDir)
+	if err != nil {
+		t.Errorf("Got error generating project: %v", err)
+	}
+
+	// Verify
+	codegentest.CompareGolden(t, filepath.Join("testdata", "golden"), rootDir)
+}
+
+func TestRegistered(t *testing.T) {
+	generator, err := codegen.Lookup("express")
+	if err != nil {
+		t.Fatalf("Got error looking up express generator: %v", err)
+	}
+	if generator.TemplateKey() != "" {
+		t.Errorf("Got template key %s; want empty string", generator.TemplateKey())
+	}
+}
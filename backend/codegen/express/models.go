@@ -0,0 +1,108 @@
+package express
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/types"
+)
+
+// writeAttribute writes the schema path for the given attribute to the given writer. It should only
+// be called when the writer is in the middle of writing a schema definition. Collection attributes
+// do not have a schema path, as they are found by querying the BelongsTo paths of other models.
+func writeAttribute(writer io.Writer, attribute *dao.Attribute) error {
+	if attribute == nil {
+		return errors.NewServer("Attribute cannot be nil")
+	}
+
+	attributeType, err := types.Lookup(attribute.Type)
+	if err != nil {
+		return errors.NewServer("Invalid attribute type: " + attribute.Type)
+	}
+	if attributeType.Collection() {
+		return nil
+	}
+
+	_, err = fmt.Fprintf(writer, "\t%s: {\n\t\ttype: %s,\n", attribute.CodeName, attributeType.Mongoose.Type)
+	if err != nil {
+		return errors.Wrap(err, "Failed to write string")
+	}
+	if attributeType.Mongoose.Match != "" {
+		_, err = fmt.Fprintf(writer, "\t\tmatch: %s,\n", attributeType.Mongoose.Match)
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+	if attributeType.HasValues {
+		_, err = fmt.Fprintf(writer, "\t\tenum: [%s],\n", types.Quote(attribute.Values, "'"))
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+	for _, line := range constraintLines(attribute, attributeType) {
+		_, err = fmt.Fprintf(writer, "\t\t%s,\n", line)
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+	if attribute.Required {
+		_, err = io.WriteString(writer, "\t\trequired: true,\n")
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+	_, err = io.WriteString(writer, "\t},\n")
+	return errors.Wrap(err, "Failed to write string")
+}
+
+// constraintLines returns the Mongoose validators and options that implement the constraints of the given attribute.
+func constraintLines(attribute *dao.Attribute, attributeType *types.Type) []string {
+	var lines []string
+	if attribute.Min != nil {
+		lines = append(lines, "min: "+types.FormatNumber(*attribute.Min))
+	}
+	if attribute.Max != nil {
+		lines = append(lines, "max: "+types.FormatNumber(*attribute.Max))
+	}
+	if attribute.MinLength != nil {
+		lines = append(lines, fmt.Sprintf("minlength: %d", *attribute.MinLength))
+	}
+	if attribute.MaxLength != nil {
+		lines = append(lines, fmt.Sprintf("maxlength: %d", *attribute.MaxLength))
+	}
+	if attribute.Pattern != "" {
+		lines = append(lines, "match: "+codegen.JSRegex(attribute.Pattern))
+	}
+	if attribute.Unique {
+		lines = append(lines, "unique: true")
+	}
+	if value, ok := attributeType.Default(attribute); ok {
+		lines = append(lines, "default: "+codegen.JSLiteral(value))
+	}
+	return lines
+}
+
+// writeModel writes the Mongoose schema and model corresponding to the given object to the given writer.
+func writeModel(writer io.Writer, object *dao.Object) error {
+	if object == nil {
+		return errors.NewServer("Object cannot be nil")
+	}
+
+	_, err := fmt.Fprintf(writer, "// models/%[1]s.js\n\nconst mongoose = require('mongoose');\n\nconst %[1]sSchema = new mongoose.Schema({\n", object.CodeName)
+	if err != nil {
+		return errors.Wrap(err, "Failed to write string")
+	}
+
+	for _, attribute := range object.Attributes {
+		err = writeAttribute(writer, attribute)
+		if err != nil {
+			return errors.Wrap(err, "Failed to write attribute")
+		}
+	}
+
+	_, err = fmt.Fprintf(writer, "});\n\nmodule.exports = mongoose.model('%[1]s', %[1]sSchema);\n", object.CodeName)
+	return errors.Wrap(err, "Failed to write string")
+}
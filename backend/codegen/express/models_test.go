@@ -0,0 +1,238 @@
+package express
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+var zero, five = 0.0, 5.0
+var ten, thirteen = 10, 13
+
+var writeModelTests = []struct {
+	name       string
+	object     *dao.Object
+	wantErr    error
+	wantString string
+}{
+	{
+		name:    "NilObject",
+		wantErr: errors.NewServer("Object cannot be nil"),
+	},
+	{
+		name: "NilAttribute",
+		object: &dao.Object{
+			ID:         "testobject",
+			Name:       "testObject",
+			CodeName:   "TestObject",
+			Attributes: []*dao.Attribute{nil},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Attribute cannot be nil"), "Failed to write attribute"),
+	},
+	{
+		name: "InvalidAttributeType",
+		object: &dao.Object{
+			ID:       "testobject",
+			Name:     "testObject",
+			CodeName: "TestObject",
+			Attributes: []*dao.Attribute{
+				{
+					Name:     "testAttribute",
+					CodeName: "testAttribute",
+					Type:     "InvalidType",
+				},
+			},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Invalid attribute type: InvalidType"), "Failed to write attribute"),
+	},
+	{
+		name: "NoAttributes",
+		object: &dao.Object{
+			ID:       "testobject",
+			Name:     "testObject",
+			CodeName: "TestObject",
+		},
+		wantString: "// models/TestObject.js\n" +
+			"\n" +
+			"const mongoose = require('mongoose');\n" +
+			"\n" +
+			"const TestObjectSchema = new mongoose.Schema({\n" +
+			"});\n" +
+			"\n" +
+			"module.exports = mongoose.model('TestObject', TestObjectSchema);\n",
+	},
+	{
+		name: "MultipleAttributes",
+		object: &dao.Object{
+			ID:       "testobject",
+			Name:     "testObject",
+			CodeName: "TestObject",
+			Attributes: []*dao.Attribute{
+				{
+					Name:     "title",
+					CodeName: "title",
+					Type:     "Text",
+					Required: true,
+				},
+				{
+					Name:     "page count",
+					CodeName: "pageCount",
+					Type:     "Integer",
+				},
+			},
+		},
+		wantString: "// models/TestObject.js\n" +
+			"\n" +
+			"const mongoose = require('mongoose');\n" +
+			"\n" +
+			"const TestObjectSchema = new mongoose.Schema({\n" +
+			"\ttitle: {\n" +
+			"\t\ttype: String,\n" +
+			"\t\trequired: true,\n" +
+			"\t},\n" +
+			"\tpageCount: {\n" +
+			"\t\ttype: Number,\n" +
+			"\t},\n" +
+			"});\n" +
+			"\n" +
+			"module.exports = mongoose.model('TestObject', TestObjectSchema);\n",
+	},
+	{
+		name: "RichAttributeTypes",
+		object: &dao.Object{
+			ID:       "article",
+			Name:     "Article",
+			CodeName: "Article",
+			Attributes: []*dao.Attribute{
+				{Name: "publishedAt", CodeName: "publishedAt", Type: "DateTime"},
+				{Name: "website", CodeName: "website", Type: "URL", Required: true},
+				{Name: "metadata", CodeName: "metadata", Type: "JSON"},
+				{Name: "status", CodeName: "status", Type: "Enum", Values: []string{"draft", "published"}},
+			},
+		},
+		wantString: "// models/Article.js\n" +
+			"\n" +
+			"const mongoose = require('mongoose');\n" +
+			"\n" +
+			"const ArticleSchema = new mongoose.Schema({\n" +
+			"\tpublishedAt: {\n" +
+			"\t\ttype: Date,\n" +
+			"\t},\n" +
+			"\twebsite: {\n" +
+			"\t\ttype: String,\n" +
+			"\t\tmatch: /^https?:\\/\\/\\S+$/,\n" +
+			"\t\trequired: true,\n" +
+			"\t},\n" +
+			"\tmetadata: {\n" +
+			"\t\ttype: mongoose.Schema.Types.Mixed,\n" +
+			"\t},\n" +
+			"\tstatus: {\n" +
+			"\t\ttype: String,\n" +
+			"\t\tenum: ['draft', 'published'],\n" +
+			"\t},\n" +
+			"});\n" +
+			"\n" +
+			"module.exports = mongoose.model('Article', ArticleSchema);\n",
+	},
+	{
+		name: "Relationships",
+		object: &dao.Object{
+			ID:       "book",
+			Name:     "Book",
+			CodeName: "Book",
+			Attributes: []*dao.Attribute{
+				{Name: "author", CodeName: "author", Type: "BelongsTo", Target: "author", Required: true},
+				{Name: "reviews", CodeName: "reviews", Type: "HasMany", Target: "review", Via: "book"},
+			},
+		},
+		wantString: "// models/Book.js\n" +
+			"\n" +
+			"const mongoose = require('mongoose');\n" +
+			"\n" +
+			"const BookSchema = new mongoose.Schema({\n" +
+			"\tauthor: {\n" +
+			"\t\ttype: mongoose.Schema.Types.ObjectId,\n" +
+			"\t\trequired: true,\n" +
+			"\t},\n" +
+			"});\n" +
+			"\n" +
+			"module.exports = mongoose.model('Book', BookSchema);\n",
+	},
+	{
+		name: "Constraints",
+		object: &dao.Object{
+			ID:       "book",
+			Name:     "Book",
+			CodeName: "Book",
+			Attributes: []*dao.Attribute{
+				{Name: "rating", CodeName: "rating", Type: "Float", Min: &zero, Max: &five, Default: "2.5", Required: true},
+				{Name: "isbn", CodeName: "isbn", Type: "Text", MinLength: &ten, MaxLength: &thirteen, Pattern: "^[0-9]+$", Unique: true},
+			},
+		},
+		wantString: "// models/Book.js\n" +
+			"\n" +
+			"const mongoose = require('mongoose');\n" +
+			"\n" +
+			"const BookSchema = new mongoose.Schema({\n" +
+			"\trating: {\n" +
+			"\t\ttype: Number,\n" +
+			"\t\tmin: 0,\n" +
+			"\t\tmax: 5,\n" +
+			"\t\tdefault: 2.5,\n" +
+			"\t\trequired: true,\n" +
+			"\t},\n" +
+			"\tisbn: {\n" +
+			"\t\ttype: String,\n" +
+			"\t\tminlength: 10,\n" +
+			"\t\tmaxlength: 13,\n" +
+			"\t\tmatch: /^[0-9]+$/,\n" +
+			"\t\tunique: true,\n" +
+			"\t},\n" +
+			"});\n" +
+			"\n" +
+			"module.exports = mongoose.model('Book', BookSchema);\n",
+	},
+}
+
+func TestWriteModel(t *testing.T) {
+	for _, test := range writeModelTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			builder := &strings.Builder{}
+
+			// Execute
+			err := writeModel(builder, test.object)
+
+			// Verify
+			if test.wantErr == nil && builder.String() != test.wantString {
+				t.Errorf("Got string:\n%s\nwant:\n%s", builder.String(), test.wantString)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+var packageNameTests = []struct {
+	name        string
+	projectName string
+	want        string
+}{
+	{name: "Spaces", projectName: "Default Project", want: "default-project"},
+	{name: "Punctuation", projectName: "  My API (v2)!", want: "my-api-v2"},
+	{name: "NoValidCharacters", projectName: "!!!", want: "crud-creator-app"},
+}
+
+func TestPackageName(t *testing.T) {
+	for _, test := range packageNameTests {
+		t.Run(test.name, func(t *testing.T) {
+			got := packageName(&dao.Project{Name: test.projectName})
+			if got != test.want {
+				t.Errorf("Got package name %s; want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package express
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// routerTemplate is the router definition written for each object. The model name of the object
+// is substituted for every %[1]s verb and the route path of the object for every %[2]s verb.
+const routerTemplate = `// routes/%[2]s.js
+
+const express = require('express');
+const %[1]s = require('../models/%[1]s');
+
+const router = express.Router();
+
+router.get('/', async (req, res, next) => {
+	try {
+		const records = await %[1]s.find();
+		res.json(records);
+	} catch (err) {
+		next(err);
+	}
+});
+
+router.get('/:id', async (req, res, next) => {
+	try {
+		const record = await %[1]s.findById(req.params.id);
+		if (!record) {
+			return res.sendStatus(404);
+		}
+		res.json(record);
+	} catch (err) {
+		next(err);
+	}
+});
+
+router.post('/', async (req, res, next) => {
+	try {
+		const record = await %[1]s.create(req.body);
+		res.status(201).json(record);
+	} catch (err) {
+		next(err);
+	}
+});
+
+router.patch('/:id', async (req, res, next) => {
+	try {
+		const record = await %[1]s.findByIdAndUpdate(req.params.id, req.body, { new: true, runValidators: true });
+		if (!record) {
+			return res.sendStatus(404);
+		}
+		res.json(record);
+	} catch (err) {
+		next(err);
+	}
+});
+
+router.delete('/:id', async (req, res, next) => {
+	try {
+		const record = await %[1]s.findByIdAndDelete(req.params.id);
+		if (!record) {
+			return res.sendStatus(404);
+		}
+		res.json(record);
+	} catch (err) {
+		next(err);
+	}
+});
+
+module.exports = router;
+`
+
+// writeRouter writes the Express router corresponding to the given object to the given writer. The
+// router has list, get, create, update and delete endpoints.
+func writeRouter(writer io.Writer, object *dao.Object) error {
+	if object == nil {
+		return errors.NewServer("Object cannot be nil")
+	}
+
+	_, err := fmt.Fprintf(writer, routerTemplate, object.CodeName, routePath(object))
+	return errors.Wrap(err, "Failed to write string")
+}
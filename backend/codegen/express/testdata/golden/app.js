@@ -0,0 +1,31 @@
+// app.js
+
+const express = require('express');
+const mongoose = require('mongoose');
+
+const app = express();
+app.use(express.json());
+
+// Routes generated by CRUD Creator
+app.use('/author', require('./routes/author'));
+app.use('/testobject', require('./routes/testobject'));
+
+app.use((err, req, res, next) => {
+	if (err instanceof mongoose.Error.ValidationError || err instanceof mongoose.Error.CastError) {
+		return res.status(400).json({ error: err.message });
+	}
+	console.error(err);
+	res.status(500).json({ error: 'Internal server error' });
+});
+
+const port = process.env.PORT || 3000;
+const databaseURL = process.env.MONGODB_URI || 'mongodb://localhost:27017/default-project';
+
+mongoose.connect(databaseURL, { useNewUrlParser: true, useUnifiedTopology: true })
+	.then(() => {
+		app.listen(port, () => console.log('Listening on port ' + port));
+	})
+	.catch((err) => {
+		console.error(err);
+		process.exit(1);
+	});
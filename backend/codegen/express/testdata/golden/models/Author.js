@@ -0,0 +1,12 @@
+// models/Author.js
+
+const mongoose = require('mongoose');
+
+const AuthorSchema = new mongoose.Schema({
+	name: {
+		type: String,
+		required: true,
+	},
+});
+
+module.exports = mongoose.model('Author', AuthorSchema);
@@ -0,0 +1,11 @@
+// models/TestObject.js
+
+const mongoose = require('mongoose');
+
+const TestObjectSchema = new mongoose.Schema({
+	testAttribute: {
+		type: Number,
+	},
+});
+
+module.exports = mongoose.model('TestObject', TestObjectSchema);
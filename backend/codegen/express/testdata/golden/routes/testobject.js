@@ -0,0 +1,62 @@
+// routes/testobject.js
+
+const express = require('express');
+const TestObject = require('../models/TestObject');
+
+const router = express.Router();
+
+router.get('/', async (req, res, next) => {
+	try {
+		const records = await TestObject.find();
+		res.json(records);
+	} catch (err) {
+		next(err);
+	}
+});
+
+router.get('/:id', async (req, res, next) => {
+	try {
+		const record = await TestObject.findById(req.params.id);
+		if (!record) {
+			return res.sendStatus(404);
+		}
+		res.json(record);
+	} catch (err) {
+		next(err);
+	}
+});
+
+router.post('/', async (req, res, next) => {
+	try {
+		const record = await TestObject.create(req.body);
+		res.status(201).json(record);
+	} catch (err) {
+		next(err);
+	}
+});
+
+router.patch('/:id', async (req, res, next) => {
+	try {
+		const record = await TestObject.findByIdAndUpdate(req.params.id, req.body, { new: true, runValidators: true });
+		if (!record) {
+			return res.sendStatus(404);
+		}
+		res.json(record);
+	} catch (err) {
+		next(err);
+	}
+});
+
+router.delete('/:id', async (req, res, next) => {
+	try {
+		const record = await TestObject.findByIdAndDelete(req.params.id);
+		if (!record) {
+			return res.sendStatus(404);
+		}
+		res.json(record);
+	} catch (err) {
+		next(err);
+	}
+});
+
+module.exports = router;
@@ -0,0 +1,358 @@
+// Package gohttp generates the code for a project as a Go module that uses net/http and database/sql.
+package gohttp
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/types"
+)
+
+func init() {
+	codegen.Register(generator{})
+}
+
+// generator implements the codegen.Generator interface for Go.
+type generator struct{}
+
+// Name returns the name used to select the Go generator.
+func (generator) Name() string {
+	return "go"
+}
+
+// TemplateKey returns an empty string, as the Go generator writes the entire module itself.
+func (generator) TemplateKey() string {
+	return ""
+}
+
+// Generate creates the Go code for the given project. See the package-level Generate function.
+func (generator) Generate(project *dao.Project, rootDir string) error {
+	return Generate(project, rootDir)
+}
+
+// Templates used to generate the module. They are parsed once, when the package is initialized.
+var (
+	goModTmpl    = template.Must(template.New("go.mod").Parse(goModTemplate))
+	goSumTmpl    = template.Must(template.New("go.sum").Parse(goSumTemplate))
+	mainTmpl     = template.Must(template.New("main.go").Parse(mainTemplate))
+	dbTmpl       = template.Must(template.New("db.go").Parse(dbTemplate))
+	driversTmpl  = template.Must(template.New("driver.go").Parse(driversTemplate))
+	httpTmpl     = template.Must(template.New("http.go").Parse(httpTemplate))
+	validateTmpl = template.Must(template.New("validate.go").Parse(validateTemplate))
+	objectTmpl   = template.Must(template.New("object.go").Parse(objectTemplate))
+	readmeTmpl   = template.Must(template.New("README.md").Parse(readmeTemplate))
+)
+
+// drivers lists the database/sql drivers that the generated module can be built with. SQLite is the driver
+// selected when DB_DRIVER is not set, so it is also compiled in when no driver tag is given.
+var drivers = []driverData{
+	{
+		Tag:              "postgres",
+		Constraint:       "postgres",
+		LegacyConstraint: "postgres",
+		Import:           "github.com/lib/pq",
+		Version:          "v1.9.0",
+		Sum:              "h1:L8nSXQQzAYByakOFMTwpjRoHsMJklur4Gi59b6VivR8=",
+		ModSum:           "h1:AlVN5x4E4T544tWzH6hKfbfQvm3HdbOxrmggDNAPY9o=",
+	},
+	{
+		Tag:              "sqlite",
+		Constraint:       "sqlite || !postgres",
+		LegacyConstraint: "sqlite !postgres",
+		Import:           "github.com/mattn/go-sqlite3",
+		Version:          "v1.14.6",
+		Sum:              "h1:dNPt6NO46WmLVt2DLNpwczCmdV5boIZ6g/tlDrlRUbg=",
+		ModSum:           "h1:NyWgC/yNuGj7Q9rpYnZvas74GogHl5/Z4A/KQRfk6bU=",
+	},
+}
+
+// Generate creates a Go module for the given project in the directory specified by rootDir. The module
+// contains a struct, a database store and an HTTP handler for each object, along with a main.go that
+// serves the CRUD endpoints of every object.
+func Generate(project *dao.Project, rootDir string) error {
+	data, err := newProjectData(project)
+	if err != nil {
+		return errors.Wrap(err, "Failed to prepare project")
+	}
+
+	err = writeFile(rootDir, "go.mod", goModTmpl, data, false)
+	if err != nil {
+		return err
+	}
+	err = writeFile(rootDir, "go.sum", goSumTmpl, data, false)
+	if err != nil {
+		return err
+	}
+	err = writeFile(rootDir, "README.md", readmeTmpl, data, false)
+	if err != nil {
+		return err
+	}
+	err = writeFile(rootDir, "main.go", mainTmpl, data, true)
+	if err != nil {
+		return err
+	}
+	err = writeFile(rootDir, "db.go", dbTmpl, data, true)
+	if err != nil {
+		return err
+	}
+	err = writeFile(rootDir, "http.go", httpTmpl, data, true)
+	if err != nil {
+		return err
+	}
+	err = writeFile(rootDir, "validate.go", validateTmpl, validateData{EmailPattern: types.EmailPattern, UUIDPattern: types.UUIDPattern}, true)
+	if err != nil {
+		return err
+	}
+
+	for _, driver := range drivers {
+		err = writeFile(rootDir, "driver_"+driver.Tag+".go", driversTmpl, driver, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, object := range data.Objects {
+		err = writeFile(rootDir, object.Path+"_object.go", objectTmpl, object, true)
+		if err != nil {
+			return errors.Wrap(err, "Failed to generate code for object "+object.Name)
+		}
+	}
+	return nil
+}
+
+// writeFile executes the given template with the given data and writes the result to the file with the
+// given name in rootDir. If isGo is true, the result is formatted with gofmt before it is written.
+func writeFile(rootDir string, name string, tmpl *template.Template, data interface{}, isGo bool) error {
+	var buffer bytes.Buffer
+	err := tmpl.Execute(&buffer, data)
+	if err != nil {
+		return errors.Wrap(err, "Failed to execute template for "+name)
+	}
+
+	contents := buffer.Bytes()
+	if isGo {
+		contents, err = format.Source(contents)
+		if err != nil {
+			return errors.Wrap(err, "Failed to format "+name)
+		}
+	}
+
+	const fileMode os.FileMode = 0644
+	err = ioutil.WriteFile(filepath.Join(rootDir, name), contents, fileMode)
+	return errors.Wrap(err, "Failed to write "+name)
+}
+
+// projectData contains the values substituted into the templates that describe the whole project.
+type projectData struct {
+	Name        string
+	Description string
+	Module      string
+	Objects     []*objectData
+	Drivers     []driverData
+}
+
+// objectData contains the values substituted into the templates that describe a single object.
+type objectData struct {
+	Name        string
+	Description string
+	Type        string
+	Var         string
+	Path        string
+	Table       string
+	Attributes  []*attributeData
+}
+
+// attributeData contains the values substituted into the templates that describe a single attribute.
+// Empty is the expression that is true when the attribute was not set, and Values is the list of allowed
+// values as Go string literals.
+type attributeData struct {
+	Field      string
+	JSON       string
+	Column     string
+	Required   bool
+	GoType     string
+	Empty      string
+	Check      string
+	Message    string
+	Values     string
+	SQLType    string
+	SQLDefault string
+}
+
+// validateData contains the values substituted into the validate.go template.
+type validateData struct {
+	EmailPattern string
+	UUIDPattern  string
+}
+
+// driverData contains the values substituted into the driver template and into the go.mod and go.sum files.
+// Constraint is the build constraint under which the driver is compiled in, and LegacyConstraint is the same
+// constraint in the +build syntax understood by Go 1.16 and earlier.
+type driverData struct {
+	Tag              string
+	Constraint       string
+	LegacyConstraint string
+	Import           string
+	Version          string
+	Sum              string
+	ModSum           string
+}
+
+// invalidModuleChars matches every run of characters that may not appear in the generated module path.
+var invalidModuleChars = regexp.MustCompile("[^a-z0-9]+")
+
+// moduleName returns the module path of the generated code, which is derived from the project name.
+func moduleName(project *dao.Project) string {
+	name := invalidModuleChars.ReplaceAllString(strings.ToLower(project.Name), "-")
+	name = strings.Trim(name, "-")
+	if name == "" {
+		return "crud-creator-app"
+	}
+	return name
+}
+
+// singleLine replaces every line break in the given text with a space, so that it can be used in a comment.
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// newProjectData converts the given project into the values substituted into the templates. Objects are
+// sorted by ID so that the generated code does not depend on map iteration order.
+func newProjectData(project *dao.Project) (*projectData, error) {
+	if project == nil {
+		return nil, errors.NewServer("Project cannot be nil")
+	}
+
+	data := &projectData{
+		Name:        project.Name,
+		Description: project.Description,
+		Module:      moduleName(project),
+		Drivers:     drivers,
+	}
+	for _, id := range codegen.SortedIDs(project) {
+		object, err := newObjectData(project.Objects[id])
+		if err != nil {
+			return nil, err
+		}
+		data.Objects = append(data.Objects, object)
+	}
+	return data, nil
+}
+
+// newObjectData converts the given object into the values substituted into the templates.
+func newObjectData(object *dao.Object) (*objectData, error) {
+	if object == nil {
+		return nil, errors.NewServer("Object cannot be nil")
+	}
+	if object.CodeName == "" {
+		return nil, errors.NewServer("Object " + object.Name + " does not have a code name")
+	}
+
+	path := strings.ToLower(object.CodeName)
+	data := &objectData{
+		Name:        object.Name,
+		Description: singleLine(object.Description),
+		Type:        strings.ToUpper(object.CodeName[:1]) + object.CodeName[1:],
+		Var:         path,
+		Path:        path,
+		Table:       path,
+	}
+	for _, attribute := range object.Attributes {
+		if attribute == nil {
+			return nil, errors.NewServer("Attribute cannot be nil")
+		}
+		if attribute.CodeName == "" {
+			return nil, errors.NewServer("Attribute " + attribute.Name + " does not have a code name")
+		}
+		attrType, err := types.Lookup(attribute.Type)
+		if err != nil {
+			return nil, errors.NewServer("Invalid attribute type: " + attribute.Type)
+		}
+		if attrType.Collection() {
+			// Collections are found by querying the BelongsTo columns of other tables.
+			continue
+		}
+		data.Attributes = append(data.Attributes, newAttributeData(attribute, attrType))
+	}
+	return data, nil
+}
+
+// newAttributeData converts the given attribute of the given type into the values substituted into the templates.
+func newAttributeData(attribute *dao.Attribute, attrType *types.Type) *attributeData {
+	field := strings.ToUpper(attribute.CodeName[:1]) + attribute.CodeName[1:]
+	data := &attributeData{
+		Field:      field,
+		JSON:       attribute.CodeName,
+		Column:     attribute.CodeName,
+		Required:   attribute.Required,
+		GoType:     attrType.Go.Type,
+		Check:      attrType.Go.Check,
+		Message:    attrType.Go.Message,
+		SQLType:    attrType.Go.SQLType,
+		SQLDefault: attrType.Go.SQLDefault,
+	}
+	if attrType.Go.Empty != "" {
+		data.Empty = fmt.Sprintf(attrType.Go.Empty, "record."+field)
+	}
+	if attrType.HasValues {
+		data.Values = types.Quote(attribute.Values, `"`)
+		data.Message = types.Quote(attribute.Values, "'")
+	}
+	return data
+}
+
+// Columns returns the quoted list of columns selected for the object, starting with the ID.
+func (object *objectData) Columns() string {
+	columns := []string{`"id"`}
+	for _, attribute := range object.Attributes {
+		columns = append(columns, `"`+attribute.Column+`"`)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// ScanArgs returns the arguments passed to Scan when reading the columns returned by Columns.
+func (object *objectData) ScanArgs() string {
+	args := []string{"&record.ID"}
+	for _, attribute := range object.Attributes {
+		args = append(args, "&record."+attribute.Field)
+	}
+	return strings.Join(args, ", ")
+}
+
+// InsertColumns returns the quoted list of attribute columns written when creating a record.
+func (object *objectData) InsertColumns() string {
+	return strings.TrimPrefix(object.Columns(), `"id", `)
+}
+
+// Placeholders returns a placeholder for each attribute column.
+func (object *objectData) Placeholders() string {
+	return strings.TrimSuffix(strings.Repeat("?, ", len(object.Attributes)), ", ")
+}
+
+// Assignments returns the SET clause that updates every attribute column.
+func (object *objectData) Assignments() string {
+	assignments := make([]string, 0, len(object.Attributes))
+	for _, attribute := range object.Attributes {
+		assignments = append(assignments, `"`+attribute.Column+`" = ?`)
+	}
+	return strings.Join(assignments, ", ")
+}
+
+// ValueArgs returns the arguments bound to the attribute placeholders.
+func (object *objectData) ValueArgs() string {
+	args := make([]string, 0, len(object.Attributes))
+	for _, attribute := range object.Attributes {
+		args = append(args, "record."+attribute.Field)
+	}
+	return strings.Join(args, ", ")
+}
@@ -0,0 +1,219 @@
+package gohttp
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/codegentest"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+var project = &dao.Project{
+	Name:        "Default Project",
+	Description: "Test project",
+	Objects: map[string]*dao.Object{
+		"testobject": &dao.Object{
+			ID:          "testobject",
+			Name:        "TestObject",
+			CodeName:    "TestObject",
+			Description: "This is a description of testobject.",
+			Attributes: []*dao.Attribute{
+				{
+					Name:     "testAttribute",
+					CodeName: "testAttribute",
+					Type:     "Integer",
+				},
+				{
+					Name:     "author",
+					CodeName: "author",
+					Type:     "BelongsTo",
+					Target:   "author",
+				},
+			},
+		},
+		"author": &dao.Object{
+			ID:          "author",
+			Name:        "Author",
+			CodeName:    "Author",
+			Description: "This is a description\nof author.",
+			Attributes: []*dao.Attribute{
+				{
+					Name:     "testObjects",
+					CodeName: "testObjects",
+					Type:     "HasMany",
+					Target:   "testobject",
+					Via:      "author",
+				},
+				{
+					Name:     "name",
+					CodeName: "name",
+					Type:     "Text",
+					Required: true,
+				},
+				{
+					Name:     "age",
+					CodeName: "age",
+					Type:     "Integer",
+				},
+				{
+					Name:     "email",
+					CodeName: "email",
+					Type:     "Email",
+					Required: true,
+				},
+				{
+					Name:     "website",
+					CodeName: "website",
+					Type:     "URL",
+				},
+				{
+					Name:     "birthday",
+					CodeName: "birthday",
+					Type:     "Date",
+				},
+				{
+					Name:     "profile",
+					CodeName: "profile",
+					Type:     "JSON",
+				},
+				{
+					Name:     "genre",
+					CodeName: "genre",
+					Type:     "Enum",
+					Values:   []string{"fiction", "non-fiction"},
+					Required: true,
+				},
+			},
+		},
+		"main": &dao.Object{
+			ID:       "main",
+			Name:     "main",
+			CodeName: "Main",
+		},
+	},
+}
+
+// generate creates the Go module for the test project in a new temp directory. The caller must
+// remove the directory when it is done.
+func generate(t *testing.T) string {
+	rootDir, err := ioutil.TempDir("", "gohttp")
+	if err != nil {
+		t.Fatalf("Error creating temp directory: %v", err)
+	}
+
+	err = Generate(project, rootDir)
+	if err != nil {
+		os.RemoveAll(rootDir)
+		t.Fatalf("Got error generating project: %v", err)
+	}
+	return rootDir
+}
+
+func TestGenerate(t *testing.T) {
+	// Setup
+	rootDir := generate(t)
+	defer os.RemoveAll(rootDir)
+
+	// Verify
+	codegentest.CompareGolden(t, filepath.Join("testdata", "golden"), rootDir)
+}
+
+// goCommand returns the go command that runs with the given arguments in the given directory without
+// accessing the network.
+func goCommand(goTool string, dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command(goTool, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=on", "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	return cmd
+}
+
+func TestGeneratedModuleBuilds(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found in PATH")
+	}
+
+	// Setup
+	rootDir := generate(t)
+	defer os.RemoveAll(rootDir)
+	output, err := goCommand(goTool, rootDir, "mod", "download").CombinedOutput()
+	if err != nil {
+		t.Skipf("Database drivers are not in the module cache: %s", output)
+	}
+
+	// Execute and verify
+	for _, tags := range []string{"", "sqlite", "postgres"} {
+		t.Run("Tags="+tags, func(t *testing.T) {
+			for _, args := range [][]string{{"vet", "-tags", tags, "./..."}, {"build", "-tags", tags, "-o", os.DevNull, "./..."}} {
+				output, err := goCommand(goTool, rootDir, args...).CombinedOutput()
+				if err != nil {
+					t.Errorf("go %v failed: %v\n%s", args, err, output)
+				}
+			}
+		})
+	}
+}
+
+var generateErrorTests = []struct {
+	name    string
+	project *dao.Project
+	wantErr error
+}{
+	{
+		name:    "NilProject",
+		wantErr: errors.Wrap(errors.NewServer("Project cannot be nil"), "Failed to prepare project"),
+	},
+	{
+		name: "InvalidAttributeType",
+		project: &dao.Project{
+			Name: "Project",
+			Objects: map[string]*dao.Object{
+				"book": &dao.Object{
+					ID:       "book",
+					Name:     "Book",
+					CodeName: "Book",
+					Attributes: []*dao.Attribute{
+						{Name: "title", CodeName: "title", Type: "InvalidType"},
+					},
+				},
+			},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Invalid attribute type: InvalidType"), "Failed to prepare project"),
+	},
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, test := range generateErrorTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			rootDir, err := ioutil.TempDir("", "gohttp")
+			if err != nil {
+				t.Fatalf("Error creating temp directory: %v", err)
+			}
+			defer os.RemoveAll(rootDir)
+
+			// Execute
+			err = Generate(test.project, rootDir)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	generator, err := codegen.Lookup("go")
+	if err != nil {
+		t.Fatalf("Got error looking up go generator: %v", err)
+	}
+	if generator.TemplateKey() != "" {
+		t.Errorf("Got template key %s; want empty string", generator.TemplateKey())
+	}
+}
@@ -0,0 +1,210 @@
+// Code generated by CRUD Creator.
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+)
+
+// Author is a record of the Author object.
+//
+// This is a description of author.
+type Author struct {
+	ID       int64     `json:"id"`
+	Name     string    `json:"name"`
+	Age      int64     `json:"age"`
+	Email    string    `json:"email"`
+	Website  string    `json:"website"`
+	Birthday string    `json:"birthday"`
+	Profile  jsonValue `json:"profile"`
+	Genre    string    `json:"genre"`
+}
+
+// validate returns a message describing the first missing or invalid attribute of the record, or an empty string.
+func (record *Author) validate() string {
+	if record.Name == "" {
+		return "Attribute `name` is required"
+	}
+	if record.Email == "" {
+		return "Attribute `email` is required"
+	}
+	if record.Email != "" && !validEmail(record.Email) {
+		return "Attribute `email` must be a valid email address"
+	}
+	if record.Website != "" && !validURL(record.Website) {
+		return "Attribute `website` must be a valid URL"
+	}
+	if record.Birthday != "" && !validDate(record.Birthday) {
+		return "Attribute `birthday` must be a date in the format YYYY-MM-DD"
+	}
+	if record.Genre == "" {
+		return "Attribute `genre` is required"
+	}
+	if record.Genre != "" && !oneOf(record.Genre, "fiction", "non-fiction") {
+		return "Attribute `genre` must be one of 'fiction', 'non-fiction'"
+	}
+	return ""
+}
+
+// authorStore reads and writes Author records in the database.
+type authorStore struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+// list returns every record, ordered by ID.
+func (store *authorStore) list() ([]*Author, error) {
+	rows, err := store.db.Query(`SELECT "id", "name", "age", "email", "website", "birthday", "profile", "genre" FROM "author" ORDER BY "id"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []*Author{}
+	for rows.Next() {
+		record := &Author{}
+		err = rows.Scan(&record.ID, &record.Name, &record.Age, &record.Email, &record.Website, &record.Birthday, &record.Profile, &record.Genre)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// get returns the record with the given ID, or nil if it does not exist.
+func (store *authorStore) get(id int64) (*Author, error) {
+	record := &Author{}
+	query := store.dialect.rebind(`SELECT "id", "name", "age", "email", "website", "birthday", "profile", "genre" FROM "author" WHERE "id" = ?`)
+	err := store.db.QueryRow(query, id).Scan(&record.ID, &record.Name, &record.Age, &record.Email, &record.Website, &record.Birthday, &record.Profile, &record.Genre)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// create inserts the given record and sets its ID.
+func (store *authorStore) create(record *Author) error {
+	query := store.dialect.rebind(`INSERT INTO "author" ("name", "age", "email", "website", "birthday", "profile", "genre") VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING "id"`)
+	return store.db.QueryRow(query, record.Name, record.Age, record.Email, record.Website, record.Birthday, record.Profile, record.Genre).Scan(&record.ID)
+}
+
+// update saves every attribute of the given record.
+func (store *authorStore) update(record *Author) error {
+	query := store.dialect.rebind(`UPDATE "author" SET "name" = ?, "age" = ?, "email" = ?, "website" = ?, "birthday" = ?, "profile" = ?, "genre" = ? WHERE "id" = ?`)
+	_, err := store.db.Exec(query, record.Name, record.Age, record.Email, record.Website, record.Birthday, record.Profile, record.Genre, record.ID)
+	return err
+}
+
+// delete removes the record with the given ID.
+func (store *authorStore) delete(id int64) error {
+	query := store.dialect.rebind(`DELETE FROM "author" WHERE "id" = ?`)
+	_, err := store.db.Exec(query, id)
+	return err
+}
+
+// registerAuthor registers the CRUD endpoints of Author records with the given mux.
+func registerAuthor(mux *http.ServeMux, db *sql.DB, d dialect) {
+	handler := &authorHandler{store: &authorStore{db: db, dialect: d}}
+	mux.HandleFunc("/author", handler.serveCollection)
+	mux.HandleFunc("/author/", handler.serveItem)
+}
+
+// authorHandler serves the CRUD endpoints of Author records.
+type authorHandler struct {
+	store *authorStore
+}
+
+// serveCollection handles requests to /author.
+func (handler *authorHandler) serveCollection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		records, err := handler.store.list()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, records)
+
+	case http.MethodPost:
+		record := &Author{}
+		err := json.NewDecoder(r.Body).Decode(record)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if message := record.validate(); message != "" {
+			writeError(w, http.StatusBadRequest, message)
+			return
+		}
+		err = handler.store.create(record)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, record)
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// serveItem handles requests to /author/{id}.
+func (handler *authorHandler) serveItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r.URL.Path, "/author/")
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	record, err := handler.store.get(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if record == nil {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, record)
+
+	case http.MethodPatch:
+		err = json.NewDecoder(r.Body).Decode(record)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		record.ID = id
+		if message := record.validate(); message != "" {
+			writeError(w, http.StatusBadRequest, message)
+			return
+		}
+		err = handler.store.update(record)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, record)
+
+	case http.MethodDelete:
+		err = handler.store.delete(id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, record)
+
+	default:
+		w.Header().Set("Allow", "GET, PATCH, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,62 @@
+// Code generated by CRUD Creator.
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// dialect describes the differences between the SQL databases supported by this program.
+type dialect struct {
+	// idColumn is the definition of the auto-incrementing primary key column.
+	idColumn string
+
+	// numbered is true if the driver expects numbered placeholders ($1, $2, ...) instead of ?.
+	numbered bool
+}
+
+// dialects maps the name of each supported database/sql driver to its dialect.
+var dialects = map[string]dialect{
+	"postgres": {idColumn: `"id" BIGSERIAL PRIMARY KEY`, numbered: true},
+	"sqlite3":  {idColumn: `"id" INTEGER PRIMARY KEY AUTOINCREMENT`},
+}
+
+// rebind replaces the ? placeholders in the given query with the placeholders expected by the dialect.
+func (d dialect) rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+	var builder strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// tables contains the CREATE TABLE statement of every object. The %s verb is replaced by the
+// primary key column of the dialect.
+var tables = []string{
+	`CREATE TABLE IF NOT EXISTS "author" (%s, "name" TEXT NOT NULL DEFAULT '', "age" BIGINT NOT NULL DEFAULT 0, "email" TEXT NOT NULL DEFAULT '', "website" TEXT NOT NULL DEFAULT '', "birthday" TEXT NOT NULL DEFAULT '', "profile" TEXT NOT NULL DEFAULT 'null', "genre" TEXT NOT NULL DEFAULT '')`,
+	`CREATE TABLE IF NOT EXISTS "main" (%s)`,
+	`CREATE TABLE IF NOT EXISTS "testobject" (%s, "testAttribute" BIGINT NOT NULL DEFAULT 0, "author" BIGINT NOT NULL DEFAULT 0)`,
+}
+
+// migrate creates the table of every object if it does not already exist.
+func migrate(db *sql.DB, d dialect) error {
+	for _, table := range tables {
+		_, err := db.Exec(fmt.Sprintf(table, d.idColumn))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
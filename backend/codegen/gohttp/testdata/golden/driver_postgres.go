@@ -0,0 +1,8 @@
+//go:build postgres
+// +build postgres
+
+// Code generated by CRUD Creator.
+
+package main
+
+import _ "github.com/lib/pq"
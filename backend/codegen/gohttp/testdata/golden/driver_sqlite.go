@@ -0,0 +1,8 @@
+//go:build sqlite || !postgres
+// +build sqlite !postgres
+
+// Code generated by CRUD Creator.
+
+package main
+
+import _ "github.com/mattn/go-sqlite3"
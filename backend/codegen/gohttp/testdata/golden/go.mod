@@ -0,0 +1,8 @@
+module default-project
+
+go 1.13
+
+require (
+	github.com/lib/pq v1.9.0
+	github.com/mattn/go-sqlite3 v1.14.6
+)
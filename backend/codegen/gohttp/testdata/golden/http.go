@@ -0,0 +1,27 @@
+// Code generated by CRUD Creator.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// writeJSON writes the given value to the response as JSON with the given status code.
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes a JSON object with an error field to the response.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// parseID returns the record ID at the end of the request path, following the given prefix.
+func parseID(path string, prefix string) (int64, error) {
+	return strconv.ParseInt(strings.TrimPrefix(path, prefix), 10, 64)
+}
@@ -0,0 +1,46 @@
+// Code generated by CRUD Creator.
+
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"os"
+)
+
+func main() {
+	driver := getenv("DB_DRIVER", "sqlite3")
+	dialect, ok := dialects[driver]
+	if !ok {
+		log.Fatalf("Unsupported DB_DRIVER %q", driver)
+	}
+
+	db, err := sql.Open(driver, getenv("DATABASE_URL", "default-project.db"))
+	if err != nil {
+		log.Fatalf("Failed to open database: %v (was the program built with the driver's build tag?)", err)
+	}
+	defer db.Close()
+
+	err = migrate(db, dialect)
+	if err != nil {
+		log.Fatalf("Failed to create tables: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	registerAuthor(mux, db, dialect)
+	registerMain(mux, db, dialect)
+	registerTestObject(mux, db, dialect)
+
+	addr := ":" + getenv("PORT", "8080")
+	log.Println("Listening on", addr)
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
+
+// getenv returns the value of the given environment variable, or fallback if it is not set.
+func getenv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,177 @@
+// Code generated by CRUD Creator.
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+)
+
+// Main is a record of the main object.
+type Main struct {
+	ID int64 `json:"id"`
+}
+
+// validate returns a message describing the first missing or invalid attribute of the record, or an empty string.
+func (record *Main) validate() string {
+	return ""
+}
+
+// mainStore reads and writes Main records in the database.
+type mainStore struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+// list returns every record, ordered by ID.
+func (store *mainStore) list() ([]*Main, error) {
+	rows, err := store.db.Query(`SELECT "id" FROM "main" ORDER BY "id"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []*Main{}
+	for rows.Next() {
+		record := &Main{}
+		err = rows.Scan(&record.ID)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// get returns the record with the given ID, or nil if it does not exist.
+func (store *mainStore) get(id int64) (*Main, error) {
+	record := &Main{}
+	query := store.dialect.rebind(`SELECT "id" FROM "main" WHERE "id" = ?`)
+	err := store.db.QueryRow(query, id).Scan(&record.ID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// create inserts the given record and sets its ID.
+func (store *mainStore) create(record *Main) error {
+	return store.db.QueryRow(`INSERT INTO "main" DEFAULT VALUES RETURNING "id"`).Scan(&record.ID)
+}
+
+// update saves every attribute of the given record.
+func (store *mainStore) update(record *Main) error {
+	return nil
+}
+
+// delete removes the record with the given ID.
+func (store *mainStore) delete(id int64) error {
+	query := store.dialect.rebind(`DELETE FROM "main" WHERE "id" = ?`)
+	_, err := store.db.Exec(query, id)
+	return err
+}
+
+// registerMain registers the CRUD endpoints of Main records with the given mux.
+func registerMain(mux *http.ServeMux, db *sql.DB, d dialect) {
+	handler := &mainHandler{store: &mainStore{db: db, dialect: d}}
+	mux.HandleFunc("/main", handler.serveCollection)
+	mux.HandleFunc("/main/", handler.serveItem)
+}
+
+// mainHandler serves the CRUD endpoints of Main records.
+type mainHandler struct {
+	store *mainStore
+}
+
+// serveCollection handles requests to /main.
+func (handler *mainHandler) serveCollection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		records, err := handler.store.list()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, records)
+
+	case http.MethodPost:
+		record := &Main{}
+		err := json.NewDecoder(r.Body).Decode(record)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if message := record.validate(); message != "" {
+			writeError(w, http.StatusBadRequest, message)
+			return
+		}
+		err = handler.store.create(record)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, record)
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// serveItem handles requests to /main/{id}.
+func (handler *mainHandler) serveItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r.URL.Path, "/main/")
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	record, err := handler.store.get(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if record == nil {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, record)
+
+	case http.MethodPatch:
+		err = json.NewDecoder(r.Body).Decode(record)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		record.ID = id
+		if message := record.validate(); message != "" {
+			writeError(w, http.StatusBadRequest, message)
+			return
+		}
+		err = handler.store.update(record)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, record)
+
+	case http.MethodDelete:
+		err = handler.store.delete(id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, record)
+
+	default:
+		w.Header().Set("Allow", "GET, PATCH, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
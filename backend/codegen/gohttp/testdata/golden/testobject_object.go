@@ -0,0 +1,184 @@
+// Code generated by CRUD Creator.
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+)
+
+// TestObject is a record of the TestObject object.
+//
+// This is a description of testobject.
+type TestObject struct {
+	ID            int64 `json:"id"`
+	TestAttribute int64 `json:"testAttribute"`
+	Author        int64 `json:"author"`
+}
+
+// validate returns a message describing the first missing or invalid attribute of the record, or an empty string.
+func (record *TestObject) validate() string {
+	return ""
+}
+
+// testobjectStore reads and writes TestObject records in the database.
+type testobjectStore struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+// list returns every record, ordered by ID.
+func (store *testobjectStore) list() ([]*TestObject, error) {
+	rows, err := store.db.Query(`SELECT "id", "testAttribute", "author" FROM "testobject" ORDER BY "id"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []*TestObject{}
+	for rows.Next() {
+		record := &TestObject{}
+		err = rows.Scan(&record.ID, &record.TestAttribute, &record.Author)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// get returns the record with the given ID, or nil if it does not exist.
+func (store *testobjectStore) get(id int64) (*TestObject, error) {
+	record := &TestObject{}
+	query := store.dialect.rebind(`SELECT "id", "testAttribute", "author" FROM "testobject" WHERE "id" = ?`)
+	err := store.db.QueryRow(query, id).Scan(&record.ID, &record.TestAttribute, &record.Author)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// create inserts the given record and sets its ID.
+func (store *testobjectStore) create(record *TestObject) error {
+	query := store.dialect.rebind(`INSERT INTO "testobject" ("testAttribute", "author") VALUES (?, ?) RETURNING "id"`)
+	return store.db.QueryRow(query, record.TestAttribute, record.Author).Scan(&record.ID)
+}
+
+// update saves every attribute of the given record.
+func (store *testobjectStore) update(record *TestObject) error {
+	query := store.dialect.rebind(`UPDATE "testobject" SET "testAttribute" = ?, "author" = ? WHERE "id" = ?`)
+	_, err := store.db.Exec(query, record.TestAttribute, record.Author, record.ID)
+	return err
+}
+
+// delete removes the record with the given ID.
+func (store *testobjectStore) delete(id int64) error {
+	query := store.dialect.rebind(`DELETE FROM "testobject" WHERE "id" = ?`)
+	_, err := store.db.Exec(query, id)
+	return err
+}
+
+// registerTestObject registers the CRUD endpoints of TestObject records with the given mux.
+func registerTestObject(mux *http.ServeMux, db *sql.DB, d dialect) {
+	handler := &testobjectHandler{store: &testobjectStore{db: db, dialect: d}}
+	mux.HandleFunc("/testobject", handler.serveCollection)
+	mux.HandleFunc("/testobject/", handler.serveItem)
+}
+
+// testobjectHandler serves the CRUD endpoints of TestObject records.
+type testobjectHandler struct {
+	store *testobjectStore
+}
+
+// serveCollection handles requests to /testobject.
+func (handler *testobjectHandler) serveCollection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		records, err := handler.store.list()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, records)
+
+	case http.MethodPost:
+		record := &TestObject{}
+		err := json.NewDecoder(r.Body).Decode(record)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if message := record.validate(); message != "" {
+			writeError(w, http.StatusBadRequest, message)
+			return
+		}
+		err = handler.store.create(record)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, record)
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// serveItem handles requests to /testobject/{id}.
+func (handler *testobjectHandler) serveItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r.URL.Path, "/testobject/")
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	record, err := handler.store.get(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if record == nil {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, record)
+
+	case http.MethodPatch:
+		err = json.NewDecoder(r.Body).Decode(record)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		record.ID = id
+		if message := record.validate(); message != "" {
+			writeError(w, http.StatusBadRequest, message)
+			return
+		}
+		err = handler.store.update(record)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, record)
+
+	case http.MethodDelete:
+		err = handler.store.delete(id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, record)
+
+	default:
+		w.Header().Set("Allow", "GET, PATCH, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,94 @@
+// Code generated by CRUD Creator.
+
+package main
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// validDate returns true if the given value is a date in the format YYYY-MM-DD.
+func validDate(value string) bool {
+	_, err := time.Parse("2006-01-02", value)
+	return err == nil
+}
+
+// validDateTime returns true if the given value is a date and time in RFC 3339 format.
+func validDateTime(value string) bool {
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
+
+// validEmail returns true if the given value looks like an email address.
+func validEmail(value string) bool {
+	return emailPattern.MatchString(value)
+}
+
+// validURL returns true if the given value is an absolute HTTP or HTTPS URL.
+func validURL(value string) bool {
+	u, err := url.ParseRequestURI(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validUUID returns true if the given value is a UUID in its canonical textual form.
+func validUUID(value string) bool {
+	return uuidPattern.MatchString(value)
+}
+
+// oneOf returns true if the given value is one of the allowed values.
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonValue stores an arbitrary JSON value. It is written to the database as text.
+type jsonValue []byte
+
+// MarshalJSON returns the stored JSON value, or null if no value is stored.
+func (value jsonValue) MarshalJSON() ([]byte, error) {
+	if len(value) == 0 {
+		return []byte("null"), nil
+	}
+	return value, nil
+}
+
+// UnmarshalJSON stores a copy of the given JSON value.
+func (value *jsonValue) UnmarshalJSON(data []byte) error {
+	*value = append((*value)[:0], data...)
+	return nil
+}
+
+// Value returns the stored JSON value as text for the database.
+func (value jsonValue) Value() (driver.Value, error) {
+	if len(value) == 0 {
+		return "null", nil
+	}
+	return string(value), nil
+}
+
+// Scan reads a JSON value stored as text in the database.
+func (value *jsonValue) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		*value = jsonValue(src)
+	case []byte:
+		*value = append(jsonValue(nil), src...)
+	case nil:
+		*value = nil
+	default:
+		return errors.New("unsupported type for JSON attribute")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package codegen
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/types"
+)
+
+// jsStringReplacer escapes the characters that may not appear unescaped in a single-quoted JavaScript string.
+var jsStringReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\u2028", `\u2028`, "\u2029", `\u2029`)
+
+// JSLiteral returns the JavaScript literal of the given default value, as returned by types.Type.Default.
+// Strings are single-quoted.
+func JSLiteral(value interface{}) string {
+	switch value := value.(type) {
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return types.FormatNumber(value)
+	case bool:
+		return strconv.FormatBool(value)
+	case string:
+		return "'" + jsStringReplacer.Replace(value) + "'"
+	}
+	return "undefined"
+}
+
+// JSRegex returns the JavaScript regular expression literal that matches the given pattern. Slashes that are
+// not already escaped are escaped so that they do not end the literal.
+func JSRegex(pattern string) string {
+	builder := &strings.Builder{}
+	builder.WriteString("/")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '/':
+			builder.WriteString(`\`)
+		case r == '\n':
+			builder.WriteString(`\n`)
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	builder.WriteString("/")
+	return builder.String()
+}
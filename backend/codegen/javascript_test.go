@@ -0,0 +1,47 @@
+package codegen
+
+import "testing"
+
+var jsLiteralTests = []struct {
+	name  string
+	value interface{}
+	want  string
+}{
+	{name: "Integer", value: int64(-42), want: "-42"},
+	{name: "Float", value: 2.5, want: "2.5"},
+	{name: "LargeFloat", value: 1e21, want: "1e+21"},
+	{name: "Boolean", value: false, want: "false"},
+	{name: "String", value: "it's a \\ test\n", want: `'it\'s a \\ test\n'`},
+	{name: "Unsupported", value: nil, want: "undefined"},
+}
+
+func TestJSLiteral(t *testing.T) {
+	for _, test := range jsLiteralTests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := JSLiteral(test.value); got != test.want {
+				t.Errorf("Got %s; want %s", got, test.want)
+			}
+		})
+	}
+}
+
+var jsRegexTests = []struct {
+	name    string
+	pattern string
+	want    string
+}{
+	{name: "Plain", pattern: `^[A-Z]+$`, want: `/^[A-Z]+$/`},
+	{name: "Slash", pattern: `^a/b$`, want: `/^a\/b$/`},
+	{name: "EscapedSlash", pattern: `^a\/b$`, want: `/^a\/b$/`},
+	{name: "EscapedBackslash", pattern: `^a\\/b$`, want: `/^a\\\/b$/`},
+}
+
+func TestJSRegex(t *testing.T) {
+	for _, test := range jsRegexTests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := JSRegex(test.pattern); got != test.want {
+				t.Errorf("Got %s; want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/types"
+)
+
+// describeAttribute returns the type of the given attribute and whether it is required, such as "Text, required".
+func describeAttribute(attribute *dao.Attribute) string {
+	if attribute.Required {
+		return attribute.Type + ", required"
+	}
+	return attribute.Type + ", optional"
+}
+
+// changelogLines returns a line describing each change in the given diff.
+func changelogLines(diff *Diff) []string {
+	var lines []string
+	for _, object := range diff.AddedObjects {
+		lines = append(lines, fmt.Sprintf("Added object `%s`.", object.Name))
+	}
+	for _, object := range diff.RemovedObjects {
+		lines = append(lines, fmt.Sprintf("Removed object `%s`. Its table and data are dropped.", object.Name))
+	}
+	for _, objectDiff := range diff.ChangedObjects {
+		name := objectDiff.New.Name
+		for _, attribute := range objectDiff.AddedAttributes {
+			lines = append(lines, fmt.Sprintf("Object `%s`: added attribute `%s` (%s).", name, attribute.Name, describeAttribute(attribute)))
+		}
+		for _, attribute := range objectDiff.RemovedAttributes {
+			lines = append(lines, fmt.Sprintf("Object `%s`: removed attribute `%s`.", name, attribute.Name))
+		}
+		for _, change := range objectDiff.ChangedAttributes {
+			if change.TypeChanged() {
+				lines = append(lines, fmt.Sprintf("Object `%s`: changed the type of attribute `%s` from %s to %s.", name, change.New.Name, change.Old.Type, change.New.Type))
+			}
+			if change.ValuesChanged() && len(change.New.Values) > 0 {
+				lines = append(lines, fmt.Sprintf("Object `%s`: attribute `%s` now allows %s.", name, change.New.Name, types.Quote(change.New.Values, "`")))
+			}
+			if change.ConstraintsChanged() {
+				lines = append(lines, fmt.Sprintf("Object `%s`: changed the constraints of attribute `%s`.", name, change.New.Name))
+			}
+			if change.UniqueChanged() && change.New.Unique {
+				lines = append(lines, fmt.Sprintf("Object `%s`: attribute `%s` is now unique.", name, change.New.Name))
+			} else if change.UniqueChanged() {
+				lines = append(lines, fmt.Sprintf("Object `%s`: attribute `%s` is no longer unique.", name, change.New.Name))
+			}
+			if change.DefaultChanged() && change.New.Default != "" {
+				lines = append(lines, fmt.Sprintf("Object `%s`: attribute `%s` now defaults to `%s`.", name, change.New.Name, change.New.Default))
+			} else if change.DefaultChanged() {
+				lines = append(lines, fmt.Sprintf("Object `%s`: attribute `%s` no longer has a default value.", name, change.New.Name))
+			}
+			if change.RequiredChanged() && change.New.Required {
+				lines = append(lines, fmt.Sprintf("Object `%s`: attribute `%s` is now required.", name, change.New.Name))
+			} else if change.RequiredChanged() {
+				lines = append(lines, fmt.Sprintf("Object `%s`: attribute `%s` is no longer required.", name, change.New.Name))
+			}
+		}
+	}
+	return lines
+}
+
+// WriteChangelog writes a human-readable Markdown list of the changes in the given diff to the given writer.
+func WriteChangelog(writer io.Writer, diff *Diff) error {
+	if diff == nil {
+		return errors.NewServer("Diff cannot be nil")
+	}
+
+	_, err := io.WriteString(writer, "# Changelog\n\n")
+	if err != nil {
+		return errors.Wrap(err, "Failed to write string")
+	}
+	if diff.Empty() {
+		_, err = io.WriteString(writer, "No changes.\n")
+		return errors.Wrap(err, "Failed to write string")
+	}
+
+	for _, line := range changelogLines(diff) {
+		_, err = io.WriteString(writer, "- "+line+"\n")
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+	return nil
+}
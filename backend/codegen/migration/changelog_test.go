@@ -0,0 +1,82 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+var writeChangelogTests = []struct {
+	name       string
+	diff       *Diff
+	wantString string
+	wantErr    error
+}{
+	{
+		name:    "NilDiff",
+		wantErr: errors.NewServer("Diff cannot be nil"),
+	},
+	{
+		name:       "EmptyDiff",
+		diff:       &Diff{},
+		wantString: "# Changelog\n\nNo changes.\n",
+	},
+	{
+		name: "Changes",
+		diff: &Diff{
+			AddedObjects:   []*dao.Object{newPublisher},
+			RemovedObjects: []*dao.Object{oldAuthor},
+			ChangedObjects: []*ObjectDiff{bookDiff},
+		},
+		wantString: "# Changelog\n" +
+			"\n" +
+			"- Added object `Publisher`.\n" +
+			"- Removed object `Author`. Its table and data are dropped.\n" +
+			"- Object `Book`: added attribute `rating` (Integer, required).\n" +
+			"- Object `Book`: added attribute `format` (Enum, required).\n" +
+			"- Object `Book`: added attribute `edition` (Integer, required).\n" +
+			"- Object `Book`: removed attribute `summary`.\n" +
+			"- Object `Book`: changed the type of attribute `pages` from Text to Integer.\n" +
+			"- Object `Book`: attribute `pages` is now required.\n" +
+			"- Object `Book`: attribute `isbn` is now required.\n" +
+			"- Object `Book`: attribute `genre` now allows `fiction`, `non-fiction`, `poetry`.\n" +
+			"- Object `Book`: attribute `genre` is now required.\n" +
+			"- Object `Book`: changed the constraints of attribute `price`.\n" +
+			"- Object `Book`: attribute `price` now defaults to `9.99`.\n" +
+			"- Object `Book`: changed the constraints of attribute `slug`.\n" +
+			"- Object `Book`: attribute `slug` is no longer unique.\n",
+	},
+	{
+		name: "NoLongerRequired",
+		diff: &Diff{
+			ChangedObjects: []*ObjectDiff{{
+				Old:               newBook,
+				New:               oldBook,
+				ChangedAttributes: []*AttributeChange{{Old: isbnRequired, New: isbn}},
+			}},
+		},
+		wantString: "# Changelog\n\n- Object `Book`: attribute `isbn` is no longer required.\n",
+	},
+}
+
+func TestWriteChangelog(t *testing.T) {
+	for _, test := range writeChangelogTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			builder := &strings.Builder{}
+
+			// Execute
+			err := WriteChangelog(builder, test.diff)
+
+			// Verify
+			if builder.String() != test.wantString {
+				t.Errorf("Got string:\n%s\nwant:\n%s", builder.String(), test.wantString)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Package migration computes the structural differences between two versions of a project and renders them
+// as SQL migration scripts and as a human-readable changelog, so that deployed databases can be upgraded
+// instead of recreated.
+package migration
+
+import (
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/ddl"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// Diff contains the structural differences between two versions of a project. Objects are matched by ID,
+// so renaming an object is reported as removing the old object and adding a new one.
+type Diff struct {
+	AddedObjects   []*dao.Object
+	RemovedObjects []*dao.Object
+	ChangedObjects []*ObjectDiff
+}
+
+// ObjectDiff contains the differences between two versions of an object with the same ID. Attributes are
+// matched by CodeName.
+type ObjectDiff struct {
+	Old *dao.Object
+	New *dao.Object
+
+	AddedAttributes   []*dao.Attribute
+	RemovedAttributes []*dao.Attribute
+	ChangedAttributes []*AttributeChange
+}
+
+// AttributeChange contains the old and new versions of an attribute whose type, required flag, allowed
+// values or constraints changed.
+type AttributeChange struct {
+	Old *dao.Attribute
+	New *dao.Attribute
+}
+
+// TypeChanged returns true if the type of the attribute changed.
+func (change *AttributeChange) TypeChanged() bool {
+	return change.Old.Type != change.New.Type
+}
+
+// RequiredChanged returns true if the attribute became required or stopped being required.
+func (change *AttributeChange) RequiredChanged() bool {
+	return change.Old.Required != change.New.Required
+}
+
+// ValuesChanged returns true if the allowed values of the attribute changed. The order of the values matters,
+// since the first value is used to fill existing rows.
+func (change *AttributeChange) ValuesChanged() bool {
+	if len(change.Old.Values) != len(change.New.Values) {
+		return true
+	}
+	for i, value := range change.Old.Values {
+		if value != change.New.Values[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// equalFloat returns true if the given optional numbers are both unset or both set to the same value.
+func equalFloat(a, b *float64) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+// equalInt returns true if the given optional numbers are both unset or both set to the same value.
+func equalInt(a, b *int) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+// ConstraintsChanged returns true if the range, length or pattern of the attribute changed.
+func (change *AttributeChange) ConstraintsChanged() bool {
+	return !equalFloat(change.Old.Min, change.New.Min) || !equalFloat(change.Old.Max, change.New.Max) ||
+		!equalInt(change.Old.MinLength, change.New.MinLength) || !equalInt(change.Old.MaxLength, change.New.MaxLength) ||
+		change.Old.Pattern != change.New.Pattern
+}
+
+// UniqueChanged returns true if the attribute became unique or stopped being unique.
+func (change *AttributeChange) UniqueChanged() bool {
+	return change.Old.Unique != change.New.Unique
+}
+
+// DefaultChanged returns true if the default value of the attribute changed.
+func (change *AttributeChange) DefaultChanged() bool {
+	return change.Old.Default != change.New.Default
+}
+
+// Changed returns true if any part of the attribute that affects its column changed.
+func (change *AttributeChange) Changed() bool {
+	return change.TypeChanged() || change.RequiredChanged() || change.ValuesChanged() ||
+		change.ConstraintsChanged() || change.UniqueChanged() || change.DefaultChanged()
+}
+
+// Empty returns true if the diff does not contain any changes.
+func (diff *Diff) Empty() bool {
+	return len(diff.AddedObjects) == 0 && len(diff.RemovedObjects) == 0 && len(diff.ChangedObjects) == 0
+}
+
+// lookupObject returns the object with the given ID in the given project, or nil if it does not exist.
+func lookupObject(project *dao.Project, id string) (*dao.Object, bool) {
+	if project == nil {
+		return nil, false
+	}
+	object, ok := project.Objects[id]
+	return object, ok
+}
+
+// Compare returns the differences between the old and new versions of a project. A nil project is treated
+// as a project without objects, so comparing against a nil old project reports every object as added.
+func Compare(oldProject *dao.Project, newProject *dao.Project) (*Diff, error) {
+	diff := &Diff{}
+
+	for _, id := range codegen.SortedIDs(oldProject) {
+		oldObject := oldProject.Objects[id]
+		if oldObject == nil {
+			return nil, errors.NewServer("Object cannot be nil")
+		}
+		if _, ok := lookupObject(newProject, id); !ok {
+			diff.RemovedObjects = append(diff.RemovedObjects, oldObject)
+		}
+	}
+
+	for _, id := range codegen.SortedIDs(newProject) {
+		newObject := newProject.Objects[id]
+		if newObject == nil {
+			return nil, errors.NewServer("Object cannot be nil")
+		}
+
+		oldObject, ok := lookupObject(oldProject, id)
+		if !ok {
+			diff.AddedObjects = append(diff.AddedObjects, newObject)
+			continue
+		}
+
+		objectDiff, err := compareObjects(oldObject, newObject)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to compare object "+id)
+		}
+		if objectDiff != nil {
+			diff.ChangedObjects = append(diff.ChangedObjects, objectDiff)
+		}
+	}
+	return diff, nil
+}
+
+// attributeMap returns the stored attributes of the given object keyed by CodeName.
+func attributeMap(object *dao.Object) (map[string]*dao.Attribute, error) {
+	attributes := make(map[string]*dao.Attribute, len(object.Attributes))
+	for _, attribute := range object.Attributes {
+		if attribute == nil {
+			return nil, errors.NewServer("Attribute cannot be nil")
+		}
+		if ddl.Stored(attribute) {
+			attributes[attribute.CodeName] = attribute
+		}
+	}
+	return attributes, nil
+}
+
+// compareObjects returns the differences between the old and new versions of an object, or nil if the
+// stored attributes of the object did not change. Removed attributes are listed in their old order, while added
+// and changed attributes are listed in their new order.
+func compareObjects(oldObject *dao.Object, newObject *dao.Object) (*ObjectDiff, error) {
+	oldAttributes, err := attributeMap(oldObject)
+	if err != nil {
+		return nil, err
+	}
+	newAttributes, err := attributeMap(newObject)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &ObjectDiff{Old: oldObject, New: newObject}
+	for _, attribute := range oldObject.Attributes {
+		if _, ok := newAttributes[attribute.CodeName]; !ok && ddl.Stored(attribute) {
+			diff.RemovedAttributes = append(diff.RemovedAttributes, attribute)
+		}
+	}
+	for _, attribute := range newObject.Attributes {
+		if !ddl.Stored(attribute) {
+			continue
+		}
+		oldAttribute, ok := oldAttributes[attribute.CodeName]
+		if !ok {
+			diff.AddedAttributes = append(diff.AddedAttributes, attribute)
+			continue
+		}
+		change := &AttributeChange{Old: oldAttribute, New: attribute}
+		if change.Changed() {
+			diff.ChangedAttributes = append(diff.ChangedAttributes, change)
+		}
+	}
+
+	if len(diff.AddedAttributes) == 0 && len(diff.RemovedAttributes) == 0 && len(diff.ChangedAttributes) == 0 {
+		return nil, nil
+	}
+	return diff, nil
+}
@@ -0,0 +1,183 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+var title = &dao.Attribute{Name: "title", CodeName: "title", Type: "Text", Required: true}
+var pagesText = &dao.Attribute{Name: "pages", CodeName: "pages", Type: "Text"}
+var pagesInteger = &dao.Attribute{Name: "pages", CodeName: "pages", Type: "Integer", Required: true}
+var summary = &dao.Attribute{Name: "summary", CodeName: "summary", Type: "Text"}
+var isbn = &dao.Attribute{Name: "isbn", CodeName: "isbn", Type: "Text"}
+var isbnRequired = &dao.Attribute{Name: "isbn", CodeName: "isbn", Type: "Text", Required: true}
+var rating = &dao.Attribute{Name: "rating", CodeName: "rating", Type: "Integer", Required: true}
+var genre = &dao.Attribute{Name: "genre", CodeName: "genre", Type: "Enum", Values: []string{"fiction", "non-fiction"}}
+var genreRequired = &dao.Attribute{Name: "genre", CodeName: "genre", Type: "Enum", Values: []string{"fiction", "non-fiction", "poetry"}, Required: true}
+var format = &dao.Attribute{Name: "format", CodeName: "format", Type: "Enum", Values: []string{"hardcover", "paperback"}, Required: true}
+var price = &dao.Attribute{Name: "price", CodeName: "price", Type: "Float"}
+var priceConstrained = &dao.Attribute{Name: "price", CodeName: "price", Type: "Float", Min: &zero, Default: "9.99"}
+var slug = &dao.Attribute{Name: "slug", CodeName: "slug", Type: "Text", Unique: true}
+var slugShort = &dao.Attribute{Name: "slug", CodeName: "slug", Type: "Text", MaxLength: &maxSlug}
+var edition = &dao.Attribute{Name: "edition", CodeName: "edition", Type: "Integer", Required: true, Min: &one, Default: "1"}
+
+var zero, one = 0.0, 1.0
+var maxSlug = 64
+
+var oldAuthor = &dao.Object{ID: "author", Name: "Author", CodeName: "Author", Attributes: []*dao.Attribute{title}}
+var newPublisher = &dao.Object{ID: "publisher", Name: "Publisher", CodeName: "Publisher", Attributes: []*dao.Attribute{title}}
+var oldBook = &dao.Object{ID: "book", Name: "Book", CodeName: "Book", Attributes: []*dao.Attribute{title, pagesText, summary, isbn, genre, price, slug}}
+var newBook = &dao.Object{ID: "book", Name: "Book", CodeName: "Book", Attributes: []*dao.Attribute{title, pagesInteger, isbnRequired, rating, genreRequired, format, priceConstrained, slugShort, edition}}
+
+var oldProject = &dao.Project{Objects: map[string]*dao.Object{"author": oldAuthor, "book": oldBook}}
+var newProject = &dao.Project{Objects: map[string]*dao.Object{"book": newBook, "publisher": newPublisher}}
+
+var bookDiff = &ObjectDiff{
+	Old:               oldBook,
+	New:               newBook,
+	AddedAttributes:   []*dao.Attribute{rating, format, edition},
+	RemovedAttributes: []*dao.Attribute{summary},
+	ChangedAttributes: []*AttributeChange{
+		{Old: pagesText, New: pagesInteger},
+		{Old: isbn, New: isbnRequired},
+		{Old: genre, New: genreRequired},
+		{Old: price, New: priceConstrained},
+		{Old: slug, New: slugShort},
+	},
+}
+
+var compareTests = []struct {
+	name       string
+	oldProject *dao.Project
+	newProject *dao.Project
+	wantDiff   *Diff
+	wantErr    error
+}{
+	{
+		name:     "NilProjects",
+		wantDiff: &Diff{},
+	},
+	{
+		name:       "NilOldProject",
+		newProject: newProject,
+		wantDiff:   &Diff{AddedObjects: []*dao.Object{newBook, newPublisher}},
+	},
+	{
+		name:       "NilNewProject",
+		oldProject: oldProject,
+		wantDiff:   &Diff{RemovedObjects: []*dao.Object{oldAuthor, oldBook}},
+	},
+	{
+		name:       "NilObject",
+		oldProject: &dao.Project{Objects: map[string]*dao.Object{"book": nil}},
+		wantErr:    errors.NewServer("Object cannot be nil"),
+	},
+	{
+		name:       "NilAttribute",
+		oldProject: &dao.Project{Objects: map[string]*dao.Object{"book": oldBook}},
+		newProject: &dao.Project{Objects: map[string]*dao.Object{"book": {ID: "book", Attributes: []*dao.Attribute{nil}}}},
+		wantErr:    errors.Wrap(errors.NewServer("Attribute cannot be nil"), "Failed to compare object book"),
+	},
+	{
+		name:       "Unchanged",
+		oldProject: oldProject,
+		newProject: oldProject,
+		wantDiff:   &Diff{},
+	},
+	{
+		name:       "DescriptionChanged",
+		oldProject: &dao.Project{Objects: map[string]*dao.Object{"book": oldBook}},
+		newProject: &dao.Project{Objects: map[string]*dao.Object{"book": {ID: "book", Name: "Book", Description: "New", Attributes: oldBook.Attributes}}},
+		wantDiff:   &Diff{},
+	},
+	{
+		name:       "CollectionAdded",
+		oldProject: &dao.Project{Objects: map[string]*dao.Object{"book": oldBook}},
+		newProject: &dao.Project{Objects: map[string]*dao.Object{"book": {ID: "book", Name: "Book", Attributes: append([]*dao.Attribute{
+			{Name: "reviews", CodeName: "reviews", Type: "HasMany", Target: "review", Via: "book"},
+		}, oldBook.Attributes...)}}},
+		wantDiff: &Diff{},
+	},
+	{
+		name:       "Changed",
+		oldProject: oldProject,
+		newProject: newProject,
+		wantDiff: &Diff{
+			AddedObjects:   []*dao.Object{newPublisher},
+			RemovedObjects: []*dao.Object{oldAuthor},
+			ChangedObjects: []*ObjectDiff{bookDiff},
+		},
+	},
+}
+
+var attributeChangeTests = []struct {
+	name         string
+	change       *AttributeChange
+	wantType     bool
+	wantValues   bool
+	wantRequired bool
+}{
+	{
+		name:   "Unchanged",
+		change: &AttributeChange{Old: genre, New: genre},
+	},
+	{
+		name:         "TypeAndRequired",
+		change:       &AttributeChange{Old: pagesText, New: pagesInteger},
+		wantType:     true,
+		wantRequired: true,
+	},
+	{
+		name:         "ValuesAndRequired",
+		change:       &AttributeChange{Old: genre, New: genreRequired},
+		wantValues:   true,
+		wantRequired: true,
+	},
+	{
+		name:       "ValuesReordered",
+		change:     &AttributeChange{Old: genre, New: &dao.Attribute{Name: "genre", CodeName: "genre", Type: "Enum", Values: []string{"non-fiction", "fiction"}}},
+		wantValues: true,
+	},
+}
+
+func TestAttributeChange(t *testing.T) {
+	for _, test := range attributeChangeTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			typeChanged := test.change.TypeChanged()
+			valuesChanged := test.change.ValuesChanged()
+			requiredChanged := test.change.RequiredChanged()
+
+			// Verify
+			if typeChanged != test.wantType {
+				t.Errorf("Got TypeChanged %v; want %v", typeChanged, test.wantType)
+			}
+			if valuesChanged != test.wantValues {
+				t.Errorf("Got ValuesChanged %v; want %v", valuesChanged, test.wantValues)
+			}
+			if requiredChanged != test.wantRequired {
+				t.Errorf("Got RequiredChanged %v; want %v", requiredChanged, test.wantRequired)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	for _, test := range compareTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			diff, err := Compare(test.oldProject, test.newProject)
+
+			// Verify
+			if !reflect.DeepEqual(diff, test.wantDiff) {
+				t.Errorf("Got diff %+v; want %+v", diff, test.wantDiff)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/ddl"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// Dir is the directory, relative to the root of the generated code, that contains the migration directories.
+const Dir = ddl.Dir + "/migrations"
+
+// VersionDir returns the directory, relative to the root of the generated code, that contains the migration
+// from the given old version of a project to the given new version, such as db/migrations/3-4.
+func VersionDir(oldVersion int, newVersion int) string {
+	return filepath.Join(Dir, fmt.Sprintf("%d-%d", oldVersion, newVersion))
+}
+
+// Generate writes the migration from the old version of a project to its new version inside rootDir. The
+// migration of each dialect is written to its own file, such as db/migrations/3-4/postgres.sql, next to a
+// CHANGELOG.md describing the changes.
+func Generate(oldProject *dao.Project, newProject *dao.Project, rootDir string) error {
+	if oldProject == nil || newProject == nil {
+		return errors.NewServer("Project cannot be nil")
+	}
+	diff, err := Compare(oldProject, newProject)
+	if err != nil {
+		return errors.Wrap(err, "Failed to compare projects")
+	}
+
+	dir := filepath.Join(rootDir, VersionDir(oldProject.Version, newProject.Version))
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create migration directory")
+	}
+
+	for _, dialect := range ddl.Dialects() {
+		err = generateFile(filepath.Join(dir, dialect.Name+".sql"), func(file *os.File) error {
+			return WriteMigration(file, diff, dialect)
+		})
+		if err != nil {
+			return errors.Wrap(err, "Failed to generate "+dialect.Title+" migration")
+		}
+	}
+
+	err = generateFile(filepath.Join(dir, "CHANGELOG.md"), func(file *os.File) error {
+		return WriteChangelog(file, diff)
+	})
+	return errors.Wrap(err, "Failed to generate changelog")
+}
+
+// generateFile creates the file at the given path and passes it to write.
+func generateFile(path string, write func(*os.File) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create file")
+	}
+	defer file.Close()
+
+	err = write(file)
+	return errors.Wrap(err, "Failed to write file")
+}
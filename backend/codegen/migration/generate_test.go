@@ -0,0 +1,59 @@
+package migration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/ddl"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func TestGenerate(t *testing.T) {
+	// Setup
+	rootDir, err := ioutil.TempDir("", "migration")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(rootDir)
+	oldVersion, newVersion := *oldProject, *newProject
+	oldVersion.Version, newVersion.Version = 3, 4
+
+	// Execute
+	err = Generate(&oldVersion, &newVersion, rootDir)
+
+	// Verify
+	if err != nil {
+		t.Fatalf("Got error generating migration: %v", err)
+	}
+	dir := filepath.Join(rootDir, "db", "migrations", "3-4")
+	for _, dialect := range ddl.Dialects() {
+		want, err := ioutil.ReadFile(filepath.Join("testdata", dialect.Name+".sql"))
+		if err != nil {
+			t.Fatalf("Error reading golden file: %v", err)
+		}
+		got, err := ioutil.ReadFile(filepath.Join(dir, dialect.Name+".sql"))
+		if err != nil {
+			t.Fatalf("Error reading generated file: %v", err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("Got %s migration:\n%s\nwant:\n%s", dialect.Title, got, want)
+		}
+	}
+	changelog, err := ioutil.ReadFile(filepath.Join(dir, "CHANGELOG.md"))
+	if err != nil {
+		t.Fatalf("Error reading changelog: %v", err)
+	}
+	if !strings.HasPrefix(string(changelog), "# Changelog\n") {
+		t.Errorf("Got changelog %s; want a Markdown changelog", changelog)
+	}
+}
+
+func TestGenerateNilProject(t *testing.T) {
+	err := Generate(nil, newProject, "")
+	if !errors.Equal(err, errors.NewServer("Project cannot be nil")) {
+		t.Errorf("Got err %v; want Project cannot be nil", err)
+	}
+}
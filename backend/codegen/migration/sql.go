@@ -0,0 +1,322 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/ddl"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// WriteMigration writes the SQL statements that upgrade a database created from the old version of a project
+// to its new version to the given writer. Removed tables are dropped before new tables are created, and the
+// tables of changed objects are altered last. When a column becomes NOT NULL, existing NULL values are
+// replaced with the default value of the column first.
+func WriteMigration(writer io.Writer, diff *Diff, dialect *ddl.Dialect) error {
+	if diff == nil {
+		return errors.NewServer("Diff cannot be nil")
+	}
+
+	_, err := fmt.Fprintf(writer, "-- %s migration generated by CRUD Creator.\n", dialect.Title)
+	if err != nil {
+		return errors.Wrap(err, "Failed to write string")
+	}
+	if diff.Empty() {
+		_, err = io.WriteString(writer, "\n-- No changes.\n")
+		return errors.Wrap(err, "Failed to write string")
+	}
+
+	for _, object := range diff.RemovedObjects {
+		_, err = fmt.Fprintf(writer, "\nDROP TABLE %s;\n", dialect.Quote(ddl.TableName(object)))
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+
+	for _, object := range diff.AddedObjects {
+		_, err = io.WriteString(writer, "\n")
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+		err = ddl.WriteTable(writer, object, dialect)
+		if err != nil {
+			return errors.Wrap(err, "Failed to write table for object "+object.Name)
+		}
+	}
+
+	for _, objectDiff := range diff.ChangedObjects {
+		statements, err := alterStatements(objectDiff, dialect)
+		if err != nil {
+			return errors.Wrap(err, "Failed to create statements for object "+objectDiff.New.Name)
+		}
+		_, err = io.WriteString(writer, "\n"+strings.Join(statements, "\n")+"\n")
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+	return nil
+}
+
+// addsUnique returns true if any of the attributes added by the given diff is unique.
+func addsUnique(diff *ObjectDiff) bool {
+	for _, attribute := range diff.AddedAttributes {
+		if attribute.Unique {
+			return true
+		}
+	}
+	return false
+}
+
+// alterStatements returns the statements that migrate the table of a changed object. SQLite cannot change
+// the type or constraints of a column or add a unique column, so in those cases the table is rebuilt instead.
+func alterStatements(diff *ObjectDiff, dialect *ddl.Dialect) ([]string, error) {
+	if dialect.Name == ddl.SQLite.Name && (len(diff.ChangedAttributes) > 0 || addsUnique(diff)) {
+		return rebuildStatements(diff, dialect)
+	}
+
+	table := dialect.Quote(ddl.TableName(diff.New))
+	var statements []string
+
+	for _, attribute := range diff.RemovedAttributes {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, dialect.Quote(attribute.CodeName)))
+	}
+
+	for _, attribute := range diff.AddedAttributes {
+		column, err := dialect.Definition(attribute)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create column")
+		}
+		if attribute.Required && attribute.Default == "" {
+			value, err := dialect.DefaultValue(attribute)
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to create column")
+			}
+			column += " DEFAULT " + value
+		}
+		constraints, err := dialect.Constraints(ddl.TableName(diff.New), attribute)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create column")
+		}
+		if constraints != "" {
+			column += " " + constraints
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", table, column))
+	}
+
+	for _, change := range diff.ChangedAttributes {
+		var changeStatements []string
+		var err error
+		if dialect.Name == ddl.MySQL.Name {
+			changeStatements, err = modifyStatements(table, change, dialect)
+		} else {
+			changeStatements, err = alterColumnStatements(table, change, dialect)
+		}
+		if err == nil {
+			changeStatements, err = checkStatements(ddl.TableName(diff.New), change, dialect, changeStatements)
+		}
+		if err == nil {
+			changeStatements = uniqueStatements(ddl.TableName(diff.New), change, dialect, changeStatements)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to change column "+change.New.CodeName)
+		}
+		statements = append(statements, changeStatements...)
+	}
+	return statements, nil
+}
+
+// checkStatements surrounds the given statements, which change the column of an attribute, with the statements
+// that replace the column's CHECK constraint when the attribute's type, allowed values or constraints changed.
+// The old constraint is dropped first so that it does not reject the converted values.
+func checkStatements(tableName string, change *AttributeChange, dialect *ddl.Dialect, statements []string) ([]string, error) {
+	if !change.TypeChanged() && !change.ValuesChanged() && !change.ConstraintsChanged() {
+		return statements, nil
+	}
+	table := dialect.Quote(tableName)
+
+	oldCheck, err := dialect.Check(tableName, change.Old)
+	if err != nil {
+		return nil, err
+	}
+	newCheck, err := dialect.Check(tableName, change.New)
+	if err != nil {
+		return nil, err
+	}
+
+	if oldCheck != "" {
+		name := dialect.Quote(tableName + "_" + change.Old.CodeName + "_check")
+		drop := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;", table, name)
+		if dialect.Name == ddl.MySQL.Name {
+			drop = fmt.Sprintf("ALTER TABLE %s DROP CHECK %s;", table, name)
+		}
+		statements = append([]string{drop}, statements...)
+	}
+	if newCheck != "" {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD %s;", table, newCheck))
+	}
+	return statements, nil
+}
+
+// uniqueStatements surrounds the given statements, which change the column of an attribute, with the statement
+// that adds or drops the column's UNIQUE constraint when the attribute became unique or stopped being unique.
+// The constraint is dropped before and added after the column changes, since MySQL changes the type of unique
+// TEXT columns. PostgreSQL names the constraint after the table and column, while MySQL names its index after
+// the column.
+func uniqueStatements(tableName string, change *AttributeChange, dialect *ddl.Dialect, statements []string) []string {
+	if !change.UniqueChanged() {
+		return statements
+	}
+	table := dialect.Quote(tableName)
+	column := dialect.Quote(change.New.CodeName)
+	name := dialect.Quote(tableName + "_" + change.New.CodeName + "_key")
+
+	if change.New.Unique {
+		add := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);", table, name, column)
+		if dialect.Name == ddl.MySQL.Name {
+			add = fmt.Sprintf("ALTER TABLE %s ADD UNIQUE (%s);", table, column)
+		}
+		return append(statements, add)
+	}
+
+	drop := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;", table, name)
+	if dialect.Name == ddl.MySQL.Name {
+		drop = fmt.Sprintf("ALTER TABLE %s DROP INDEX %s;", table, dialect.Quote(change.Old.CodeName))
+	}
+	return append([]string{drop}, statements...)
+}
+
+// fillNullsStatement returns the statement that replaces the NULL values of the given attribute's column with
+// the default value of the attribute.
+func fillNullsStatement(table string, attribute *dao.Attribute, dialect *ddl.Dialect) (string, error) {
+	value, err := dialect.DefaultValue(attribute)
+	if err != nil {
+		return "", err
+	}
+	column := dialect.Quote(attribute.CodeName)
+	return fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s IS NULL;", table, column, value, column), nil
+}
+
+// alterColumnStatements returns the ALTER COLUMN statements that apply the given change in PostgreSQL.
+func alterColumnStatements(table string, change *AttributeChange, dialect *ddl.Dialect) ([]string, error) {
+	column := dialect.Quote(change.New.CodeName)
+	var statements []string
+
+	if change.TypeChanged() {
+		columnType, err := dialect.ColumnType(change.New.Type)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s;", table, column, columnType, column, columnType))
+	}
+
+	if change.DefaultChanged() {
+		value, err := dialect.Default(change.New)
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;", table, column))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s;", table, column, value))
+		}
+	}
+
+	if change.RequiredChanged() {
+		if !change.New.Required {
+			return append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL;", table, column)), nil
+		}
+		fill, err := fillNullsStatement(table, change.New, dialect)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, fill, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL;", table, column))
+	}
+	return statements, nil
+}
+
+// modifyStatements returns the MODIFY COLUMN statements that apply the given change in MySQL. The column is
+// modified even if only its constraints changed, since its type depends on whether it is unique. If the column
+// becomes NOT NULL, it is first converted to its new type while still nullable so that its NULL values can
+// be replaced with a value of the new type.
+func modifyStatements(table string, change *AttributeChange, dialect *ddl.Dialect) ([]string, error) {
+	definition, err := dialect.Definition(change.New)
+	if err != nil {
+		return nil, err
+	}
+	modify := fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s;", table, definition)
+
+	if !change.RequiredChanged() || !change.New.Required {
+		return []string{modify}, nil
+	}
+
+	var statements []string
+	if change.TypeChanged() {
+		columnType, err := dialect.ColumnType(change.New.Type)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s;", table, dialect.Quote(change.New.CodeName), columnType))
+	}
+	fill, err := fillNullsStatement(table, change.New, dialect)
+	if err != nil {
+		return nil, err
+	}
+	return append(statements, fill, modify), nil
+}
+
+// rebuildStatements returns the statements that rebuild the table of a changed object in SQLite. A new table
+// is created with the new definition, the rows of the old table are copied into it and the old table is
+// replaced. Required columns that are new or previously allowed NULL are filled with their default value.
+func rebuildStatements(diff *ObjectDiff, dialect *ddl.Dialect) ([]string, error) {
+	tableName := ddl.TableName(diff.New)
+	table := dialect.Quote(tableName)
+	newTable := dialect.Quote(tableName + "_new")
+
+	builder := &strings.Builder{}
+	err := ddl.WriteTableAs(builder, diff.New, dialect, tableName+"_new")
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to write table")
+	}
+
+	oldAttributes, err := attributeMap(diff.Old)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := []string{dialect.Quote("id")}
+	values := []string{dialect.Quote("id")}
+	for _, attribute := range diff.New.Attributes {
+		if !ddl.Stored(attribute) {
+			continue
+		}
+		column := dialect.Quote(attribute.CodeName)
+		oldAttribute, existed := oldAttributes[attribute.CodeName]
+		if existed && (!attribute.Required || oldAttribute.Required) {
+			columns = append(columns, column)
+			values = append(values, column)
+			continue
+		}
+		if !attribute.Required {
+			continue
+		}
+
+		value, err := dialect.DefaultValue(attribute)
+		if err != nil {
+			return nil, err
+		}
+		if existed {
+			value = fmt.Sprintf("COALESCE(%s, %s)", column, value)
+		}
+		columns = append(columns, column)
+		values = append(values, value)
+	}
+
+	return []string{
+		strings.TrimSuffix(builder.String(), "\n"),
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;", newTable, strings.Join(columns, ", "), strings.Join(values, ", "), table),
+		fmt.Sprintf("DROP TABLE %s;", table),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", newTable, table),
+	}, nil
+}
@@ -0,0 +1,110 @@
+package migration
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/ddl"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func TestWriteMigration(t *testing.T) {
+	diff, err := Compare(oldProject, newProject)
+	if err != nil {
+		t.Fatalf("Got error comparing projects: %v", err)
+	}
+
+	for _, dialect := range ddl.Dialects() {
+		t.Run(dialect.Name, func(t *testing.T) {
+			// Setup
+			builder := &strings.Builder{}
+			want, err := ioutil.ReadFile(filepath.Join("testdata", dialect.Name+".sql"))
+			if err != nil {
+				t.Fatalf("Error reading golden file: %v", err)
+			}
+
+			// Execute
+			err = WriteMigration(builder, diff, dialect)
+
+			// Verify
+			if err != nil {
+				t.Errorf("Got error writing migration: %v", err)
+			}
+			if builder.String() != string(want) {
+				t.Errorf("Got migration:\n%s\nwant:\n%s", builder.String(), want)
+			}
+		})
+	}
+}
+
+var writeMigrationErrorTests = []struct {
+	name       string
+	diff       *Diff
+	wantString string
+	wantErr    error
+}{
+	{
+		name:    "NilDiff",
+		wantErr: errors.NewServer("Diff cannot be nil"),
+	},
+	{
+		name:       "EmptyDiff",
+		diff:       &Diff{},
+		wantString: "-- PostgreSQL migration generated by CRUD Creator.\n\n-- No changes.\n",
+	},
+}
+
+func TestWriteMigrationErrors(t *testing.T) {
+	for _, test := range writeMigrationErrorTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			builder := &strings.Builder{}
+
+			// Execute
+			err := WriteMigration(builder, test.diff, ddl.Postgres)
+
+			// Verify
+			if builder.String() != test.wantString {
+				t.Errorf("Got string:\n%s\nwant:\n%s", builder.String(), test.wantString)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteMigrationRebuildsForUniqueColumn(t *testing.T) {
+	// Setup
+	builder := &strings.Builder{}
+	isbnUnique := &dao.Attribute{Name: "isbn", CodeName: "isbn", Type: "Text", Unique: true}
+	diff := &Diff{ChangedObjects: []*ObjectDiff{{
+		Old:             oldAuthor,
+		New:             &dao.Object{ID: "author", Name: "Author", CodeName: "Author", Attributes: []*dao.Attribute{title, isbnUnique}},
+		AddedAttributes: []*dao.Attribute{isbnUnique},
+	}}}
+	want := "-- SQLite migration generated by CRUD Creator.\n" +
+		"\n" +
+		"CREATE TABLE \"author_new\" (\n" +
+		"    \"id\" INTEGER PRIMARY KEY AUTOINCREMENT,\n" +
+		"    \"title\" TEXT NOT NULL,\n" +
+		"    \"isbn\" TEXT UNIQUE\n" +
+		");\n" +
+		"INSERT INTO \"author_new\" (\"id\", \"title\") SELECT \"id\", \"title\" FROM \"author\";\n" +
+		"DROP TABLE \"author\";\n" +
+		"ALTER TABLE \"author_new\" RENAME TO \"author\";\n"
+
+	// Execute
+	err := WriteMigration(builder, diff, ddl.SQLite)
+
+	// Verify
+	if err != nil {
+		t.Errorf("Got error writing migration: %v", err)
+	}
+	if builder.String() != want {
+		t.Errorf("Got migration:\n%s\nwant:\n%s", builder.String(), want)
+	}
+}
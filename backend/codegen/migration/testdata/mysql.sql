@@ -0,0 +1,27 @@
+-- MySQL migration generated by CRUD Creator.
+
+DROP TABLE `author`;
+
+CREATE TABLE `publisher` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `title` TEXT NOT NULL
+);
+
+ALTER TABLE `book` DROP COLUMN `summary`;
+ALTER TABLE `book` ADD COLUMN `rating` BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE `book` ADD COLUMN `format` VARCHAR(255) NOT NULL DEFAULT 'hardcover' CONSTRAINT `book_format_check` CHECK (`format` IN ('hardcover', 'paperback'));
+ALTER TABLE `book` ADD COLUMN `edition` BIGINT DEFAULT (1) NOT NULL CONSTRAINT `book_edition_check` CHECK (`edition` >= 1);
+ALTER TABLE `book` MODIFY COLUMN `pages` BIGINT;
+UPDATE `book` SET `pages` = 0 WHERE `pages` IS NULL;
+ALTER TABLE `book` MODIFY COLUMN `pages` BIGINT NOT NULL;
+UPDATE `book` SET `isbn` = ('') WHERE `isbn` IS NULL;
+ALTER TABLE `book` MODIFY COLUMN `isbn` TEXT NOT NULL;
+ALTER TABLE `book` DROP CHECK `book_genre_check`;
+UPDATE `book` SET `genre` = 'fiction' WHERE `genre` IS NULL;
+ALTER TABLE `book` MODIFY COLUMN `genre` VARCHAR(255) NOT NULL;
+ALTER TABLE `book` ADD CONSTRAINT `book_genre_check` CHECK (`genre` IN ('fiction', 'non-fiction', 'poetry'));
+ALTER TABLE `book` MODIFY COLUMN `price` DOUBLE DEFAULT (9.99);
+ALTER TABLE `book` ADD CONSTRAINT `book_price_check` CHECK (`price` >= 0);
+ALTER TABLE `book` DROP INDEX `slug`;
+ALTER TABLE `book` MODIFY COLUMN `slug` TEXT;
+ALTER TABLE `book` ADD CONSTRAINT `book_slug_check` CHECK (CHAR_LENGTH(`slug`) <= 64);
@@ -0,0 +1,26 @@
+-- PostgreSQL migration generated by CRUD Creator.
+
+DROP TABLE "author";
+
+CREATE TABLE "publisher" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "title" TEXT NOT NULL
+);
+
+ALTER TABLE "book" DROP COLUMN "summary";
+ALTER TABLE "book" ADD COLUMN "rating" BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE "book" ADD COLUMN "format" TEXT NOT NULL DEFAULT 'hardcover' CONSTRAINT "book_format_check" CHECK ("format" IN ('hardcover', 'paperback'));
+ALTER TABLE "book" ADD COLUMN "edition" BIGINT DEFAULT 1 NOT NULL CONSTRAINT "book_edition_check" CHECK ("edition" >= 1);
+ALTER TABLE "book" ALTER COLUMN "pages" TYPE BIGINT USING "pages"::BIGINT;
+UPDATE "book" SET "pages" = 0 WHERE "pages" IS NULL;
+ALTER TABLE "book" ALTER COLUMN "pages" SET NOT NULL;
+UPDATE "book" SET "isbn" = '' WHERE "isbn" IS NULL;
+ALTER TABLE "book" ALTER COLUMN "isbn" SET NOT NULL;
+ALTER TABLE "book" DROP CONSTRAINT IF EXISTS "book_genre_check";
+UPDATE "book" SET "genre" = 'fiction' WHERE "genre" IS NULL;
+ALTER TABLE "book" ALTER COLUMN "genre" SET NOT NULL;
+ALTER TABLE "book" ADD CONSTRAINT "book_genre_check" CHECK ("genre" IN ('fiction', 'non-fiction', 'poetry'));
+ALTER TABLE "book" ALTER COLUMN "price" SET DEFAULT 9.99;
+ALTER TABLE "book" ADD CONSTRAINT "book_price_check" CHECK ("price" >= 0);
+ALTER TABLE "book" DROP CONSTRAINT IF EXISTS "book_slug_key";
+ALTER TABLE "book" ADD CONSTRAINT "book_slug_check" CHECK (char_length("slug") <= 64);
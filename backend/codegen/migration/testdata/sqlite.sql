@@ -0,0 +1,24 @@
+-- SQLite migration generated by CRUD Creator.
+
+DROP TABLE "author";
+
+CREATE TABLE "publisher" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "title" TEXT NOT NULL
+);
+
+CREATE TABLE "book_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "title" TEXT NOT NULL,
+    "pages" INTEGER NOT NULL,
+    "isbn" TEXT NOT NULL,
+    "rating" INTEGER NOT NULL,
+    "genre" TEXT NOT NULL CONSTRAINT "book_genre_check" CHECK ("genre" IN ('fiction', 'non-fiction', 'poetry')),
+    "format" TEXT NOT NULL CONSTRAINT "book_format_check" CHECK ("format" IN ('hardcover', 'paperback')),
+    "price" REAL DEFAULT 9.99 CONSTRAINT "book_price_check" CHECK ("price" >= 0),
+    "slug" TEXT CONSTRAINT "book_slug_check" CHECK (length("slug") <= 64),
+    "edition" INTEGER DEFAULT 1 NOT NULL CONSTRAINT "book_edition_check" CHECK ("edition" >= 1)
+);
+INSERT INTO "book_new" ("id", "title", "pages", "isbn", "rating", "genre", "format", "price", "slug", "edition") SELECT "id", "title", COALESCE("pages", 0), COALESCE("isbn", ''), 0, COALESCE("genre", 'fiction'), 'hardcover', "price", "slug", 1 FROM "book";
+DROP TABLE "book";
+ALTER TABLE "book_new" RENAME TO "book";
@@ -0,0 +1,88 @@
+package openapi
+
+// Document is the root object of an OpenAPI 3.0 document. Only the fields used by the generated
+// documents are defined.
+type Document struct {
+	OpenAPI    string               `json:"openapi" yaml:"openapi"`
+	Info       *Info                `json:"info" yaml:"info"`
+	Paths      map[string]*PathItem `json:"paths" yaml:"paths"`
+	Components *Components          `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+// Info provides metadata about the API.
+type Info struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+// PathItem describes the operations available on a single path.
+type PathItem struct {
+	Get        *Operation   `json:"get,omitempty" yaml:"get,omitempty"`
+	Post       *Operation   `json:"post,omitempty" yaml:"post,omitempty"`
+	Put        *Operation   `json:"put,omitempty" yaml:"put,omitempty"`
+	Patch      *Operation   `json:"patch,omitempty" yaml:"patch,omitempty"`
+	Delete     *Operation   `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Parameters []*Parameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// Operation describes a single API operation on a path.
+type Operation struct {
+	OperationID string               `json:"operationId" yaml:"operationId"`
+	Summary     string               `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string               `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses" yaml:"responses"`
+}
+
+// Parameter describes a single operation parameter.
+type Parameter struct {
+	Name        string  `json:"name" yaml:"name"`
+	In          string  `json:"in" yaml:"in"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool    `json:"required" yaml:"required"`
+	Schema      *Schema `json:"schema" yaml:"schema"`
+}
+
+// RequestBody describes the body of a request.
+type RequestBody struct {
+	Required bool                  `json:"required" yaml:"required"`
+	Content  map[string]*MediaType `json:"content" yaml:"content"`
+}
+
+// Response describes a single response of an operation.
+type Response struct {
+	Description string                `json:"description" yaml:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// MediaType describes the schema of a request or response body in a specific media type.
+type MediaType struct {
+	Schema *Schema `json:"schema" yaml:"schema"`
+}
+
+// Components holds the reusable schemas referenced throughout the document.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+}
+
+// Schema defines a data type. A schema with Ref set refers to a schema in Components.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format      string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Enum        []string           `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Pattern     string             `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Default     interface{}        `json:"default,omitempty" yaml:"default,omitempty"`
+	Description string             `json:"description,omitempty" yaml:"description,omitempty"`
+	ReadOnly    bool               `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+	Items       *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty" yaml:"required,omitempty"`
+}
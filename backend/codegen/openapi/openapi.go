@@ -0,0 +1,393 @@
+// Package openapi renders a project into an OpenAPI 3.0 document describing the REST API of its
+// generated code.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/endpoints"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/types"
+	"gopkg.in/yaml.v2"
+)
+
+// Version is the version of the OpenAPI specification that generated documents conform to.
+const Version = "3.0.3"
+
+// jsonMediaType is the media type of every request and response body of the generated API.
+const jsonMediaType = "application/json"
+
+// idSchemas maps the name of each code generation target to the schema of the IDs of the records that its
+// generated code stores. Sails and the Go target use auto-incrementing integers, while Express uses MongoDB
+// object IDs.
+var idSchemas = map[string]Schema{
+	"express": {Type: "string", Pattern: "^[0-9a-fA-F]{24}$"},
+	"gohttp":  {Type: "integer", Format: "int64"},
+	"sails":   {Type: "integer"},
+}
+
+// idSchema returns the schema of the IDs of the records stored by the code generated for the given target,
+// with the given description. If target is empty, codegen.DefaultTarget is used. If the target is not
+// supported, a client error is returned.
+func idSchema(target string, description string) (*Schema, error) {
+	if target == "" {
+		target = codegen.DefaultTarget
+	}
+	schema, ok := idSchemas[target]
+	if !ok {
+		targets := make([]string, 0, len(idSchemas))
+		for name := range idSchemas {
+			targets = append(targets, name)
+		}
+		sort.Strings(targets)
+		return nil, errors.NewClient("Unsupported target `" + target + "`. Supported targets are: " + strings.Join(targets, ", "))
+	}
+	schema.Description = description
+	return &schema, nil
+}
+
+// attributeSchema returns the schema of the given attribute, including its constraints. Collection attributes
+// are not part of a record, so their schema is nil. OpenAPI cannot express uniqueness, so it is omitted.
+func attributeSchema(attribute *dao.Attribute) (*Schema, error) {
+	if attribute == nil {
+		return nil, errors.NewServer("Attribute cannot be nil")
+	}
+
+	attributeType, err := types.Lookup(attribute.Type)
+	if err != nil {
+		return nil, errors.NewServer("Invalid attribute type: " + attribute.Type)
+	}
+	if attributeType.Collection() {
+		return nil, nil
+	}
+
+	schema := &Schema{
+		Type:        attributeType.OpenAPI.Type,
+		Format:      attributeType.OpenAPI.Format,
+		Description: attribute.Description,
+	}
+	if attributeType.HasValues {
+		schema.Enum = attribute.Values
+	}
+	schema.Minimum = attribute.Min
+	schema.Maximum = attribute.Max
+	schema.MinLength = attribute.MinLength
+	schema.MaxLength = attribute.MaxLength
+	schema.Pattern = attribute.Pattern
+	if value, ok := attributeType.Default(attribute); ok {
+		schema.Default = value
+	}
+	return schema, nil
+}
+
+// objectSchemas returns the schema of the given object along with the schema of the body of requests that
+// update the object. The update schema has the same properties but does not require any of them. The ID of
+// the object has the given schema.
+func objectSchemas(object *dao.Object, id *Schema) (*Schema, *Schema, error) {
+	if object == nil {
+		return nil, nil, errors.NewServer("Object cannot be nil")
+	}
+
+	schema := &Schema{
+		Type:        "object",
+		Description: object.Description,
+		Properties: map[string]*Schema{
+			"id": id,
+		},
+	}
+	update := &Schema{
+		Type:        "object",
+		Description: "The attributes of " + object.Name + " to update.",
+		Properties:  map[string]*Schema{},
+	}
+
+	for _, attribute := range object.Attributes {
+		property, err := attributeSchema(attribute)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Failed to create schema for attribute")
+		}
+		if property == nil {
+			continue
+		}
+		schema.Properties[attribute.CodeName] = property
+		update.Properties[attribute.CodeName] = property
+		if attribute.Required {
+			schema.Required = append(schema.Required, attribute.CodeName)
+		}
+	}
+	return schema, update, nil
+}
+
+// ref returns a schema that refers to the component schema with the given name.
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// jsonContent returns the content map of a body containing the given schema.
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{jsonMediaType: {Schema: schema}}
+}
+
+// customTargets is the set of code generation targets whose generated code serves the custom endpoints of a
+// project. The other targets always serve the default endpoints of every object.
+var customTargets = map[string]bool{
+	"sails": true,
+}
+
+// targetEndpoints returns the endpoints served by the code generated for the given project and target, in the
+// order their routes are generated. If target is empty, codegen.DefaultTarget is used.
+func targetEndpoints(project *dao.Project, target string) []*dao.Endpoint {
+	if target == "" {
+		target = codegen.DefaultTarget
+	}
+	if customTargets[target] {
+		return endpoints.Project(project)
+	}
+
+	var defaults []*dao.Endpoint
+	for _, object := range codegen.SortedObjects(project) {
+		defaults = append(defaults, endpoints.Defaults(object)...)
+	}
+	return defaults
+}
+
+// templatePath returns the OpenAPI path template of the given route path, such as `/books/{id}` for
+// `/books/:id`.
+func templatePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pathParameters returns the parameters of the given route path. The `id` parameter has the given schema and
+// every other parameter is a string.
+func pathParameters(path string, id *Schema) []*Parameter {
+	var parameters []*Parameter
+	for _, name := range endpoints.Parameters(path) {
+		if name == "id" {
+			parameters = append(parameters, &Parameter{Name: name, In: "path", Description: "The ID of the record", Required: true, Schema: id})
+		} else {
+			parameters = append(parameters, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	return parameters
+}
+
+// filterDescriptions maps each filter operator to the phrase that describes how it compares an attribute to
+// the value of its query parameter.
+var filterDescriptions = map[string]string{
+	endpoints.Equal:              "equals",
+	endpoints.NotEqual:           "does not equal",
+	endpoints.LessThan:           "is less than",
+	endpoints.LessThanOrEqual:    "is at most",
+	endpoints.GreaterThan:        "is greater than",
+	endpoints.GreaterThanOrEqual: "is at least",
+	endpoints.Contains:           "contains",
+	endpoints.StartsWith:         "starts with",
+}
+
+// queryParameters returns the optional query parameters of the filters of the given endpoint, which filters
+// the records of the given object. Each parameter has the type of the attribute it is compared to.
+func queryParameters(object *dao.Object, endpoint *dao.Endpoint) ([]*Parameter, error) {
+	var parameters []*Parameter
+	for _, filter := range endpoint.Filters {
+		var attribute *dao.Attribute
+		for _, a := range object.Attributes {
+			if a != nil && a.CodeName == filter.Attribute {
+				attribute = a
+			}
+		}
+		if attribute == nil {
+			return nil, errors.NewServer(fmt.Sprintf("Object `%s` does not have attribute `%s`", object.Name, filter.Attribute))
+		}
+		attributeType, err := types.Lookup(attribute.Type)
+		if err != nil {
+			return nil, errors.NewServer("Invalid attribute type: " + attribute.Type)
+		}
+		parameters = append(parameters, &Parameter{
+			Name:        filter.Parameter,
+			In:          "query",
+			Description: fmt.Sprintf("Only returns records whose %s %s this value", attribute.CodeName, filterDescriptions[filter.Operator]),
+			Schema:      &Schema{Type: attributeType.OpenAPI.Type, Format: attributeType.OpenAPI.Format},
+		})
+	}
+	return parameters, nil
+}
+
+// operation returns the operation of the given endpoint, which operates on the given object. The responses
+// match those of the controller actions emitted by the code generators.
+func operation(object *dao.Object, endpoint *dao.Endpoint) (*Operation, error) {
+	name := object.CodeName
+	notFound := &Response{Description: "Record not found"}
+	result := &Operation{
+		OperationID: endpoint.CodeName + name,
+		Description: endpoint.Description,
+		Tags:        []string{name},
+	}
+
+	switch endpoint.Operation {
+	case endpoints.List:
+		parameters, err := queryParameters(object, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		result.Summary = "List records of " + object.Name
+		result.Parameters = parameters
+		result.Responses = map[string]*Response{
+			"200": {Description: "Every record", Content: jsonContent(&Schema{Type: "array", Items: ref(name)})},
+		}
+	case endpoints.Get:
+		result.Summary = "Get a record of " + object.Name
+		result.Responses = map[string]*Response{
+			"200": {Description: "The requested record", Content: jsonContent(ref(name))},
+			"404": notFound,
+		}
+	case endpoints.Create:
+		result.Summary = "Create a record of " + object.Name
+		result.RequestBody = &RequestBody{Required: true, Content: jsonContent(ref(name))}
+		result.Responses = map[string]*Response{
+			"201": {Description: "The created record", Content: jsonContent(ref(name))},
+		}
+	case endpoints.Update:
+		result.Summary = "Update a record of " + object.Name
+		result.RequestBody = &RequestBody{Required: true, Content: jsonContent(ref(name + "Update"))}
+		result.Responses = map[string]*Response{
+			"200": {Description: "The updated record", Content: jsonContent(ref(name))},
+			"404": notFound,
+		}
+	case endpoints.Delete:
+		result.Summary = "Delete a record of " + object.Name
+		result.Responses = map[string]*Response{
+			"200": {Description: "The deleted record", Content: jsonContent(ref(name))},
+			"404": notFound,
+		}
+	case endpoints.Custom:
+		result.Summary = endpoint.Name
+		result.Responses = map[string]*Response{
+			"501": {Description: "The endpoint is not implemented yet"},
+		}
+	default:
+		return nil, errors.NewServer("Invalid operation: " + endpoint.Operation)
+	}
+
+	if endpoint.AuthRequired {
+		result.Responses["403"] = &Response{Description: "The request does not have a logged in user"}
+	}
+	return result, nil
+}
+
+// addPath adds the given endpoint to the given document. The path of the endpoint matches the route emitted
+// by the code generators, and its `id` path parameter has the given schema.
+func addPath(document *Document, objects map[string]*dao.Object, endpoint *dao.Endpoint, id *Schema) error {
+	object := objects[endpoint.Object]
+	if object == nil {
+		return errors.NewServer(fmt.Sprintf("Object `%s` does not exist", endpoint.Object))
+	}
+	op, err := operation(object, endpoint)
+	if err != nil {
+		return err
+	}
+
+	path := templatePath(endpoint.Path)
+	item := document.Paths[path]
+	if item == nil {
+		item = &PathItem{Parameters: pathParameters(endpoint.Path, id)}
+		document.Paths[path] = item
+	}
+
+	switch endpoint.Method {
+	case "GET":
+		item.Get = op
+	case "POST":
+		item.Post = op
+	case "PUT":
+		item.Put = op
+	case "PATCH":
+		item.Patch = op
+	case "DELETE":
+		item.Delete = op
+	default:
+		return errors.NewServer("Invalid method: " + endpoint.Method)
+	}
+	return nil
+}
+
+// Build returns the OpenAPI document describing the REST API generated for the given project and target. Each
+// object becomes a component schema, and each endpoint served by the generated code is added as a path. If
+// target is empty, codegen.DefaultTarget is used.
+func Build(project *dao.Project, target string) (*Document, error) {
+	if project == nil {
+		return nil, errors.NewServer("Project cannot be nil")
+	}
+	parameter, err := idSchema(target, "")
+	if err != nil {
+		return nil, err
+	}
+	property, err := idSchema(target, "The unique identifier of the record.")
+	if err != nil {
+		return nil, err
+	}
+	property.ReadOnly = true
+
+	document := &Document{
+		OpenAPI: Version,
+		Info: &Info{
+			Title:       project.Name,
+			Description: project.Description,
+			Version:     "1.0.0",
+		},
+		Paths:      map[string]*PathItem{},
+		Components: &Components{Schemas: map[string]*Schema{}},
+	}
+
+	for _, id := range codegen.SortedIDs(project) {
+		object := project.Objects[id]
+		schema, update, err := objectSchemas(object, property)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create schema for object "+id)
+		}
+		document.Components.Schemas[object.CodeName] = schema
+		document.Components.Schemas[object.CodeName+"Update"] = update
+	}
+
+	for _, endpoint := range targetEndpoints(project, target) {
+		err = addPath(document, project.Objects, endpoint, parameter)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to add path for endpoint "+endpoint.ID)
+		}
+	}
+	return document, nil
+}
+
+// JSON returns the OpenAPI document of the code generated for the given project and target, encoded as
+// indented JSON.
+func JSON(project *dao.Project, target string) ([]byte, error) {
+	document, err := Build(project, target)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to build OpenAPI document")
+	}
+	contents, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to marshal OpenAPI document to JSON")
+	}
+	return append(contents, '\n'), nil
+}
+
+// YAML returns the OpenAPI document of the code generated for the given project and target, encoded as YAML.
+func YAML(project *dao.Project, target string) ([]byte, error) {
+	document, err := Build(project, target)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to build OpenAPI document")
+	}
+	contents, err := yaml.Marshal(document)
+	return contents, errors.Wrap(err, "Failed to marshal OpenAPI document to YAML")
+}
@@ -0,0 +1,337 @@
+package openapi
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+var minimum, maximum = 0.0, 100.0
+var minLength, maxLength = 1, 80
+
+var project = &dao.Project{
+	Name:        "Default Project",
+	Description: "Test project",
+	Objects: map[string]*dao.Object{
+		"testobject": &dao.Object{
+			ID:          "testobject",
+			Name:        "TestObject",
+			CodeName:    "TestObject",
+			Description: "This is a description of testobject.",
+			Attributes: []*dao.Attribute{
+				{
+					Name:        "testAttribute",
+					CodeName:    "testAttribute",
+					Type:        "Integer",
+					Description: "An integer attribute.",
+					Min:         &minimum,
+					Max:         &maximum,
+					Default:     "10",
+				},
+				{
+					Name:     "author",
+					CodeName: "author",
+					Type:     "BelongsTo",
+					Target:   "author",
+				},
+			},
+		},
+		"author": &dao.Object{
+			ID:          "author",
+			Name:        "Author",
+			CodeName:    "Author",
+			Description: "This is a description of author.",
+			Attributes: []*dao.Attribute{
+				{
+					Name:     "testObjects",
+					CodeName: "testObjects",
+					Type:     "HasMany",
+					Target:   "testobject",
+					Via:      "author",
+				},
+				{
+					Name:        "name",
+					CodeName:    "name",
+					Type:        "Text",
+					Required:    true,
+					Description: "The name of the author.",
+					MinLength:   &minLength,
+					MaxLength:   &maxLength,
+					Pattern:     "^[A-Z]",
+				},
+				{
+					Name:     "email",
+					CodeName: "email",
+					Type:     "Email",
+				},
+				{
+					Name:     "birthday",
+					CodeName: "birthday",
+					Type:     "Date",
+				},
+				{
+					Name:     "genre",
+					CodeName: "genre",
+					Type:     "Enum",
+					Values:   []string{"fiction", "non-fiction"},
+				},
+			},
+		},
+	},
+}
+
+var renderTests = []struct {
+	name   string
+	render func(*dao.Project, string) ([]byte, error)
+	golden string
+}{
+	{
+		name:   "JSON",
+		render: JSON,
+		golden: "openapi.json",
+	},
+	{
+		name:   "YAML",
+		render: YAML,
+		golden: "openapi.yaml",
+	},
+}
+
+func TestRender(t *testing.T) {
+	for _, test := range renderTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			want, err := ioutil.ReadFile(filepath.Join("testdata", test.golden))
+			if err != nil {
+				t.Fatalf("Error reading golden file: %v", err)
+			}
+
+			// Execute
+			got, err := test.render(project, "")
+
+			// Verify
+			if err != nil {
+				t.Errorf("Got error rendering document: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("Got incorrect document:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+var buildErrorTests = []struct {
+	name    string
+	project *dao.Project
+	target  string
+	wantErr error
+}{
+	{
+		name:    "NilProject",
+		wantErr: errors.NewServer("Project cannot be nil"),
+	},
+	{
+		name:    "UnsupportedTarget",
+		project: &dao.Project{},
+		target:  "rails",
+		wantErr: errors.NewClient("Unsupported target `rails`. Supported targets are: express, gohttp, sails"),
+	},
+	{
+		name: "NilObject",
+		project: &dao.Project{
+			Objects: map[string]*dao.Object{"book": nil},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Object cannot be nil"), "Failed to create schema for object book"),
+	},
+	{
+		name: "InvalidAttributeType",
+		project: &dao.Project{
+			Objects: map[string]*dao.Object{
+				"book": &dao.Object{
+					ID:         "book",
+					Name:       "Book",
+					CodeName:   "Book",
+					Attributes: []*dao.Attribute{{Name: "title", CodeName: "title", Type: "InvalidType"}},
+				},
+			},
+		},
+		wantErr: errors.Wrap(errors.Wrap(errors.NewServer("Invalid attribute type: InvalidType"), "Failed to create schema for attribute"), "Failed to create schema for object book"),
+	},
+	{
+		name: "MissingEndpointObject",
+		project: &dao.Project{
+			Endpoints: map[string]*dao.Endpoint{
+				"findBooks": {ID: "findBooks", CodeName: "find", Method: "GET", Path: "/books", Object: "book", Operation: "list"},
+			},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Object `book` does not exist"), "Failed to add path for endpoint findBooks"),
+	},
+}
+
+func TestBuildErrors(t *testing.T) {
+	for _, test := range buildErrorTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			document, err := Build(test.project, test.target)
+
+			// Verify
+			if document != nil {
+				t.Errorf("Got document %v; want nil", document)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+var idSchemaTests = []struct {
+	name       string
+	target     string
+	wantSchema *Schema
+}{
+	{
+		name:       "DefaultTarget",
+		wantSchema: &Schema{Type: "integer"},
+	},
+	{
+		name:       "Express",
+		target:     "express",
+		wantSchema: &Schema{Type: "string", Pattern: "^[0-9a-fA-F]{24}$"},
+	},
+	{
+		name:       "Go",
+		target:     "gohttp",
+		wantSchema: &Schema{Type: "integer", Format: "int64"},
+	},
+}
+
+func TestIDSchema(t *testing.T) {
+	for _, test := range idSchemaTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			document, err := Build(project, test.target)
+
+			// Verify
+			if err != nil {
+				t.Fatalf("Got error building document: %v", err)
+			}
+			parameter := document.Paths["/testobject/{id}"].Parameters[0].Schema
+			if !reflect.DeepEqual(parameter, test.wantSchema) {
+				t.Errorf("Got parameter schema %+v; want %+v", parameter, test.wantSchema)
+			}
+			property := document.Components.Schemas["TestObject"].Properties["id"]
+			if property.Type != test.wantSchema.Type || property.Format != test.wantSchema.Format || !property.ReadOnly {
+				t.Errorf("Got property schema %+v; want read-only %+v", property, test.wantSchema)
+			}
+		})
+	}
+}
+
+var customEndpoints = map[string]*dao.Endpoint{
+	"searchAuthors": {
+		ID:          "searchAuthors",
+		Name:        "Search authors",
+		CodeName:    "search",
+		Description: "Finds authors by name.",
+		Method:      "GET",
+		Path:        "/authors",
+		Object:      "author",
+		Operation:   "list",
+		Filters:     []*dao.Filter{{Attribute: "name", Operator: "startsWith", Parameter: "prefix"}},
+	},
+	"replaceBook": {
+		ID:           "replaceBook",
+		Name:         "Replace book",
+		CodeName:     "replace",
+		Method:       "PUT",
+		Path:         "/authors/:authorId/books/:id",
+		Object:       "testobject",
+		Operation:    "update",
+		AuthRequired: true,
+	},
+	"publishBook": {
+		ID:        "publishBook",
+		Name:      "Publish book",
+		CodeName:  "publish",
+		Method:    "POST",
+		Path:      "/authors/:authorId/books/:id",
+		Object:    "testobject",
+		Operation: "custom",
+	},
+}
+
+func TestCustomEndpoints(t *testing.T) {
+	// Setup
+	custom := *project
+	custom.Endpoints = customEndpoints
+
+	// Execute
+	document, err := Build(&custom, "sails")
+
+	// Verify
+	if err != nil {
+		t.Fatalf("Got error building document: %v", err)
+	}
+	if len(document.Paths) != 2 {
+		t.Errorf("Got paths %v; want /authors and /authors/{authorId}/books/{id}", document.Paths)
+	}
+
+	search := document.Paths["/authors"].Get
+	wantSearch := &Operation{
+		OperationID: "searchAuthor",
+		Summary:     "List records of Author",
+		Description: "Finds authors by name.",
+		Tags:        []string{"Author"},
+		Parameters: []*Parameter{
+			{Name: "prefix", In: "query", Description: "Only returns records whose name starts with this value", Schema: &Schema{Type: "string"}},
+		},
+		Responses: map[string]*Response{
+			"200": {Description: "Every record", Content: jsonContent(&Schema{Type: "array", Items: ref("Author")})},
+		},
+	}
+	if !reflect.DeepEqual(search, wantSearch) {
+		t.Errorf("Got search operation %+v; want %+v", search, wantSearch)
+	}
+
+	item := document.Paths["/authors/{authorId}/books/{id}"]
+	wantParameters := []*Parameter{
+		{Name: "authorId", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+		{Name: "id", In: "path", Description: "The ID of the record", Required: true, Schema: &Schema{Type: "integer"}},
+	}
+	if !reflect.DeepEqual(item.Parameters, wantParameters) {
+		t.Errorf("Got path parameters %+v; want %+v", item.Parameters, wantParameters)
+	}
+	if item.Put == nil || item.Put.OperationID != "replaceTestObject" || item.Put.Responses["403"] == nil {
+		t.Errorf("Got replace operation %+v; want replaceTestObject with a 403 response", item.Put)
+	}
+	if item.Post == nil || item.Post.Summary != "Publish book" || item.Post.Responses["501"] == nil {
+		t.Errorf("Got publish operation %+v; want Publish book with a 501 response", item.Post)
+	}
+}
+
+func TestDefaultEndpointsOnlyTarget(t *testing.T) {
+	// Setup
+	custom := *project
+	custom.Endpoints = customEndpoints
+
+	// Execute
+	document, err := Build(&custom, "express")
+
+	// Verify
+	if err != nil {
+		t.Fatalf("Got error building document: %v", err)
+	}
+	for _, path := range []string{"/author", "/author/{id}", "/testobject", "/testobject/{id}"} {
+		if document.Paths[path] == nil {
+			t.Errorf("Missing path %s", path)
+		}
+	}
+	if len(document.Paths) != 4 {
+		t.Errorf("Got %d paths; want only the 4 default paths", len(document.Paths))
+	}
+}
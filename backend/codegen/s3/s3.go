@@ -24,16 +24,44 @@ type uploader interface {
 	Upload(*s3manager.UploadInput, ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error)
 }
 
+// batchDeleter wraps the Delete function to support dependency injection of the batch delete service.
+type batchDeleter interface {
+	Delete(aws.Context, s3manager.BatchDeleteIterator) error
+}
+
 // The session the S3 manager and service will use
 var sess = session.New()
 
 // Default services to use when running on lambda
 var defaultDownloader = s3manager.NewDownloader(sess)
 var defaultUploader = s3manager.NewUploader(sess)
+var defaultBatchDeleter = s3manager.NewBatchDelete(sess)
 
 // Wrappers on the services that should be changed only for dependency injection in unit tests.
 var downloadSvc downloader = defaultDownloader
 var uploadSvc uploader = defaultUploader
+var batchDeleteSvc batchDeleter = defaultBatchDeleter
+
+// ProjectPrefix returns the key prefix under which every generated artifact of the given user's project
+// is stored. Deleting this prefix removes all of the project's artifacts.
+func ProjectPrefix(email string, projectID string) string {
+	return email + "/" + projectID + "/"
+}
+
+// DeletePrefix deletes every file in AWS S3 whose key starts with the given prefix. The prefix must not
+// be empty, as that would delete every file in the bucket.
+func DeletePrefix(prefix string) error {
+	if prefix == "" {
+		return errors.NewServer("Prefix cannot be empty")
+	}
+
+	iterator := s3manager.NewDeleteListIterator(s3.New(sess), &s3.ListObjectsInput{
+		Bucket: aws.String(os.Getenv("BUCKET_NAME")),
+		Prefix: aws.String(prefix),
+	})
+	err := batchDeleteSvc.Delete(aws.BackgroundContext(), iterator)
+	return errors.Wrap(err, "Failed to delete files from S3")
+}
 
 // Download retrieves the file with the given key from AWS S3 and saves it in the local filesystem
 // at the specified local path.
@@ -56,12 +84,17 @@ func Download(localPath string, s3Key string) error {
 
 // Presign returns a presigned URL for the specified AWS S3 key. The key expires in 10 minutes.
 func Presign(s3Key string) (string, error) {
+	return PresignFor(s3Key, 10*time.Minute)
+}
+
+// PresignFor returns a presigned URL for the specified AWS S3 key that expires after the given duration.
+func PresignFor(s3Key string, expiry time.Duration) (string, error) {
 	svc := s3.New(sess)
 	req, _ := svc.GetObjectRequest(&s3.GetObjectInput{
 		Bucket: aws.String(os.Getenv("BUCKET_NAME")),
 		Key:    aws.String(s3Key),
 	})
-	url, err := req.Presign(10 * time.Minute)
+	url, err := req.Presign(expiry)
 	return url, errors.Wrap(err, "Failed to presign S3 request")
 }
 
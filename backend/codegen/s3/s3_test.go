@@ -165,3 +165,68 @@ func TestUpload(t *testing.T) {
 		})
 	}
 }
+
+// --------------- DeletePrefix Tests ----------------------
+
+type batchDeleteFunc func(aws.Context, s3manager.BatchDeleteIterator) error
+
+func (f batchDeleteFunc) Delete(ctx aws.Context, iterator s3manager.BatchDeleteIterator) error {
+	return f(ctx, iterator)
+}
+
+func batchDeleteMock(mockErr error) batchDeleteFunc {
+	return func(_ aws.Context, iterator s3manager.BatchDeleteIterator) error {
+		if iterator == nil {
+			return errors.NewServer("Incorrect mock input")
+		}
+		return mockErr
+	}
+}
+
+var deletePrefixTests = []struct {
+	name string
+
+	// Input
+	prefix string
+
+	// Mock data
+	mockErr error
+
+	// Expected output
+	wantErr error
+}{
+	{
+		name:    "EmptyPrefix",
+		wantErr: errors.NewServer("Prefix cannot be empty"),
+	},
+	{
+		name:    "DeleteError",
+		prefix:  ProjectPrefix("test@example.com", "projectID"),
+		mockErr: errors.NewServer("Delete failure"),
+		wantErr: errors.Wrap(errors.NewServer("Delete failure"), "Failed to delete files from S3"),
+	},
+	{
+		name:   "SuccessfulInvocation",
+		prefix: ProjectPrefix("test@example.com", "projectID"),
+	},
+}
+
+func TestDeletePrefix(t *testing.T) {
+	for _, test := range deletePrefixTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			batchDeleteSvc = batchDeleteMock(test.mockErr)
+			defer func() {
+				batchDeleteSvc = defaultBatchDeleter
+			}()
+
+			// Execute
+			err := DeletePrefix(test.prefix)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package sails
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/endpoints"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/types"
+)
+
+// actionTemplates maps each operation to the body of the controller action that performs it. The model
+// name of the object is substituted for every %[1]s verb and the criteria of the list query, if any, are
+// substituted for the %[2]s verb.
+var actionTemplates = map[string]string{
+	endpoints.List: `		const records = await %[1]s.find(%[2]s);
+		return res.json(records);
+`,
+	endpoints.Get: `		const record = await %[1]s.findOne({ id: req.param('id') });
+		if (!record) {
+			return res.notFound();
+		}
+		return res.json(record);
+`,
+	endpoints.Create: `		const record = await %[1]s.create(req.body).fetch();
+		return res.status(201).json(record);
+`,
+	endpoints.Update: `		const record = await %[1]s.updateOne({ id: req.param('id') }).set(req.body);
+		if (!record) {
+			return res.notFound();
+		}
+		return res.json(record);
+`,
+	endpoints.Delete: `		const record = await %[1]s.destroyOne({ id: req.param('id') });
+		if (!record) {
+			return res.notFound();
+		}
+		return res.json(record);
+`,
+	endpoints.Custom: `		// TODO: Implement custom logic for this endpoint using the %[1]s model.
+		return res.status(501).json({ error: 'Not implemented' });
+`,
+}
+
+// waterlineOperators maps each filter operator other than eq to the Waterline criteria modifier that
+// implements it.
+var waterlineOperators = map[string]string{
+	endpoints.NotEqual:           "!=",
+	endpoints.LessThan:           "<",
+	endpoints.LessThanOrEqual:    "<=",
+	endpoints.GreaterThan:        ">",
+	endpoints.GreaterThanOrEqual: ">=",
+	endpoints.Contains:           "contains",
+	endpoints.StartsWith:         "startsWith",
+}
+
+// filterValue returns the JavaScript expression that reads the query parameter of the given filter and
+// converts it to the type of the given attribute.
+func filterValue(filter *dao.Filter, attribute *dao.Attribute) (string, error) {
+	value := fmt.Sprintf("req.param('%s')", filter.Parameter)
+	attributeType, err := types.Lookup(attribute.Type)
+	if err != nil {
+		return "", errors.NewServer("Invalid attribute type: " + attribute.Type)
+	}
+	switch attributeType.OpenAPI.Type {
+	case "integer", "number":
+		return "Number(" + value + ")", nil
+	case "boolean":
+		return value + " === 'true'", nil
+	}
+	return value, nil
+}
+
+// writeQuery writes the statements that build the Waterline criteria of a list action from the filters of
+// the given endpoint into the where variable. A filter only applies when the request has its query parameter.
+func writeQuery(writer io.Writer, object *dao.Object, endpoint *dao.Endpoint) error {
+	builder := &strings.Builder{}
+	builder.WriteString("\t\tconst where = {};\n")
+	for _, filter := range endpoint.Filters {
+		var attribute *dao.Attribute
+		for _, a := range object.Attributes {
+			if a != nil && a.CodeName == filter.Attribute {
+				attribute = a
+			}
+		}
+		if attribute == nil {
+			return errors.NewServer(fmt.Sprintf("Object `%s` does not have attribute `%s`", object.Name, filter.Attribute))
+		}
+		value, err := filterValue(filter, attribute)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(builder, "\t\tif (req.param('%s') !== undefined) {\n", filter.Parameter)
+		if filter.Operator == endpoints.Equal {
+			fmt.Fprintf(builder, "\t\t\twhere.%s = %s;\n", attribute.CodeName, value)
+		} else {
+			fmt.Fprintf(builder, "\t\t\twhere.%[1]s = { ...where.%[1]s, '%[2]s': %[3]s };\n", attribute.CodeName, waterlineOperators[filter.Operator], value)
+		}
+		builder.WriteString("\t\t}\n")
+	}
+	_, err := io.WriteString(writer, builder.String())
+	return errors.Wrap(err, "Failed to write string")
+}
+
+// writeAction writes the controller action of the given endpoint, which operates on the given object, to
+// the given writer. Actions of endpoints that require authentication reject requests without a logged in
+// user. It should only be called when the writer is in the middle of writing a controller definition.
+func writeAction(writer io.Writer, object *dao.Object, endpoint *dao.Endpoint) error {
+	if endpoint == nil {
+		return errors.NewServer("Endpoint cannot be nil")
+	}
+	template, ok := actionTemplates[endpoint.Operation]
+	if !ok {
+		return errors.NewServer("Invalid operation: " + endpoint.Operation)
+	}
+
+	_, err := fmt.Fprintf(writer, "\t%s: async function(req, res) {\n", endpoint.CodeName)
+	if err != nil {
+		return errors.Wrap(err, "Failed to write string")
+	}
+	if endpoint.AuthRequired {
+		_, err = io.WriteString(writer, "\t\tif (!req.session.userId) {\n\t\t\treturn res.forbidden();\n\t\t}\n")
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+	criteria := ""
+	if len(endpoint.Filters) > 0 {
+		err = writeQuery(writer, object, endpoint)
+		if err != nil {
+			return err
+		}
+		criteria = "where"
+	}
+	_, err = fmt.Fprintf(writer, template, object.CodeName, criteria)
+	if err != nil {
+		return errors.Wrap(err, "Failed to write string")
+	}
+	_, err = io.WriteString(writer, "\t},\n")
+	return errors.Wrap(err, "Failed to write string")
+}
+
+// writeController writes the controller definition corresponding to the given object to the given writer.
+// The controller has one action for each of the given endpoints, named after the code name of the endpoint.
+func writeController(writer io.Writer, object *dao.Object, actions []*dao.Endpoint) error {
+	if object == nil {
+		return errors.NewServer("Object cannot be nil")
+	}
+
+	_, err := fmt.Fprintf(writer, "// api/controllers/%sController.js\n\nmodule.exports = {\n", object.CodeName)
+	if err != nil {
+		return errors.Wrap(err, "Failed to write string")
+	}
+	for i, endpoint := range actions {
+		if i > 0 {
+			_, err = io.WriteString(writer, "\n")
+			if err != nil {
+				return errors.Wrap(err, "Failed to write string")
+			}
+		}
+		err = writeAction(writer, object, endpoint)
+		if err != nil {
+			return errors.Wrap(err, "Failed to write action")
+		}
+	}
+	_, err = io.WriteString(writer, "}\n")
+	return errors.Wrap(err, "Failed to write string")
+}
@@ -0,0 +1,152 @@
+package sails
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/endpoints"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+var controllerObject = &dao.Object{
+	ID:       "book",
+	Name:     "Book",
+	CodeName: "Book",
+	Attributes: []*dao.Attribute{
+		{Name: "Title", CodeName: "title", Type: "Text"},
+		{Name: "Pages", CodeName: "pages", Type: "Integer"},
+		{Name: "Published", CodeName: "published", Type: "Boolean"},
+	},
+}
+
+var writeControllerTests = []struct {
+	name       string
+	object     *dao.Object
+	actions    []*dao.Endpoint
+	wantErr    error
+	wantPrefix string
+}{
+	{
+		name:    "NilObject",
+		wantErr: errors.NewServer("Object cannot be nil"),
+	},
+	{
+		name:    "NilEndpoint",
+		object:  controllerObject,
+		actions: []*dao.Endpoint{nil},
+		wantErr: errors.Wrap(errors.NewServer("Endpoint cannot be nil"), "Failed to write action"),
+		wantPrefix: "// api/controllers/BookController.js\n" +
+			"\n" +
+			"module.exports = {\n",
+	},
+	{
+		name:    "InvalidOperation",
+		object:  controllerObject,
+		actions: []*dao.Endpoint{{CodeName: "archive", Operation: "archive"}},
+		wantErr: errors.Wrap(errors.NewServer("Invalid operation: archive"), "Failed to write action"),
+	},
+	{
+		name:    "ValidObject",
+		object:  &dao.Object{ID: "testobject", Name: "testObject", CodeName: "TestObject"},
+		actions: endpoints.Defaults(&dao.Object{ID: "testobject", Name: "testObject", CodeName: "TestObject"}),
+		wantPrefix: "// api/controllers/TestObjectController.js\n" +
+			"\n" +
+			"module.exports = {\n" +
+			"\tlist: async function(req, res) {\n" +
+			"\t\tconst records = await TestObject.find();\n",
+	},
+	{
+		name:   "FilteredList",
+		object: controllerObject,
+		actions: []*dao.Endpoint{
+			{
+				CodeName:  "search",
+				Operation: "list",
+				Filters: []*dao.Filter{
+					{Attribute: "title", Operator: "contains", Parameter: "q"},
+					{Attribute: "pages", Operator: "gte", Parameter: "minPages"},
+					{Attribute: "pages", Operator: "lt", Parameter: "maxPages"},
+					{Attribute: "published", Operator: "eq", Parameter: "published"},
+				},
+			},
+		},
+		wantPrefix: "// api/controllers/BookController.js\n" +
+			"\n" +
+			"module.exports = {\n" +
+			"\tsearch: async function(req, res) {\n" +
+			"\t\tconst where = {};\n" +
+			"\t\tif (req.param('q') !== undefined) {\n" +
+			"\t\t\twhere.title = { ...where.title, 'contains': req.param('q') };\n" +
+			"\t\t}\n" +
+			"\t\tif (req.param('minPages') !== undefined) {\n" +
+			"\t\t\twhere.pages = { ...where.pages, '>=': Number(req.param('minPages')) };\n" +
+			"\t\t}\n" +
+			"\t\tif (req.param('maxPages') !== undefined) {\n" +
+			"\t\t\twhere.pages = { ...where.pages, '<': Number(req.param('maxPages')) };\n" +
+			"\t\t}\n" +
+			"\t\tif (req.param('published') !== undefined) {\n" +
+			"\t\t\twhere.published = req.param('published') === 'true';\n" +
+			"\t\t}\n" +
+			"\t\tconst records = await Book.find(where);\n" +
+			"\t\treturn res.json(records);\n" +
+			"\t},\n" +
+			"}\n",
+	},
+	{
+		name:   "FilterMissingAttribute",
+		object: controllerObject,
+		actions: []*dao.Endpoint{
+			{CodeName: "search", Operation: "list", Filters: []*dao.Filter{{Attribute: "isbn", Operator: "eq", Parameter: "isbn"}}},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Object `Book` does not have attribute `isbn`"), "Failed to write action"),
+	},
+	{
+		name:   "AuthRequiredAndCustom",
+		object: controllerObject,
+		actions: []*dao.Endpoint{
+			{CodeName: "publish", Operation: "update", AuthRequired: true},
+			{CodeName: "recommend", Operation: "custom"},
+		},
+		wantPrefix: "// api/controllers/BookController.js\n" +
+			"\n" +
+			"module.exports = {\n" +
+			"\tpublish: async function(req, res) {\n" +
+			"\t\tif (!req.session.userId) {\n" +
+			"\t\t\treturn res.forbidden();\n" +
+			"\t\t}\n" +
+			"\t\tconst record = await Book.updateOne({ id: req.param('id') }).set(req.body);\n" +
+			"\t\tif (!record) {\n" +
+			"\t\t\treturn res.notFound();\n" +
+			"\t\t}\n" +
+			"\t\treturn res.json(record);\n" +
+			"\t},\n" +
+			"\n" +
+			"\trecommend: async function(req, res) {\n" +
+			"\t\t// TODO: Implement custom logic for this endpoint using the Book model.\n" +
+			"\t\treturn res.status(501).json({ error: 'Not implemented' });\n" +
+			"\t},\n" +
+			"}\n",
+	},
+}
+
+func TestWriteController(t *testing.T) {
+	for _, test := range writeControllerTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			builder := &strings.Builder{}
+
+			// Execute
+			err := writeController(builder, test.object, test.actions)
+			gotString := builder.String()
+
+			// Verify
+			if !strings.HasPrefix(gotString, test.wantPrefix) {
+				t.Errorf("Got string: \n %s\n want prefix: %s", gotString, test.wantPrefix)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
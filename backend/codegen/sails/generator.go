@@ -1,3 +1,4 @@
+// Package sails generates the code for a project using the Sails.js framework.
 package sails
 
 import (
@@ -5,27 +6,77 @@ import (
 	"os"
 	"strings"
 
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/endpoints"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
 
+func init() {
+	codegen.Register(generator{})
+}
+
+// generator implements the codegen.Generator interface for the Sails.js framework.
+type generator struct{}
+
+// Name returns the name used to select the Sails.js generator.
+func (generator) Name() string {
+	return "sails"
+}
+
+// TemplateKey returns the S3 key of the zipped blank Sails.js project.
+func (generator) TemplateKey() string {
+	return "templates/sails.zip"
+}
+
+// Generate creates the Sails.js code for the given project. See the package-level Generate function.
+func (generator) Generate(project *dao.Project, rootDir string) error {
+	return Generate(project, rootDir)
+}
+
 // Generate creates the Sails.js code for the given project. It expects a blank Sails.js project located
-// at the path specified by rootDir.
+// at the path specified by rootDir. Routes and controller actions are generated from the endpoints of the
+// project, and objects without endpoints do not get a controller. Blueprint routes are disabled, so only those
+// routes are served.
 func Generate(project *dao.Project, rootDir string) error {
+	objects := codegen.SortedObjects(project)
+	projectEndpoints := endpoints.Project(project)
 
 	// Generate objects
-	for _, object := range project.Objects {
+	for _, object := range objects {
 		err := generateModel(object, rootDir)
 		if err != nil {
 			return errors.Wrap(err, "Failed to generate model for object "+object.Name)
 		}
+
+		var actions []*dao.Endpoint
+		for _, endpoint := range projectEndpoints {
+			if endpoint.Object == object.ID {
+				actions = append(actions, endpoint)
+			}
+		}
+		if len(actions) == 0 {
+			continue
+		}
+		err = generateController(object, actions, rootDir)
+		if err != nil {
+			return errors.Wrap(err, "Failed to generate controller for object "+object.Name)
+		}
+	}
+
+	// Generate endpoints
+	err := generateRoutes(project.Objects, projectEndpoints, rootDir)
+	if err != nil {
+		return errors.Wrap(err, "Failed to generate routes")
+	}
+	err = generateBlueprints(rootDir)
+	if err != nil {
+		return errors.Wrap(err, "Failed to generate blueprint config")
 	}
 
 	// Change database migration strategy configuration
-	err := setMigrationStrategy(rootDir)
+	err = setMigrationStrategy(rootDir)
 	return errors.Wrap(err, "Failed to set migration strategy")
-
-	// TODO: generate endpoints
 }
 
 func generateModel(object *dao.Object, rootDir string) error {
@@ -39,6 +90,40 @@ func generateModel(object *dao.Object, rootDir string) error {
 	return errors.Wrap(err, "Failed to write model file for object: "+object.CodeName)
 }
 
+func generateController(object *dao.Object, actions []*dao.Endpoint, rootDir string) error {
+	file, err := os.Create(rootDir + "/api/controllers/" + object.CodeName + "Controller.js")
+	if err != nil {
+		return errors.Wrap(err, "Failed to create controller file for object: "+object.CodeName)
+	}
+	defer file.Close()
+
+	err = writeController(file, object, actions)
+	return errors.Wrap(err, "Failed to write controller file for object: "+object.CodeName)
+}
+
+func generateRoutes(objects map[string]*dao.Object, endpoints []*dao.Endpoint, rootDir string) error {
+	filename := rootDir + "/config/routes.js"
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read config/routes.js file")
+	}
+
+	newContents, err := insertRoutes(string(contents), objects, endpoints)
+	if err != nil {
+		return errors.Wrap(err, "Failed to insert routes into config/routes.js file")
+	}
+
+	const fileMode os.FileMode = 0644
+	err = ioutil.WriteFile(filename, []byte(newContents), fileMode)
+	return errors.Wrap(err, "Failed to write config/routes.js file")
+}
+
+func generateBlueprints(rootDir string) error {
+	const fileMode os.FileMode = 0644
+	err := ioutil.WriteFile(rootDir+"/config/blueprints.js", []byte(blueprintsConfig), fileMode)
+	return errors.Wrap(err, "Failed to write config/blueprints.js file")
+}
+
 func setMigrationStrategy(rootDir string) error {
 	filename := rootDir + "/config/models.js"
 	contents, err := ioutil.ReadFile(filename)
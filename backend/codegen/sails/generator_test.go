@@ -6,14 +6,44 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/codegentest"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
 )
 
+// customProject only has custom endpoints for TestObject, one of which requires authentication, so the
+// generated code must not route any other request to either object.
+var customProject = &dao.Project{
+	Name:    project.Name,
+	Objects: project.Objects,
+	Endpoints: map[string]*dao.Endpoint{
+		"findObjects": {
+			ID:        "findObjects",
+			CodeName:  "find",
+			Method:    "GET",
+			Path:      "/objects",
+			Object:    "testobject",
+			Operation: "list",
+			Filters:   []*dao.Filter{{Attribute: "testAttribute", Operator: "gt", Parameter: "min"}},
+		},
+		"removeObject": {
+			ID:           "removeObject",
+			CodeName:     "remove",
+			Method:       "DELETE",
+			Path:         "/objects/:id",
+			Object:       "testobject",
+			Operation:    "delete",
+			AuthRequired: true,
+		},
+	},
+}
+
 var project = &dao.Project{
 	Name:        "Default Project",
 	Description: "Test project",
 	Objects: map[string]*dao.Object{
 		"testobject": &dao.Object{
+			ID:          "testobject",
 			Name:        "TestObject",
 			CodeName:    "TestObject",
 			Description: "This is a description of testobject.",
@@ -25,45 +55,100 @@ var project = &dao.Project{
 				},
 			},
 		},
+		"author": &dao.Object{
+			ID:          "author",
+			Name:        "Author",
+			CodeName:    "Author",
+			Description: "This is a description of author.",
+			Attributes: []*dao.Attribute{
+				{
+					Name:     "name",
+					CodeName: "name",
+					Type:     "Text",
+				},
+			},
+		},
 	},
 }
 
-const wantText = `// api/models/TestObject.js
-
-module.exports = {
-	attributes: {
-		testAttribute: {
-			type: 'number',
-		},
-	}
+// copyDir recursively copies the contents of the src directory into the dest directory.
+func copyDir(src string, dest string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, relPath)
+		if info.IsDir() {
+			return os.MkdirAll(target, os.ModePerm)
+		}
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, contents, info.Mode())
+	})
 }
-`
 
 func TestGenerate(t *testing.T) {
 	// Setup
-	wd, err := os.Getwd()
+	rootDir, err := ioutil.TempDir("", "sails")
 	if err != nil {
-		t.Errorf("Error getting working directory: %v", err)
+		t.Fatalf("Error creating temp directory: %v", err)
 	}
-	err = os.Remove(filepath.Join(wd, "testdata", "api", "models", "TestObject.js"))
-	if err != nil && !os.IsNotExist(err) {
-		t.Errorf("Error removing model file: %v", err)
+	defer os.RemoveAll(rootDir)
+
+	err = copyDir(filepath.Join("testdata", "template"), rootDir)
+	if err != nil {
+		t.Fatalf("Error copying project template: %v", err)
 	}
 
 	// Execute
-	err = Generate(project, filepath.Join(wd, "testdata"))
+	err = Generate(project, rootDir)
 	if err != nil {
 		t.Errorf("Got error generating project: %v", err)
 	}
 
 	// Verify
-	content, err := ioutil.ReadFile(filepath.Join(wd, "testdata", "api", "models", "TestObject.js"))
+	codegentest.CompareGolden(t, filepath.Join("testdata", "golden"), rootDir)
+}
+
+func TestGenerateCustomEndpoints(t *testing.T) {
+	// Setup
+	rootDir, err := ioutil.TempDir("", "sails")
+	if err != nil {
+		t.Fatalf("Error creating temp directory: %v", err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	err = copyDir(filepath.Join("testdata", "template"), rootDir)
+	if err != nil {
+		t.Fatalf("Error copying project template: %v", err)
+	}
+
+	// Execute
+	err = Generate(customProject, rootDir)
 	if err != nil {
-		t.Errorf("Failed to read model file: %v", err)
+		t.Errorf("Got error generating project: %v", err)
+	}
+
+	// Verify
+	codegentest.CompareGolden(t, filepath.Join("testdata", "custom"), rootDir)
+	_, err = os.Stat(filepath.Join(rootDir, "api", "controllers", "AuthorController.js"))
+	if !os.IsNotExist(err) {
+		t.Errorf("Got err %v for AuthorController.js; want it to not exist", err)
 	}
+}
 
-	text := string(content)
-	if text != wantText {
-		t.Errorf("Got incorrect text from model file:\n%s", text)
+func TestRegistered(t *testing.T) {
+	generator, err := codegen.Lookup("sails")
+	if err != nil {
+		t.Fatalf("Got error looking up sails generator: %v", err)
+	}
+	if generator.TemplateKey() != "templates/sails.zip" {
+		t.Errorf("Got template key %s; want templates/sails.zip", generator.TemplateKey())
 	}
 }
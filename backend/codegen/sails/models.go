@@ -1,10 +1,13 @@
 package sails
 
 import (
+	"fmt"
 	"io"
 
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/types"
 )
 
 // writeAttribute writes the given attribute to the given writer. It should only
@@ -22,28 +25,93 @@ func writeAttribute(writer io.Writer, attribute *dao.Attribute) error {
 	if err != nil {
 		return errors.Wrap(err, "Failed to write string")
 	}
-	_, err = io.WriteString(writer, ": {\n\t\t\ttype: ")
+	_, err = io.WriteString(writer, ": {\n")
 	if err != nil {
 		return errors.Wrap(err, "Failed to write string")
 	}
 
-	switch attribute.Type {
-	case "Text":
-		_, err = io.WriteString(writer, "'string',\n")
-	case "Integer":
-		_, err = io.WriteString(writer, "'number',\n")
-	default:
+	attributeType, err := types.Lookup(attribute.Type)
+	if err != nil {
 		return errors.NewServer("Invalid attribute type: " + attribute.Type)
 	}
-
+	if attributeType.Relation != types.None {
+		err = writeAssociation(writer, attribute, attributeType)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(writer, "\t\t},\n")
+		return errors.Wrap(err, "Failed to write string")
+	}
+	_, err = fmt.Fprintf(writer, "\t\t\ttype: '%s',\n", attributeType.Sails.Type)
 	if err != nil {
 		return errors.Wrap(err, "Failed to write string")
 	}
+	if attributeType.Sails.Rule != "" {
+		_, err = fmt.Fprintf(writer, "\t\t\t%s,\n", attributeType.Sails.Rule)
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+	if attributeType.HasValues {
+		_, err = fmt.Fprintf(writer, "\t\t\tisIn: [%s],\n", types.Quote(attribute.Values, "'"))
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+	for _, line := range constraintLines(attribute, attributeType) {
+		_, err = fmt.Fprintf(writer, "\t\t\t%s,\n", line)
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
 
 	_, err = io.WriteString(writer, "\t\t},\n")
 	return errors.Wrap(err, "Failed to write string")
 }
 
+// constraintLines returns the Waterline validation rules, default value and auto-migration settings that
+// implement the constraints of the given attribute.
+func constraintLines(attribute *dao.Attribute, attributeType *types.Type) []string {
+	var lines []string
+	if attribute.Min != nil {
+		lines = append(lines, "min: "+types.FormatNumber(*attribute.Min))
+	}
+	if attribute.Max != nil {
+		lines = append(lines, "max: "+types.FormatNumber(*attribute.Max))
+	}
+	if attribute.MinLength != nil {
+		lines = append(lines, fmt.Sprintf("minLength: %d", *attribute.MinLength))
+	}
+	if attribute.MaxLength != nil {
+		lines = append(lines, fmt.Sprintf("maxLength: %d", *attribute.MaxLength))
+	}
+	if attribute.Pattern != "" {
+		lines = append(lines, "regex: "+codegen.JSRegex(attribute.Pattern))
+	}
+	if value, ok := attributeType.Default(attribute); ok {
+		lines = append(lines, "defaultsTo: "+codegen.JSLiteral(value))
+	}
+	if attribute.Unique {
+		lines = append(lines, "autoMigrations: { unique: true }")
+	}
+	return lines
+}
+
+// writeAssociation writes the keys that define the given relationship attribute as a Waterline association.
+// Model identities are the lowercase model names, which are the IDs of the objects.
+func writeAssociation(writer io.Writer, attribute *dao.Attribute, attributeType *types.Type) error {
+	var err error
+	switch attributeType.Relation {
+	case types.BelongsTo:
+		_, err = fmt.Fprintf(writer, "\t\t\tmodel: '%s',\n", attribute.Target)
+	case types.HasMany:
+		_, err = fmt.Fprintf(writer, "\t\t\tcollection: '%s',\n\t\t\tvia: '%s',\n", attribute.Target, attribute.Via)
+	case types.ManyToMany:
+		_, err = fmt.Fprintf(writer, "\t\t\tcollection: '%s',\n\t\t\tvia: '%s',\n\t\t\tthrough: '%s',\n", attribute.Target, attribute.Via, attribute.Through)
+	}
+	return errors.Wrap(err, "Failed to write string")
+}
+
 // writeModel writes the model definition corresponding to the given object to
 // the given writer.
 func writeModel(writer io.Writer, object *dao.Object) error {
@@ -8,6 +8,9 @@ import (
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
 
+var one, thousand = 1.0, 1000.0
+var ten, thirteen = 10, 13
+
 var writeModelTests = []struct {
 	name       string
 	object     *dao.Object
@@ -119,6 +122,107 @@ var writeModelTests = []struct {
 			"\t}\n" +
 			"}\n",
 	},
+	{
+		name: "RichAttributeTypes",
+		object: &dao.Object{
+			ID:       "article",
+			Name:     "Article",
+			CodeName: "Article",
+			Attributes: []*dao.Attribute{
+				{Name: "published", CodeName: "published", Type: "Boolean"},
+				{Name: "author", CodeName: "author", Type: "Email"},
+				{Name: "metadata", CodeName: "metadata", Type: "JSON"},
+				{Name: "status", CodeName: "status", Type: "Enum", Values: []string{"draft", "published"}},
+			},
+		},
+		wantString: "// api/models/Article.js\n" +
+			"\n" +
+			"module.exports = {\n" +
+			"\tattributes: {\n" +
+			"\t\tpublished: {\n" +
+			"\t\t\ttype: 'boolean',\n" +
+			"\t\t},\n" +
+			"\t\tauthor: {\n" +
+			"\t\t\ttype: 'string',\n" +
+			"\t\t\tisEmail: true,\n" +
+			"\t\t},\n" +
+			"\t\tmetadata: {\n" +
+			"\t\t\ttype: 'json',\n" +
+			"\t\t},\n" +
+			"\t\tstatus: {\n" +
+			"\t\t\ttype: 'string',\n" +
+			"\t\t\tisIn: ['draft', 'published'],\n" +
+			"\t\t},\n" +
+			"\t}\n" +
+			"}\n",
+	},
+	{
+		name: "Associations",
+		object: &dao.Object{
+			ID:       "author",
+			Name:     "Author",
+			CodeName: "Author",
+			Attributes: []*dao.Attribute{
+				{Name: "publisher", CodeName: "publisher", Type: "BelongsTo", Target: "publisher"},
+				{Name: "reviews", CodeName: "reviews", Type: "HasMany", Target: "review", Via: "author"},
+				{Name: "books", CodeName: "books", Type: "ManyToMany", Target: "book", Via: "author", Through: "authorship"},
+			},
+		},
+		wantString: "// api/models/Author.js\n" +
+			"\n" +
+			"module.exports = {\n" +
+			"\tattributes: {\n" +
+			"\t\tpublisher: {\n" +
+			"\t\t\tmodel: 'publisher',\n" +
+			"\t\t},\n" +
+			"\t\treviews: {\n" +
+			"\t\t\tcollection: 'review',\n" +
+			"\t\t\tvia: 'author',\n" +
+			"\t\t},\n" +
+			"\t\tbooks: {\n" +
+			"\t\t\tcollection: 'book',\n" +
+			"\t\t\tvia: 'author',\n" +
+			"\t\t\tthrough: 'authorship',\n" +
+			"\t\t},\n" +
+			"\t}\n" +
+			"}\n",
+	},
+	{
+		name: "Constraints",
+		object: &dao.Object{
+			ID:       "book",
+			Name:     "Book",
+			CodeName: "Book",
+			Attributes: []*dao.Attribute{
+				{Name: "pages", CodeName: "pages", Type: "Integer", Min: &one, Max: &thousand, Default: "100"},
+				{Name: "isbn", CodeName: "isbn", Type: "Text", MinLength: &ten, MaxLength: &thirteen, Pattern: "^[0-9/]+$", Unique: true},
+				{Name: "title", CodeName: "title", Type: "Text", Default: "Untitled 'draft'"},
+			},
+		},
+		wantString: "// api/models/Book.js\n" +
+			"\n" +
+			"module.exports = {\n" +
+			"\tattributes: {\n" +
+			"\t\tpages: {\n" +
+			"\t\t\ttype: 'number',\n" +
+			"\t\t\tmin: 1,\n" +
+			"\t\t\tmax: 1000,\n" +
+			"\t\t\tdefaultsTo: 100,\n" +
+			"\t\t},\n" +
+			"\t\tisbn: {\n" +
+			"\t\t\ttype: 'string',\n" +
+			"\t\t\tminLength: 10,\n" +
+			"\t\t\tmaxLength: 13,\n" +
+			"\t\t\tregex: /^[0-9\\/]+$/,\n" +
+			"\t\t\tautoMigrations: { unique: true },\n" +
+			"\t\t},\n" +
+			"\t\ttitle: {\n" +
+			"\t\t\ttype: 'string',\n" +
+			"\t\t\tdefaultsTo: 'Untitled \\'draft\\'',\n" +
+			"\t\t},\n" +
+			"\t}\n" +
+			"}\n",
+	},
 }
 
 func TestWriteModel(t *testing.T) {
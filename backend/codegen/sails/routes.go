@@ -0,0 +1,77 @@
+package sails
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// routesDeclaration is the line of config/routes.js after which the generated routes are inserted.
+const routesDeclaration = "module.exports.routes = {\n"
+
+// blueprintsConfig is the contents of config/blueprints.js. Every route is generated in config/routes.js, so the
+// blueprint action, REST and shortcut routes are disabled; otherwise they would expose every model without the
+// authentication required by its endpoints.
+const blueprintsConfig = `/**
+ * Blueprint API Configuration
+ * (sails.config.blueprints)
+ *
+ * Generated by CRUD Creator. Every route is defined in config/routes.js, so blueprint routes are disabled.
+ */
+
+module.exports.blueprints = {
+
+  actions: false,
+  rest: false,
+  shortcuts: false,
+
+};
+`
+
+// writeRoutes writes the route definitions for the given endpoints to the given writer. Each endpoint is
+// routed to the action with its code name in the controller of its object, which is looked up in the given
+// map of object IDs to objects. It should only be called when the writer is in the middle of writing the
+// routes object of config/routes.js.
+func writeRoutes(writer io.Writer, objects map[string]*dao.Object, endpoints []*dao.Endpoint) error {
+	_, err := io.WriteString(writer, "\n  // Routes generated by CRUD Creator\n")
+	if err != nil {
+		return errors.Wrap(err, "Failed to write string")
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint == nil {
+			return errors.NewServer("Endpoint cannot be nil")
+		}
+		object := objects[endpoint.Object]
+		if object == nil {
+			return errors.NewServer(fmt.Sprintf("Object `%s` does not exist", endpoint.Object))
+		}
+		_, err = fmt.Fprintf(writer, "  '%s %s': '%sController.%s',\n", endpoint.Method, endpoint.Path, object.CodeName, endpoint.CodeName)
+		if err != nil {
+			return errors.Wrap(err, "Failed to write string")
+		}
+	}
+	return nil
+}
+
+// insertRoutes inserts the route definitions for the given endpoints at the start of the routes object
+// in the given contents of config/routes.js. If the routes object cannot be found, an error is returned.
+func insertRoutes(contents string, objects map[string]*dao.Object, endpoints []*dao.Endpoint) (string, error) {
+	index := strings.Index(contents, routesDeclaration)
+	if index < 0 {
+		return "", errors.NewServer("Routes declaration not found")
+	}
+	index += len(routesDeclaration)
+
+	builder := &strings.Builder{}
+	builder.WriteString(contents[:index])
+	err := writeRoutes(builder, objects, endpoints)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to write routes")
+	}
+	builder.WriteString(contents[index:])
+	return builder.String(), nil
+}
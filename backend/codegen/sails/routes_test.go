@@ -0,0 +1,99 @@
+package sails
+
+import (
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/endpoints"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+var routesObjects = map[string]*dao.Object{
+	"author": {ID: "author", Name: "Author", CodeName: "Author"},
+	"book":   {ID: "book", Name: "book", CodeName: "Book"},
+}
+
+var insertRoutesTests = []struct {
+	name         string
+	contents     string
+	endpoints    []*dao.Endpoint
+	wantContents string
+	wantErr      error
+}{
+	{
+		name:     "MissingDeclaration",
+		contents: "module.exports = {};\n",
+		wantErr:  errors.NewServer("Routes declaration not found"),
+	},
+	{
+		name:      "NilEndpoint",
+		contents:  "module.exports.routes = {\n};\n",
+		endpoints: []*dao.Endpoint{nil},
+		wantErr:   errors.Wrap(errors.NewServer("Endpoint cannot be nil"), "Failed to write routes"),
+	},
+	{
+		name:      "MissingObject",
+		contents:  "module.exports.routes = {\n};\n",
+		endpoints: []*dao.Endpoint{{ID: "listPublishers", CodeName: "list", Method: "GET", Path: "/publishers", Object: "publisher"}},
+		wantErr:   errors.Wrap(errors.NewServer("Object `publisher` does not exist"), "Failed to write routes"),
+	},
+	{
+		name:     "NoEndpoints",
+		contents: "module.exports.routes = {\n  '/': { view: 'pages/homepage' },\n};\n",
+		wantContents: "module.exports.routes = {\n" +
+			"\n" +
+			"  // Routes generated by CRUD Creator\n" +
+			"  '/': { view: 'pages/homepage' },\n" +
+			"};\n",
+	},
+	{
+		name:      "DefaultEndpoints",
+		contents:  "module.exports.routes = {\n};\n",
+		endpoints: append(endpoints.Defaults(routesObjects["author"]), endpoints.Defaults(routesObjects["book"])...),
+		wantContents: "module.exports.routes = {\n" +
+			"\n" +
+			"  // Routes generated by CRUD Creator\n" +
+			"  'GET /author': 'AuthorController.list',\n" +
+			"  'GET /author/:id': 'AuthorController.get',\n" +
+			"  'POST /author': 'AuthorController.create',\n" +
+			"  'PATCH /author/:id': 'AuthorController.update',\n" +
+			"  'DELETE /author/:id': 'AuthorController.delete',\n" +
+			"  'GET /book': 'BookController.list',\n" +
+			"  'GET /book/:id': 'BookController.get',\n" +
+			"  'POST /book': 'BookController.create',\n" +
+			"  'PATCH /book/:id': 'BookController.update',\n" +
+			"  'DELETE /book/:id': 'BookController.delete',\n" +
+			"};\n",
+	},
+	{
+		name:     "CustomEndpoints",
+		contents: "module.exports.routes = {\n};\n",
+		endpoints: []*dao.Endpoint{
+			{ID: "authorBooks", CodeName: "byAuthor", Method: "GET", Path: "/authors/:authorId/books", Object: "book", Operation: "custom"},
+			{ID: "publishBook", CodeName: "publish", Method: "PUT", Path: "/books/:id/publish", Object: "book", Operation: "update"},
+		},
+		wantContents: "module.exports.routes = {\n" +
+			"\n" +
+			"  // Routes generated by CRUD Creator\n" +
+			"  'GET /authors/:authorId/books': 'BookController.byAuthor',\n" +
+			"  'PUT /books/:id/publish': 'BookController.publish',\n" +
+			"};\n",
+	},
+}
+
+func TestInsertRoutes(t *testing.T) {
+	for _, test := range insertRoutesTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			contents, err := insertRoutes(test.contents, routesObjects, test.endpoints)
+
+			// Verify
+			if contents != test.wantContents {
+				t.Errorf("Got contents:\n%s\nwant:\n%s", contents, test.wantContents)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
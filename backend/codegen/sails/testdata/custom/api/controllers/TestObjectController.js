@@ -0,0 +1,23 @@
+// api/controllers/TestObjectController.js
+
+module.exports = {
+	find: async function(req, res) {
+		const where = {};
+		if (req.param('min') !== undefined) {
+			where.testAttribute = { ...where.testAttribute, '>': Number(req.param('min')) };
+		}
+		const records = await TestObject.find(where);
+		return res.json(records);
+	},
+
+	remove: async function(req, res) {
+		if (!req.session.userId) {
+			return res.forbidden();
+		}
+		const record = await TestObject.destroyOne({ id: req.param('id') });
+		if (!record) {
+			return res.notFound();
+		}
+		return res.json(record);
+	},
+}
@@ -0,0 +1,16 @@
+/**
+ * Route Mappings
+ * (sails.config.routes)
+ *
+ * Your routes tell Sails what to do each time it receives a request.
+ */
+
+module.exports.routes = {
+
+  // Routes generated by CRUD Creator
+  'GET /objects': 'TestObjectController.find',
+  'DELETE /objects/:id': 'TestObjectController.remove',
+
+  '/': { view: 'pages/homepage' },
+
+};
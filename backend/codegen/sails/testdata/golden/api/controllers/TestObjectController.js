@@ -0,0 +1,37 @@
+// api/controllers/TestObjectController.js
+
+module.exports = {
+	list: async function(req, res) {
+		const records = await TestObject.find();
+		return res.json(records);
+	},
+
+	get: async function(req, res) {
+		const record = await TestObject.findOne({ id: req.param('id') });
+		if (!record) {
+			return res.notFound();
+		}
+		return res.json(record);
+	},
+
+	create: async function(req, res) {
+		const record = await TestObject.create(req.body).fetch();
+		return res.status(201).json(record);
+	},
+
+	update: async function(req, res) {
+		const record = await TestObject.updateOne({ id: req.param('id') }).set(req.body);
+		if (!record) {
+			return res.notFound();
+		}
+		return res.json(record);
+	},
+
+	delete: async function(req, res) {
+		const record = await TestObject.destroyOne({ id: req.param('id') });
+		if (!record) {
+			return res.notFound();
+		}
+		return res.json(record);
+	},
+}
@@ -0,0 +1,9 @@
+// api/models/Author.js
+
+module.exports = {
+	attributes: {
+		name: {
+			type: 'string',
+		},
+	}
+}
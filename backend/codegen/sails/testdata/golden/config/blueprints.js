@@ -0,0 +1,14 @@
+/**
+ * Blueprint API Configuration
+ * (sails.config.blueprints)
+ *
+ * Generated by CRUD Creator. Every route is defined in config/routes.js, so blueprint routes are disabled.
+ */
+
+module.exports.blueprints = {
+
+  actions: false,
+  rest: false,
+  shortcuts: false,
+
+};
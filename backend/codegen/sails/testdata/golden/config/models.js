@@ -0,0 +1,19 @@
+/**
+ * Default model settings
+ * (sails.config.models)
+ *
+ * Your default, project-wide model settings. Can also be overridden on a
+ * per-model basis by setting a top-level properties in the model definition.
+ */
+
+module.exports.models = {
+
+  migrate: 'alter',
+
+  attributes: {
+    createdAt: { type: 'number', autoCreatedAt: true, },
+    updatedAt: { type: 'number', autoUpdatedAt: true, },
+    id: { type: 'number', autoIncrement: true, },
+  },
+
+};
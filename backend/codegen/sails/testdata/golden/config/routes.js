@@ -0,0 +1,24 @@
+/**
+ * Route Mappings
+ * (sails.config.routes)
+ *
+ * Your routes tell Sails what to do each time it receives a request.
+ */
+
+module.exports.routes = {
+
+  // Routes generated by CRUD Creator
+  'GET /author': 'AuthorController.list',
+  'GET /author/:id': 'AuthorController.get',
+  'POST /author': 'AuthorController.create',
+  'PATCH /author/:id': 'AuthorController.update',
+  'DELETE /author/:id': 'AuthorController.delete',
+  'GET /testobject': 'TestObjectController.list',
+  'GET /testobject/:id': 'TestObjectController.get',
+  'POST /testobject': 'TestObjectController.create',
+  'PATCH /testobject/:id': 'TestObjectController.update',
+  'DELETE /testobject/:id': 'TestObjectController.delete',
+
+  '/': { view: 'pages/homepage' },
+
+};
@@ -0,0 +1,12 @@
+/**
+ * Route Mappings
+ * (sails.config.routes)
+ *
+ * Your routes tell Sails what to do each time it receives a request.
+ */
+
+module.exports.routes = {
+
+  '/': { view: 'pages/homepage' },
+
+};
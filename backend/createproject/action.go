@@ -0,0 +1,47 @@
+package createproject
+
+import (
+	"github.com/google/uuid"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// createProjectDatabase wraps the database methods required to perform the createProject action.
+// This allows for dependency injection of the database.
+type createProjectDatabase interface {
+	auth.CredentialStore
+	CreateProject(string, *dao.Project) error
+}
+
+// newProjectID points to the function used to generate the IDs of new projects. It should not be
+// changed except in unit tests, when performing dependency injection.
+var newProjectID = func() string {
+	return uuid.New().String()
+}
+
+// createProject creates a new project with the given name and description for the user associated with
+// the given cookie. The new project has a randomly generated ID and no objects. If the project is created
+// successfully, it is returned. Otherwise, createProject returns a nil project along with the error.
+func createProject(cookie string, name string, description string, verifyCookie auth.VerifyCookieFunc, db createProjectDatabase) (*dao.Project, error) {
+	if name == "" {
+		return nil, errors.NewClient("Parameter `name` is required")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return nil, errors.NewClient("Not authenticated")
+	}
+
+	project := &dao.Project{
+		ID:          newProjectID(),
+		Name:        name,
+		Description: description,
+		Objects:     map[string]*dao.Object{},
+	}
+	err = db.CreateProject(email, project)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create project in database")
+	}
+	return project, nil
+}
@@ -0,0 +1,128 @@
+package createproject
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email   string
+	project *dao.Project
+	err     error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) CreateProject(email string, project *dao.Project) error {
+	if email != mock.email || !reflect.DeepEqual(project, mock.project) {
+		return errors.NewServer("Incorrect input to CreateProject mock")
+	}
+	return mock.err
+}
+
+var createProjectTests = []struct {
+	name string
+
+	// Input
+	cookie      string
+	projectName string
+	description string
+
+	// Mock data
+	db        *databaseMock
+	email     string
+	verifyErr error
+
+	// Expected output
+	wantProject *dao.Project
+	wantErr     error
+}{
+	{
+		name:    "EmptyName",
+		cookie:  "cookie",
+		wantErr: errors.NewClient("Parameter `name` is required"),
+	},
+	{
+		name:        "InvalidCookie",
+		cookie:      "cookie",
+		projectName: "Project",
+		verifyErr:   errors.NewClient("Invalid cookie"),
+		wantErr:     errors.NewClient("Not authenticated"),
+	},
+	{
+		name:        "DatabaseFailure",
+		cookie:      "cookie",
+		projectName: "Project",
+		description: "desc",
+		db: &databaseMock{
+			email:   "test@example.com",
+			project: &dao.Project{ID: "projectID", Name: "Project", Description: "desc", Objects: map[string]*dao.Object{}},
+			err:     errors.NewClient("Project 'projectID' already exists"),
+		},
+		email:   "test@example.com",
+		wantErr: errors.Wrap(errors.NewClient("Project 'projectID' already exists"), "Failed to create project in database"),
+	},
+	{
+		name:        "SuccessfulInvocation",
+		cookie:      "cookie",
+		projectName: "Project",
+		description: "desc",
+		db: &databaseMock{
+			email:   "test@example.com",
+			project: &dao.Project{ID: "projectID", Name: "Project", Description: "desc", Objects: map[string]*dao.Object{}},
+		},
+		email:       "test@example.com",
+		wantProject: &dao.Project{ID: "projectID", Name: "Project", Description: "desc", Objects: map[string]*dao.Object{}},
+	},
+}
+
+func TestCreateProject(t *testing.T) {
+	for _, test := range createProjectTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock(test.cookie, test.db, test.email, test.verifyErr)
+			newProjectID = func() string {
+				return "projectID"
+			}
+
+			// Execute
+			project, err := createProject(test.cookie, test.projectName, test.description, verifyCookie, test.db)
+
+			// Verify
+			if !reflect.DeepEqual(project, test.wantProject) {
+				t.Errorf("Got project %v; want %v", project, test.wantProject)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Package createproject handles requests to the POST /projects REST API endpoint.
+package createproject
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// createProjectRequest contains the fields passed in the API JSON request body.
+type createProjectRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// createProjectResponse contains the fields returned in the API JSON response body.
+type createProjectResponse struct {
+	Project *dao.Project `json:"project,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+func (response *createProjectResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// createProjectFunc points to the function used to perform the createProject action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var createProjectFunc = createProject
+
+// HandleCreateProject parses the request object from AWS APIGateway and passes it to the createProject action.
+// The request must contain a valid cookie or access token and a `name` field in the body. The body may also contain a
+// `description` field. If the request succeeds, the response will have a 200 status, and the body will have a
+// `project` field containing the new project. If the request fails, the response will have either a 400 or a
+// 500 status, and the body will have an `error` field detailing what went wrong.
+func HandleCreateProject(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	cookie := auth.ExtractCredential(request.Headers)
+	var createRequest createProjectRequest
+	json.Unmarshal([]byte(request.Body), &createRequest)
+
+	// Perform the action
+	project, err := createProjectFunc(cookie, createRequest.Name, createRequest.Description, auth.Verifier(), dao.Dynamo)
+	log.Error(err)
+
+	// Return the response
+	return http.GatewayResponse(&createProjectResponse{Project: project}, "", err), nil
+}
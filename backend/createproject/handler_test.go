@@ -0,0 +1,91 @@
+package createproject
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type createProjectMockFunc func(string, string, string, auth.VerifyCookieFunc, createProjectDatabase) (*dao.Project, error)
+
+func createProjectMock(wantCookie string, wantName string, wantDescription string, project *dao.Project, err error) createProjectMockFunc {
+	return func(cookie string, name string, description string, _ auth.VerifyCookieFunc, _ createProjectDatabase) (*dao.Project, error) {
+		if cookie != wantCookie || name != wantName || description != wantDescription {
+			return nil, errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return project, err
+	}
+}
+
+func handlerRequest(cookie string, name string, description string) events.APIGatewayProxyRequest {
+	headers := map[string]string{
+		"Cookie": cookie,
+	}
+	json, _ := json.Marshal(&createProjectRequest{Name: name, Description: description})
+	return events.APIGatewayProxyRequest{Headers: headers, Body: string(json)}
+}
+
+func handlerResponse(project *dao.Project, err string, status int) events.APIGatewayProxyResponse {
+	json, _ := json.Marshal(&createProjectResponse{Project: project, Error: err})
+	return events.APIGatewayProxyResponse{
+		Body:       string(json),
+		Headers:    map[string]string{"Access-Control-Allow-Origin": os.Getenv("CORS_ORIGIN"), "Access-Control-Allow-Credentials": "true"},
+		StatusCode: status,
+	}
+}
+
+var handleCreateProjectTests = []struct {
+	name string
+
+	// Input
+	request events.APIGatewayProxyRequest
+
+	// Mock data
+	createProjectMock createProjectMockFunc
+
+	// Expected output
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:              "CreateProjectFailure",
+		request:           handlerRequest("session=cookievalue", "Project", "desc"),
+		createProjectMock: createProjectMock("cookievalue", "Project", "desc", nil, errors.NewServer("Failed database call")),
+		wantResponse:      handlerResponse(nil, "Failed database call", 500),
+	},
+	{
+		name:              "SuccessfulInvocation",
+		request:           handlerRequest("session=cookievalue", "Project", "desc"),
+		createProjectMock: createProjectMock("cookievalue", "Project", "desc", &dao.Project{ID: "projectID", Name: "Project", Description: "desc"}, nil),
+		wantResponse:      handlerResponse(&dao.Project{ID: "projectID", Name: "Project", Description: "desc"}, "", 200),
+	},
+}
+
+func TestHandleCreateProject(t *testing.T) {
+	for _, test := range handleCreateProjectTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			createProjectFunc = test.createProjectMock
+			defer func() {
+				createProjectFunc = createProject
+			}()
+
+			// Execute
+			response, err := HandleCreateProject(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package createtoken
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// maxNameLength is the maximum number of characters in the name of an access token.
+const maxNameLength = 100
+
+// defaultExpiryDays is the lifetime in days of an access token if the request does not specify one.
+const defaultExpiryDays = 30
+
+// maxExpiryDays is the maximum lifetime in days of an access token.
+const maxExpiryDays = 365
+
+// createTokenDatabase wraps the database methods required to perform the createToken action.
+// This allows for dependency injection of the database.
+type createTokenDatabase interface {
+	auth.CredentialStore
+	CreateAccessToken(*dao.AccessToken) error
+}
+
+// generateTokenFunc wraps the function type used to generate a new access token.
+type generateTokenFunc func() (string, error)
+
+// newTokenFunc wraps the function type used to create the access token record of a new access token.
+type newTokenFunc func(string, string, string, []auth.Scope, time.Duration) *dao.AccessToken
+
+// validateScopes checks that every scope is valid and appears once, and returns the scopes as auth scopes.
+func validateScopes(scopes []string) ([]auth.Scope, error) {
+	var valid []auth.Scope
+	seen := make(map[string]bool)
+	for _, scope := range scopes {
+		if !auth.ValidScope(scope) {
+			names := make([]string, len(auth.Scopes))
+			for i, name := range auth.Scopes {
+				names[i] = string(name)
+			}
+			return nil, errors.NewClient(fmt.Sprintf("Invalid scope `%s`. Scopes must be one of: %s", scope, strings.Join(names, ", ")))
+		}
+		if seen[scope] {
+			return nil, errors.NewClient(fmt.Sprintf("Duplicate scope `%s`", scope))
+		}
+		seen[scope] = true
+		valid = append(valid, auth.Scope(scope))
+	}
+	return valid, nil
+}
+
+// createToken creates a personal access token with the given name and scopes for the user associated with the
+// given cookie. The token expires after expiresInDays days, or after defaultExpiryDays days if expiresInDays is 0.
+// An empty list of scopes creates an unrestricted token. createToken returns the token along with its record; the
+// token itself is not stored, so it cannot be returned again. If an error occurs, createToken returns the empty
+// string and a nil record along with the error.
+func createToken(cookie string, name string, scopes []string, expiresInDays int, verifyCookie auth.VerifyCookieFunc, generateToken generateTokenFunc, newToken newTokenFunc, db createTokenDatabase) (string, *dao.AccessToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, errors.NewClient("Parameter `name` is required")
+	}
+	if len(name) > maxNameLength {
+		return "", nil, errors.NewClient(fmt.Sprintf("Parameter `name` must be at most %d characters", maxNameLength))
+	}
+	if expiresInDays == 0 {
+		expiresInDays = defaultExpiryDays
+	}
+	if expiresInDays < 0 || expiresInDays > maxExpiryDays {
+		return "", nil, errors.NewClient(fmt.Sprintf("Parameter `expiresInDays` must be between 1 and %d", maxExpiryDays))
+	}
+	validScopes, err := validateScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return "", nil, errors.NewClient("Not authenticated")
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Failed to generate access token")
+	}
+
+	record := newToken(email, token, name, validScopes, time.Duration(expiresInDays)*24*time.Hour)
+	err = db.CreateAccessToken(record)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Failed to create access token")
+	}
+	return token, record, nil
+}
@@ -0,0 +1,186 @@
+package createtoken
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+func generateTokenMock(mockToken string, mockErr error) generateTokenFunc {
+	return func() (string, error) {
+		return mockToken, mockErr
+	}
+}
+
+// testRecord returns the record that newTokenMock creates for the given token, name, scopes and lifetime.
+func testRecord(token string, name string, scopes []string, lifetime time.Duration) *dao.AccessToken {
+	return &dao.AccessToken{ID: "id-" + token, Email: "test@example.com", Name: name, Scopes: scopes, Expires: time.Time{}.Add(lifetime)}
+}
+
+func newTokenMock(email string, token string, name string, scopes []auth.Scope, lifetime time.Duration) *dao.AccessToken {
+	var names []string
+	for _, scope := range scopes {
+		names = append(names, string(scope))
+	}
+	return &dao.AccessToken{ID: "id-" + token, Email: email, Name: name, Scopes: names, Expires: time.Time{}.Add(lifetime)}
+}
+
+type databaseMock struct {
+	record *dao.AccessToken
+	err    error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) CreateAccessToken(record *dao.AccessToken) error {
+	if !reflect.DeepEqual(record, mock.record) {
+		return errors.NewServer("Incorrect input to CreateAccessToken mock")
+	}
+	return mock.err
+}
+
+const day = 24 * time.Hour
+
+var createTokenTests = []struct {
+	name string
+
+	// Input
+	tokenName     string
+	scopes        []string
+	expiresInDays int
+
+	// Mock data
+	db        *databaseMock
+	email     string
+	verifyErr error
+	tokenErr  error
+
+	// Expected output
+	wantToken  string
+	wantRecord *dao.AccessToken
+	wantErr    error
+}{
+	{
+		name:      "MissingName",
+		tokenName: "  ",
+		wantErr:   errors.NewClient("Parameter `name` is required"),
+	},
+	{
+		name:      "LongName",
+		tokenName: string(make([]byte, 101)),
+		wantErr:   errors.NewClient("Parameter `name` must be at most 100 characters"),
+	},
+	{
+		name:          "NegativeExpiry",
+		tokenName:     "CI",
+		expiresInDays: -1,
+		wantErr:       errors.NewClient("Parameter `expiresInDays` must be between 1 and 365"),
+	},
+	{
+		name:          "LongExpiry",
+		tokenName:     "CI",
+		expiresInDays: 366,
+		wantErr:       errors.NewClient("Parameter `expiresInDays` must be between 1 and 365"),
+	},
+	{
+		name:      "InvalidScope",
+		tokenName: "CI",
+		scopes:    []string{"codegen", "admin"},
+		wantErr:   errors.NewClient("Invalid scope `admin`. Scopes must be one of: read-only, codegen, deploy"),
+	},
+	{
+		name:      "DuplicateScope",
+		tokenName: "CI",
+		scopes:    []string{"deploy", "deploy"},
+		wantErr:   errors.NewClient("Duplicate scope `deploy`"),
+	},
+	{
+		name:      "InvalidCookie",
+		tokenName: "CI",
+		verifyErr: errors.NewClient("Invalid cookie"),
+		wantErr:   errors.NewClient("Not authenticated"),
+	},
+	{
+		name:      "GenerateTokenFailure",
+		tokenName: "CI",
+		email:     "test@example.com",
+		tokenErr:  errors.NewServer("Random failure"),
+		wantErr:   errors.Wrap(errors.NewServer("Random failure"), "Failed to generate access token"),
+	},
+	{
+		name:      "DatabaseFailure",
+		tokenName: "CI",
+		db:        &databaseMock{record: testRecord("apic_token", "CI", nil, 30*day), err: errors.NewServer("DynamoDB failure")},
+		email:     "test@example.com",
+		wantErr:   errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to create access token"),
+	},
+	{
+		name:       "DefaultExpiry",
+		tokenName:  " CI ",
+		db:         &databaseMock{record: testRecord("apic_token", "CI", nil, 30*day)},
+		email:      "test@example.com",
+		wantToken:  "apic_token",
+		wantRecord: testRecord("apic_token", "CI", nil, 30*day),
+	},
+	{
+		name:          "ScopedToken",
+		tokenName:     "Deploy bot",
+		scopes:        []string{"codegen", "deploy"},
+		expiresInDays: 365,
+		db:            &databaseMock{record: testRecord("apic_token", "Deploy bot", []string{"codegen", "deploy"}, 365*day)},
+		email:         "test@example.com",
+		wantToken:     "apic_token",
+		wantRecord:    testRecord("apic_token", "Deploy bot", []string{"codegen", "deploy"}, 365*day),
+	},
+}
+
+func TestCreateToken(t *testing.T) {
+	for _, test := range createTokenTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock("cookie", test.db, test.email, test.verifyErr)
+			generateToken := generateTokenMock("apic_token", test.tokenErr)
+
+			// Execute
+			token, record, err := createToken("cookie", test.tokenName, test.scopes, test.expiresInDays, verifyCookie, generateToken, newTokenMock, test.db)
+
+			// Verify
+			if token != test.wantToken {
+				t.Errorf("Got token %s; want %s", token, test.wantToken)
+			}
+			if !reflect.DeepEqual(record, test.wantRecord) {
+				t.Errorf("Got record %+v; want %+v", record, test.wantRecord)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
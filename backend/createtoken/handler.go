@@ -0,0 +1,58 @@
+// Package createtoken handles requests to the POST /tokens REST API endpoint.
+package createtoken
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// createTokenRequest contains the fields passed in the API JSON request body.
+type createTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expiresInDays"`
+}
+
+// createTokenResponse contains the fields returned in the API JSON response body.
+type createTokenResponse struct {
+	Token       string           `json:"token,omitempty"`
+	AccessToken *dao.AccessToken `json:"accessToken,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+func (response *createTokenResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// createTokenFunc points to the function used to perform the createToken action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var createTokenFunc = createToken
+
+// HandleCreateToken parses the request object from AWS APIGateway and passes it to the createToken action. The
+// request must contain a valid `Cookie` header, since access tokens cannot create other access tokens, and a
+// `name` field in the body. The body may also contain a `scopes` list and an `expiresInDays` field. If the request
+// succeeds, the response will have a 200 status, and the body will have a `token` field containing the new access
+// token and an `accessToken` field describing it. The token is only ever returned by this request. If the request
+// fails, the response will have either a 400 or a 500 status, and the body will have an `error` field detailing
+// what went wrong.
+func HandleCreateToken(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	cookie := auth.ExtractCookie(request.Headers["Cookie"])
+	var createRequest createTokenRequest
+	json.Unmarshal([]byte(request.Body), &createRequest)
+
+	// Perform the action
+	token, record, err := createTokenFunc(cookie, createRequest.Name, createRequest.Scopes, createRequest.ExpiresInDays,
+		auth.VerifySession, auth.GenerateAccessToken, auth.NewAccessToken, dao.Dynamo)
+	log.Error(err)
+
+	// Return the response
+	return http.GatewayResponse(&createTokenResponse{Token: token, AccessToken: record}, "", err), nil
+}
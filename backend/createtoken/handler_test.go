@@ -0,0 +1,96 @@
+package createtoken
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type createTokenMockFunc func(string, string, []string, int, auth.VerifyCookieFunc, generateTokenFunc, newTokenFunc, createTokenDatabase) (string, *dao.AccessToken, error)
+
+func createTokenMock(wantCookie string, wantName string, wantScopes []string, wantDays int, token string, record *dao.AccessToken, err error) createTokenMockFunc {
+	return func(cookie string, name string, scopes []string, days int, _ auth.VerifyCookieFunc, _ generateTokenFunc, _ newTokenFunc, _ createTokenDatabase) (string, *dao.AccessToken, error) {
+		if cookie != wantCookie || name != wantName || !reflect.DeepEqual(scopes, wantScopes) || days != wantDays {
+			return "", nil, errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return token, record, err
+	}
+}
+
+func handlerRequest(cookie string, body string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		Body:    body,
+		Headers: map[string]string{"Cookie": cookie},
+	}
+}
+
+func handlerResponse(token string, record *dao.AccessToken, err string, status int) events.APIGatewayProxyResponse {
+	json, _ := json.Marshal(&createTokenResponse{Token: token, AccessToken: record, Error: err})
+	return events.APIGatewayProxyResponse{
+		Body:       string(json),
+		Headers:    map[string]string{"Access-Control-Allow-Origin": os.Getenv("CORS_ORIGIN"), "Access-Control-Allow-Credentials": "true"},
+		StatusCode: status,
+	}
+}
+
+var handleCreateTokenTests = []struct {
+	name string
+
+	// Input
+	request events.APIGatewayProxyRequest
+
+	// Mock data
+	createTokenMock createTokenMockFunc
+
+	// Expected output
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:            "ClientError",
+		request:         handlerRequest("session=cookievalue", `{"name": "CI", "scopes": ["admin"]}`),
+		createTokenMock: createTokenMock("cookievalue", "CI", []string{"admin"}, 0, "", nil, errors.NewClient("Invalid scope `admin`")),
+		wantResponse:    handlerResponse("", nil, "Invalid scope `admin`", 400),
+	},
+	{
+		name:            "CreateTokenFailure",
+		request:         handlerRequest("session=cookievalue", `{"name": "CI"}`),
+		createTokenMock: createTokenMock("cookievalue", "CI", nil, 0, "", nil, errors.NewServer("Failed database call")),
+		wantResponse:    handlerResponse("", nil, "Failed database call", 500),
+	},
+	{
+		name:            "SuccessfulInvocation",
+		request:         handlerRequest("session=cookievalue", `{"name": "CI", "scopes": ["deploy"], "expiresInDays": 7}`),
+		createTokenMock: createTokenMock("cookievalue", "CI", []string{"deploy"}, 7, "apic_token", testRecord("apic_token", "CI", []string{"deploy"}, 7*day), nil),
+		wantResponse:    handlerResponse("apic_token", testRecord("apic_token", "CI", []string{"deploy"}, 7*day), "", 200),
+	},
+}
+
+func TestHandleCreateToken(t *testing.T) {
+	for _, test := range handleCreateTokenTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			createTokenFunc = test.createTokenMock
+			defer func() {
+				createTokenFunc = createToken
+			}()
+
+			// Execute
+			response, err := HandleCreateToken(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
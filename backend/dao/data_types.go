@@ -2,22 +2,57 @@
 // to manipulate those objects in the database.
 package dao
 
+import "time"
+
 // User represents an instance of the User model in the database.
 type User struct {
-	Email    string              `dynamodbav:"Email" json:"email"`
-	Password string              `dynamodbav:"Password" json:"-"`
-	Token    string              `dynamodbav:"SessionToken" json:"-"`
-	Projects map[string]*Project `dynamodbav:"Projects" json:"projects,omitempty"`
+	Email         string              `dynamodbav:"Email" json:"email"`
+	EmailVerified bool                `dynamodbav:"EmailVerified" json:"emailVerified"`
+	Password      string              `dynamodbav:"Password" json:"-"`
+	Projects      map[string]*Project `dynamodbav:"Projects" json:"projects,omitempty"`
 }
 
 // Project represents an instance of the Project model in the database.
 type Project struct {
-	ID          string             `dynamodbav:"Id" json:"id"`
-	Name        string             `dynamodbav:"Name" json:"name"`
-	Description string             `dynamodbav:"Description" json:"description"`
-	InstanceID  string             `dynamodbav:"InstanceId" json:"-"`
-	DeployURL   string             `dynamodbav:"DeployUrl" json:"url"`
-	Objects     map[string]*Object `dynamodbav:"Objects" json:"objects"`
+	ID           string               `dynamodbav:"Id" json:"id"`
+	Name         string               `dynamodbav:"Name" json:"name"`
+	Description  string               `dynamodbav:"Description" json:"description"`
+	Version      int                  `dynamodbav:"Version,omitempty" json:"version"`
+	Objects      map[string]*Object   `dynamodbav:"Objects" json:"objects"`
+	Endpoints    map[string]*Endpoint `dynamodbav:"Endpoints" json:"endpoints,omitempty"`
+	DeployConfig *DeployConfig        `dynamodbav:"DeployConfig,omitempty" json:"deployConfig,omitempty"`
+	Deployment
+}
+
+// The statuses of a deployment. A deployment is pending while its instance is being launched, provisioning
+// until its instance is running, booting until the generated server responds and then healthy. A deployment
+// whose instance fails to launch, stops or does not boot in time is failed.
+const (
+	DeployPending      = "pending"
+	DeployProvisioning = "provisioning"
+	DeployBooting      = "booting"
+	DeployHealthy      = "healthy"
+	DeployFailed       = "failed"
+)
+
+// Deployment represents the state of the EC2 instance that runs a project. It is embedded in Project, so its
+// fields are stored alongside the other fields of the project.
+type Deployment struct {
+	Provider   string `dynamodbav:"DeployProvider,omitempty" json:"-"`
+	InstanceID string `dynamodbav:"InstanceId" json:"-"`
+	URL        string `dynamodbav:"DeployUrl" json:"url"`
+	Status     string `dynamodbav:"DeployStatus,omitempty" json:"deployStatus,omitempty"`
+	Error      string `dynamodbav:"DeployError,omitempty" json:"deployError,omitempty"`
+}
+
+// DeployConfig represents the options used to deploy a project. It is kept when the project is undeployed, so
+// that later deployments reuse it.
+type DeployConfig struct {
+	Region       string `dynamodbav:"Region" json:"region,omitempty"`
+	InstanceType string `dynamodbav:"InstanceType" json:"instanceType,omitempty"`
+	KeyPair      string `dynamodbav:"KeyPair,omitempty" json:"keyPair,omitempty"`
+	IngressCIDR  string `dynamodbav:"IngressCidr" json:"ingressCidr,omitempty"`
+	Port         int    `dynamodbav:"Port" json:"port,omitempty"`
 }
 
 // Object represents an instance of the Object model in the database.
@@ -31,9 +66,92 @@ type Object struct {
 
 // Attribute represents an instance of the Attribute model in the database.
 type Attribute struct {
-	Name        string `dynamodbav:"Name" json:"name"`
-	CodeName    string `dynamodbav:"CodeName" json:"-"`
-	Type        string `dynamodbav:"Type" json:"type"`
-	Required    bool   `dynamodbav:"Required" json:"required"`
-	Description string `dynamodbav:"Description" json:"description"`
+	Name        string   `dynamodbav:"Name" json:"name"`
+	CodeName    string   `dynamodbav:"CodeName" json:"-"`
+	Type        string   `dynamodbav:"Type" json:"type"`
+	Required    bool     `dynamodbav:"Required" json:"required"`
+	Description string   `dynamodbav:"Description" json:"description"`
+	Values      []string `dynamodbav:"Values,omitempty" json:"values,omitempty"`
+	Target      string   `dynamodbav:"Target,omitempty" json:"target,omitempty"`
+	Via         string   `dynamodbav:"Via,omitempty" json:"via,omitempty"`
+	Through     string   `dynamodbav:"Through,omitempty" json:"through,omitempty"`
+	Min         *float64 `dynamodbav:"Min,omitempty" json:"min,omitempty"`
+	Max         *float64 `dynamodbav:"Max,omitempty" json:"max,omitempty"`
+	MinLength   *int     `dynamodbav:"MinLength,omitempty" json:"minLength,omitempty"`
+	MaxLength   *int     `dynamodbav:"MaxLength,omitempty" json:"maxLength,omitempty"`
+	Pattern     string   `dynamodbav:"Pattern,omitempty" json:"pattern,omitempty"`
+	Unique      bool     `dynamodbav:"Unique,omitempty" json:"unique,omitempty"`
+	Default     string   `dynamodbav:"Default,omitempty" json:"default,omitempty"`
+}
+
+// Endpoint represents an instance of the Endpoint model in the database. An endpoint maps a route of the
+// generated API to an operation on the records of an object.
+type Endpoint struct {
+	ID           string    `dynamodbav:"Id" json:"id"`
+	Name         string    `dynamodbav:"Name" json:"name"`
+	CodeName     string    `dynamodbav:"CodeName" json:"-"`
+	Description  string    `dynamodbav:"Description" json:"description"`
+	Method       string    `dynamodbav:"Method" json:"method"`
+	Path         string    `dynamodbav:"Path" json:"path"`
+	Object       string    `dynamodbav:"Object" json:"object"`
+	Operation    string    `dynamodbav:"Operation" json:"operation"`
+	Filters      []*Filter `dynamodbav:"Filters,omitempty" json:"filters,omitempty"`
+	AuthRequired bool      `dynamodbav:"AuthRequired" json:"authRequired"`
+}
+
+// Filter represents a query parameter of a list endpoint that restricts the returned records to those whose
+// attribute compares to the parameter's value using the operator.
+type Filter struct {
+	Attribute string `dynamodbav:"Attribute" json:"attribute"`
+	Operator  string `dynamodbav:"Operator" json:"operator"`
+	Parameter string `dynamodbav:"Parameter" json:"parameter"`
+}
+
+// ProjectVersion represents an immutable snapshot of a project. A new version is recorded every time the
+// objects of the project change. Versions of the same project are numbered in increasing order.
+type ProjectVersion struct {
+	ProjectKey string    `dynamodbav:"ProjectKey" json:"-"`
+	Version    int       `dynamodbav:"Version" json:"version"`
+	Author     string    `dynamodbav:"Author" json:"author"`
+	Timestamp  time.Time `dynamodbav:"Timestamp" json:"timestamp"`
+	Project    *Project  `dynamodbav:"Project,omitempty" json:"project,omitempty"`
+}
+
+// Session represents a login of a user on a single device. Sessions are stored in their own table, keyed by an ID
+// derived from the token in the session cookie, and are deleted by DynamoDB some time after they expire.
+type Session struct {
+	ID        string    `dynamodbav:"SessionId" json:"id"`
+	Email     string    `dynamodbav:"Email" json:"-"`
+	Created   time.Time `dynamodbav:"Created" json:"created"`
+	LastSeen  time.Time `dynamodbav:"LastSeen" json:"lastSeen"`
+	Expires   time.Time `dynamodbav:"Expires,unixtime" json:"expires"`
+	UserAgent string    `dynamodbav:"UserAgent" json:"userAgent"`
+	IP        string    `dynamodbav:"IP" json:"ip"`
+
+	// Current is true if the session is the one making the request. It is not stored in the database.
+	Current bool `dynamodbav:"-" json:"current"`
+}
+
+// AccessToken represents a personal access token, which authenticates scripts and API clients as a user. Like
+// sessions, access tokens are stored in their own table, keyed by an ID derived from the token itself, and are
+// deleted by DynamoDB some time after they expire. A token without scopes grants the same access as a session.
+type AccessToken struct {
+	ID       string     `dynamodbav:"TokenId" json:"id"`
+	Email    string     `dynamodbav:"Email" json:"-"`
+	Name     string     `dynamodbav:"Name" json:"name"`
+	Scopes   []string   `dynamodbav:"Scopes,stringset,omitempty" json:"scopes,omitempty"`
+	Created  time.Time  `dynamodbav:"Created" json:"created"`
+	LastUsed *time.Time `dynamodbav:"LastUsed,omitempty" json:"lastUsed,omitempty"`
+	Expires  time.Time  `dynamodbav:"Expires,unixtime" json:"expires"`
+}
+
+// Identity links an account of an external identity provider, such as GitHub or Google, to a user, so that the user
+// can log in with that account. Identities are stored in their own table, keyed by the name of the provider and the
+// subject that the provider uses to identify the account.
+type Identity struct {
+	ID       string    `dynamodbav:"IdentityId" json:"id"`
+	Provider string    `dynamodbav:"Provider" json:"provider"`
+	Subject  string    `dynamodbav:"Subject" json:"subject"`
+	Email    string    `dynamodbav:"Email" json:"-"`
+	Created  time.Time `dynamodbav:"Created" json:"created"`
 }
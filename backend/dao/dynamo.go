@@ -30,12 +30,25 @@ type putter interface {
 	PutItem(*dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error)
 }
 
+// transactWriter wraps the TransactWriteItems method in order to perform dependency
+// injection in the dynamo tests.
+type transactWriter interface {
+	TransactWriteItems(*dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
 // defaultSvc actually queries DynamoDB. The other Svc variables exist only for
 // dependency injection and should only be changed inside a test.
 var defaultSvc = dynamodb.New(session.New())
 var getSvc getter = defaultSvc
 var putSvc putter = defaultSvc
 var updateSvc updater = defaultSvc
+var transactSvc transactWriter = defaultSvc
+
+// encoder marshals the items of update expressions. Empty collections are preserved so that, for example, a
+// project without objects is stored with an empty Objects map that later updates can add to.
+var encoder = dynamodbattribute.NewEncoder(func(e *dynamodbattribute.Encoder) {
+	e.EnableEmptyCollections = true
+})
 
 // dynamo is an empty struct that acts as a collection of database methods.
 type dynamo struct{}
@@ -43,62 +56,132 @@ type dynamo struct{}
 // Dynamo provides a high-level interface to perform database queries against AWS DynamoDB.
 var Dynamo = dynamo{}
 
-// CreateUser adds a User object to the database with the given email, password and session token.
-// If the email already exists in the database, CreateUser makes no changes to the databse and returns
-// a client error.
-func (dynamo) CreateUser(email string, password string, token string) error {
-	input := &dynamodb.PutItemInput{
-		ConditionExpression: aws.String("attribute_not_exists(Email)"),
-		Item: map[string]*dynamodb.AttributeValue{
-			"Email": {
-				S: aws.String(email),
-			},
-			"Password": {
-				S: aws.String(password),
-			},
-			"SessionToken": {
-				S: aws.String(token),
-			},
-			// TODO: Remove this and dynamically create projects
-			"Projects": {
-				M: map[string]*dynamodb.AttributeValue{
-					"defaultProject": {
-						M: map[string]*dynamodb.AttributeValue{
-							"Id":          {S: aws.String("defaultProject")},
-							"Name":        {S: aws.String("Default Project")},
-							"Description": {S: aws.String(defaultProjectDesc)},
-							"Objects":     {M: map[string]*dynamodb.AttributeValue{}},
-						},
-					},
-				},
-			},
+// CreateUser adds a User object to the database with the given email and password. The new user
+// has the given project as its only project, so that a user never exists without one. If the email
+// already exists in the database, CreateUser makes no changes to the databse and returns a client error.
+func (dynamo) CreateUser(email string, password string, project *Project) error {
+	return Dynamo.createUser(map[string]*dynamodb.AttributeValue{
+		"Email": {
+			S: aws.String(email),
+		},
+		"Password": {
+			S: aws.String(password),
+		},
+	}, project)
+}
+
+// createUser puts the given user item in the database with the given project as its only project, and records
+// the first version of the project. The user and the version are written in a single transaction, so either both
+// are created or neither is. If an item with the same email already exists, createUser makes no changes to the
+// database and returns a client error.
+func (dynamo) createUser(item map[string]*dynamodb.AttributeValue, project *Project) error {
+	project.Version = 1
+	initCollections(project)
+	projectAV, err := encoder.Encode(project)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal project")
+	}
+	item["Projects"] = &dynamodb.AttributeValue{
+		M: map[string]*dynamodb.AttributeValue{project.ID: projectAV},
+	}
+	email := aws.StringValue(item["Email"].S)
+	version, err := versionWrite(email, project)
+	if err != nil {
+		return err
+	}
+
+	put := &dynamodb.TransactWriteItem{
+		Put: &dynamodb.Put{
+			ConditionExpression: aws.String("attribute_not_exists(Email)"),
+			Item:                item,
+			TableName:           aws.String(os.Getenv("TABLE_NAME")),
 		},
-		TableName: aws.String(os.Getenv("TABLE_NAME")),
 	}
+	err = transactWrite(put, version)
+	if cancellationCode(err, 0) == conditionalCheckFailed {
+		return errors.NewClient("Email already in use")
+	}
+	return err
+}
 
-	_, err := putSvc.PutItem(input)
+// CreateProject adds the given project to the projects of the user with the given email and records its first
+// version, so that every version of the project can be restored. If the user already has a project with the same
+// ID, CreateProject makes no changes to the database and returns a client error.
+func (dynamo) CreateProject(email string, project *Project) error {
+	project.Version = 1
+	initCollections(project)
+	condition := "attribute_not_exists(Projects.#pid)"
+	expression := "SET Projects.#pid = :proj"
+	attributeNames := map[string]*string{
+		"#pid": aws.String(project.ID),
+	}
+	items := map[string]interface{}{
+		":proj": project,
+	}
+
+	update, err := updateUserWrite(email, condition, expression, attributeNames, items)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			if aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
-				return errors.NewClient("Email already in use")
-			}
-		}
-		return errors.Wrap(err, "Failed DynamoDB PutItem call")
+		return err
+	}
+	version, err := versionWrite(email, project)
+	if err != nil {
+		return err
+	}
+
+	err = transactWrite(update, version)
+	if cancellationCode(err, 0) == conditionalCheckFailed {
+		return errors.NewClient(fmt.Sprintf("Project '%s' already exists", project.ID))
 	}
-	return nil
+	if isTransactionConflict(err) {
+		return projectChanged(project.ID)
+	}
+	return err
+}
+
+// DeleteEndpoint removes the endpoint with the given ID from the given project and records a new version of the
+// project. If the endpoint does not exist, no changes are made to the database and no error is returned.
+func (dynamo) DeleteEndpoint(email string, projectID string, endpointID string) error {
+	_, err := Dynamo.mutateProject(email, projectID, anyVersion, func(project *Project) bool {
+		if _, ok := project.Endpoints[endpointID]; !ok {
+			return false
+		}
+		delete(project.Endpoints, endpointID)
+		return true
+	})
+	return err
 }
 
-func (dynamo) DeleteObject(email string, projectID string, objectID string) error {
-	expression := "REMOVE Projects.#pid.Objects.#oid"
+// DeleteObject removes the object with the given ID from the given project and records a new version of the
+// project. If the object does not exist, no changes are made to the database and no error is returned. If the
+// project is no longer at the given version, DeleteObject makes no changes to the database and returns a client
+// error asking the user to try again.
+func (dynamo) DeleteObject(email string, projectID string, objectID string, version int) error {
+	_, err := Dynamo.mutateProject(email, projectID, version, func(project *Project) bool {
+		if _, ok := project.Objects[objectID]; !ok {
+			return false
+		}
+		delete(project.Objects, objectID)
+		return true
+	})
+	return err
+}
+
+// DeleteProject removes the project with the given ID and all of its versions from the user with the given
+// email. If the project does not exist, no error is returned.
+func (dynamo) DeleteProject(email string, projectID string) error {
+	expression := "REMOVE Projects.#pid"
 	attributeNames := map[string]*string{
 		"#pid": aws.String(projectID),
-		"#oid": aws.String(objectID),
 	}
-	return Dynamo.updateUser(email, expression, attributeNames, nil)
+	err := Dynamo.updateUser(email, expression, attributeNames, nil)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(Dynamo.deleteVersions(email, projectID), "Failed to delete project versions")
 }
 
 func (dynamo) getUser(email string, expression string, attributeNames map[string]*string) (*User, error) {
-	input := &dynamodb.GetItemInput{
+	return Dynamo.readUser(&dynamodb.GetItemInput{
 		ExpressionAttributeNames: attributeNames,
 		Key: map[string]*dynamodb.AttributeValue{
 			"Email": {
@@ -107,14 +190,18 @@ func (dynamo) getUser(email string, expression string, attributeNames map[string
 		},
 		ProjectionExpression: aws.String(expression),
 		TableName:            aws.String(os.Getenv("TABLE_NAME")),
-	}
+	})
+}
 
+// readUser returns the user read by the given GetItem input. If the user does not exist, the returned user
+// will be nil and the returned error will be a new client error.
+func (dynamo) readUser(input *dynamodb.GetItemInput) (*User, error) {
 	result, err := getSvc.GetItem(input)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed DynamoDB GetItem call")
 	}
 	if result.Item == nil {
-		return nil, errors.NewClient(fmt.Sprintf("Email '%s' not found", email))
+		return nil, errors.NewClient(fmt.Sprintf("Email '%s' not found", aws.StringValue(input.Key["Email"].S)))
 	}
 
 	user := User{}
@@ -128,7 +215,7 @@ func (dynamo) getUser(email string, expression string, attributeNames map[string
 // GetUser returns the entire user object associated with the given email. All projects are included in
 // their entirety. If an error occurs, the returned user will be nil.
 func (dynamo) GetUser(email string) (*User, error) {
-	expression := "Email, Projects"
+	expression := "Email, EmailVerified, Projects"
 	return Dynamo.getUser(email, expression, nil)
 }
 
@@ -136,7 +223,7 @@ func (dynamo) GetUser(email string) (*User, error) {
 // If the email does not exist, the returned user will be nil and the returned error will be a new client
 // error.
 func (dynamo) GetUserInfo(email string) (*User, error) {
-	expression := "Email, Password, SessionToken"
+	expression := "Email, EmailVerified, Password"
 	return Dynamo.getUser(email, expression, nil)
 }
 
@@ -161,26 +248,106 @@ func (dynamo) GetProject(email string, projectID string) (*Project, error) {
 	return project, nil
 }
 
+// isConditionalCheckFailure returns true if err was caused by a failed DynamoDB condition expression.
+func isConditionalCheckFailure(err error) bool {
+	if aerr, ok := errors.Cause(err).(awserr.Error); ok {
+		return aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+	}
+	return false
+}
+
+// The reasons that DynamoDB gives for canceling a write of a transaction.
+const (
+	conditionalCheckFailed = "ConditionalCheckFailed"
+	transactionConflict    = "TransactionConflict"
+)
+
+// transactWrite performs the given writes in a single transaction, so either all of them succeed or none of them
+// do. The reason that a write canceled the transaction can be found with cancellationCode.
+func transactWrite(writes ...*dynamodb.TransactWriteItem) error {
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: writes,
+	}
+	_, err := transactSvc.TransactWriteItems(input)
+	return errors.Wrap(err, "Failed DynamoDB TransactWriteItems call")
+}
+
+// cancellationCode returns the reason that the write with the given index canceled the transaction that caused
+// err. If err was not caused by a canceled transaction, cancellationCode returns the empty string.
+func cancellationCode(err error, index int) string {
+	if cerr, ok := errors.Cause(err).(*dynamodb.TransactionCanceledException); ok && index < len(cerr.CancellationReasons) {
+		return aws.StringValue(cerr.CancellationReasons[index].Code)
+	}
+	return ""
+}
+
+// isTransactionConflict returns true if err was caused by a transaction that was canceled because the condition
+// of one of its writes failed or because another request was changing the same items.
+func isTransactionConflict(err error) bool {
+	if cerr, ok := errors.Cause(err).(*dynamodb.TransactionCanceledException); ok {
+		for _, reason := range cerr.CancellationReasons {
+			code := aws.StringValue(reason.Code)
+			if code == conditionalCheckFailed || code == transactionConflict {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// updateUserWrite returns the write of a transaction that performs the same update as updateUserIf.
+func updateUserWrite(email string, condition string, expression string, attributeNames map[string]*string, items map[string]interface{}) (*dynamodb.TransactWriteItem, error) {
+	values, err := encodeValues(items)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditionExpression *string
+	if condition != "" {
+		conditionExpression = aws.String(condition)
+	}
+
+	return &dynamodb.TransactWriteItem{
+		Update: &dynamodb.Update{
+			ConditionExpression:       conditionExpression,
+			ExpressionAttributeNames:  attributeNames,
+			ExpressionAttributeValues: values,
+			Key: map[string]*dynamodb.AttributeValue{
+				"Email": {
+					S: aws.String(email),
+				},
+			},
+			TableName:        aws.String(os.Getenv("TABLE_NAME")),
+			UpdateExpression: aws.String(expression),
+		},
+	}, nil
+}
+
 // updateUser updates the properties of the user given in expression with the given items. If expression is not a valid
 // property path for the given user, a client error is returned. If something else goes wrong, a server
 // error is returned.
 //
 // TODO: Return client error if path does not exist
 func (dynamo) updateUser(email string, expression string, attributeNames map[string]*string, items map[string]interface{}) error {
+	return Dynamo.updateUserIf(email, "", expression, attributeNames, items)
+}
 
-	var expressionAttributeValues map[string]*dynamodb.AttributeValue
-	if len(items) > 0 {
-		expressionAttributeValues = make(map[string]*dynamodb.AttributeValue)
-		for key, item := range items {
-			itemAV, err := dynamodbattribute.Marshal(item)
-			if err != nil {
-				return errors.Wrap(err, fmt.Sprintf("Failed to marshal item %s", key))
-			}
-			expressionAttributeValues[key] = itemAV
-		}
+// updateUserIf performs the same update as updateUser, but only if the given condition expression is true for the
+// user. If condition is the empty string, the update is unconditional. The error returned when the condition is false
+// can be detected with isConditionalCheckFailure.
+func (dynamo) updateUserIf(email string, condition string, expression string, attributeNames map[string]*string, items map[string]interface{}) error {
+	expressionAttributeValues, err := encodeValues(items)
+	if err != nil {
+		return err
+	}
+
+	var conditionExpression *string
+	if condition != "" {
+		conditionExpression = aws.String(condition)
 	}
 
 	input := &dynamodb.UpdateItemInput{
+		ConditionExpression:       conditionExpression,
 		ExpressionAttributeNames:  attributeNames,
 		ExpressionAttributeValues: expressionAttributeValues,
 		Key: map[string]*dynamodb.AttributeValue{
@@ -192,53 +359,137 @@ func (dynamo) updateUser(email string, expression string, attributeNames map[str
 		UpdateExpression: aws.String(expression),
 	}
 
-	_, err := updateSvc.UpdateItem(input)
+	_, err = updateSvc.UpdateItem(input)
 	return errors.Wrap(err, "Failed DynamoDB UpdateItem call")
 }
 
-func (dynamo) UpdateDeployment(email string, projectID string, instanceID string, instanceURL string) error {
-	expression := "SET Projects.#pid.InstanceId = :id, Projects.#pid.DeployUrl = :url"
-	attributeNames := map[string]*string{
-		"#pid": aws.String(projectID),
+// encodeValues marshals the given items of an expression. If there are no items, encodeValues returns nil.
+func encodeValues(items map[string]interface{}) (map[string]*dynamodb.AttributeValue, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	values := make(map[string]*dynamodb.AttributeValue)
+	for key, item := range items {
+		itemAV, err := encoder.Encode(item)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Failed to marshal item %s", key))
+		}
+		values[key] = itemAV
 	}
+	return values, nil
+}
+
+// UpdateDeployment replaces the deployment of the given project with the given deployment. Unlike changes to the
+// objects of a project, changes to its deployment do not record a new version. If the project does not exist,
+// a client error is returned.
+func (dynamo) UpdateDeployment(email string, projectID string, deployment *Deployment) error {
+	err := Dynamo.updateDeploymentIf(email, projectID, "attribute_exists(Projects.#pid)", map[string]interface{}{}, deployment)
+	if isConditionalCheckFailure(err) {
+		return errors.NewClient(fmt.Sprintf("Project '%s' not found", projectID))
+	}
+	return err
+}
+
+// AdvanceDeployment replaces the deployment of the given project with the given deployment only if the project is
+// still deployed to the instance of the given deployment, so that the status of an instance that has since been
+// replaced or terminated never overwrites the deployment that replaced it. If the project no longer has that
+// instance, or no longer exists, AdvanceDeployment makes no changes to the database and returns false.
+func (dynamo) AdvanceDeployment(email string, projectID string, deployment *Deployment) (bool, error) {
+	condition := "Projects.#pid.InstanceId = :expected"
 	items := map[string]interface{}{
-		":id":  instanceID,
-		":url": instanceURL,
+		":expected": deployment.InstanceID,
+	}
+	err := Dynamo.updateDeploymentIf(email, projectID, condition, items, deployment)
+	if isConditionalCheckFailure(err) {
+		return false, nil
 	}
-	return Dynamo.updateUser(email, expression, attributeNames, items)
+	return err == nil, err
 }
 
-// UpdateObject either creates or replaces the given object within the given project. If an error occurs,
-// it is returned.
-func (dynamo) UpdateObject(email string, projectID string, object *Object, originalID string) error {
-	expression := ""
+// updateDeploymentIf replaces the deployment of the given project with the given deployment if the given condition
+// holds. The given items are added to the attribute values of the update.
+func (dynamo) updateDeploymentIf(email string, projectID string, condition string, items map[string]interface{}, deployment *Deployment) error {
+	expression := "SET Projects.#pid.DeployProvider = :provider, Projects.#pid.InstanceId = :id, Projects.#pid.DeployUrl = :url, " +
+		"Projects.#pid.DeployStatus = :status, Projects.#pid.DeployError = :error"
+	attributeNames := map[string]*string{
+		"#pid": aws.String(projectID),
+	}
+	items[":provider"] = deployment.Provider
+	items[":id"] = deployment.InstanceID
+	items[":url"] = deployment.URL
+	items[":status"] = deployment.Status
+	items[":error"] = deployment.Error
+	return Dynamo.updateUserIf(email, condition, expression, attributeNames, items)
+}
+
+// UpdateDeployConfig sets the deployment options of the given project. The project must already exist.
+func (dynamo) UpdateDeployConfig(email string, projectID string, config *DeployConfig) error {
+	condition := "attribute_exists(Projects.#pid)"
+	expression := "SET Projects.#pid.DeployConfig = :config"
 	attributeNames := map[string]*string{
 		"#pid": aws.String(projectID),
 	}
 	items := map[string]interface{}{
-		":obj": object,
+		":config": config,
 	}
-
-	if originalID == object.ID || originalID == "" {
-		// We are updating an existing object or creating a new object
-		expression = "SET Projects.#pid.Objects.#oid = :obj"
-		attributeNames["#oid"] = aws.String(object.ID)
-	} else {
-		// We are changing the ID of an existing object and need to delete the old ID
-		expression = "REMOVE Projects.#pid.Objects.#oid1 SET Projects.#pid.Objects.#oid2 = :obj"
-		attributeNames["#oid1"] = aws.String(originalID)
-		attributeNames["#oid2"] = aws.String(object.ID)
+	err := Dynamo.updateUserIf(email, condition, expression, attributeNames, items)
+	if isConditionalCheckFailure(err) {
+		return errors.NewClient(fmt.Sprintf("Project '%s' not found", projectID))
 	}
+	return err
+}
+
+// UpdateEndpoint either creates or replaces the given endpoint within the given project and records a new
+// version of the project. The endpoints of the project are read and written together with its version, so an
+// endpoint written concurrently is never overwritten.
+func (dynamo) UpdateEndpoint(email string, projectID string, endpoint *Endpoint) error {
+	_, err := Dynamo.mutateProject(email, projectID, anyVersion, func(project *Project) bool {
+		if project.Endpoints == nil {
+			project.Endpoints = make(map[string]*Endpoint)
+		}
+		project.Endpoints[endpoint.ID] = endpoint
+		return true
+	})
+	return err
+}
 
-	return Dynamo.updateUser(email, expression, attributeNames, items)
+// UpdateObject either creates or replaces the given object within the given project and records a new version
+// of the project. If originalID differs from the ID of the object, the object with originalID is removed. If the
+// project is no longer at the given version, UpdateObject makes no changes to the database and returns a client
+// error asking the user to try again. If an error occurs, it is returned.
+func (dynamo) UpdateObject(email string, projectID string, object *Object, originalID string, version int) error {
+	_, err := Dynamo.mutateProject(email, projectID, version, func(project *Project) bool {
+		if originalID != "" && originalID != object.ID {
+			// We are changing the ID of an existing object and need to delete the old ID
+			delete(project.Objects, originalID)
+		}
+		if project.Objects == nil {
+			project.Objects = make(map[string]*Object)
+		}
+		project.Objects[object.ID] = object
+		return true
+	})
+	return err
 }
 
-// UpdateUserToken sets the auth token on the User object associated with the given email
-// in the database.
-func (dynamo) UpdateUserToken(email string, token string) error {
-	expression := "SET SessionToken = :tok"
+// UpdateProject sets the name and description of the project with the given ID. If the user with the given
+// email does not have a project with that ID, UpdateProject makes no changes to the database and returns a
+// client error.
+func (dynamo) UpdateProject(email string, projectID string, name string, description string) error {
+	condition := "attribute_exists(Projects.#pid)"
+	expression := "SET Projects.#pid.#name = :name, Projects.#pid.Description = :desc"
+	attributeNames := map[string]*string{
+		"#pid":  aws.String(projectID),
+		"#name": aws.String("Name"),
+	}
 	items := map[string]interface{}{
-		":tok": token,
+		":name": name,
+		":desc": description,
+	}
+
+	err := Dynamo.updateUserIf(email, condition, expression, attributeNames, items)
+	if isConditionalCheckFailure(err) {
+		return errors.NewClient(fmt.Sprintf("Project '%s' not found", projectID))
 	}
-	return Dynamo.updateUser(email, expression, nil, items)
+	return err
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -65,62 +66,113 @@ func updateItemMock(mockInput *dynamodb.UpdateItemInput, mockOutput *dynamodb.Up
 	}
 }
 
-// ----------- DeleteObject Tests ---------------
+// -------------- Project Mutation Helpers -----------------
 
-var deleteObjectTests = []struct {
+// mutationTest describes a change to versionedProject("projectID", 3) of test@example.com. If wantProject is not
+// nil, the change must write it as version 4 of the project.
+type mutationTest struct {
 	name string
 
-	// Input
-	email     string
-	projectID string
-	objectID  string
-
 	// Mock data
-	mockInput *dynamodb.UpdateItemInput
-	mockErr   error
+	stored      *Project
+	transactErr error
 
 	// Expected output
-	wantErr error
+	wantProject *Project
+	wantErr     error
+}
+
+// runMutationTest performs the change of the given test with mutate.
+func runMutationTest(t *testing.T, test mutationTest, mutate func() error) {
+	t.Run(test.name, func(t *testing.T) {
+		// Setup
+		stored := test.stored
+		if stored == nil {
+			stored = versionedProject("projectID", 3)
+		}
+		var mockInput *dynamodb.TransactWriteItemsInput
+		if test.wantProject != nil {
+			mockInput = mutateTransaction("test@example.com", stored.Version, test.wantProject)
+		}
+		getSvc = getItemMock(projectReadInput("test@example.com", "projectID"), projectReadOutput("test@example.com", stored), nil)
+		transactSvc = transactWriteItemsMock(mockInput, test.transactErr)
+		now = func() time.Time { return versionTime }
+		defer func() {
+			getSvc = defaultSvc
+			transactSvc = defaultSvc
+			now = time.Now
+		}()
+
+		// Execute
+		err := mutate()
+
+		// Verify
+		if !errors.Equal(err, test.wantErr) {
+			t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+		}
+	})
+}
+
+// changedProject returns version 4 of versionedProject after change is applied to it.
+func changedProject(change func(*Project)) *Project {
+	project := versionedProject("projectID", 4)
+	change(project)
+	return project
+}
+
+// ----------- DeleteObject Tests ---------------
+
+var deleteObjectTests = []struct {
+	mutationTest
+	version int
 }{
 	{
-		name:      "DatabaseFailure",
-		email:     "test@example.com",
-		projectID: "project",
-		objectID:  "object",
-		mockInput: &dynamodb.UpdateItemInput{
-			ExpressionAttributeNames: map[string]*string{
-				"#pid": aws.String("project"),
-				"#oid": aws.String("object"),
-			},
-			Key: map[string]*dynamodb.AttributeValue{
-				"Email": {
-					S: aws.String("test@example.com"),
-				},
-			},
-			TableName:        aws.String(os.Getenv("TABLE_NAME")),
-			UpdateExpression: aws.String("REMOVE Projects.#pid.Objects.#oid"),
+		mutationTest: mutationTest{
+			name:    "StaleVersion",
+			wantErr: errors.NewClient("Project 'projectID' was changed by another request. Please try again"),
 		},
-		mockErr: errors.NewServer("Database failure"),
-		wantErr: errors.Wrap(errors.NewServer("Database failure"), "Failed DynamoDB UpdateItem call"),
+		version: 2,
 	},
+	{
+		mutationTest: mutationTest{
+			name:        "ServiceError",
+			transactErr: errors.NewServer("DynamoDB failure"),
+			wantProject: changedProject(func(project *Project) {
+				delete(project.Objects, "book")
+			}),
+			wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB TransactWriteItems call"),
+		},
+		version: 3,
+	},
+	{
+		mutationTest: mutationTest{
+			name:   "MissingObject",
+			stored: withoutObjects(),
+		},
+		version: 3,
+	},
+	{
+		mutationTest: mutationTest{
+			name: "SuccessfulInvocation",
+			wantProject: changedProject(func(project *Project) {
+				delete(project.Objects, "book")
+			}),
+		},
+		version: 3,
+	},
+}
+
+// withoutObjects returns version 3 of versionedProject without any objects.
+func withoutObjects() *Project {
+	project := versionedProject("projectID", 3)
+	project.Objects = map[string]*Object{}
+	return project
 }
 
 func TestDeleteObject(t *testing.T) {
 	for _, test := range deleteObjectTests {
-		t.Run(test.name, func(t *testing.T) {
-			// Setup
-			updateSvc = updateItemMock(test.mockInput, nil, test.mockErr)
-			defer func() {
-				updateSvc = defaultSvc
-			}()
-
-			// Execute
-			gotErr := Dynamo.DeleteObject(test.email, test.projectID, test.objectID)
-
-			// Verify
-			if !errors.Equal(gotErr, test.wantErr) {
-				t.Errorf("Got error '%s'; want '%s'", gotErr, test.wantErr)
-			}
+		runMutationTest(t, test.mutationTest, func() error {
+			return Dynamo.DeleteObject("test@example.com", "projectID", "book", test.version)
 		})
 	}
 }
@@ -147,14 +199,14 @@ var getUserTests = []struct {
 	{
 		name:       "ServiceError",
 		email:      "email",
-		expression: "Email, Password, SessionToken",
+		expression: "Email, Password",
 		mockInput: &dynamodb.GetItemInput{
 			Key: map[string]*dynamodb.AttributeValue{
 				"Email": {
 					S: aws.String("email"),
 				},
 			},
-			ProjectionExpression: aws.String("Email, Password, SessionToken"),
+			ProjectionExpression: aws.String("Email, Password"),
 			TableName:            aws.String(os.Getenv("TABLE_NAME")),
 		},
 		mockErr: errors.NewServer("DynamoDB failed"),
@@ -185,24 +237,23 @@ var getUserTests = []struct {
 	{
 		name:       "SuccessfulInvocation",
 		email:      "email",
-		expression: "Email, Password, SessionToken",
+		expression: "Email, Password",
 		mockInput: &dynamodb.GetItemInput{
 			Key: map[string]*dynamodb.AttributeValue{
 				"Email": {
 					S: aws.String("email"),
 				},
 			},
-			ProjectionExpression: aws.String("Email, Password, SessionToken"),
+			ProjectionExpression: aws.String("Email, Password"),
 			TableName:            aws.String(os.Getenv("TABLE_NAME")),
 		},
 		mockOutput: &dynamodb.GetItemOutput{
 			Item: map[string]*dynamodb.AttributeValue{
 				"Email":        {S: aws.String("email")},
 				"Password":     {S: aws.String("password")},
-				"SessionToken": {S: aws.String("sessionToken")},
 			},
 		},
-		wantUser: &User{Email: "email", Password: "password", Token: "sessionToken"},
+		wantUser: &User{Email: "email", Password: "password"},
 	},
 }
 
@@ -343,82 +394,516 @@ func TestGetProject(t *testing.T) {
 // ----------- CreateUser Tests --------------
 
 var createUserTests = []struct {
+	name     string
+	email    string
+	password string
+	mockErr  error
+	wantErr  error
+}{
+	{
+		name:     "ServiceError",
+		email:    "email",
+		password: "password",
+		mockErr:  errors.NewServer("DynamoDB failure"),
+		wantErr:  errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB TransactWriteItems call"),
+	},
+	{
+		name:     "EmailAlreadyExists",
+		email:    "email",
+		password: "password",
+		mockErr:  canceledErr(conditionalCheckFailed, "None"),
+		wantErr:  errors.NewClient("Email already in use"),
+	},
+	{
+		name:     "SuccessfulInvocation",
+		email:    "email",
+		password: "password",
+	},
+}
+
+// firstVersion returns the default project at its first version.
+func firstVersion() *Project {
+	project := DefaultProject()
+	project.Version = 1
+	return project
+}
+
+func createUserMockInput(email string, password string) *dynamodb.TransactWriteItemsInput {
+	project, _ := encoder.Encode(firstVersion())
+	return &dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Put: &dynamodb.Put{
+					ConditionExpression: aws.String("attribute_not_exists(Email)"),
+					Item: map[string]*dynamodb.AttributeValue{
+						"Email": {
+							S: aws.String(email),
+						},
+						"Password": {
+							S: aws.String(password),
+						},
+						"Projects": {
+							M: map[string]*dynamodb.AttributeValue{DefaultProjectID: project},
+						},
+					},
+					TableName: aws.String(os.Getenv("TABLE_NAME")),
+				},
+			},
+			versionPut(email, firstVersion()),
+		},
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	for _, test := range createUserTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			transactSvc = transactWriteItemsMock(createUserMockInput(test.email, test.password), test.mockErr)
+			now = func() time.Time { return versionTime }
+			defer func() {
+				transactSvc = defaultSvc
+				now = time.Now
+			}()
+
+			// Execute
+			gotErr := Dynamo.CreateUser(test.email, test.password, DefaultProject())
+
+			// Verify
+			if !errors.Equal(gotErr, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", gotErr, test.wantErr)
+			}
+		})
+	}
+}
+
+// ----------- CreateProject Tests --------------
+
+// newProject returns the project created by the CreateProject tests, at its first version.
+func newProject() *Project {
+	return &Project{ID: "projectID", Name: "Project Name", Description: "Project description", Version: 1, Objects: map[string]*Object{}, Endpoints: map[string]*Endpoint{}}
+}
+
+func createProjectMockInput(email string, projectID string) *dynamodb.TransactWriteItemsInput {
+	return &dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Update: &dynamodb.Update{
+					ConditionExpression: aws.String("attribute_not_exists(Projects.#pid)"),
+					ExpressionAttributeNames: map[string]*string{
+						"#pid": aws.String(projectID),
+					},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+						":proj": {
+							M: map[string]*dynamodb.AttributeValue{
+								"Id":          {S: aws.String(projectID)},
+								"Name":        {S: aws.String("Project Name")},
+								"Description": {S: aws.String("Project description")},
+								"Version":     {N: aws.String("1")},
+								"InstanceId":  {NULL: aws.Bool(true)},
+								"DeployUrl":   {NULL: aws.Bool(true)},
+								"Objects":     {M: map[string]*dynamodb.AttributeValue{}},
+								"Endpoints":   {M: map[string]*dynamodb.AttributeValue{}},
+							},
+						},
+					},
+					Key: map[string]*dynamodb.AttributeValue{
+						"Email": {
+							S: aws.String(email),
+						},
+					},
+					TableName:        aws.String(os.Getenv("TABLE_NAME")),
+					UpdateExpression: aws.String("SET Projects.#pid = :proj"),
+				},
+			},
+			versionPut(email, newProject()),
+		},
+	}
+}
+
+var createProjectTests = []struct {
+	name    string
+	mockErr error
+	wantErr error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB TransactWriteItems call"),
+	},
+	{
+		name:    "ProjectAlreadyExists",
+		mockErr: canceledErr(conditionalCheckFailed, "None"),
+		wantErr: errors.NewClient("Project 'projectID' already exists"),
+	},
+	{
+		name:    "VersionAlreadyExists",
+		mockErr: canceledErr("None", conditionalCheckFailed),
+		wantErr: errors.NewClient("Project 'projectID' was changed by another request. Please try again"),
+	},
+	{
+		name: "SuccessfulInvocation",
+	},
+}
+
+func TestCreateProject(t *testing.T) {
+	for _, test := range createProjectTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			transactSvc = transactWriteItemsMock(createProjectMockInput("test@example.com", "projectID"), test.mockErr)
+			now = func() time.Time { return versionTime }
+			defer func() {
+				transactSvc = defaultSvc
+				now = time.Now
+			}()
+
+			// Execute
+			project := &Project{ID: "projectID", Name: "Project Name", Description: "Project description", Objects: map[string]*Object{}}
+			gotErr := Dynamo.CreateProject("test@example.com", project)
+
+			// Verify
+			if !errors.Equal(gotErr, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", gotErr, test.wantErr)
+			}
+		})
+	}
+}
+
+// ----------- UpdateProject Tests --------------
+
+func updateProjectMockInput(email string, projectID string) *dynamodb.UpdateItemInput {
+	return &dynamodb.UpdateItemInput{
+		ConditionExpression: aws.String("attribute_exists(Projects.#pid)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#pid":  aws.String(projectID),
+			"#name": aws.String("Name"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":name": {S: aws.String("New Name")},
+			":desc": {S: aws.String("New description")},
+		},
+		Key: map[string]*dynamodb.AttributeValue{
+			"Email": {
+				S: aws.String(email),
+			},
+		},
+		TableName:        aws.String(os.Getenv("TABLE_NAME")),
+		UpdateExpression: aws.String("SET Projects.#pid.#name = :name, Projects.#pid.Description = :desc"),
+	}
+}
+
+var updateProjectTests = []struct {
 	name      string
 	email     string
-	password  string
-	token     string
-	mockInput *dynamodb.PutItemInput
+	projectID string
+	mockInput *dynamodb.UpdateItemInput
 	mockErr   error
 	wantErr   error
 }{
 	{
 		name:      "ServiceError",
-		email:     "email",
-		password:  "password",
-		token:     "token",
-		mockInput: createUserMockInput("email", "password", "token"),
+		email:     "test@example.com",
+		projectID: "projectID",
+		mockInput: updateProjectMockInput("test@example.com", "projectID"),
 		mockErr:   errors.NewServer("DynamoDB failure"),
-		wantErr:   errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB PutItem call"),
+		wantErr:   errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB UpdateItem call"),
 	},
 	{
-		name:      "EmailAlreadyExists",
-		email:     "email",
-		password:  "password",
-		token:     "token",
-		mockInput: createUserMockInput("email", "password", "token"),
-		mockErr:   awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "Email exists", nil),
-		wantErr:   errors.NewClient("Email already in use"),
+		name:      "NonexistentProject",
+		email:     "test@example.com",
+		projectID: "projectID",
+		mockInput: updateProjectMockInput("test@example.com", "projectID"),
+		mockErr:   awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "Project does not exist", nil),
+		wantErr:   errors.NewClient("Project 'projectID' not found"),
 	},
 	{
 		name:      "SuccessfulInvocation",
-		email:     "email",
-		password:  "password",
-		token:     "token",
-		mockInput: createUserMockInput("email", "password", "token"),
+		email:     "test@example.com",
+		projectID: "projectID",
+		mockInput: updateProjectMockInput("test@example.com", "projectID"),
 	},
 }
 
-func createUserMockInput(email string, password string, token string) *dynamodb.PutItemInput {
-	return &dynamodb.PutItemInput{
-		ConditionExpression: aws.String("attribute_not_exists(Email)"),
-		Item: map[string]*dynamodb.AttributeValue{
+func TestUpdateProject(t *testing.T) {
+	for _, test := range updateProjectTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			updateSvc = updateItemMock(test.mockInput, nil, test.mockErr)
+			defer func() {
+				updateSvc = defaultSvc
+			}()
+
+			// Execute
+			gotErr := Dynamo.UpdateProject(test.email, test.projectID, "New Name", "New description")
+
+			// Verify
+			if !errors.Equal(gotErr, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", gotErr, test.wantErr)
+			}
+		})
+	}
+}
+
+// ----------- UpdateDeployment Tests --------------
+
+func updateDeploymentMockInput(email string, projectID string) *dynamodb.UpdateItemInput {
+	return &dynamodb.UpdateItemInput{
+		ConditionExpression: aws.String("attribute_exists(Projects.#pid)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#pid": aws.String(projectID),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":provider": {S: aws.String("ec2")},
+			":id":       {S: aws.String("instanceID")},
+			":url":      {S: aws.String("example.com")},
+			":status":   {S: aws.String(DeployHealthy)},
+			":error":    {NULL: aws.Bool(true)},
+		},
+		Key: map[string]*dynamodb.AttributeValue{
 			"Email": {
 				S: aws.String(email),
 			},
-			"Password": {
-				S: aws.String(password),
-			},
-			"SessionToken": {
-				S: aws.String(token),
+		},
+		TableName:        aws.String(os.Getenv("TABLE_NAME")),
+		UpdateExpression: aws.String("SET Projects.#pid.DeployProvider = :provider, Projects.#pid.InstanceId = :id, Projects.#pid.DeployUrl = :url, Projects.#pid.DeployStatus = :status, Projects.#pid.DeployError = :error"),
+	}
+}
+
+var updateDeploymentTests = []struct {
+	name      string
+	mockInput *dynamodb.UpdateItemInput
+	mockErr   error
+	wantErr   error
+}{
+	{
+		name:      "ServiceError",
+		mockInput: updateDeploymentMockInput("test@example.com", "projectID"),
+		mockErr:   errors.NewServer("DynamoDB failure"),
+		wantErr:   errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB UpdateItem call"),
+	},
+	{
+		name:      "NonexistentProject",
+		mockInput: updateDeploymentMockInput("test@example.com", "projectID"),
+		mockErr:   awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "Project does not exist", nil),
+		wantErr:   errors.NewClient("Project 'projectID' not found"),
+	},
+	{
+		name:      "SuccessfulInvocation",
+		mockInput: updateDeploymentMockInput("test@example.com", "projectID"),
+	},
+}
+
+func TestUpdateDeployment(t *testing.T) {
+	for _, test := range updateDeploymentTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			updateSvc = updateItemMock(test.mockInput, nil, test.mockErr)
+			defer func() {
+				updateSvc = defaultSvc
+			}()
+
+			// Execute
+			deployment := &Deployment{Provider: "ec2", InstanceID: "instanceID", URL: "example.com", Status: DeployHealthy}
+			gotErr := Dynamo.UpdateDeployment("test@example.com", "projectID", deployment)
+
+			// Verify
+			if !errors.Equal(gotErr, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", gotErr, test.wantErr)
+			}
+		})
+	}
+}
+
+// ----------- AdvanceDeployment Tests --------------
+
+func advanceDeploymentMockInput(email string, projectID string) *dynamodb.UpdateItemInput {
+	input := updateDeploymentMockInput(email, projectID)
+	input.ConditionExpression = aws.String("Projects.#pid.InstanceId = :expected")
+	input.ExpressionAttributeValues[":expected"] = &dynamodb.AttributeValue{S: aws.String("instanceID")}
+	return input
+}
+
+var advanceDeploymentTests = []struct {
+	name        string
+	mockErr     error
+	wantApplied bool
+	wantErr     error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB UpdateItem call"),
+	},
+	{
+		name:    "InstanceReplaced",
+		mockErr: awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "Instance changed", nil),
+	},
+	{
+		name:        "SuccessfulInvocation",
+		wantApplied: true,
+	},
+}
+
+func TestAdvanceDeployment(t *testing.T) {
+	for _, test := range advanceDeploymentTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			updateSvc = updateItemMock(advanceDeploymentMockInput("test@example.com", "projectID"), nil, test.mockErr)
+			defer func() {
+				updateSvc = defaultSvc
+			}()
+
+			// Execute
+			deployment := &Deployment{Provider: "ec2", InstanceID: "instanceID", URL: "example.com", Status: DeployHealthy}
+			applied, err := Dynamo.AdvanceDeployment("test@example.com", "projectID", deployment)
+
+			// Verify
+			if applied != test.wantApplied {
+				t.Errorf("Got applied %t; want %t", applied, test.wantApplied)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ----------- UpdateDeployConfig Tests --------------
+
+func updateDeployConfigMockInput(email string, projectID string) *dynamodb.UpdateItemInput {
+	return &dynamodb.UpdateItemInput{
+		ConditionExpression: aws.String("attribute_exists(Projects.#pid)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#pid": aws.String(projectID),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":config": {M: map[string]*dynamodb.AttributeValue{
+				"Region":       {S: aws.String("eu-west-1")},
+				"InstanceType": {S: aws.String("t3.small")},
+				"IngressCidr":  {S: aws.String("203.0.113.0/24")},
+				"Port":         {N: aws.String("1337")},
+			}},
+		},
+		Key: map[string]*dynamodb.AttributeValue{
+			"Email": {
+				S: aws.String(email),
 			},
-			"Projects": {
-				M: map[string]*dynamodb.AttributeValue{
-					"defaultProject": {
-						M: map[string]*dynamodb.AttributeValue{
-							"Id":          {S: aws.String("defaultProject")},
-							"Name":        {S: aws.String("Default Project")},
-							"Description": {S: aws.String(defaultProjectDesc)},
-							"Objects":     {M: map[string]*dynamodb.AttributeValue{}},
-						},
-					},
-				},
+		},
+		TableName:        aws.String(os.Getenv("TABLE_NAME")),
+		UpdateExpression: aws.String("SET Projects.#pid.DeployConfig = :config"),
+	}
+}
+
+var updateDeployConfigTests = []struct {
+	name      string
+	mockInput *dynamodb.UpdateItemInput
+	mockErr   error
+	wantErr   error
+}{
+	{
+		name:      "ServiceError",
+		mockInput: updateDeployConfigMockInput("test@example.com", "projectID"),
+		mockErr:   errors.NewServer("DynamoDB failure"),
+		wantErr:   errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB UpdateItem call"),
+	},
+	{
+		name:      "NonexistentProject",
+		mockInput: updateDeployConfigMockInput("test@example.com", "projectID"),
+		mockErr:   awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "Project does not exist", nil),
+		wantErr:   errors.NewClient("Project 'projectID' not found"),
+	},
+	{
+		name:      "SuccessfulInvocation",
+		mockInput: updateDeployConfigMockInput("test@example.com", "projectID"),
+	},
+}
+
+func TestUpdateDeployConfig(t *testing.T) {
+	for _, test := range updateDeployConfigTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			updateSvc = updateItemMock(test.mockInput, nil, test.mockErr)
+			defer func() {
+				updateSvc = defaultSvc
+			}()
+
+			// Execute
+			config := &DeployConfig{Region: "eu-west-1", InstanceType: "t3.small", IngressCIDR: "203.0.113.0/24", Port: 1337}
+			gotErr := Dynamo.UpdateDeployConfig("test@example.com", "projectID", config)
+
+			// Verify
+			if !errors.Equal(gotErr, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", gotErr, test.wantErr)
+			}
+		})
+	}
+}
+
+// ----------- DeleteProject Tests --------------
+
+func deleteProjectMockInput(email string, projectID string) *dynamodb.UpdateItemInput {
+	return &dynamodb.UpdateItemInput{
+		ExpressionAttributeNames: map[string]*string{
+			"#pid": aws.String(projectID),
+		},
+		Key: map[string]*dynamodb.AttributeValue{
+			"Email": {
+				S: aws.String(email),
 			},
 		},
-		TableName: aws.String(os.Getenv("TABLE_NAME")),
+		TableName:        aws.String(os.Getenv("TABLE_NAME")),
+		UpdateExpression: aws.String("REMOVE Projects.#pid"),
 	}
 }
 
-func TestCreateUser(t *testing.T) {
-	for _, test := range createUserTests {
+var deleteProjectTests = []struct {
+	name       string
+	email      string
+	projectID  string
+	mockInput  *dynamodb.UpdateItemInput
+	mockErr    error
+	queryInput *dynamodb.QueryInput
+	queryErr   error
+	wantErr    error
+}{
+	{
+		name:      "ServiceError",
+		email:     "test@example.com",
+		projectID: "projectID",
+		mockInput: deleteProjectMockInput("test@example.com", "projectID"),
+		mockErr:   errors.NewServer("DynamoDB failure"),
+		wantErr:   errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB UpdateItem call"),
+	},
+	{
+		name:       "VersionsError",
+		email:      "test@example.com",
+		projectID:  "projectID",
+		mockInput:  deleteProjectMockInput("test@example.com", "projectID"),
+		queryInput: versionKeysQueryInput("test@example.com", "projectID"),
+		queryErr:   errors.NewServer("DynamoDB failure"),
+		wantErr:    errors.Wrap(errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB Query call"), "Failed to delete project versions"),
+	},
+	{
+		name:       "SuccessfulInvocation",
+		email:      "test@example.com",
+		projectID:  "projectID",
+		mockInput:  deleteProjectMockInput("test@example.com", "projectID"),
+		queryInput: versionKeysQueryInput("test@example.com", "projectID"),
+	},
+}
+
+func TestDeleteProject(t *testing.T) {
+	for _, test := range deleteProjectTests {
 		t.Run(test.name, func(t *testing.T) {
 			// Setup
-			putSvc = putItemMock(test.mockInput, nil, test.mockErr)
+			updateSvc = updateItemMock(test.mockInput, nil, test.mockErr)
+			querySvc = queryMock([]*dynamodb.QueryInput{test.queryInput}, []*dynamodb.QueryOutput{{}}, test.queryErr)
 			defer func() {
-				putSvc = defaultSvc
+				updateSvc = defaultSvc
+				querySvc = defaultSvc
 			}()
 
 			// Execute
-			gotErr := Dynamo.CreateUser(test.email, test.password, test.token)
+			gotErr := Dynamo.DeleteProject(test.email, test.projectID)
 
 			// Verify
 			if !errors.Equal(gotErr, test.wantErr) {
@@ -520,185 +1005,168 @@ func TestUpdateItem(t *testing.T) {
 	}
 }
 
-var updateObjectTests = []struct {
-	name string
+// ----------- UpdateObject Tests --------------
 
-	// Input
-	email      string
-	projectID  string
+var author = &Object{ID: "author", Name: "Author", CodeName: "Author"}
+
+var updateObjectTests = []struct {
+	mutationTest
 	object     *Object
 	originalID string
-
-	// Mock data
-	mockInput *dynamodb.UpdateItemInput
-	mockErr   error
-
-	// Expected output
-	wantErr error
+	version    int
 }{
 	{
-		name:      "ServiceError",
-		email:     "error@test.com",
-		projectID: "projectID",
-		object:    &Object{ID: "objectID", Name: "objectName", CodeName: "ObjectName", Description: "objectDesc"},
-		mockInput: &dynamodb.UpdateItemInput{
-			ExpressionAttributeNames: map[string]*string{
-				"#pid": aws.String("projectID"),
-				"#oid": aws.String("objectID"),
-			},
-			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-				":obj": {
-					M: map[string]*dynamodb.AttributeValue{
-						"Id":          {S: aws.String("objectID")},
-						"Name":        {S: aws.String("objectName")},
-						"CodeName":    {S: aws.String("ObjectName")},
-						"Description": {S: aws.String("objectDesc")},
-					},
-				},
-			},
-			Key: map[string]*dynamodb.AttributeValue{
-				"Email": {
-					S: aws.String("error@test.com"),
-				},
-			},
-			TableName:        aws.String(os.Getenv("TABLE_NAME")),
-			UpdateExpression: aws.String("SET Projects.#pid.Objects.#oid = :obj"),
+		mutationTest: mutationTest{
+			name:    "StaleVersion",
+			wantErr: errors.NewClient("Project 'projectID' was changed by another request. Please try again"),
 		},
-		mockErr: errors.NewServer("DynamoDB failure"),
-		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB UpdateItem call"),
+		object:  author,
+		version: 2,
 	},
 	{
-		name:       "ConstantID",
-		email:      "error@test.com",
-		projectID:  "projectID",
-		object:     &Object{ID: "objectID", Name: "objectName", CodeName: "ObjectName", Description: "objectDesc"},
-		originalID: "objectID",
-		mockInput: &dynamodb.UpdateItemInput{
-			ExpressionAttributeNames: map[string]*string{
-				"#pid": aws.String("projectID"),
-				"#oid": aws.String("objectID"),
-			},
-			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-				":obj": {
-					M: map[string]*dynamodb.AttributeValue{
-						"Id":          {S: aws.String("objectID")},
-						"Name":        {S: aws.String("objectName")},
-						"CodeName":    {S: aws.String("ObjectName")},
-						"Description": {S: aws.String("objectDesc")},
-					},
-				},
-			},
-			Key: map[string]*dynamodb.AttributeValue{
-				"Email": {
-					S: aws.String("error@test.com"),
-				},
-			},
-			TableName:        aws.String(os.Getenv("TABLE_NAME")),
-			UpdateExpression: aws.String("SET Projects.#pid.Objects.#oid = :obj"),
+		mutationTest: mutationTest{
+			name:        "ConcurrentChange",
+			transactErr: canceledErr(conditionalCheckFailed, "None"),
+			wantProject: changedProject(func(project *Project) {
+				project.Objects["author"] = author
+			}),
+			wantErr: errors.NewClient("Project 'projectID' was changed by another request. Please try again"),
 		},
+		object:  author,
+		version: 3,
 	},
 	{
-		name:       "ChangingID",
-		email:      "error@test.com",
-		projectID:  "projectID",
-		object:     &Object{ID: "objectID", Name: "objectName", CodeName: "ObjectName", Description: "objectDesc"},
-		originalID: "differentID",
-		mockInput: &dynamodb.UpdateItemInput{
-			ExpressionAttributeNames: map[string]*string{
-				"#pid":  aws.String("projectID"),
-				"#oid1": aws.String("differentID"),
-				"#oid2": aws.String("objectID"),
-			},
-			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-				":obj": {
-					M: map[string]*dynamodb.AttributeValue{
-						"Id":          {S: aws.String("objectID")},
-						"Name":        {S: aws.String("objectName")},
-						"CodeName":    {S: aws.String("ObjectName")},
-						"Description": {S: aws.String("objectDesc")},
-					},
-				},
-			},
-			Key: map[string]*dynamodb.AttributeValue{
-				"Email": {
-					S: aws.String("error@test.com"),
-				},
-			},
-			TableName:        aws.String(os.Getenv("TABLE_NAME")),
-			UpdateExpression: aws.String("REMOVE Projects.#pid.Objects.#oid1 SET Projects.#pid.Objects.#oid2 = :obj"),
+		mutationTest: mutationTest{
+			name: "NewObject",
+			wantProject: changedProject(func(project *Project) {
+				project.Objects["author"] = author
+			}),
+		},
+		object:  author,
+		version: 3,
+	},
+	{
+		mutationTest: mutationTest{
+			name: "ConstantID",
+			wantProject: changedProject(func(project *Project) {
+				project.Objects["author"] = author
+			}),
+		},
+		object:     author,
+		originalID: "author",
+		version:    3,
+	},
+	{
+		mutationTest: mutationTest{
+			name: "ChangingID",
+			wantProject: changedProject(func(project *Project) {
+				delete(project.Objects, "book")
+				project.Objects["author"] = author
+			}),
 		},
+		object:     author,
+		originalID: "book",
+		version:    3,
 	},
 }
 
 func TestUpdateObject(t *testing.T) {
 	for _, test := range updateObjectTests {
-		t.Run(test.name, func(t *testing.T) {
-			// Setup
-			updateSvc = updateItemMock(test.mockInput, nil, test.mockErr)
-			defer func() {
-				updateSvc = defaultSvc
-			}()
-
-			// Execute
-			gotErr := Dynamo.UpdateObject(test.email, test.projectID, test.object, test.originalID)
-
-			// Verify
-			if !errors.Equal(gotErr, test.wantErr) {
-				t.Errorf("Got error '%s'; want '%s'", gotErr, test.wantErr)
-			}
+		runMutationTest(t, test.mutationTest, func() error {
+			return Dynamo.UpdateObject("test@example.com", "projectID", test.object, test.originalID, test.version)
 		})
 	}
 }
 
-var updateUserTokenTests = []struct {
-	name string
+// ----------- UpdateEndpoint Tests --------------
 
-	// Input
-	email string
-	token string
+var endpoint = &Endpoint{ID: "listBooks", Name: "listBooks", CodeName: "listBooks", Method: "GET", Path: "/books", Object: "book", Operation: "list"}
 
-	// Mock data
-	mockInput *dynamodb.UpdateItemInput
-	mockErr   error
+// withoutEndpoints returns version 3 of versionedProject, stored before projects had an Endpoints map.
+func withoutEndpoints() *Project {
+	project := versionedProject("projectID", 3)
+	project.Endpoints = nil
+	return project
+}
 
-	// Expected output
-	wantErr error
-}{
+var updateEndpointTests = []mutationTest{
 	{
-		name:  "SuccessfulInvocation",
-		email: "success@test.com",
-		token: "tokenValue",
-		mockInput: &dynamodb.UpdateItemInput{
-			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-				":tok": {S: aws.String("tokenValue")},
-			},
-			Key: map[string]*dynamodb.AttributeValue{
-				"Email": {
-					S: aws.String("success@test.com"),
-				},
-			},
-			TableName:        aws.String(os.Getenv("TABLE_NAME")),
-			UpdateExpression: aws.String("SET SessionToken = :tok"),
-		},
+		name:        "ServiceError",
+		transactErr: errors.NewServer("DynamoDB failure"),
+		wantProject: changedProject(func(project *Project) {
+			project.Endpoints["listBooks"] = endpoint
+		}),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB TransactWriteItems call"),
+	},
+	{
+		name: "ExistingEndpoints",
+		wantProject: changedProject(func(project *Project) {
+			project.Endpoints["listBooks"] = endpoint
+		}),
+	},
+	{
+		name:   "MissingEndpoints",
+		stored: withoutEndpoints(),
+		wantProject: changedProject(func(project *Project) {
+			project.Endpoints["listBooks"] = endpoint
+		}),
+	},
+	{
+		// Two requests that add the first endpoint of a project must not both succeed.
+		name:        "ConcurrentFirstEndpoint",
+		stored:      withoutEndpoints(),
+		transactErr: canceledErr(conditionalCheckFailed, "None"),
+		wantProject: changedProject(func(project *Project) {
+			project.Endpoints["listBooks"] = endpoint
+		}),
+		wantErr: errors.NewClient("Project 'projectID' was changed by another request. Please try again"),
 	},
 }
 
-func TestUpdateUserToken(t *testing.T) {
-	for _, test := range updateUserTokenTests {
-		t.Run(test.name, func(t *testing.T) {
-			// Setup
-			updateSvc = updateItemMock(test.mockInput, nil, test.mockErr)
-			defer func() {
-				updateSvc = defaultSvc
-			}()
+func TestUpdateEndpoint(t *testing.T) {
+	for _, test := range updateEndpointTests {
+		runMutationTest(t, test, func() error {
+			return Dynamo.UpdateEndpoint("test@example.com", "projectID", endpoint)
+		})
+	}
+}
 
-			// Execute
-			gotErr := Dynamo.UpdateUserToken(test.email, test.token)
+// ----------- DeleteEndpoint Tests --------------
 
-			// Verify
-			if !errors.Equal(gotErr, test.wantErr) {
-				t.Errorf("Got error '%s'; want '%s'", gotErr, test.wantErr)
-			}
+// withEndpoint returns version 3 of versionedProject with a single endpoint.
+func withEndpoint() *Project {
+	project := versionedProject("projectID", 3)
+	project.Endpoints["listBooks"] = endpoint
+	return project
+}
+
+var deleteEndpointTests = []mutationTest{
+	{
+		name:        "ServiceError",
+		stored:      withEndpoint(),
+		transactErr: errors.NewServer("DynamoDB failure"),
+		wantProject: versionedProject("projectID", 4),
+		wantErr:     errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB TransactWriteItems call"),
+	},
+	{
+		name:        "ExistingEndpoint",
+		stored:      withEndpoint(),
+		wantProject: versionedProject("projectID", 4),
+	},
+	{
+		// Deleting an endpoint that does not exist must not record an empty version.
+		name: "MissingEndpoint",
+	},
+	{
+		name:   "MissingEndpoints",
+		stored: withoutEndpoints(),
+	},
+}
+
+func TestDeleteEndpoint(t *testing.T) {
+	for _, test := range deleteEndpointTests {
+		runMutationTest(t, test, func() error {
+			return Dynamo.DeleteEndpoint("test@example.com", "projectID", "listBooks")
 		})
 	}
 }
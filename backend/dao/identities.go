@@ -0,0 +1,72 @@
+package dao
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// CreateExternalUser adds a User object to the database for a user who signed up with an external identity
+// provider, with the given project as its only project. The new user has a verified email, since the provider
+// verified it, and no password, so it cannot log in with a password until it resets it. If the email already
+// exists in the database, CreateExternalUser makes no changes to the database and returns a client error.
+func (dynamo) CreateExternalUser(email string, project *Project) error {
+	return Dynamo.createUser(map[string]*dynamodb.AttributeValue{
+		"Email": {
+			S: aws.String(email),
+		},
+		"EmailVerified": {
+			BOOL: aws.Bool(true),
+		},
+	}, project)
+}
+
+// CreateIdentity adds the given identity to the identities table. If the identity is already linked to a user,
+// CreateIdentity makes no changes to the database and returns a client error.
+func (dynamo) CreateIdentity(identity *Identity) error {
+	item, err := dynamodbattribute.MarshalMap(identity)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal identity")
+	}
+
+	input := &dynamodb.PutItemInput{
+		ConditionExpression: aws.String("attribute_not_exists(IdentityId)"),
+		Item:                item,
+		TableName:           aws.String(os.Getenv("IDENTITY_TABLE_NAME")),
+	}
+	_, err = putSvc.PutItem(input)
+	if isConditionalCheckFailure(err) {
+		return errors.NewClient(fmt.Sprintf("This %s account is already linked to a user", identity.Provider))
+	}
+	return errors.Wrap(err, "Failed DynamoDB PutItem call")
+}
+
+// GetIdentity returns the identity with the given ID. If the identity does not exist, the returned identity will be
+// nil and the returned error will be a new client error.
+func (dynamo) GetIdentity(identityID string) (*Identity, error) {
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"IdentityId": {S: aws.String(identityID)},
+		},
+		TableName: aws.String(os.Getenv("IDENTITY_TABLE_NAME")),
+	}
+
+	result, err := getSvc.GetItem(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed DynamoDB GetItem call")
+	}
+	if result.Item == nil {
+		return nil, errors.NewClient(fmt.Sprintf("Identity '%s' not found", identityID))
+	}
+
+	identity := Identity{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, &identity)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal GetItem result")
+	}
+	return &identity, nil
+}
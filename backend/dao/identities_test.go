@@ -0,0 +1,199 @@
+package dao
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// -------------- Helpers -----------------
+
+// testIdentity returns the identity of the GitHub account 1234 of test@example.com.
+func testIdentity() *Identity {
+	return &Identity{
+		ID:       "github#1234",
+		Provider: "github",
+		Subject:  "1234",
+		Email:    "test@example.com",
+		Created:  sessionTime,
+	}
+}
+
+// identityItem returns the item that stores the identity returned by testIdentity.
+func identityItem() map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"IdentityId": {S: aws.String("github#1234")},
+		"Provider":   {S: aws.String("github")},
+		"Subject":    {S: aws.String("1234")},
+		"Email":      {S: aws.String("test@example.com")},
+		"Created":    {S: aws.String("2020-05-01T12:30:00Z")},
+	}
+}
+
+// ------------- CreateExternalUser Tests ------------------
+
+var createExternalUserTests = []struct {
+	name    string
+	mockErr error
+	wantErr error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB TransactWriteItems call"),
+	},
+	{
+		name:    "EmailInUse",
+		mockErr: canceledErr(conditionalCheckFailed, "None"),
+		wantErr: errors.NewClient("Email already in use"),
+	},
+	{
+		name: "SuccessfulInvocation",
+	},
+}
+
+func TestCreateExternalUser(t *testing.T) {
+	for _, test := range createExternalUserTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			project, _ := encoder.Encode(firstVersion())
+			transactSvc = transactWriteItemsMock(&dynamodb.TransactWriteItemsInput{
+				TransactItems: []*dynamodb.TransactWriteItem{
+					{
+						Put: &dynamodb.Put{
+							ConditionExpression: aws.String("attribute_not_exists(Email)"),
+							Item: map[string]*dynamodb.AttributeValue{
+								"Email":         {S: aws.String("test@example.com")},
+								"EmailVerified": {BOOL: aws.Bool(true)},
+								"Projects":      {M: map[string]*dynamodb.AttributeValue{DefaultProjectID: project}},
+							},
+							TableName: aws.String(os.Getenv("TABLE_NAME")),
+						},
+					},
+					versionPut("test@example.com", firstVersion()),
+				},
+			}, test.mockErr)
+			now = func() time.Time { return versionTime }
+			defer func() {
+				transactSvc = defaultSvc
+				now = time.Now
+			}()
+
+			// Execute
+			err := Dynamo.CreateExternalUser("test@example.com", DefaultProject())
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- CreateIdentity Tests ------------------
+
+var createIdentityTests = []struct {
+	name    string
+	mockErr error
+	wantErr error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB PutItem call"),
+	},
+	{
+		name:    "AlreadyLinked",
+		mockErr: awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "Identity exists", nil),
+		wantErr: errors.NewClient("This github account is already linked to a user"),
+	},
+	{
+		name: "SuccessfulInvocation",
+	},
+}
+
+func TestCreateIdentity(t *testing.T) {
+	for _, test := range createIdentityTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			putSvc = putItemMock(&dynamodb.PutItemInput{
+				ConditionExpression: aws.String("attribute_not_exists(IdentityId)"),
+				Item:                identityItem(),
+				TableName:           aws.String(os.Getenv("IDENTITY_TABLE_NAME")),
+			}, nil, test.mockErr)
+			defer func() {
+				putSvc = defaultSvc
+			}()
+
+			// Execute
+			err := Dynamo.CreateIdentity(testIdentity())
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- GetIdentity Tests ------------------
+
+var getIdentityTests = []struct {
+	name string
+
+	// Mock data
+	mockOutput *dynamodb.GetItemOutput
+	mockErr    error
+
+	// Expected output
+	wantIdentity *Identity
+	wantErr      error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB GetItem call"),
+	},
+	{
+		name:       "NotFound",
+		mockOutput: &dynamodb.GetItemOutput{},
+		wantErr:    errors.NewClient("Identity 'github#1234' not found"),
+	},
+	{
+		name:         "SuccessfulInvocation",
+		mockOutput:   &dynamodb.GetItemOutput{Item: identityItem()},
+		wantIdentity: testIdentity(),
+	},
+}
+
+func TestGetIdentity(t *testing.T) {
+	for _, test := range getIdentityTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			getSvc = getItemMock(&dynamodb.GetItemInput{
+				Key:       map[string]*dynamodb.AttributeValue{"IdentityId": {S: aws.String("github#1234")}},
+				TableName: aws.String(os.Getenv("IDENTITY_TABLE_NAME")),
+			}, test.mockOutput, test.mockErr)
+			defer func() {
+				getSvc = defaultSvc
+			}()
+
+			// Execute
+			identity, err := Dynamo.GetIdentity("github#1234")
+
+			// Verify
+			if !reflect.DeepEqual(identity, test.wantIdentity) {
+				t.Errorf("Got identity %+v; want %+v", identity, test.wantIdentity)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
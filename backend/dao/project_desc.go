@@ -1,6 +1,20 @@
 package dao
 
-const defaultProjectDesc = "This is the default project. Future versions of this website will allow you to create multiple projects. If you had created your own project, " +
-	"a description of it would go here. For now, this site allows you to define database objects and auto-generate the code to support a REST API " +
+// DefaultProjectID is the ID of the project that is created for every new user.
+const DefaultProjectID = "defaultProject"
+
+const defaultProjectDesc = "This is the default project. It was created for you when you signed up, and you can rename it, delete it or create " +
+	"additional projects at any time. For now, this site allows you to define database objects and auto-generate the code to support a REST API " +
 	"based on those objects. Future versions will also allow you to automatically deploy the API to AWS and other cloud services. This page will " +
 	"allow you to see the status of those deployed APIs."
+
+// DefaultProject returns the project that is created for every new user.
+func DefaultProject() *Project {
+	return &Project{
+		ID:          DefaultProjectID,
+		Name:        "Default Project",
+		Description: defaultProjectDesc,
+		Objects:     map[string]*Object{},
+		Endpoints:   map[string]*Endpoint{},
+	}
+}
@@ -0,0 +1,149 @@
+package dao
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// deleter wraps the DeleteItem method in order to perform dependency injection in the dynamo tests.
+type deleter interface {
+	DeleteItem(*dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error)
+}
+
+// This variable should only be changed inside a test.
+var deleteSvc deleter = defaultSvc
+
+// sessionEmailIndex is the name of the global secondary index of the sessions table, which is keyed by Email.
+const sessionEmailIndex = "EmailIndex"
+
+// sessionTableKey returns the primary key of the session with the given ID.
+func sessionTableKey(sessionID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"SessionId": {S: aws.String(sessionID)},
+	}
+}
+
+// CreateSession adds the given session to the sessions table. If a session with the same ID already exists,
+// CreateSession makes no changes to the database and returns an error.
+func (dynamo) CreateSession(session *Session) error {
+	item, err := dynamodbattribute.MarshalMap(session)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal session")
+	}
+
+	input := &dynamodb.PutItemInput{
+		ConditionExpression: aws.String("attribute_not_exists(SessionId)"),
+		Item:                item,
+		TableName:           aws.String(os.Getenv("SESSION_TABLE_NAME")),
+	}
+	_, err = putSvc.PutItem(input)
+	return errors.Wrap(err, "Failed DynamoDB PutItem call")
+}
+
+// GetSession returns the session with the given ID. If the session does not exist, the returned session will
+// be nil and the returned error will be a new client error. Expired sessions may still be returned until
+// DynamoDB deletes them, so callers must check the expiry themselves.
+func (dynamo) GetSession(sessionID string) (*Session, error) {
+	input := &dynamodb.GetItemInput{
+		Key:       sessionTableKey(sessionID),
+		TableName: aws.String(os.Getenv("SESSION_TABLE_NAME")),
+	}
+
+	result, err := getSvc.GetItem(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed DynamoDB GetItem call")
+	}
+	if result.Item == nil {
+		return nil, errors.NewClient(fmt.Sprintf("Session '%s' not found", sessionID))
+	}
+
+	session := Session{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, &session)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal GetItem result")
+	}
+	return &session, nil
+}
+
+// TouchSession sets the last-seen time of the session with the given ID. If the session does not exist, a
+// client error is returned and no changes are made.
+func (dynamo) TouchSession(sessionID string, lastSeen time.Time) error {
+	item, err := dynamodbattribute.Marshal(lastSeen)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal last-seen time")
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		ConditionExpression:       aws.String("attribute_exists(SessionId)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":seen": item},
+		Key:                       sessionTableKey(sessionID),
+		TableName:                 aws.String(os.Getenv("SESSION_TABLE_NAME")),
+		UpdateExpression:          aws.String("SET LastSeen = :seen"),
+	}
+	_, err = updateSvc.UpdateItem(input)
+	if isConditionalCheckFailure(err) {
+		return errors.NewClient(fmt.Sprintf("Session '%s' not found", sessionID))
+	}
+	return errors.Wrap(err, "Failed DynamoDB UpdateItem call")
+}
+
+// ListSessions returns the unexpired sessions of the user with the given email, in no particular order.
+func (dynamo) ListSessions(email string) ([]*Session, error) {
+	input := &dynamodb.QueryInput{
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":email": {S: aws.String(email)},
+		},
+		IndexName:              aws.String(sessionEmailIndex),
+		KeyConditionExpression: aws.String("Email = :email"),
+		TableName:              aws.String(os.Getenv("SESSION_TABLE_NAME")),
+	}
+
+	sessions := []*Session{}
+	current := now()
+	for {
+		result, err := querySvc.Query(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed DynamoDB Query call")
+		}
+
+		var page []*Session
+		err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &page)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to unmarshal Query result")
+		}
+		for _, session := range page {
+			if session.Expires.After(current) {
+				sessions = append(sessions, session)
+			}
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return sessions, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// DeleteSession deletes the session with the given ID. If the session does not exist or does not belong to
+// the user with the given email, a client error is returned and no changes are made.
+func (dynamo) DeleteSession(email string, sessionID string) error {
+	input := &dynamodb.DeleteItemInput{
+		ConditionExpression: aws.String("Email = :email"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":email": {S: aws.String(email)},
+		},
+		Key:       sessionTableKey(sessionID),
+		TableName: aws.String(os.Getenv("SESSION_TABLE_NAME")),
+	}
+	_, err := deleteSvc.DeleteItem(input)
+	if isConditionalCheckFailure(err) {
+		return errors.NewClient(fmt.Sprintf("Session '%s' not found", sessionID))
+	}
+	return errors.Wrap(err, "Failed DynamoDB DeleteItem call")
+}
@@ -0,0 +1,345 @@
+package dao
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// -------------- DeleteItem Mock -----------------
+
+type deleteItemFunc func(*dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error)
+
+func (f deleteItemFunc) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	return f(input)
+}
+
+func deleteItemMock(mockInput *dynamodb.DeleteItemInput, mockErr error) deleteItemFunc {
+	return func(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+		if !reflect.DeepEqual(input, mockInput) {
+			return nil, errors.NewServer("Incorrect DeleteItemInput to mock")
+		}
+		if mockErr != nil {
+			return nil, mockErr
+		}
+		return &dynamodb.DeleteItemOutput{}, nil
+	}
+}
+
+// -------------- Helpers -----------------
+
+var sessionTime = time.Date(2020, time.May, 1, 12, 30, 0, 0, time.UTC)
+
+// Unix times before and after sessionTime, used as the expiry of expired and active sessions.
+const (
+	expiredTime = 1588000000
+	activeTime  = 1590000000
+)
+
+// testSession returns a session with the given ID that expires at the given Unix time.
+func testSession(sessionID string, expires int64) *Session {
+	return &Session{
+		ID:        sessionID,
+		Email:     "test@example.com",
+		Created:   sessionTime,
+		LastSeen:  sessionTime,
+		Expires:   time.Unix(expires, 0),
+		UserAgent: "Mozilla/5.0",
+		IP:        "203.0.113.1",
+	}
+}
+
+// sessionItem returns the item that stores the session with the given ID that expires at the given Unix time.
+func sessionItem(sessionID string, expires int64) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"SessionId": {S: aws.String(sessionID)},
+		"Email":     {S: aws.String("test@example.com")},
+		"Created":   {S: aws.String("2020-05-01T12:30:00Z")},
+		"LastSeen":  {S: aws.String("2020-05-01T12:30:00Z")},
+		"Expires":   {N: aws.String(strconv.FormatInt(expires, 10))},
+		"UserAgent": {S: aws.String("Mozilla/5.0")},
+		"IP":        {S: aws.String("203.0.113.1")},
+	}
+}
+
+// sessionQueryInput returns the QueryInput that lists the sessions of test@example.com.
+func sessionQueryInput(startKey map[string]*dynamodb.AttributeValue) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		ExclusiveStartKey: startKey,
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":email": {S: aws.String("test@example.com")},
+		},
+		IndexName:              aws.String("EmailIndex"),
+		KeyConditionExpression: aws.String("Email = :email"),
+		TableName:              aws.String(os.Getenv("SESSION_TABLE_NAME")),
+	}
+}
+
+var conditionalCheckFailure = awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "Condition failed", nil)
+
+// ------------- CreateSession Tests ------------------
+
+var createSessionTests = []struct {
+	name    string
+	mockErr error
+	wantErr error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB PutItem call"),
+	},
+	{
+		name: "SuccessfulInvocation",
+	},
+}
+
+func TestCreateSession(t *testing.T) {
+	for _, test := range createSessionTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			putSvc = putItemMock(&dynamodb.PutItemInput{
+				ConditionExpression: aws.String("attribute_not_exists(SessionId)"),
+				Item:                sessionItem("sessionID", activeTime),
+				TableName:           aws.String(os.Getenv("SESSION_TABLE_NAME")),
+			}, nil, test.mockErr)
+			defer func() {
+				putSvc = defaultSvc
+			}()
+
+			// Execute
+			err := Dynamo.CreateSession(testSession("sessionID", activeTime))
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- GetSession Tests ------------------
+
+var getSessionTests = []struct {
+	name string
+
+	// Mock data
+	mockOutput *dynamodb.GetItemOutput
+	mockErr    error
+
+	// Expected output
+	wantSession *Session
+	wantErr     error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB GetItem call"),
+	},
+	{
+		name:       "NotFound",
+		mockOutput: &dynamodb.GetItemOutput{},
+		wantErr:    errors.NewClient("Session 'sessionID' not found"),
+	},
+	{
+		name:        "SuccessfulInvocation",
+		mockOutput:  &dynamodb.GetItemOutput{Item: sessionItem("sessionID", activeTime)},
+		wantSession: testSession("sessionID", activeTime),
+	},
+}
+
+func TestGetSession(t *testing.T) {
+	for _, test := range getSessionTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			getSvc = getItemMock(&dynamodb.GetItemInput{
+				Key:       map[string]*dynamodb.AttributeValue{"SessionId": {S: aws.String("sessionID")}},
+				TableName: aws.String(os.Getenv("SESSION_TABLE_NAME")),
+			}, test.mockOutput, test.mockErr)
+			defer func() {
+				getSvc = defaultSvc
+			}()
+
+			// Execute
+			session, err := Dynamo.GetSession("sessionID")
+
+			// Verify
+			if !reflect.DeepEqual(session, test.wantSession) {
+				t.Errorf("Got session %+v; want %+v", session, test.wantSession)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- TouchSession Tests ------------------
+
+var touchSessionTests = []struct {
+	name    string
+	mockErr error
+	wantErr error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB UpdateItem call"),
+	},
+	{
+		name:    "NotFound",
+		mockErr: conditionalCheckFailure,
+		wantErr: errors.NewClient("Session 'sessionID' not found"),
+	},
+	{
+		name: "SuccessfulInvocation",
+	},
+}
+
+func TestTouchSession(t *testing.T) {
+	for _, test := range touchSessionTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			updateSvc = updateItemMock(&dynamodb.UpdateItemInput{
+				ConditionExpression: aws.String("attribute_exists(SessionId)"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":seen": {S: aws.String("2020-05-01T12:30:00Z")},
+				},
+				Key:              map[string]*dynamodb.AttributeValue{"SessionId": {S: aws.String("sessionID")}},
+				TableName:        aws.String(os.Getenv("SESSION_TABLE_NAME")),
+				UpdateExpression: aws.String("SET LastSeen = :seen"),
+			}, nil, test.mockErr)
+			defer func() {
+				updateSvc = defaultSvc
+			}()
+
+			// Execute
+			err := Dynamo.TouchSession("sessionID", sessionTime)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- ListSessions Tests ------------------
+
+var sessionLastKey = map[string]*dynamodb.AttributeValue{"SessionId": {S: aws.String("session2")}}
+
+var listSessionsTests = []struct {
+	name string
+
+	// Mock data
+	mockInputs  []*dynamodb.QueryInput
+	mockOutputs []*dynamodb.QueryOutput
+	mockErr     error
+
+	// Expected output
+	wantSessions []*Session
+	wantErr      error
+}{
+	{
+		name:       "ServiceError",
+		mockInputs: []*dynamodb.QueryInput{sessionQueryInput(nil)},
+		mockErr:    errors.NewServer("DynamoDB failure"),
+		wantErr:    errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB Query call"),
+	},
+	{
+		name:         "NoSessions",
+		mockInputs:   []*dynamodb.QueryInput{sessionQueryInput(nil)},
+		mockOutputs:  []*dynamodb.QueryOutput{{}},
+		wantSessions: []*Session{},
+	},
+	{
+		name:       "MultiplePages",
+		mockInputs: []*dynamodb.QueryInput{sessionQueryInput(nil), sessionQueryInput(sessionLastKey)},
+		mockOutputs: []*dynamodb.QueryOutput{
+			{Items: []map[string]*dynamodb.AttributeValue{sessionItem("session1", activeTime), sessionItem("session2", expiredTime)}, LastEvaluatedKey: sessionLastKey},
+			{Items: []map[string]*dynamodb.AttributeValue{sessionItem("session3", activeTime)}},
+		},
+		wantSessions: []*Session{testSession("session1", activeTime), testSession("session3", activeTime)},
+	},
+}
+
+func TestListSessions(t *testing.T) {
+	for _, test := range listSessionsTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			querySvc = queryMock(test.mockInputs, test.mockOutputs, test.mockErr)
+			now = func() time.Time { return sessionTime }
+			defer func() {
+				querySvc = defaultSvc
+				now = time.Now
+			}()
+
+			// Execute
+			sessions, err := Dynamo.ListSessions("test@example.com")
+
+			// Verify
+			if !reflect.DeepEqual(sessions, test.wantSessions) {
+				t.Errorf("Got sessions %v; want %v", sessions, test.wantSessions)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- DeleteSession Tests ------------------
+
+var deleteSessionTests = []struct {
+	name    string
+	mockErr error
+	wantErr error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB DeleteItem call"),
+	},
+	{
+		name:    "NotFound",
+		mockErr: conditionalCheckFailure,
+		wantErr: errors.NewClient("Session 'sessionID' not found"),
+	},
+	{
+		name: "SuccessfulInvocation",
+	},
+}
+
+func TestDeleteSession(t *testing.T) {
+	for _, test := range deleteSessionTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			deleteSvc = deleteItemMock(&dynamodb.DeleteItemInput{
+				ConditionExpression: aws.String("Email = :email"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":email": {S: aws.String("test@example.com")},
+				},
+				Key:       map[string]*dynamodb.AttributeValue{"SessionId": {S: aws.String("sessionID")}},
+				TableName: aws.String(os.Getenv("SESSION_TABLE_NAME")),
+			}, test.mockErr)
+			defer func() {
+				deleteSvc = defaultSvc
+			}()
+
+			// Execute
+			err := Dynamo.DeleteSession("test@example.com", "sessionID")
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
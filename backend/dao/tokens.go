@@ -0,0 +1,141 @@
+package dao
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// tokenEmailIndex is the name of the global secondary index of the access tokens table, which is keyed by Email.
+const tokenEmailIndex = "EmailIndex"
+
+// tokenTableKey returns the primary key of the access token with the given ID.
+func tokenTableKey(tokenID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"TokenId": {S: aws.String(tokenID)},
+	}
+}
+
+// CreateAccessToken adds the given access token to the access tokens table. If a token with the same ID already
+// exists, CreateAccessToken makes no changes to the database and returns an error.
+func (dynamo) CreateAccessToken(token *AccessToken) error {
+	item, err := dynamodbattribute.MarshalMap(token)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal access token")
+	}
+
+	input := &dynamodb.PutItemInput{
+		ConditionExpression: aws.String("attribute_not_exists(TokenId)"),
+		Item:                item,
+		TableName:           aws.String(os.Getenv("TOKEN_TABLE_NAME")),
+	}
+	_, err = putSvc.PutItem(input)
+	return errors.Wrap(err, "Failed DynamoDB PutItem call")
+}
+
+// GetAccessToken returns the access token with the given ID. If the token does not exist, the returned token will
+// be nil and the returned error will be a new client error. Expired tokens may still be returned until DynamoDB
+// deletes them, so callers must check the expiry themselves.
+func (dynamo) GetAccessToken(tokenID string) (*AccessToken, error) {
+	input := &dynamodb.GetItemInput{
+		Key:       tokenTableKey(tokenID),
+		TableName: aws.String(os.Getenv("TOKEN_TABLE_NAME")),
+	}
+
+	result, err := getSvc.GetItem(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed DynamoDB GetItem call")
+	}
+	if result.Item == nil {
+		return nil, errors.NewClient(fmt.Sprintf("Access token '%s' not found", tokenID))
+	}
+
+	token := AccessToken{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, &token)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal GetItem result")
+	}
+	return &token, nil
+}
+
+// TouchAccessToken sets the last-used time of the access token with the given ID. If the token does not exist, a
+// client error is returned and no changes are made.
+func (dynamo) TouchAccessToken(tokenID string, lastUsed time.Time) error {
+	item, err := dynamodbattribute.Marshal(lastUsed)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal last-used time")
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		ConditionExpression:       aws.String("attribute_exists(TokenId)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":used": item},
+		Key:                       tokenTableKey(tokenID),
+		TableName:                 aws.String(os.Getenv("TOKEN_TABLE_NAME")),
+		UpdateExpression:          aws.String("SET LastUsed = :used"),
+	}
+	_, err = updateSvc.UpdateItem(input)
+	if isConditionalCheckFailure(err) {
+		return errors.NewClient(fmt.Sprintf("Access token '%s' not found", tokenID))
+	}
+	return errors.Wrap(err, "Failed DynamoDB UpdateItem call")
+}
+
+// ListAccessTokens returns the unexpired access tokens of the user with the given email, in no particular order.
+func (dynamo) ListAccessTokens(email string) ([]*AccessToken, error) {
+	input := &dynamodb.QueryInput{
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":email": {S: aws.String(email)},
+		},
+		IndexName:              aws.String(tokenEmailIndex),
+		KeyConditionExpression: aws.String("Email = :email"),
+		TableName:              aws.String(os.Getenv("TOKEN_TABLE_NAME")),
+	}
+
+	tokens := []*AccessToken{}
+	current := now()
+	for {
+		result, err := querySvc.Query(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed DynamoDB Query call")
+		}
+
+		var page []*AccessToken
+		err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &page)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to unmarshal Query result")
+		}
+		for _, token := range page {
+			if token.Expires.After(current) {
+				tokens = append(tokens, token)
+			}
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return tokens, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// DeleteAccessToken deletes the access token with the given ID. If the token does not exist or does not belong to
+// the user with the given email, a client error is returned and no changes are made.
+func (dynamo) DeleteAccessToken(email string, tokenID string) error {
+	input := &dynamodb.DeleteItemInput{
+		ConditionExpression: aws.String("Email = :email"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":email": {S: aws.String(email)},
+		},
+		Key:       tokenTableKey(tokenID),
+		TableName: aws.String(os.Getenv("TOKEN_TABLE_NAME")),
+	}
+	_, err := deleteSvc.DeleteItem(input)
+	if isConditionalCheckFailure(err) {
+		return errors.NewClient(fmt.Sprintf("Access token '%s' not found", tokenID))
+	}
+	return errors.Wrap(err, "Failed DynamoDB DeleteItem call")
+}
@@ -0,0 +1,312 @@
+package dao
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// -------------- Helpers -----------------
+
+// testAccessToken returns an access token with the given ID that expires at the given Unix time.
+func testAccessToken(tokenID string, expires int64) *AccessToken {
+	return &AccessToken{
+		ID:      tokenID,
+		Email:   "test@example.com",
+		Name:    "CI",
+		Scopes:  []string{"codegen", "deploy"},
+		Created: sessionTime,
+		Expires: time.Unix(expires, 0),
+	}
+}
+
+// tokenItem returns the item that stores the access token with the given ID that expires at the given Unix time.
+func tokenItem(tokenID string, expires int64) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"TokenId": {S: aws.String(tokenID)},
+		"Email":   {S: aws.String("test@example.com")},
+		"Name":    {S: aws.String("CI")},
+		"Scopes":  {SS: aws.StringSlice([]string{"codegen", "deploy"})},
+		"Created": {S: aws.String("2020-05-01T12:30:00Z")},
+		"Expires": {N: aws.String(strconv.FormatInt(expires, 10))},
+	}
+}
+
+// tokenQueryInput returns the QueryInput that lists the access tokens of test@example.com.
+func tokenQueryInput(startKey map[string]*dynamodb.AttributeValue) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		ExclusiveStartKey: startKey,
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":email": {S: aws.String("test@example.com")},
+		},
+		IndexName:              aws.String("EmailIndex"),
+		KeyConditionExpression: aws.String("Email = :email"),
+		TableName:              aws.String(os.Getenv("TOKEN_TABLE_NAME")),
+	}
+}
+
+// ------------- CreateAccessToken Tests ------------------
+
+var createAccessTokenTests = []struct {
+	name    string
+	mockErr error
+	wantErr error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB PutItem call"),
+	},
+	{
+		name: "SuccessfulInvocation",
+	},
+}
+
+func TestCreateAccessToken(t *testing.T) {
+	for _, test := range createAccessTokenTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			putSvc = putItemMock(&dynamodb.PutItemInput{
+				ConditionExpression: aws.String("attribute_not_exists(TokenId)"),
+				Item:                tokenItem("tokenID", activeTime),
+				TableName:           aws.String(os.Getenv("TOKEN_TABLE_NAME")),
+			}, nil, test.mockErr)
+			defer func() {
+				putSvc = defaultSvc
+			}()
+
+			// Execute
+			err := Dynamo.CreateAccessToken(testAccessToken("tokenID", activeTime))
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- GetAccessToken Tests ------------------
+
+var getAccessTokenTests = []struct {
+	name string
+
+	// Mock data
+	mockOutput *dynamodb.GetItemOutput
+	mockErr    error
+
+	// Expected output
+	wantToken *AccessToken
+	wantErr   error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB GetItem call"),
+	},
+	{
+		name:       "NotFound",
+		mockOutput: &dynamodb.GetItemOutput{},
+		wantErr:    errors.NewClient("Access token 'tokenID' not found"),
+	},
+	{
+		name:       "SuccessfulInvocation",
+		mockOutput: &dynamodb.GetItemOutput{Item: tokenItem("tokenID", activeTime)},
+		wantToken:  testAccessToken("tokenID", activeTime),
+	},
+}
+
+func TestGetAccessToken(t *testing.T) {
+	for _, test := range getAccessTokenTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			getSvc = getItemMock(&dynamodb.GetItemInput{
+				Key:       map[string]*dynamodb.AttributeValue{"TokenId": {S: aws.String("tokenID")}},
+				TableName: aws.String(os.Getenv("TOKEN_TABLE_NAME")),
+			}, test.mockOutput, test.mockErr)
+			defer func() {
+				getSvc = defaultSvc
+			}()
+
+			// Execute
+			token, err := Dynamo.GetAccessToken("tokenID")
+
+			// Verify
+			if !reflect.DeepEqual(token, test.wantToken) {
+				t.Errorf("Got token %+v; want %+v", token, test.wantToken)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- TouchAccessToken Tests ------------------
+
+var touchAccessTokenTests = []struct {
+	name    string
+	mockErr error
+	wantErr error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB UpdateItem call"),
+	},
+	{
+		name:    "NotFound",
+		mockErr: conditionalCheckFailure,
+		wantErr: errors.NewClient("Access token 'tokenID' not found"),
+	},
+	{
+		name: "SuccessfulInvocation",
+	},
+}
+
+func TestTouchAccessToken(t *testing.T) {
+	for _, test := range touchAccessTokenTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			updateSvc = updateItemMock(&dynamodb.UpdateItemInput{
+				ConditionExpression: aws.String("attribute_exists(TokenId)"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":used": {S: aws.String("2020-05-01T12:30:00Z")},
+				},
+				Key:              map[string]*dynamodb.AttributeValue{"TokenId": {S: aws.String("tokenID")}},
+				TableName:        aws.String(os.Getenv("TOKEN_TABLE_NAME")),
+				UpdateExpression: aws.String("SET LastUsed = :used"),
+			}, nil, test.mockErr)
+			defer func() {
+				updateSvc = defaultSvc
+			}()
+
+			// Execute
+			err := Dynamo.TouchAccessToken("tokenID", sessionTime)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- ListAccessTokens Tests ------------------
+
+var tokenLastKey = map[string]*dynamodb.AttributeValue{"TokenId": {S: aws.String("token2")}}
+
+var listAccessTokensTests = []struct {
+	name string
+
+	// Mock data
+	mockInputs  []*dynamodb.QueryInput
+	mockOutputs []*dynamodb.QueryOutput
+	mockErr     error
+
+	// Expected output
+	wantTokens []*AccessToken
+	wantErr    error
+}{
+	{
+		name:       "ServiceError",
+		mockInputs: []*dynamodb.QueryInput{tokenQueryInput(nil)},
+		mockErr:    errors.NewServer("DynamoDB failure"),
+		wantErr:    errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB Query call"),
+	},
+	{
+		name:        "NoTokens",
+		mockInputs:  []*dynamodb.QueryInput{tokenQueryInput(nil)},
+		mockOutputs: []*dynamodb.QueryOutput{{}},
+		wantTokens:  []*AccessToken{},
+	},
+	{
+		name:       "MultiplePages",
+		mockInputs: []*dynamodb.QueryInput{tokenQueryInput(nil), tokenQueryInput(tokenLastKey)},
+		mockOutputs: []*dynamodb.QueryOutput{
+			{Items: []map[string]*dynamodb.AttributeValue{tokenItem("token1", activeTime), tokenItem("token2", expiredTime)}, LastEvaluatedKey: tokenLastKey},
+			{Items: []map[string]*dynamodb.AttributeValue{tokenItem("token3", activeTime)}},
+		},
+		wantTokens: []*AccessToken{testAccessToken("token1", activeTime), testAccessToken("token3", activeTime)},
+	},
+}
+
+func TestListAccessTokens(t *testing.T) {
+	for _, test := range listAccessTokensTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			querySvc = queryMock(test.mockInputs, test.mockOutputs, test.mockErr)
+			now = func() time.Time { return sessionTime }
+			defer func() {
+				querySvc = defaultSvc
+				now = time.Now
+			}()
+
+			// Execute
+			tokens, err := Dynamo.ListAccessTokens("test@example.com")
+
+			// Verify
+			if !reflect.DeepEqual(tokens, test.wantTokens) {
+				t.Errorf("Got tokens %v; want %v", tokens, test.wantTokens)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- DeleteAccessToken Tests ------------------
+
+var deleteAccessTokenTests = []struct {
+	name    string
+	mockErr error
+	wantErr error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB DeleteItem call"),
+	},
+	{
+		name:    "NotFound",
+		mockErr: conditionalCheckFailure,
+		wantErr: errors.NewClient("Access token 'tokenID' not found"),
+	},
+	{
+		name: "SuccessfulInvocation",
+	},
+}
+
+func TestDeleteAccessToken(t *testing.T) {
+	for _, test := range deleteAccessTokenTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			deleteSvc = deleteItemMock(&dynamodb.DeleteItemInput{
+				ConditionExpression: aws.String("Email = :email"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":email": {S: aws.String("test@example.com")},
+				},
+				Key:       map[string]*dynamodb.AttributeValue{"TokenId": {S: aws.String("tokenID")}},
+				TableName: aws.String(os.Getenv("TOKEN_TABLE_NAME")),
+			}, test.mockErr)
+			defer func() {
+				deleteSvc = defaultSvc
+			}()
+
+			// Execute
+			err := Dynamo.DeleteAccessToken("test@example.com", "tokenID")
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"fmt"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// The attributes of a user that store the nonce of their newest email verification and password reset tokens.
+const (
+	verifyNonceAttribute = "VerifyNonce"
+	resetNonceAttribute  = "ResetNonce"
+)
+
+// setNonce stores the given nonce in the given attribute of the user with the given email, replacing any previous
+// nonce. If the user does not exist, a client error is returned and no changes are made.
+func (dynamo) setNonce(email string, attribute string, nonce string) error {
+	condition := "attribute_exists(Email)"
+	expression := fmt.Sprintf("SET %s = :nonce", attribute)
+	items := map[string]interface{}{
+		":nonce": nonce,
+	}
+	err := Dynamo.updateUserIf(email, condition, expression, nil, items)
+	if isConditionalCheckFailure(err) {
+		return errors.NewClient(fmt.Sprintf("Email '%s' not found", email))
+	}
+	return err
+}
+
+// SetVerificationNonce stores the nonce of the newest email verification token of the user with the given email.
+// Tokens with other nonces can no longer verify the email. If the user does not exist, a client error is returned.
+func (dynamo) SetVerificationNonce(email string, nonce string) error {
+	return Dynamo.setNonce(email, verifyNonceAttribute, nonce)
+}
+
+// SetPasswordResetNonce stores the nonce of the newest password reset token of the user with the given email.
+// Tokens with other nonces can no longer reset the password. If the user does not exist, a client error is returned.
+func (dynamo) SetPasswordResetNonce(email string, nonce string) error {
+	return Dynamo.setNonce(email, resetNonceAttribute, nonce)
+}
+
+// VerifyEmail marks the email of the given user as verified and removes their verification nonce, but only if the
+// nonce matches the given nonce, so that each verification token can be used once. If the nonce does not match,
+// a client error is returned and no changes are made.
+func (dynamo) VerifyEmail(email string, nonce string) error {
+	condition := verifyNonceAttribute + " = :nonce"
+	expression := "SET EmailVerified = :verified REMOVE " + verifyNonceAttribute
+	items := map[string]interface{}{
+		":nonce":    nonce,
+		":verified": true,
+	}
+	err := Dynamo.updateUserIf(email, condition, expression, nil, items)
+	if isConditionalCheckFailure(err) {
+		return errors.NewClient("Token is invalid or has already been used")
+	}
+	return err
+}
+
+// ResetPassword replaces the hashed password of the given user and removes their password reset nonce, but only if
+// the nonce matches the given nonce, so that each reset token can be used once. Since the reset token was received
+// by email, the email is also marked as verified. If the nonce does not match, a client error is returned and no
+// changes are made.
+func (dynamo) ResetPassword(email string, nonce string, password string) error {
+	condition := resetNonceAttribute + " = :nonce"
+	expression := "SET Password = :password, EmailVerified = :verified REMOVE " + resetNonceAttribute
+	items := map[string]interface{}{
+		":nonce":    nonce,
+		":password": password,
+		":verified": true,
+	}
+	err := Dynamo.updateUserIf(email, condition, expression, nil, items)
+	if isConditionalCheckFailure(err) {
+		return errors.NewClient("Token is invalid or has already been used")
+	}
+	return err
+}
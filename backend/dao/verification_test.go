@@ -0,0 +1,129 @@
+package dao
+
+import (
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// userUpdateInput returns the UpdateItemInput that updates test@example.com with the given condition, expression
+// and values.
+func userUpdateInput(condition string, expression string, values map[string]*dynamodb.AttributeValue) *dynamodb.UpdateItemInput {
+	return &dynamodb.UpdateItemInput{
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: values,
+		Key: map[string]*dynamodb.AttributeValue{
+			"Email": {S: aws.String("test@example.com")},
+		},
+		TableName:        aws.String(os.Getenv("TABLE_NAME")),
+		UpdateExpression: aws.String(expression),
+	}
+}
+
+var verificationTests = []struct {
+	name string
+
+	// Input
+	call func() error
+
+	// Mock data
+	mockInput *dynamodb.UpdateItemInput
+	mockErr   error
+
+	// Expected output
+	wantErr error
+}{
+	{
+		name: "SetVerificationNonce",
+		call: func() error { return Dynamo.SetVerificationNonce("test@example.com", "nonce") },
+		mockInput: userUpdateInput("attribute_exists(Email)", "SET VerifyNonce = :nonce", map[string]*dynamodb.AttributeValue{
+			":nonce": {S: aws.String("nonce")},
+		}),
+	},
+	{
+		name: "SetPasswordResetNonce",
+		call: func() error { return Dynamo.SetPasswordResetNonce("test@example.com", "nonce") },
+		mockInput: userUpdateInput("attribute_exists(Email)", "SET ResetNonce = :nonce", map[string]*dynamodb.AttributeValue{
+			":nonce": {S: aws.String("nonce")},
+		}),
+	},
+	{
+		name: "SetNonceOfNonexistentUser",
+		call: func() error { return Dynamo.SetPasswordResetNonce("test@example.com", "nonce") },
+		mockInput: userUpdateInput("attribute_exists(Email)", "SET ResetNonce = :nonce", map[string]*dynamodb.AttributeValue{
+			":nonce": {S: aws.String("nonce")},
+		}),
+		mockErr: conditionalCheckFailure,
+		wantErr: errors.NewClient("Email 'test@example.com' not found"),
+	},
+	{
+		name: "SetNonceServiceError",
+		call: func() error { return Dynamo.SetVerificationNonce("test@example.com", "nonce") },
+		mockInput: userUpdateInput("attribute_exists(Email)", "SET VerifyNonce = :nonce", map[string]*dynamodb.AttributeValue{
+			":nonce": {S: aws.String("nonce")},
+		}),
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB UpdateItem call"),
+	},
+	{
+		name: "VerifyEmail",
+		call: func() error { return Dynamo.VerifyEmail("test@example.com", "nonce") },
+		mockInput: userUpdateInput("VerifyNonce = :nonce", "SET EmailVerified = :verified REMOVE VerifyNonce", map[string]*dynamodb.AttributeValue{
+			":nonce":    {S: aws.String("nonce")},
+			":verified": {BOOL: aws.Bool(true)},
+		}),
+	},
+	{
+		name: "VerifyEmailWithUsedToken",
+		call: func() error { return Dynamo.VerifyEmail("test@example.com", "nonce") },
+		mockInput: userUpdateInput("VerifyNonce = :nonce", "SET EmailVerified = :verified REMOVE VerifyNonce", map[string]*dynamodb.AttributeValue{
+			":nonce":    {S: aws.String("nonce")},
+			":verified": {BOOL: aws.Bool(true)},
+		}),
+		mockErr: conditionalCheckFailure,
+		wantErr: errors.NewClient("Token is invalid or has already been used"),
+	},
+	{
+		name: "ResetPassword",
+		call: func() error { return Dynamo.ResetPassword("test@example.com", "nonce", "hash") },
+		mockInput: userUpdateInput("ResetNonce = :nonce", "SET Password = :password, EmailVerified = :verified REMOVE ResetNonce", map[string]*dynamodb.AttributeValue{
+			":nonce":    {S: aws.String("nonce")},
+			":password": {S: aws.String("hash")},
+			":verified": {BOOL: aws.Bool(true)},
+		}),
+	},
+	{
+		name: "ResetPasswordWithUsedToken",
+		call: func() error { return Dynamo.ResetPassword("test@example.com", "nonce", "hash") },
+		mockInput: userUpdateInput("ResetNonce = :nonce", "SET Password = :password, EmailVerified = :verified REMOVE ResetNonce", map[string]*dynamodb.AttributeValue{
+			":nonce":    {S: aws.String("nonce")},
+			":password": {S: aws.String("hash")},
+			":verified": {BOOL: aws.Bool(true)},
+		}),
+		mockErr: conditionalCheckFailure,
+		wantErr: errors.NewClient("Token is invalid or has already been used"),
+	},
+}
+
+func TestVerification(t *testing.T) {
+	for _, test := range verificationTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			updateSvc = updateItemMock(test.mockInput, nil, test.mockErr)
+			defer func() {
+				updateSvc = defaultSvc
+			}()
+
+			// Execute
+			err := test.call()
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
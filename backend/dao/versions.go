@@ -0,0 +1,328 @@
+package dao
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// querier wraps the Query method in order to perform dependency injection in the dynamo tests.
+type querier interface {
+	Query(*dynamodb.QueryInput) (*dynamodb.QueryOutput, error)
+}
+
+// batchWriter wraps the BatchWriteItem method in order to perform dependency injection in the dynamo tests.
+type batchWriter interface {
+	BatchWriteItem(*dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+// These variables should only be changed inside a test.
+var querySvc querier = defaultSvc
+var batchWriteSvc batchWriter = defaultSvc
+var now = time.Now
+var sleep = time.Sleep
+
+// maxBatchWrite is the maximum number of requests DynamoDB accepts in a single BatchWriteItem call.
+const maxBatchWrite = 25
+
+// maxBatchRetries is the number of times in a row that unprocessed BatchWriteItem requests are retried before
+// giving up, and batchRetryDelay is the delay before the first retry.
+const maxBatchRetries = 8
+const batchRetryDelay = 50 * time.Millisecond
+
+// versionKey returns the partition key under which the versions of the given user's project are stored.
+func versionKey(email string, projectID string) string {
+	return email + "/" + projectID
+}
+
+// versionTableKey returns the primary key of the given version of the given user's project.
+func versionTableKey(email string, projectID string, version int) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"ProjectKey": {S: aws.String(versionKey(email, projectID))},
+		"Version":    {N: aws.String(strconv.Itoa(version))},
+	}
+}
+
+// anyVersion is passed to mutateProject by callers that did not read the project before changing it, so that
+// whichever version of the project is current is changed.
+const anyVersion = -1
+
+// projectChanged returns the error returned when a project changes while it is being updated. The update can be
+// retried.
+func projectChanged(projectID string) error {
+	return errors.NewClient(fmt.Sprintf("Project '%s' was changed by another request. Please try again", projectID))
+}
+
+// getProjectForUpdate returns the project with the given ID, like GetProject, but with a strongly consistent
+// read, so that the returned version of the project is the current one.
+func (dynamo) getProjectForUpdate(email string, projectID string) (*Project, error) {
+	user, err := Dynamo.readUser(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		ExpressionAttributeNames: map[string]*string{
+			"#pid": aws.String(projectID),
+		},
+		Key: map[string]*dynamodb.AttributeValue{
+			"Email": {
+				S: aws.String(email),
+			},
+		},
+		ProjectionExpression: aws.String("Projects.#pid"),
+		TableName:            aws.String(os.Getenv("TABLE_NAME")),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Failed to get user with email '%s'", email))
+	}
+
+	project := user.Projects[projectID]
+	if project == nil {
+		return nil, errors.NewClient(fmt.Sprintf("Project '%s' not found", projectID))
+	}
+	return project, nil
+}
+
+// mutateProject applies mutate to the project with the given ID, increments the version of the project and records
+// a snapshot of the updated project authored by the given email. If mutate reports that it did not change the
+// project, no changes are made to the database and the project is returned as it was read, so that a request that
+// changes nothing never records an empty version. If version is not anyVersion, the project must
+// still be at that version. The project and its snapshot are written in a single transaction that only succeeds if
+// the project is still at the version that was read, so a project never changes without recording its version and a
+// concurrent change is never overwritten; if the project has changed, a client error asking the user to try again is
+// returned. If the user does not have the project, a client error is returned and no changes are made. The updated
+// project is returned.
+func (dynamo) mutateProject(email string, projectID string, version int, mutate func(*Project) bool) (*Project, error) {
+	project, err := Dynamo.getProjectForUpdate(email, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if version != anyVersion && project.Version != version {
+		return nil, projectChanged(projectID)
+	}
+
+	// Projects created before versions were recorded do not have a version yet.
+	condition := "attribute_exists(Projects.#pid) AND attribute_not_exists(Projects.#pid.Version)"
+	items := map[string]interface{}{}
+	if project.Version > 0 {
+		condition = "Projects.#pid.Version = :expected"
+		items[":expected"] = project.Version
+	}
+
+	if !mutate(project) {
+		return project, nil
+	}
+	project.Version++
+	initCollections(project)
+
+	expression := "SET Projects.#pid.Version = :version, Projects.#pid.#name = :name, Projects.#pid.Description = :desc, " +
+		"Projects.#pid.Objects = :objs, Projects.#pid.Endpoints = :endps"
+	attributeNames := map[string]*string{
+		"#pid":  aws.String(projectID),
+		"#name": aws.String("Name"),
+	}
+	items[":version"] = project.Version
+	items[":name"] = project.Name
+	items[":desc"] = project.Description
+	items[":objs"] = project.Objects
+	items[":endps"] = project.Endpoints
+
+	update, err := updateUserWrite(email, condition, expression, attributeNames, items)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := versionWrite(email, project)
+	if err != nil {
+		return nil, err
+	}
+
+	err = transactWrite(update, snapshot)
+	if isTransactionConflict(err) {
+		return nil, projectChanged(projectID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// initCollections replaces the missing objects and endpoints of the given project with empty maps, so that the
+// stored project always has both maps and a snapshot restores them exactly.
+func initCollections(project *Project) {
+	if project.Objects == nil {
+		project.Objects = map[string]*Object{}
+	}
+	if project.Endpoints == nil {
+		project.Endpoints = map[string]*Endpoint{}
+	}
+}
+
+// versionWrite returns the write of a transaction that records a snapshot of the given project, at its current
+// version, authored by the given email. Versions are immutable, so the write fails if the version already exists.
+func versionWrite(email string, project *Project) (*dynamodb.TransactWriteItem, error) {
+	item, err := encoder.Encode(&ProjectVersion{
+		ProjectKey: versionKey(email, project.ID),
+		Version:    project.Version,
+		Author:     email,
+		Timestamp:  now().UTC(),
+		Project:    project,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to marshal version")
+	}
+
+	return &dynamodb.TransactWriteItem{
+		Put: &dynamodb.Put{
+			ConditionExpression: aws.String("attribute_not_exists(ProjectKey)"),
+			Item:                item.M,
+			TableName:           aws.String(os.Getenv("VERSION_TABLE_NAME")),
+		},
+	}, nil
+}
+
+// GetVersion returns the given version of the project with the given ID. If the version does not exist, the
+// returned version will be nil and the returned error will be a new client error.
+func (dynamo) GetVersion(email string, projectID string, version int) (*ProjectVersion, error) {
+	input := &dynamodb.GetItemInput{
+		Key:       versionTableKey(email, projectID, version),
+		TableName: aws.String(os.Getenv("VERSION_TABLE_NAME")),
+	}
+
+	result, err := getSvc.GetItem(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed DynamoDB GetItem call")
+	}
+	if result.Item == nil {
+		return nil, errors.NewClient(fmt.Sprintf("Version %d of project '%s' not found", version, projectID))
+	}
+
+	projectVersion := ProjectVersion{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, &projectVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal GetItem result")
+	}
+	return &projectVersion, nil
+}
+
+// queryVersions calls the given function with every page of versions of the given project. Each page contains
+// only the attributes given in the projection expression.
+func queryVersions(email string, projectID string, projection string, attributeNames map[string]*string, f func([]map[string]*dynamodb.AttributeValue) error) error {
+	input := &dynamodb.QueryInput{
+		ExpressionAttributeNames: attributeNames,
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":key": {S: aws.String(versionKey(email, projectID))},
+		},
+		KeyConditionExpression: aws.String("ProjectKey = :key"),
+		ProjectionExpression:   aws.String(projection),
+		ScanIndexForward:       aws.Bool(false),
+		TableName:              aws.String(os.Getenv("VERSION_TABLE_NAME")),
+	}
+
+	for {
+		result, err := querySvc.Query(input)
+		if err != nil {
+			return errors.Wrap(err, "Failed DynamoDB Query call")
+		}
+		err = f(result.Items)
+		if err != nil {
+			return err
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// ListVersions returns the versions of the project with the given ID, newest first. The returned versions do
+// not include the project snapshots; use GetVersion to fetch them.
+func (dynamo) ListVersions(email string, projectID string) ([]*ProjectVersion, error) {
+	versions := []*ProjectVersion{}
+	attributeNames := map[string]*string{
+		"#ts": aws.String("Timestamp"),
+	}
+	err := queryVersions(email, projectID, "Version, Author, #ts", attributeNames, func(items []map[string]*dynamodb.AttributeValue) error {
+		var page []*ProjectVersion
+		err := dynamodbattribute.UnmarshalListOfMaps(items, &page)
+		if err != nil {
+			return errors.Wrap(err, "Failed to unmarshal Query result")
+		}
+		versions = append(versions, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// RestoreVersion replaces the name, description, objects and endpoints of the project with the given ID with
+// those of the given version. Deployment information is not changed. Restoring records a new version, so the
+// history of the project is preserved and the restore itself can be undone. The updated project is returned.
+func (dynamo) RestoreVersion(email string, projectID string, version int) (*Project, error) {
+	projectVersion, err := Dynamo.GetVersion(email, projectID, version)
+	if err != nil {
+		return nil, err
+	}
+	if projectVersion.Project == nil {
+		return nil, errors.NewServer(fmt.Sprintf("Version %d of project '%s' has no snapshot", version, projectID))
+	}
+
+	snapshot := projectVersion.Project
+	return Dynamo.mutateProject(email, projectID, anyVersion, func(project *Project) bool {
+		project.Name = snapshot.Name
+		project.Description = snapshot.Description
+		project.Objects = snapshot.Objects
+		project.Endpoints = snapshot.Endpoints
+		return true
+	})
+}
+
+// deleteVersions deletes every version of the project with the given ID. Requests that DynamoDB leaves unprocessed
+// are retried after a delay that doubles with every attempt, up to maxBatchRetries times in a row.
+func (dynamo) deleteVersions(email string, projectID string) error {
+	var requests []*dynamodb.WriteRequest
+	err := queryVersions(email, projectID, "ProjectKey, Version", nil, func(items []map[string]*dynamodb.AttributeValue) error {
+		for _, item := range items {
+			requests = append(requests, &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: item}})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	table := os.Getenv("VERSION_TABLE_NAME")
+	retries := 0
+	for len(requests) > 0 {
+		count := len(requests)
+		if count > maxBatchWrite {
+			count = maxBatchWrite
+		}
+		input := &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]*dynamodb.WriteRequest{table: requests[:count]},
+		}
+
+		result, err := batchWriteSvc.BatchWriteItem(input)
+		if err != nil {
+			return errors.Wrap(err, "Failed DynamoDB BatchWriteItem call")
+		}
+
+		// Unprocessed requests are retried with the next batch, once DynamoDB has had time to recover.
+		unprocessed := result.UnprocessedItems[table]
+		if len(unprocessed) == 0 {
+			retries = 0
+		} else {
+			if retries == maxBatchRetries {
+				return errors.NewServer(fmt.Sprintf("%d version deletions were still unprocessed after %d retries", len(unprocessed)+len(requests)-count, retries))
+			}
+			sleep(batchRetryDelay << uint(retries))
+			retries++
+		}
+		requests = append(unprocessed, requests[count:]...)
+	}
+	return nil
+}
@@ -0,0 +1,735 @@
+package dao
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// -------------- Query Mock -----------------
+
+type queryFunc func(*dynamodb.QueryInput) (*dynamodb.QueryOutput, error)
+
+func (f queryFunc) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	return f(input)
+}
+
+// queryMock returns the given outputs in order, as long as it is called with the corresponding inputs.
+func queryMock(mockInputs []*dynamodb.QueryInput, mockOutputs []*dynamodb.QueryOutput, mockErr error) queryFunc {
+	call := 0
+	return func(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		if call >= len(mockInputs) || !reflect.DeepEqual(input, mockInputs[call]) {
+			return nil, errors.NewServer("Incorrect QueryInput to mock")
+		}
+		call++
+		if mockErr != nil {
+			return nil, mockErr
+		}
+		return mockOutputs[call-1], nil
+	}
+}
+
+// -------------- BatchWriteItem Mock -----------------
+
+type batchWriteItemFunc func(*dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error)
+
+func (f batchWriteItemFunc) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	return f(input)
+}
+
+// batchWriteItemMock returns the given outputs in order, as long as it is called with the corresponding inputs.
+func batchWriteItemMock(mockInputs []*dynamodb.BatchWriteItemInput, mockOutputs []*dynamodb.BatchWriteItemOutput, mockErr error) batchWriteItemFunc {
+	call := 0
+	return func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		if call >= len(mockInputs) || !reflect.DeepEqual(input, mockInputs[call]) {
+			return nil, errors.NewServer("Incorrect BatchWriteItemInput to mock")
+		}
+		call++
+		if mockErr != nil {
+			return nil, mockErr
+		}
+		return mockOutputs[call-1], nil
+	}
+}
+
+// -------------- TransactWriteItems Mock -----------------
+
+type transactWriteItemsFunc func(*dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error)
+
+func (f transactWriteItemsFunc) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	return f(input)
+}
+
+func transactWriteItemsMock(mockInput *dynamodb.TransactWriteItemsInput, mockErr error) transactWriteItemsFunc {
+	return func(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+		if reflect.DeepEqual(input, mockInput) {
+			return &dynamodb.TransactWriteItemsOutput{}, mockErr
+		}
+		fmt.Println("Actual input:", input)
+		fmt.Println("Expected input:", mockInput)
+		return nil, errors.NewServer("Incorrect TransactWriteItemsInput to mock")
+	}
+}
+
+// canceledErr returns the error of a transaction whose writes were canceled for the given reasons.
+func canceledErr(codes ...string) error {
+	reasons := make([]*dynamodb.CancellationReason, len(codes))
+	for i, code := range codes {
+		reasons[i] = &dynamodb.CancellationReason{Code: aws.String(code)}
+	}
+	return &dynamodb.TransactionCanceledException{CancellationReasons: reasons}
+}
+
+// -------------- Helpers -----------------
+
+var versionTime = time.Date(2020, time.May, 1, 12, 30, 0, 0, time.UTC)
+
+// versionedProject returns a project with a single object at the given version.
+func versionedProject(projectID string, version int) *Project {
+	return &Project{
+		ID:          projectID,
+		Name:        "Project Name",
+		Description: "Project description",
+		Version:     version,
+		Objects: map[string]*Object{
+			"book": {ID: "book", Name: "Book", CodeName: "Book", Attributes: []*Attribute{{Name: "title", CodeName: "title", Type: "Text"}}},
+		},
+		Endpoints: map[string]*Endpoint{},
+	}
+}
+
+// projectReadInput returns the GetItemInput with which mutateProject reads the given project.
+func projectReadInput(email string, projectID string) *dynamodb.GetItemInput {
+	return &dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		ExpressionAttributeNames: map[string]*string{
+			"#pid": aws.String(projectID),
+		},
+		Key: map[string]*dynamodb.AttributeValue{
+			"Email": {
+				S: aws.String(email),
+			},
+		},
+		ProjectionExpression: aws.String("Projects.#pid"),
+		TableName:            aws.String(os.Getenv("TABLE_NAME")),
+	}
+}
+
+// projectReadOutput returns the GetItemOutput of a user who has the given project.
+func projectReadOutput(email string, project *Project) *dynamodb.GetItemOutput {
+	item, _ := encoder.Encode(&User{Email: email, Projects: map[string]*Project{project.ID: project}})
+	return &dynamodb.GetItemOutput{Item: item.M}
+}
+
+// mutateTransaction returns the transaction with which mutateProject changes a project at version from into the
+// given project.
+func mutateTransaction(email string, from int, project *Project) *dynamodb.TransactWriteItemsInput {
+	condition := "attribute_exists(Projects.#pid) AND attribute_not_exists(Projects.#pid.Version)"
+	values, _ := encodeValues(map[string]interface{}{
+		":version": project.Version,
+		":name":    project.Name,
+		":desc":    project.Description,
+		":objs":    project.Objects,
+		":endps":   project.Endpoints,
+	})
+	if from > 0 {
+		condition = "Projects.#pid.Version = :expected"
+		values[":expected"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(from))}
+	}
+
+	return &dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Update: &dynamodb.Update{
+					ConditionExpression: aws.String(condition),
+					ExpressionAttributeNames: map[string]*string{
+						"#pid":  aws.String(project.ID),
+						"#name": aws.String("Name"),
+					},
+					ExpressionAttributeValues: values,
+					Key: map[string]*dynamodb.AttributeValue{
+						"Email": {
+							S: aws.String(email),
+						},
+					},
+					TableName: aws.String(os.Getenv("TABLE_NAME")),
+					UpdateExpression: aws.String("SET Projects.#pid.Version = :version, Projects.#pid.#name = :name, Projects.#pid.Description = :desc, " +
+						"Projects.#pid.Objects = :objs, Projects.#pid.Endpoints = :endps"),
+				},
+			},
+			versionPut(email, project),
+		},
+	}
+}
+
+// versionItem returns the item that stores the snapshot of the given project.
+func versionItem(email string, project *Project) map[string]*dynamodb.AttributeValue {
+	item, _ := encoder.Encode(&ProjectVersion{
+		ProjectKey: email + "/" + project.ID,
+		Version:    project.Version,
+		Author:     email,
+		Timestamp:  versionTime,
+		Project:    project,
+	})
+	return item.M
+}
+
+// versionPut returns the write of a transaction that records the snapshot of the given project.
+func versionPut(email string, project *Project) *dynamodb.TransactWriteItem {
+	return &dynamodb.TransactWriteItem{
+		Put: &dynamodb.Put{
+			ConditionExpression: aws.String("attribute_not_exists(ProjectKey)"),
+			Item:                versionItem(email, project),
+			TableName:           aws.String(os.Getenv("VERSION_TABLE_NAME")),
+		},
+	}
+}
+
+// getVersionInput returns the GetItemInput that fetches the given version.
+func getVersionInput(email string, projectID string, version int) *dynamodb.GetItemInput {
+	return &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"ProjectKey": {S: aws.String(email + "/" + projectID)},
+			"Version":    {N: aws.String(strconv.Itoa(version))},
+		},
+		TableName: aws.String(os.Getenv("VERSION_TABLE_NAME")),
+	}
+}
+
+// versionQueryInput returns the QueryInput that lists the versions of the given project.
+func versionQueryInput(email string, projectID string, projection string, attributeNames map[string]*string, startKey map[string]*dynamodb.AttributeValue) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		ExclusiveStartKey:        startKey,
+		ExpressionAttributeNames: attributeNames,
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":key": {S: aws.String(email + "/" + projectID)},
+		},
+		KeyConditionExpression: aws.String("ProjectKey = :key"),
+		ProjectionExpression:   aws.String(projection),
+		ScanIndexForward:       aws.Bool(false),
+		TableName:              aws.String(os.Getenv("VERSION_TABLE_NAME")),
+	}
+}
+
+// versionKeysQueryInput returns the QueryInput that lists the keys of the versions of the given project.
+func versionKeysQueryInput(email string, projectID string) *dynamodb.QueryInput {
+	return versionQueryInput(email, projectID, "ProjectKey, Version", nil, nil)
+}
+
+// summaryItem returns the item of a version as returned by the ListVersions query.
+func summaryItem(version int) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"Version":   {N: aws.String(strconv.Itoa(version))},
+		"Author":    {S: aws.String("test@example.com")},
+		"Timestamp": {S: aws.String("2020-05-01T12:30:00Z")},
+	}
+}
+
+// ------------- GetVersion Tests ------------------
+
+// snapshot returns version 2 of versionedProject with an endpoint, so that it is decoded exactly as it was stored.
+func snapshot() *Project {
+	project := versionedProject("projectID", 2)
+	project.Endpoints["listBooks"] = endpoint
+	return project
+}
+
+var getVersionTests = []struct {
+	name string
+
+	// Mock data
+	mockOutput *dynamodb.GetItemOutput
+	mockErr    error
+
+	// Expected output
+	wantVersion *ProjectVersion
+	wantErr     error
+}{
+	{
+		name:    "ServiceError",
+		mockErr: errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB GetItem call"),
+	},
+	{
+		name:       "NotFound",
+		mockOutput: &dynamodb.GetItemOutput{},
+		wantErr:    errors.NewClient("Version 2 of project 'projectID' not found"),
+	},
+	{
+		name:       "SuccessfulInvocation",
+		mockOutput: &dynamodb.GetItemOutput{Item: versionItem("test@example.com", snapshot())},
+		wantVersion: &ProjectVersion{
+			ProjectKey: "test@example.com/projectID",
+			Version:    2,
+			Author:     "test@example.com",
+			Timestamp:  versionTime,
+			Project:    snapshot(),
+		},
+	},
+}
+
+func TestGetVersion(t *testing.T) {
+	for _, test := range getVersionTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			getSvc = getItemMock(getVersionInput("test@example.com", "projectID", 2), test.mockOutput, test.mockErr)
+			defer func() {
+				getSvc = defaultSvc
+			}()
+
+			// Execute
+			version, err := Dynamo.GetVersion("test@example.com", "projectID", 2)
+
+			// Verify
+			if !reflect.DeepEqual(version, test.wantVersion) {
+				t.Errorf("Got version %+v; want %+v", version, test.wantVersion)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- ListVersions Tests ------------------
+
+var listNames = map[string]*string{"#ts": aws.String("Timestamp")}
+var lastKey = map[string]*dynamodb.AttributeValue{"Version": {N: aws.String("2")}}
+
+var listVersionsTests = []struct {
+	name string
+
+	// Mock data
+	mockInputs  []*dynamodb.QueryInput
+	mockOutputs []*dynamodb.QueryOutput
+	mockErr     error
+
+	// Expected output
+	wantVersions []*ProjectVersion
+	wantErr      error
+}{
+	{
+		name:       "ServiceError",
+		mockInputs: []*dynamodb.QueryInput{versionQueryInput("test@example.com", "projectID", "Version, Author, #ts", listNames, nil)},
+		mockErr:    errors.NewServer("DynamoDB failure"),
+		wantErr:    errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB Query call"),
+	},
+	{
+		name:         "NoVersions",
+		mockInputs:   []*dynamodb.QueryInput{versionQueryInput("test@example.com", "projectID", "Version, Author, #ts", listNames, nil)},
+		mockOutputs:  []*dynamodb.QueryOutput{{}},
+		wantVersions: []*ProjectVersion{},
+	},
+	{
+		name: "MultiplePages",
+		mockInputs: []*dynamodb.QueryInput{
+			versionQueryInput("test@example.com", "projectID", "Version, Author, #ts", listNames, nil),
+			versionQueryInput("test@example.com", "projectID", "Version, Author, #ts", listNames, lastKey),
+		},
+		mockOutputs: []*dynamodb.QueryOutput{
+			{Items: []map[string]*dynamodb.AttributeValue{summaryItem(3), summaryItem(2)}, LastEvaluatedKey: lastKey},
+			{Items: []map[string]*dynamodb.AttributeValue{summaryItem(1)}},
+		},
+		wantVersions: []*ProjectVersion{
+			{Version: 3, Author: "test@example.com", Timestamp: versionTime},
+			{Version: 2, Author: "test@example.com", Timestamp: versionTime},
+			{Version: 1, Author: "test@example.com", Timestamp: versionTime},
+		},
+	},
+}
+
+func TestListVersions(t *testing.T) {
+	for _, test := range listVersionsTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			querySvc = queryMock(test.mockInputs, test.mockOutputs, test.mockErr)
+			defer func() {
+				querySvc = defaultSvc
+			}()
+
+			// Execute
+			versions, err := Dynamo.ListVersions("test@example.com", "projectID")
+
+			// Verify
+			if !reflect.DeepEqual(versions, test.wantVersions) {
+				t.Errorf("Got versions %v; want %v", versions, test.wantVersions)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- mutateProject Tests ------------------
+
+// renameProject is the mutation used by the mutateProject tests.
+func renameProject(project *Project) bool {
+	project.Name = "New Name"
+	return true
+}
+
+// keepProject is a mutation that does not change the project.
+func keepProject(project *Project) bool {
+	return false
+}
+
+// readProject returns versionedProject as it is decoded after being read from the database, which does not
+// distinguish an empty map from a missing one.
+func readProject(version int) *Project {
+	project := versionedProject("projectID", version)
+	project.Endpoints = nil
+	return project
+}
+
+// renamedProject returns the project returned by versionedProject after renameProject is applied to it.
+func renamedProject(version int) *Project {
+	project := versionedProject("projectID", version)
+	project.Name = "New Name"
+	return project
+}
+
+var mutateProjectTests = []struct {
+	name    string
+	version int
+	mutate  func(*Project) bool
+
+	// Mock data
+	getOutput   *dynamodb.GetItemOutput
+	getErr      error
+	mockInput   *dynamodb.TransactWriteItemsInput
+	transactErr error
+
+	// Expected output
+	wantProject *Project
+	wantErr     error
+}{
+	{
+		name:    "GetError",
+		version: anyVersion,
+		getErr:  errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB GetItem call"), "Failed to get user with email 'test@example.com'"),
+	},
+	{
+		name:      "ProjectNotFound",
+		version:   anyVersion,
+		getOutput: &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{"Email": {S: aws.String("test@example.com")}}},
+		wantErr:   errors.NewClient("Project 'projectID' not found"),
+	},
+	{
+		name:      "StaleVersion",
+		version:   2,
+		getOutput: projectReadOutput("test@example.com", versionedProject("projectID", 3)),
+		wantErr:   errors.NewClient("Project 'projectID' was changed by another request. Please try again"),
+	},
+	{
+		name:        "ConcurrentChange",
+		version:     anyVersion,
+		getOutput:   projectReadOutput("test@example.com", versionedProject("projectID", 3)),
+		mockInput:   mutateTransaction("test@example.com", 3, renamedProject(4)),
+		transactErr: canceledErr(conditionalCheckFailed, "None"),
+		wantErr:     errors.NewClient("Project 'projectID' was changed by another request. Please try again"),
+	},
+	{
+		name:        "ConcurrentTransaction",
+		version:     anyVersion,
+		getOutput:   projectReadOutput("test@example.com", versionedProject("projectID", 3)),
+		mockInput:   mutateTransaction("test@example.com", 3, renamedProject(4)),
+		transactErr: canceledErr(transactionConflict, "None"),
+		wantErr:     errors.NewClient("Project 'projectID' was changed by another request. Please try again"),
+	},
+	{
+		name:        "ServiceError",
+		version:     anyVersion,
+		getOutput:   projectReadOutput("test@example.com", versionedProject("projectID", 3)),
+		mockInput:   mutateTransaction("test@example.com", 3, renamedProject(4)),
+		transactErr: errors.NewServer("DynamoDB failure"),
+		wantErr:     errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB TransactWriteItems call"),
+	},
+	{
+		name:        "NoChange",
+		version:     3,
+		mutate:      keepProject,
+		getOutput:   projectReadOutput("test@example.com", versionedProject("projectID", 3)),
+		wantProject: readProject(3),
+	},
+	{
+		name:        "UnversionedProject",
+		version:     anyVersion,
+		getOutput:   projectReadOutput("test@example.com", versionedProject("projectID", 0)),
+		mockInput:   mutateTransaction("test@example.com", 0, renamedProject(1)),
+		wantProject: renamedProject(1),
+	},
+	{
+		name:        "ExpectedVersion",
+		version:     3,
+		getOutput:   projectReadOutput("test@example.com", versionedProject("projectID", 3)),
+		mockInput:   mutateTransaction("test@example.com", 3, renamedProject(4)),
+		wantProject: renamedProject(4),
+	},
+}
+
+func TestMutateProject(t *testing.T) {
+	for _, test := range mutateProjectTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			getSvc = getItemMock(projectReadInput("test@example.com", "projectID"), test.getOutput, test.getErr)
+			transactSvc = transactWriteItemsMock(test.mockInput, test.transactErr)
+			now = func() time.Time { return versionTime }
+			defer func() {
+				getSvc = defaultSvc
+				transactSvc = defaultSvc
+				now = time.Now
+			}()
+
+			mutate := test.mutate
+			if mutate == nil {
+				mutate = renameProject
+			}
+
+			// Execute
+			project, err := Dynamo.mutateProject("test@example.com", "projectID", test.version, mutate)
+
+			// Verify
+			if !reflect.DeepEqual(project, test.wantProject) {
+				t.Errorf("Got project %+v; want %+v", project, test.wantProject)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// ------------- RestoreVersion Tests ------------------
+
+// currentProject returns version 4 of the project, which differs from version 2 and is deployed.
+func currentProject() *Project {
+	return &Project{
+		ID:         "projectID",
+		Name:       "Renamed",
+		Version:    4,
+		Objects:    map[string]*Object{},
+		Endpoints:  map[string]*Endpoint{},
+		Deployment: Deployment{Status: DeployHealthy},
+	}
+}
+
+// restoredProject returns the project after version 2 is restored over currentProject. The deployment of the
+// project is not restored.
+func restoredProject() *Project {
+	project := versionedProject("projectID", 5)
+	project.Status = DeployHealthy
+	return project
+}
+
+var restoreVersionTests = []struct {
+	name string
+
+	// Mock data
+	versionOutput *dynamodb.GetItemOutput
+	mockInput     *dynamodb.TransactWriteItemsInput
+	transactErr   error
+
+	// Expected output
+	wantProject *Project
+	wantErr     error
+}{
+	{
+		name:          "VersionNotFound",
+		versionOutput: &dynamodb.GetItemOutput{},
+		wantErr:       errors.NewClient("Version 2 of project 'projectID' not found"),
+	},
+	{
+		name:          "TransactError",
+		versionOutput: &dynamodb.GetItemOutput{Item: versionItem("test@example.com", versionedProject("projectID", 2))},
+		mockInput:     mutateTransaction("test@example.com", 4, restoredProject()),
+		transactErr:   errors.NewServer("DynamoDB failure"),
+		wantErr:       errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed DynamoDB TransactWriteItems call"),
+	},
+	{
+		name:          "SuccessfulInvocation",
+		versionOutput: &dynamodb.GetItemOutput{Item: versionItem("test@example.com", versionedProject("projectID", 2))},
+		mockInput:     mutateTransaction("test@example.com", 4, restoredProject()),
+		wantProject:   restoredProject(),
+	},
+}
+
+func TestRestoreVersion(t *testing.T) {
+	for _, test := range restoreVersionTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			getSvc = getItemsMock(
+				[]*dynamodb.GetItemInput{getVersionInput("test@example.com", "projectID", 2), projectReadInput("test@example.com", "projectID")},
+				[]*dynamodb.GetItemOutput{test.versionOutput, projectReadOutput("test@example.com", currentProject())},
+			)
+			transactSvc = transactWriteItemsMock(test.mockInput, test.transactErr)
+			now = func() time.Time { return versionTime }
+			defer func() {
+				getSvc = defaultSvc
+				transactSvc = defaultSvc
+				now = time.Now
+			}()
+
+			// Execute
+			project, err := Dynamo.RestoreVersion("test@example.com", "projectID", 2)
+
+			// Verify
+			if !reflect.DeepEqual(project, test.wantProject) {
+				t.Errorf("Got project %+v; want %+v", project, test.wantProject)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// getItemsMock returns the output with the same index as each of the given inputs, in any order.
+func getItemsMock(mockInputs []*dynamodb.GetItemInput, mockOutputs []*dynamodb.GetItemOutput) getItemFunc {
+	return func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		for i, mockInput := range mockInputs {
+			if reflect.DeepEqual(input, mockInput) {
+				return mockOutputs[i], nil
+			}
+		}
+		return nil, errors.NewServer("Incorrect GetItemInput to mock")
+	}
+}
+
+// ------------- deleteVersions Tests ------------------
+
+func versionKeys(count int) []map[string]*dynamodb.AttributeValue {
+	keys := make([]map[string]*dynamodb.AttributeValue, count)
+	for i := range keys {
+		keys[i] = map[string]*dynamodb.AttributeValue{
+			"ProjectKey": {S: aws.String("test@example.com/projectID")},
+			"Version":    {N: aws.String(fmt.Sprint(count - i))},
+		}
+	}
+	return keys
+}
+
+func deleteRequests(keys []map[string]*dynamodb.AttributeValue) []*dynamodb.WriteRequest {
+	requests := make([]*dynamodb.WriteRequest, len(keys))
+	for i, key := range keys {
+		requests[i] = &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: key}}
+	}
+	return requests
+}
+
+func batchDeleteInput(requests []*dynamodb.WriteRequest) *dynamodb.BatchWriteItemInput {
+	return &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]*dynamodb.WriteRequest{os.Getenv("VERSION_TABLE_NAME"): requests},
+	}
+}
+
+// unprocessedOutput returns the BatchWriteItemOutput that leaves the given requests unprocessed.
+func unprocessedOutput(requests ...*dynamodb.WriteRequest) *dynamodb.BatchWriteItemOutput {
+	return &dynamodb.BatchWriteItemOutput{
+		UnprocessedItems: map[string][]*dynamodb.WriteRequest{os.Getenv("VERSION_TABLE_NAME"): requests},
+	}
+}
+
+func TestDeleteVersions(t *testing.T) {
+	keys := versionKeys(30)
+	requests := deleteRequests(keys)
+	throttled := batchDeleteInput([]*dynamodb.WriteRequest{requests[29]})
+
+	// retriedInputs returns the inputs of a deletion whose last request is retried the given number of times.
+	retriedInputs := func(count int) []*dynamodb.BatchWriteItemInput {
+		inputs := []*dynamodb.BatchWriteItemInput{batchDeleteInput(requests[:25]), batchDeleteInput(requests[25:])}
+		for i := 0; i < count; i++ {
+			inputs = append(inputs, throttled)
+		}
+		return inputs
+	}
+	// retriedOutputs returns the outputs of a deletion whose last request is left unprocessed the given number of
+	// times, and then processed if processed is true.
+	retriedOutputs := func(count int, processed bool) []*dynamodb.BatchWriteItemOutput {
+		outputs := []*dynamodb.BatchWriteItemOutput{{}}
+		for i := 0; i < count; i++ {
+			outputs = append(outputs, unprocessedOutput(requests[29]))
+		}
+		if processed {
+			outputs = append(outputs, &dynamodb.BatchWriteItemOutput{})
+		}
+		return outputs
+	}
+
+	tests := []struct {
+		name string
+
+		// Mock data
+		mockInputs  []*dynamodb.BatchWriteItemInput
+		mockOutputs []*dynamodb.BatchWriteItemOutput
+
+		// Expected output
+		wantDelays []time.Duration
+		wantErr    error
+	}{
+		{
+			// The first batch leaves one request unprocessed, which must be retried with the remaining requests.
+			name: "UnprocessedRequest",
+			mockInputs: []*dynamodb.BatchWriteItemInput{
+				batchDeleteInput(requests[:25]),
+				batchDeleteInput(append([]*dynamodb.WriteRequest{requests[24]}, requests[25:]...)),
+			},
+			mockOutputs: []*dynamodb.BatchWriteItemOutput{unprocessedOutput(requests[24]), {}},
+			wantDelays:  []time.Duration{50 * time.Millisecond},
+		},
+		{
+			name:        "Backoff",
+			mockInputs:  retriedInputs(3),
+			mockOutputs: retriedOutputs(3, true),
+			wantDelays:  []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name:        "TooManyRetries",
+			mockInputs:  retriedInputs(maxBatchRetries),
+			mockOutputs: retriedOutputs(maxBatchRetries+1, false),
+			wantDelays: []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+				800 * time.Millisecond, 1600 * time.Millisecond, 3200 * time.Millisecond, 6400 * time.Millisecond},
+			wantErr: errors.NewServer("1 version deletions were still unprocessed after 8 retries"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			var delays []time.Duration
+			querySvc = queryMock(
+				[]*dynamodb.QueryInput{versionKeysQueryInput("test@example.com", "projectID")},
+				[]*dynamodb.QueryOutput{{Items: keys}},
+				nil,
+			)
+			batchWriteSvc = batchWriteItemMock(test.mockInputs, test.mockOutputs, nil)
+			sleep = func(delay time.Duration) {
+				delays = append(delays, delay)
+			}
+			defer func() {
+				querySvc = defaultSvc
+				batchWriteSvc = defaultSvc
+				sleep = time.Sleep
+			}()
+
+			// Execute
+			err := Dynamo.deleteVersions("test@example.com", "projectID")
+
+			// Verify
+			if !reflect.DeepEqual(delays, test.wantDelays) {
+				t.Errorf("Got delays %v; want %v", delays, test.wantDelays)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%s'; want '%s'", err, test.wantErr)
+			}
+		})
+	}
+}
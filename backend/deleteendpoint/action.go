@@ -0,0 +1,29 @@
+package deleteendpoint
+
+import (
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// deleteEndpointDatabase wraps the database methods required to perform the deleteEndpoint action.
+// This allows for dependency injection of the database.
+type deleteEndpointDatabase interface {
+	auth.CredentialStore
+	DeleteEndpoint(string, string, string) error
+}
+
+// deleteEndpoint deletes the given endpointID from the given projectID. If the endpoint does not exist, no error
+// is returned.
+func deleteEndpoint(cookie string, projectID string, endpointID string, verifyCookie auth.VerifyCookieFunc, db deleteEndpointDatabase) error {
+	if projectID == "" || endpointID == "" {
+		return errors.NewClient("Parameters `projectID` and `endpointID` are both required")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return errors.NewClient("Not authenticated")
+	}
+
+	err = db.DeleteEndpoint(email, projectID, endpointID)
+	return errors.Wrap(err, "Failed to delete endpoint in database")
+}
@@ -0,0 +1,116 @@
+package deleteendpoint
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email      string
+	projectID  string
+	endpointID string
+	err        error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) DeleteEndpoint(email string, projectID string, endpointID string) error {
+	if email != mock.email || projectID != mock.projectID || endpointID != mock.endpointID {
+		return errors.NewServer("Incorrect input to DeleteEndpoint mock.")
+	}
+	return mock.err
+}
+
+var deleteEndpointTests = []struct {
+	name string
+
+	cookie     string
+	projectID  string
+	endpointID string
+
+	db        *databaseMock
+	email     string
+	verifyErr error
+
+	wantErr error
+}{
+	{
+		name:    "EmptyProjectID",
+		wantErr: errors.NewClient("Parameters `projectID` and `endpointID` are both required"),
+	},
+	{
+		name:      "EmptyEndpointID",
+		projectID: "project",
+		wantErr:   errors.NewClient("Parameters `projectID` and `endpointID` are both required"),
+	},
+	{
+		name:       "InvalidCookie",
+		cookie:     "invalidCookie",
+		projectID:  "project",
+		endpointID: "endpoint",
+		verifyErr:  errors.NewClient("Invalid cookie format"),
+		wantErr:    errors.NewClient("Not authenticated"),
+	},
+	{
+		name:       "DatabaseFailure",
+		cookie:     "validCookie",
+		projectID:  "project",
+		endpointID: "endpoint",
+		db:         &databaseMock{email: "test@example.com", projectID: "project", endpointID: "endpoint", err: errors.NewServer("Database failure")},
+		email:      "test@example.com",
+		wantErr:    errors.Wrap(errors.NewServer("Database failure"), "Failed to delete endpoint in database"),
+	},
+	{
+		name:       "SuccessfulInvocation",
+		cookie:     "validCookie",
+		projectID:  "project",
+		endpointID: "endpoint",
+		db:         &databaseMock{email: "test@example.com", projectID: "project", endpointID: "endpoint"},
+		email:      "test@example.com",
+	},
+}
+
+func TestDeleteEndpoint(t *testing.T) {
+	for _, test := range deleteEndpointTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock(test.cookie, test.db, test.email, test.verifyErr)
+
+			// Execute
+			err := deleteEndpoint(test.cookie, test.projectID, test.endpointID, verifyCookie, test.db)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err `%v`; want `%v`", err, test.wantErr)
+			}
+		})
+	}
+}
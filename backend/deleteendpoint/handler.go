@@ -0,0 +1,44 @@
+// Package deleteendpoint handles requests to the DELETE /projects/{pid}/endpoints/{eid} REST API endpoint.
+package deleteendpoint
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// deleteEndpointResponse contains the fields returned in the API JSON response body.
+type deleteEndpointResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+func (response *deleteEndpointResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// deleteEndpointFunc points to the function used to perform the deleteEndpoint action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var deleteEndpointFunc = deleteEndpoint
+
+// HandleDeleteEndpoint parses the request from AWS APIGateway and passes it to the deleteEndpoint action. The
+// request must contain a valid cookie or access token, as well as `pid` and `eid` path parameters. If the request
+// succeeds, the response will have a 200 status and an empty body. If the request fails, the response will
+// have either a 400 or 500 status and an `error` field in the body.
+func HandleDeleteEndpoint(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	cookie := auth.ExtractCredential(request.Headers)
+	projectID := request.PathParameters["pid"]
+	endpointID := request.PathParameters["eid"]
+	log.Info("Got request parameters. Cookie:", cookie, "projectID:", projectID, "endpointID:", endpointID)
+
+	// Delete the endpoint
+	err := deleteEndpointFunc(cookie, projectID, endpointID, auth.Verifier(), dao.Dynamo)
+	log.Error(err)
+
+	// Handle the output
+	return http.GatewayResponse(&deleteEndpointResponse{}, "", err), nil
+}
@@ -0,0 +1,100 @@
+package deleteendpoint
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type deleteEndpointMockFunc func(string, string, string, auth.VerifyCookieFunc, deleteEndpointDatabase) error
+
+func deleteEndpointMock(wantCookie string, wantPID string, wantEID string, err error) deleteEndpointMockFunc {
+	return func(cookie string, pid string, eid string, _ auth.VerifyCookieFunc, _ deleteEndpointDatabase) error {
+		if cookie != wantCookie || pid != wantPID || eid != wantEID {
+			return errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return err
+	}
+}
+
+func handlerRequest(cookie string, pid string, eid string) events.APIGatewayProxyRequest {
+	parameters := map[string]string{
+		"pid": pid,
+		"eid": eid,
+	}
+	headers := map[string]string{
+		"Cookie": cookie,
+	}
+	return events.APIGatewayProxyRequest{PathParameters: parameters, Headers: headers}
+}
+
+func handlerResponse(err string, status int) events.APIGatewayProxyResponse {
+	if err != "" {
+		return events.APIGatewayProxyResponse{
+			Body: `{"error":"` + err + `"}`,
+			Headers: map[string]string{
+				"Access-Control-Allow-Origin":      os.Getenv("CORS_ORIGIN"),
+				"Access-Control-Allow-Credentials": "true",
+			},
+			StatusCode: status,
+		}
+	}
+	return events.APIGatewayProxyResponse{
+		Body: "{}",
+		Headers: map[string]string{
+			"Access-Control-Allow-Origin":      os.Getenv("CORS_ORIGIN"),
+			"Access-Control-Allow-Credentials": "true",
+		},
+		StatusCode: status,
+	}
+}
+
+var handleDeleteEndpointTests = []struct {
+	name string
+
+	request            events.APIGatewayProxyRequest
+	deleteEndpointMock deleteEndpointMockFunc
+
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:               "DeleteEndpointFailure",
+		request:            handlerRequest("session=cookievalue", "projectId", "endpointId"),
+		deleteEndpointMock: deleteEndpointMock("cookievalue", "projectId", "endpointId", errors.Wrap(errors.NewClient("Invalid endpoint ID"), "Failed database update")),
+		wantResponse:       handlerResponse("Invalid endpoint ID", 400),
+	},
+	{
+		name:               "SuccessfulInvocation",
+		request:            handlerRequest("session=cookievalue", "projectId", "endpointId"),
+		deleteEndpointMock: deleteEndpointMock("cookievalue", "projectId", "endpointId", nil),
+		wantResponse:       handlerResponse("", 200),
+	},
+}
+
+func TestHandleDeleteEndpoint(t *testing.T) {
+	for _, test := range handleDeleteEndpointTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			deleteEndpointFunc = test.deleteEndpointMock
+			defer func() {
+				deleteEndpointFunc = deleteEndpoint
+			}()
+
+			// Execute
+			response, err := HandleDeleteEndpoint(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -1,18 +1,27 @@
 package deleteobject
 
 import (
+	"fmt"
+
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/endpoints"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/types"
 )
 
 // deleteObjectDatabase wraps the database methods required to perform the deleteObject action.
 // This allows for dependency injection of the database.
 type deleteObjectDatabase interface {
-	auth.UserGetter
-	DeleteObject(string, string, string) error
+	auth.CredentialStore
+	GetProject(string, string) (*dao.Project, error)
+	DeleteObject(string, string, string, int) error
 }
 
-// deleteObject deletes the given objectID from the given projectID. If the object or project does not exist, no error is returned.
+// deleteObject deletes the given objectID from the given projectID. If the object does not exist, no error is returned.
+// An object cannot be deleted while a relationship attribute of another object in the project references it or
+// while an endpoint operates on it. If the project changes while the object is being deleted, a client error asking
+// the user to try again is returned.
 func deleteObject(cookie string, projectID string, objectID string, verifyCookie auth.VerifyCookieFunc, db deleteObjectDatabase) error {
 	if projectID == "" || objectID == "" {
 		return errors.NewClient("Parameters `projectID` and `objectID` are both required")
@@ -23,6 +32,18 @@ func deleteObject(cookie string, projectID string, objectID string, verifyCookie
 		return errors.NewClient("Not authenticated")
 	}
 
-	err = db.DeleteObject(email, projectID, objectID)
+	project, err := db.GetProject(email, projectID)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get project")
+	}
+	if object, attribute := types.ReferencedBy(project.Objects, objectID); object != nil {
+		return errors.NewClient(fmt.Sprintf("Object `%s` cannot be deleted because attribute `%s` of object `%s` references it", objectID, attribute.Name, object.Name))
+	}
+
+	if endpoint := endpoints.UsedBy(project.Endpoints, objectID); endpoint != nil {
+		return errors.NewClient(fmt.Sprintf("Object `%s` cannot be deleted because endpoint `%s` uses it", objectID, endpoint.ID))
+	}
+
+	err = db.DeleteObject(email, projectID, objectID, project.Version)
 	return errors.Wrap(err, "Failed to delete object in database")
 }
@@ -3,14 +3,15 @@ package deleteobject
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
 
-func verifyCookieMock(mockCookie string, mockDB auth.UserGetter, mockEmail string, mockErr error) auth.VerifyCookieFunc {
-	return func(cookie string, db auth.UserGetter) (string, error) {
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
 		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
 			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
 		}
@@ -22,20 +23,55 @@ type databaseMock struct {
 	email     string
 	projectID string
 	objectID  string
+	version   int
 	err       error
+	project   *dao.Project
+	getErr    error
 }
 
-func (mock *databaseMock) GetUserInfo(email string) (*dao.User, error) {
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
 	return nil, nil
 }
 
-func (mock *databaseMock) DeleteObject(email string, projectID string, objectID string) error {
-	if email != mock.email || projectID != mock.projectID || objectID != mock.objectID {
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetProject(email string, projectID string) (*dao.Project, error) {
+	if email != mock.email || projectID != mock.projectID {
+		return nil, errors.NewServer("Incorrect input to GetProject mock.")
+	}
+	return mock.project, mock.getErr
+}
+
+func (mock *databaseMock) DeleteObject(email string, projectID string, objectID string, version int) error {
+	if email != mock.email || projectID != mock.projectID || objectID != mock.objectID || version != mock.version {
 		return errors.NewServer("Incorrect input to DeleteObject mock.")
 	}
 	return mock.err
 }
 
+// referencingProject contains a book that belongs to an author and an endpoint that lists books.
+var referencingProject = &dao.Project{
+	Version: 3,
+	Objects: map[string]*dao.Object{
+		"author": {ID: "author", Name: "Author"},
+		"book":   {ID: "book", Name: "Book", Attributes: []*dao.Attribute{{Name: "author", Type: "BelongsTo", Target: "author"}}},
+		"object": {ID: "object", Name: "Object"},
+	},
+	Endpoints: map[string]*dao.Endpoint{
+		"listBooks": {ID: "listBooks", Object: "book"},
+	},
+}
+
 var deleteObjectTests = []struct {
 	name string
 
@@ -66,21 +102,57 @@ var deleteObjectTests = []struct {
 		verifyErr: errors.NewClient("Invalid cookie format"),
 		wantErr:   errors.NewClient("Not authenticated"),
 	},
+	{
+		name:      "GetProjectFailure",
+		cookie:    "validCookie",
+		projectID: "project",
+		objectID:  "object",
+		db:        &databaseMock{email: "test@example.com", projectID: "project", getErr: errors.NewClient("Project 'project' not found")},
+		email:     "test@example.com",
+		wantErr:   errors.Wrap(errors.NewClient("Project 'project' not found"), "Failed to get project"),
+	},
+	{
+		name:      "ObjectReferenced",
+		cookie:    "validCookie",
+		projectID: "project",
+		objectID:  "author",
+		db:        &databaseMock{email: "test@example.com", projectID: "project", project: referencingProject},
+		email:     "test@example.com",
+		wantErr:   errors.NewClient("Object `author` cannot be deleted because attribute `author` of object `Book` references it"),
+	},
+	{
+		name:      "ObjectUsedByEndpoint",
+		cookie:    "validCookie",
+		projectID: "project",
+		objectID:  "book",
+		db:        &databaseMock{email: "test@example.com", projectID: "project", project: referencingProject},
+		email:     "test@example.com",
+		wantErr:   errors.NewClient("Object `book` cannot be deleted because endpoint `listBooks` uses it"),
+	},
 	{
 		name:      "DatabaseFailure",
 		cookie:    "validCookie",
 		projectID: "project",
 		objectID:  "object",
-		db:        &databaseMock{email: "test@example.com", projectID: "project", objectID: "object", err: errors.NewServer("Database failure")},
+		db:        &databaseMock{email: "test@example.com", projectID: "project", objectID: "object", err: errors.NewServer("Database failure"), project: &dao.Project{}},
 		email:     "test@example.com",
 		wantErr:   errors.Wrap(errors.NewServer("Database failure"), "Failed to delete object in database"),
 	},
+	{
+		name:      "ProjectChanged",
+		cookie:    "validCookie",
+		projectID: "project",
+		objectID:  "object",
+		db:        &databaseMock{email: "test@example.com", projectID: "project", objectID: "object", version: 3, err: errors.NewClient("Project 'project' was changed by another request. Please try again"), project: referencingProject},
+		email:     "test@example.com",
+		wantErr:   errors.Wrap(errors.NewClient("Project 'project' was changed by another request. Please try again"), "Failed to delete object in database"),
+	},
 	{
 		name:      "SuccessfulInvocation",
 		cookie:    "validCookie",
 		projectID: "project",
 		objectID:  "object",
-		db:        &databaseMock{email: "test@example.com", projectID: "project", objectID: "object"},
+		db:        &databaseMock{email: "test@example.com", projectID: "project", objectID: "object", version: 3, project: referencingProject},
 		email:     "test@example.com",
 	},
 }
@@ -25,18 +25,18 @@ func (response *deleteObjectResponse) SetError(err string) {
 var deleteObjectFunc = deleteObject
 
 // HandleDeleteObject parses the request from AWS APIGateway and passes it to the deleteObject action. The
-// request must contain a valid `Cookie` header, as well as `pid` and `oid` path parameters. If the request
+// request must contain a valid cookie or access token, as well as `pid` and `oid` path parameters. If the request
 // succeeds, the response will have a 200 status and an empty body. If the request fails, the response will
 // have either a 400 or 500 status and an `error` field in the body.
 func HandleDeleteObject(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	// Get request parameters
-	cookie := auth.ExtractCookie(request.Headers["Cookie"])
+	cookie := auth.ExtractCredential(request.Headers)
 	projectID := request.PathParameters["pid"]
 	objectID := request.PathParameters["oid"]
 	log.Info("Got request parameters. Cookie:", cookie, "projectID:", projectID, "objectID:", objectID)
 
 	// Delete the object
-	err := deleteObjectFunc(cookie, projectID, objectID, auth.VerifyCookie, dao.Dynamo)
+	err := deleteObjectFunc(cookie, projectID, objectID, auth.Verifier(), dao.Dynamo)
 	log.Error(err)
 
 	// Handle the output
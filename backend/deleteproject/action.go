@@ -0,0 +1,60 @@
+package deleteproject
+
+import (
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/s3"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// deleteProjectDatabase wraps the database methods required to perform the deleteProject action.
+// This allows for dependency injection of the database.
+type deleteProjectDatabase interface {
+	auth.CredentialStore
+	GetProject(string, string) (*dao.Project, error)
+	DeleteProject(string, string) error
+}
+
+// deletePrefix points to the function used to delete the project's generated artifacts from S3. It
+// should not be changed except for dependency injection within unit tests.
+var deletePrefix = s3.DeletePrefix
+
+// deleteProject deletes the given projectID from the user associated with the given cookie. Before the project
+// is removed from the database, its deployed instance (if any) is terminated and its generated code is deleted
+// from S3, so that nothing is left running or stored once the project no longer exists. The instance is
+// terminated by the deployment provider that launched it, which is found with providers.
+func deleteProject(cookie string, projectID string, verifyCookie auth.VerifyCookieFunc, db deleteProjectDatabase, providers provider.LookupFunc) error {
+	if projectID == "" {
+		return errors.NewClient("Parameter `projectID` is required")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return errors.NewClient("Not authenticated")
+	}
+
+	project, err := db.GetProject(email, projectID)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get project")
+	}
+
+	if project.InstanceID != "" {
+		deployer, err := providers(provider.NameOf(&project.Deployment))
+		if err != nil {
+			return errors.Wrap(err, "Failed to get deployment provider")
+		}
+		err = deployer.Terminate(project.InstanceID)
+		if err != nil {
+			return errors.Wrap(err, "Failed to terminate deployed instance")
+		}
+	}
+
+	err = deletePrefix(s3.ProjectPrefix(email, projectID))
+	if err != nil {
+		return errors.Wrap(err, "Failed to delete generated code")
+	}
+
+	err = db.DeleteProject(email, projectID)
+	return errors.Wrap(err, "Failed to delete project in database")
+}
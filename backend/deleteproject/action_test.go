@@ -0,0 +1,255 @@
+package deleteproject
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email     string
+	projectID string
+
+	project          *dao.Project
+	getProjectErr    error
+	deleteProjectErr error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetProject(email string, projectID string) (*dao.Project, error) {
+	if email != mock.email || projectID != mock.projectID {
+		return nil, errors.NewServer("Incorrect input to GetProject mock")
+	}
+	return mock.project, mock.getProjectErr
+}
+
+func (mock *databaseMock) DeleteProject(email string, projectID string) error {
+	if email != mock.email || projectID != mock.projectID {
+		return errors.NewServer("Incorrect input to DeleteProject mock")
+	}
+	return mock.deleteProjectErr
+}
+
+type providerMock struct {
+	name       string
+	lookupErr  error
+	instanceID string
+	err        error
+}
+
+func (mock *providerMock) lookup(name string) (provider.Provider, error) {
+	if name != mock.name {
+		return nil, errors.NewServer("Incorrect input to Lookup mock")
+	}
+	if mock.lookupErr != nil {
+		return nil, mock.lookupErr
+	}
+	return mock, nil
+}
+
+func (mock *providerMock) Name() string {
+	return mock.name
+}
+
+func (mock *providerMock) Launch(projectURL string, owner provider.Owner, config *dao.DeployConfig) (*provider.Instance, error) {
+	return nil, errors.NewServer("Unexpected call to Launch mock")
+}
+
+func (mock *providerMock) Describe(instanceID string) (*provider.Instance, error) {
+	return nil, errors.NewServer("Unexpected call to Describe mock")
+}
+
+func (mock *providerMock) Terminate(instanceID string) error {
+	if instanceID != mock.instanceID {
+		return errors.NewServer("Incorrect input to Terminate mock")
+	}
+	return mock.err
+}
+
+func (mock *providerMock) List() ([]*provider.Instance, error) {
+	return nil, errors.NewServer("Unexpected call to List mock")
+}
+
+func (mock *providerMock) Logs(instanceID string) (string, error) {
+	return "", errors.NewServer("Unexpected call to Logs mock")
+}
+
+func deletePrefixMock(wantPrefix string, err error) func(string) error {
+	return func(prefix string) error {
+		if prefix != wantPrefix {
+			return errors.NewServer("Incorrect input to DeletePrefix mock")
+		}
+		return err
+	}
+}
+
+var deleteProjectTests = []struct {
+	name string
+
+	// Input
+	cookie    string
+	projectID string
+
+	// Mock data
+	db               *databaseMock
+	deployer         *providerMock
+	email            string
+	verifyErr        error
+	deletePrefixMock func(string) error
+
+	// Expected output
+	wantErr error
+}{
+	{
+		name:    "EmptyProjectID",
+		cookie:  "cookie",
+		wantErr: errors.NewClient("Parameter `projectID` is required"),
+	},
+	{
+		name:      "InvalidCookie",
+		cookie:    "cookie",
+		projectID: "projectID",
+		verifyErr: errors.NewClient("Invalid cookie"),
+		wantErr:   errors.NewClient("Not authenticated"),
+	},
+	{
+		name:      "GetProjectFailure",
+		cookie:    "cookie",
+		projectID: "projectID",
+		db: &databaseMock{
+			email:         "test@example.com",
+			projectID:     "projectID",
+			getProjectErr: errors.NewClient("Invalid project id"),
+		},
+		email:   "test@example.com",
+		wantErr: errors.Wrap(errors.NewClient("Invalid project id"), "Failed to get project"),
+	},
+	{
+		name:      "TerminateInstanceFailure",
+		cookie:    "cookie",
+		projectID: "projectID",
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "projectID",
+			project:   &dao.Project{ID: "projectID", Deployment: dao.Deployment{InstanceID: "instanceID"}},
+		},
+		deployer: &providerMock{name: "ec2", instanceID: "instanceID", err: errors.NewServer("EC2 failure")},
+		email:    "test@example.com",
+		wantErr:  errors.Wrap(errors.NewServer("EC2 failure"), "Failed to terminate deployed instance"),
+	},
+	{
+		name:      "ProviderLookupFailure",
+		cookie:    "cookie",
+		projectID: "projectID",
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "projectID",
+			project:   &dao.Project{ID: "projectID", Deployment: dao.Deployment{Provider: "docker", InstanceID: "container"}},
+		},
+		deployer: &providerMock{name: "docker", lookupErr: errors.NewServer("Unsupported deployment provider `docker`")},
+		email:    "test@example.com",
+		wantErr:  errors.Wrap(errors.NewServer("Unsupported deployment provider `docker`"), "Failed to get deployment provider"),
+	},
+	{
+		name:      "DockerDeployment",
+		cookie:    "cookie",
+		projectID: "projectID",
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "projectID",
+			project:   &dao.Project{ID: "projectID", Deployment: dao.Deployment{Provider: "docker", InstanceID: "container"}},
+		},
+		deployer:         &providerMock{name: "docker", instanceID: "container"},
+		email:            "test@example.com",
+		deletePrefixMock: deletePrefixMock("test@example.com/projectID/", nil),
+	},
+	{
+		name:      "DeletePrefixFailure",
+		cookie:    "cookie",
+		projectID: "projectID",
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "projectID",
+			project:   &dao.Project{ID: "projectID", Deployment: dao.Deployment{InstanceID: "instanceID"}},
+		},
+		deployer:         &providerMock{name: "ec2", instanceID: "instanceID"},
+		email:            "test@example.com",
+		deletePrefixMock: deletePrefixMock("test@example.com/projectID/", errors.NewServer("S3 failure")),
+		wantErr:          errors.Wrap(errors.NewServer("S3 failure"), "Failed to delete generated code"),
+	},
+	{
+		name:      "DeleteProjectFailure",
+		cookie:    "cookie",
+		projectID: "projectID",
+		db: &databaseMock{
+			email:            "test@example.com",
+			projectID:        "projectID",
+			project:          &dao.Project{ID: "projectID"},
+			deleteProjectErr: errors.NewServer("DynamoDB failure"),
+		},
+		deployer:         &providerMock{},
+		email:            "test@example.com",
+		deletePrefixMock: deletePrefixMock("test@example.com/projectID/", nil),
+		wantErr:          errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to delete project in database"),
+	},
+	{
+		name:      "SuccessfulInvocation",
+		cookie:    "cookie",
+		projectID: "projectID",
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "projectID",
+			project:   &dao.Project{ID: "projectID", Deployment: dao.Deployment{InstanceID: "instanceID"}},
+		},
+		deployer:         &providerMock{name: "ec2", instanceID: "instanceID"},
+		email:            "test@example.com",
+		deletePrefixMock: deletePrefixMock("test@example.com/projectID/", nil),
+	},
+}
+
+func TestDeleteProject(t *testing.T) {
+	for _, test := range deleteProjectTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock(test.cookie, test.db, test.email, test.verifyErr)
+			deletePrefix = test.deletePrefixMock
+
+			// Execute
+			err := deleteProject(test.cookie, test.projectID, verifyCookie, test.db, test.deployer.lookup)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Package deleteproject handles requests to the DELETE /projects/{pid} REST API endpoint.
+package deleteproject
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+
+	// Register the supported deployment providers
+	_ "github.com/jackstenglein/rest_api_creator/backend/deploy/docker"
+	_ "github.com/jackstenglein/rest_api_creator/backend/deploy/ec2"
+)
+
+// deleteProjectResponse contains the fields returned in the API JSON response body.
+type deleteProjectResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+func (response *deleteProjectResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// deleteProjectFunc points to the function used to perform the deleteProject action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var deleteProjectFunc = deleteProject
+
+// HandleDeleteProject parses the request from AWS APIGateway and passes it to the deleteProject action. The
+// request must contain a valid cookie or access token and a `pid` path parameter. If the request succeeds, the response
+// will have a 200 status and an empty body. If the request fails, the response will have either a 400 or 500
+// status and an `error` field in the body.
+func HandleDeleteProject(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	cookie := auth.ExtractCredential(request.Headers)
+	projectID := request.PathParameters["pid"]
+	log.Info("Got request parameters. Cookie:", cookie, "projectID:", projectID)
+
+	// Delete the project
+	err := deleteProjectFunc(cookie, projectID, auth.Verifier(), dao.Dynamo, provider.Lookup)
+	log.Error(err)
+
+	// Handle the output
+	return http.GatewayResponse(&deleteProjectResponse{}, "", err), nil
+}
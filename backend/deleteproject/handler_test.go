@@ -0,0 +1,94 @@
+package deleteproject
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type deleteProjectMockFunc func(string, string, auth.VerifyCookieFunc, deleteProjectDatabase, provider.LookupFunc) error
+
+func deleteProjectMock(wantCookie string, wantPID string, err error) deleteProjectMockFunc {
+	return func(cookie string, pid string, _ auth.VerifyCookieFunc, _ deleteProjectDatabase, _ provider.LookupFunc) error {
+		if cookie != wantCookie || pid != wantPID {
+			return errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return err
+	}
+}
+
+func handlerRequest(cookie string, pid string) events.APIGatewayProxyRequest {
+	parameters := map[string]string{
+		"pid": pid,
+	}
+	headers := map[string]string{
+		"Cookie": cookie,
+	}
+	return events.APIGatewayProxyRequest{PathParameters: parameters, Headers: headers}
+}
+
+func handlerResponse(err string, status int) events.APIGatewayProxyResponse {
+	body := "{}"
+	if err != "" {
+		body = `{"error":"` + err + `"}`
+	}
+	return events.APIGatewayProxyResponse{
+		Body: body,
+		Headers: map[string]string{
+			"Access-Control-Allow-Origin":      os.Getenv("CORS_ORIGIN"),
+			"Access-Control-Allow-Credentials": "true",
+		},
+		StatusCode: status,
+	}
+}
+
+var handleDeleteProjectTests = []struct {
+	name string
+
+	request           events.APIGatewayProxyRequest
+	deleteProjectMock deleteProjectMockFunc
+
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:              "DeleteProjectFailure",
+		request:           handlerRequest("session=cookievalue", "projectId"),
+		deleteProjectMock: deleteProjectMock("cookievalue", "projectId", errors.Wrap(errors.NewClient("Invalid project id"), "Failed to get project")),
+		wantResponse:      handlerResponse("Invalid project id", 400),
+	},
+	{
+		name:              "SuccessfulInvocation",
+		request:           handlerRequest("session=cookievalue", "projectId"),
+		deleteProjectMock: deleteProjectMock("cookievalue", "projectId", nil),
+		wantResponse:      handlerResponse("", 200),
+	},
+}
+
+func TestHandleDeleteProject(t *testing.T) {
+	for _, test := range handleDeleteProjectTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			deleteProjectFunc = test.deleteProjectMock
+			defer func() {
+				deleteProjectFunc = deleteProject
+			}()
+
+			// Execute
+			response, err := HandleDeleteProject(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
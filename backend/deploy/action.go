@@ -1,71 +1,133 @@
 package deploy
 
 import (
+	"time"
+
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/artifact"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/s3"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 	"github.com/jackstenglein/rest_api_creator/backend/log"
 )
 
 // deployDatabase wraps the database functions used by the deployProject action in order to allow dependency injection.
 type deployDatabase interface {
-	auth.UserGetter
+	auth.CredentialStore
 	GetProject(string, string) (*dao.Project, error)
-	UpdateDeployment(string, string, string, string) error
+	UpdateDeployment(string, string, *dao.Deployment) error
+	UpdateDeployConfig(string, string, *dao.DeployConfig) error
 }
 
-// deployer wraps the EC2 functions used by the deployProject action in order to allow dependency injection.
-type deployer interface {
-	GetPublicURL(string) (string, error)
-	LaunchInstance(string) (string, string, error)
-	TerminateInstance(string) error
-}
+// artifactExpiry is how long the pre-signed URL from which a new instance downloads the generated code of the
+// project remains valid. It leaves time for the instance to boot and install its dependencies first.
+const artifactExpiry = time.Hour
 
-// deployProject launches an EC2 instance to run the given project. If the deployment is successful and the EC2 instance launches
-// within 5 seconds, the public DNS name of the instance is returned.
-func deployProject(cookie string, projectID string, deployRequest deployRequest, verifyCookie auth.VerifyCookieFunc, db deployDatabase, ec2 deployer) (string, string, error) {
+// These variables wrap the functions that deployProject uses to build the code of the project. They should
+// not be changed except for dependency injection within unit tests.
+var lookup = codegen.Lookup
+var build = artifact.Build
+var presign = s3.PresignFor
 
-	if projectID == "" {
-		return "", "", errors.NewClient("Parameter `pid` is required")
-	}
+// deployProject launches an instance to run the given project, replacing the instance that currently runs it.
+// The code of the project is generated for codegen.DefaultTarget and uploaded to S3 first, and the instance
+// downloads it from a pre-signed URL created here, so that instances only ever run code generated by us.
+// The instance is launched by the configured deployment provider, while the current instance is terminated by
+// the provider that launched it; both are found with providers. The deployment is recorded as pending before
+// the instance is launched and as provisioning once the provider accepts the instance, so that
+// GET /projects/{pid}/deploy can follow it until it is healthy. If the instance fails to launch, the deployment
+// is recorded as failed, and if the launched instance cannot be recorded, it is terminated. The instance is
+// launched with the deployment config of the request, which is saved to the project, or else with the config
+// last saved to the project. The recorded deployment is returned.
+func deployProject(cookie string, projectID string, deployRequest deployRequest, verifyCookie auth.VerifyCookieFunc, db deployDatabase, providers provider.LookupFunc) (*dao.Deployment, error) {
 
-	if deployRequest.URL == "" {
-		return "", "", errors.NewClient("Parameter `url` is required")
+	if projectID == "" {
+		return nil, errors.NewClient("Parameter `pid` is required")
 	}
 
 	email, err := verifyCookie(cookie, db)
 	if err != nil {
-		return "", "", errors.Wrap(err, "Failed to verify cookie")
+		return nil, errors.Wrap(err, "Failed to verify cookie")
 	}
 
 	project, err := db.GetProject(email, projectID)
 	if err != nil {
-		return "", "", errors.Wrap(err, "Failed to get project")
+		return nil, errors.Wrap(err, "Failed to get project")
 	}
 	log.Info("Got project:", project)
 
+	config := deployRequest.Config
+	if config == nil {
+		config = project.DeployConfig
+	}
+	config, err = provider.ValidateConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid deployment config")
+	}
+	if deployRequest.Config != nil {
+		err = db.UpdateDeployConfig(email, projectID, config)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to update deployment config")
+		}
+	}
+
+	generator, err := lookup(codegen.DefaultTarget)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to find code generator")
+	}
+	key, err := build(generator, email, projectID, project, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to build generated code")
+	}
+	projectURL, err := presign(key, artifactExpiry)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to generate pre-signed URL")
+	}
+
 	if project.InstanceID != "" {
-		// TODO: terminate old instance
 		log.Info("Terminating old instance with id: ", project.InstanceID)
-		err = ec2.TerminateInstance(project.InstanceID)
+		current, err := providers(provider.NameOf(&project.Deployment))
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to get deployment provider")
+		}
+		err = current.Terminate(project.InstanceID)
 		if err != nil {
-			return "", "", errors.Wrap(err, "Failed to terminate original instance")
+			return nil, errors.Wrap(err, "Failed to terminate original instance")
 		}
 	}
 
+	deployer, err := providers("")
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get deployment provider")
+	}
+
+	deployment := &dao.Deployment{Provider: deployer.Name(), Status: dao.DeployPending}
+	err = db.UpdateDeployment(email, projectID, deployment)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to update deployment info")
+	}
+
 	// Launch new instance
-	log.Info("Launching instance")
-	instanceID, url, err := ec2.LaunchInstance(deployRequest.URL)
+	log.Info("Launching instance with provider:", deployer.Name())
+	instance, err := deployer.Launch(projectURL, provider.Owner{Email: email, ProjectID: projectID}, config)
 	if err != nil {
-		return "", "", errors.Wrap(err, "Failed to launch EC2 instance")
+		err = errors.Wrap(err, "Failed to launch instance")
+		message, _ := errors.UserDetails(err)
+		deployment = &dao.Deployment{Provider: deployer.Name(), Status: dao.DeployFailed, Error: message}
+		log.Error(db.UpdateDeployment(email, projectID, deployment))
+		return nil, err
 	}
 
 	log.Info("Updating deployment")
-	err = db.UpdateDeployment(email, projectID, instanceID, url)
+	deployment = &dao.Deployment{Provider: deployer.Name(), InstanceID: instance.ID, URL: instance.URL, Status: dao.DeployProvisioning}
+	err = db.UpdateDeployment(email, projectID, deployment)
 	if err != nil {
-		// TODO: terminate instance in order to not leak EC2 instances
-		return "", "", errors.Wrap(err, "Failed to update deployment info")
+		// The instance is not recorded anywhere, so terminate it rather than leak it
+		log.Error(errors.Wrap(deployer.Terminate(instance.ID), "Failed to terminate unrecorded instance"))
+		return nil, errors.Wrap(err, "Failed to update deployment info")
 	}
 
-	return instanceID, url, nil
+	return deployment, nil
 }
@@ -3,14 +3,19 @@ package deploy
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/artifact"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/s3"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
 
-func verifyCookieMock(mockCookie string, mockDB auth.UserGetter, mockEmail string, mockErr error) auth.VerifyCookieFunc {
-	return func(cookie string, db auth.UserGetter) (string, error) {
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
 		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
 			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
 		}
@@ -19,19 +24,39 @@ func verifyCookieMock(mockCookie string, mockDB auth.UserGetter, mockEmail strin
 }
 
 type databaseMock struct {
-	email      string
-	projectID  string
-	project    *dao.Project
-	getErr     error
-	instanceID string
-	url        string
-	updateErr  error
+	email     string
+	projectID string
+	project   *dao.Project
+	getErr    error
+
+	// updates are the deployments expected by successive calls to UpdateDeployment, and updateErrs are
+	// the errors those calls return.
+	updates    []*dao.Deployment
+	updateErrs []error
+	calls      int
+
+	// config is the deployment config expected by UpdateDeployConfig, and configSaved is set when it is saved.
+	config      *dao.DeployConfig
+	configErr   error
+	configSaved bool
 }
 
-func (mock *databaseMock) GetUserInfo(email string) (*dao.User, error) {
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
 	return nil, nil
 }
 
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
 func (mock *databaseMock) GetProject(email string, projectID string) (*dao.Project, error) {
 	if email != mock.email || projectID != mock.projectID {
 		return nil, errors.NewServer("Incorrect input to GetProject mock.")
@@ -39,45 +64,168 @@ func (mock *databaseMock) GetProject(email string, projectID string) (*dao.Proje
 	return mock.project, mock.getErr
 }
 
-func (mock *databaseMock) UpdateDeployment(email string, projectID string, instanceID string, url string) error {
-	if email != mock.email || projectID != mock.projectID || instanceID != mock.instanceID || url != mock.url {
+func (mock *databaseMock) UpdateDeployment(email string, projectID string, deployment *dao.Deployment) error {
+	if email != mock.email || projectID != mock.projectID || mock.calls >= len(mock.updates) || !reflect.DeepEqual(deployment, mock.updates[mock.calls]) {
 		return errors.NewServer("Incorrect input to UpdateDeployment mock")
 	}
-	return mock.updateErr
+	mock.calls++
+	if mock.calls > len(mock.updateErrs) {
+		return nil
+	}
+	return mock.updateErrs[mock.calls-1]
+}
+
+func (mock *databaseMock) UpdateDeployConfig(email string, projectID string, config *dao.DeployConfig) error {
+	if email != mock.email || projectID != mock.projectID || !reflect.DeepEqual(config, mock.config) {
+		return errors.NewServer("Incorrect input to UpdateDeployConfig mock")
+	}
+	mock.configSaved = true
+	return mock.configErr
+}
+
+// defaultConfig is the deployment config used when neither the request nor the project has a config.
+var defaultConfig = &dao.DeployConfig{Region: "us-east-1", InstanceType: "t2.micro", IngressCIDR: "0.0.0.0/0", Port: 80}
+
+type providerMock struct {
+	name         string
+	projectURL   string
+	config       *dao.DeployConfig
+	instance     *provider.Instance
+	launchErr    error
+	terminateID  string
+	terminateErr error
+
+	// rollbackID is the ID of the launched instance that should be terminated because it could not be
+	// recorded, and rolledBack is set when it is terminated.
+	rollbackID  string
+	rollbackErr error
+	rolledBack  bool
 }
 
-type ec2Mock struct {
-	instanceID string
-	launchURL  string
-	launchErr  error
-	getURL     string
-	getErr     error
+func (mock *providerMock) Name() string {
+	return mock.name
 }
 
-func (mock *ec2Mock) LaunchInstance() (string, string, error) {
-	return mock.instanceID, mock.launchURL, mock.launchErr
+func (mock *providerMock) Launch(projectURL string, owner provider.Owner, config *dao.DeployConfig) (*provider.Instance, error) {
+	wantConfig := mock.config
+	if wantConfig == nil {
+		wantConfig = defaultConfig
+	}
+	if projectURL != mock.projectURL || owner != (provider.Owner{Email: "test@example.com", ProjectID: "project"}) || !reflect.DeepEqual(config, wantConfig) {
+		return nil, errors.NewServer("Incorrect input to Launch mock")
+	}
+	return mock.instance, mock.launchErr
 }
 
-func (mock *ec2Mock) GetPublicURL(instanceID string) (string, error) {
-	if instanceID != mock.instanceID {
-		return "", errors.NewServer("Incorrect input to GetPublicURL mock")
+func (mock *providerMock) Describe(instanceID string) (*provider.Instance, error) {
+	return nil, errors.NewServer("Unexpected call to Describe mock")
+}
+
+func (mock *providerMock) Terminate(instanceID string) error {
+	if mock.rollbackID != "" && instanceID == mock.rollbackID {
+		mock.rolledBack = true
+		return mock.rollbackErr
 	}
-	return mock.getURL, mock.getErr
+	if instanceID != mock.terminateID {
+		return errors.NewServer("Incorrect input to Terminate mock")
+	}
+	return mock.terminateErr
 }
 
+func (mock *providerMock) List() ([]*provider.Instance, error) {
+	return nil, errors.NewServer("Unexpected call to List mock")
+}
+
+func (mock *providerMock) Logs(instanceID string) (string, error) {
+	return "", errors.NewServer("Unexpected call to Logs mock")
+}
+
+// lookupMock returns a provider.LookupFunc that finds the given mocks by name. The empty name selects the
+// configured provider.
+func lookupMock(configured string, mocks ...*providerMock) provider.LookupFunc {
+	return func(name string) (provider.Provider, error) {
+		if name == "" {
+			name = configured
+		}
+		for _, mock := range mocks {
+			if mock.name == name {
+				return mock, nil
+			}
+		}
+		return nil, errors.NewServer("Unsupported deployment provider `" + name + "`")
+	}
+}
+
+type generatorMock struct{}
+
+func (generatorMock) Name() string {
+	return "sails"
+}
+
+func (generatorMock) TemplateKey() string {
+	return "templates/sails.zip"
+}
+
+func (generatorMock) Generate(project *dao.Project, rootDir string) error {
+	return errors.NewServer("Unexpected call to Generate mock")
+}
+
+func lookupGeneratorMock(name string) (codegen.Generator, error) {
+	if name != "sails" {
+		return nil, errors.NewServer("Incorrect input to Lookup mock")
+	}
+	return generatorMock{}, nil
+}
+
+func buildMock(wantProject *dao.Project, err error) func(codegen.Generator, string, string, *dao.Project, *dao.Project) (string, error) {
+	return func(generator codegen.Generator, email string, projectID string, project *dao.Project, base *dao.Project) (string, error) {
+		if generator != (generatorMock{}) || email != "test@example.com" || projectID != "project" || project != wantProject || base != nil {
+			return "", errors.NewServer("Incorrect input to Build mock")
+		}
+		if err != nil {
+			return "", err
+		}
+		return "test@example.com/project/sails.zip", nil
+	}
+}
+
+func presignMock(err error) func(string, time.Duration) (string, error) {
+	return func(key string, expiry time.Duration) (string, error) {
+		if key != "test@example.com/project/sails.zip" || expiry != time.Hour {
+			return "", errors.NewServer("Incorrect input to Presign mock")
+		}
+		if err != nil {
+			return "", err
+		}
+		return "projecturl", nil
+	}
+}
+
+var pending = &dao.Deployment{Provider: "ec2", Status: dao.DeployPending}
+
+var rollbackProvider = &providerMock{name: "ec2", projectURL: "projecturl", instance: &provider.Instance{ID: "newinstance", URL: "instanceurl"}, terminateID: "instance", rollbackID: "newinstance"}
+
+var failedRollbackProvider = &providerMock{name: "ec2", projectURL: "projecturl", instance: &provider.Instance{ID: "newinstance"}, rollbackID: "newinstance", rollbackErr: errors.NewServer("EC2 failure")}
+
 var deployProjectTests = []struct {
 	name      string
 	cookie    string
 	projectID string
+	request   deployRequest
 
 	// Mock data
-	db        *databaseMock
-	email     string
-	verifyErr error
-	ec2       *ec2Mock
+	db         *databaseMock
+	email      string
+	verifyErr  error
+	buildErr   error
+	presignErr error
+	providers  provider.LookupFunc
 
-	wantURL string
-	wantErr error
+	wantDeployment  *dao.Deployment
+	wantUpdates     int
+	wantRollback    *providerMock
+	wantConfigSaved bool
+	wantErr         error
 }{
 	{
 		name:    "EmptyProjectID",
@@ -99,74 +247,260 @@ var deployProjectTests = []struct {
 		wantErr:   errors.Wrap(errors.NewServer("Database failure"), "Failed to get project"),
 	},
 	{
-		name:      "LaunchInstanceFailure",
+		name:      "InvalidConfig",
 		cookie:    "cookievalue",
 		projectID: "project",
-		db:        &databaseMock{email: "test@example.com", projectID: "project", project: &dao.Project{ID: "project", Name: "Project", InstanceID: "instance"}},
+		request:   deployRequest{Config: &dao.DeployConfig{Region: "mars-1"}},
+		db:        &databaseMock{email: "test@example.com", projectID: "project", project: &dao.Project{ID: "project"}},
 		email:     "test@example.com",
-		ec2:       &ec2Mock{launchErr: errors.NewServer("EC2 failure")},
-		wantErr:   errors.Wrap(errors.NewServer("EC2 failure"), "Failed to launch EC2 instance"),
+		wantErr:   errors.Wrap(errors.NewClient("Unsupported region `mars-1`. Supported regions are: eu-central-1, eu-west-1, us-east-1, us-east-2, us-west-1, us-west-2"), "Invalid deployment config"),
 	},
 	{
-		name:      "UpdateDeploymentFailure",
+		name:      "UpdateDeployConfigFailure",
+		cookie:    "cookievalue",
+		projectID: "project",
+		request:   deployRequest{Config: &dao.DeployConfig{Port: 8080}},
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "project",
+			project:   &dao.Project{ID: "project"},
+			config:    &dao.DeployConfig{Region: "us-east-1", InstanceType: "t2.micro", IngressCIDR: "0.0.0.0/0", Port: 8080},
+			configErr: errors.NewServer("Database failure"),
+		},
+		email:           "test@example.com",
+		wantConfigSaved: true,
+		wantErr:         errors.Wrap(errors.NewServer("Database failure"), "Failed to update deployment config"),
+	},
+	{
+		name:      "BuildFailure",
+		cookie:    "cookievalue",
+		projectID: "project",
+		db:        &databaseMock{email: "test@example.com", projectID: "project", project: &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance"}}},
+		email:     "test@example.com",
+		buildErr:  errors.Wrap(errors.NewServer("Zip failure"), "Failed to zip generated code"),
+		wantErr:   errors.Wrap(errors.Wrap(errors.NewServer("Zip failure"), "Failed to zip generated code"), "Failed to build generated code"),
+	},
+	{
+		name:       "PresignFailure",
+		cookie:     "cookievalue",
+		projectID:  "project",
+		db:         &databaseMock{email: "test@example.com", projectID: "project", project: &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance"}}},
+		email:      "test@example.com",
+		presignErr: errors.NewServer("Presign failure"),
+		wantErr:    errors.Wrap(errors.NewServer("Presign failure"), "Failed to generate pre-signed URL"),
+	},
+	{
+		name:      "TerminateInstanceFailure",
+		cookie:    "cookievalue",
+		projectID: "project",
+		db:        &databaseMock{email: "test@example.com", projectID: "project", project: &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance"}}},
+		email:     "test@example.com",
+		providers: lookupMock("ec2", &providerMock{name: "ec2", terminateID: "instance", terminateErr: errors.NewServer("EC2 failure")}),
+		wantErr:   errors.Wrap(errors.NewServer("EC2 failure"), "Failed to terminate original instance"),
+	},
+	{
+		name:      "PendingUpdateFailure",
 		cookie:    "cookievalue",
 		projectID: "project",
 		db: &databaseMock{
 			email:      "test@example.com",
 			projectID:  "project",
-			project:    &dao.Project{ID: "project", Name: "Project", InstanceID: "instance"},
-			instanceID: "newinstance",
-			url:        "instanceurl",
-			updateErr:  errors.NewServer("Database failure"),
+			project:    &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance"}},
+			updates:    []*dao.Deployment{pending},
+			updateErrs: []error{errors.NewServer("Database failure")},
+		},
+		email:       "test@example.com",
+		providers:   lookupMock("ec2", &providerMock{name: "ec2", terminateID: "instance"}),
+		wantUpdates: 1,
+		wantErr:     errors.Wrap(errors.NewServer("Database failure"), "Failed to update deployment info"),
+	},
+	{
+		name:      "LaunchInstanceFailure",
+		cookie:    "cookievalue",
+		projectID: "project",
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "project",
+			project:   &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance"}},
+			updates: []*dao.Deployment{
+				pending,
+				{Provider: "ec2", Status: dao.DeployFailed, Error: "Failed to launch instance"},
+			},
 		},
-		email:   "test@example.com",
-		ec2:     &ec2Mock{instanceID: "newinstance", launchURL: "instanceurl", launchErr: nil},
-		wantErr: errors.Wrap(errors.NewServer("Database failure"), "Failed to update deployment info"),
+		email:       "test@example.com",
+		providers:   lookupMock("ec2", &providerMock{name: "ec2", projectURL: "projecturl", launchErr: errors.NewServer("EC2 failure"), terminateID: "instance"}),
+		wantUpdates: 2,
+		wantErr:     errors.Wrap(errors.NewServer("EC2 failure"), "Failed to launch instance"),
+	},
+	{
+		name:      "UpdateDeploymentFailure",
+		cookie:    "cookievalue",
+		projectID: "project",
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "project",
+			project:   &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance"}},
+			updates: []*dao.Deployment{
+				pending,
+				{Provider: "ec2", InstanceID: "newinstance", URL: "instanceurl", Status: dao.DeployProvisioning},
+			},
+			updateErrs: []error{nil, errors.NewServer("Database failure")},
+		},
+		email:        "test@example.com",
+		providers:    lookupMock("ec2", rollbackProvider),
+		wantUpdates:  2,
+		wantRollback: rollbackProvider,
+		wantErr:      errors.Wrap(errors.NewServer("Database failure"), "Failed to update deployment info"),
+	},
+	{
+		name:      "RollbackFailure",
+		cookie:    "cookievalue",
+		projectID: "project",
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "project",
+			project:   &dao.Project{ID: "project"},
+			updates: []*dao.Deployment{
+				pending,
+				{Provider: "ec2", InstanceID: "newinstance", Status: dao.DeployProvisioning},
+			},
+			updateErrs: []error{nil, errors.NewServer("Database failure")},
+		},
+		email:        "test@example.com",
+		providers:    lookupMock("ec2", failedRollbackProvider),
+		wantUpdates:  2,
+		wantRollback: failedRollbackProvider,
+		wantErr:      errors.Wrap(errors.NewServer("Database failure"), "Failed to update deployment info"),
+	},
+	{
+		name:      "FirstDeployment",
+		cookie:    "cookievalue",
+		projectID: "project",
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "project",
+			project:   &dao.Project{ID: "project"},
+			updates: []*dao.Deployment{
+				pending,
+				{Provider: "ec2", InstanceID: "newinstance", Status: dao.DeployProvisioning},
+			},
+		},
+		email:          "test@example.com",
+		providers:      lookupMock("ec2", &providerMock{name: "ec2", projectURL: "projecturl", instance: &provider.Instance{ID: "newinstance"}}),
+		wantDeployment: &dao.Deployment{Provider: "ec2", InstanceID: "newinstance", Status: dao.DeployProvisioning},
+		wantUpdates:    2,
 	},
 	{
 		name:      "SuccessfulInvocation",
 		cookie:    "cookievalue",
 		projectID: "project",
 		db: &databaseMock{
-			email:      "test@example.com",
-			projectID:  "project",
-			project:    &dao.Project{ID: "project", Name: "Project", InstanceID: "instance"},
-			instanceID: "newinstance",
-			url:        "instanceurl",
+			email:     "test@example.com",
+			projectID: "project",
+			project:   &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance", Status: dao.DeployHealthy}},
+			updates: []*dao.Deployment{
+				pending,
+				{Provider: "ec2", InstanceID: "newinstance", URL: "instanceurl", Status: dao.DeployProvisioning},
+			},
 		},
-		email:   "test@example.com",
-		ec2:     &ec2Mock{instanceID: "newinstance", launchURL: "instanceurl", launchErr: nil},
-		wantURL: "instanceurl",
+		email:          "test@example.com",
+		providers:      lookupMock("ec2", &providerMock{name: "ec2", projectURL: "projecturl", instance: &provider.Instance{ID: "newinstance", URL: "instanceurl"}, terminateID: "instance"}),
+		wantDeployment: &dao.Deployment{Provider: "ec2", InstanceID: "newinstance", URL: "instanceurl", Status: dao.DeployProvisioning},
+		wantUpdates:    2,
 	},
 	{
-		name:      "GetURLFailure",
+		name:      "RequestConfig",
 		cookie:    "cookievalue",
 		projectID: "project",
+		request: deployRequest{Config: &dao.DeployConfig{
+			Region: "eu-west-1", InstanceType: "t3.small", KeyPair: "my-key", IngressCIDR: "203.0.113.7/24", Port: 3000,
+		}},
 		db: &databaseMock{
-			email:      "test@example.com",
-			projectID:  "project",
-			project:    &dao.Project{ID: "project", Name: "Project", InstanceID: "instance"},
-			instanceID: "newinstance",
-			url:        "instanceurl",
+			email:     "test@example.com",
+			projectID: "project",
+			project:   &dao.Project{ID: "project", DeployConfig: &dao.DeployConfig{Region: "us-west-2"}},
+			config:    &dao.DeployConfig{Region: "eu-west-1", InstanceType: "t3.small", KeyPair: "my-key", IngressCIDR: "203.0.113.0/24", Port: 3000},
+			updates: []*dao.Deployment{
+				pending,
+				{Provider: "ec2", InstanceID: "eu-west-1:newinstance", Status: dao.DeployProvisioning},
+			},
 		},
-		email:   "test@example.com",
-		ec2:     &ec2Mock{instanceID: "newinstance", launchURL: "", launchErr: nil, getErr: errors.NewServer("DescribeInstance failure")},
-		wantErr: errors.Wrap(errors.NewServer("DescribeInstance failure"), "Failed to get instance DNS name"),
+		email: "test@example.com",
+		providers: lookupMock("ec2", &providerMock{
+			name:       "ec2",
+			projectURL: "projecturl",
+			config:     &dao.DeployConfig{Region: "eu-west-1", InstanceType: "t3.small", KeyPair: "my-key", IngressCIDR: "203.0.113.0/24", Port: 3000},
+			instance:   &provider.Instance{ID: "eu-west-1:newinstance"},
+		}),
+		wantDeployment:  &dao.Deployment{Provider: "ec2", InstanceID: "eu-west-1:newinstance", Status: dao.DeployProvisioning},
+		wantUpdates:     2,
+		wantConfigSaved: true,
 	},
 	{
-		name:      "GetURLSuccess",
+		name:      "SavedConfig",
 		cookie:    "cookievalue",
 		projectID: "project",
 		db: &databaseMock{
-			email:      "test@example.com",
-			projectID:  "project",
-			project:    &dao.Project{ID: "project", Name: "Project", InstanceID: "instance"},
-			instanceID: "newinstance",
-			url:        "instanceurl",
+			email:     "test@example.com",
+			projectID: "project",
+			project:   &dao.Project{ID: "project", DeployConfig: &dao.DeployConfig{Region: "us-west-2", Port: 8080}},
+			updates: []*dao.Deployment{
+				pending,
+				{Provider: "ec2", InstanceID: "us-west-2:newinstance", Status: dao.DeployProvisioning},
+			},
 		},
-		email:   "test@example.com",
-		ec2:     &ec2Mock{instanceID: "newinstance", launchURL: "", launchErr: nil, getURL: "instanceurl"},
-		wantURL: "instanceurl",
+		email: "test@example.com",
+		providers: lookupMock("ec2", &providerMock{
+			name:       "ec2",
+			projectURL: "projecturl",
+			config:     &dao.DeployConfig{Region: "us-west-2", InstanceType: "t2.micro", IngressCIDR: "0.0.0.0/0", Port: 8080},
+			instance:   &provider.Instance{ID: "us-west-2:newinstance"},
+		}),
+		wantDeployment: &dao.Deployment{Provider: "ec2", InstanceID: "us-west-2:newinstance", Status: dao.DeployProvisioning},
+		wantUpdates:    2,
+	},
+	{
+		name:      "OldProviderLookupFailure",
+		cookie:    "cookievalue",
+		projectID: "project",
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "project",
+			project:   &dao.Project{ID: "project", Deployment: dao.Deployment{Provider: "gce", InstanceID: "instance"}},
+		},
+		email:     "test@example.com",
+		providers: lookupMock("ec2", &providerMock{name: "ec2"}),
+		wantErr:   errors.Wrap(errors.NewServer("Unsupported deployment provider `gce`"), "Failed to get deployment provider"),
+	},
+	{
+		name:      "ConfiguredProviderLookupFailure",
+		cookie:    "cookievalue",
+		projectID: "project",
+		db:        &databaseMock{email: "test@example.com", projectID: "project", project: &dao.Project{ID: "project"}},
+		email:     "test@example.com",
+		providers: lookupMock("gce"),
+		wantErr:   errors.Wrap(errors.NewServer("Unsupported deployment provider `gce`"), "Failed to get deployment provider"),
+	},
+	{
+		name:      "SwitchProvider",
+		cookie:    "cookievalue",
+		projectID: "project",
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "project",
+			project:   &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance", Status: dao.DeployHealthy}},
+			updates: []*dao.Deployment{
+				{Provider: "docker", Status: dao.DeployPending},
+				{Provider: "docker", InstanceID: "container", Status: dao.DeployProvisioning},
+			},
+		},
+		email: "test@example.com",
+		providers: lookupMock("docker",
+			&providerMock{name: "ec2", terminateID: "instance"},
+			&providerMock{name: "docker", projectURL: "projecturl", instance: &provider.Instance{ID: "container"}},
+		),
+		wantDeployment: &dao.Deployment{Provider: "docker", InstanceID: "container", Status: dao.DeployProvisioning},
+		wantUpdates:    2,
 	},
 }
 
@@ -175,18 +509,34 @@ func TestDeployProject(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// Setup
 			verifyCookie := verifyCookieMock(test.cookie, test.db, test.email, test.verifyErr)
-			delay = func() {}
+			var project *dao.Project
+			if test.db != nil {
+				project = test.db.project
+			}
+			lookup, build, presign = lookupGeneratorMock, buildMock(project, test.buildErr), presignMock(test.presignErr)
+			defer func() {
+				lookup, build, presign = codegen.Lookup, artifact.Build, s3.PresignFor
+			}()
 
 			// Execute
-			url, err := deployProject(test.cookie, test.projectID, verifyCookie, test.db, test.ec2)
+			deployment, err := deployProject(test.cookie, test.projectID, test.request, verifyCookie, test.db, test.providers)
 
 			// Verify
-			if url != test.wantURL {
-				t.Errorf("Got url `%s`; want `%s`", url, test.wantURL)
+			if !reflect.DeepEqual(deployment, test.wantDeployment) {
+				t.Errorf("Got deployment %v; want %v", deployment, test.wantDeployment)
 			}
 			if !errors.Equal(err, test.wantErr) {
 				t.Errorf("Got err %v; want %v", err, test.wantErr)
 			}
+			if test.db != nil && test.db.calls != test.wantUpdates {
+				t.Errorf("Got %d calls to UpdateDeployment; want %d", test.db.calls, test.wantUpdates)
+			}
+			if test.db != nil && test.db.configSaved != test.wantConfigSaved {
+				t.Errorf("Got config saved %v; want %v", test.db.configSaved, test.wantConfigSaved)
+			}
+			if test.wantRollback != nil && !test.wantRollback.rolledBack {
+				t.Errorf("Unrecorded instance was not terminated")
+			}
 		})
 	}
 }
@@ -0,0 +1,396 @@
+// Package docker registers a deployment provider that runs each deployed project as a container of a local
+// Docker Engine, which it controls through the Engine API socket. It allows deployments to be tested on a
+// laptop or in CI without AWS. The socket defaults to /var/run/docker.sock and can be changed with the
+// DOCKER_SOCKET environment variable, and the image that runs the project defaults to node:14 and can be
+// changed with the DOCKER_IMAGE environment variable.
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	nethttp "net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+func init() {
+	provider.Register(Docker)
+}
+
+const (
+	defaultSocket = "/var/run/docker.sock"
+	defaultImage  = "node:14"
+
+	// defaultContainerPort is the port on which the project listens inside containers created before the
+	// port could be configured.
+	defaultContainerPort = "80/tcp"
+
+	// label marks the containers created by this provider. The owner of each container is stored in the
+	// ownerLabel and projectLabel labels, and the port on which the project listens is stored in the
+	// portLabel label.
+	label        = "crudcreator"
+	ownerLabel   = "crudcreator.owner"
+	projectLabel = "crudcreator.project"
+	portLabel    = "crudcreator.port"
+
+	// logLines is the number of lines returned by Logs.
+	logLines = "200"
+)
+
+// script downloads the zipped project from the URL in the PROJECT_URL environment variable and runs it from the
+// directory in the PROJECT_DIR environment variable, like the userdata script of the EC2 provider. Both are passed
+// through the environment so that they are never interpreted by the shell.
+const script = `set -e
+cd /tmp
+wget -q -O project.zip "$PROJECT_URL"
+unzip -q project.zip
+cd "$PROJECT_DIR"
+exec node app.js
+`
+
+// docker sends requests to the Docker Engine API at base using client.
+type docker struct {
+	client *nethttp.Client
+	base   string
+	image  string
+}
+
+// Docker is the Docker provider. It implements the provider.Provider interface.
+var Docker = newDocker(os.Getenv("DOCKER_SOCKET"), os.Getenv("DOCKER_IMAGE"))
+
+// newDocker returns a provider that connects to the Engine API through the Unix socket at the given path and
+// runs projects in the given image. Empty arguments are replaced by their defaults. The socket is not opened
+// until the first request, so the provider can be created on hosts without Docker.
+func newDocker(socket string, image string) *docker {
+	if socket == "" {
+		socket = defaultSocket
+	}
+	if image == "" {
+		image = defaultImage
+	}
+	transport := &nethttp.Transport{
+		DialContext: func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}
+	return &docker{
+		client: &nethttp.Client{Transport: transport, Timeout: 5 * time.Minute},
+		base:   "http://docker",
+		image:  image,
+	}
+}
+
+// apiError is the body of an Engine API error response.
+type apiError struct {
+	Message string `json:"message"`
+}
+
+// do sends a request with the given method, path and JSON body to the Engine API. If result is not nil, the
+// JSON response body is decoded into it. If the response has a status that is not 2xx, an error containing the
+// status is returned and the response is closed. Otherwise, the response is returned, and the caller must
+// close its body if result is nil.
+func (d *docker) do(method string, path string, body interface{}, result interface{}) (*nethttp.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to encode request body")
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	request, err := nethttp.NewRequest(method, d.base+path, reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create request")
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	log.Info("Making Docker Engine API request:", method, path)
+	response, err := d.client.Do(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed Docker Engine API request")
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		defer response.Body.Close()
+		var apiErr apiError
+		json.NewDecoder(response.Body).Decode(&apiErr)
+		return response, errors.NewServer(fmt.Sprintf("Docker Engine API returned status %d: %s", response.StatusCode, apiErr.Message))
+	}
+	if result == nil {
+		return response, nil
+	}
+
+	defer response.Body.Close()
+	err = json.NewDecoder(response.Body).Decode(result)
+	return response, errors.Wrap(err, "Failed to decode response body")
+}
+
+// discard sends a request like do and discards the response body.
+func (d *docker) discard(method string, path string, body interface{}) (*nethttp.Response, error) {
+	response, err := d.do(method, path, body, nil)
+	if err != nil {
+		return response, err
+	}
+	defer response.Body.Close()
+	_, err = io.Copy(ioutil.Discard, response.Body)
+	return response, errors.Wrap(err, "Failed to read response body")
+}
+
+// isNotFound returns true if the given response has a 404 status.
+func isNotFound(response *nethttp.Response) bool {
+	return response != nil && response.StatusCode == nethttp.StatusNotFound
+}
+
+// Name returns the name used to select the Docker provider.
+func (*docker) Name() string {
+	return "docker"
+}
+
+// portBinding is a port of the host to which a container port is published.
+type portBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+// createRequest is the body of the create container request.
+type createRequest struct {
+	Image        string                  `json:"Image"`
+	Cmd          []string                `json:"Cmd"`
+	Env          []string                `json:"Env"`
+	Labels       map[string]string       `json:"Labels"`
+	ExposedPorts map[string]struct{}     `json:"ExposedPorts"`
+	HostConfig   createRequestHostConfig `json:"HostConfig"`
+}
+
+// createRequestHostConfig is the host configuration of a create container request.
+type createRequestHostConfig struct {
+	PortBindings map[string][]portBinding `json:"PortBindings"`
+}
+
+// createResponse is the body of a create container response.
+type createResponse struct {
+	ID string `json:"Id"`
+}
+
+// containerPort returns the container port on which a project listening on the given port is exposed.
+func containerPort(port string) string {
+	return port + "/tcp"
+}
+
+// create creates a container that runs the project at the given URL on the port of the given deployment config
+// and is labeled with the given owner. The port is published on a random port of the loopback interface of the
+// host. If the image is not present, it is pulled and the container is created again.
+func (d *docker) create(projectURL string, owner provider.Owner, config *dao.DeployConfig) (string, error) {
+	port := strconv.Itoa(config.Port)
+	exposed := containerPort(port)
+	request := &createRequest{
+		Image:        d.image,
+		Cmd:          []string{"sh", "-c", script},
+		Env:          []string{"PROJECT_URL=" + projectURL, "PROJECT_DIR=" + codegen.TemplateRoot, "PORT=" + port},
+		Labels:       map[string]string{label: "true", ownerLabel: owner.Email, projectLabel: owner.ProjectID, portLabel: port},
+		ExposedPorts: map[string]struct{}{exposed: {}},
+		HostConfig: createRequestHostConfig{
+			PortBindings: map[string][]portBinding{exposed: {{HostIP: "127.0.0.1"}}},
+		},
+	}
+
+	var created createResponse
+	response, err := d.do("POST", "/containers/create", request, &created)
+	if isNotFound(response) {
+		err = d.pull()
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to pull image")
+		}
+		_, err = d.do("POST", "/containers/create", request, &created)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create container")
+	}
+	return created.ID, nil
+}
+
+// pull pulls the image of the provider. The Engine API streams the progress of the pull, so the response is
+// read to the end in order to wait for the pull to finish.
+func (d *docker) pull() error {
+	image, tag := d.image, "latest"
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image, tag = image[:i], image[i+1:]
+	}
+	query := url.Values{"fromImage": {image}, "tag": {tag}}
+	_, err := d.discard("POST", "/images/create?"+query.Encode(), nil)
+	return err
+}
+
+// Launch creates and starts a container that runs the project at the given URL. The port is the only option of
+// the deployment config that applies to containers.
+func (d *docker) Launch(projectURL string, owner provider.Owner, config *dao.DeployConfig) (*provider.Instance, error) {
+	id, err := d.create(projectURL, owner, config)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = d.discard("POST", "/containers/"+id+"/start", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to start container")
+	}
+	return &provider.Instance{ID: id, State: provider.Pending}, nil
+}
+
+// inspectResponse contains the fields of an inspect container response used by Describe.
+type inspectResponse struct {
+	ID     string `json:"Id"`
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	State struct {
+		Status    string    `json:"Status"`
+		ExitCode  int       `json:"ExitCode"`
+		StartedAt time.Time `json:"StartedAt"`
+	} `json:"State"`
+	NetworkSettings struct {
+		Ports map[string][]portBinding `json:"Ports"`
+	} `json:"NetworkSettings"`
+}
+
+// Describe returns the current state of the container with the given id, as described by toState. The URL of
+// the container is the host port to which the port of the project, which is read from its labels, is published.
+func (d *docker) Describe(instanceID string) (*provider.Instance, error) {
+	var inspected inspectResponse
+	_, err := d.do("GET", "/containers/"+url.PathEscape(instanceID)+"/json", nil, &inspected)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to inspect container")
+	}
+
+	instance := &provider.Instance{ID: inspected.ID, LaunchTime: inspected.State.StartedAt, Owner: owner(inspected.Config.Labels)}
+	port := defaultContainerPort
+	if labeled := inspected.Config.Labels[portLabel]; labeled != "" {
+		port = containerPort(labeled)
+	}
+	if bindings := inspected.NetworkSettings.Ports[port]; len(bindings) > 0 && bindings[0].HostPort != "" {
+		instance.URL = "localhost:" + bindings[0].HostPort
+	}
+	instance.State = toState(inspected.State.Status)
+	if instance.State == provider.Stopped {
+		instance.Reason = fmt.Sprintf("Container is %s with exit code %d", inspected.State.Status, inspected.State.ExitCode)
+	}
+	return instance, nil
+}
+
+// toState returns the provider state of a container with the given status. Created and restarting containers
+// are pending, running containers are running and all other containers are stopped.
+func toState(status string) string {
+	switch status {
+	case "created", "restarting":
+		return provider.Pending
+	case "running":
+		return provider.Running
+	}
+	return provider.Stopped
+}
+
+// owner returns the owner stored in the given container labels.
+func owner(labels map[string]string) provider.Owner {
+	return provider.Owner{Email: labels[ownerLabel], ProjectID: labels[projectLabel]}
+}
+
+// listResponse contains the fields of a container in a list containers response used by List.
+type listResponse struct {
+	ID      string            `json:"Id"`
+	Created int64             `json:"Created"`
+	State   string            `json:"State"`
+	Labels  map[string]string `json:"Labels"`
+}
+
+// List returns every container created by the provider, including containers that have exited, since they
+// are not removed until they are terminated. The exit code of exited containers is not included in their reason.
+func (d *docker) List() ([]*provider.Instance, error) {
+	query := url.Values{"all": {"true"}, "filters": {`{"label":["` + label + `=true"]}`}}
+	var containers []listResponse
+	_, err := d.do("GET", "/containers/json?"+query.Encode(), nil, &containers)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to list containers")
+	}
+
+	instances := make([]*provider.Instance, 0, len(containers))
+	for _, container := range containers {
+		instance := &provider.Instance{
+			ID:         container.ID,
+			State:      toState(container.State),
+			LaunchTime: time.Unix(container.Created, 0).UTC(),
+			Owner:      owner(container.Labels),
+		}
+		if instance.State == provider.Stopped {
+			instance.Reason = "Container is " + container.State
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// Terminate stops and removes the container with the given id. If the id is empty or the container does not
+// exist, Terminate does nothing.
+func (d *docker) Terminate(instanceID string) error {
+	if instanceID == "" {
+		return nil
+	}
+	response, err := d.discard("DELETE", "/containers/"+url.PathEscape(instanceID)+"?force=true", nil)
+	if isNotFound(response) {
+		return nil
+	}
+	return errors.Wrap(err, "Failed to remove container")
+}
+
+// Logs returns the last lines written by the container with the given id to its standard output and error.
+func (d *docker) Logs(instanceID string) (string, error) {
+	query := url.Values{"stdout": {"true"}, "stderr": {"true"}, "tail": {logLines}}
+	response, err := d.do("GET", "/containers/"+url.PathEscape(instanceID)+"/logs?"+query.Encode(), nil, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to get container logs")
+	}
+	defer response.Body.Close()
+
+	logs, err := demultiplex(response.Body)
+	return logs, errors.Wrap(err, "Failed to read container logs")
+}
+
+// demultiplex reads the log stream of a container created without a TTY, in which each chunk of output is
+// preceded by an 8-byte header whose last 4 bytes are the big-endian length of the chunk, and returns the
+// output of both streams in order.
+func demultiplex(reader io.Reader) (string, error) {
+	builder := &strings.Builder{}
+	header := make([]byte, 8)
+	for {
+		_, err := io.ReadFull(reader, header)
+		if err == io.EOF {
+			return builder.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		_, err = io.CopyN(builder, reader, size)
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
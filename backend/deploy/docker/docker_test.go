@@ -0,0 +1,429 @@
+package docker
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// call is a request expected by the fake Engine API along with the response it returns.
+type call struct {
+	method string
+	uri    string
+	body   string
+	status int
+	reply  string
+}
+
+// fakeEngine serves the given calls in order on a Unix socket and returns a provider connected to it. Requests
+// that do not match the next call fail the test.
+func fakeEngine(t *testing.T, calls []call) (*docker, func()) {
+	dir, err := ioutil.TempDir("", "docker")
+	if err != nil {
+		t.Fatalf("Error creating temp directory: %v", err)
+	}
+	socket := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Error listening on socket: %v", err)
+	}
+
+	next := 0
+	server := httptest.NewUnstartedServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if next >= len(calls) {
+			t.Errorf("Got unexpected request %s %s", r.Method, r.URL.RequestURI())
+			w.WriteHeader(nethttp.StatusInternalServerError)
+			return
+		}
+		want := calls[next]
+		next++
+		if r.Method != want.method || r.URL.RequestURI() != want.uri {
+			t.Errorf("Got request %s %s; want %s %s", r.Method, r.URL.RequestURI(), want.method, want.uri)
+		}
+		if want.body != "" && !equalJSON(string(body), want.body) {
+			t.Errorf("Got request body %s; want %s", body, want.body)
+		}
+		w.WriteHeader(want.status)
+		w.Write([]byte(want.reply))
+	}))
+	server.Listener = listener
+	server.Start()
+
+	return newDocker(socket, "node:14"), func() {
+		server.Close()
+		os.RemoveAll(dir)
+		if next != len(calls) {
+			t.Errorf("Got %d requests; want %d", next, len(calls))
+		}
+	}
+}
+
+// equalJSON returns true if the given strings encode the same JSON value.
+func equalJSON(lhs string, rhs string) bool {
+	var l, r interface{}
+	if json.Unmarshal([]byte(lhs), &l) != nil || json.Unmarshal([]byte(rhs), &r) != nil {
+		return false
+	}
+	return reflect.DeepEqual(l, r)
+}
+
+// frame returns a chunk of a multiplexed log stream for the given stream and output.
+func frame(stream byte, output string) string {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(output)))
+	return string(header) + output
+}
+
+// createBody returns the body of the request that creates a container listening on the given port.
+func createBody(port string) string {
+	return `{
+	"Image": "node:14",
+	"Cmd": ["sh", "-c", ` + mustJSON(script) + `],
+	"Env": ["PROJECT_URL=https://example.com/project.zip?a=1&b=2", "PROJECT_DIR=defaultProject", "PORT=` + port + `"],
+	"Labels": {"crudcreator": "true", "crudcreator.owner": "test@example.com", "crudcreator.project": "project", "crudcreator.port": "` + port + `"},
+	"ExposedPorts": {"` + port + `/tcp": {}},
+	"HostConfig": {"PortBindings": {"` + port + `/tcp": [{"HostIp": "127.0.0.1", "HostPort": ""}]}}
+}`
+}
+
+var testConfig = &dao.DeployConfig{Region: "us-east-1", InstanceType: "t2.micro", IngressCIDR: "0.0.0.0/0", Port: 80}
+
+func mustJSON(value string) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+var launchTests = []struct {
+	name         string
+	config       *dao.DeployConfig
+	calls        []call
+	wantInstance *provider.Instance
+	wantErr      error
+}{
+	{
+		name: "CreateFailure",
+		calls: []call{
+			{method: "POST", uri: "/containers/create", body: createBody("80"), status: 500, reply: `{"message": "engine failure"}`},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Docker Engine API returned status 500: engine failure"), "Failed to create container"),
+	},
+	{
+		name: "PullFailure",
+		calls: []call{
+			{method: "POST", uri: "/containers/create", body: createBody("80"), status: 404, reply: `{"message": "No such image: node:14"}`},
+			{method: "POST", uri: "/images/create?fromImage=node&tag=14", status: 500, reply: `{"message": "registry unavailable"}`},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Docker Engine API returned status 500: registry unavailable"), "Failed to pull image"),
+	},
+	{
+		name: "PullsMissingImage",
+		calls: []call{
+			{method: "POST", uri: "/containers/create", body: createBody("80"), status: 404, reply: `{"message": "No such image: node:14"}`},
+			{method: "POST", uri: "/images/create?fromImage=node&tag=14", status: 200, reply: `{"status": "Downloaded newer image for node:14"}`},
+			{method: "POST", uri: "/containers/create", body: createBody("80"), status: 201, reply: `{"Id": "container"}`},
+			{method: "POST", uri: "/containers/container/start", status: 204},
+		},
+		wantInstance: &provider.Instance{ID: "container", State: provider.Pending},
+	},
+	{
+		name: "StartFailure",
+		calls: []call{
+			{method: "POST", uri: "/containers/create", body: createBody("80"), status: 201, reply: `{"Id": "container"}`},
+			{method: "POST", uri: "/containers/container/start", status: 500, reply: `{"message": "port is already allocated"}`},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Docker Engine API returned status 500: port is already allocated"), "Failed to start container"),
+	},
+	{
+		name: "SuccessfulInvocation",
+		calls: []call{
+			{method: "POST", uri: "/containers/create", body: createBody("80"), status: 201, reply: `{"Id": "container"}`},
+			{method: "POST", uri: "/containers/container/start", status: 204},
+		},
+		wantInstance: &provider.Instance{ID: "container", State: provider.Pending},
+	},
+	{
+		name:   "ConfiguredPort",
+		config: &dao.DeployConfig{Region: "us-east-1", InstanceType: "t2.micro", IngressCIDR: "0.0.0.0/0", Port: 3000},
+		calls: []call{
+			{method: "POST", uri: "/containers/create", body: createBody("3000"), status: 201, reply: `{"Id": "container"}`},
+			{method: "POST", uri: "/containers/container/start", status: 204},
+		},
+		wantInstance: &provider.Instance{ID: "container", State: provider.Pending},
+	},
+}
+
+func TestLaunch(t *testing.T) {
+	for _, test := range launchTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			docker, done := fakeEngine(t, test.calls)
+			defer done()
+			config := test.config
+			if config == nil {
+				config = testConfig
+			}
+
+			// Execute
+			instance, err := docker.Launch("https://example.com/project.zip?a=1&b=2", testOwner, config)
+
+			// Verify
+			if !reflect.DeepEqual(instance, test.wantInstance) {
+				t.Errorf("Got instance %v; want %v", instance, test.wantInstance)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+var startedAt = time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+var testOwner = provider.Owner{Email: "test@example.com", ProjectID: "project"}
+
+var describeTests = []struct {
+	name         string
+	calls        []call
+	wantInstance *provider.Instance
+	wantErr      error
+}{
+	{
+		name: "MissingContainer",
+		calls: []call{
+			{method: "GET", uri: "/containers/container/json", status: 404, reply: `{"message": "No such container: container"}`},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Docker Engine API returned status 404: No such container: container"), "Failed to inspect container"),
+	},
+	{
+		name: "CreatedContainer",
+		calls: []call{
+			{method: "GET", uri: "/containers/container/json", status: 200, reply: `{"Id": "container", "State": {"Status": "created", "StartedAt": "0001-01-01T00:00:00Z"}, "NetworkSettings": {"Ports": {}}}`},
+		},
+		wantInstance: &provider.Instance{ID: "container", State: provider.Pending},
+	},
+	{
+		name: "RunningContainer",
+		calls: []call{
+			{method: "GET", uri: "/containers/container/json", status: 200, reply: `{"Id": "container", "Config": {"Labels": {"crudcreator": "true", "crudcreator.owner": "test@example.com", "crudcreator.project": "project"}}, "State": {"Status": "running", "StartedAt": "2020-06-01T12:00:00Z"}, "NetworkSettings": {"Ports": {"80/tcp": [{"HostIp": "127.0.0.1", "HostPort": "32768"}]}}}`},
+		},
+		wantInstance: &provider.Instance{ID: "container", URL: "localhost:32768", State: provider.Running, LaunchTime: startedAt, Owner: testOwner},
+	},
+	{
+		name: "ConfiguredPort",
+		calls: []call{
+			{method: "GET", uri: "/containers/container/json", status: 200, reply: `{"Id": "container", "Config": {"Labels": {"crudcreator": "true", "crudcreator.owner": "test@example.com", "crudcreator.project": "project", "crudcreator.port": "3000"}}, "State": {"Status": "running", "StartedAt": "2020-06-01T12:00:00Z"}, "NetworkSettings": {"Ports": {"3000/tcp": [{"HostIp": "127.0.0.1", "HostPort": "32769"}]}}}`},
+		},
+		wantInstance: &provider.Instance{ID: "container", URL: "localhost:32769", State: provider.Running, LaunchTime: startedAt, Owner: testOwner},
+	},
+	{
+		name: "ExitedContainer",
+		calls: []call{
+			{method: "GET", uri: "/containers/container/json", status: 200, reply: `{"Id": "container", "State": {"Status": "exited", "ExitCode": 8, "StartedAt": "2020-06-01T12:00:00Z"}, "NetworkSettings": {"Ports": {}}}`},
+		},
+		wantInstance: &provider.Instance{ID: "container", State: provider.Stopped, Reason: "Container is exited with exit code 8", LaunchTime: startedAt},
+	},
+}
+
+func TestDescribe(t *testing.T) {
+	for _, test := range describeTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			docker, done := fakeEngine(t, test.calls)
+			defer done()
+
+			// Execute
+			instance, err := docker.Describe("container")
+
+			// Verify
+			if !reflect.DeepEqual(instance, test.wantInstance) {
+				t.Errorf("Got instance %v; want %v", instance, test.wantInstance)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+var terminateTests = []struct {
+	name       string
+	instanceID string
+	calls      []call
+	wantErr    error
+}{
+	{
+		name: "EmptyID",
+	},
+	{
+		name:       "RemoveFailure",
+		instanceID: "container",
+		calls: []call{
+			{method: "DELETE", uri: "/containers/container?force=true", status: 500, reply: `{"message": "engine failure"}`},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Docker Engine API returned status 500: engine failure"), "Failed to remove container"),
+	},
+	{
+		name:       "MissingContainer",
+		instanceID: "container",
+		calls: []call{
+			{method: "DELETE", uri: "/containers/container?force=true", status: 404, reply: `{"message": "No such container: container"}`},
+		},
+	},
+	{
+		name:       "SuccessfulInvocation",
+		instanceID: "container",
+		calls: []call{
+			{method: "DELETE", uri: "/containers/container?force=true", status: 204},
+		},
+	},
+}
+
+func TestTerminate(t *testing.T) {
+	for _, test := range terminateTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			docker, done := fakeEngine(t, test.calls)
+			defer done()
+
+			// Execute
+			err := docker.Terminate(test.instanceID)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+var logsTests = []struct {
+	name     string
+	calls    []call
+	wantLogs string
+	wantErr  error
+}{
+	{
+		name: "LogsFailure",
+		calls: []call{
+			{method: "GET", uri: "/containers/container/logs?stderr=true&stdout=true&tail=200", status: 404, reply: `{"message": "No such container: container"}`},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Docker Engine API returned status 404: No such container: container"), "Failed to get container logs"),
+	},
+	{
+		name: "TruncatedStream",
+		calls: []call{
+			{method: "GET", uri: "/containers/container/logs?stderr=true&stdout=true&tail=200", status: 200, reply: frame(1, "Server started\n")[:12]},
+		},
+		wantErr: errors.Wrap(errors.NewServer("unexpected EOF"), "Failed to read container logs"),
+	},
+	{
+		name: "SuccessfulInvocation",
+		calls: []call{
+			{method: "GET", uri: "/containers/container/logs?stderr=true&stdout=true&tail=200", status: 200, reply: frame(1, "Lifting sails\n") + frame(2, "warn: no database\n") + frame(1, "Server started\n")},
+		},
+		wantLogs: "Lifting sails\nwarn: no database\nServer started\n",
+	},
+}
+
+func TestLogs(t *testing.T) {
+	for _, test := range logsTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			docker, done := fakeEngine(t, test.calls)
+			defer done()
+
+			// Execute
+			logs, err := docker.Logs("container")
+
+			// Verify
+			if logs != test.wantLogs {
+				t.Errorf("Got logs %q; want %q", logs, test.wantLogs)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+const listURI = "/containers/json?all=true&filters=%7B%22label%22%3A%5B%22crudcreator%3Dtrue%22%5D%7D"
+
+var listTests = []struct {
+	name          string
+	calls         []call
+	wantInstances []*provider.Instance
+	wantErr       error
+}{
+	{
+		name: "ListFailure",
+		calls: []call{
+			{method: "GET", uri: listURI, status: 500, reply: `{"message": "engine failure"}`},
+		},
+		wantErr: errors.Wrap(errors.NewServer("Docker Engine API returned status 500: engine failure"), "Failed to list containers"),
+	},
+	{
+		name: "NoContainers",
+		calls: []call{
+			{method: "GET", uri: listURI, status: 200, reply: `[]`},
+		},
+		wantInstances: []*provider.Instance{},
+	},
+	{
+		name: "SuccessfulInvocation",
+		calls: []call{
+			{method: "GET", uri: listURI, status: 200, reply: `[
+				{"Id": "running", "Created": 1591012800, "State": "running", "Labels": {"crudcreator": "true", "crudcreator.owner": "test@example.com", "crudcreator.project": "project"}},
+				{"Id": "exited", "Created": 1591012800, "State": "exited", "Labels": {"crudcreator": "true"}}
+			]`},
+		},
+		wantInstances: []*provider.Instance{
+			{ID: "running", State: provider.Running, LaunchTime: startedAt, Owner: testOwner},
+			{ID: "exited", State: provider.Stopped, Reason: "Container is exited", LaunchTime: startedAt},
+		},
+	},
+}
+
+func TestList(t *testing.T) {
+	for _, test := range listTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			docker, done := fakeEngine(t, test.calls)
+			defer done()
+
+			// Execute
+			instances, err := docker.List()
+
+			// Verify
+			if !reflect.DeepEqual(instances, test.wantInstances) {
+				t.Errorf("Got instances %v; want %v", instances, test.wantInstances)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	registered, err := provider.Lookup("docker")
+	if err != nil {
+		t.Fatalf("Got error looking up docker provider: %v", err)
+	}
+	if registered != Docker {
+		t.Errorf("Got provider %v; want Docker", registered)
+	}
+}
@@ -1,21 +1,33 @@
-// Package ec2 provides functions for interacting with EC2 instances on AWS.
+// Package ec2 provides functions for interacting with EC2 instances on AWS. It registers the EC2 deployment
+// provider, which runs each deployed project on its own EC2 instance.
 package ec2
 
 import (
 	"encoding/base64"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 	"github.com/jackstenglein/rest_api_creator/backend/log"
 )
 
+func init() {
+	provider.Register(EC2)
+}
+
 // deployer is an empty struct that acts as a collection of EC2 methods.
 type deployer struct{}
 
-// EC2 provides a high-level interface to perform AWS EC2 operations.
+// EC2 provides a high-level interface to perform AWS EC2 operations. It implements the provider.Provider
+// interface.
 var EC2 = deployer{}
 
 // service wraps the EC2 functions needed to provide functionality.
@@ -23,55 +35,118 @@ var EC2 = deployer{}
 type service interface {
 	AuthorizeSecurityGroupIngress(*ec2.AuthorizeSecurityGroupIngressInput) (*ec2.AuthorizeSecurityGroupIngressOutput, error)
 	CreateSecurityGroup(*ec2.CreateSecurityGroupInput) (*ec2.CreateSecurityGroupOutput, error)
-	CreateTags(*ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error)
+	DescribeImages(*ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error)
 	DescribeInstances(*ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error)
 	DescribeSecurityGroups(*ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error)
+	GetConsoleOutput(*ec2.GetConsoleOutputInput) (*ec2.GetConsoleOutputOutput, error)
 	RunInstances(*ec2.RunInstancesInput) (*ec2.Reservation, error)
 	TerminateInstances(*ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error)
 }
 
-// defaultSvc is the service that actually queries EC2 and should be used in deployment.
-var defaultSvc = ec2.New(session.New())
+// sess is the session shared by the services of every region.
+var sess = session.New()
 
-// svc is the service currently being used. The value of this variable should be changed only in unit tests
-// when performing dependency injection.
+// defaultSvc is the service that actually queries EC2 in the default region and should be used in deployment.
+var defaultSvc = ec2.New(sess)
+
+// svc is the service currently being used in the default region. The value of this variable should be changed
+// only in unit tests when performing dependency injection.
 var svc service = defaultSvc
 
+// newService returns the service used in the given region, other than the default region. The value of this
+// variable should be changed only in unit tests when performing dependency injection.
+var newService = func(region string) service {
+	return ec2.New(sess, aws.NewConfig().WithRegion(region))
+}
+
+// regionalService returns the service used in the given region. The empty region is the default region.
+func regionalService(region string) service {
+	if region == "" || region == provider.DefaultRegion {
+		return svc
+	}
+	return newService(region)
+}
+
 const (
 	// AuthorizeSecurityGroupIngress
-	ingressPort     = 80
-	ipProtocol      = "tcp"
-	ipRangeAnywhere = "0.0.0.0/0"
+	ipProtocol = "tcp"
 
 	// CreateSecurityGroup
-	securityGroupDescription = "Opens port 80 for use as a webserver. This security group was generated by CRUD Creator."
-	securityGroupName        = "crudcreator-webserver"
+	securityGroupName = "crudcreator-webserver"
 
 	// RunInstance
-	imageID      = "ami-0323c3dd2da7fb37d"
-	instanceType = "t2.micro"
+	imageID = "ami-0323c3dd2da7fb37d"
+
+	// DescribeImages
+	imageOwner = "amazon"
+	imageName  = "amzn2-ami-hvm-*-x86_64-gp2"
+
+	// Tags
+	nameTag    = "Name"
+	serverName = "CRUD Creator Server"
+	ownerTag   = "crudcreator:owner"
+	projectTag = "crudcreator:project"
+	portTag    = "crudcreator:port"
 )
 
-// addIngressRule adds an ingress rule to the default security group. The new rule allows all traffic
-// on port 80.
-func addIngressRule() error {
+// regionalID returns the ID of the given instance as seen by the users of the provider. Instances outside of
+// the default region are prefixed with their region, so that they can be found again from their ID alone.
+func regionalID(region string, instanceID string) string {
+	if region == "" || region == provider.DefaultRegion || instanceID == "" {
+		return instanceID
+	}
+	return region + ":" + instanceID
+}
+
+// parseID returns the region and EC2 instance ID of the given ID returned by regionalID.
+func parseID(id string) (string, string) {
+	if i := strings.Index(id, ":"); i >= 0 {
+		return id[:i], id[i+1:]
+	}
+	return provider.DefaultRegion, id
+}
+
+// withPort returns the given host followed by the given port, unless the host is empty or the port is 80.
+func withPort(host string, port string) string {
+	if host == "" || port == "" || port == "80" {
+		return host
+	}
+	return host + ":" + port
+}
+
+// securityGroup returns the name and description of the security group that allows the ingress of the given
+// deployment config. Deployments that allow all traffic on port 80 share the original CRUD Creator group.
+func securityGroup(config *dao.DeployConfig) (string, string) {
+	description := fmt.Sprintf("Opens port %d to %s for use as a webserver. This security group was generated by CRUD Creator.", config.Port, config.IngressCIDR)
+	if config.Port == provider.DefaultPort && config.IngressCIDR == provider.DefaultIngressCIDR {
+		return securityGroupName, description
+	}
+	suffix := strings.NewReplacer(".", "-", "/", "-").Replace(config.IngressCIDR)
+	return fmt.Sprintf("%s-%d-%s", securityGroupName, config.Port, suffix), description
+}
+
+// addIngressRule adds an ingress rule to the security group of the given deployment config. The new rule allows
+// traffic from the ingress CIDR of the config on the port of the config.
+func addIngressRule(svc service, config *dao.DeployConfig) error {
+	name, _ := securityGroup(config)
 	input := &ec2.AuthorizeSecurityGroupIngressInput{
-		CidrIp:     aws.String(ipRangeAnywhere),
-		FromPort:   aws.Int64(ingressPort),
-		GroupName:  aws.String(securityGroupName),
+		CidrIp:     aws.String(config.IngressCIDR),
+		FromPort:   aws.Int64(int64(config.Port)),
+		GroupName:  aws.String(name),
 		IpProtocol: aws.String(ipProtocol),
-		ToPort:     aws.Int64(ingressPort),
+		ToPort:     aws.Int64(int64(config.Port)),
 	}
 	log.Info("Making call to AuthorizeSecurityGroupIngress with input: ", input)
 	_, err := svc.AuthorizeSecurityGroupIngress(input)
 	return errors.Wrap(err, "Failed call to AuthorizeSecurityGroupIngress")
 }
 
-// createSecurityGroup creates a security group with the default name.
-func createSecurityGroup() error {
+// createSecurityGroup creates the security group of the given deployment config.
+func createSecurityGroup(svc service, config *dao.DeployConfig) error {
+	name, description := securityGroup(config)
 	input := &ec2.CreateSecurityGroupInput{
-		Description: aws.String(securityGroupDescription),
-		GroupName:   aws.String(securityGroupName),
+		Description: aws.String(description),
+		GroupName:   aws.String(name),
 	}
 	log.Info("Making call to CreateSecurityGroup with input: ", input)
 	_, err := svc.CreateSecurityGroup(input)
@@ -80,12 +155,13 @@ func createSecurityGroup() error {
 
 // DescribeInstance returns the instance with the given id.
 func (deployer) DescribeInstance(instanceID string) (*ec2.Instance, error) {
+	region, instanceID := parseID(instanceID)
 	input := &ec2.DescribeInstancesInput{
 		InstanceIds: []*string{aws.String(instanceID)},
 	}
 
 	log.Info("Making call to DescribeInstances with input:", input)
-	result, err := svc.DescribeInstances(input)
+	result, err := regionalService(region).DescribeInstances(input)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed call to DescribeInstances")
 	}
@@ -98,11 +174,12 @@ func (deployer) DescribeInstance(instanceID string) (*ec2.Instance, error) {
 	return result.Reservations[0].Instances[0], nil
 }
 
-// describeSecurityGroup returns the default CRUD security group, if it already exists. If it does not exist,
-// nil is returned.
-func describeSecurityGroup() (*ec2.SecurityGroup, error) {
+// describeSecurityGroup returns the security group of the given deployment config, if it already exists. If it
+// does not exist, nil is returned.
+func describeSecurityGroup(svc service, config *dao.DeployConfig) (*ec2.SecurityGroup, error) {
+	name, _ := securityGroup(config)
 	input := &ec2.DescribeSecurityGroupsInput{
-		GroupNames: []*string{aws.String(securityGroupName)},
+		GroupNames: []*string{aws.String(name)},
 	}
 	log.Info("Making call to DescribeSecurityGroups with input:", input)
 	output, err := svc.DescribeSecurityGroups(input)
@@ -127,52 +204,111 @@ func (deployer) GetPublicURL(instanceID string) (string, error) {
 	return *instance.PublicDnsName, nil
 }
 
-// LaunchInstance creates an EC2 instance that runs the default project. The EC2 instance will
-// download the project from the provided URL. If successful, LaunchInstance returns the new
-// instance's ID and the public URL of the instance.
-func (deployer) LaunchInstance(projectURL string) (string, string, error) {
+// image returns the ID of the image that instances are launched from in the given region. The default region
+// uses a fixed Amazon Linux 2 image, while other regions use the latest Amazon Linux 2 image published there.
+func image(svc service, region string) (string, error) {
+	if region == provider.DefaultRegion {
+		return imageID, nil
+	}
 
-	securityGroup, err := describeSecurityGroup()
+	input := &ec2.DescribeImagesInput{
+		Owners: []*string{aws.String(imageOwner)},
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("name"),
+				Values: []*string{aws.String(imageName)},
+			},
+			{
+				Name:   aws.String("state"),
+				Values: []*string{aws.String(ec2.ImageStateAvailable)},
+			},
+		},
+	}
+	log.Info("Making call to DescribeImages with input:", input)
+	output, err := svc.DescribeImages(input)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed call to DescribeImages")
+	}
+	if len(output.Images) == 0 {
+		return "", errors.NewServer("DescribeImages returned no images")
+	}
+
+	// Creation dates are ISO 8601 strings, so they sort chronologically
+	images := output.Images
+	sort.Slice(images, func(i, j int) bool {
+		return aws.StringValue(images[i].CreationDate) > aws.StringValue(images[j].CreationDate)
+	})
+	return aws.StringValue(images[0].ImageId), nil
+}
+
+// LaunchInstance creates an EC2 instance that runs the default project. The EC2 instance will
+// download the project from the provided URL and is tagged with the given owner. It is launched
+// with the options of the given deployment config, which must have been validated by
+// provider.ValidateConfig. If successful, LaunchInstance returns the new instance's ID and the
+// public URL of the instance.
+func (deployer) LaunchInstance(projectURL string, owner provider.Owner, config *dao.DeployConfig) (string, string, error) {
+	svc := regionalService(config.Region)
+
+	securityGroup, err := describeSecurityGroup(svc, config)
 	if err != nil {
 		return "", "", errors.Wrap(err, "Failed to get security group")
 	}
 
 	if securityGroup == nil {
-		err = createSecurityGroup()
+		err = createSecurityGroup(svc, config)
 		if err != nil {
 			return "", "", errors.Wrap(err, "Failed to create security group")
 		}
 	}
 
-	if shouldAddIngressRule(securityGroup) {
-		err = addIngressRule()
+	if shouldAddIngressRule(securityGroup, config) {
+		err = addIngressRule(svc, config)
 		if err != nil {
 			return "", "", errors.Wrap(err, "Failed to add ingress rule to security group")
 		}
 	}
 
-	instance, err := runInstance(projectURL)
+	ami, err := image(svc, config.Region)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Failed to get image")
+	}
+
+	instance, err := runInstance(svc, ami, projectURL, owner, config)
 	if err != nil {
 		return "", "", errors.Wrap(err, "Failed to run instance")
 	}
 
-	return *instance.InstanceId, *instance.PublicDnsName, nil
+	url := withPort(aws.StringValue(instance.PublicDnsName), strconv.Itoa(config.Port))
+	return regionalID(config.Region, *instance.InstanceId), url, nil
 }
 
-// runInstance creates a new EC2 instance with the default CRUD Creator security group and launches it.
-// If successful, it returns the new instance id and the public URL of the instance.
-func runInstance(projectURL string) (*ec2.Instance, error) {
-	userData := fmt.Sprintf(userDataTemplate, projectURL)
+// runInstance creates a new EC2 instance from the given image with the security group of the given deployment
+// config and launches it. The instance is tagged with the given owner as part of the launch, so that it can be
+// found by List even if the launch is never recorded. If successful, it returns the new instance.
+func runInstance(svc service, ami string, projectURL string, owner provider.Owner, config *dao.DeployConfig) (*ec2.Instance, error) {
+	userData, err := newUserData(projectURL, config.Port).render()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to render user data")
+	}
 	encUserData := base64.StdEncoding.EncodeToString([]byte(userData))
 
+	groupName, _ := securityGroup(config)
 	runInput := &ec2.RunInstancesInput{
-		ImageId:        aws.String(imageID),
-		InstanceType:   aws.String(instanceType),
+		ImageId:        aws.String(ami),
+		InstanceType:   aws.String(config.InstanceType),
 		MaxCount:       aws.Int64(1),
 		MinCount:       aws.Int64(1),
 		UserData:       aws.String(encUserData),
-		SecurityGroups: []*string{aws.String(securityGroupName)},
-		// KeyName:        aws.String("test-keypair.pem"), //TODO remove this field, added to connect to instances
+		SecurityGroups: []*string{aws.String(groupName)},
+		TagSpecifications: []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeInstance),
+				Tags:         tags(owner, config),
+			},
+		},
+	}
+	if config.KeyPair != "" {
+		runInput.KeyName = aws.String(config.KeyPair)
 	}
 	log.Info("Making call to RunInstances with input: ", runInput)
 
@@ -190,24 +326,23 @@ func runInstance(projectURL string) (*ec2.Instance, error) {
 		return nil, errors.NewServer("RunInstances did not return InstanceId")
 	}
 
-	tagInput := &ec2.CreateTagsInput{
-		Resources: []*string{instance.InstanceId},
-		Tags: []*ec2.Tag{
-			{
-				Key:   aws.String("Name"),
-				Value: aws.String("CRUD Creator Server"),
-			},
-		},
-	}
-	log.Info("Making call to CreateTags with input: ", tagInput)
-	_, err = svc.CreateTags(tagInput)
-
 	log.Info("Created instance: ", instance)
-	return instance, errors.Wrap(err, "Failed call to CreateTags")
+	return instance, nil
+}
+
+// tags returns the tags of an instance launched for the given owner with the given deployment config.
+func tags(owner provider.Owner, config *dao.DeployConfig) []*ec2.Tag {
+	return []*ec2.Tag{
+		{Key: aws.String(nameTag), Value: aws.String(serverName)},
+		{Key: aws.String(ownerTag), Value: aws.String(owner.Email)},
+		{Key: aws.String(projectTag), Value: aws.String(owner.ProjectID)},
+		{Key: aws.String(portTag), Value: aws.String(strconv.Itoa(config.Port))},
+	}
 }
 
-// shouldAddIngressRule checks whether the given security group needs to add the HTTP port 80 all traffic rule.
-func shouldAddIngressRule(group *ec2.SecurityGroup) bool {
+// shouldAddIngressRule checks whether the given security group needs to add the rule that allows the ingress of
+// the given deployment config.
+func shouldAddIngressRule(group *ec2.SecurityGroup, config *dao.DeployConfig) bool {
 	if group == nil {
 		return true
 	}
@@ -219,7 +354,7 @@ func shouldAddIngressRule(group *ec2.SecurityGroup) bool {
 	if *rule.IpProtocol != ipProtocol {
 		return true
 	}
-	if *rule.FromPort != ingressPort || *rule.ToPort != ingressPort {
+	if *rule.FromPort != int64(config.Port) || *rule.ToPort != int64(config.Port) {
 		return true
 	}
 	if len(rule.IpRanges) == 0 {
@@ -227,24 +362,181 @@ func shouldAddIngressRule(group *ec2.SecurityGroup) bool {
 	}
 
 	ipRange := rule.IpRanges[0]
-	if *ipRange.CidrIp != ipRangeAnywhere {
+	if *ipRange.CidrIp != config.IngressCIDR {
 		return true
 	}
 
 	return false
 }
 
+// instanceNotFound is the code of the error returned by EC2 for instances that do not exist, which includes
+// instances that were terminated long enough ago to be forgotten.
+const instanceNotFound = "InvalidInstanceID.NotFound"
+
+// TerminateInstance terminates the instance with the given id. If the id is empty or the instance does not exist,
+// TerminateInstance does nothing.
 func (deployer) TerminateInstance(instanceID string) error {
 	if instanceID == "" {
 		return nil
 	}
 
+	region, instanceID := parseID(instanceID)
 	input := &ec2.TerminateInstancesInput{
 		InstanceIds: []*string{aws.String(instanceID)},
 	}
 
 	log.Info("Making call to TerminateInstance with input: ", input)
-	result, err := svc.TerminateInstances(input)
+	result, err := regionalService(region).TerminateInstances(input)
 	log.Info("Got TerminateInstances result:", result)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == instanceNotFound {
+		return nil
+	}
 	return errors.Wrap(err, "Failed call to TerminateInstances")
 }
+
+// Name returns the name used to select the EC2 provider.
+func (deployer) Name() string {
+	return "ec2"
+}
+
+// toInstance converts the given EC2 instance in the given region to a provider.Instance. Instances that are
+// shutting down, stopping, stopped or terminated are all stopped, since a deployment never restarts its instance.
+// The owner of the instance and the port of its URL are read from its tags.
+func toInstance(region string, instance *ec2.Instance) *provider.Instance {
+	result := &provider.Instance{
+		ID:         regionalID(region, aws.StringValue(instance.InstanceId)),
+		State:      provider.Stopped,
+		LaunchTime: aws.TimeValue(instance.LaunchTime),
+	}
+	port := ""
+	for _, tag := range instance.Tags {
+		switch aws.StringValue(tag.Key) {
+		case ownerTag:
+			result.Owner.Email = aws.StringValue(tag.Value)
+		case projectTag:
+			result.Owner.ProjectID = aws.StringValue(tag.Value)
+		case portTag:
+			port = aws.StringValue(tag.Value)
+		}
+	}
+	result.URL = withPort(aws.StringValue(instance.PublicDnsName), port)
+
+	state := ""
+	if instance.State != nil {
+		state = aws.StringValue(instance.State.Name)
+	}
+	switch state {
+	case ec2.InstanceStateNamePending:
+		result.State = provider.Pending
+	case ec2.InstanceStateNameRunning:
+		result.State = provider.Running
+	default:
+		result.Reason = fmt.Sprintf("Instance is %s", state)
+	}
+	return result
+}
+
+// Launch creates an EC2 instance that runs the project at the given URL. See LaunchInstance.
+func (deployer) Launch(projectURL string, owner provider.Owner, config *dao.DeployConfig) (*provider.Instance, error) {
+	instanceID, url, err := EC2.LaunchInstance(projectURL, owner, config)
+	if err != nil {
+		return nil, err
+	}
+	return &provider.Instance{ID: instanceID, URL: url, State: provider.Pending}, nil
+}
+
+// Describe returns the current state of the instance with the given id.
+func (deployer) Describe(instanceID string) (*provider.Instance, error) {
+	instance, err := EC2.DescribeInstance(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	region, _ := parseID(instanceID)
+	return toInstance(region, instance), nil
+}
+
+// Terminate terminates the instance with the given id. See TerminateInstance.
+func (deployer) Terminate(instanceID string) error {
+	return EC2.TerminateInstance(instanceID)
+}
+
+// Logs returns the most recent console output of the instance with the given id, which includes the output of
+// the userdata script that installs and starts the project. EC2 only captures the output periodically, so it
+// is empty for the first few minutes after the launch.
+func (deployer) Logs(instanceID string) (string, error) {
+	region, instanceID := parseID(instanceID)
+	input := &ec2.GetConsoleOutputInput{
+		InstanceId: aws.String(instanceID),
+	}
+	log.Info("Making call to GetConsoleOutput with input: ", input)
+	output, err := regionalService(region).GetConsoleOutput(input)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed call to GetConsoleOutput")
+	}
+
+	logs, err := base64.StdEncoding.DecodeString(aws.StringValue(output.Output))
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to decode console output")
+	}
+	return string(logs), nil
+}
+
+// List returns every CRUD Creator instance in every supported region that is not shutting down or terminated.
+// Stopped instances are included, since their volumes are still billed.
+func (deployer) List() ([]*provider.Instance, error) {
+	regions := make([]string, 0, len(provider.Regions))
+	for region := range provider.Regions {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	var instances []*provider.Instance
+	for _, region := range regions {
+		regionInstances, err := list(region)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Failed to list instances in %s", region))
+		}
+		instances = append(instances, regionInstances...)
+	}
+	return instances, nil
+}
+
+// list returns every CRUD Creator instance in the given region that is not shutting down or terminated.
+func list(region string) ([]*provider.Instance, error) {
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("tag:" + nameTag),
+				Values: []*string{aws.String(serverName)},
+			},
+			{
+				Name: aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{
+					ec2.InstanceStateNamePending,
+					ec2.InstanceStateNameRunning,
+					ec2.InstanceStateNameStopping,
+					ec2.InstanceStateNameStopped,
+				}),
+			},
+		},
+	}
+
+	svc := regionalService(region)
+	var instances []*provider.Instance
+	for {
+		log.Info("Making call to DescribeInstances with input:", input)
+		result, err := svc.DescribeInstances(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed call to DescribeInstances")
+		}
+		for _, reservation := range result.Reservations {
+			for _, instance := range reservation.Instances {
+				instances = append(instances, toInstance(region, instance))
+			}
+		}
+		if aws.StringValue(result.NextToken) == "" {
+			return instances, nil
+		}
+		input.NextToken = result.NextToken
+	}
+}
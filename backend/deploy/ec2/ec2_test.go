@@ -2,18 +2,66 @@ package ec2
 
 import (
 	"encoding/base64"
-	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
 
 const testProjectURL = "testProjectURL"
 
-var testEncUserData = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf(userDataTemplate, testProjectURL)))
+// encodedUserData returns the encoded user data of an instance that runs the test project on the given port.
+func encodedUserData(port int) string {
+	userData, _ := newUserData(testProjectURL, port).render()
+	return base64.StdEncoding.EncodeToString([]byte(userData))
+}
+
+var testEncUserData = encodedUserData(80)
+
+var testConfig = &dao.DeployConfig{Region: "us-east-1", InstanceType: "t2.micro", IngressCIDR: "0.0.0.0/0", Port: 80}
+
+var testGroupDescription = "Opens port 80 to 0.0.0.0/0 for use as a webserver. This security group was generated by CRUD Creator."
+
+var testOwner = provider.Owner{Email: "test@example.com", ProjectID: "project"}
+
+var testTags = []*ec2.Tag{
+	{Key: aws.String("Name"), Value: aws.String("CRUD Creator Server")},
+	{Key: aws.String("crudcreator:owner"), Value: aws.String("test@example.com")},
+	{Key: aws.String("crudcreator:project"), Value: aws.String("project")},
+	{Key: aws.String("crudcreator:port"), Value: aws.String("80")},
+}
+
+// regionalConfig is a deployment config that changes every option.
+var regionalConfig = &dao.DeployConfig{Region: "eu-west-1", InstanceType: "t3.small", KeyPair: "my-key", IngressCIDR: "203.0.113.0/24", Port: 3000}
+
+const regionalGroupName = "crudcreator-webserver-3000-203-0-113-0-24"
+
+var regionalTags = []*ec2.Tag{
+	{Key: aws.String("Name"), Value: aws.String("CRUD Creator Server")},
+	{Key: aws.String("crudcreator:owner"), Value: aws.String("test@example.com")},
+	{Key: aws.String("crudcreator:project"), Value: aws.String("project")},
+	{Key: aws.String("crudcreator:port"), Value: aws.String("3000")},
+}
+
+var describeImagesInput = &ec2.DescribeImagesInput{
+	Owners: []*string{aws.String("amazon")},
+	Filters: []*ec2.Filter{
+		{
+			Name:   aws.String("name"),
+			Values: []*string{aws.String("amzn2-ami-hvm-*-x86_64-gp2")},
+		},
+		{
+			Name:   aws.String("state"),
+			Values: []*string{aws.String("available")},
+		},
+	},
+}
 
 type mockService struct {
 	addIngressRuleInput *ec2.AuthorizeSecurityGroupIngressInput
@@ -22,17 +70,25 @@ type mockService struct {
 	createSecurityGroupInput *ec2.CreateSecurityGroupInput
 	createSecurityGroupErr   error
 
-	createTagsInput *ec2.CreateTagsInput
-	createTagsErr   error
+	describeImagesInput  *ec2.DescribeImagesInput
+	describeImagesOutput *ec2.DescribeImagesOutput
+	describeImagesErr    error
 
 	describeInstanceInput  *ec2.DescribeInstancesInput
 	describeInstanceOutput *ec2.DescribeInstancesOutput
 	describeInstanceErr    error
 
+	listInputs  []*ec2.DescribeInstancesInput
+	listOutputs []*ec2.DescribeInstancesOutput
+
 	describeGroupInput  *ec2.DescribeSecurityGroupsInput
 	describeGroupOutput *ec2.DescribeSecurityGroupsOutput
 	describeGroupErr    error
 
+	consoleOutputInput  *ec2.GetConsoleOutputInput
+	consoleOutputOutput *ec2.GetConsoleOutputOutput
+	consoleOutputErr    error
+
 	runInstanceInput  *ec2.RunInstancesInput
 	runInstanceOutput *ec2.Reservation
 	runInstanceErr    error
@@ -56,14 +112,22 @@ func (mock *mockService) CreateSecurityGroup(input *ec2.CreateSecurityGroupInput
 	return nil, mock.createSecurityGroupErr
 }
 
-func (mock *mockService) CreateTags(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
-	if !reflect.DeepEqual(input, mock.createTagsInput) {
-		return nil, errors.NewServer("Incorrect input to CreateTags mock")
+func (mock *mockService) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	if !reflect.DeepEqual(input, mock.describeImagesInput) {
+		return nil, errors.NewServer("Incorrect input to DescribeImages mock")
 	}
-	return nil, mock.createTagsErr
+	return mock.describeImagesOutput, mock.describeImagesErr
 }
 
 func (mock *mockService) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	if len(mock.listInputs) > 0 {
+		want, output := mock.listInputs[0], mock.listOutputs[0]
+		mock.listInputs, mock.listOutputs = mock.listInputs[1:], mock.listOutputs[1:]
+		if !reflect.DeepEqual(input, want) {
+			return nil, errors.NewServer("Incorrect input to DescribeInstances mock")
+		}
+		return output, nil
+	}
 	if !reflect.DeepEqual(input, mock.describeInstanceInput) {
 		return nil, errors.NewServer("Incorrect input to DescribeInstances mock")
 	}
@@ -77,6 +141,13 @@ func (mock *mockService) DescribeSecurityGroups(input *ec2.DescribeSecurityGroup
 	return mock.describeGroupOutput, mock.describeGroupErr
 }
 
+func (mock *mockService) GetConsoleOutput(input *ec2.GetConsoleOutputInput) (*ec2.GetConsoleOutputOutput, error) {
+	if !reflect.DeepEqual(input, mock.consoleOutputInput) {
+		return nil, errors.NewServer("Incorrect input to GetConsoleOutput mock")
+	}
+	return mock.consoleOutputOutput, mock.consoleOutputErr
+}
+
 func (mock *mockService) RunInstances(input *ec2.RunInstancesInput) (*ec2.Reservation, error) {
 	if !reflect.DeepEqual(input, mock.runInstanceInput) {
 		return nil, errors.NewServer("Incorrect input to RunInstances mock")
@@ -286,6 +357,7 @@ func TestGetPublicURL(t *testing.T) {
 
 var launchInstanceTests = []struct {
 	name    string
+	config  *dao.DeployConfig
 	mock    *mockService
 	wantID  string
 	wantURL string
@@ -305,7 +377,7 @@ var launchInstanceTests = []struct {
 		name: "CreateSecurityGroupError",
 		mock: &mockService{
 			createSecurityGroupInput: &ec2.CreateSecurityGroupInput{
-				Description: aws.String(securityGroupDescription),
+				Description: aws.String(testGroupDescription),
 				GroupName:   aws.String(securityGroupName),
 			},
 			createSecurityGroupErr: errors.NewServer("EC2 failure"),
@@ -320,15 +392,15 @@ var launchInstanceTests = []struct {
 		name: "AuthorizeSecurityGroupIngressError",
 		mock: &mockService{
 			addIngressRuleInput: &ec2.AuthorizeSecurityGroupIngressInput{
-				CidrIp:     aws.String(ipRangeAnywhere),
-				FromPort:   aws.Int64(ingressPort),
+				CidrIp:     aws.String("0.0.0.0/0"),
+				FromPort:   aws.Int64(80),
 				GroupName:  aws.String(securityGroupName),
 				IpProtocol: aws.String(ipProtocol),
-				ToPort:     aws.Int64(ingressPort),
+				ToPort:     aws.Int64(80),
 			},
 			addIngressRuleErr: errors.NewServer("EC2 failure"),
 			createSecurityGroupInput: &ec2.CreateSecurityGroupInput{
-				Description: aws.String(securityGroupDescription),
+				Description: aws.String(testGroupDescription),
 				GroupName:   aws.String(securityGroupName),
 			},
 			describeGroupInput: &ec2.DescribeSecurityGroupsInput{
@@ -342,14 +414,14 @@ var launchInstanceTests = []struct {
 		name: "RunInstanceError",
 		mock: &mockService{
 			addIngressRuleInput: &ec2.AuthorizeSecurityGroupIngressInput{
-				CidrIp:     aws.String(ipRangeAnywhere),
-				FromPort:   aws.Int64(ingressPort),
+				CidrIp:     aws.String("0.0.0.0/0"),
+				FromPort:   aws.Int64(80),
 				GroupName:  aws.String(securityGroupName),
 				IpProtocol: aws.String(ipProtocol),
-				ToPort:     aws.Int64(ingressPort),
+				ToPort:     aws.Int64(80),
 			},
 			createSecurityGroupInput: &ec2.CreateSecurityGroupInput{
-				Description: aws.String(securityGroupDescription),
+				Description: aws.String(testGroupDescription),
 				GroupName:   aws.String(securityGroupName),
 			},
 			describeGroupInput: &ec2.DescribeSecurityGroupsInput{
@@ -358,11 +430,17 @@ var launchInstanceTests = []struct {
 			describeGroupOutput: &ec2.DescribeSecurityGroupsOutput{},
 			runInstanceInput: &ec2.RunInstancesInput{
 				ImageId:        aws.String(imageID),
-				InstanceType:   aws.String(instanceType),
+				InstanceType:   aws.String("t2.micro"),
 				MaxCount:       aws.Int64(1),
 				MinCount:       aws.Int64(1),
 				UserData:       aws.String(testEncUserData),
 				SecurityGroups: []*string{aws.String(securityGroupName)},
+				TagSpecifications: []*ec2.TagSpecification{
+					{
+						ResourceType: aws.String(ec2.ResourceTypeInstance),
+						Tags:         testTags,
+					},
+				},
 			},
 			runInstanceErr: errors.NewServer("EC2 failure"),
 		},
@@ -372,14 +450,14 @@ var launchInstanceTests = []struct {
 		name: "NoInstances",
 		mock: &mockService{
 			addIngressRuleInput: &ec2.AuthorizeSecurityGroupIngressInput{
-				CidrIp:     aws.String(ipRangeAnywhere),
-				FromPort:   aws.Int64(ingressPort),
+				CidrIp:     aws.String("0.0.0.0/0"),
+				FromPort:   aws.Int64(80),
 				GroupName:  aws.String(securityGroupName),
 				IpProtocol: aws.String(ipProtocol),
-				ToPort:     aws.Int64(ingressPort),
+				ToPort:     aws.Int64(80),
 			},
 			createSecurityGroupInput: &ec2.CreateSecurityGroupInput{
-				Description: aws.String(securityGroupDescription),
+				Description: aws.String(testGroupDescription),
 				GroupName:   aws.String(securityGroupName),
 			},
 			describeGroupInput: &ec2.DescribeSecurityGroupsInput{
@@ -388,11 +466,17 @@ var launchInstanceTests = []struct {
 			describeGroupOutput: &ec2.DescribeSecurityGroupsOutput{},
 			runInstanceInput: &ec2.RunInstancesInput{
 				ImageId:        aws.String(imageID),
-				InstanceType:   aws.String(instanceType),
+				InstanceType:   aws.String("t2.micro"),
 				MaxCount:       aws.Int64(1),
 				MinCount:       aws.Int64(1),
 				UserData:       aws.String(testEncUserData),
 				SecurityGroups: []*string{aws.String(securityGroupName)},
+				TagSpecifications: []*ec2.TagSpecification{
+					{
+						ResourceType: aws.String(ec2.ResourceTypeInstance),
+						Tags:         testTags,
+					},
+				},
 			},
 			runInstanceOutput: &ec2.Reservation{},
 		},
@@ -402,14 +486,14 @@ var launchInstanceTests = []struct {
 		name: "NilInstanceId",
 		mock: &mockService{
 			addIngressRuleInput: &ec2.AuthorizeSecurityGroupIngressInput{
-				CidrIp:     aws.String(ipRangeAnywhere),
-				FromPort:   aws.Int64(ingressPort),
+				CidrIp:     aws.String("0.0.0.0/0"),
+				FromPort:   aws.Int64(80),
 				GroupName:  aws.String(securityGroupName),
 				IpProtocol: aws.String(ipProtocol),
-				ToPort:     aws.Int64(ingressPort),
+				ToPort:     aws.Int64(80),
 			},
 			createSecurityGroupInput: &ec2.CreateSecurityGroupInput{
-				Description: aws.String(securityGroupDescription),
+				Description: aws.String(testGroupDescription),
 				GroupName:   aws.String(securityGroupName),
 			},
 			describeGroupInput: &ec2.DescribeSecurityGroupsInput{
@@ -418,11 +502,17 @@ var launchInstanceTests = []struct {
 			describeGroupOutput: &ec2.DescribeSecurityGroupsOutput{},
 			runInstanceInput: &ec2.RunInstancesInput{
 				ImageId:        aws.String(imageID),
-				InstanceType:   aws.String(instanceType),
+				InstanceType:   aws.String("t2.micro"),
 				MaxCount:       aws.Int64(1),
 				MinCount:       aws.Int64(1),
 				UserData:       aws.String(testEncUserData),
 				SecurityGroups: []*string{aws.String(securityGroupName)},
+				TagSpecifications: []*ec2.TagSpecification{
+					{
+						ResourceType: aws.String(ec2.ResourceTypeInstance),
+						Tags:         testTags,
+					},
+				},
 			},
 			runInstanceOutput: &ec2.Reservation{
 				Instances: []*ec2.Instance{
@@ -436,14 +526,14 @@ var launchInstanceTests = []struct {
 		name: "EmptyInstanceId",
 		mock: &mockService{
 			addIngressRuleInput: &ec2.AuthorizeSecurityGroupIngressInput{
-				CidrIp:     aws.String(ipRangeAnywhere),
-				FromPort:   aws.Int64(ingressPort),
+				CidrIp:     aws.String("0.0.0.0/0"),
+				FromPort:   aws.Int64(80),
 				GroupName:  aws.String(securityGroupName),
 				IpProtocol: aws.String(ipProtocol),
-				ToPort:     aws.Int64(ingressPort),
+				ToPort:     aws.Int64(80),
 			},
 			createSecurityGroupInput: &ec2.CreateSecurityGroupInput{
-				Description: aws.String(securityGroupDescription),
+				Description: aws.String(testGroupDescription),
 				GroupName:   aws.String(securityGroupName),
 			},
 			describeGroupInput: &ec2.DescribeSecurityGroupsInput{
@@ -452,11 +542,17 @@ var launchInstanceTests = []struct {
 			describeGroupOutput: &ec2.DescribeSecurityGroupsOutput{},
 			runInstanceInput: &ec2.RunInstancesInput{
 				ImageId:        aws.String(imageID),
-				InstanceType:   aws.String(instanceType),
+				InstanceType:   aws.String("t2.micro"),
 				MaxCount:       aws.Int64(1),
 				MinCount:       aws.Int64(1),
 				UserData:       aws.String(testEncUserData),
 				SecurityGroups: []*string{aws.String(securityGroupName)},
+				TagSpecifications: []*ec2.TagSpecification{
+					{
+						ResourceType: aws.String(ec2.ResourceTypeInstance),
+						Tags:         testTags,
+					},
+				},
 			},
 			runInstanceOutput: &ec2.Reservation{
 				Instances: []*ec2.Instance{
@@ -467,85 +563,88 @@ var launchInstanceTests = []struct {
 		wantErr: errors.Wrap(errors.NewServer("RunInstances did not return InstanceId"), "Failed to run instance"),
 	},
 	{
-		name: "CreateTagsError",
+		name: "SuccessfulInvocation",
 		mock: &mockService{
 			addIngressRuleInput: &ec2.AuthorizeSecurityGroupIngressInput{
-				CidrIp:     aws.String(ipRangeAnywhere),
-				FromPort:   aws.Int64(ingressPort),
+				CidrIp:     aws.String("0.0.0.0/0"),
+				FromPort:   aws.Int64(80),
 				GroupName:  aws.String(securityGroupName),
 				IpProtocol: aws.String(ipProtocol),
-				ToPort:     aws.Int64(ingressPort),
+				ToPort:     aws.Int64(80),
 			},
 			createSecurityGroupInput: &ec2.CreateSecurityGroupInput{
-				Description: aws.String(securityGroupDescription),
+				Description: aws.String(testGroupDescription),
 				GroupName:   aws.String(securityGroupName),
 			},
-			createTagsInput: &ec2.CreateTagsInput{
-				Resources: []*string{aws.String("instance")},
-				Tags: []*ec2.Tag{
-					{
-						Key:   aws.String("Name"),
-						Value: aws.String("CRUD Creator Server"),
-					},
-				},
-			},
-			createTagsErr: errors.NewServer("EC2 failure"),
 			describeGroupInput: &ec2.DescribeSecurityGroupsInput{
 				GroupNames: []*string{aws.String(securityGroupName)},
 			},
 			describeGroupOutput: &ec2.DescribeSecurityGroupsOutput{},
 			runInstanceInput: &ec2.RunInstancesInput{
 				ImageId:        aws.String(imageID),
-				InstanceType:   aws.String(instanceType),
+				InstanceType:   aws.String("t2.micro"),
 				MaxCount:       aws.Int64(1),
 				MinCount:       aws.Int64(1),
 				UserData:       aws.String(testEncUserData),
 				SecurityGroups: []*string{aws.String(securityGroupName)},
+				TagSpecifications: []*ec2.TagSpecification{
+					{
+						ResourceType: aws.String(ec2.ResourceTypeInstance),
+						Tags:         testTags,
+					},
+				},
 			},
 			runInstanceOutput: &ec2.Reservation{
 				Instances: []*ec2.Instance{
 					{
-						InstanceId: aws.String("instance"),
+						InstanceId:    aws.String("instance"),
+						PublicDnsName: aws.String("instance.example.com"),
 					},
 				},
 			},
 		},
-		wantErr: errors.Wrap(errors.Wrap(errors.NewServer("EC2 failure"), "Failed call to CreateTags"), "Failed to run instance"),
+		wantID:  "instance",
+		wantURL: "instance.example.com",
 	},
 	{
-		name: "SuccessfulInvocation",
+		name: "ExistingSecurityGroup",
 		mock: &mockService{
-			addIngressRuleInput: &ec2.AuthorizeSecurityGroupIngressInput{
-				CidrIp:     aws.String(ipRangeAnywhere),
-				FromPort:   aws.Int64(ingressPort),
-				GroupName:  aws.String(securityGroupName),
-				IpProtocol: aws.String(ipProtocol),
-				ToPort:     aws.Int64(ingressPort),
-			},
-			createSecurityGroupInput: &ec2.CreateSecurityGroupInput{
-				Description: aws.String(securityGroupDescription),
-				GroupName:   aws.String(securityGroupName),
+			describeGroupInput: &ec2.DescribeSecurityGroupsInput{
+				GroupNames: []*string{aws.String(securityGroupName)},
 			},
-			createTagsInput: &ec2.CreateTagsInput{
-				Resources: []*string{aws.String("instance")},
-				Tags: []*ec2.Tag{
+			describeGroupOutput: &ec2.DescribeSecurityGroupsOutput{
+				SecurityGroups: []*ec2.SecurityGroup{
 					{
-						Key:   aws.String("Name"),
-						Value: aws.String("CRUD Creator Server"),
+						Description: aws.String(testGroupDescription),
+						GroupName:   aws.String(securityGroupName),
+						IpPermissions: []*ec2.IpPermission{
+							{
+								IpProtocol: aws.String(ipProtocol),
+								FromPort:   aws.Int64(80),
+								ToPort:     aws.Int64(80),
+								IpRanges: []*ec2.IpRange{
+									{
+										CidrIp: aws.String("0.0.0.0/0"),
+									},
+								},
+							},
+						},
 					},
 				},
 			},
-			describeGroupInput: &ec2.DescribeSecurityGroupsInput{
-				GroupNames: []*string{aws.String(securityGroupName)},
-			},
-			describeGroupOutput: &ec2.DescribeSecurityGroupsOutput{},
 			runInstanceInput: &ec2.RunInstancesInput{
 				ImageId:        aws.String(imageID),
-				InstanceType:   aws.String(instanceType),
+				InstanceType:   aws.String("t2.micro"),
 				MaxCount:       aws.Int64(1),
 				MinCount:       aws.Int64(1),
 				UserData:       aws.String(testEncUserData),
 				SecurityGroups: []*string{aws.String(securityGroupName)},
+				TagSpecifications: []*ec2.TagSpecification{
+					{
+						ResourceType: aws.String(ec2.ResourceTypeInstance),
+						Tags:         testTags,
+					},
+				},
 			},
 			runInstanceOutput: &ec2.Reservation{
 				Instances: []*ec2.Instance{
@@ -560,47 +659,97 @@ var launchInstanceTests = []struct {
 		wantURL: "instance.example.com",
 	},
 	{
-		name: "ExistingSecurityGroup",
+		name:   "DescribeImagesError",
+		config: regionalConfig,
 		mock: &mockService{
-			createTagsInput: &ec2.CreateTagsInput{
-				Resources: []*string{aws.String("instance")},
-				Tags: []*ec2.Tag{
-					{
-						Key:   aws.String("Name"),
-						Value: aws.String("CRUD Creator Server"),
-					},
-				},
-			},
 			describeGroupInput: &ec2.DescribeSecurityGroupsInput{
-				GroupNames: []*string{aws.String(securityGroupName)},
+				GroupNames: []*string{aws.String(regionalGroupName)},
 			},
 			describeGroupOutput: &ec2.DescribeSecurityGroupsOutput{
 				SecurityGroups: []*ec2.SecurityGroup{
 					{
-						Description: aws.String(securityGroupDescription),
-						GroupName:   aws.String(securityGroupName),
+						GroupName: aws.String(regionalGroupName),
 						IpPermissions: []*ec2.IpPermission{
 							{
 								IpProtocol: aws.String(ipProtocol),
-								FromPort:   aws.Int64(ingressPort),
-								ToPort:     aws.Int64(ingressPort),
-								IpRanges: []*ec2.IpRange{
-									{
-										CidrIp: aws.String(ipRangeAnywhere),
-									},
-								},
+								FromPort:   aws.Int64(3000),
+								ToPort:     aws.Int64(3000),
+								IpRanges:   []*ec2.IpRange{{CidrIp: aws.String("203.0.113.0/24")}},
 							},
 						},
 					},
 				},
 			},
+			describeImagesInput: describeImagesInput,
+			describeImagesErr:   errors.NewServer("EC2 failure"),
+		},
+		wantErr: errors.Wrap(errors.Wrap(errors.NewServer("EC2 failure"), "Failed call to DescribeImages"), "Failed to get image"),
+	},
+	{
+		name:   "NoImages",
+		config: regionalConfig,
+		mock: &mockService{
+			describeGroupInput: &ec2.DescribeSecurityGroupsInput{
+				GroupNames: []*string{aws.String(regionalGroupName)},
+			},
+			describeGroupOutput: &ec2.DescribeSecurityGroupsOutput{},
+			createSecurityGroupInput: &ec2.CreateSecurityGroupInput{
+				Description: aws.String("Opens port 3000 to 203.0.113.0/24 for use as a webserver. This security group was generated by CRUD Creator."),
+				GroupName:   aws.String(regionalGroupName),
+			},
+			addIngressRuleInput: &ec2.AuthorizeSecurityGroupIngressInput{
+				CidrIp:     aws.String("203.0.113.0/24"),
+				FromPort:   aws.Int64(3000),
+				GroupName:  aws.String(regionalGroupName),
+				IpProtocol: aws.String(ipProtocol),
+				ToPort:     aws.Int64(3000),
+			},
+			describeImagesInput:  describeImagesInput,
+			describeImagesOutput: &ec2.DescribeImagesOutput{},
+		},
+		wantErr: errors.Wrap(errors.NewServer("DescribeImages returned no images"), "Failed to get image"),
+	},
+	{
+		name:   "RegionalInstance",
+		config: regionalConfig,
+		mock: &mockService{
+			describeGroupInput: &ec2.DescribeSecurityGroupsInput{
+				GroupNames: []*string{aws.String(regionalGroupName)},
+			},
+			describeGroupOutput: &ec2.DescribeSecurityGroupsOutput{},
+			createSecurityGroupInput: &ec2.CreateSecurityGroupInput{
+				Description: aws.String("Opens port 3000 to 203.0.113.0/24 for use as a webserver. This security group was generated by CRUD Creator."),
+				GroupName:   aws.String(regionalGroupName),
+			},
+			addIngressRuleInput: &ec2.AuthorizeSecurityGroupIngressInput{
+				CidrIp:     aws.String("203.0.113.0/24"),
+				FromPort:   aws.Int64(3000),
+				GroupName:  aws.String(regionalGroupName),
+				IpProtocol: aws.String(ipProtocol),
+				ToPort:     aws.Int64(3000),
+			},
+			describeImagesInput: describeImagesInput,
+			describeImagesOutput: &ec2.DescribeImagesOutput{
+				Images: []*ec2.Image{
+					{ImageId: aws.String("ami-old"), CreationDate: aws.String("2020-03-01T00:00:00.000Z")},
+					{ImageId: aws.String("ami-new"), CreationDate: aws.String("2020-05-01T00:00:00.000Z")},
+					{ImageId: aws.String("ami-older"), CreationDate: aws.String("2020-01-01T00:00:00.000Z")},
+				},
+			},
 			runInstanceInput: &ec2.RunInstancesInput{
-				ImageId:        aws.String(imageID),
-				InstanceType:   aws.String(instanceType),
+				ImageId:        aws.String("ami-new"),
+				InstanceType:   aws.String("t3.small"),
+				KeyName:        aws.String("my-key"),
 				MaxCount:       aws.Int64(1),
 				MinCount:       aws.Int64(1),
-				UserData:       aws.String(testEncUserData),
-				SecurityGroups: []*string{aws.String(securityGroupName)},
+				UserData:       aws.String(encodedUserData(3000)),
+				SecurityGroups: []*string{aws.String(regionalGroupName)},
+				TagSpecifications: []*ec2.TagSpecification{
+					{
+						ResourceType: aws.String(ec2.ResourceTypeInstance),
+						Tags:         regionalTags,
+					},
+				},
 			},
 			runInstanceOutput: &ec2.Reservation{
 				Instances: []*ec2.Instance{
@@ -611,8 +760,8 @@ var launchInstanceTests = []struct {
 				},
 			},
 		},
-		wantID:  "instance",
-		wantURL: "instance.example.com",
+		wantID:  "eu-west-1:instance",
+		wantURL: "instance.example.com:3000",
 	},
 }
 
@@ -625,8 +774,22 @@ func TestLaunchInstance(t *testing.T) {
 				svc = defaultSvc
 			}()
 
+			config := test.config
+			if config == nil {
+				config = testConfig
+			}
+			newService = func(region string) service {
+				if region != config.Region {
+					return &mockService{}
+				}
+				return test.mock
+			}
+			defer func() {
+				newService = defaultNewService
+			}()
+
 			// Execute
-			id, url, err := EC2.LaunchInstance(testProjectURL)
+			id, url, err := EC2.LaunchInstance(testProjectURL, testOwner, config)
 
 			// Verify
 			if id != test.wantID {
@@ -643,9 +806,10 @@ func TestLaunchInstance(t *testing.T) {
 }
 
 var shouldAddTests = []struct {
-	name  string
-	group *ec2.SecurityGroup
-	want  bool
+	name   string
+	group  *ec2.SecurityGroup
+	config *dao.DeployConfig
+	want   bool
 }{
 	{
 		name: "NilGroup",
@@ -672,7 +836,7 @@ var shouldAddTests = []struct {
 				&ec2.IpPermission{
 					IpProtocol: aws.String("tcp"),
 					FromPort:   aws.Int64(1337),
-					ToPort:     aws.Int64(ingressPort),
+					ToPort:     aws.Int64(80),
 				},
 			},
 		},
@@ -684,7 +848,7 @@ var shouldAddTests = []struct {
 			IpPermissions: []*ec2.IpPermission{
 				&ec2.IpPermission{
 					IpProtocol: aws.String("tcp"),
-					FromPort:   aws.Int64(ingressPort),
+					FromPort:   aws.Int64(80),
 					ToPort:     aws.Int64(1337),
 				},
 			},
@@ -697,8 +861,8 @@ var shouldAddTests = []struct {
 			IpPermissions: []*ec2.IpPermission{
 				&ec2.IpPermission{
 					IpProtocol: aws.String("tcp"),
-					FromPort:   aws.Int64(ingressPort),
-					ToPort:     aws.Int64(ingressPort),
+					FromPort:   aws.Int64(80),
+					ToPort:     aws.Int64(80),
 				},
 			},
 		},
@@ -710,8 +874,8 @@ var shouldAddTests = []struct {
 			IpPermissions: []*ec2.IpPermission{
 				&ec2.IpPermission{
 					IpProtocol: aws.String("tcp"),
-					FromPort:   aws.Int64(ingressPort),
-					ToPort:     aws.Int64(ingressPort),
+					FromPort:   aws.Int64(80),
+					ToPort:     aws.Int64(80),
 					IpRanges: []*ec2.IpRange{
 						&ec2.IpRange{
 							CidrIp: aws.String("127.0.0.0/0"),
@@ -728,11 +892,11 @@ var shouldAddTests = []struct {
 			IpPermissions: []*ec2.IpPermission{
 				&ec2.IpPermission{
 					IpProtocol: aws.String("tcp"),
-					FromPort:   aws.Int64(ingressPort),
-					ToPort:     aws.Int64(ingressPort),
+					FromPort:   aws.Int64(80),
+					ToPort:     aws.Int64(80),
 					IpRanges: []*ec2.IpRange{
 						&ec2.IpRange{
-							CidrIp: aws.String(ipRangeAnywhere),
+							CidrIp: aws.String("0.0.0.0/0"),
 						},
 					},
 				},
@@ -740,13 +904,38 @@ var shouldAddTests = []struct {
 		},
 		want: false,
 	},
+	{
+		name: "OtherConfig",
+		group: &ec2.SecurityGroup{
+			IpPermissions: []*ec2.IpPermission{
+				&ec2.IpPermission{
+					IpProtocol: aws.String("tcp"),
+					FromPort:   aws.Int64(80),
+					ToPort:     aws.Int64(80),
+					IpRanges: []*ec2.IpRange{
+						&ec2.IpRange{
+							CidrIp: aws.String("0.0.0.0/0"),
+						},
+					},
+				},
+			},
+		},
+		config: regionalConfig,
+		want:   true,
+	},
 }
 
 func TestShouldAddIngressRule(t *testing.T) {
 	for _, test := range shouldAddTests {
 		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			config := test.config
+			if config == nil {
+				config = testConfig
+			}
+
 			// Execute
-			got := shouldAddIngressRule(test.group)
+			got := shouldAddIngressRule(test.group, config)
 
 			// Verify
 			if got != test.want {
@@ -760,6 +949,7 @@ var terminateInstanceTests = []struct {
 	name       string
 	mock       *mockService
 	instanceID string
+	regional   bool
 	wantErr    error
 }{
 	{
@@ -776,6 +966,16 @@ var terminateInstanceTests = []struct {
 		},
 		wantErr: errors.Wrap(errors.NewServer("EC2 service error"), "Failed call to TerminateInstances"),
 	},
+	{
+		name:       "MissingInstance",
+		instanceID: "testInstanceID",
+		mock: &mockService{
+			terminateInstancesInput: &ec2.TerminateInstancesInput{
+				InstanceIds: []*string{aws.String("testInstanceID")},
+			},
+			terminateInstancesErr: awserr.New("InvalidInstanceID.NotFound", "The instance ID 'testInstanceID' does not exist", nil),
+		},
+	},
 	{
 		name:       "SuccessfulInvocation",
 		instanceID: "testInstanceID",
@@ -786,21 +986,450 @@ var terminateInstanceTests = []struct {
 		},
 		wantErr: nil,
 	},
+	{
+		name:       "RegionalInstance",
+		instanceID: "eu-west-1:testInstanceID",
+		regional:   true,
+		mock: &mockService{
+			terminateInstancesInput: &ec2.TerminateInstancesInput{
+				InstanceIds: []*string{aws.String("testInstanceID")},
+			},
+		},
+	},
 }
 
 func TestTerminateInstance(t *testing.T) {
 	for _, test := range terminateInstanceTests {
 		t.Run(test.name, func(t *testing.T) {
+			svc, newService = test.mock, regionalMock(test.regional, "eu-west-1", test.mock)
+			defer func() {
+				svc, newService = defaultSvc, defaultNewService
+			}()
+			if test.regional {
+				svc = &mockService{}
+			}
+
+			err := EC2.TerminateInstance(test.instanceID)
+
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err `%v`; want `%v`", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func describeMock(state string) *mockService {
+	return &mockService{
+		describeInstanceInput: &ec2.DescribeInstancesInput{
+			InstanceIds: []*string{aws.String("instance")},
+		},
+		describeInstanceOutput: &ec2.DescribeInstancesOutput{
+			Reservations: []*ec2.Reservation{
+				{
+					Instances: []*ec2.Instance{
+						{
+							InstanceId:    aws.String("instance"),
+							LaunchTime:    aws.Time(time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)),
+							PublicDnsName: aws.String("instance.example.com"),
+							State:         &ec2.InstanceState{Name: aws.String(state)},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var describeTests = []struct {
+	name         string
+	instanceID   string
+	regional     bool
+	mock         *mockService
+	wantInstance *provider.Instance
+	wantErr      error
+}{
+	{
+		name: "ServiceError",
+		mock: &mockService{
+			describeInstanceInput: &ec2.DescribeInstancesInput{
+				InstanceIds: []*string{aws.String("instance")},
+			},
+			describeInstanceErr: errors.NewServer("EC2 failure"),
+		},
+		wantErr: errors.Wrap(errors.NewServer("EC2 failure"), "Failed call to DescribeInstances"),
+	},
+	{
+		name: "PendingInstance",
+		mock: describeMock(ec2.InstanceStateNamePending),
+		wantInstance: &provider.Instance{
+			ID:         "instance",
+			URL:        "instance.example.com",
+			State:      provider.Pending,
+			LaunchTime: time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC),
+		},
+	},
+	{
+		name: "RunningInstance",
+		mock: describeMock(ec2.InstanceStateNameRunning),
+		wantInstance: &provider.Instance{
+			ID:         "instance",
+			URL:        "instance.example.com",
+			State:      provider.Running,
+			LaunchTime: time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC),
+		},
+	},
+	{
+		name: "TerminatedInstance",
+		mock: describeMock(ec2.InstanceStateNameTerminated),
+		wantInstance: &provider.Instance{
+			ID:         "instance",
+			URL:        "instance.example.com",
+			State:      provider.Stopped,
+			Reason:     "Instance is terminated",
+			LaunchTime: time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC),
+		},
+	},
+	{
+		name:       "RegionalInstance",
+		instanceID: "eu-west-1:instance",
+		regional:   true,
+		mock: &mockService{
+			describeInstanceInput: &ec2.DescribeInstancesInput{
+				InstanceIds: []*string{aws.String("instance")},
+			},
+			describeInstanceOutput: &ec2.DescribeInstancesOutput{
+				Reservations: []*ec2.Reservation{
+					{
+						Instances: []*ec2.Instance{
+							{
+								InstanceId:    aws.String("instance"),
+								LaunchTime:    aws.Time(time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)),
+								PublicDnsName: aws.String("instance.example.com"),
+								State:         &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+								Tags:          regionalTags,
+							},
+						},
+					},
+				},
+			},
+		},
+		wantInstance: &provider.Instance{
+			ID:         "eu-west-1:instance",
+			URL:        "instance.example.com:3000",
+			State:      provider.Running,
+			LaunchTime: time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC),
+			Owner:      testOwner,
+		},
+	},
+}
+
+func TestDescribe(t *testing.T) {
+	for _, test := range describeTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			svc, newService = test.mock, regionalMock(test.regional, "eu-west-1", test.mock)
+			defer func() {
+				svc, newService = defaultSvc, defaultNewService
+			}()
+			if test.regional {
+				svc = &mockService{}
+			}
+			instanceID := test.instanceID
+			if instanceID == "" {
+				instanceID = "instance"
+			}
+
+			// Execute
+			instance, err := EC2.Describe(instanceID)
+
+			// Verify
+			if !reflect.DeepEqual(instance, test.wantInstance) {
+				t.Errorf("Got instance %v; want %v", instance, test.wantInstance)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err `%v`; want `%v`", err, test.wantErr)
+			}
+		})
+	}
+}
+
+var logsTests = []struct {
+	name     string
+	mock     *mockService
+	wantLogs string
+	wantErr  error
+}{
+	{
+		name: "ServiceError",
+		mock: &mockService{
+			consoleOutputInput: &ec2.GetConsoleOutputInput{InstanceId: aws.String("instance")},
+			consoleOutputErr:   errors.NewServer("EC2 failure"),
+		},
+		wantErr: errors.Wrap(errors.NewServer("EC2 failure"), "Failed call to GetConsoleOutput"),
+	},
+	{
+		name: "InvalidOutput",
+		mock: &mockService{
+			consoleOutputInput:  &ec2.GetConsoleOutputInput{InstanceId: aws.String("instance")},
+			consoleOutputOutput: &ec2.GetConsoleOutputOutput{Output: aws.String("not base64!")},
+		},
+		wantErr: errors.Wrap(base64.CorruptInputError(3), "Failed to decode console output"),
+	},
+	{
+		name: "EmptyOutput",
+		mock: &mockService{
+			consoleOutputInput:  &ec2.GetConsoleOutputInput{InstanceId: aws.String("instance")},
+			consoleOutputOutput: &ec2.GetConsoleOutputOutput{},
+		},
+	},
+	{
+		name: "SuccessfulInvocation",
+		mock: &mockService{
+			consoleOutputInput:  &ec2.GetConsoleOutputInput{InstanceId: aws.String("instance")},
+			consoleOutputOutput: &ec2.GetConsoleOutputOutput{Output: aws.String(base64.StdEncoding.EncodeToString([]byte("Server started\n")))},
+		},
+		wantLogs: "Server started\n",
+	},
+}
+
+func TestLogs(t *testing.T) {
+	for _, test := range logsTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
 			svc = test.mock
 			defer func() {
 				svc = defaultSvc
 			}()
 
-			err := EC2.TerminateInstance(test.instanceID)
+			// Execute
+			logs, err := EC2.Logs("instance")
 
+			// Verify
+			if logs != test.wantLogs {
+				t.Errorf("Got logs `%s`; want `%s`", logs, test.wantLogs)
+			}
 			if !errors.Equal(err, test.wantErr) {
 				t.Errorf("Got err `%v`; want `%v`", err, test.wantErr)
 			}
 		})
 	}
 }
+
+func listInput(nextToken *string) *ec2.DescribeInstancesInput {
+	return &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("tag:Name"),
+				Values: []*string{aws.String("CRUD Creator Server")},
+			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+			},
+		},
+		NextToken: nextToken,
+	}
+}
+
+// emptyRegion returns a service for a region without CRUD Creator instances.
+func emptyRegion() *mockService {
+	return &mockService{
+		listInputs:  []*ec2.DescribeInstancesInput{listInput(nil)},
+		listOutputs: []*ec2.DescribeInstancesOutput{{}},
+	}
+}
+
+var listTests = []struct {
+	name          string
+	mock          *mockService
+	regions       map[string]*mockService
+	wantInstances []*provider.Instance
+	wantErr       error
+}{
+	{
+		name: "ServiceError",
+		mock: &mockService{
+			describeInstanceInput: listInput(nil),
+			describeInstanceErr:   errors.NewServer("EC2 failure"),
+		},
+		wantErr: errors.Wrap(errors.Wrap(errors.NewServer("EC2 failure"), "Failed call to DescribeInstances"), "Failed to list instances in us-east-1"),
+	},
+	{
+		name: "NoInstances",
+		mock: &mockService{
+			listInputs:  []*ec2.DescribeInstancesInput{listInput(nil)},
+			listOutputs: []*ec2.DescribeInstancesOutput{{}},
+		},
+	},
+	{
+		name: "MultiplePages",
+		mock: &mockService{
+			listInputs: []*ec2.DescribeInstancesInput{listInput(nil), listInput(aws.String("page2"))},
+			listOutputs: []*ec2.DescribeInstancesOutput{
+				{
+					Reservations: []*ec2.Reservation{
+						{
+							Instances: []*ec2.Instance{
+								{
+									InstanceId: aws.String("instance1"),
+									LaunchTime: aws.Time(time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)),
+									State:      &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+									Tags:       testTags,
+								},
+							},
+						},
+					},
+					NextToken: aws.String("page2"),
+				},
+				{
+					Reservations: []*ec2.Reservation{
+						{
+							Instances: []*ec2.Instance{
+								{
+									InstanceId: aws.String("instance2"),
+									LaunchTime: aws.Time(time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)),
+									State:      &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameStopped)},
+									Tags:       []*ec2.Tag{{Key: aws.String("Name"), Value: aws.String("CRUD Creator Server")}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		wantInstances: []*provider.Instance{
+			{
+				ID:         "instance1",
+				State:      provider.Running,
+				LaunchTime: time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC),
+				Owner:      testOwner,
+			},
+			{
+				ID:         "instance2",
+				State:      provider.Stopped,
+				Reason:     "Instance is stopped",
+				LaunchTime: time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+	},
+	{
+		name: "MultipleRegions",
+		mock: emptyRegion(),
+		regions: map[string]*mockService{
+			"eu-west-1": {
+				listInputs: []*ec2.DescribeInstancesInput{listInput(nil)},
+				listOutputs: []*ec2.DescribeInstancesOutput{
+					{
+						Reservations: []*ec2.Reservation{
+							{
+								Instances: []*ec2.Instance{
+									{
+										InstanceId:    aws.String("instance"),
+										LaunchTime:    aws.Time(time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)),
+										PublicDnsName: aws.String("instance.example.com"),
+										State:         &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+										Tags:          regionalTags,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"us-west-2": {
+				describeInstanceInput: listInput(nil),
+				describeInstanceErr:   errors.NewServer("EC2 failure"),
+			},
+		},
+		wantErr: errors.Wrap(errors.Wrap(errors.NewServer("EC2 failure"), "Failed call to DescribeInstances"), "Failed to list instances in us-west-2"),
+	},
+	{
+		name: "OtherRegion",
+		mock: emptyRegion(),
+		regions: map[string]*mockService{
+			"eu-west-1": {
+				listInputs: []*ec2.DescribeInstancesInput{listInput(nil)},
+				listOutputs: []*ec2.DescribeInstancesOutput{
+					{
+						Reservations: []*ec2.Reservation{
+							{
+								Instances: []*ec2.Instance{
+									{
+										InstanceId:    aws.String("instance"),
+										LaunchTime:    aws.Time(time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)),
+										PublicDnsName: aws.String("instance.example.com"),
+										State:         &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+										Tags:          regionalTags,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		wantInstances: []*provider.Instance{
+			{
+				ID:         "eu-west-1:instance",
+				URL:        "instance.example.com:3000",
+				State:      provider.Running,
+				LaunchTime: time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC),
+				Owner:      testOwner,
+			},
+		},
+	},
+}
+
+func TestList(t *testing.T) {
+	for _, test := range listTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			svc = test.mock
+			newService = func(region string) service {
+				if mock, ok := test.regions[region]; ok {
+					return mock
+				}
+				return emptyRegion()
+			}
+			defer func() {
+				svc, newService = defaultSvc, defaultNewService
+			}()
+
+			// Execute
+			instances, err := EC2.List()
+
+			// Verify
+			if !reflect.DeepEqual(instances, test.wantInstances) {
+				t.Errorf("Got instances %v; want %v", instances, test.wantInstances)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err `%v`; want `%v`", err, test.wantErr)
+			}
+			if len(test.mock.listInputs) > 0 {
+				t.Errorf("Got %d fewer DescribeInstances calls than expected", len(test.mock.listInputs))
+			}
+		})
+	}
+}
+
+// regionalMock returns a newService function that returns mock in the given region if regional is set.
+func regionalMock(regional bool, region string, mock *mockService) func(string) service {
+	return func(name string) service {
+		if !regional || name != region {
+			return &mockService{}
+		}
+		return mock
+	}
+}
+
+var defaultNewService = newService
+
+func TestRegistered(t *testing.T) {
+	registered, err := provider.Lookup("ec2")
+	if err != nil {
+		t.Fatalf("Got error looking up ec2 provider: %v", err)
+	}
+	if registered != EC2 {
+		t.Errorf("Got provider %v; want EC2", registered)
+	}
+}
@@ -1,6 +1,105 @@
 package ec2
 
-const userDataTemplate = `Content-Type: multipart/mixed; boundary="//"
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// defaultNodeVersion is the major version of Node.js installed on instances.
+const defaultNodeVersion = "14"
+
+// userData contains the values interpolated into the user data of an instance.
+type userData struct {
+	// NodeVersion is the major version of Node.js to install, such as "14".
+	NodeVersion string
+
+	// ArtifactURL is the URL from which the zipped project is downloaded.
+	ArtifactURL string
+
+	// ProjectDir is the directory of the project inside the zip, which contains app.js.
+	ProjectDir string
+
+	// Port is the port on which the project listens.
+	Port int
+
+	// Env contains additional environment variables of the project, by name.
+	Env map[string]string
+}
+
+// newUserData returns the user data of an instance that runs the project at the given URL on the given port.
+func newUserData(artifactURL string, port int) userData {
+	return userData{
+		NodeVersion: defaultNodeVersion,
+		ArtifactURL: artifactURL,
+		ProjectDir:  codegen.TemplateRoot,
+		Port:        port,
+	}
+}
+
+var (
+	nodeVersionPattern = regexp.MustCompile(`^[0-9]{1,3}$`)
+	envNamePattern     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// printable returns true if the given value only contains printable ASCII characters. Other characters could
+// break the 7bit MIME parts of the user data, for example by starting a new line with the boundary.
+func printable(value string) bool {
+	for _, char := range value {
+		if char < ' ' || char > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// validate returns a server error if the given user data cannot be rendered safely.
+func (data userData) validate() error {
+	if !nodeVersionPattern.MatchString(data.NodeVersion) {
+		return errors.NewServer(fmt.Sprintf("Invalid Node.js version `%s`", data.NodeVersion))
+	}
+	if data.ArtifactURL == "" || !printable(data.ArtifactURL) {
+		return errors.NewServer("Invalid artifact URL")
+	}
+	if data.ProjectDir == "" || !printable(data.ProjectDir) {
+		return errors.NewServer(fmt.Sprintf("Invalid project directory `%s`", data.ProjectDir))
+	}
+	if data.Port < 1 || data.Port > 65535 {
+		return errors.NewServer(fmt.Sprintf("Invalid port %d", data.Port))
+	}
+
+	names := make([]string, 0, len(data.Env))
+	for name := range data.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !envNamePattern.MatchString(name) || name == "PORT" {
+			return errors.NewServer(fmt.Sprintf("Invalid environment variable name `%s`", name))
+		}
+		if !printable(data.Env[name]) {
+			return errors.NewServer(fmt.Sprintf("Invalid value of environment variable `%s`", name))
+		}
+	}
+	return nil
+}
+
+// shellQuote returns the given value as a single-quoted shell word, in which no character is special.
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// userDataTemplate renders the user data of an instance as a multipart MIME document with two parts. The
+// cloud-config part makes cloud-init run the script on every boot, and the script installs Node.js, downloads
+// the project and runs it with pm2. The artifact URL expires soon after launch, so the project is only downloaded
+// until a download succeeds, and later boots run the project from the saved zip. Every value that is not
+// validated to be a number is shell-quoted.
+var userDataTemplate = template.Must(template.New("userdata").Funcs(template.FuncMap{"quote": shellQuote}).Parse(`Content-Type: multipart/mixed; boundary="//"
 MIME-Version: 1.0
 
 --//
@@ -21,15 +120,31 @@ Content-Disposition: attachment; filename="userdata.txt"
 
 #!/bin/bash
 sudo yum update -y
-curl -sL https://rpm.nodesource.com/setup_14.x | sudo -E bash -
+curl -sL https://rpm.nodesource.com/setup_{{.NodeVersion}}.x | sudo -E bash -
 sudo yum install -y nodejs
 sudo npm install pm2 -g
 cd /home/ec2-user
-wget -O defaultProject.zip "%s"
-unzip defaultProject.zip
-cd defaultProject
+if [ ! -f project.zip ]; then
+  wget -O project.zip.part {{quote .ArtifactURL}} && mv project.zip.part project.zip
+fi
+unzip -o project.zip
+cd {{quote .ProjectDir}}
 sudo pkill -f PM2
-sudo PORT=80 pm2 start app.js --name server
+sudo {{range $name, $value := .Env}}{{$name}}={{quote $value}} {{end}}PORT={{.Port}} pm2 start app.js --name server
 sudo pm2 save
---//
-`
+--//--
+`))
+
+// render validates the given user data and renders it with userDataTemplate.
+func (data userData) render() (string, error) {
+	err := data.validate()
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid user data")
+	}
+	builder := &strings.Builder{}
+	err = userDataTemplate.Execute(builder, data)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to execute user data template")
+	}
+	return builder.String(), nil
+}
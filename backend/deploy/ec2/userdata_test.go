@@ -0,0 +1,248 @@
+package ec2
+
+import (
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// part is a part of the multipart user data.
+type part struct {
+	contentType string
+	filename    string
+	body        string
+}
+
+// parseUserData parses the given user data as a multipart MIME document and returns its parts. Every part
+// must be a 7bit us-ascii attachment.
+func parseUserData(t *testing.T, userData string) []part {
+	message, err := mail.ReadMessage(strings.NewReader(userData))
+	if err != nil {
+		t.Fatalf("Failed to read user data headers: %v", err)
+	}
+	if version := message.Header.Get("MIME-Version"); version != "1.0" {
+		t.Errorf("Got MIME-Version `%s`; want `1.0`", version)
+	}
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" || params["boundary"] == "" {
+		t.Fatalf("Got Content-Type `%s`; want multipart/mixed with a boundary", message.Header.Get("Content-Type"))
+	}
+
+	var parts []part
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		mimePart, err := reader.NextPart()
+		if err != nil {
+			if err != io.EOF {
+				t.Fatalf("Failed to read part %d: %v", len(parts), err)
+			}
+			return parts
+		}
+
+		mediaType, params, err := mime.ParseMediaType(mimePart.Header.Get("Content-Type"))
+		if err != nil || params["charset"] != "us-ascii" {
+			t.Errorf("Got Content-Type `%s` for part %d; want a us-ascii charset", mimePart.Header.Get("Content-Type"), len(parts))
+		}
+		if encoding := mimePart.Header.Get("Content-Transfer-Encoding"); encoding != "7bit" {
+			t.Errorf("Got Content-Transfer-Encoding `%s` for part %d; want `7bit`", encoding, len(parts))
+		}
+		disposition, dispositionParams, err := mime.ParseMediaType(mimePart.Header.Get("Content-Disposition"))
+		if err != nil || disposition != "attachment" {
+			t.Errorf("Got Content-Disposition `%s` for part %d; want an attachment", mimePart.Header.Get("Content-Disposition"), len(parts))
+		}
+		body, err := ioutil.ReadAll(mimePart)
+		if err != nil {
+			t.Fatalf("Failed to read body of part %d: %v", len(parts), err)
+		}
+		parts = append(parts, part{contentType: mediaType, filename: dispositionParams["filename"], body: string(body)})
+	}
+}
+
+// checkScript checks that the given shell script is syntactically valid.
+func checkScript(t *testing.T, script string) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Log("Skipping syntax check of the script since bash is not installed")
+		return
+	}
+	command := exec.Command(bash, "-n")
+	command.Stdin = strings.NewReader(script)
+	output, err := command.CombinedOutput()
+	if err != nil {
+		t.Errorf("Got invalid script: %v: %s", err, output)
+	}
+}
+
+var renderTests = []struct {
+	name string
+	data userData
+
+	// Expected output
+	wantLines []string
+	wantErr   error
+}{
+	{
+		name: "DefaultUserData",
+		data: newUserData("https://bucket.s3.amazonaws.com/project.zip?X-Amz-Signature=abc&X-Amz-Expires=3600", 80),
+		wantLines: []string{
+			"curl -sL https://rpm.nodesource.com/setup_14.x | sudo -E bash -",
+			"if [ ! -f project.zip ]; then",
+			"  wget -O project.zip.part 'https://bucket.s3.amazonaws.com/project.zip?X-Amz-Signature=abc&X-Amz-Expires=3600' && mv project.zip.part project.zip",
+			"unzip -o project.zip",
+			"cd 'defaultProject'",
+			"sudo PORT=80 pm2 start app.js --name server",
+		},
+	},
+	{
+		name: "AllValues",
+		data: userData{
+			NodeVersion: "16",
+			ArtifactURL: "https://example.com/project.zip",
+			ProjectDir:  "my project",
+			Port:        3000,
+			Env:         map[string]string{"NODE_ENV": "production", "GREETING": "it's $(whoami)"},
+		},
+		wantLines: []string{
+			"curl -sL https://rpm.nodesource.com/setup_16.x | sudo -E bash -",
+			"  wget -O project.zip.part 'https://example.com/project.zip' && mv project.zip.part project.zip",
+			"cd 'my project'",
+			`sudo GREETING='it'\''s $(whoami)' NODE_ENV='production' PORT=3000 pm2 start app.js --name server`,
+		},
+	},
+	{
+		name: "InjectedURL",
+		data: newUserData(`https://example.com/"; rm -rf / #`, 80),
+		wantLines: []string{
+			`  wget -O project.zip.part 'https://example.com/"; rm -rf / #' && mv project.zip.part project.zip`,
+		},
+	},
+	{
+		name:    "InvalidNodeVersion",
+		data:    userData{NodeVersion: "14; reboot", ArtifactURL: "url", ProjectDir: "dir", Port: 80},
+		wantErr: errors.Wrap(errors.NewServer("Invalid Node.js version `14; reboot`"), "Invalid user data"),
+	},
+	{
+		name:    "EmptyURL",
+		data:    newUserData("", 80),
+		wantErr: errors.Wrap(errors.NewServer("Invalid artifact URL"), "Invalid user data"),
+	},
+	{
+		name:    "MultilineURL",
+		data:    newUserData("https://example.com/\n--//\nContent-Type: text/x-shellscript", 80),
+		wantErr: errors.Wrap(errors.NewServer("Invalid artifact URL"), "Invalid user data"),
+	},
+	{
+		name:    "InvalidProjectDir",
+		data:    userData{NodeVersion: "14", ArtifactURL: "url", Port: 80},
+		wantErr: errors.Wrap(errors.NewServer("Invalid project directory ``"), "Invalid user data"),
+	},
+	{
+		name:    "InvalidPort",
+		data:    newUserData("url", 0),
+		wantErr: errors.Wrap(errors.NewServer("Invalid port 0"), "Invalid user data"),
+	},
+	{
+		name:    "InvalidEnvName",
+		data:    userData{NodeVersion: "14", ArtifactURL: "url", ProjectDir: "dir", Port: 80, Env: map[string]string{"A=B": "c"}},
+		wantErr: errors.Wrap(errors.NewServer("Invalid environment variable name `A=B`"), "Invalid user data"),
+	},
+	{
+		name:    "PortEnv",
+		data:    userData{NodeVersion: "14", ArtifactURL: "url", ProjectDir: "dir", Port: 80, Env: map[string]string{"PORT": "8080"}},
+		wantErr: errors.Wrap(errors.NewServer("Invalid environment variable name `PORT`"), "Invalid user data"),
+	},
+	{
+		name:    "InvalidEnvValue",
+		data:    userData{NodeVersion: "14", ArtifactURL: "url", ProjectDir: "dir", Port: 80, Env: map[string]string{"SECRET": "line1\nline2"}},
+		wantErr: errors.Wrap(errors.NewServer("Invalid value of environment variable `SECRET`"), "Invalid user data"),
+	},
+}
+
+func TestRender(t *testing.T) {
+	for _, test := range renderTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			userData, err := test.data.render()
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Fatalf("Got err `%v`; want `%v`", err, test.wantErr)
+			}
+			if err != nil {
+				if userData != "" {
+					t.Errorf("Got user data `%s`; want empty user data", userData)
+				}
+				return
+			}
+
+			parts := parseUserData(t, userData)
+			if len(parts) != 2 {
+				t.Fatalf("Got %d parts; want 2", len(parts))
+			}
+
+			config := parts[0]
+			if config.contentType != "text/cloud-config" || config.filename != "cloud-config.txt" {
+				t.Errorf("Got first part %s `%s`; want text/cloud-config `cloud-config.txt`", config.contentType, config.filename)
+			}
+			if !strings.HasPrefix(config.body, "#cloud-config\n") || !strings.Contains(config.body, "- [scripts-user, always]\n") {
+				t.Errorf("Got cloud-config `%s`; want scripts-user to run on every boot", config.body)
+			}
+
+			script := parts[1]
+			if script.contentType != "text/x-shellscript" || script.filename != "userdata.txt" {
+				t.Errorf("Got second part %s `%s`; want text/x-shellscript `userdata.txt`", script.contentType, script.filename)
+			}
+			if !strings.HasPrefix(script.body, "#!/bin/bash\n") {
+				t.Errorf("Got script `%s`; want a bash script", script.body)
+			}
+			lines := strings.Split(script.body, "\n")
+			for _, want := range test.wantLines {
+				found := false
+				for _, line := range lines {
+					found = found || line == want
+				}
+				if !found {
+					t.Errorf("Got script `%s`; want line `%s`", script.body, want)
+				}
+			}
+			checkScript(t, script.body)
+		})
+	}
+}
+
+var shellQuoteTests = []string{
+	"",
+	"plain",
+	"with space",
+	"it's",
+	`"double" $HOME $(whoami) ` + "`id`" + ` \ ; | & > < * ? ~ !`,
+	"'''",
+}
+
+func TestShellQuote(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("Skipping since sh is not installed")
+	}
+	for _, value := range shellQuoteTests {
+		t.Run(value, func(t *testing.T) {
+			// Execute
+			output, err := exec.Command(sh, "-c", "printf '%s' "+shellQuote(value)).CombinedOutput()
+
+			// Verify
+			if err != nil {
+				t.Fatalf("Got err `%v` with output `%s`", err, output)
+			}
+			if string(output) != value {
+				t.Errorf("Got `%s` from shell; want `%s`", output, value)
+			}
+		})
+	}
+}
@@ -1,4 +1,5 @@
-// Package deploy handles requests to the PUT /projects/{pid}/deploy REST API endpoint.
+// Package deploy handles requests to the PUT /projects/{pid}/deploy REST API endpoint. The packages under it
+// implement the deployment providers that launch the instances running deployed projects.
 package deploy
 
 import (
@@ -7,21 +8,29 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
-	"github.com/jackstenglein/rest_api_creator/backend/deploy/ec2"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
 	"github.com/jackstenglein/rest_api_creator/backend/http"
 	"github.com/jackstenglein/rest_api_creator/backend/log"
+
+	// Register the supported deployment providers
+	_ "github.com/jackstenglein/rest_api_creator/backend/deploy/docker"
+	_ "github.com/jackstenglein/rest_api_creator/backend/deploy/ec2"
+
+	// Register the code generator of the deployed code
+	_ "github.com/jackstenglein/rest_api_creator/backend/codegen/sails"
 )
 
 // deployRequest contains the fields passed in the API JSON request body.
 type deployRequest struct {
-	URL string `json:"url"`
+	Config *dao.DeployConfig `json:"config"`
 }
 
 // deployResponse contains the fields returned in the API JSON response body.
 type deployResponse struct {
-	ID    string `json:"instanceId,omitempty"`
-	URL   string `json:"url,omitempty"`
-	Error string `json:"error,omitempty"`
+	ID     string `json:"instanceId,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
 }
 
 func (response *deployResponse) SetError(err string) {
@@ -35,21 +44,27 @@ func (response *deployResponse) SetError(err string) {
 var deploy = deployProject
 
 // HandleDeploy parses the request object from AWS APIGateway and passes it to the deployProject action.
-// The request must contain a valid `Cookie` header and a `pid` path parameter. If the request succeeds
-// and the EC2 instance launches within 5 seconds, the response body will have a `url` field. If the request
-// succeeds but the EC2 instance is slow to launch, the response body will be empty. If the request fails,
-// the response body will have an `error` field.
+// The request must contain a valid cookie or access token and a `pid` path parameter. The body is optional and may
+// contain a `config` object with the `region`, `instanceType`, `keyPair`, `ingressCidr` and `port` of the
+// deployment, which is saved for later deployments of the project. If the request succeeds,
+// the response body will have the `instanceId` and `status` fields of the new deployment. The public URL
+// is usually not known yet, so clients should poll GET /projects/{pid}/deploy until the status is `healthy`
+// or `failed`. If the request fails, the response body will have an `error` field.
 func HandleDeploy(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	// Get request parameters
 	projectID := request.PathParameters["pid"]
-	cookie := auth.ExtractCookie(request.Headers["Cookie"])
+	cookie := auth.ExtractCredential(request.Headers)
 	var deployRequest deployRequest
 	json.Unmarshal([]byte(request.Body), &deployRequest)
 
 	// Perform the action
-	instanceID, url, err := deploy(cookie, projectID, deployRequest, auth.VerifyCookie, dao.Dynamo, ec2.EC2)
+	deployment, err := deploy(cookie, projectID, deployRequest, auth.Verifier(auth.ScopeDeploy), dao.Dynamo, provider.Lookup)
 	log.Error(err)
 
 	// Return the response
-	return http.GatewayResponse(&deployResponse{ID: instanceID, URL: url}, "", err), nil
+	response := &deployResponse{}
+	if deployment != nil {
+		response = &deployResponse{ID: deployment.InstanceID, URL: deployment.URL, Status: deployment.Status}
+	}
+	return http.GatewayResponse(response, "", err), nil
 }
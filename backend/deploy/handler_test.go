@@ -8,32 +8,34 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
 
-type deployFunc func(string, string, auth.VerifyCookieFunc, deployDatabase, deployer) (string, error)
+type deployFunc func(string, string, deployRequest, auth.VerifyCookieFunc, deployDatabase, provider.LookupFunc) (*dao.Deployment, error)
 
-func deployMock(wantCookie string, wantProjectID string, url string, err error) deployFunc {
-	return func(cookie string, projectID string, _ auth.VerifyCookieFunc, _ deployDatabase, _ deployer) (string, error) {
-		if cookie != wantCookie || projectID != projectID {
-			return "", errors.NewServer("Incorrect parameters passed to mock")
+func deployMock(wantCookie string, wantProjectID string, wantRequest deployRequest, deployment *dao.Deployment, err error) deployFunc {
+	return func(cookie string, projectID string, request deployRequest, _ auth.VerifyCookieFunc, _ deployDatabase, _ provider.LookupFunc) (*dao.Deployment, error) {
+		if cookie != wantCookie || projectID != wantProjectID || !reflect.DeepEqual(request, wantRequest) {
+			return nil, errors.NewServer("Incorrect parameters passed to mock")
 		}
-		return url, err
+		return deployment, err
 	}
 }
 
-func handlerRequest(cookie string, projectID string) events.APIGatewayProxyRequest {
+func handlerRequest(cookie string, projectID string, body string) events.APIGatewayProxyRequest {
 	parameters := map[string]string{
 		"pid": projectID,
 	}
 	headers := map[string]string{
 		"Cookie": cookie,
 	}
-	return events.APIGatewayProxyRequest{PathParameters: parameters, Headers: headers}
+	return events.APIGatewayProxyRequest{PathParameters: parameters, Headers: headers, Body: body}
 }
 
-func handlerResponse(url string, err string, status int) events.APIGatewayProxyResponse {
-	json, _ := json.Marshal(&deployResponse{URL: url, Error: err})
+func handlerResponse(response *deployResponse, status int) events.APIGatewayProxyResponse {
+	json, _ := json.Marshal(response)
 	return events.APIGatewayProxyResponse{
 		Body:       string(json),
 		Headers:    map[string]string{"Access-Control-Allow-Origin": os.Getenv("CORS_ORIGIN"), "Access-Control-Allow-Credentials": "true"},
@@ -56,15 +58,21 @@ var handleDeployTests = []struct {
 }{
 	{
 		name:         "DeployProjectFailure",
-		request:      handlerRequest("session=cookievalue", "projectId"),
-		deployMock:   deployMock("cookievalue", "projectId", "", errors.NewServer("Failed database call")),
-		wantResponse: handlerResponse("", "Failed database call", 500),
+		request:      handlerRequest("session=cookievalue", "projectId", ""),
+		deployMock:   deployMock("cookievalue", "projectId", deployRequest{}, nil, errors.NewServer("Failed database call")),
+		wantResponse: handlerResponse(&deployResponse{Error: "Failed database call"}, 500),
 	},
 	{
 		name:         "SuccessfulInvocation",
-		request:      handlerRequest("session=cookievalue", "projectId"),
-		deployMock:   deployMock("cookievalue", "projectId", "example.com", nil),
-		wantResponse: handlerResponse("example.com", "", 200),
+		request:      handlerRequest("session=cookievalue", "projectId", ""),
+		deployMock:   deployMock("cookievalue", "projectId", deployRequest{}, &dao.Deployment{InstanceID: "instance", Status: dao.DeployProvisioning}, nil),
+		wantResponse: handlerResponse(&deployResponse{ID: "instance", Status: dao.DeployProvisioning}, 200),
+	},
+	{
+		name:         "DeployConfig",
+		request:      handlerRequest("session=cookievalue", "projectId", `{"config": {"region": "eu-west-1", "port": 3000}}`),
+		deployMock:   deployMock("cookievalue", "projectId", deployRequest{Config: &dao.DeployConfig{Region: "eu-west-1", Port: 3000}}, &dao.Deployment{InstanceID: "eu-west-1:instance", Status: dao.DeployProvisioning}, nil),
+		wantResponse: handlerResponse(&deployResponse{ID: "eu-west-1:instance", Status: dao.DeployProvisioning}, 200),
 	},
 }
 
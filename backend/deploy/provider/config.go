@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// The options used for the fields of a deployment config that are not set.
+const (
+	DefaultRegion       = "us-east-1"
+	DefaultInstanceType = "t2.micro"
+	DefaultIngressCIDR  = "0.0.0.0/0"
+	DefaultPort         = 80
+)
+
+// Regions is the set of regions to which projects can be deployed.
+var Regions = map[string]bool{
+	"us-east-1":    true,
+	"us-east-2":    true,
+	"us-west-1":    true,
+	"us-west-2":    true,
+	"eu-west-1":    true,
+	"eu-central-1": true,
+}
+
+// InstanceTypes is the set of instance sizes that projects can be deployed on.
+var InstanceTypes = map[string]bool{
+	"t2.micro":  true,
+	"t2.small":  true,
+	"t2.medium": true,
+	"t3.micro":  true,
+	"t3.small":  true,
+	"t3.medium": true,
+}
+
+// Ports is the set of ports on which deployed projects can listen.
+var Ports = map[int]bool{
+	80:   true,
+	1337: true,
+	3000: true,
+	8000: true,
+	8080: true,
+}
+
+// keyPairPattern matches the names of EC2 key pairs that can be used to connect to instances.
+var keyPairPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,255}$`)
+
+// allowed returns the sorted keys of the given allowlist, joined by commas.
+func allowed(allowlist map[string]bool) string {
+	keys := make([]string, 0, len(allowlist))
+	for key := range allowlist {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+// allowedPorts returns the sorted ports of the Ports allowlist, joined by commas.
+func allowedPorts() string {
+	ports := make([]int, 0, len(Ports))
+	for port := range Ports {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	names := make([]string, 0, len(ports))
+	for _, port := range ports {
+		names = append(names, fmt.Sprint(port))
+	}
+	return strings.Join(names, ", ")
+}
+
+// ValidateConfig returns a copy of the given deployment config in which every field that is not set has its
+// default value. A nil config has every default value. If a field is not in its allowlist, a client error is
+// returned. The ingress CIDR must be an IPv4 network, which is returned in its canonical form.
+func ValidateConfig(config *dao.DeployConfig) (*dao.DeployConfig, error) {
+	result := &dao.DeployConfig{}
+	if config != nil {
+		*result = *config
+	}
+
+	if result.Region == "" {
+		result.Region = DefaultRegion
+	}
+	if !Regions[result.Region] {
+		return nil, errors.NewClient(fmt.Sprintf("Unsupported region `%s`. Supported regions are: %s", result.Region, allowed(Regions)))
+	}
+
+	if result.InstanceType == "" {
+		result.InstanceType = DefaultInstanceType
+	}
+	if !InstanceTypes[result.InstanceType] {
+		return nil, errors.NewClient(fmt.Sprintf("Unsupported instance type `%s`. Supported instance types are: %s", result.InstanceType, allowed(InstanceTypes)))
+	}
+
+	if result.KeyPair != "" && !keyPairPattern.MatchString(result.KeyPair) {
+		return nil, errors.NewClient(fmt.Sprintf("Invalid key pair name `%s`", result.KeyPair))
+	}
+
+	if result.IngressCIDR == "" {
+		result.IngressCIDR = DefaultIngressCIDR
+	}
+	_, network, err := net.ParseCIDR(result.IngressCIDR)
+	if err != nil || network.IP.To4() == nil {
+		return nil, errors.NewClient(fmt.Sprintf("Invalid ingress CIDR `%s`. It must be an IPv4 network such as 203.0.113.0/24", result.IngressCIDR))
+	}
+	result.IngressCIDR = network.String()
+
+	if result.Port == 0 {
+		result.Port = DefaultPort
+	}
+	if !Ports[result.Port] {
+		return nil, errors.NewClient(fmt.Sprintf("Unsupported port %d. Supported ports are: %s", result.Port, allowedPorts()))
+	}
+	return result, nil
+}
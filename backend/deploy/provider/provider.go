@@ -0,0 +1,138 @@
+// Package provider defines the interface implemented by every deployment provider and a registry that allows
+// providers to be selected by name. Each provider lives in its own package next to this one and registers
+// itself from an init function, so that importing the package makes the provider available. The provider
+// used for new deployments is chosen by the DEPLOY_PROVIDER environment variable.
+package provider
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// DefaultProvider is the name of the provider used when DEPLOY_PROVIDER is not set. Deployments recorded
+// before providers could be selected were also made with this provider.
+const DefaultProvider = "ec2"
+
+// The states of an instance. A pending instance is starting up, a running instance can serve requests once the
+// project it runs has booted and a stopped instance will never serve requests again.
+const (
+	Pending = "pending"
+	Running = "running"
+	Stopped = "stopped"
+)
+
+// Owner identifies the project that an instance was launched to run. Providers attach it to every instance they
+// launch, so that instances which are no longer recorded as the deployment of their project can be found.
+type Owner struct {
+	Email     string
+	ProjectID string
+}
+
+// Instance describes a server launched by a provider to run a project.
+type Instance struct {
+	// ID identifies the instance to the provider that launched it.
+	ID string
+
+	// URL is the host, and port if it is not 80, at which the instance serves the project. It is empty
+	// until the provider assigns an address to the instance.
+	URL string
+
+	// State is one of Pending, Running or Stopped.
+	State string
+
+	// Reason explains why a stopped instance stopped.
+	Reason string
+
+	// LaunchTime is the time at which the instance was launched.
+	LaunchTime time.Time
+
+	// Owner is the project that the instance runs. It is empty for instances launched before providers
+	// recorded owners.
+	Owner Owner
+}
+
+// Provider launches and manages the servers that run deployed projects.
+type Provider interface {
+	// Name returns the unique name used to select the provider, such as "ec2".
+	Name() string
+
+	// Launch starts a new instance that downloads the zipped project at the given URL and runs it. The
+	// instance is marked as belonging to the given owner and is launched with the options of the given
+	// deployment config, which must have been returned by ValidateConfig. Providers ignore the options
+	// that do not apply to them.
+	Launch(projectURL string, owner Owner, config *dao.DeployConfig) (*Instance, error)
+
+	// Describe returns the current state of the instance with the given ID.
+	Describe(instanceID string) (*Instance, error)
+
+	// Terminate stops and removes the instance with the given ID. Terminating an empty ID or an instance
+	// that no longer exists is not an error.
+	Terminate(instanceID string) error
+
+	// Logs returns the most recent console output of the instance with the given ID.
+	Logs(instanceID string) (string, error)
+
+	// List returns every instance launched by the provider that has not been terminated.
+	List() ([]*Instance, error)
+}
+
+// LookupFunc is the signature of Lookup. Actions accept a LookupFunc in order to allow dependency injection.
+type LookupFunc func(string) (Provider, error)
+
+// providers maps the name of each registered provider to the provider itself.
+var providers = make(map[string]Provider)
+
+// Register makes the given provider available by its name. It is intended to be called from the init
+// function of the provider's package. Register panics if the provider is nil or if a provider with the
+// same name has already been registered.
+func Register(provider Provider) {
+	if provider == nil {
+		panic("provider: Register provider is nil")
+	}
+	name := provider.Name()
+	if _, ok := providers[name]; ok {
+		panic("provider: Register called twice for provider " + name)
+	}
+	providers[name] = provider
+}
+
+// Lookup returns the registered provider with the given name. If name is empty, the provider named by the
+// DEPLOY_PROVIDER environment variable, or DefaultProvider if it is not set, is returned. If no provider has
+// the name, a server error is returned, since the name comes from configuration rather than from the user.
+func Lookup(name string) (Provider, error) {
+	if name == "" {
+		name = os.Getenv("DEPLOY_PROVIDER")
+	}
+	if name == "" {
+		name = DefaultProvider
+	}
+	provider, ok := providers[name]
+	if !ok {
+		return nil, errors.NewServer("Unsupported deployment provider `" + name + "`. Supported providers are: " + strings.Join(Names(), ", "))
+	}
+	return provider, nil
+}
+
+// Names returns the sorted names of every registered provider.
+func Names() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NameOf returns the name of the provider that launched the instance of the given deployment, which must
+// be used to manage the instance regardless of the provider currently configured.
+func NameOf(deployment *dao.Deployment) string {
+	if deployment.Provider == "" {
+		return DefaultProvider
+	}
+	return deployment.Provider
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type mockProvider struct {
+	name string
+}
+
+func (mock mockProvider) Name() string {
+	return mock.name
+}
+
+func (mock mockProvider) Launch(projectURL string, owner Owner, config *dao.DeployConfig) (*Instance, error) {
+	return nil, nil
+}
+
+func (mock mockProvider) Describe(instanceID string) (*Instance, error) {
+	return nil, nil
+}
+
+func (mock mockProvider) Terminate(instanceID string) error {
+	return nil
+}
+
+func (mock mockProvider) Logs(instanceID string) (string, error) {
+	return "", nil
+}
+
+func (mock mockProvider) List() ([]*Instance, error) {
+	return nil, nil
+}
+
+var lookupTests = []struct {
+	name string
+
+	// Input
+	registered []Provider
+	configured string
+	provider   string
+
+	// Expected output
+	wantProvider Provider
+	wantErr      error
+}{
+	{
+		name:         "DefaultProvider",
+		registered:   []Provider{mockProvider{"ec2"}, mockProvider{"docker"}},
+		wantProvider: mockProvider{"ec2"},
+	},
+	{
+		name:         "ConfiguredProvider",
+		registered:   []Provider{mockProvider{"ec2"}, mockProvider{"docker"}},
+		configured:   "docker",
+		wantProvider: mockProvider{"docker"},
+	},
+	{
+		name:         "NamedProvider",
+		registered:   []Provider{mockProvider{"ec2"}, mockProvider{"docker"}},
+		configured:   "docker",
+		provider:     "ec2",
+		wantProvider: mockProvider{"ec2"},
+	},
+	{
+		name:       "UnsupportedProvider",
+		registered: []Provider{mockProvider{"ec2"}, mockProvider{"docker"}},
+		provider:   "gce",
+		wantErr:    errors.NewServer("Unsupported deployment provider `gce`. Supported providers are: docker, ec2"),
+	},
+}
+
+func TestLookup(t *testing.T) {
+	for _, test := range lookupTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			providers = make(map[string]Provider)
+			for _, provider := range test.registered {
+				Register(provider)
+			}
+			os.Setenv("DEPLOY_PROVIDER", test.configured)
+			defer os.Unsetenv("DEPLOY_PROVIDER")
+
+			// Execute
+			provider, err := Lookup(test.provider)
+
+			// Verify
+			if !reflect.DeepEqual(provider, test.wantProvider) {
+				t.Errorf("Got provider %v; want %v", provider, test.wantProvider)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	// Setup
+	providers = make(map[string]Provider)
+	Register(mockProvider{"ec2"})
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Register did not panic on duplicate provider")
+		}
+	}()
+
+	// Execute
+	Register(mockProvider{"ec2"})
+}
+
+func TestNameOf(t *testing.T) {
+	if name := NameOf(&dao.Deployment{}); name != DefaultProvider {
+		t.Errorf("Got name %s for legacy deployment; want %s", name, DefaultProvider)
+	}
+	if name := NameOf(&dao.Deployment{Provider: "docker"}); name != "docker" {
+		t.Errorf("Got name %s; want docker", name)
+	}
+}
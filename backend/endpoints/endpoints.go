@@ -0,0 +1,276 @@
+// Package endpoints validates the custom endpoints of a project and lists the endpoints that code generators
+// emit for it. A project without custom endpoints is given the standard CRUD endpoints of every object, so
+// that projects created before endpoints could be customized keep generating the same API.
+package endpoints
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/types"
+)
+
+// The operations an endpoint can perform on the records of its object.
+const (
+	List   = "list"
+	Get    = "get"
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+	Custom = "custom"
+)
+
+// The operators a filter can use to compare an attribute to the value of its query parameter.
+const (
+	Equal              = "eq"
+	NotEqual           = "ne"
+	LessThan           = "lt"
+	LessThanOrEqual    = "lte"
+	GreaterThan        = "gt"
+	GreaterThanOrEqual = "gte"
+	Contains           = "contains"
+	StartsWith         = "startsWith"
+)
+
+// methods maps each operation to the HTTP methods it can be served with. Custom endpoints can use any method.
+var methods = map[string][]string{
+	List:   {"GET"},
+	Get:    {"GET"},
+	Create: {"POST"},
+	Update: {"PATCH", "PUT"},
+	Delete: {"DELETE"},
+	Custom: {"DELETE", "GET", "PATCH", "POST", "PUT"},
+}
+
+// operators maps the OpenAPI type of an attribute to the operators that filters on the attribute can use.
+// Attributes of other types, such as JSON and collection attributes, cannot be filtered.
+var operators = map[string][]string{
+	"string":  {Equal, NotEqual, LessThan, LessThanOrEqual, GreaterThan, GreaterThanOrEqual, Contains, StartsWith},
+	"integer": {Equal, NotEqual, LessThan, LessThanOrEqual, GreaterThan, GreaterThanOrEqual},
+	"number":  {Equal, NotEqual, LessThan, LessThanOrEqual, GreaterThan, GreaterThanOrEqual},
+	"boolean": {Equal, NotEqual},
+}
+
+var isSegment = regexp.MustCompile(`^[A-Za-z0-9_-]+$`).MatchString
+var isParameter = regexp.MustCompile(`^:[A-Za-z][A-Za-z0-9]*$`).MatchString
+
+// contains returns true if the given list contains the given value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Parameters returns the names of the path parameters of the given path, such as `id` for `/books/:id`.
+func Parameters(path string) []string {
+	var parameters []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			parameters = append(parameters, segment[1:])
+		}
+	}
+	return parameters
+}
+
+// validatePath checks that the given path starts with a slash and that each of its segments is either a
+// static segment or a uniquely named parameter, such as `/authors/:authorId/books`.
+func validatePath(path string) error {
+	if !strings.HasPrefix(path, "/") {
+		return errors.NewClient(fmt.Sprintf("Path `%s` must start with `/`", path))
+	}
+	if path == "/" {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, segment := range strings.Split(path[1:], "/") {
+		if !isSegment(segment) && !isParameter(segment) {
+			return errors.NewClient(fmt.Sprintf("Path `%s` has invalid segment `%s`", path, segment))
+		}
+		if isParameter(segment) && seen[segment] {
+			return errors.NewClient(fmt.Sprintf("Path `%s` has duplicate parameter `%s`", path, segment))
+		}
+		seen[segment] = true
+	}
+	return nil
+}
+
+// findAttribute returns the attribute of the given object with the given code name, or nil if there is none.
+func findAttribute(object *dao.Object, codeName string) *dao.Attribute {
+	for _, attribute := range object.Attributes {
+		if attribute != nil && attribute.CodeName == codeName {
+			return attribute
+		}
+	}
+	return nil
+}
+
+// validateFilters checks that the filters of the given endpoint compare attributes of the given object using
+// operators that their types support, and that each filter has its own query parameter. An attribute with an
+// `eq` filter cannot have other filters, since it already matches a single value.
+func validateFilters(endpoint *dao.Endpoint, object *dao.Object) error {
+	if len(endpoint.Filters) > 0 && endpoint.Operation != List {
+		return errors.NewClient(fmt.Sprintf("Operation `%s` does not accept filters", endpoint.Operation))
+	}
+
+	parameters := make(map[string]bool)
+	counts := make(map[string]int)
+	equal := make(map[string]bool)
+	for _, filter := range endpoint.Filters {
+		if filter == nil {
+			return errors.NewClient("Filter cannot be nil")
+		}
+		attribute := findAttribute(object, filter.Attribute)
+		if attribute == nil {
+			return errors.NewClient(fmt.Sprintf("Object `%s` does not have attribute `%s`", object.Name, filter.Attribute))
+		}
+		t, err := types.Lookup(attribute.Type)
+		if err != nil {
+			return err
+		}
+		if !contains(operators[t.OpenAPI.Type], filter.Operator) {
+			return errors.NewClient(fmt.Sprintf("Attribute `%s` of type `%s` cannot be filtered with operator `%s`", attribute.Name, attribute.Type, filter.Operator))
+		}
+		if parameters[filter.Parameter] {
+			return errors.NewClient(fmt.Sprintf("Query parameter `%s` is used by more than one filter", filter.Parameter))
+		}
+		parameters[filter.Parameter] = true
+		counts[filter.Attribute]++
+		equal[filter.Attribute] = equal[filter.Attribute] || filter.Operator == Equal
+		if equal[filter.Attribute] && counts[filter.Attribute] > 1 {
+			return errors.NewClient(fmt.Sprintf("Attribute `%s` cannot have other filters in addition to an `eq` filter", attribute.Name))
+		}
+	}
+	return nil
+}
+
+// Validate checks that the given endpoint is consistent and that it refers to an existing object, and to
+// existing attributes of that object, in the given map of object IDs to objects. The operation must be served
+// with a method that matches it, and operations on a single record must have an `id` path parameter.
+func Validate(objects map[string]*dao.Object, endpoint *dao.Endpoint) error {
+	if endpoint == nil {
+		return errors.NewServer("Endpoint cannot be nil")
+	}
+
+	allowed, ok := methods[endpoint.Operation]
+	if !ok {
+		return errors.NewClient(fmt.Sprintf("Operation `%s` is not supported. Supported operations are: `create`, `custom`, `delete`, `get`, `list`, `update`", endpoint.Operation))
+	}
+	if !contains(allowed, endpoint.Method) {
+		return errors.NewClient(fmt.Sprintf("Operation `%s` cannot use method `%s`. Allowed methods are: `%s`", endpoint.Operation, endpoint.Method, strings.Join(allowed, "`, `")))
+	}
+
+	err := validatePath(endpoint.Path)
+	if err != nil {
+		return err
+	}
+	single := endpoint.Operation == Get || endpoint.Operation == Update || endpoint.Operation == Delete
+	if single && !contains(Parameters(endpoint.Path), "id") {
+		return errors.NewClient(fmt.Sprintf("Operation `%s` requires path parameter `:id`", endpoint.Operation))
+	}
+
+	object := objects[endpoint.Object]
+	if object == nil {
+		return errors.NewClient(fmt.Sprintf("Object `%s` does not exist", endpoint.Object))
+	}
+	return validateFilters(endpoint, object)
+}
+
+// ValidateConflicts checks that the given endpoint does not share its route with another endpoint of the given
+// map of endpoint IDs to endpoints, and that it does not have the same code name as another endpoint of its
+// object, since both would be served by the same controller action. The endpoint itself is ignored.
+func ValidateConflicts(endpoints map[string]*dao.Endpoint, endpoint *dao.Endpoint) error {
+	for _, other := range Sorted(endpoints) {
+		if other.ID == endpoint.ID {
+			continue
+		}
+		if other.Method == endpoint.Method && other.Path == endpoint.Path {
+			return errors.NewClient(fmt.Sprintf("Endpoint `%s` already uses route `%s %s`", other.ID, other.Method, other.Path))
+		}
+		if other.Object == endpoint.Object && other.CodeName == endpoint.CodeName {
+			return errors.NewClient(fmt.Sprintf("Endpoint `%s` already has name `%s` for object `%s`", other.ID, other.Name, other.Object))
+		}
+	}
+	return nil
+}
+
+// UsedBy returns the first endpoint, in order of ID, that operates on the object with the given ID, or nil if
+// there is none.
+func UsedBy(endpoints map[string]*dao.Endpoint, objectID string) *dao.Endpoint {
+	for _, endpoint := range Sorted(endpoints) {
+		if endpoint.Object == objectID {
+			return endpoint
+		}
+	}
+	return nil
+}
+
+// Sorted returns the non-nil endpoints of the given map sorted by ID.
+func Sorted(endpoints map[string]*dao.Endpoint) []*dao.Endpoint {
+	ids := make([]string, 0, len(endpoints))
+	for id, endpoint := range endpoints {
+		if endpoint != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	sorted := make([]*dao.Endpoint, 0, len(ids))
+	for _, id := range ids {
+		sorted = append(sorted, endpoints[id])
+	}
+	return sorted
+}
+
+// Defaults returns the standard list, get, create, update and delete endpoints of the given object. Their
+// paths start with the lowercase code name of the object.
+func Defaults(object *dao.Object) []*dao.Endpoint {
+	path := "/" + strings.ToLower(object.CodeName)
+	endpoint := func(operation string, method string, path string) *dao.Endpoint {
+		return &dao.Endpoint{
+			ID:        operation + object.CodeName,
+			Name:      operation,
+			CodeName:  operation,
+			Method:    method,
+			Path:      path,
+			Object:    object.ID,
+			Operation: operation,
+		}
+	}
+	return []*dao.Endpoint{
+		endpoint(List, "GET", path),
+		endpoint(Get, "GET", path+"/:id"),
+		endpoint(Create, "POST", path),
+		endpoint(Update, "PATCH", path+"/:id"),
+		endpoint(Delete, "DELETE", path+"/:id"),
+	}
+}
+
+// Project returns the endpoints that code generators emit for the given project: its custom endpoints sorted
+// by ID or, if it does not have any, the default endpoints of each object in order of object ID.
+func Project(project *dao.Project) []*dao.Endpoint {
+	if len(project.Endpoints) > 0 {
+		return Sorted(project.Endpoints)
+	}
+
+	ids := make([]string, 0, len(project.Objects))
+	for id, object := range project.Objects {
+		if object != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var endpoints []*dao.Endpoint
+	for _, id := range ids {
+		endpoints = append(endpoints, Defaults(project.Objects[id])...)
+	}
+	return endpoints
+}
@@ -0,0 +1,218 @@
+package endpoints
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+var book = &dao.Object{ID: "book", Name: "Book", CodeName: "Book", Attributes: []*dao.Attribute{
+	{Name: "title", CodeName: "title", Type: "Text"},
+	{Name: "pages", CodeName: "pages", Type: "Integer"},
+	{Name: "available", CodeName: "available", Type: "Boolean"},
+	{Name: "details", CodeName: "details", Type: "JSON"},
+}}
+var author = &dao.Object{ID: "author", Name: "Author", CodeName: "Author"}
+
+var objects = map[string]*dao.Object{"book": book, "author": author}
+
+// listBooks returns an endpoint that lists books with the given filters.
+func listBooks(filters ...*dao.Filter) *dao.Endpoint {
+	return &dao.Endpoint{ID: "listBooks", Name: "listBooks", CodeName: "listBooks", Method: "GET", Path: "/books", Object: "book", Operation: List, Filters: filters}
+}
+
+var validateTests = []struct {
+	name     string
+	endpoint *dao.Endpoint
+	wantErr  error
+}{
+	{
+		name:    "NilEndpoint",
+		wantErr: errors.NewServer("Endpoint cannot be nil"),
+	},
+	{
+		name:     "InvalidOperation",
+		endpoint: &dao.Endpoint{Method: "GET", Path: "/books", Object: "book", Operation: "search"},
+		wantErr:  errors.NewClient("Operation `search` is not supported. Supported operations are: `create`, `custom`, `delete`, `get`, `list`, `update`"),
+	},
+	{
+		name:     "InvalidMethod",
+		endpoint: &dao.Endpoint{Method: "POST", Path: "/books", Object: "book", Operation: List},
+		wantErr:  errors.NewClient("Operation `list` cannot use method `POST`. Allowed methods are: `GET`"),
+	},
+	{
+		name:     "RelativePath",
+		endpoint: &dao.Endpoint{Method: "GET", Path: "books", Object: "book", Operation: List},
+		wantErr:  errors.NewClient("Path `books` must start with `/`"),
+	},
+	{
+		name:     "InvalidSegment",
+		endpoint: &dao.Endpoint{Method: "GET", Path: "/books/{id}", Object: "book", Operation: Get},
+		wantErr:  errors.NewClient("Path `/books/{id}` has invalid segment `{id}`"),
+	},
+	{
+		name:     "TrailingSlash",
+		endpoint: &dao.Endpoint{Method: "GET", Path: "/books/", Object: "book", Operation: List},
+		wantErr:  errors.NewClient("Path `/books/` has invalid segment ``"),
+	},
+	{
+		name:     "DuplicateParameter",
+		endpoint: &dao.Endpoint{Method: "GET", Path: "/books/:id/copies/:id", Object: "book", Operation: Get},
+		wantErr:  errors.NewClient("Path `/books/:id/copies/:id` has duplicate parameter `:id`"),
+	},
+	{
+		name:     "MissingID",
+		endpoint: &dao.Endpoint{Method: "DELETE", Path: "/books/:bookId", Object: "book", Operation: Delete},
+		wantErr:  errors.NewClient("Operation `delete` requires path parameter `:id`"),
+	},
+	{
+		name:     "MissingObject",
+		endpoint: &dao.Endpoint{Method: "GET", Path: "/publishers", Object: "publisher", Operation: List},
+		wantErr:  errors.NewClient("Object `publisher` does not exist"),
+	},
+	{
+		name:     "FiltersOnGet",
+		endpoint: &dao.Endpoint{Method: "GET", Path: "/books/:id", Object: "book", Operation: Get, Filters: []*dao.Filter{{Attribute: "title", Operator: Equal, Parameter: "title"}}},
+		wantErr:  errors.NewClient("Operation `get` does not accept filters"),
+	},
+	{
+		name:     "NilFilter",
+		endpoint: listBooks(nil),
+		wantErr:  errors.NewClient("Filter cannot be nil"),
+	},
+	{
+		name:     "MissingAttribute",
+		endpoint: listBooks(&dao.Filter{Attribute: "isbn", Operator: Equal, Parameter: "isbn"}),
+		wantErr:  errors.NewClient("Object `Book` does not have attribute `isbn`"),
+	},
+	{
+		name:     "UnsupportedOperator",
+		endpoint: listBooks(&dao.Filter{Attribute: "pages", Operator: Contains, Parameter: "pages"}),
+		wantErr:  errors.NewClient("Attribute `pages` of type `Integer` cannot be filtered with operator `contains`"),
+	},
+	{
+		name:     "UnfilterableType",
+		endpoint: listBooks(&dao.Filter{Attribute: "details", Operator: Equal, Parameter: "details"}),
+		wantErr:  errors.NewClient("Attribute `details` of type `JSON` cannot be filtered with operator `eq`"),
+	},
+	{
+		name: "DuplicateParameterName",
+		endpoint: listBooks(
+			&dao.Filter{Attribute: "title", Operator: Contains, Parameter: "q"},
+			&dao.Filter{Attribute: "pages", Operator: GreaterThan, Parameter: "q"},
+		),
+		wantErr: errors.NewClient("Query parameter `q` is used by more than one filter"),
+	},
+	{
+		name: "EqualWithOtherFilter",
+		endpoint: listBooks(
+			&dao.Filter{Attribute: "pages", Operator: GreaterThan, Parameter: "minPages"},
+			&dao.Filter{Attribute: "pages", Operator: Equal, Parameter: "pages"},
+		),
+		wantErr: errors.NewClient("Attribute `pages` cannot have other filters in addition to an `eq` filter"),
+	},
+	{
+		name: "ValidList",
+		endpoint: listBooks(
+			&dao.Filter{Attribute: "pages", Operator: GreaterThanOrEqual, Parameter: "minPages"},
+			&dao.Filter{Attribute: "pages", Operator: LessThanOrEqual, Parameter: "maxPages"},
+			&dao.Filter{Attribute: "title", Operator: StartsWith, Parameter: "prefix"},
+			&dao.Filter{Attribute: "available", Operator: Equal, Parameter: "available"},
+		),
+	},
+	{
+		name:     "ValidCustom",
+		endpoint: &dao.Endpoint{Method: "POST", Path: "/authors/:authorId/books/:id/publish", Object: "book", Operation: Custom},
+	},
+	{
+		name:     "RootPath",
+		endpoint: &dao.Endpoint{Method: "GET", Path: "/", Object: "book", Operation: Custom},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range validateTests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Validate(objects, test.endpoint)
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%v'; want '%v'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+var existing = map[string]*dao.Endpoint{
+	"listBooks":   listBooks(),
+	"listAuthors": {ID: "listAuthors", Name: "list", CodeName: "list", Method: "GET", Path: "/authors", Object: "author", Operation: List},
+}
+
+var validateConflictsTests = []struct {
+	name     string
+	endpoint *dao.Endpoint
+	wantErr  error
+}{
+	{
+		name:     "SameRoute",
+		endpoint: &dao.Endpoint{ID: "search", Name: "search", CodeName: "search", Method: "GET", Path: "/books", Object: "book", Operation: Custom},
+		wantErr:  errors.NewClient("Endpoint `listBooks` already uses route `GET /books`"),
+	},
+	{
+		name:     "SameCodeName",
+		endpoint: &dao.Endpoint{ID: "allBooks", Name: "ListBooks", CodeName: "listBooks", Method: "GET", Path: "/books/all", Object: "book", Operation: List},
+		wantErr:  errors.NewClient("Endpoint `listBooks` already has name `listBooks` for object `book`"),
+	},
+	{
+		name:     "SameCodeNameOtherObject",
+		endpoint: &dao.Endpoint{ID: "listAll", Name: "list", CodeName: "list", Method: "GET", Path: "/books/all", Object: "book", Operation: List},
+	},
+	{
+		name:     "ReplacingItself",
+		endpoint: listBooks(),
+	},
+}
+
+func TestValidateConflicts(t *testing.T) {
+	for _, test := range validateConflictsTests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateConflicts(existing, test.endpoint)
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error '%v'; want '%v'", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestUsedBy(t *testing.T) {
+	if endpoint := UsedBy(existing, "author"); endpoint != existing["listAuthors"] {
+		t.Errorf("Got endpoint %+v; want listAuthors", endpoint)
+	}
+	if endpoint := UsedBy(existing, "publisher"); endpoint != nil {
+		t.Errorf("Got endpoint %+v; want nil", endpoint)
+	}
+}
+
+func TestProject(t *testing.T) {
+	t.Run("CustomEndpoints", func(t *testing.T) {
+		got := Project(&dao.Project{Objects: objects, Endpoints: existing})
+		want := []*dao.Endpoint{existing["listAuthors"], existing["listBooks"]}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Got endpoints %+v; want %+v", got, want)
+		}
+	})
+
+	t.Run("DefaultEndpoints", func(t *testing.T) {
+		got := Project(&dao.Project{Objects: map[string]*dao.Object{"book": book}})
+		want := []*dao.Endpoint{
+			{ID: "listBook", Name: "list", CodeName: "list", Method: "GET", Path: "/book", Object: "book", Operation: List},
+			{ID: "getBook", Name: "get", CodeName: "get", Method: "GET", Path: "/book/:id", Object: "book", Operation: Get},
+			{ID: "createBook", Name: "create", CodeName: "create", Method: "POST", Path: "/book", Object: "book", Operation: Create},
+			{ID: "updateBook", Name: "update", CodeName: "update", Method: "PATCH", Path: "/book/:id", Object: "book", Operation: Update},
+			{ID: "deleteBook", Name: "delete", CodeName: "delete", Method: "DELETE", Path: "/book/:id", Object: "book", Operation: Delete},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Got endpoints %+v; want %+v", got, want)
+		}
+	})
+}
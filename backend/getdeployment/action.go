@@ -0,0 +1,157 @@
+package getdeployment
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// getDeploymentDatabase wraps the database functions used by the getDeployment action in order to allow
+// dependency injection.
+type getDeploymentDatabase interface {
+	auth.CredentialStore
+	GetProject(string, string) (*dao.Project, error)
+	AdvanceDeployment(string, string, *dao.Deployment) (bool, error)
+}
+
+// bootTimeout is how long the generated server has to respond after its instance is launched before the
+// deployment is considered failed.
+const bootTimeout = 10 * time.Minute
+
+// now returns the current time. It should be changed only in unit tests.
+var now = time.Now
+
+// checkHealth points to the function used to check whether the server at the given public DNS name responds.
+// It should be changed only in unit tests.
+var checkHealth = pingServer
+
+// pingServer sends an HTTP request to the server at the given public DNS name. It returns an error if the
+// server does not respond or responds with a server error.
+func pingServer(url string) error {
+	client := &nethttp.Client{Timeout: 2 * time.Second}
+	response, err := client.Get("http://" + url)
+	if err != nil {
+		return errors.Wrap(err, "Failed to reach server")
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 500 {
+		return errors.NewServer(fmt.Sprintf("Server responded with status %d", response.StatusCode))
+	}
+	return nil
+}
+
+// advance returns the deployment that follows the given deployment according to the current state of its
+// instance. A running instance is booting until the server it runs responds, and it fails if the server
+// does not respond within bootTimeout of the launch. A stopped instance fails.
+func advance(deployment dao.Deployment, instance *provider.Instance) dao.Deployment {
+	if instance.URL != "" {
+		deployment.URL = instance.URL
+	}
+
+	switch instance.State {
+	case provider.Pending:
+		deployment.Status = dao.DeployProvisioning
+	case provider.Running:
+		deployment.Status = dao.DeployBooting
+		if deployment.URL != "" && checkHealth(deployment.URL) == nil {
+			deployment.Status = dao.DeployHealthy
+		} else if now().Sub(instance.LaunchTime) > bootTimeout {
+			deployment.Status = dao.DeployFailed
+			deployment.Error = fmt.Sprintf("Server did not respond within %v of launch", bootTimeout)
+		}
+	default:
+		deployment.Status = dao.DeployFailed
+		deployment.Error = instance.Reason
+	}
+	return deployment
+}
+
+// getDeployment returns the deployment of the given project. If the deployment is still in progress, its
+// instance is described by the provider that launched it, which is found with providers, to advance its status,
+// and the new status is saved before it is returned. If the project is redeployed or undeployed while its status is
+// being advanced, the new status is discarded and the deployment that replaced it is returned instead. Healthy and
+// failed deployments are returned as they are, as is the empty deployment of a project that has never been
+// deployed. If includeLogs is true and the project has an instance, the console output of the instance is also
+// returned.
+func getDeployment(cookie string, projectID string, includeLogs bool, verifyCookie auth.VerifyCookieFunc, db getDeploymentDatabase, providers provider.LookupFunc) (*dao.Deployment, string, error) {
+	if projectID == "" {
+		return nil, "", errors.NewClient("Parameter `pid` is required")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Failed to verify cookie")
+	}
+
+	project, err := db.GetProject(email, projectID)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Failed to get project")
+	}
+
+	deployment := project.Deployment
+	if deployment.InstanceID == "" {
+		return &deployment, "", nil
+	}
+	deployer, err := providers(provider.NameOf(&deployment))
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Failed to get deployment provider")
+	}
+
+	if deployment.Status != dao.DeployHealthy && deployment.Status != dao.DeployFailed {
+		instance, err := deployer.Describe(deployment.InstanceID)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "Failed to describe instance")
+		}
+		next := advance(deployment, instance)
+		if next != deployment {
+			log.Info("Updating deployment:", next)
+			applied, err := db.AdvanceDeployment(email, projectID, &next)
+			if err != nil {
+				return nil, "", errors.Wrap(err, "Failed to update deployment info")
+			}
+			if !applied {
+				log.Info("Deployment changed while it was being updated")
+				return getLatestDeployment(email, projectID, includeLogs, db, providers)
+			}
+			deployment = next
+		}
+	}
+
+	if !includeLogs {
+		return &deployment, "", nil
+	}
+	logs, err := deployer.Logs(deployment.InstanceID)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Failed to get instance logs")
+	}
+	return &deployment, logs, nil
+}
+
+// getLatestDeployment returns the deployment of the given project as it is stored, without advancing its status.
+// If includeLogs is true and the project has an instance, the console output of the instance is also returned.
+func getLatestDeployment(email string, projectID string, includeLogs bool, db getDeploymentDatabase, providers provider.LookupFunc) (*dao.Deployment, string, error) {
+	project, err := db.GetProject(email, projectID)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Failed to get project")
+	}
+
+	deployment := project.Deployment
+	if !includeLogs || deployment.InstanceID == "" {
+		return &deployment, "", nil
+	}
+	deployer, err := providers(provider.NameOf(&deployment))
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Failed to get deployment provider")
+	}
+	logs, err := deployer.Logs(deployment.InstanceID)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Failed to get instance logs")
+	}
+	return &deployment, logs, nil
+}
@@ -0,0 +1,381 @@
+package getdeployment
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	project    *dao.Project
+	getErr     error
+	deployment *dao.Deployment
+	updateErr  error
+	updated    bool
+
+	// The project read after the deployment changed concurrently. If it is set, AdvanceDeployment does not apply.
+	latest    *dao.Project
+	latestErr error
+	reads     int
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetProject(email string, projectID string) (*dao.Project, error) {
+	if email != "test@example.com" || projectID != "project" {
+		return nil, errors.NewServer("Incorrect input to GetProject mock")
+	}
+	mock.reads++
+	if mock.reads > 1 {
+		return mock.latest, mock.latestErr
+	}
+	return mock.project, mock.getErr
+}
+
+func (mock *databaseMock) AdvanceDeployment(email string, projectID string, deployment *dao.Deployment) (bool, error) {
+	if email != "test@example.com" || projectID != "project" || !reflect.DeepEqual(deployment, mock.deployment) {
+		return false, errors.NewServer("Incorrect input to AdvanceDeployment mock")
+	}
+	mock.updated = true
+	if mock.latest != nil || mock.latestErr != nil {
+		return false, nil
+	}
+	return mock.updateErr == nil, mock.updateErr
+}
+
+type providerMock struct {
+	name        string
+	instance    *provider.Instance
+	describeErr error
+	logs        string
+	logsErr     error
+}
+
+func (mock *providerMock) lookup(name string) (provider.Provider, error) {
+	if name != mock.name {
+		return nil, errors.NewServer("Unsupported deployment provider `" + name + "`")
+	}
+	return mock, nil
+}
+
+func (mock *providerMock) Name() string {
+	return mock.name
+}
+
+func (mock *providerMock) Launch(projectURL string, owner provider.Owner, config *dao.DeployConfig) (*provider.Instance, error) {
+	return nil, errors.NewServer("Unexpected call to Launch mock")
+}
+
+func (mock *providerMock) Describe(instanceID string) (*provider.Instance, error) {
+	if instanceID != "instance" {
+		return nil, errors.NewServer("Incorrect input to Describe mock")
+	}
+	return mock.instance, mock.describeErr
+}
+
+func (mock *providerMock) Terminate(instanceID string) error {
+	return errors.NewServer("Unexpected call to Terminate mock")
+}
+
+func (mock *providerMock) List() ([]*provider.Instance, error) {
+	return nil, errors.NewServer("Unexpected call to List mock")
+}
+
+func (mock *providerMock) Logs(instanceID string) (string, error) {
+	if instanceID != "instance" {
+		return "", errors.NewServer("Incorrect input to Logs mock")
+	}
+	return mock.logs, mock.logsErr
+}
+
+var launchTime = time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+func instance(state string, url string, reason string) *provider.Instance {
+	return &provider.Instance{ID: "instance", URL: url, State: state, Reason: reason, LaunchTime: launchTime}
+}
+
+func deployedProject(status string) *dao.Project {
+	return &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance", Status: status}}
+}
+
+var getDeploymentTests = []struct {
+	name      string
+	projectID string
+
+	// Mock data
+	db          *databaseMock
+	verifyErr   error
+	includeLogs bool
+	deployer    *providerMock
+	elapsed     time.Duration
+	healthErr   error
+
+	wantDeployment *dao.Deployment
+	wantLogs       string
+	wantUpdate     bool
+	wantErr        error
+}{
+	{
+		name:    "EmptyProjectID",
+		wantErr: errors.NewClient("Parameter `pid` is required"),
+	},
+	{
+		name:      "InvalidCookie",
+		projectID: "project",
+		verifyErr: errors.NewClient("Not authenticated"),
+		wantErr:   errors.Wrap(errors.NewClient("Not authenticated"), "Failed to verify cookie"),
+	},
+	{
+		name:      "GetProjectFailure",
+		projectID: "project",
+		db:        &databaseMock{getErr: errors.NewServer("Database failure")},
+		wantErr:   errors.Wrap(errors.NewServer("Database failure"), "Failed to get project"),
+	},
+	{
+		name:           "NotDeployed",
+		projectID:      "project",
+		db:             &databaseMock{project: &dao.Project{ID: "project"}},
+		wantDeployment: &dao.Deployment{},
+	},
+	{
+		name:           "AlreadyHealthy",
+		projectID:      "project",
+		db:             &databaseMock{project: &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployHealthy}}},
+		deployer:       &providerMock{name: "ec2"},
+		wantDeployment: &dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployHealthy},
+	},
+	{
+		name:           "AlreadyFailed",
+		projectID:      "project",
+		db:             &databaseMock{project: &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance", Status: dao.DeployFailed, Error: "Instance is stopped"}}},
+		deployer:       &providerMock{name: "ec2"},
+		wantDeployment: &dao.Deployment{InstanceID: "instance", Status: dao.DeployFailed, Error: "Instance is stopped"},
+	},
+	{
+		name:        "LogsFailure",
+		projectID:   "project",
+		includeLogs: true,
+		db:          &databaseMock{project: &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance", Status: dao.DeployFailed}}},
+		deployer:    &providerMock{name: "ec2", logsErr: errors.NewServer("EC2 failure")},
+		wantErr:     errors.Wrap(errors.NewServer("EC2 failure"), "Failed to get instance logs"),
+	},
+	{
+		name:           "NotDeployedWithLogs",
+		projectID:      "project",
+		includeLogs:    true,
+		db:             &databaseMock{project: &dao.Project{ID: "project"}},
+		wantDeployment: &dao.Deployment{},
+	},
+	{
+		name:        "DockerDeploymentWithLogs",
+		projectID:   "project",
+		includeLogs: true,
+		db: &databaseMock{
+			project:    &dao.Project{ID: "project", Deployment: dao.Deployment{Provider: "docker", InstanceID: "instance", Status: dao.DeployProvisioning}},
+			deployment: &dao.Deployment{Provider: "docker", InstanceID: "instance", URL: "localhost:32768", Status: dao.DeployBooting},
+		},
+		deployer:       &providerMock{name: "docker", instance: instance(provider.Running, "localhost:32768", ""), logs: "Lifting sails\n"},
+		elapsed:        time.Minute,
+		healthErr:      errors.NewServer("Connection refused"),
+		wantDeployment: &dao.Deployment{Provider: "docker", InstanceID: "instance", URL: "localhost:32768", Status: dao.DeployBooting},
+		wantLogs:       "Lifting sails\n",
+		wantUpdate:     true,
+	},
+	{
+		name:      "ProviderLookupFailure",
+		projectID: "project",
+		db:        &databaseMock{project: &dao.Project{ID: "project", Deployment: dao.Deployment{Provider: "gce", InstanceID: "instance"}}},
+		deployer:  &providerMock{name: "ec2"},
+		wantErr:   errors.Wrap(errors.NewServer("Unsupported deployment provider `gce`"), "Failed to get deployment provider"),
+	},
+	{
+		name:      "DescribeInstanceFailure",
+		projectID: "project",
+		db:        &databaseMock{project: deployedProject(dao.DeployProvisioning)},
+		deployer:  &providerMock{name: "ec2", describeErr: errors.NewServer("EC2 failure")},
+		wantErr:   errors.Wrap(errors.NewServer("EC2 failure"), "Failed to describe instance"),
+	},
+	{
+		name:           "StillProvisioning",
+		projectID:      "project",
+		db:             &databaseMock{project: deployedProject(dao.DeployProvisioning)},
+		deployer:       &providerMock{name: "ec2", instance: instance(provider.Pending, "", "")},
+		wantDeployment: &dao.Deployment{InstanceID: "instance", Status: dao.DeployProvisioning},
+	},
+	{
+		name:      "LegacyDeployment",
+		projectID: "project",
+		db: &databaseMock{
+			project:    deployedProject(""),
+			deployment: &dao.Deployment{InstanceID: "instance", Status: dao.DeployProvisioning},
+		},
+		deployer:       &providerMock{name: "ec2", instance: instance(provider.Pending, "", "")},
+		wantDeployment: &dao.Deployment{InstanceID: "instance", Status: dao.DeployProvisioning},
+		wantUpdate:     true,
+	},
+	{
+		name:      "Booting",
+		projectID: "project",
+		db: &databaseMock{
+			project:    deployedProject(dao.DeployProvisioning),
+			deployment: &dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployBooting},
+		},
+		deployer:       &providerMock{name: "ec2", instance: instance(provider.Running, "example.com", "")},
+		elapsed:        time.Minute,
+		healthErr:      errors.NewServer("Connection refused"),
+		wantDeployment: &dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployBooting},
+		wantUpdate:     true,
+	},
+	{
+		name:      "Healthy",
+		projectID: "project",
+		db: &databaseMock{
+			project:    &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployBooting}},
+			deployment: &dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployHealthy},
+		},
+		deployer:       &providerMock{name: "ec2", instance: instance(provider.Running, "example.com", "")},
+		elapsed:        time.Minute,
+		wantDeployment: &dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployHealthy},
+		wantUpdate:     true,
+	},
+	{
+		name:      "BootTimeout",
+		projectID: "project",
+		db: &databaseMock{
+			project:    &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployBooting}},
+			deployment: &dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployFailed, Error: "Server did not respond within 10m0s of launch"},
+		},
+		deployer:       &providerMock{name: "ec2", instance: instance(provider.Running, "example.com", "")},
+		elapsed:        11 * time.Minute,
+		healthErr:      errors.NewServer("Connection refused"),
+		wantDeployment: &dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployFailed, Error: "Server did not respond within 10m0s of launch"},
+		wantUpdate:     true,
+	},
+	{
+		name:      "InstanceTerminated",
+		projectID: "project",
+		db: &databaseMock{
+			project:    deployedProject(dao.DeployBooting),
+			deployment: &dao.Deployment{InstanceID: "instance", Status: dao.DeployFailed, Error: "Instance is terminated"},
+		},
+		deployer:       &providerMock{name: "ec2", instance: instance(provider.Stopped, "", "Instance is terminated")},
+		wantDeployment: &dao.Deployment{InstanceID: "instance", Status: dao.DeployFailed, Error: "Instance is terminated"},
+		wantUpdate:     true,
+	},
+	{
+		name:      "UpdateDeploymentFailure",
+		projectID: "project",
+		db: &databaseMock{
+			project:    deployedProject(dao.DeployProvisioning),
+			deployment: &dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployHealthy},
+			updateErr:  errors.NewServer("Database failure"),
+		},
+		deployer:   &providerMock{name: "ec2", instance: instance(provider.Running, "example.com", "")},
+		wantUpdate: true,
+		wantErr:    errors.Wrap(errors.NewServer("Database failure"), "Failed to update deployment info"),
+	},
+	{
+		// The project is redeployed after its old instance is described, so the status of the old instance
+		// must not overwrite the new deployment.
+		name:      "Redeployed",
+		projectID: "project",
+		db: &databaseMock{
+			project:    deployedProject(dao.DeployProvisioning),
+			deployment: &dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployHealthy},
+			latest:     &dao.Project{ID: "project", Deployment: dao.Deployment{InstanceID: "newInstance", Status: dao.DeployPending}},
+		},
+		deployer:       &providerMock{name: "ec2", instance: instance(provider.Running, "example.com", "")},
+		wantDeployment: &dao.Deployment{InstanceID: "newInstance", Status: dao.DeployPending},
+		wantUpdate:     true,
+	},
+	{
+		name:        "UndeployedWithLogs",
+		projectID:   "project",
+		includeLogs: true,
+		db: &databaseMock{
+			project:    deployedProject(dao.DeployProvisioning),
+			deployment: &dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployHealthy},
+			latest:     &dao.Project{ID: "project"},
+		},
+		deployer:       &providerMock{name: "ec2", instance: instance(provider.Running, "example.com", "")},
+		wantDeployment: &dao.Deployment{},
+		wantUpdate:     true,
+	},
+	{
+		name:      "LatestDeploymentFailure",
+		projectID: "project",
+		db: &databaseMock{
+			project:    deployedProject(dao.DeployProvisioning),
+			deployment: &dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployHealthy},
+			latestErr:  errors.NewClient("Project 'project' not found"),
+		},
+		deployer:   &providerMock{name: "ec2", instance: instance(provider.Running, "example.com", "")},
+		wantUpdate: true,
+		wantErr:    errors.Wrap(errors.NewClient("Project 'project' not found"), "Failed to get project"),
+	},
+}
+
+func TestGetDeployment(t *testing.T) {
+	for _, test := range getDeploymentTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock("cookievalue", test.db, "test@example.com", test.verifyErr)
+			now = func() time.Time { return launchTime.Add(test.elapsed) }
+			checkHealth = func(url string) error {
+				if url != "example.com" && url != "localhost:32768" {
+					return errors.NewServer("Incorrect input to checkHealth mock")
+				}
+				return test.healthErr
+			}
+			defer func() {
+				now = time.Now
+				checkHealth = pingServer
+			}()
+
+			// Execute
+			deployment, logs, err := getDeployment("cookievalue", test.projectID, test.includeLogs, verifyCookie, test.db, test.deployer.lookup)
+
+			// Verify
+			if !reflect.DeepEqual(deployment, test.wantDeployment) {
+				t.Errorf("Got deployment %v; want %v", deployment, test.wantDeployment)
+			}
+			if logs != test.wantLogs {
+				t.Errorf("Got logs %q; want %q", logs, test.wantLogs)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+			if test.db != nil && test.db.updated != test.wantUpdate {
+				t.Errorf("Got update %t; want %t", test.db.updated, test.wantUpdate)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Package getdeployment handles requests to the GET /projects/{pid}/deploy REST API endpoint.
+package getdeployment
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+
+	// Register the supported deployment providers
+	_ "github.com/jackstenglein/rest_api_creator/backend/deploy/docker"
+	_ "github.com/jackstenglein/rest_api_creator/backend/deploy/ec2"
+)
+
+// getDeploymentResponse contains the fields returned in the API JSON response body.
+type getDeploymentResponse struct {
+	ID      string `json:"instanceId,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+	Logs    string `json:"logs,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (response *getDeploymentResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// getDeploymentFunc points to the function used to perform the getDeployment action. It should
+// not be changed except in unit tests.
+var getDeploymentFunc = getDeployment
+
+// HandleGetDeployment parses the request object from AWS APIGateway and passes it to the getDeployment action.
+// The request must contain a valid cookie or access token and a `pid` path parameter. If the request succeeds, the
+// response body will have the `status` of the deployment along with its `instanceId` and public `url` once they
+// are known, and a `message` explaining why the deployment failed if its status is `failed`. If the `logs` query
+// parameter is `true`, the body will also have the console output of the instance in a `logs` field. The body is
+// empty if the project has never been deployed. If the request fails, the response body will have an `error` field.
+func HandleGetDeployment(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	projectID := request.PathParameters["pid"]
+	cookie := auth.ExtractCredential(request.Headers)
+	includeLogs := request.QueryStringParameters["logs"] == "true"
+
+	// Perform the action
+	deployment, logs, err := getDeploymentFunc(cookie, projectID, includeLogs, auth.Verifier(auth.ScopeReadOnly, auth.ScopeDeploy), dao.Dynamo, provider.Lookup)
+	log.Error(err)
+
+	// Return the response
+	response := &getDeploymentResponse{}
+	if deployment != nil {
+		response = &getDeploymentResponse{
+			ID:      deployment.InstanceID,
+			URL:     deployment.URL,
+			Status:  deployment.Status,
+			Message: deployment.Error,
+			Logs:    logs,
+		}
+	}
+	return http.GatewayResponse(response, "", err), nil
+}
@@ -0,0 +1,113 @@
+package getdeployment
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type getDeploymentMockFunc func(string, string, bool, auth.VerifyCookieFunc, getDeploymentDatabase, provider.LookupFunc) (*dao.Deployment, string, error)
+
+func getDeploymentMock(wantCookie string, wantPID string, wantLogs bool, deployment *dao.Deployment, logs string, err error) getDeploymentMockFunc {
+	return func(cookie string, pid string, includeLogs bool, _ auth.VerifyCookieFunc, _ getDeploymentDatabase, _ provider.LookupFunc) (*dao.Deployment, string, error) {
+		if cookie != wantCookie || pid != wantPID || includeLogs != wantLogs {
+			return nil, "", errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return deployment, logs, err
+	}
+}
+
+func handlerRequest(cookie string, pid string, logs string) events.APIGatewayProxyRequest {
+	parameters := map[string]string{
+		"pid": pid,
+	}
+	headers := map[string]string{
+		"Cookie": cookie,
+	}
+	query := map[string]string{}
+	if logs != "" {
+		query["logs"] = logs
+	}
+	return events.APIGatewayProxyRequest{PathParameters: parameters, Headers: headers, QueryStringParameters: query}
+}
+
+func handlerResponse(body string, status int) events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		Body: body,
+		Headers: map[string]string{
+			"Access-Control-Allow-Origin":      os.Getenv("CORS_ORIGIN"),
+			"Access-Control-Allow-Credentials": "true",
+		},
+		StatusCode: status,
+	}
+}
+
+var handleGetDeploymentTests = []struct {
+	name string
+
+	request           events.APIGatewayProxyRequest
+	getDeploymentMock getDeploymentMockFunc
+
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:              "GetDeploymentFailure",
+		request:           handlerRequest("session=cookievalue", "projectId", ""),
+		getDeploymentMock: getDeploymentMock("cookievalue", "projectId", false, nil, "", errors.Wrap(errors.NewClient("Parameter `pid` is required"), "Failed")),
+		wantResponse:      handlerResponse(`{"error":"Parameter `+"`pid`"+` is required"}`, 400),
+	},
+	{
+		name:              "NotDeployed",
+		request:           handlerRequest("session=cookievalue", "projectId", ""),
+		getDeploymentMock: getDeploymentMock("cookievalue", "projectId", false, &dao.Deployment{}, "", nil),
+		wantResponse:      handlerResponse("{}", 200),
+	},
+	{
+		name:              "FailedDeployment",
+		request:           handlerRequest("session=cookievalue", "projectId", ""),
+		getDeploymentMock: getDeploymentMock("cookievalue", "projectId", false, &dao.Deployment{InstanceID: "instance", Status: dao.DeployFailed, Error: "Instance is stopped"}, "", nil),
+		wantResponse:      handlerResponse(`{"instanceId":"instance","status":"failed","message":"Instance is stopped"}`, 200),
+	},
+	{
+		name:              "HealthyDeployment",
+		request:           handlerRequest("session=cookievalue", "projectId", ""),
+		getDeploymentMock: getDeploymentMock("cookievalue", "projectId", false, &dao.Deployment{InstanceID: "instance", URL: "example.com", Status: dao.DeployHealthy}, "", nil),
+		wantResponse:      handlerResponse(`{"instanceId":"instance","url":"example.com","status":"healthy"}`, 200),
+	},
+	{
+		name:              "IncludeLogs",
+		request:           handlerRequest("session=cookievalue", "projectId", "true"),
+		getDeploymentMock: getDeploymentMock("cookievalue", "projectId", true, &dao.Deployment{InstanceID: "instance", Status: dao.DeployBooting}, "Lifting sails\n", nil),
+		wantResponse:      handlerResponse(`{"instanceId":"instance","status":"booting","logs":"Lifting sails\n"}`, 200),
+	},
+}
+
+func TestHandleGetDeployment(t *testing.T) {
+	for _, test := range handleGetDeploymentTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			getDeploymentFunc = test.getDeploymentMock
+			defer func() {
+				getDeploymentFunc = getDeployment
+			}()
+
+			// Execute
+			response, err := HandleGetDeployment(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
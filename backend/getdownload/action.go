@@ -1,10 +1,13 @@
 package getdownload
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/artifact"
 	"github.com/jackstenglein/rest_api_creator/backend/codegen/s3"
-	"github.com/jackstenglein/rest_api_creator/backend/codegen/sails"
-	"github.com/jackstenglein/rest_api_creator/backend/codegen/zip"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
@@ -12,80 +15,76 @@ import (
 // generateCodeDatabase wraps the database methods required to perform the generateCode
 // action. This interface is used to perform dependency injection in unit tests.
 type generateCodeDatabase interface {
-	auth.UserGetter
+	auth.CredentialStore
 	GetProject(string, string) (*dao.Project, error)
+	GetVersion(string, string, int) (*dao.ProjectVersion, error)
 }
 
-// cookieVerifier wraps the function type used to check the validity of the user's cookie.
-// This allows for dependency injection of the function.
-type cookieVerifier func(string, auth.UserGetter) (string, error)
-
 // These variables wrap the different functions that generateCode relies upon. They should
 // not be changed except for dependency injection within unit tests.
-var download = s3.Download
-var unzip = zip.Unzip
-var generate = sails.Generate
-var zipper = zip.Zip
-var upload = s3.Upload
+var lookup = codegen.Lookup
+var build = artifact.Build
 var presign = s3.Presign
 
 // generateCode performs the following steps:
-//		1. Download the blank project template from S3
-//		2. Unzip the template
-//		3. Generate the code for the given project
-//		4. Zip the generated code
-//		5. Upload the generated zip to S3
-// 		6. Generate a pre-signed URL to download the generated zip from S3
-// The pre-signed URL is returned, or an empty string if an error occurred.
-func generateCode(projectID string, cookie string, verifyCookie cookieVerifier, db generateCodeDatabase) (string, error) {
+//		1. Find the generator for the requested target
+//		2. Build the zipped code of the given project and upload it to S3 (see artifact.Build)
+// 		3. Generate a pre-signed URL to download the generated zip from S3
+// If target is empty, codegen.DefaultTarget is used. If from is not empty, it must be a version older than the
+// current version of the project, and the zip also contains the migration that upgrades a database created
+// from that version. The pre-signed URL is returned, or an empty string if an error occurred.
+func generateCode(projectID string, target string, from string, cookie string, verifyCookie auth.VerifyCookieFunc, db generateCodeDatabase) (string, error) {
 	if cookie == "" {
 		return "", errors.NewClient("Not authenticated")
 	}
 	if projectID == "" {
 		return "", errors.NewClient("Parameter `pid` is required")
 	}
-
-	email, err := verifyCookie(cookie, db)
-	if err != nil {
-		return "", errors.Wrap(err, "Failed to verify cookie")
-	}
-
-	project, err := db.GetProject(email, projectID)
-	if err != nil {
-		return "", errors.Wrap(err, "Failed to get project from database")
+	fromVersion := 0
+	if from != "" {
+		number, err := strconv.Atoi(from)
+		if err != nil || number < 1 {
+			return "", errors.NewClient("Parameter `from` must be a positive integer")
+		}
+		fromVersion = number
 	}
 
-	// Download the project template from S3
-	err = download("/tmp/blank-sails.zip", "templates/sails.zip")
+	generator, err := lookup(target)
 	if err != nil {
-		return "", errors.Wrap(err, "Failed to get project template from S3")
+		return "", errors.Wrap(err, "Failed to find code generator")
 	}
 
-	// Unzip the template
-	err = unzip("/tmp/blank-sails.zip", "/tmp")
+	email, err := verifyCookie(cookie, db)
 	if err != nil {
-		return "", errors.Wrap(err, "Failed to unzip project template")
+		return "", errors.Wrap(err, "Failed to verify cookie")
 	}
 
-	// Generate the code
-	err = generate(project, "/tmp/defaultProject")
+	project, err := db.GetProject(email, projectID)
 	if err != nil {
-		return "", errors.Wrap(err, "Failed to generate project code")
+		return "", errors.Wrap(err, "Failed to get project from database")
 	}
 
-	// Zip the generated code
-	err = zipper("/tmp/generated-project.zip", "/tmp/defaultProject")
-	if err != nil {
-		return "", errors.Wrap(err, "Failed to zip generated code")
+	var base *dao.Project
+	if fromVersion > 0 {
+		if fromVersion >= project.Version {
+			return "", errors.NewClient(fmt.Sprintf("Parameter `from` must be older than the current version %d", project.Version))
+		}
+		projectVersion, err := db.GetVersion(email, projectID, fromVersion)
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to get version from database")
+		}
+		if projectVersion.Project == nil {
+			return "", errors.NewServer(fmt.Sprintf("Version %d of project '%s' has no snapshot", fromVersion, projectID))
+		}
+		base = projectVersion.Project
 	}
 
-	// Upload generated zip to S3
-	err = upload("/tmp/generated-project.zip", email+"/defaultProject.zip")
+	key, err := build(generator, email, projectID, project, base)
 	if err != nil {
-		return "", errors.Wrap(err, "Failed to upload generated zip to S3")
+		return "", errors.Wrap(err, "Failed to build generated code")
 	}
 
 	// Create the pre-signed URL to download the code
-	url, err := presign(email + "/defaultProject.zip")
+	url, err := presign(key)
 	return url, errors.Wrap(err, "Failed to generate pre-signed URL")
 }
@@ -3,8 +3,10 @@ package getdownload
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
@@ -12,18 +14,42 @@ import (
 type databaseMock struct {
 	project *dao.Project
 	err     error
+
+	// version is returned by GetVersion when it is called with wantVersion.
+	wantVersion int
+	version     *dao.ProjectVersion
+	versionErr  error
 }
 
 func (mock *databaseMock) GetProject(email string, projectID string) (*dao.Project, error) {
 	return mock.project, mock.err
 }
 
-func (mock *databaseMock) GetUserInfo(email string) (*dao.User, error) {
+func (mock *databaseMock) GetVersion(email string, projectID string, version int) (*dao.ProjectVersion, error) {
+	if email != "test@example.com" || projectID != "projectID" || version != mock.wantVersion {
+		return nil, errors.NewServer("Incorrect input to GetVersion mock")
+	}
+	return mock.version, mock.versionErr
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
 	return nil, nil
 }
 
-func verifyCookieMock(mockCookie string, mockDB auth.UserGetter, mockEmail string, mockErr error) cookieVerifier {
-	return func(cookie string, db auth.UserGetter) (string, error) {
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
 		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
 			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
 		}
@@ -31,21 +57,37 @@ func verifyCookieMock(mockCookie string, mockDB auth.UserGetter, mockEmail strin
 	}
 }
 
-func createMock(mock1 string, mock2 string, mockErr error) func(string, string) error {
-	return func(in1 string, in2 string) error {
-		if in1 != mock1 || in2 != mock2 {
-			return errors.NewServer("Incorrect input to mock")
+type generatorMock struct {
+	name string
+}
+
+func (mock *generatorMock) Name() string {
+	return mock.name
+}
+
+func (mock *generatorMock) TemplateKey() string {
+	return ""
+}
+
+func (mock *generatorMock) Generate(project *dao.Project, rootDir string) error {
+	return errors.NewServer("Unexpected call to Generate mock")
+}
+
+func lookupMock(wantTarget string, generator codegen.Generator, err error) func(string) (codegen.Generator, error) {
+	return func(target string) (codegen.Generator, error) {
+		if target != wantTarget {
+			return nil, errors.NewServer("Incorrect input to lookup mock")
 		}
-		return mockErr
+		return generator, err
 	}
 }
 
-func generatorMock(mockProject *dao.Project, mockPath string, mockErr error) func(*dao.Project, string) error {
-	return func(project *dao.Project, dirPath string) error {
-		if !reflect.DeepEqual(project, mockProject) || dirPath != mockPath {
-			return errors.NewServer("Incorrect input to generate mock")
+func buildMock(wantGenerator codegen.Generator, wantProject *dao.Project, wantBase *dao.Project, key string, err error) func(codegen.Generator, string, string, *dao.Project, *dao.Project) (string, error) {
+	return func(generator codegen.Generator, email string, projectID string, project *dao.Project, base *dao.Project) (string, error) {
+		if generator != wantGenerator || email != "test@example.com" || projectID != "projectID" || !reflect.DeepEqual(project, wantProject) || base != wantBase {
+			return "", errors.NewServer("Incorrect input to build mock")
 		}
-		return mockErr
+		return key, err
 	}
 }
 
@@ -58,23 +100,32 @@ func presignMock(mockKey string, mockURL string, mockErr error) func(string) (st
 	}
 }
 
+var defaultProject = &dao.Project{Name: "Default Project", ID: "defaultProject"}
+
+var versionedProject = &dao.Project{Name: "Default Project", ID: "defaultProject", Version: 5}
+
+var baseProject = &dao.Project{Name: "Old Project", ID: "defaultProject", Version: 3}
+
+var sailsGenerator = &generatorMock{name: "sails"}
+
+var goGenerator = &generatorMock{name: "go"}
+
 var generateCodeTests = []struct {
 	name string
 
 	// Input
 	projectID string
+	target    string
+	from      string
 	cookie    string
 
 	// Mock data
-	db         *databaseMock
-	email      string
-	verifyErr  error
-	downloader func(string, string) error
-	unzipper   func(string, string) error
-	generator  func(*dao.Project, string) error
-	zipper     func(string, string) error
-	uploader   func(string, string) error
-	presigner  func(string) (string, error)
+	db        *databaseMock
+	email     string
+	verifyErr error
+	lookup    func(string) (codegen.Generator, error)
+	build     func(codegen.Generator, string, string, *dao.Project, *dao.Project) (string, error)
+	presigner func(string) (string, error)
 
 	// Expected output
 	wantURL string
@@ -89,10 +140,19 @@ var generateCodeTests = []struct {
 		cookie:  "TestCookie",
 		wantErr: errors.NewClient("Parameter `pid` is required"),
 	},
+	{
+		name:      "UnsupportedTarget",
+		cookie:    "validcookie",
+		projectID: "projectID",
+		target:    "rails",
+		lookup:    lookupMock("rails", nil, errors.NewClient("Unsupported target `rails`")),
+		wantErr:   errors.Wrap(errors.NewClient("Unsupported target `rails`"), "Failed to find code generator"),
+	},
 	{
 		name:      "InvalidCookie",
 		cookie:    "invalidcookie",
 		projectID: "projectID",
+		lookup:    lookupMock("", sailsGenerator, nil),
 		verifyErr: errors.NewClient("Not authenticated"),
 		wantErr:   errors.Wrap(errors.NewClient("Not authenticated"), "Failed to verify cookie"),
 	},
@@ -101,91 +161,92 @@ var generateCodeTests = []struct {
 		cookie:    "validcookie",
 		projectID: "projectID",
 		email:     "test@example.com",
-		db:        &databaseMock{nil, errors.NewServer("DynamoDB failure")},
+		db:        &databaseMock{err: errors.NewServer("DynamoDB failure")},
+		lookup:    lookupMock("", sailsGenerator, nil),
 		wantErr:   errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to get project from database"),
 	},
 	{
-		name:       "DownloadError",
-		cookie:     "validcookie",
-		projectID:  "projectID",
-		email:      "test@example.com",
-		db:         &databaseMock{&dao.Project{Name: "Default Project", ID: "defaultProject"}, nil},
-		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", errors.NewServer("S3 failure")),
-		wantErr:    errors.Wrap(errors.NewServer("S3 failure"), "Failed to get project template from S3"),
+		name:      "BuildError",
+		cookie:    "validcookie",
+		projectID: "projectID",
+		email:     "test@example.com",
+		db:        &databaseMock{project: defaultProject},
+		lookup:    lookupMock("", sailsGenerator, nil),
+		build:     buildMock(sailsGenerator, defaultProject, nil, "", errors.Wrap(errors.NewServer("Zip failure"), "Failed to zip generated code")),
+		wantErr:   errors.Wrap(errors.Wrap(errors.NewServer("Zip failure"), "Failed to zip generated code"), "Failed to build generated code"),
 	},
 	{
-		name:       "UnzipError",
-		cookie:     "validcookie",
-		projectID:  "projectID",
-		email:      "test@example.com",
-		db:         &databaseMock{&dao.Project{Name: "Default Project", ID: "defaultProject"}, nil},
-		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", nil),
-		unzipper:   createMock("/tmp/blank-sails.zip", "/tmp", errors.NewServer("Unzip failure")),
-		wantErr:    errors.Wrap(errors.NewServer("Unzip failure"), "Failed to unzip project template"),
+		name:      "PresignError",
+		cookie:    "validcookie",
+		projectID: "projectID",
+		email:     "test@example.com",
+		db:        &databaseMock{project: defaultProject},
+		lookup:    lookupMock("", sailsGenerator, nil),
+		build:     buildMock(sailsGenerator, defaultProject, nil, "test@example.com/projectID/sails.zip", nil),
+		presigner: presignMock("test@example.com/projectID/sails.zip", "", errors.NewServer("Presign failure")),
+		wantErr:   errors.Wrap(errors.NewServer("Presign failure"), "Failed to generate pre-signed URL"),
 	},
 	{
-		name:       "GenerateError",
-		cookie:     "validcookie",
-		projectID:  "projectID",
-		email:      "test@example.com",
-		db:         &databaseMock{&dao.Project{Name: "Default Project", ID: "defaultProject"}, nil},
-		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", nil),
-		unzipper:   createMock("/tmp/blank-sails.zip", "/tmp", nil),
-		generator:  generatorMock(&dao.Project{Name: "Default Project", ID: "defaultProject"}, "/tmp/defaultProject", errors.NewServer("Generate failure")),
-		wantErr:    errors.Wrap(errors.NewServer("Generate failure"), "Failed to generate project code"),
+		name:      "SuccessfulInvocation",
+		cookie:    "validcookie",
+		projectID: "projectID",
+		email:     "test@example.com",
+		db:        &databaseMock{project: defaultProject},
+		lookup:    lookupMock("", sailsGenerator, nil),
+		build:     buildMock(sailsGenerator, defaultProject, nil, "test@example.com/projectID/sails.zip", nil),
+		presigner: presignMock("test@example.com/projectID/sails.zip", "example.com", nil),
+		wantURL:   "example.com",
 	},
 	{
-		name:       "ZipError",
-		cookie:     "validcookie",
-		projectID:  "projectID",
-		email:      "test@example.com",
-		db:         &databaseMock{&dao.Project{Name: "Default Project", ID: "defaultProject"}, nil},
-		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", nil),
-		unzipper:   createMock("/tmp/blank-sails.zip", "/tmp", nil),
-		generator:  generatorMock(&dao.Project{Name: "Default Project", ID: "defaultProject"}, "/tmp/defaultProject", nil),
-		zipper:     createMock("/tmp/generated-project.zip", "/tmp/defaultProject", errors.NewServer("Zip failure")),
-		wantErr:    errors.Wrap(errors.NewServer("Zip failure"), "Failed to zip generated code"),
+		name:      "OtherTarget",
+		cookie:    "validcookie",
+		projectID: "projectID",
+		target:    "go",
+		email:     "test@example.com",
+		db:        &databaseMock{project: defaultProject},
+		lookup:    lookupMock("go", goGenerator, nil),
+		build:     buildMock(goGenerator, defaultProject, nil, "test@example.com/projectID/go.zip", nil),
+		presigner: presignMock("test@example.com/projectID/go.zip", "example.com", nil),
+		wantURL:   "example.com",
 	},
 	{
-		name:       "UploadError",
-		cookie:     "validcookie",
-		projectID:  "projectID",
-		email:      "test@example.com",
-		db:         &databaseMock{&dao.Project{Name: "Default Project", ID: "defaultProject"}, nil},
-		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", nil),
-		unzipper:   createMock("/tmp/blank-sails.zip", "/tmp", nil),
-		generator:  generatorMock(&dao.Project{Name: "Default Project", ID: "defaultProject"}, "/tmp/defaultProject", nil),
-		zipper:     createMock("/tmp/generated-project.zip", "/tmp/defaultProject", nil),
-		uploader:   createMock("/tmp/generated-project.zip", "test@example.com/defaultProject.zip", errors.NewServer("Upload failure")),
-		wantErr:    errors.Wrap(errors.NewServer("Upload failure"), "Failed to upload generated zip to S3"),
+		name:      "InvalidFrom",
+		cookie:    "validcookie",
+		projectID: "projectID",
+		from:      "latest",
+		wantErr:   errors.NewClient("Parameter `from` must be a positive integer"),
 	},
 	{
-		name:       "PresignError",
-		cookie:     "validcookie",
-		projectID:  "projectID",
-		email:      "test@example.com",
-		db:         &databaseMock{&dao.Project{Name: "Default Project", ID: "defaultProject"}, nil},
-		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", nil),
-		unzipper:   createMock("/tmp/blank-sails.zip", "/tmp", nil),
-		generator:  generatorMock(&dao.Project{Name: "Default Project", ID: "defaultProject"}, "/tmp/defaultProject", nil),
-		zipper:     createMock("/tmp/generated-project.zip", "/tmp/defaultProject", nil),
-		uploader:   createMock("/tmp/generated-project.zip", "test@example.com/defaultProject.zip", nil),
-		presigner:  presignMock("test@example.com/defaultProject.zip", "", errors.NewServer("Presign failure")),
-		wantErr:    errors.Wrap(errors.NewServer("Presign failure"), "Failed to generate pre-signed URL"),
+		name:      "FromCurrentVersion",
+		cookie:    "validcookie",
+		projectID: "projectID",
+		from:      "5",
+		email:     "test@example.com",
+		db:        &databaseMock{project: versionedProject},
+		lookup:    lookupMock("", sailsGenerator, nil),
+		wantErr:   errors.NewClient("Parameter `from` must be older than the current version 5"),
 	},
 	{
-		name:       "SuccessfulInvocation",
-		cookie:     "validcookie",
-		projectID:  "projectID",
-		email:      "test@example.com",
-		db:         &databaseMock{&dao.Project{Name: "Default Project", ID: "defaultProject"}, nil},
-		downloader: createMock("/tmp/blank-sails.zip", "templates/sails.zip", nil),
-		unzipper:   createMock("/tmp/blank-sails.zip", "/tmp", nil),
-		generator:  generatorMock(&dao.Project{Name: "Default Project", ID: "defaultProject"}, "/tmp/defaultProject", nil),
-		zipper:     createMock("/tmp/generated-project.zip", "/tmp/defaultProject", nil),
-		uploader:   createMock("/tmp/generated-project.zip", "test@example.com/defaultProject.zip", nil),
-		presigner:  presignMock("test@example.com/defaultProject.zip", "example.com", nil),
-		wantURL:    "example.com",
+		name:      "GetVersionError",
+		cookie:    "validcookie",
+		projectID: "projectID",
+		from:      "3",
+		email:     "test@example.com",
+		db:        &databaseMock{project: versionedProject, wantVersion: 3, versionErr: errors.NewClient("Version 3 of project 'projectID' not found")},
+		lookup:    lookupMock("", sailsGenerator, nil),
+		wantErr:   errors.Wrap(errors.NewClient("Version 3 of project 'projectID' not found"), "Failed to get version from database"),
+	},
+	{
+		name:      "FromVersion",
+		cookie:    "validcookie",
+		projectID: "projectID",
+		from:      "3",
+		email:     "test@example.com",
+		db:        &databaseMock{project: versionedProject, wantVersion: 3, version: &dao.ProjectVersion{Version: 3, Project: baseProject}},
+		lookup:    lookupMock("", sailsGenerator, nil),
+		build:     buildMock(sailsGenerator, versionedProject, baseProject, "test@example.com/projectID/sails.zip", nil),
+		presigner: presignMock("test@example.com/projectID/sails.zip", "example.com", nil),
+		wantURL:   "example.com",
 	},
 }
 
@@ -194,15 +255,12 @@ func TestGenerateCode(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// Setup
 			verifyCookie := verifyCookieMock(test.cookie, test.db, test.email, test.verifyErr)
-			download = test.downloader
-			unzip = test.unzipper
-			generate = test.generator
-			zipper = test.zipper
-			upload = test.uploader
+			lookup = test.lookup
+			build = test.build
 			presign = test.presigner
 
 			// Execute
-			url, err := generateCode(test.projectID, test.cookie, verifyCookie, test.db)
+			url, err := generateCode(test.projectID, test.target, test.from, test.cookie, verifyCookie, test.db)
 
 			// Verify
 			if url != test.wantURL {
@@ -5,6 +5,11 @@ import (
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
 	"github.com/jackstenglein/rest_api_creator/backend/http"
+
+	// Register the supported code generators
+	_ "github.com/jackstenglein/rest_api_creator/backend/codegen/express"
+	_ "github.com/jackstenglein/rest_api_creator/backend/codegen/gohttp"
+	_ "github.com/jackstenglein/rest_api_creator/backend/codegen/sails"
 )
 
 type getDownloadResponse struct {
@@ -20,18 +25,23 @@ func (response *getDownloadResponse) SetError(err string) {
 
 var actionFunc = generateCode
 
-// HandleRequest parses the request object from AWS APIGateway and returns a response object containing a
-// URL to download the generated code for the project. The project id must be passed in the `pid` path parameter,
-// and the request must contain a valid `Cookie` header. If the request succeeds, the response will have a 200 status,
-// and the body will have a `url` field. If the request fails, the response will have either a 400 or a 500 status,
-// and the body will have an `error` field.
+// HandleRequest parses the request object from AWS APIGateway and returns a response object containing a URL
+// to download the generated code for the project. The project id must be passed in the `pid` path parameter,
+// and the request must contain a valid cookie or access token. The optional `target` query parameter selects
+// the framework of the generated code (`sails`, `express` or `go`); if it is omitted, Sails.js code is
+// generated. The optional `from` query parameter is an older version of the project; if it is given, the zip
+// also contains the migration from that version in `db/migrations/`. If the request succeeds, the response
+// will have a 200 status, and the body will have a `url` field. If the request fails, the response will have
+// either a 400 or a 500 status, and the body will have an `error` field.
 func HandleRequest(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	// Get request parameters
 	projectID := request.PathParameters["pid"]
-	cookie := auth.ExtractCookie(request.Headers["Cookie"])
+	target := request.QueryStringParameters["target"]
+	from := request.QueryStringParameters["from"]
+	cookie := auth.ExtractCredential(request.Headers)
 
 	// Perform the action
-	url, err := actionFunc(projectID, cookie, auth.VerifyCookie, dao.Dynamo)
+	url, err := actionFunc(projectID, target, from, cookie, auth.Verifier(auth.ScopeCodegen), dao.Dynamo)
 
 	// Handle the output
 	response := &getDownloadResponse{URL: url}
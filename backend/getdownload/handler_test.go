@@ -7,28 +7,35 @@ import (
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
 
-type generateCodeFunc func(string, string, cookieVerifier, generateCodeDatabase) (string, error)
+type generateCodeFunc func(string, string, string, string, auth.VerifyCookieFunc, generateCodeDatabase) (string, error)
 
-func generateCodeMock(wantProjectID string, wantCookie string, url string, err error) generateCodeFunc {
-	return func(gotProjectID string, gotCookie string, _ cookieVerifier, _ generateCodeDatabase) (string, error) {
-		if gotProjectID != wantProjectID || gotCookie != wantCookie {
+func generateCodeMock(wantProjectID string, wantTarget string, wantFrom string, wantCookie string, url string, err error) generateCodeFunc {
+	return func(gotProjectID string, gotTarget string, gotFrom string, gotCookie string, _ auth.VerifyCookieFunc, _ generateCodeDatabase) (string, error) {
+		if gotProjectID != wantProjectID || gotTarget != wantTarget || gotFrom != wantFrom || gotCookie != wantCookie {
 			return "", errors.NewServer("Incorrect parameters passed to mock")
 		}
 		return url, err
 	}
 }
 
-func handlerRequest(projectID string, cookie string) events.APIGatewayProxyRequest {
+func handlerRequest(projectID string, target string, cookie string) events.APIGatewayProxyRequest {
 	parameters := map[string]string{
 		"pid": projectID,
 	}
+	var query map[string]string
+	if target != "" {
+		query = map[string]string{
+			"target": target,
+		}
+	}
 	headers := map[string]string{
 		"Cookie": cookie,
 	}
-	return events.APIGatewayProxyRequest{PathParameters: parameters, Headers: headers}
+	return events.APIGatewayProxyRequest{PathParameters: parameters, QueryStringParameters: query, Headers: headers}
 }
 
 func handlerResponse(url string, err string, status int) events.APIGatewayProxyResponse {
@@ -48,6 +55,8 @@ var handlerTests = []struct {
 
 	// Mock data
 	mockProjectID string
+	mockTarget    string
+	mockFrom      string
 	mockCookie    string
 	mockURL       string
 	mockErr       error
@@ -58,7 +67,7 @@ var handlerTests = []struct {
 }{
 	{
 		name:          "GetProjectError",
-		request:       handlerRequest("default", "session=cookie"),
+		request:       handlerRequest("default", "", "session=cookie"),
 		mockProjectID: "default",
 		mockCookie:    "cookie",
 		mockErr:       errors.NewServer("Failed to get project"),
@@ -66,8 +75,30 @@ var handlerTests = []struct {
 	},
 	{
 		name:          "SucccessfulInvocation",
-		request:       handlerRequest("default", "session=cookie"),
+		request:       handlerRequest("default", "", "session=cookie"),
+		mockProjectID: "default",
+		mockCookie:    "cookie",
+		mockURL:       "presigned-url.com",
+		wantResponse:  handlerResponse("presigned-url.com", "", 200),
+	},
+	{
+		name:          "TargetParameter",
+		request:       handlerRequest("default", "express", "session=cookie"),
+		mockProjectID: "default",
+		mockTarget:    "express",
+		mockCookie:    "cookie",
+		mockURL:       "presigned-url.com",
+		wantResponse:  handlerResponse("presigned-url.com", "", 200),
+	},
+	{
+		name: "FromParameter",
+		request: events.APIGatewayProxyRequest{
+			PathParameters:        map[string]string{"pid": "default"},
+			QueryStringParameters: map[string]string{"from": "3"},
+			Headers:               map[string]string{"Cookie": "session=cookie"},
+		},
 		mockProjectID: "default",
+		mockFrom:      "3",
 		mockCookie:    "cookie",
 		mockURL:       "presigned-url.com",
 		wantResponse:  handlerResponse("presigned-url.com", "", 200),
@@ -78,7 +109,7 @@ func TestHandleRequest(t *testing.T) {
 	for _, test := range handlerTests {
 		t.Run("", func(t *testing.T) {
 			// Setup
-			actionFunc = generateCodeMock(test.mockProjectID, test.mockCookie, test.mockURL, test.mockErr)
+			actionFunc = generateCodeMock(test.mockProjectID, test.mockTarget, test.mockFrom, test.mockCookie, test.mockURL, test.mockErr)
 			defer func() {
 				actionFunc = generateCode
 			}()
@@ -0,0 +1,70 @@
+package getopenapi
+
+import (
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/openapi"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// Media types of the documents returned by the getOpenAPI action.
+const (
+	jsonContentType = "application/json"
+	yamlContentType = "application/yaml"
+)
+
+// getOpenAPIDatabase wraps the database methods required to perform the getOpenAPI action.
+// This allows for dependency injection of the database.
+type getOpenAPIDatabase interface {
+	auth.CredentialStore
+	GetProject(string, string) (*dao.Project, error)
+}
+
+// negotiateContentType returns the media type of the document requested by the given Accept header. The
+// media ranges are checked in order, and the first one naming JSON or YAML is used. If none of them do,
+// JSON is returned.
+func negotiateContentType(accept string) string {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.Split(mediaRange, ";")[0])
+		switch strings.ToLower(mediaType) {
+		case "application/json":
+			return jsonContentType
+		case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+			return yamlContentType
+		}
+	}
+	return jsonContentType
+}
+
+// getOpenAPI returns the OpenAPI document of the code generated for the given projectID and target along with its
+// media type, which is chosen using the given Accept header. If target is empty, codegen.DefaultTarget is used. If
+// an error occurs, the returned document is nil.
+func getOpenAPI(cookie string, projectID string, target string, accept string, verifyCookie auth.VerifyCookieFunc, db getOpenAPIDatabase) ([]byte, string, error) {
+	if projectID == "" {
+		return nil, "", errors.NewClient("Parameter `projectID` is required")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return nil, "", errors.NewClient("Not authenticated")
+	}
+
+	project, err := db.GetProject(email, projectID)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Failed to get project")
+	}
+
+	contentType := negotiateContentType(accept)
+	var document []byte
+	if contentType == yamlContentType {
+		document, err = openapi.YAML(project, target)
+	} else {
+		document, err = openapi.JSON(project, target)
+	}
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Failed to render OpenAPI document")
+	}
+	return document, contentType, nil
+}
@@ -0,0 +1,185 @@
+package getopenapi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email     string
+	projectID string
+	project   *dao.Project
+	err       error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetProject(email string, projectID string) (*dao.Project, error) {
+	if email != mock.email || projectID != mock.projectID {
+		return nil, errors.NewServer("Incorrect input to GetProject mock")
+	}
+	return mock.project, mock.err
+}
+
+var emptyProject = &dao.Project{ID: "projectID", Name: "Project", Objects: map[string]*dao.Object{}}
+
+const emptyJSON = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Project",
+    "version": "1.0.0"
+  },
+  "paths": {},
+  "components": {}
+}
+`
+
+const emptyYAML = `openapi: 3.0.3
+info:
+  title: Project
+  version: 1.0.0
+paths: {}
+components: {}
+`
+
+var getOpenAPITests = []struct {
+	name string
+
+	// Input
+	cookie    string
+	projectID string
+	target    string
+	accept    string
+
+	// Mock data
+	db        *databaseMock
+	email     string
+	verifyErr error
+
+	// Expected output
+	wantDocument    string
+	wantContentType string
+	wantErr         error
+}{
+	{
+		name:    "EmptyProjectID",
+		cookie:  "cookie",
+		wantErr: errors.NewClient("Parameter `projectID` is required"),
+	},
+	{
+		name:      "InvalidCookie",
+		cookie:    "cookie",
+		projectID: "projectID",
+		verifyErr: errors.NewClient("Invalid cookie"),
+		wantErr:   errors.NewClient("Not authenticated"),
+	},
+	{
+		name:      "GetProjectFailure",
+		cookie:    "cookie",
+		projectID: "projectID",
+		db:        &databaseMock{email: "test@example.com", projectID: "projectID", err: errors.NewClient("Invalid project id")},
+		email:     "test@example.com",
+		wantErr:   errors.Wrap(errors.NewClient("Invalid project id"), "Failed to get project"),
+	},
+	{
+		name:      "RenderFailure",
+		cookie:    "cookie",
+		projectID: "projectID",
+		db: &databaseMock{email: "test@example.com", projectID: "projectID", project: &dao.Project{
+			Objects: map[string]*dao.Object{"book": nil},
+		}},
+		email: "test@example.com",
+		wantErr: errors.Wrap(errors.Wrap(errors.Wrap(errors.NewServer("Object cannot be nil"), "Failed to create schema for object book"),
+			"Failed to build OpenAPI document"), "Failed to render OpenAPI document"),
+	},
+	{
+		name:      "UnsupportedTarget",
+		cookie:    "cookie",
+		projectID: "projectID",
+		target:    "rails",
+		db:        &databaseMock{email: "test@example.com", projectID: "projectID", project: emptyProject},
+		email:     "test@example.com",
+		wantErr: errors.Wrap(errors.Wrap(errors.NewClient("Unsupported target `rails`. Supported targets are: express, gohttp, sails"),
+			"Failed to build OpenAPI document"), "Failed to render OpenAPI document"),
+	},
+	{
+		name:            "NoAcceptHeader",
+		cookie:          "cookie",
+		projectID:       "projectID",
+		db:              &databaseMock{email: "test@example.com", projectID: "projectID", project: emptyProject},
+		email:           "test@example.com",
+		wantDocument:    emptyJSON,
+		wantContentType: "application/json",
+	},
+	{
+		name:            "AcceptYAML",
+		cookie:          "cookie",
+		projectID:       "projectID",
+		accept:          "text/html;q=0.9, application/x-yaml, application/json;q=0.8",
+		db:              &databaseMock{email: "test@example.com", projectID: "projectID", project: emptyProject},
+		email:           "test@example.com",
+		wantDocument:    emptyYAML,
+		wantContentType: "application/yaml",
+	},
+	{
+		name:            "AcceptJSON",
+		cookie:          "cookie",
+		projectID:       "projectID",
+		accept:          "application/json, application/yaml",
+		db:              &databaseMock{email: "test@example.com", projectID: "projectID", project: emptyProject},
+		email:           "test@example.com",
+		wantDocument:    emptyJSON,
+		wantContentType: "application/json",
+	},
+}
+
+func TestGetOpenAPI(t *testing.T) {
+	for _, test := range getOpenAPITests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock(test.cookie, test.db, test.email, test.verifyErr)
+
+			// Execute
+			document, contentType, err := getOpenAPI(test.cookie, test.projectID, test.target, test.accept, verifyCookie, test.db)
+
+			// Verify
+			if string(document) != test.wantDocument {
+				t.Errorf("Got document:\n%s\nwant:\n%s", document, test.wantDocument)
+			}
+			if contentType != test.wantContentType {
+				t.Errorf("Got content type %s; want %s", contentType, test.wantContentType)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Package getopenapi handles requests to the GET /projects/{pid}/openapi REST API endpoint.
+package getopenapi
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// getOpenAPIResponse contains the fields returned in the API JSON response body when the request fails.
+type getOpenAPIResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+func (response *getOpenAPIResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// getOpenAPIFunc points to the function used to perform the getOpenAPI action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var getOpenAPIFunc = getOpenAPI
+
+// HandleGetOpenAPI parses the request from AWS APIGateway and passes it to the getOpenAPI action. The request
+// must contain a valid cookie or access token and a `pid` path parameter. The optional `target` query parameter selects
+// the framework whose generated code the document describes. If the `Accept` header, in any case, requests YAML (for
+// example `application/yaml`), the document is returned as YAML; otherwise it is returned as JSON. If the
+// request succeeds, the response will have a 200 status and the OpenAPI document as its body. If the request
+// fails, the response will have either a 400 or 500 status and a JSON body with an `error` field.
+func HandleGetOpenAPI(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	cookie := auth.ExtractCredential(request.Headers)
+	projectID := request.PathParameters["pid"]
+	target := request.QueryStringParameters["target"]
+	accept := http.Header(request.Headers, "Accept")
+
+	// Perform the action
+	document, contentType, err := getOpenAPIFunc(cookie, projectID, target, accept, auth.Verifier(auth.ScopeReadOnly, auth.ScopeCodegen), dao.Dynamo)
+	log.Error(err)
+
+	// Return the response
+	if err != nil {
+		return http.GatewayResponse(&getOpenAPIResponse{}, "", err), nil
+	}
+	return http.DocumentResponse(document, contentType), nil
+}
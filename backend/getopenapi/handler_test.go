@@ -0,0 +1,109 @@
+package getopenapi
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type getOpenAPIMockFunc func(string, string, string, string, auth.VerifyCookieFunc, getOpenAPIDatabase) ([]byte, string, error)
+
+func getOpenAPIMock(wantCookie string, wantPID string, wantTarget string, wantAccept string, document string, contentType string, err error) getOpenAPIMockFunc {
+	return func(cookie string, pid string, target string, accept string, _ auth.VerifyCookieFunc, _ getOpenAPIDatabase) ([]byte, string, error) {
+		if cookie != wantCookie || pid != wantPID || target != wantTarget || accept != wantAccept {
+			return nil, "", errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return []byte(document), contentType, err
+	}
+}
+
+func handlerRequest(cookie string, pid string, headers map[string]string) events.APIGatewayProxyRequest {
+	parameters := map[string]string{
+		"pid": pid,
+	}
+	headers["Cookie"] = cookie
+	return events.APIGatewayProxyRequest{PathParameters: parameters, Headers: headers}
+}
+
+func handlerResponse(body string, contentType string, status int) events.APIGatewayProxyResponse {
+	headers := map[string]string{
+		"Access-Control-Allow-Origin":      os.Getenv("CORS_ORIGIN"),
+		"Access-Control-Allow-Credentials": "true",
+	}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	return events.APIGatewayProxyResponse{Body: body, Headers: headers, StatusCode: status}
+}
+
+var handleGetOpenAPITests = []struct {
+	name string
+
+	request        events.APIGatewayProxyRequest
+	getOpenAPIMock getOpenAPIMockFunc
+
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:           "GetOpenAPIFailure",
+		request:        handlerRequest("session=cookievalue", "projectId", map[string]string{}),
+		getOpenAPIMock: getOpenAPIMock("cookievalue", "projectId", "", "", "", "", errors.Wrap(errors.NewClient("Invalid project id"), "Failed to get project")),
+		wantResponse:   handlerResponse(`{"error":"Invalid project id"}`, "", 400),
+	},
+	{
+		name:           "AcceptHeader",
+		request:        handlerRequest("session=cookievalue", "projectId", map[string]string{"Accept": "application/yaml"}),
+		getOpenAPIMock: getOpenAPIMock("cookievalue", "projectId", "", "application/yaml", "openapi: 3.0.3\n", "application/yaml", nil),
+		wantResponse:   handlerResponse("openapi: 3.0.3\n", "application/yaml", 200),
+	},
+	{
+		name:           "LowercaseAcceptHeader",
+		request:        handlerRequest("session=cookievalue", "projectId", map[string]string{"accept": "application/json"}),
+		getOpenAPIMock: getOpenAPIMock("cookievalue", "projectId", "", "application/json", `{"openapi":"3.0.3"}`, "application/json", nil),
+		wantResponse:   handlerResponse(`{"openapi":"3.0.3"}`, "application/json", 200),
+	},
+	{
+		name:           "UppercaseAcceptHeader",
+		request:        handlerRequest("session=cookievalue", "projectId", map[string]string{"ACCEPT": "application/yaml"}),
+		getOpenAPIMock: getOpenAPIMock("cookievalue", "projectId", "", "application/yaml", "openapi: 3.0.3\n", "application/yaml", nil),
+		wantResponse:   handlerResponse("openapi: 3.0.3\n", "application/yaml", 200),
+	},
+	{
+		name: "Target",
+		request: events.APIGatewayProxyRequest{
+			PathParameters:        map[string]string{"pid": "projectId"},
+			QueryStringParameters: map[string]string{"target": "express"},
+			Headers:               map[string]string{"Cookie": "session=cookievalue"},
+		},
+		getOpenAPIMock: getOpenAPIMock("cookievalue", "projectId", "express", "", `{"openapi":"3.0.3"}`, "application/json", nil),
+		wantResponse:   handlerResponse(`{"openapi":"3.0.3"}`, "application/json", 200),
+	},
+}
+
+func TestHandleGetOpenAPI(t *testing.T) {
+	for _, test := range handleGetOpenAPITests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			getOpenAPIFunc = test.getOpenAPIMock
+			defer func() {
+				getOpenAPIFunc = getOpenAPI
+			}()
+
+			// Execute
+			response, err := HandleGetOpenAPI(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -9,13 +9,13 @@ import (
 // getProjectDatabase wraps the database methods required to perform the getProject
 // action. This interface is used to perform dependency injection in unit tests.
 type getProjectDatabase interface {
-	auth.UserGetter
+	auth.CredentialStore
 	GetProject(string, string) (*dao.Project, error)
 }
 
-// verifyCookie points to the function used to check the validity of the cookie.
+// verifyCookie points to the function used to check the validity of the cookie or access token.
 // This variable should be changed only to perform dependency injection in unit tests.
-var verifyCookie = auth.VerifyCookie
+var verifyCookie = auth.Verifier(auth.ScopeReadOnly)
 
 // db is the object that implements the required database methods defined in getProjectDatabase.
 // This variable should be changed only to perform dependency injection in unit tests.
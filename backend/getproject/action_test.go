@@ -6,6 +6,7 @@ import (
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 type databaseMock struct {
@@ -15,10 +16,22 @@ type databaseMock struct {
 	err     error
 }
 
-func (mock *databaseMock) GetUserInfo(email string) (*dao.User, error) {
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
 	return nil, nil
 }
 
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
 func (mock *databaseMock) GetProject(email string, id string) (*dao.Project, error) {
 	if email != mock.email || id != mock.id {
 		return nil, errors.NewServer("Incorrect parameters passed to mock")
@@ -26,10 +39,8 @@ func (mock *databaseMock) GetProject(email string, id string) (*dao.Project, err
 	return mock.project, mock.err
 }
 
-type verifyCookieFunc func(string, auth.UserGetter) (string, error)
-
-func verifyCookieMock(wantCookie string, wantDB auth.UserGetter, email string, err error) verifyCookieFunc {
-	return func(gotCookie string, gotDB auth.UserGetter) (string, error) {
+func verifyCookieMock(wantCookie string, wantDB auth.CredentialStore, email string, err error) auth.VerifyCookieFunc {
+	return func(gotCookie string, gotDB auth.CredentialStore) (string, error) {
 		if gotCookie != wantCookie || !reflect.DeepEqual(gotDB, wantDB) {
 			return "", errors.NewServer("Incorrect parameters passed to mock")
 		}
@@ -92,7 +103,7 @@ func TestGetProject(t *testing.T) {
 			verifyCookie = verifyCookieMock(test.cookie, dbMock, test.email, test.cookieErr)
 			defer func() {
 				db = dao.Dynamo
-				verifyCookie = auth.VerifyCookie
+				verifyCookie = auth.Verifier(auth.ScopeReadOnly)
 			}()
 
 			// Execute
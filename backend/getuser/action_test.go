@@ -3,6 +3,7 @@ package getuser
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
@@ -19,12 +20,24 @@ func (mock *databaseMock) GetUser(email string) (*dao.User, error) {
 	return mock.user, mock.err
 }
 
-func (mock *databaseMock) GetUserInfo(email string) (*dao.User, error) {
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
 	return nil, nil
 }
 
-func verifyCookieMock(mockCookie string, mockDB auth.UserGetter, mockEmail string, mockErr error) verifyCookieFunc {
-	return func(cookie string, db auth.UserGetter) (string, error) {
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
 		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
 			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
 		}
@@ -25,16 +25,16 @@ func (response *getUserResponse) SetError(err string) {
 var getUserFunc = getUser
 
 // HandleGetUser parses the request object from AWS APIGateway and passes it to the getUser action.
-// The request must contain a valid `Cookie` header. If the request succeeds, the response will have
+// The request must contain a valid cookie or access token. If the request succeeds, the response will have
 // a 200 status, and the body will contain the user object. If the request fails, the response will
 // have either a 400 or 500 status, and the body will have an `error` field detailing what went wrong.
 // This function always returns a nil error.
 func HandleGetUser(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	// Get request parameters
-	cookie := auth.ExtractCookie(request.Headers["Cookie"])
+	cookie := auth.ExtractCredential(request.Headers)
 
 	// Get the user
-	user, err := getUserFunc(cookie, auth.VerifyCookie, dao.Dynamo)
+	user, err := getUserFunc(cookie, auth.Verifier(auth.ScopeReadOnly), dao.Dynamo)
 
 	// Return the response
 	response := &getUserResponse{User: user}
@@ -7,14 +7,15 @@ import (
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
 
-type getUserMocker func(string, verifyCookieFunc, getUserDatabase) (*dao.User, error)
+type getUserMocker func(string, auth.VerifyCookieFunc, getUserDatabase) (*dao.User, error)
 
 func getUserMock(wantCookie string, user *dao.User, err error) getUserMocker {
-	return func(cookie string, _ verifyCookieFunc, _ getUserDatabase) (*dao.User, error) {
+	return func(cookie string, _ auth.VerifyCookieFunc, _ getUserDatabase) (*dao.User, error) {
 		if cookie != wantCookie {
 			return nil, errors.NewServer("Incorrect input to get user mock.")
 		}
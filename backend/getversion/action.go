@@ -0,0 +1,40 @@
+package getversion
+
+import (
+	"strconv"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// getVersionDatabase wraps the database methods required to perform the getVersion action.
+// This allows for dependency injection of the database.
+type getVersionDatabase interface {
+	auth.CredentialStore
+	GetVersion(string, string, int) (*dao.ProjectVersion, error)
+}
+
+// getVersion returns the given version of the given projectID, including its project snapshot, for the user
+// associated with the given cookie. The version must be a positive integer. If an error occurs, getVersion
+// returns a nil version along with the error.
+func getVersion(cookie string, projectID string, version string, verifyCookie auth.VerifyCookieFunc, db getVersionDatabase) (*dao.ProjectVersion, error) {
+	if projectID == "" {
+		return nil, errors.NewClient("Parameter `projectID` is required")
+	}
+	number, err := strconv.Atoi(version)
+	if err != nil || number < 1 {
+		return nil, errors.NewClient("Parameter `version` must be a positive integer")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return nil, errors.NewClient("Not authenticated")
+	}
+
+	projectVersion, err := db.GetVersion(email, projectID, number)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get project version")
+	}
+	return projectVersion, nil
+}
@@ -0,0 +1,143 @@
+package getversion
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email     string
+	projectID string
+	version   int
+	output    *dao.ProjectVersion
+	err       error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetVersion(email string, projectID string, version int) (*dao.ProjectVersion, error) {
+	if email != mock.email || projectID != mock.projectID || version != mock.version {
+		return nil, errors.NewServer("Incorrect input to GetVersion mock")
+	}
+	return mock.output, mock.err
+}
+
+var projectVersion = &dao.ProjectVersion{
+	Version:   3,
+	Author:    "test@example.com",
+	Timestamp: time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC),
+	Project:   &dao.Project{ID: "projectID", Name: "Project", Version: 3},
+}
+
+var getVersionTests = []struct {
+	name string
+
+	// Input
+	cookie    string
+	projectID string
+	version   string
+
+	// Mock data
+	db        *databaseMock
+	email     string
+	verifyErr error
+
+	// Expected output
+	wantVersion *dao.ProjectVersion
+	wantErr     error
+}{
+	{
+		name:    "EmptyProjectID",
+		cookie:  "cookie",
+		version: "3",
+		wantErr: errors.NewClient("Parameter `projectID` is required"),
+	},
+	{
+		name:      "InvalidVersion",
+		cookie:    "cookie",
+		projectID: "projectID",
+		version:   "latest",
+		wantErr:   errors.NewClient("Parameter `version` must be a positive integer"),
+	},
+	{
+		name:      "ZeroVersion",
+		cookie:    "cookie",
+		projectID: "projectID",
+		version:   "0",
+		wantErr:   errors.NewClient("Parameter `version` must be a positive integer"),
+	},
+	{
+		name:      "InvalidCookie",
+		cookie:    "cookie",
+		projectID: "projectID",
+		version:   "3",
+		verifyErr: errors.NewClient("Invalid cookie"),
+		wantErr:   errors.NewClient("Not authenticated"),
+	},
+	{
+		name:      "DatabaseFailure",
+		cookie:    "cookie",
+		projectID: "projectID",
+		version:   "3",
+		db:        &databaseMock{email: "test@example.com", projectID: "projectID", version: 3, err: errors.NewClient("Version 3 of project 'projectID' not found")},
+		email:     "test@example.com",
+		wantErr:   errors.Wrap(errors.NewClient("Version 3 of project 'projectID' not found"), "Failed to get project version"),
+	},
+	{
+		name:        "SuccessfulInvocation",
+		cookie:      "cookie",
+		projectID:   "projectID",
+		version:     "3",
+		db:          &databaseMock{email: "test@example.com", projectID: "projectID", version: 3, output: projectVersion},
+		email:       "test@example.com",
+		wantVersion: projectVersion,
+	},
+}
+
+func TestGetVersion(t *testing.T) {
+	for _, test := range getVersionTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock(test.cookie, test.db, test.email, test.verifyErr)
+
+			// Execute
+			version, err := getVersion(test.cookie, test.projectID, test.version, verifyCookie, test.db)
+
+			// Verify
+			if !reflect.DeepEqual(version, test.wantVersion) {
+				t.Errorf("Got version %v; want %v", version, test.wantVersion)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
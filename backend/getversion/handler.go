@@ -0,0 +1,45 @@
+// Package getversion handles requests to the GET /projects/{pid}/versions/{version} REST API endpoint.
+package getversion
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// getVersionResponse contains the fields returned in the API JSON response body.
+type getVersionResponse struct {
+	Version *dao.ProjectVersion `json:"version,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+func (response *getVersionResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// getVersionFunc points to the function used to perform the getVersion action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var getVersionFunc = getVersion
+
+// HandleGetVersion parses the request object from AWS APIGateway and passes it to the getVersion action. The
+// request must contain a valid cookie or access token and the `pid` and `version` path parameters. If the request
+// succeeds, the response will have a 200 status, and the body will have a `version` field containing the
+// version and its project snapshot. If the request fails, the response will have either a 400 or a 500 status,
+// and the body will have an `error` field detailing what went wrong.
+func HandleGetVersion(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	projectID := request.PathParameters["pid"]
+	version := request.PathParameters["version"]
+	cookie := auth.ExtractCredential(request.Headers)
+
+	// Perform the action
+	projectVersion, err := getVersionFunc(cookie, projectID, version, auth.Verifier(auth.ScopeReadOnly), dao.Dynamo)
+	log.Error(err)
+
+	// Return the response
+	return http.GatewayResponse(&getVersionResponse{Version: projectVersion}, "", err), nil
+}
@@ -0,0 +1,90 @@
+package getversion
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type getVersionMockFunc func(string, string, string, auth.VerifyCookieFunc, getVersionDatabase) (*dao.ProjectVersion, error)
+
+func getVersionMock(wantCookie string, wantProjectID string, wantVersion string, output *dao.ProjectVersion, err error) getVersionMockFunc {
+	return func(cookie string, projectID string, version string, _ auth.VerifyCookieFunc, _ getVersionDatabase) (*dao.ProjectVersion, error) {
+		if cookie != wantCookie || projectID != wantProjectID || version != wantVersion {
+			return nil, errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return output, err
+	}
+}
+
+func handlerRequest(cookie string, projectID string, version string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		Headers:        map[string]string{"Cookie": cookie},
+		PathParameters: map[string]string{"pid": projectID, "version": version},
+	}
+}
+
+func handlerResponse(version *dao.ProjectVersion, err string, status int) events.APIGatewayProxyResponse {
+	json, _ := json.Marshal(&getVersionResponse{Version: version, Error: err})
+	return events.APIGatewayProxyResponse{
+		Body:       string(json),
+		Headers:    map[string]string{"Access-Control-Allow-Origin": os.Getenv("CORS_ORIGIN"), "Access-Control-Allow-Credentials": "true"},
+		StatusCode: status,
+	}
+}
+
+var handleGetVersionTests = []struct {
+	name string
+
+	// Input
+	request events.APIGatewayProxyRequest
+
+	// Mock data
+	getVersionMock getVersionMockFunc
+
+	// Expected output
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:           "GetVersionFailure",
+		request:        handlerRequest("session=cookievalue", "projectID", "3"),
+		getVersionMock: getVersionMock("cookievalue", "projectID", "3", nil, errors.NewClient("Version not found")),
+		wantResponse:   handlerResponse(nil, "Version not found", 400),
+	},
+	{
+		name:           "SuccessfulInvocation",
+		request:        handlerRequest("session=cookievalue", "projectID", "3"),
+		getVersionMock: getVersionMock("cookievalue", "projectID", "3", projectVersion, nil),
+		wantResponse:   handlerResponse(projectVersion, "", 200),
+	},
+}
+
+func TestHandleGetVersion(t *testing.T) {
+	for _, test := range handleGetVersionTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			getVersionFunc = test.getVersionMock
+			defer func() {
+				getVersionFunc = getVersion
+			}()
+
+			// Execute
+			response, err := HandleGetVersion(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -1,13 +1,14 @@
 module github.com/jackstenglein/rest_api_creator/backend
 
 require (
-	github.com/aws/aws-lambda-go v1.6.0
+	github.com/aws/aws-lambda-go v1.8.1
 	github.com/aws/aws-sdk-go v1.30.7
 	github.com/golang/mock v1.4.3
 	github.com/google/uuid v1.1.1
 	github.com/pkg/errors v0.9.1
 	golang.org/x/crypto v0.0.0-20191011191535-87dc89f01550
 	golang.org/x/tools v0.0.0-20200415034506-5d8e1897c761 // indirect
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 go 1.14
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
@@ -14,10 +15,27 @@ type apiResponse interface {
 	SetError(string)
 }
 
+// SessionCookie returns the value of the Set-Cookie header that stores the given session cookie in the browser.
+func SessionCookie(cookie string) string {
+	return fmt.Sprintf("session=%s;HttpOnly;", cookie)
+}
+
+// Header returns the value of the header with the given name in the given request headers. Header names are
+// compared case-insensitively, as clients and API Gateway do not agree on their case. If the header is not
+// present, the empty string is returned.
+func Header(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
 func headers(cookie string) map[string]string {
 	if len(cookie) > 0 {
 		return map[string]string{
-			"Set-Cookie":                       fmt.Sprintf("session=%s;HttpOnly;", cookie),
+			"Set-Cookie":                       SessionCookie(cookie),
 			"Access-Control-Allow-Origin":      os.Getenv("CORS_ORIGIN"),
 			"Access-Control-Allow-Credentials": "true",
 		}
@@ -48,3 +66,38 @@ func GatewayResponse(response apiResponse, cookie string, err error) events.APIG
 		StatusCode: status,
 	}
 }
+
+// DocumentResponse returns an APIGatewayResponse with a 200 status whose body is the given document. The
+// Content-Type header is set to the given content type, and CORS headers are added to the response.
+func DocumentResponse(document []byte, contentType string) events.APIGatewayProxyResponse {
+	responseHeaders := headers("")
+	responseHeaders["Content-Type"] = contentType
+	return events.APIGatewayProxyResponse{
+		Body:       string(document),
+		Headers:    responseHeaders,
+		StatusCode: 200,
+	}
+}
+
+// RedirectResponse returns an APIGatewayResponse with a 302 status that sends the browser to the given location.
+// Each of the given setCookies that is not the empty string is sent as its own Set-Cookie header, so that a single
+// response can set or expire several cookies. CORS headers are added to the response.
+func RedirectResponse(location string, setCookies ...string) events.APIGatewayProxyResponse {
+	responseHeaders := headers("")
+	responseHeaders["Location"] = location
+	var cookies []string
+	for _, setCookie := range setCookies {
+		if setCookie != "" {
+			cookies = append(cookies, setCookie)
+		}
+	}
+
+	response := events.APIGatewayProxyResponse{
+		Headers:    responseHeaders,
+		StatusCode: 302,
+	}
+	if len(cookies) > 0 {
+		response.MultiValueHeaders = map[string][]string{"Set-Cookie": cookies}
+	}
+	return response
+}
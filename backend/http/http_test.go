@@ -102,3 +102,86 @@ func TestGatewayResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestDocumentResponse(t *testing.T) {
+	want := events.APIGatewayProxyResponse{
+		Body: "openapi: 3.0.3\n",
+		Headers: map[string]string{
+			"Content-Type":                     "application/yaml",
+			"Access-Control-Allow-Origin":      os.Getenv("CORS_ORIGIN"),
+			"Access-Control-Allow-Credentials": "true",
+		},
+		StatusCode: 200,
+	}
+
+	response := DocumentResponse([]byte("openapi: 3.0.3\n"), "application/yaml")
+	if !reflect.DeepEqual(response, want) {
+		t.Errorf("Got response %v; want %v", response, want)
+	}
+}
+
+func TestRedirectResponse(t *testing.T) {
+	want := events.APIGatewayProxyResponse{
+		Headers: map[string]string{
+			"Location":                         "https://app.example.com/",
+			"Access-Control-Allow-Origin":      os.Getenv("CORS_ORIGIN"),
+			"Access-Control-Allow-Credentials": "true",
+		},
+		MultiValueHeaders: map[string][]string{
+			"Set-Cookie": {"session=cookievalue;HttpOnly;"},
+		},
+		StatusCode: 302,
+	}
+
+	response := RedirectResponse("https://app.example.com/", SessionCookie("cookievalue"))
+	if !reflect.DeepEqual(response, want) {
+		t.Errorf("Got response %v; want %v", response, want)
+	}
+
+	want.MultiValueHeaders["Set-Cookie"] = []string{"session=cookievalue;HttpOnly;", "state=;Max-Age=0"}
+	response = RedirectResponse("https://app.example.com/", SessionCookie("cookievalue"), "", "state=;Max-Age=0")
+	if !reflect.DeepEqual(response, want) {
+		t.Errorf("Got response %v; want %v", response, want)
+	}
+
+	want.MultiValueHeaders = nil
+	response = RedirectResponse("https://app.example.com/", "")
+	if !reflect.DeepEqual(response, want) {
+		t.Errorf("Got response %v; want %v", response, want)
+	}
+}
+
+var headerTests = []struct {
+	name      string
+	headers   map[string]string
+	wantValue string
+}{
+	{
+		name:    "MissingHeader",
+		headers: map[string]string{"Cookie": "session=value"},
+	},
+	{
+		name:      "CanonicalCase",
+		headers:   map[string]string{"Accept": "application/yaml"},
+		wantValue: "application/yaml",
+	},
+	{
+		name:      "MixedCase",
+		headers:   map[string]string{"ACCEPT": "application/yaml"},
+		wantValue: "application/yaml",
+	},
+}
+
+func TestHeader(t *testing.T) {
+	for _, test := range headerTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			value := Header(test.headers, "accept")
+
+			// Verify
+			if value != test.wantValue {
+				t.Errorf("Got value %q; want %q", value, test.wantValue)
+			}
+		})
+	}
+}
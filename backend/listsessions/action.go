@@ -0,0 +1,34 @@
+package listsessions
+
+import (
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// listSessionsDatabase wraps the database methods required to perform the listSessions action.
+// This allows for dependency injection of the database.
+type listSessionsDatabase interface {
+	auth.CredentialStore
+	ListSessions(string) ([]*dao.Session, error)
+}
+
+// listSessions returns the unexpired sessions of the user associated with the given cookie. The session of the
+// cookie itself is marked as current. If an error occurs, listSessions returns nil sessions along with the error.
+func listSessions(cookie string, verifyCookie auth.VerifyCookieFunc, db listSessionsDatabase) ([]*dao.Session, error) {
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return nil, errors.NewClient("Not authenticated")
+	}
+
+	sessions, err := db.ListSessions(email)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to list sessions")
+	}
+
+	current := auth.CookieSessionID(cookie)
+	for _, session := range sessions {
+		session.Current = session.ID == current
+	}
+	return sessions, nil
+}
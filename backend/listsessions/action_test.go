@@ -0,0 +1,111 @@
+package listsessions
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email    string
+	sessions []*dao.Session
+	err      error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) ListSessions(email string) ([]*dao.Session, error) {
+	if email != mock.email {
+		return nil, errors.NewServer("Incorrect input to ListSessions mock")
+	}
+	return mock.sessions, mock.err
+}
+
+const cookie = "test@example.com#token#k1#mac"
+
+// testSessions returns a session of the cookie and a session of another device, with the session of the cookie
+// marked as current if current is true.
+func testSessions(current bool) []*dao.Session {
+	return []*dao.Session{
+		{ID: auth.SessionID("other"), Email: "test@example.com", UserAgent: "curl/7.68.0"},
+		{ID: auth.SessionID("token"), Email: "test@example.com", UserAgent: "Mozilla/5.0", Current: current},
+	}
+}
+
+var listSessionsTests = []struct {
+	name string
+
+	// Mock data
+	db        *databaseMock
+	email     string
+	verifyErr error
+
+	// Expected output
+	wantSessions []*dao.Session
+	wantErr      error
+}{
+	{
+		name:      "InvalidCookie",
+		verifyErr: errors.NewClient("Invalid cookie"),
+		wantErr:   errors.NewClient("Not authenticated"),
+	},
+	{
+		name:    "DatabaseFailure",
+		db:      &databaseMock{email: "test@example.com", err: errors.NewServer("DynamoDB failure")},
+		email:   "test@example.com",
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to list sessions"),
+	},
+	{
+		name:         "SuccessfulInvocation",
+		db:           &databaseMock{email: "test@example.com", sessions: testSessions(false)},
+		email:        "test@example.com",
+		wantSessions: testSessions(true),
+	},
+}
+
+func TestListSessions(t *testing.T) {
+	for _, test := range listSessionsTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock(cookie, test.db, test.email, test.verifyErr)
+
+			// Execute
+			sessions, err := listSessions(cookie, verifyCookie, test.db)
+
+			// Verify
+			if !reflect.DeepEqual(sessions, test.wantSessions) {
+				t.Errorf("Got sessions %v; want %v", sessions, test.wantSessions)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Package listsessions handles requests to the GET /sessions REST API endpoint.
+package listsessions
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// listSessionsResponse contains the fields returned in the API JSON response body.
+type listSessionsResponse struct {
+	Sessions []*dao.Session `json:"sessions,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+func (response *listSessionsResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// listSessionsFunc points to the function used to perform the listSessions action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var listSessionsFunc = listSessions
+
+// HandleListSessions parses the request object from AWS APIGateway and passes it to the listSessions action.
+// The request must contain a valid `Cookie` header. If the request succeeds, the response will have a 200
+// status, and the body will have a `sessions` field listing the unexpired sessions of the user, with the
+// session making the request marked as `current`. If the request fails, the response will have either a 400
+// or a 500 status, and the body will have an `error` field detailing what went wrong.
+func HandleListSessions(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	cookie := auth.ExtractCookie(request.Headers["Cookie"])
+
+	// Perform the action
+	sessions, err := listSessionsFunc(cookie, auth.VerifySession, dao.Dynamo)
+	log.Error(err)
+
+	// Return the response
+	return http.GatewayResponse(&listSessionsResponse{Sessions: sessions}, "", err), nil
+}
@@ -0,0 +1,89 @@
+package listsessions
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type listSessionsMockFunc func(string, auth.VerifyCookieFunc, listSessionsDatabase) ([]*dao.Session, error)
+
+func listSessionsMock(wantCookie string, sessions []*dao.Session, err error) listSessionsMockFunc {
+	return func(cookie string, _ auth.VerifyCookieFunc, _ listSessionsDatabase) ([]*dao.Session, error) {
+		if cookie != wantCookie {
+			return nil, errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return sessions, err
+	}
+}
+
+func handlerRequest(cookie string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		Headers: map[string]string{"Cookie": cookie},
+	}
+}
+
+func handlerResponse(sessions []*dao.Session, err string, status int) events.APIGatewayProxyResponse {
+	json, _ := json.Marshal(&listSessionsResponse{Sessions: sessions, Error: err})
+	return events.APIGatewayProxyResponse{
+		Body:       string(json),
+		Headers:    map[string]string{"Access-Control-Allow-Origin": os.Getenv("CORS_ORIGIN"), "Access-Control-Allow-Credentials": "true"},
+		StatusCode: status,
+	}
+}
+
+var handleListSessionsTests = []struct {
+	name string
+
+	// Input
+	request events.APIGatewayProxyRequest
+
+	// Mock data
+	listSessionsMock listSessionsMockFunc
+
+	// Expected output
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:             "ListSessionsFailure",
+		request:          handlerRequest("session=cookievalue"),
+		listSessionsMock: listSessionsMock("cookievalue", nil, errors.NewServer("Failed database call")),
+		wantResponse:     handlerResponse(nil, "Failed database call", 500),
+	},
+	{
+		name:             "SuccessfulInvocation",
+		request:          handlerRequest("session=cookievalue"),
+		listSessionsMock: listSessionsMock("cookievalue", testSessions(true), nil),
+		wantResponse:     handlerResponse(testSessions(true), "", 200),
+	},
+}
+
+func TestHandleListSessions(t *testing.T) {
+	for _, test := range handleListSessionsTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			listSessionsFunc = test.listSessionsMock
+			defer func() {
+				listSessionsFunc = listSessions
+			}()
+
+			// Execute
+			response, err := HandleListSessions(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package listtokens
+
+import (
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// listTokensDatabase wraps the database methods required to perform the listTokens action.
+// This allows for dependency injection of the database.
+type listTokensDatabase interface {
+	auth.CredentialStore
+	ListAccessTokens(string) ([]*dao.AccessToken, error)
+}
+
+// listTokens returns the unexpired access tokens of the user associated with the given cookie. Only the records
+// of the tokens are returned, never the tokens themselves. If an error occurs, listTokens returns nil tokens
+// along with the error.
+func listTokens(cookie string, verifyCookie auth.VerifyCookieFunc, db listTokensDatabase) ([]*dao.AccessToken, error) {
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return nil, errors.NewClient("Not authenticated")
+	}
+
+	tokens, err := db.ListAccessTokens(email)
+	return tokens, errors.Wrap(err, "Failed to list access tokens")
+}
@@ -0,0 +1,105 @@
+package listtokens
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email  string
+	tokens []*dao.AccessToken
+	err    error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) ListAccessTokens(email string) ([]*dao.AccessToken, error) {
+	if email != mock.email {
+		return nil, errors.NewServer("Incorrect input to ListAccessTokens mock")
+	}
+	return mock.tokens, mock.err
+}
+
+var testTokens = []*dao.AccessToken{
+	{ID: "token1", Email: "test@example.com", Name: "CI", Scopes: []string{"codegen"}},
+	{ID: "token2", Email: "test@example.com", Name: "Laptop"},
+}
+
+var listTokensTests = []struct {
+	name string
+
+	// Mock data
+	db        *databaseMock
+	email     string
+	verifyErr error
+
+	// Expected output
+	wantTokens []*dao.AccessToken
+	wantErr    error
+}{
+	{
+		name:      "InvalidCookie",
+		verifyErr: errors.NewClient("Invalid cookie"),
+		wantErr:   errors.NewClient("Not authenticated"),
+	},
+	{
+		name:    "DatabaseFailure",
+		db:      &databaseMock{email: "test@example.com", err: errors.NewServer("DynamoDB failure")},
+		email:   "test@example.com",
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to list access tokens"),
+	},
+	{
+		name:       "SuccessfulInvocation",
+		db:         &databaseMock{email: "test@example.com", tokens: testTokens},
+		email:      "test@example.com",
+		wantTokens: testTokens,
+	},
+}
+
+func TestListTokens(t *testing.T) {
+	for _, test := range listTokensTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock("cookie", test.db, test.email, test.verifyErr)
+
+			// Execute
+			tokens, err := listTokens("cookie", verifyCookie, test.db)
+
+			// Verify
+			if !reflect.DeepEqual(tokens, test.wantTokens) {
+				t.Errorf("Got tokens %v; want %v", tokens, test.wantTokens)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Package listtokens handles requests to the GET /tokens REST API endpoint.
+package listtokens
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// listTokensResponse contains the fields returned in the API JSON response body.
+type listTokensResponse struct {
+	Tokens []*dao.AccessToken `json:"tokens,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+func (response *listTokensResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// listTokensFunc points to the function used to perform the listTokens action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var listTokensFunc = listTokens
+
+// HandleListTokens parses the request object from AWS APIGateway and passes it to the listTokens action. The
+// request must contain a valid `Cookie` header. If the request succeeds, the response will have a 200 status,
+// and the body will have a `tokens` field listing the unexpired access tokens of the user. If the request
+// fails, the response will have either a 400 or a 500 status, and the body will have an `error` field
+// detailing what went wrong.
+func HandleListTokens(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	cookie := auth.ExtractCookie(request.Headers["Cookie"])
+
+	// Perform the action
+	tokens, err := listTokensFunc(cookie, auth.VerifySession, dao.Dynamo)
+	log.Error(err)
+
+	// Return the response
+	return http.GatewayResponse(&listTokensResponse{Tokens: tokens}, "", err), nil
+}
@@ -0,0 +1,89 @@
+package listtokens
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type listTokensMockFunc func(string, auth.VerifyCookieFunc, listTokensDatabase) ([]*dao.AccessToken, error)
+
+func listTokensMock(wantCookie string, tokens []*dao.AccessToken, err error) listTokensMockFunc {
+	return func(cookie string, _ auth.VerifyCookieFunc, _ listTokensDatabase) ([]*dao.AccessToken, error) {
+		if cookie != wantCookie {
+			return nil, errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return tokens, err
+	}
+}
+
+func handlerRequest(cookie string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		Headers: map[string]string{"Cookie": cookie},
+	}
+}
+
+func handlerResponse(tokens []*dao.AccessToken, err string, status int) events.APIGatewayProxyResponse {
+	json, _ := json.Marshal(&listTokensResponse{Tokens: tokens, Error: err})
+	return events.APIGatewayProxyResponse{
+		Body:       string(json),
+		Headers:    map[string]string{"Access-Control-Allow-Origin": os.Getenv("CORS_ORIGIN"), "Access-Control-Allow-Credentials": "true"},
+		StatusCode: status,
+	}
+}
+
+var handleListTokensTests = []struct {
+	name string
+
+	// Input
+	request events.APIGatewayProxyRequest
+
+	// Mock data
+	listTokensMock listTokensMockFunc
+
+	// Expected output
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:           "ListTokensFailure",
+		request:        handlerRequest("session=cookievalue"),
+		listTokensMock: listTokensMock("cookievalue", nil, errors.NewServer("Failed database call")),
+		wantResponse:   handlerResponse(nil, "Failed database call", 500),
+	},
+	{
+		name:           "SuccessfulInvocation",
+		request:        handlerRequest("session=cookievalue"),
+		listTokensMock: listTokensMock("cookievalue", testTokens, nil),
+		wantResponse:   handlerResponse(testTokens, "", 200),
+	},
+}
+
+func TestHandleListTokens(t *testing.T) {
+	for _, test := range handleListTokensTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			listTokensFunc = test.listTokensMock
+			defer func() {
+				listTokensFunc = listTokens
+			}()
+
+			// Execute
+			response, err := HandleListTokens(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package listversions
+
+import (
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// listVersionsDatabase wraps the database methods required to perform the listVersions action.
+// This allows for dependency injection of the database.
+type listVersionsDatabase interface {
+	auth.CredentialStore
+	ListVersions(string, string) ([]*dao.ProjectVersion, error)
+}
+
+// listVersions returns the versions of the given projectID, newest first, for the user associated with the
+// given cookie. The versions contain their number, author and timestamp, but not the project snapshots. If an
+// error occurs, listVersions returns nil versions along with the error.
+func listVersions(cookie string, projectID string, verifyCookie auth.VerifyCookieFunc, db listVersionsDatabase) ([]*dao.ProjectVersion, error) {
+	if projectID == "" {
+		return nil, errors.NewClient("Parameter `projectID` is required")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return nil, errors.NewClient("Not authenticated")
+	}
+
+	versions, err := db.ListVersions(email, projectID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to list project versions")
+	}
+	return versions, nil
+}
@@ -0,0 +1,121 @@
+package listversions
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email     string
+	projectID string
+	versions  []*dao.ProjectVersion
+	err       error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) ListVersions(email string, projectID string) ([]*dao.ProjectVersion, error) {
+	if email != mock.email || projectID != mock.projectID {
+		return nil, errors.NewServer("Incorrect input to ListVersions mock")
+	}
+	return mock.versions, mock.err
+}
+
+var versions = []*dao.ProjectVersion{
+	{Version: 2, Author: "test@example.com", Timestamp: time.Date(2020, time.May, 2, 0, 0, 0, 0, time.UTC)},
+	{Version: 1, Author: "test@example.com", Timestamp: time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)},
+}
+
+var listVersionsTests = []struct {
+	name string
+
+	// Input
+	cookie    string
+	projectID string
+
+	// Mock data
+	db        *databaseMock
+	email     string
+	verifyErr error
+
+	// Expected output
+	wantVersions []*dao.ProjectVersion
+	wantErr      error
+}{
+	{
+		name:    "EmptyProjectID",
+		cookie:  "cookie",
+		wantErr: errors.NewClient("Parameter `projectID` is required"),
+	},
+	{
+		name:      "InvalidCookie",
+		cookie:    "cookie",
+		projectID: "projectID",
+		verifyErr: errors.NewClient("Invalid cookie"),
+		wantErr:   errors.NewClient("Not authenticated"),
+	},
+	{
+		name:      "DatabaseFailure",
+		cookie:    "cookie",
+		projectID: "projectID",
+		db:        &databaseMock{email: "test@example.com", projectID: "projectID", err: errors.NewServer("DynamoDB failure")},
+		email:     "test@example.com",
+		wantErr:   errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to list project versions"),
+	},
+	{
+		name:         "SuccessfulInvocation",
+		cookie:       "cookie",
+		projectID:    "projectID",
+		db:           &databaseMock{email: "test@example.com", projectID: "projectID", versions: versions},
+		email:        "test@example.com",
+		wantVersions: versions,
+	},
+}
+
+func TestListVersions(t *testing.T) {
+	for _, test := range listVersionsTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock(test.cookie, test.db, test.email, test.verifyErr)
+
+			// Execute
+			versions, err := listVersions(test.cookie, test.projectID, verifyCookie, test.db)
+
+			// Verify
+			if !reflect.DeepEqual(versions, test.wantVersions) {
+				t.Errorf("Got versions %v; want %v", versions, test.wantVersions)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Package listversions handles requests to the GET /projects/{pid}/versions REST API endpoint.
+package listversions
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// listVersionsResponse contains the fields returned in the API JSON response body.
+type listVersionsResponse struct {
+	Versions []*dao.ProjectVersion `json:"versions,omitempty"`
+	Error    string                `json:"error,omitempty"`
+}
+
+func (response *listVersionsResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// listVersionsFunc points to the function used to perform the listVersions action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var listVersionsFunc = listVersions
+
+// HandleListVersions parses the request object from AWS APIGateway and passes it to the listVersions action.
+// The request must contain a valid cookie or access token and a `pid` path parameter. If the request succeeds, the
+// response will have a 200 status, and the body will have a `versions` field listing the versions of the
+// project, newest first. If the request fails, the response will have either a 400 or a 500 status, and the
+// body will have an `error` field detailing what went wrong.
+func HandleListVersions(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	projectID := request.PathParameters["pid"]
+	cookie := auth.ExtractCredential(request.Headers)
+
+	// Perform the action
+	versions, err := listVersionsFunc(cookie, projectID, auth.Verifier(auth.ScopeReadOnly), dao.Dynamo)
+	log.Error(err)
+
+	// Return the response
+	return http.GatewayResponse(&listVersionsResponse{Versions: versions}, "", err), nil
+}
@@ -0,0 +1,90 @@
+package listversions
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type listVersionsMockFunc func(string, string, auth.VerifyCookieFunc, listVersionsDatabase) ([]*dao.ProjectVersion, error)
+
+func listVersionsMock(wantCookie string, wantProjectID string, versions []*dao.ProjectVersion, err error) listVersionsMockFunc {
+	return func(cookie string, projectID string, _ auth.VerifyCookieFunc, _ listVersionsDatabase) ([]*dao.ProjectVersion, error) {
+		if cookie != wantCookie || projectID != wantProjectID {
+			return nil, errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return versions, err
+	}
+}
+
+func handlerRequest(cookie string, projectID string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		Headers:        map[string]string{"Cookie": cookie},
+		PathParameters: map[string]string{"pid": projectID},
+	}
+}
+
+func handlerResponse(versions []*dao.ProjectVersion, err string, status int) events.APIGatewayProxyResponse {
+	json, _ := json.Marshal(&listVersionsResponse{Versions: versions, Error: err})
+	return events.APIGatewayProxyResponse{
+		Body:       string(json),
+		Headers:    map[string]string{"Access-Control-Allow-Origin": os.Getenv("CORS_ORIGIN"), "Access-Control-Allow-Credentials": "true"},
+		StatusCode: status,
+	}
+}
+
+var handleListVersionsTests = []struct {
+	name string
+
+	// Input
+	request events.APIGatewayProxyRequest
+
+	// Mock data
+	listVersionsMock listVersionsMockFunc
+
+	// Expected output
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:             "ListVersionsFailure",
+		request:          handlerRequest("session=cookievalue", "projectID"),
+		listVersionsMock: listVersionsMock("cookievalue", "projectID", nil, errors.NewServer("Failed database call")),
+		wantResponse:     handlerResponse(nil, "Failed database call", 500),
+	},
+	{
+		name:             "SuccessfulInvocation",
+		request:          handlerRequest("session=cookievalue", "projectID"),
+		listVersionsMock: listVersionsMock("cookievalue", "projectID", versions, nil),
+		wantResponse:     handlerResponse(versions, "", 200),
+	},
+}
+
+func TestHandleListVersions(t *testing.T) {
+	for _, test := range handleListVersionsTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			listVersionsFunc = test.listVersionsMock
+			defer func() {
+				listVersionsFunc = listVersions
+			}()
+
+			// Execute
+			response, err := HandleListVersions(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
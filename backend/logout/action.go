@@ -8,16 +8,16 @@ import (
 // logoutDatabase wraps the database methods required to perform the logout action.
 // This allows for dependency injection of the database.
 type logoutDatabase interface {
-	auth.UserGetter
-	UpdateUserToken(string, string) error
+	auth.CredentialStore
+	DeleteSession(string, string) error
 }
 
 // verifyCookieFunc wraps the function type used to check the validity of the user's cookie.
 // This allows for dependency injection of the function.
-type verifyCookieFunc func(string, auth.UserGetter) (string, error)
+type verifyCookieFunc func(string, auth.CredentialStore) (string, error)
 
-// logout removes the auth token from the user associated with the given cookie in the
-// given database. It returns the error generated, if the cookie was invalid or the
+// logout deletes the session of the given cookie from the given database. Other sessions of the
+// user are not affected. It returns the error generated, if the cookie was invalid or the
 // database update failed. In this case, the user should still be considered logged in.
 func logout(cookie string, verifyCookie verifyCookieFunc, db logoutDatabase) error {
 	if cookie == "" {
@@ -29,6 +29,6 @@ func logout(cookie string, verifyCookie verifyCookieFunc, db logoutDatabase) err
 		return errors.NewClient("Not authenticated")
 	}
 
-	err = db.UpdateUserToken(email, "")
-	return errors.Wrap(err, "Failed to remove auth token")
+	err = db.DeleteSession(email, auth.CookieSessionID(cookie))
+	return errors.Wrap(err, "Failed to delete session")
 }
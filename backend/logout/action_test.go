@@ -3,6 +3,7 @@ package logout
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
@@ -10,24 +11,36 @@ import (
 )
 
 type databaseMock struct {
-	email string
-	token string
-	err   error
+	email     string
+	sessionID string
+	err       error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
 }
 
-func (mock *databaseMock) GetUserInfo(email string) (*dao.User, error) {
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
 	return nil, nil
 }
 
-func (mock *databaseMock) UpdateUserToken(email string, token string) error {
-	if email != mock.email || token != mock.token {
-		return errors.NewServer("Incorrect input to UpdateUserToken mock")
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) DeleteSession(email string, sessionID string) error {
+	if email != mock.email || sessionID != mock.sessionID {
+		return errors.NewServer("Incorrect input to DeleteSession mock")
 	}
 	return mock.err
 }
 
-func verifyCookieMock(mockCookie string, mockDB auth.UserGetter, mockEmail string, mockErr error) verifyCookieFunc {
-	return func(cookie string, db auth.UserGetter) (string, error) {
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) verifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
 		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
 			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
 		}
@@ -60,18 +73,18 @@ var logoutTests = []struct {
 		wantErr:   errors.NewClient("Not authenticated"),
 	},
 	{
-		name:      "UpdateTokenError",
-		cookie:    "validcookie",
+		name:      "DeleteSessionError",
+		cookie:    "test@example.com#token#k1#mac",
 		email:     "test@example.com",
-		db:        &databaseMock{"test@example.com", "", errors.NewServer("DynamoDB failure")},
+		db:        &databaseMock{"test@example.com", auth.SessionID("token"), errors.NewServer("DynamoDB failure")},
 		verifyErr: nil,
-		wantErr:   errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to remove auth token"),
+		wantErr:   errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to delete session"),
 	},
 	{
 		name:      "SuccessfulInvocation",
-		cookie:    "validcookie",
+		cookie:    "test@example.com#token#k1#mac",
 		email:     "test@example.com",
-		db:        &databaseMock{"test@example.com", "", nil},
+		db:        &databaseMock{"test@example.com", auth.SessionID("token"), nil},
 		verifyErr: nil,
 		wantErr:   nil,
 	},
@@ -30,7 +30,7 @@ func HandleLogout(request events.APIGatewayProxyRequest) (events.APIGatewayProxy
 	cookie := auth.ExtractCookie(request.Headers["Cookie"])
 
 	// Perform the action
-	err := logoutFunc(cookie, auth.VerifyCookie, dao.Dynamo)
+	err := logoutFunc(cookie, auth.VerifySession, dao.Dynamo)
 
 	// Handle the output
 	errString, status := errors.UserDetails(err)
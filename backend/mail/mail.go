@@ -0,0 +1,105 @@
+// Package mail sends email to users, such as the links used to verify their email address and reset their
+// password.
+package mail
+
+import (
+	"fmt"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// Message is a plain text email sent to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer wraps the method used to send email, so that SMTPMailer can be replaced by FakeMailer in tests.
+type Mailer interface {
+	Send(*Message) error
+}
+
+// sendMail points to the function used to deliver email over SMTP. It should only be changed inside a test.
+var sendMail = smtp.SendMail
+
+// SMTPMailer sends email through an SMTP server. If Username is set, it authenticates with the PLAIN mechanism,
+// which net/smtp only allows over TLS or to localhost.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send delivers the given message through the SMTP server. The recipient and subject cannot contain newlines,
+// so that they cannot add headers to the message.
+func (mailer SMTPMailer) Send(message *Message) error {
+	if mailer.Host == "" || mailer.From == "" {
+		return errors.NewServer("SMTP mailer is not configured")
+	}
+	if strings.ContainsAny(message.To, "\r\n") || strings.ContainsAny(message.Subject, "\r\n") {
+		return errors.NewClient("Invalid email recipient or subject")
+	}
+
+	var auth smtp.Auth
+	if mailer.Username != "" {
+		auth = smtp.PlainAuth("", mailer.Username, mailer.Password, mailer.Host)
+	}
+
+	body := strings.Replace(message.Body, "\r\n", "\n", -1)
+	body = strings.Replace(body, "\n", "\r\n", -1)
+	contents := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		mailer.From, message.To, message.Subject, body)
+
+	err := sendMail(mailer.Host+":"+mailer.Port, auth, mailer.From, []string{message.To}, []byte(contents))
+	return errors.Wrap(err, "Failed to send email")
+}
+
+// DefaultMailer returns an SMTPMailer configured by the `SMTP_HOST`, `SMTP_PORT` (default 587), `SMTP_USERNAME`,
+// `SMTP_PASSWORD` and `MAIL_FROM` environment variables.
+func DefaultMailer() Mailer {
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	return SMTPMailer{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("MAIL_FROM"),
+	}
+}
+
+// FakeMailer records the messages it is given instead of sending them, so that tests can read the email that
+// would have been sent. If Err is set, Send returns it without recording the message.
+type FakeMailer struct {
+	Err      error
+	mutex    sync.Mutex
+	messages []*Message
+}
+
+// Send records a copy of the given message.
+func (fake *FakeMailer) Send(message *Message) error {
+	if fake.Err != nil {
+		return fake.Err
+	}
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	recorded := *message
+	fake.messages = append(fake.messages, &recorded)
+	return nil
+}
+
+// Messages returns the messages recorded by Send, in the order they were sent.
+func (fake *FakeMailer) Messages() []*Message {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	return append([]*Message(nil), fake.messages...)
+}
@@ -0,0 +1,130 @@
+package mail
+
+import (
+	"net/smtp"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// sendMailMock records the arguments of the last call to sendMail and returns the given error.
+type sendMailMock struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+	msg  string
+	err  error
+}
+
+func (mock *sendMailMock) send(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	mock.addr, mock.auth, mock.from, mock.to, mock.msg = addr, auth, from, to, string(msg)
+	return mock.err
+}
+
+var testMailer = SMTPMailer{Host: "smtp.example.com", Port: "587", Username: "user", Password: "pass", From: "no-reply@example.com"}
+
+var smtpMailerTests = []struct {
+	name    string
+	mailer  SMTPMailer
+	message *Message
+	mockErr error
+
+	// Expected output
+	wantMsg string
+	wantErr error
+}{
+	{
+		name:    "NotConfigured",
+		mailer:  SMTPMailer{Port: "587"},
+		message: &Message{To: "test@example.com", Subject: "Hello", Body: "Hi"},
+		wantErr: errors.NewServer("SMTP mailer is not configured"),
+	},
+	{
+		name:    "HeaderInjection",
+		mailer:  testMailer,
+		message: &Message{To: "test@example.com\r\nBcc: other@example.com", Subject: "Hello", Body: "Hi"},
+		wantErr: errors.NewClient("Invalid email recipient or subject"),
+	},
+	{
+		name:    "SendFailure",
+		mailer:  testMailer,
+		message: &Message{To: "test@example.com", Subject: "Hello", Body: "Hi"},
+		mockErr: errors.NewServer("Connection refused"),
+		wantMsg: "From: no-reply@example.com\r\nTo: test@example.com\r\nSubject: Hello\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\nHi\r\n",
+		wantErr: errors.Wrap(errors.NewServer("Connection refused"), "Failed to send email"),
+	},
+	{
+		name:    "SuccessfulInvocation",
+		mailer:  testMailer,
+		message: &Message{To: "test@example.com", Subject: "Hello", Body: "Line 1\nLine 2"},
+		wantMsg: "From: no-reply@example.com\r\nTo: test@example.com\r\nSubject: Hello\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\nLine 1\r\nLine 2\r\n",
+	},
+}
+
+func TestSMTPMailer(t *testing.T) {
+	for _, test := range smtpMailerTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			mock := &sendMailMock{err: test.mockErr}
+			sendMail = mock.send
+			defer func() {
+				sendMail = smtp.SendMail
+			}()
+
+			// Execute
+			err := test.mailer.Send(test.message)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+			if mock.msg != test.wantMsg {
+				t.Errorf("Got message %q; want %q", mock.msg, test.wantMsg)
+			}
+			if test.wantMsg != "" {
+				if mock.addr != "smtp.example.com:587" || mock.from != "no-reply@example.com" || !reflect.DeepEqual(mock.to, []string{"test@example.com"}) {
+					t.Errorf("Got addr %s, from %s and to %v; want the configured server and sender", mock.addr, mock.from, mock.to)
+				}
+				if mock.auth == nil {
+					t.Errorf("Got nil auth; want PLAIN auth")
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultMailer(t *testing.T) {
+	os.Setenv("SMTP_HOST", "smtp.example.com")
+	os.Setenv("MAIL_FROM", "no-reply@example.com")
+	defer func() {
+		os.Unsetenv("SMTP_HOST")
+		os.Unsetenv("MAIL_FROM")
+	}()
+
+	want := SMTPMailer{Host: "smtp.example.com", Port: "587", From: "no-reply@example.com"}
+	if mailer := DefaultMailer(); !reflect.DeepEqual(mailer, want) {
+		t.Errorf("Got mailer %+v; want %+v", mailer, want)
+	}
+}
+
+func TestFakeMailer(t *testing.T) {
+	fake := &FakeMailer{}
+	message := &Message{To: "test@example.com", Subject: "Hello", Body: "Hi"}
+	if err := fake.Send(message); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	message.Body = "Changed"
+
+	want := []*Message{{To: "test@example.com", Subject: "Hello", Body: "Hi"}}
+	if messages := fake.Messages(); !reflect.DeepEqual(messages, want) {
+		t.Errorf("Got messages %v; want %v", messages, want)
+	}
+
+	fake.Err = errors.NewServer("Mail failure")
+	if err := fake.Send(message); !errors.Equal(err, fake.Err) || len(fake.Messages()) != 1 {
+		t.Errorf("Got err %v and %d messages; want the mail failure and 1 message", err, len(fake.Messages()))
+	}
+}
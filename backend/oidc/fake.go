@@ -0,0 +1,269 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	nethttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// FakeProvider is an OpenID Connect provider that runs inside the process, so that logins can be tested without a
+// real identity provider. It implements http.Handler and is meant to be served by an httptest.Server, and its
+// issuer is the URL it is served at. Every authorization request of its client is approved for the account in
+// Identity without asking the user. FakeProvider also serves the GitHub API endpoints used to look up GitHub users,
+// so that it can stand in for GitHub.
+type FakeProvider struct {
+	ClientID     string
+	ClientSecret string
+
+	// Identity is the account that logs in. Its Provider is ignored.
+	Identity Identity
+
+	// Claims are added to the claims of the ID tokens issued by the provider, replacing the claims with the same
+	// names, so that tests can issue invalid ID tokens.
+	Claims map[string]interface{}
+
+	mutex        sync.Mutex
+	keyID        string
+	key          *rsa.PrivateKey
+	grants       map[string]fakeGrant
+	accessTokens map[string]bool
+}
+
+// fakeGrant is an authorization code issued by FakeProvider, along with the parameters of the authorization
+// request that it must be exchanged with.
+type fakeGrant struct {
+	redirectURL string
+	challenge   string
+	nonce       string
+}
+
+// NewFakeProvider returns a fake provider with a new signing key for the client with the given credentials.
+func NewFakeProvider(clientID string, clientSecret string) (*FakeProvider, error) {
+	fake := &FakeProvider{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		grants:       make(map[string]fakeGrant),
+		accessTokens: make(map[string]bool),
+	}
+	return fake, fake.RotateKey()
+}
+
+// RotateKey replaces the signing key of the provider with a new key that has a new ID.
+func (f *FakeProvider) RotateKey() error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return errors.Wrap(err, "Failed to generate signing key")
+	}
+	keyID, err := randomValue()
+	if err != nil {
+		return err
+	}
+	f.mutex.Lock()
+	f.key, f.keyID = key, keyID
+	f.mutex.Unlock()
+	return nil
+}
+
+// randomValue returns a random base64url encoded string.
+func randomValue() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to generate random value")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// SignIDToken returns an ID token with the given claims, signed by the current key of the provider.
+func (f *FakeProvider) SignIDToken(claims map[string]interface{}) (string, error) {
+	f.mutex.Lock()
+	key, keyID := f.key, f.keyID
+	f.mutex.Unlock()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyID})
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to encode header")
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to encode claims")
+	}
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to sign ID token")
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Authorize sends the authorization request at the given URL to the provider, like the browser of a user, and
+// returns the URL that the provider redirects the user to.
+func (f *FakeProvider) Authorize(authURL string) (*url.URL, error) {
+	browser := &nethttp.Client{
+		CheckRedirect: func(*nethttp.Request, []*nethttp.Request) error {
+			return nethttp.ErrUseLastResponse
+		},
+	}
+	response, err := browser.Get(authURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to send authorization request")
+	}
+	response.Body.Close()
+	if response.StatusCode != nethttp.StatusFound {
+		return nil, errors.NewServer("Authorization request was rejected with status " + response.Status)
+	}
+	return response.Location()
+}
+
+// ServeHTTP serves the discovery, authorization, token and JSON Web Key Set endpoints of the provider, as well as
+// the GitHub user endpoints.
+func (f *FakeProvider) ServeHTTP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	issuer := "http://" + r.Host
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		writeJSON(w, nethttp.StatusOK, discovery{
+			Issuer:   issuer,
+			AuthURL:  issuer + "/authorize",
+			TokenURL: issuer + "/token",
+			JWKSURL:  issuer + "/jwks",
+		})
+	case "/authorize":
+		f.authorize(w, r)
+	case "/token":
+		f.token(w, r, issuer)
+	case "/jwks":
+		f.mutex.Lock()
+		key := jwk{
+			Kty: "RSA",
+			Kid: f.keyID,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(f.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(f.key.E)).Bytes()),
+		}
+		f.mutex.Unlock()
+		writeJSON(w, nethttp.StatusOK, jwks{Keys: []jwk{key}})
+	case "/user", "/user/emails":
+		f.githubUser(w, r)
+	default:
+		nethttp.NotFound(w, r)
+	}
+}
+
+// authorize issues an authorization code for a valid authorization request of the client and redirects to its
+// redirect URL.
+func (f *FakeProvider) authorize(w nethttp.ResponseWriter, r *nethttp.Request) {
+	query := r.URL.Query()
+	redirectURL, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || query.Get("client_id") != f.ClientID || query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" || query.Get("state") == "" {
+		writeJSON(w, nethttp.StatusBadRequest, tokenResponse{Error: "invalid_request"})
+		return
+	}
+
+	code, err := randomValue()
+	if err != nil {
+		writeJSON(w, nethttp.StatusInternalServerError, tokenResponse{Error: "server_error"})
+		return
+	}
+	f.mutex.Lock()
+	f.grants[code] = fakeGrant{
+		redirectURL: query.Get("redirect_uri"),
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+	}
+	f.mutex.Unlock()
+
+	callback := redirectURL.Query()
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+	redirectURL.RawQuery = callback.Encode()
+	nethttp.Redirect(w, r, redirectURL.String(), nethttp.StatusFound)
+}
+
+// token exchanges an authorization code for an access token and an ID token. Each code can only be exchanged
+// once, by the client, with the redirect URL and code verifier of its authorization request.
+func (f *FakeProvider) token(w nethttp.ResponseWriter, r *nethttp.Request, issuer string) {
+	if r.Method != "POST" || r.ParseForm() != nil {
+		writeJSON(w, nethttp.StatusBadRequest, tokenResponse{Error: "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("client_id") != f.ClientID || r.PostForm.Get("client_secret") != f.ClientSecret {
+		writeJSON(w, nethttp.StatusUnauthorized, tokenResponse{Error: "invalid_client"})
+		return
+	}
+
+	f.mutex.Lock()
+	grant, ok := f.grants[r.PostForm.Get("code")]
+	delete(f.grants, r.PostForm.Get("code"))
+	f.mutex.Unlock()
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("redirect_uri") != grant.redirectURL ||
+		CodeChallenge(r.PostForm.Get("code_verifier")) != grant.challenge {
+		writeJSON(w, nethttp.StatusBadRequest, tokenResponse{Error: "invalid_grant", ErrorDescription: "The code is invalid or has expired"})
+		return
+	}
+
+	claims := map[string]interface{}{
+		"iss":            issuer,
+		"sub":            f.Identity.Subject,
+		"aud":            f.ClientID,
+		"exp":            now().Add(time.Hour).Unix(),
+		"iat":            now().Unix(),
+		"nonce":          grant.nonce,
+		"email":          f.Identity.Email,
+		"email_verified": f.Identity.EmailVerified,
+	}
+	for name, value := range f.Claims {
+		claims[name] = value
+	}
+	idToken, err := f.SignIDToken(claims)
+	accessToken, tokenErr := randomValue()
+	if err != nil || tokenErr != nil {
+		writeJSON(w, nethttp.StatusInternalServerError, tokenResponse{Error: "server_error"})
+		return
+	}
+	f.mutex.Lock()
+	f.accessTokens[accessToken] = true
+	f.mutex.Unlock()
+	writeJSON(w, nethttp.StatusOK, tokenResponse{AccessToken: accessToken, IDToken: idToken})
+}
+
+// githubUser serves the GitHub user and email endpoints for the access tokens issued by the provider.
+func (f *FakeProvider) githubUser(w nethttp.ResponseWriter, r *nethttp.Request) {
+	f.mutex.Lock()
+	ok := f.accessTokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	f.mutex.Unlock()
+	if !ok {
+		writeJSON(w, nethttp.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+		return
+	}
+
+	if r.URL.Path == "/user" {
+		id, _ := strconv.ParseInt(f.Identity.Subject, 10, 64)
+		writeJSON(w, nethttp.StatusOK, githubUser{ID: id})
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, []githubEmail{
+		{Email: "secondary@example.com", Verified: true},
+		{Email: f.Identity.Email, Primary: true, Verified: f.Identity.EmailVerified},
+	})
+}
+
+// writeJSON writes the given status and the JSON encoding of body to w.
+func writeJSON(w nethttp.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
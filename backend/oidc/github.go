@@ -0,0 +1,52 @@
+package oidc
+
+import (
+	"strconv"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// githubUser is the subset of a GitHub user returned by the GitHub API.
+type githubUser struct {
+	ID int64 `json:"id"`
+}
+
+// githubEmail is an email address of a GitHub user returned by the GitHub API.
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// githubIdentity returns the identity of the GitHub user that the given access token belongs to. The subject of
+// the identity is the numeric ID of the user, since GitHub users can change their login, and its email is the
+// primary email of the user.
+func (p *Provider) githubIdentity(accessToken string) (*Identity, error) {
+	if accessToken == "" {
+		return nil, errors.NewServer("Token response is missing the access token")
+	}
+
+	var user githubUser
+	err := getJSON(p.APIURL+"/user", accessToken, &user)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get GitHub user")
+	}
+	if user.ID == 0 {
+		return nil, errors.NewServer("GitHub user has no ID")
+	}
+
+	var emails []githubEmail
+	err = getJSON(p.APIURL+"/user/emails", accessToken, &emails)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get GitHub emails")
+	}
+
+	identity := &Identity{Provider: p.Name, Subject: strconv.FormatInt(user.ID, 10)}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+	return identity, nil
+}
@@ -0,0 +1,226 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// clockSkew is how far the clocks of the provider and the Lambda function may differ when checking the times of
+// an ID token.
+const clockSkew = time.Minute
+
+// jwk is a key of a JSON Web Key Set. Only RSA and P-256 elliptic curve signing keys are supported.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwks is a JSON Web Key Set.
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// publicKey returns the public key described by the JSON Web Key, or nil if the key is not a supported signing key.
+func (key jwk) publicKey() crypto.PublicKey {
+	if key.Use != "" && key.Use != "sig" {
+		return nil
+	}
+	decode := func(value string) *big.Int {
+		b, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(b) == 0 {
+			return nil
+		}
+		return new(big.Int).SetBytes(b)
+	}
+
+	switch key.Kty {
+	case "RSA":
+		n, e := decode(key.N), decode(key.E)
+		if n == nil || e == nil || !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		x, y := decode(key.X), decode(key.Y)
+		if key.Crv != "P-256" || x == nil || y == nil || !elliptic.P256().IsOnCurve(x, y) {
+			return nil
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	}
+	return nil
+}
+
+// key returns the signing key of the provider with the given ID. The key set of the provider is cached and only
+// fetched again when a token is signed by an unknown key, which happens after the provider rotates its keys.
+func (p *Provider) key(keyID string) (crypto.PublicKey, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if key, ok := p.keys[keyID]; ok {
+		return key, nil
+	}
+
+	var set jwks
+	err := getJSON(p.JWKSURL, "", &set)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to fetch JSON Web Key Set")
+	}
+	p.keys = make(map[string]crypto.PublicKey)
+	for _, key := range set.Keys {
+		if publicKey := key.publicKey(); publicKey != nil {
+			p.keys[key.Kid] = publicKey
+		}
+	}
+	return p.keys[keyID], nil
+}
+
+// audience is the `aud` claim of an ID token, which is either a string or an array of strings.
+type audience []string
+
+func (aud *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*aud = audience{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(aud))
+}
+
+// flag is a boolean claim. Some providers send booleans as the strings "true" and "false".
+type flag bool
+
+func (f *flag) UnmarshalJSON(data []byte) error {
+	*f = flag(string(data) == "true" || string(data) == `"true"`)
+	return nil
+}
+
+// idTokenClaims are the claims of an ID token used by the login flow.
+type idTokenClaims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	Expires         int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	Nonce           string   `json:"nonce"`
+	Email           string   `json:"email"`
+	EmailVerified   flag     `json:"email_verified"`
+}
+
+// verifyIDToken checks that the given ID token is signed by a key of the provider, was issued by the provider to
+// its client, has not expired and contains the given nonce, and returns the identity it describes. If the token
+// is invalid, a client error describing the problem is returned.
+func (p *Provider) verifyIDToken(token string, nonce string) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.NewClient("Invalid ID token format")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errors.NewClient("Invalid ID token header")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.NewClient("Invalid ID token signature")
+	}
+
+	key, err := p.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.NewClient(fmt.Sprintf("ID token is signed by unknown key `%s`", header.Kid))
+	}
+	if !verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature) {
+		return nil, errors.NewClient("Invalid ID token signature")
+	}
+
+	var claims idTokenClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errors.NewClient("Invalid ID token claims")
+	}
+	return p.checkClaims(&claims, nonce)
+}
+
+// checkClaims checks the claims of a correctly signed ID token and returns the identity they describe.
+func (p *Provider) checkClaims(claims *idTokenClaims, nonce string) (*Identity, error) {
+	current := now()
+	switch {
+	case claims.Issuer != p.Issuer:
+		return nil, errors.NewClient("ID token was issued by another provider")
+	case !claims.Audience.contains(p.ClientID):
+		return nil, errors.NewClient("ID token was issued to another client")
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != p.ClientID:
+		return nil, errors.NewClient("ID token was issued to another client")
+	case !current.Before(time.Unix(claims.Expires, 0).Add(clockSkew)):
+		return nil, errors.NewClient("ID token has expired")
+	case current.Add(clockSkew).Before(time.Unix(claims.IssuedAt, 0)):
+		return nil, errors.NewClient("ID token was issued in the future")
+	case nonce == "" || claims.Nonce != nonce:
+		return nil, errors.NewClient("ID token nonce does not match")
+	case claims.Subject == "":
+		return nil, errors.NewClient("ID token has no subject")
+	}
+	return &Identity{
+		Provider:      p.Name,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+	}, nil
+}
+
+// contains returns true if the audience contains the given client ID.
+func (aud audience) contains(clientID string) bool {
+	for _, value := range aud {
+		if value == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeSegment decodes the given base64url encoded JSON segment of a token into v.
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// verifySignature returns true if signature is a valid signature of the signing input under key with the given
+// algorithm. Only RS256 and ES256 are accepted, and the algorithm must match the type of the key, so that a token
+// cannot choose a weaker algorithm, such as `none`.
+func verifySignature(alg string, key crypto.PublicKey, input string, signature []byte) bool {
+	hash := sha256.Sum256([]byte(input))
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return alg == "RS256" && rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature) == nil
+	case *ecdsa.PublicKey:
+		if alg != "ES256" || len(signature) != 64 {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(key, hash[:], r, s)
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+// Package oidc implements the client side of the OAuth 2.0 authorization code flow with PKCE, which lets users log
+// in with an account of an external identity provider. OpenID Connect providers, such as Google, are discovered from
+// their issuer, and the identity of the user is read from the ID token they issue, which is verified against the
+// JSON Web Key Set of the provider. GitHub does not issue ID tokens, so the identity of GitHub users is read from the
+// GitHub API instead.
+//
+// The providers that users can log in with are listed by the OIDC_PROVIDERS environment variable, separated by
+// commas. Each provider is configured by environment variables named after it: OIDC_<NAME>_CLIENT_ID and
+// OIDC_<NAME>_CLIENT_SECRET hold the credentials of the OAuth client, and OIDC_<NAME>_ISSUER holds the issuer of
+// generic OpenID Connect providers. The `github` and `google` providers do not need an issuer.
+package oidc
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// The built-in providers, which are configured without an issuer.
+const (
+	GitHub = "github"
+	Google = "google"
+)
+
+const (
+	githubAuthURL  = "https://github.com/login/oauth/authorize"
+	githubTokenURL = "https://github.com/login/oauth/access_token"
+	githubAPIURL   = "https://api.github.com"
+	googleIssuer   = "https://accounts.google.com"
+)
+
+// namePattern matches valid provider names. Names are part of environment variable names and URL paths.
+var namePattern = regexp.MustCompile(`^[a-z0-9]{1,32}$`)
+
+// client sends the requests made to identity providers. It should only be changed inside a test.
+var client = &nethttp.Client{Timeout: 10 * time.Second}
+
+// now returns the current time. It should only be changed inside a test.
+var now = time.Now
+
+// Identity describes the account of a user at an identity provider.
+type Identity struct {
+	// Provider is the name of the identity provider.
+	Provider string
+
+	// Subject identifies the account to the provider. Unlike the email, it never changes.
+	Subject string
+
+	// Email is the email address of the account, or the empty string if the provider did not share it.
+	Email string
+
+	// EmailVerified is true if the provider has verified that the user owns the email address.
+	EmailVerified bool
+}
+
+// Provider is an identity provider that users can log in with.
+type Provider struct {
+	// Name identifies the provider in URLs and in the identities of its users.
+	Name string
+
+	// ClientID and ClientSecret are the credentials of the OAuth client registered with the provider.
+	ClientID     string
+	ClientSecret string
+
+	// Scopes are the scopes requested from the user.
+	Scopes []string
+
+	// Issuer is the issuer of the ID tokens of an OpenID Connect provider. It is empty for GitHub.
+	Issuer string
+
+	// AuthURL, TokenURL and JWKSURL are the authorization, token and JSON Web Key Set endpoints of the provider.
+	// JWKSURL is empty for GitHub.
+	AuthURL  string
+	TokenURL string
+	JWKSURL  string
+
+	// APIURL is the base URL of the GitHub API, which is used to look up the identity of GitHub users.
+	APIURL string
+
+	// mutex protects keys, which caches the JSON Web Key Set of the provider by key ID.
+	mutex sync.Mutex
+	keys  map[string]crypto.PublicKey
+}
+
+// providers caches the providers that have been looked up, so that each provider is only discovered once per
+// Lambda container.
+var providers = struct {
+	sync.Mutex
+	byName map[string]*Provider
+}{byName: make(map[string]*Provider)}
+
+// LookupFunc is the signature of Lookup. Actions accept a LookupFunc in order to allow dependency injection.
+type LookupFunc func(string) (*Provider, error)
+
+// Lookup returns the provider with the given name, which must be listed by the OIDC_PROVIDERS environment
+// variable. OpenID Connect providers are discovered from their issuer the first time they are looked up. Since the
+// name usually comes from the URL of the request, a client error is returned if no provider has the name.
+func Lookup(name string) (*Provider, error) {
+	enabled := false
+	for _, configured := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		if strings.TrimSpace(configured) == name {
+			enabled = true
+		}
+	}
+	if !enabled || !namePattern.MatchString(name) {
+		return nil, errors.NewClient(fmt.Sprintf("Unsupported login provider `%s`", name))
+	}
+
+	providers.Lock()
+	defer providers.Unlock()
+	if provider, ok := providers.byName[name]; ok {
+		return provider, nil
+	}
+	provider, err := configure(name)
+	if err != nil {
+		return nil, err
+	}
+	providers.byName[name] = provider
+	return provider, nil
+}
+
+// configure returns the provider with the given name, configured from the environment.
+func configure(name string) (*Provider, error) {
+	prefix := "OIDC_" + strings.ToUpper(name) + "_"
+	provider := &Provider{
+		Name:         name,
+		ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+		ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+		Issuer:       os.Getenv(prefix + "ISSUER"),
+	}
+	if provider.ClientID == "" {
+		return nil, errors.NewServer(fmt.Sprintf("Login provider `%s` has no client ID", name))
+	}
+
+	if name == GitHub && provider.Issuer == "" {
+		provider.AuthURL = githubAuthURL
+		provider.TokenURL = githubTokenURL
+		provider.APIURL = githubAPIURL
+		provider.Scopes = []string{"read:user", "user:email"}
+		return provider, nil
+	}
+	if name == Google && provider.Issuer == "" {
+		provider.Issuer = googleIssuer
+	}
+	if provider.Issuer == "" {
+		return nil, errors.NewServer(fmt.Sprintf("Login provider `%s` has no issuer", name))
+	}
+	provider.Scopes = []string{"openid", "email", "profile"}
+	err := provider.discover()
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Failed to discover login provider `%s`", name))
+	}
+	return provider, nil
+}
+
+// discovery is the subset of the OpenID Provider Metadata used by the login flow.
+type discovery struct {
+	Issuer   string `json:"issuer"`
+	AuthURL  string `json:"authorization_endpoint"`
+	TokenURL string `json:"token_endpoint"`
+	JWKSURL  string `json:"jwks_uri"`
+}
+
+// discover sets the endpoints of the provider from the OpenID Provider Metadata published by its issuer.
+func (p *Provider) discover() error {
+	var metadata discovery
+	err := getJSON(strings.TrimSuffix(p.Issuer, "/")+"/.well-known/openid-configuration", "", &metadata)
+	if err != nil {
+		return err
+	}
+	if metadata.Issuer != p.Issuer {
+		return errors.NewServer(fmt.Sprintf("Discovered issuer `%s` does not match `%s`", metadata.Issuer, p.Issuer))
+	}
+	if metadata.AuthURL == "" || metadata.TokenURL == "" || metadata.JWKSURL == "" {
+		return errors.NewServer("Provider metadata is missing an endpoint")
+	}
+	p.AuthURL, p.TokenURL, p.JWKSURL = metadata.AuthURL, metadata.TokenURL, metadata.JWKSURL
+	return nil
+}
+
+// CodeChallenge returns the S256 PKCE code challenge of the given code verifier.
+func CodeChallenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// AuthCodeURL returns the URL of the provider's authorization endpoint to which the user is sent to log in. After
+// the user logs in, the provider sends the user to redirectURL with the given state and an authorization code. The
+// nonce is included in the ID token, and the code can only be exchanged with the given code verifier.
+func (p *Provider) AuthCodeURL(redirectURL string, state string, nonce string, verifier string) string {
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {redirectURL},
+		"scope":                 {strings.Join(p.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {CodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	if p.Issuer != "" {
+		query.Set("nonce", nonce)
+	}
+	separator := "?"
+	if strings.Contains(p.AuthURL, "?") {
+		separator = "&"
+	}
+	return p.AuthURL + separator + query.Encode()
+}
+
+// tokenResponse is the response of a token endpoint.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange exchanges the given authorization code, which was sent to redirectURL, for the identity of the user. The
+// code verifier and nonce must be the ones passed to AuthCodeURL. The ID token of OpenID Connect providers is
+// verified and must contain the nonce. If the provider rejects the code, a client error is returned.
+func (p *Provider) Exchange(code string, redirectURL string, verifier string, nonce string) (*Identity, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURL},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	request, err := nethttp.NewRequest("POST", p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create token request")
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	var token tokenResponse
+	status, err := do(request, &token)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to call token endpoint")
+	}
+	if token.Error != "" || status != nethttp.StatusOK {
+		return nil, errors.NewClient(fmt.Sprintf("Login with %s failed: %s", p.Name, describe(token, status)))
+	}
+
+	if p.Issuer == "" {
+		return p.githubIdentity(token.AccessToken)
+	}
+	if token.IDToken == "" {
+		return nil, errors.NewServer("Token response is missing the ID token")
+	}
+	return p.verifyIDToken(token.IDToken, nonce)
+}
+
+// describe returns the error of the given token response, or its status if it has no error.
+func describe(token tokenResponse, status int) string {
+	if token.ErrorDescription != "" {
+		return token.ErrorDescription
+	}
+	if token.Error != "" {
+		return token.Error
+	}
+	return fmt.Sprintf("status %d", status)
+}
+
+// getJSON sends a GET request to the given address, authorized by the given access token if it is not empty, and
+// decodes the JSON response into result. Responses with a status other than 200 are errors.
+func getJSON(address string, accessToken string, result interface{}) error {
+	request, err := nethttp.NewRequest("GET", address, nil)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create request")
+	}
+	request.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	status, err := do(request, result)
+	if err != nil {
+		return err
+	}
+	if status != nethttp.StatusOK {
+		return errors.NewServer(fmt.Sprintf("GET %s returned status %d", address, status))
+	}
+	return nil
+}
+
+// do sends the given request and decodes the JSON response body into result, unless the body is empty. It returns
+// the status of the response.
+func do(request *nethttp.Request, result interface{}) (int, error) {
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, errors.Wrap(err, "Failed to send request")
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return 0, errors.Wrap(err, "Failed to read response")
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, result); err != nil && response.StatusCode == nethttp.StatusOK {
+			return 0, errors.Wrap(err, "Failed to decode response")
+		}
+	}
+	return response.StatusCode, nil
+}
@@ -0,0 +1,413 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+const redirectURL = "https://api.example.com/oidc/corp/callback"
+
+var currentTime = time.Date(2020, time.May, 1, 12, 30, 0, 0, time.UTC)
+
+// startFake serves a new fake provider, which logs in the user 1234 with a verified email, and returns the
+// provider along with its URL. The returned function stops the server.
+func startFake(t *testing.T) (*FakeProvider, string, func()) {
+	fake, err := NewFakeProvider("client", "secret")
+	if err != nil {
+		t.Fatalf("Failed to create fake provider: %v", err)
+	}
+	fake.Identity = Identity{Subject: "1234", Email: "test@example.com", EmailVerified: true}
+	server := httptest.NewServer(fake)
+	return fake, server.URL, server.Close
+}
+
+// fakeProvider returns the provider with the given name that logs in with the fake provider at the given URL.
+func fakeProvider(name string, issuer string) *Provider {
+	return &Provider{
+		Name:         name,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"openid", "email"},
+		Issuer:       issuer,
+		AuthURL:      issuer + "/authorize",
+		TokenURL:     issuer + "/token",
+		JWKSURL:      issuer + "/jwks",
+	}
+}
+
+// login logs in with the given provider through the fake and returns the authorization code and state sent to the
+// redirect URL.
+func login(t *testing.T, fake *FakeProvider, provider *Provider, verifier string) (string, string) {
+	location, err := fake.Authorize(provider.AuthCodeURL(redirectURL, "state", "nonce", verifier))
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if got := location.Scheme + "://" + location.Host + location.Path; got != redirectURL {
+		t.Errorf("Got redirect to %s; want %s", got, redirectURL)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestLookup(t *testing.T) {
+	_, issuer, stop := startFake(t)
+	defer stop()
+	os.Setenv("OIDC_PROVIDERS", "corp, github,nosecret,broken")
+	os.Setenv("OIDC_CORP_CLIENT_ID", "client")
+	os.Setenv("OIDC_CORP_CLIENT_SECRET", "secret")
+	os.Setenv("OIDC_CORP_ISSUER", issuer)
+	os.Setenv("OIDC_GITHUB_CLIENT_ID", "github-client")
+	os.Setenv("OIDC_BROKEN_CLIENT_ID", "client")
+	os.Setenv("OIDC_BROKEN_ISSUER", issuer+"/missing")
+	defer func() {
+		for _, name := range []string{"OIDC_PROVIDERS", "OIDC_CORP_CLIENT_ID", "OIDC_CORP_CLIENT_SECRET", "OIDC_CORP_ISSUER",
+			"OIDC_GITHUB_CLIENT_ID", "OIDC_BROKEN_CLIENT_ID", "OIDC_BROKEN_ISSUER"} {
+			os.Unsetenv(name)
+		}
+	}()
+
+	t.Run("Discovered", func(t *testing.T) {
+		provider, err := Lookup("corp")
+		if err != nil {
+			t.Fatalf("Got unexpected error: %v", err)
+		}
+		want := &Provider{
+			Name:         "corp",
+			ClientID:     "client",
+			ClientSecret: "secret",
+			Scopes:       []string{"openid", "email", "profile"},
+			Issuer:       issuer,
+			AuthURL:      issuer + "/authorize",
+			TokenURL:     issuer + "/token",
+			JWKSURL:      issuer + "/jwks",
+		}
+		if !reflect.DeepEqual(provider, want) {
+			t.Errorf("Got provider %+v; want %+v", provider, want)
+		}
+		if cached, _ := Lookup("corp"); cached != provider {
+			t.Errorf("Got a new provider from the second lookup; want the cached provider")
+		}
+	})
+
+	t.Run("GitHub", func(t *testing.T) {
+		provider, err := Lookup("github")
+		if err != nil {
+			t.Fatalf("Got unexpected error: %v", err)
+		}
+		if provider.ClientID != "github-client" || provider.AuthURL != githubAuthURL || provider.APIURL != githubAPIURL || provider.Issuer != "" {
+			t.Errorf("Got provider %+v; want the GitHub endpoints", provider)
+		}
+	})
+
+	errorTests := []struct {
+		name    string
+		wantErr error
+	}{
+		{name: "google", wantErr: errors.NewClient("Unsupported login provider `google`")},
+		{name: "Corp", wantErr: errors.NewClient("Unsupported login provider `Corp`")},
+		{name: "nosecret", wantErr: errors.NewServer("Login provider `nosecret` has no client ID")},
+		{name: "broken", wantErr: errors.Wrap(errors.NewServer("GET "+issuer+"/missing/.well-known/openid-configuration returned status 404"), "Failed to discover login provider `broken`")},
+	}
+	for _, test := range errorTests {
+		t.Run(test.name, func(t *testing.T) {
+			provider, err := Lookup(test.name)
+			if provider != nil || !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got provider %+v and err %v; want err %v", provider, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	provider := fakeProvider("corp", "https://id.example.com")
+	provider.AuthURL = "https://id.example.com/authorize?prompt=login"
+
+	got, err := url.Parse(provider.AuthCodeURL(redirectURL, "state", "nonce", "verifier"))
+	if err != nil {
+		t.Fatalf("Got invalid URL: %v", err)
+	}
+	hash := sha256.Sum256([]byte("verifier"))
+	want := url.Values{
+		"prompt":                {"login"},
+		"response_type":         {"code"},
+		"client_id":             {"client"},
+		"redirect_uri":          {redirectURL},
+		"scope":                 {"openid email"},
+		"state":                 {"state"},
+		"nonce":                 {"nonce"},
+		"code_challenge":        {urlEncoding(hash[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if !reflect.DeepEqual(got.Query(), want) {
+		t.Errorf("Got query %v; want %v", got.Query(), want)
+	}
+
+	// GitHub does not support nonces
+	provider.Issuer = ""
+	got, _ = url.Parse(provider.AuthCodeURL(redirectURL, "state", "nonce", "verifier"))
+	if _, ok := got.Query()["nonce"]; ok {
+		t.Errorf("Got nonce in the authorization URL of a provider without ID tokens")
+	}
+}
+
+func TestExchange(t *testing.T) {
+	fake, issuer, stop := startFake(t)
+	defer stop()
+	provider := fakeProvider("corp", issuer)
+
+	code, state := login(t, fake, provider, "verifier")
+	if state != "state" {
+		t.Errorf("Got state %s; want `state`", state)
+	}
+	identity, err := provider.Exchange(code, redirectURL, "verifier", "nonce")
+	want := &Identity{Provider: "corp", Subject: "1234", Email: "test@example.com", EmailVerified: true}
+	if err != nil || !reflect.DeepEqual(identity, want) {
+		t.Errorf("Got identity %+v and err %v; want %+v", identity, err, want)
+	}
+
+	invalidGrant := errors.NewClient("Login with corp failed: The code is invalid or has expired")
+	t.Run("ReusedCode", func(t *testing.T) {
+		identity, err := provider.Exchange(code, redirectURL, "verifier", "nonce")
+		if identity != nil || !errors.Equal(err, invalidGrant) {
+			t.Errorf("Got identity %+v and err %v; want %v", identity, err, invalidGrant)
+		}
+	})
+
+	t.Run("WrongVerifier", func(t *testing.T) {
+		code, _ := login(t, fake, provider, "verifier")
+		identity, err := provider.Exchange(code, redirectURL, "other verifier", "nonce")
+		if identity != nil || !errors.Equal(err, invalidGrant) {
+			t.Errorf("Got identity %+v and err %v; want %v", identity, err, invalidGrant)
+		}
+	})
+
+	t.Run("WrongNonce", func(t *testing.T) {
+		code, _ := login(t, fake, provider, "verifier")
+		identity, err := provider.Exchange(code, redirectURL, "verifier", "other nonce")
+		wantErr := errors.NewClient("ID token nonce does not match")
+		if identity != nil || !errors.Equal(err, wantErr) {
+			t.Errorf("Got identity %+v and err %v; want %v", identity, err, wantErr)
+		}
+	})
+
+	t.Run("WrongSecret", func(t *testing.T) {
+		code, _ := login(t, fake, provider, "verifier")
+		other := fakeProvider("corp", issuer)
+		other.ClientSecret = "other secret"
+		identity, err := other.Exchange(code, redirectURL, "verifier", "nonce")
+		wantErr := errors.NewClient("Login with corp failed: invalid_client")
+		if identity != nil || !errors.Equal(err, wantErr) {
+			t.Errorf("Got identity %+v and err %v; want %v", identity, err, wantErr)
+		}
+	})
+
+	t.Run("KeyRotation", func(t *testing.T) {
+		if err := fake.RotateKey(); err != nil {
+			t.Fatalf("Failed to rotate key: %v", err)
+		}
+		code, _ := login(t, fake, provider, "verifier")
+		identity, err := provider.Exchange(code, redirectURL, "verifier", "nonce")
+		if err != nil || !reflect.DeepEqual(identity, want) {
+			t.Errorf("Got identity %+v and err %v after a key rotation; want %+v", identity, err, want)
+		}
+	})
+}
+
+func TestGitHubExchange(t *testing.T) {
+	fake, server, stop := startFake(t)
+	defer stop()
+	provider := fakeProvider("github", "")
+	provider.AuthURL, provider.TokenURL, provider.APIURL = server+"/authorize", server+"/token", server
+
+	code, _ := login(t, fake, provider, "verifier")
+	identity, err := provider.Exchange(code, redirectURL, "verifier", "")
+	want := &Identity{Provider: "github", Subject: "1234", Email: "test@example.com", EmailVerified: true}
+	if err != nil || !reflect.DeepEqual(identity, want) {
+		t.Errorf("Got identity %+v and err %v; want %+v", identity, err, want)
+	}
+
+	t.Run("UnverifiedEmail", func(t *testing.T) {
+		fake.Identity.EmailVerified = false
+		defer func() {
+			fake.Identity.EmailVerified = true
+		}()
+		code, _ := login(t, fake, provider, "verifier")
+		identity, err := provider.Exchange(code, redirectURL, "verifier", "")
+		if err != nil || identity.Email != "test@example.com" || identity.EmailVerified {
+			t.Errorf("Got identity %+v and err %v; want the primary email to be unverified", identity, err)
+		}
+	})
+}
+
+var idTokenTests = []struct {
+	name    string
+	claims  map[string]interface{}
+	wantErr error
+}{
+	{
+		name:    "OtherIssuer",
+		claims:  map[string]interface{}{"iss": "https://other.example.com"},
+		wantErr: errors.NewClient("ID token was issued by another provider"),
+	},
+	{
+		name:    "OtherAudience",
+		claims:  map[string]interface{}{"aud": "other"},
+		wantErr: errors.NewClient("ID token was issued to another client"),
+	},
+	{
+		name:    "MultipleAudiences",
+		claims:  map[string]interface{}{"aud": []string{"client", "other"}},
+		wantErr: errors.NewClient("ID token was issued to another client"),
+	},
+	{
+		name:   "AuthorizedParty",
+		claims: map[string]interface{}{"aud": []string{"client", "other"}, "azp": "client"},
+	},
+	{
+		name:    "Expired",
+		claims:  map[string]interface{}{"exp": currentTime.Add(-clockSkew).Unix()},
+		wantErr: errors.NewClient("ID token has expired"),
+	},
+	{
+		name:   "ExpiredWithinSkew",
+		claims: map[string]interface{}{"exp": currentTime.Add(-time.Second).Unix()},
+	},
+	{
+		name:    "IssuedInFuture",
+		claims:  map[string]interface{}{"iat": currentTime.Add(2 * clockSkew).Unix()},
+		wantErr: errors.NewClient("ID token was issued in the future"),
+	},
+	{
+		name:    "MissingNonce",
+		claims:  map[string]interface{}{"nonce": ""},
+		wantErr: errors.NewClient("ID token nonce does not match"),
+	},
+	{
+		name:    "MissingSubject",
+		claims:  map[string]interface{}{"sub": ""},
+		wantErr: errors.NewClient("ID token has no subject"),
+	},
+	{
+		name:   "StringEmailVerified",
+		claims: map[string]interface{}{"email_verified": "true"},
+	},
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	now = func() time.Time { return currentTime }
+	defer func() {
+		now = time.Now
+	}()
+	fake, issuer, stop := startFake(t)
+	defer stop()
+	provider := fakeProvider("corp", issuer)
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		claims := map[string]interface{}{
+			"iss":            issuer,
+			"sub":            "1234",
+			"aud":            "client",
+			"exp":            currentTime.Add(time.Hour).Unix(),
+			"iat":            currentTime.Unix(),
+			"nonce":          "nonce",
+			"email":          "test@example.com",
+			"email_verified": true,
+		}
+		for name, value := range overrides {
+			claims[name] = value
+		}
+		return claims
+	}
+	want := &Identity{Provider: "corp", Subject: "1234", Email: "test@example.com", EmailVerified: true}
+
+	for _, test := range idTokenTests {
+		t.Run(test.name, func(t *testing.T) {
+			token, err := fake.SignIDToken(claims(test.claims))
+			if err != nil {
+				t.Fatalf("Failed to sign ID token: %v", err)
+			}
+			identity, err := provider.verifyIDToken(token, "nonce")
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+			if test.wantErr == nil && !reflect.DeepEqual(identity, want) {
+				t.Errorf("Got identity %+v; want %+v", identity, want)
+			}
+		})
+	}
+
+	token, err := fake.SignIDToken(claims(nil))
+	if err != nil {
+		t.Fatalf("Failed to sign ID token: %v", err)
+	}
+	parts := strings.Split(token, ".")
+	invalidTokens := map[string]struct {
+		token   string
+		wantErr error
+	}{
+		"Format":       {token: parts[0] + "." + parts[1], wantErr: errors.NewClient("Invalid ID token format")},
+		"Header":       {token: "!." + parts[1] + "." + parts[2], wantErr: errors.NewClient("Invalid ID token header")},
+		"UnknownKey":   {token: urlEncoding([]byte(`{"alg":"RS256","kid":"other"}`)) + "." + parts[1] + "." + parts[2], wantErr: errors.NewClient("ID token is signed by unknown key `other`")},
+		"AlgNone":      {token: strings.Replace(token, parts[0], urlEncoding([]byte(`{"alg":"none","kid":"`+fake.keyID+`"}`)), 1), wantErr: errors.NewClient("Invalid ID token signature")},
+		"ChangedClaim": {token: parts[0] + "." + urlEncoding([]byte(`{"sub":"5678"}`)) + "." + parts[2], wantErr: errors.NewClient("Invalid ID token signature")},
+	}
+	for name, test := range invalidTokens {
+		t.Run(name, func(t *testing.T) {
+			identity, err := provider.verifyIDToken(test.token, "nonce")
+			if identity != nil || !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got identity %+v and err %v; want %v", identity, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestECKeys(t *testing.T) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	key := jwk{Kty: "EC", Crv: "P-256", X: urlEncoding(private.X.Bytes()), Y: urlEncoding(private.Y.Bytes())}
+	public := key.publicKey()
+	if public == nil {
+		t.Fatalf("Got no public key for a P-256 JSON Web Key")
+	}
+
+	hash := sha256.Sum256([]byte("input"))
+	r, s, err := ecdsa.Sign(rand.Reader, private, hash[:])
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	signature := make([]byte, 64)
+	rBytes, sBytes := r.Bytes(), s.Bytes()
+	copy(signature[32-len(rBytes):32], rBytes)
+	copy(signature[64-len(sBytes):], sBytes)
+	if !verifySignature("ES256", public, "input", signature) {
+		t.Errorf("Got an invalid ES256 signature; want it to be valid")
+	}
+	if verifySignature("RS256", public, "input", signature) || verifySignature("ES256", public, "other input", signature) {
+		t.Errorf("Got a valid signature with the wrong algorithm or input")
+	}
+
+	if (jwk{Kty: "EC", Crv: "P-384", X: key.X, Y: key.Y}).publicKey() != nil || (jwk{Kty: "EC", Crv: "P-256", X: key.Y, Y: key.X}).publicKey() != nil {
+		t.Errorf("Got a public key for an unsupported curve or a point that is not on the curve")
+	}
+	if (jwk{Kty: "oct", Kid: "secret"}).publicKey() != nil || (jwk{Kty: "EC", Use: "enc", Crv: "P-256", X: key.X, Y: key.Y}).publicKey() != nil {
+		t.Errorf("Got a public key for a symmetric or encryption key")
+	}
+}
+
+// urlEncoding returns the base64url encoding of b without padding.
+func urlEncoding(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
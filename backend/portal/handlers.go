@@ -1,21 +1,52 @@
-// Package portal handles requests to the POST /signup and PUT /login REST API endpoints.
+// Package portal handles requests to the POST /signup, PUT /login, POST /password/forgot, POST /password/reset,
+// POST /email/verify, GET /oidc/{provider}/login and GET /oidc/{provider}/callback REST API endpoints.
 package portal
 
 import (
 	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
 	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+	"github.com/jackstenglein/rest_api_creator/backend/mail"
+	"github.com/jackstenglein/rest_api_creator/backend/oidc"
 )
 
+// loginStateCookie is the name of the cookie that stores the signed login state while the user logs in with an
+// identity provider.
+const loginStateCookie = "oidc_state"
+
 // portalRequest represents the HTTP request body when calling the signup or login APIs and is used for unmarshalling.
 type portalRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
+// forgotPasswordRequest represents the HTTP request body when calling the forgot password API.
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// resetPasswordRequest represents the HTTP request body when calling the reset password API.
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// verifyEmailRequest represents the HTTP request body when calling the verify email API.
+type verifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
 // portalResponse represents the HTTP response body when calling the signup or login APIs and is used for marshalling.
 type portalResponse struct {
 	Error string `json:"error,omitempty"`
@@ -27,8 +58,15 @@ func (response *portalResponse) SetError(err string) {
 	}
 }
 
+// clientInfo describes the device that made a signup or login request. It is stored in the created session so
+// that users can recognize their devices.
+type clientInfo struct {
+	UserAgent string
+	IP        string
+}
+
 // portalFunc wraps the function signature for functions that perform portal actions.
-type portalFunc func(email string, password string) (string, error)
+type portalFunc func(email string, password string, client clientInfo) (string, error)
 
 // generateTokenFunc wraps the function signature for functions that generate auth tokens.
 type generateTokenFunc func() (string, error)
@@ -36,6 +74,27 @@ type generateTokenFunc func() (string, error)
 // generateCookieFunc wraps the function signature for functions that generate cookies.
 type generateCookieFunc func(string, string) (string, error)
 
+// newSessionFunc wraps the function signature for functions that create sessions.
+type newSessionFunc func(email string, token string, userAgent string, ip string) *dao.Session
+
+// generateEmailTokenFunc wraps the function signature for functions that generate email tokens.
+type generateEmailTokenFunc func(auth.EmailTokenPurpose, string, time.Duration) (string, string, error)
+
+// verifyEmailTokenFunc wraps the function signature for functions that verify email tokens.
+type verifyEmailTokenFunc func(string, auth.EmailTokenPurpose) (*auth.EmailToken, error)
+
+// oidcLoginActionFunc wraps the function signature for functions that start a login with an identity provider.
+type oidcLoginActionFunc func(provider string, link bool, cookie string) (string, string, error)
+
+// oidcCallbackActionFunc wraps the function signature for functions that complete a login with an identity provider.
+type oidcCallbackActionFunc func(request oidcCallbackRequest, client clientInfo) (string, error)
+
+// sendVerificationFunc wraps the function signature for functions that email a verification link to a user.
+type sendVerificationFunc func(email string) error
+
+// mailer is used to send email to users. It should only be changed inside a test.
+var mailer mail.Mailer = mail.DefaultMailer()
+
 // signupFunc points to the function used to perform the signup action.
 // This variable should be changed only to perform dependency injection in unit tests.
 var signupFunc = handleSignup
@@ -44,6 +103,22 @@ var signupFunc = handleSignup
 // This variable should be changed only to perform dependency injection in unit tests.
 var loginFunc = handleLogin
 
+// forgotPasswordFunc, resetPasswordFunc and verifyEmailFunc point to the functions used to perform the forgot
+// password, reset password and verify email actions. These variables should be changed only to perform dependency
+// injection in unit tests.
+var (
+	forgotPasswordFunc = handleForgotPassword
+	resetPasswordFunc  = handleResetPassword
+	verifyEmailFunc    = handleVerifyEmail
+)
+
+// oidcLoginFunc and oidcCallbackFunc point to the functions used to start and complete a login with an identity
+// provider. These variables should be changed only to perform dependency injection in unit tests.
+var (
+	oidcLoginFunc    oidcLoginActionFunc    = handleOIDCLogin
+	oidcCallbackFunc oidcCallbackActionFunc = handleOIDCCallback
+)
+
 // HandleSignupRequest acts as a middle-man between AWS APIGateway and the signup action function. HandleSignupRequest
 // unmarshals the request from APIGateway and forwards it to the signup action. HandleSignupRequest then marshals
 // the response from the signup action and returns it to APIGateway. The request must contain the `email` and
@@ -67,6 +142,144 @@ func HandleLoginRequest(request events.APIGatewayProxyRequest) (events.APIGatewa
 	return handleRequest(request, loginFunc)
 }
 
+// HandleForgotPassword parses the request from AWS APIGateway and passes it to the forgotPassword action, which
+// emails the user a link to reset their password. The request must contain the `email` body parameter. If the
+// request succeeds, the response will have status 200 OK and an empty body, whether or not a user has the email.
+// If the request fails, the response will have either a 400 or a 500 status and the body will have an `error`
+// field.
+func HandleForgotPassword(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var forgotRequest forgotPasswordRequest
+	json.Unmarshal([]byte(request.Body), &forgotRequest)
+
+	err := forgotPasswordFunc(forgotRequest.Email)
+	log.Error(err)
+	return http.GatewayResponse(&portalResponse{}, "", err), nil
+}
+
+// HandleResetPassword parses the request from AWS APIGateway and passes it to the resetPassword action. The request
+// must contain the `token` body parameter, taken from the link of a password reset email, and the new `password`.
+// If the request succeeds, the response will have status 200 OK and an empty body, and every session of the user
+// will have been logged out. If the request fails, the response will have either a 400 or a 500 status and the body
+// will have an `error` field.
+func HandleResetPassword(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var resetRequest resetPasswordRequest
+	json.Unmarshal([]byte(request.Body), &resetRequest)
+
+	err := resetPasswordFunc(resetRequest.Token, resetRequest.Password)
+	log.Error(err)
+	return http.GatewayResponse(&portalResponse{}, "", err), nil
+}
+
+// HandleVerifyEmail parses the request from AWS APIGateway and passes it to the verifyEmail action. The request must
+// contain the `token` body parameter, taken from the link of a verification email. If the request succeeds, the
+// response will have status 200 OK and an empty body. If the request fails, the response will have either a 400 or
+// a 500 status and the body will have an `error` field.
+func HandleVerifyEmail(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var verifyRequest verifyEmailRequest
+	json.Unmarshal([]byte(request.Body), &verifyRequest)
+
+	err := verifyEmailFunc(verifyRequest.Token)
+	log.Error(err)
+	return http.GatewayResponse(&portalResponse{}, "", err), nil
+}
+
+// HandleOIDCLogin starts a login with the identity provider named by the `provider` path parameter. The browser is
+// sent to this endpoint rather than calling it, and the response redirects it to the provider, storing the login
+// state in a cookie that is only sent to the callback endpoint. If the `link` query parameter is `true`, the request
+// must contain a valid `Cookie` header, and the account that the user logs in with at the provider will be linked
+// to the logged in user. If the request fails, the response redirects the browser to the login page of the frontend
+// with an `error` query parameter. This function always returns a nil error.
+func HandleOIDCLogin(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	provider := request.PathParameters["provider"]
+	link := request.QueryStringParameters["link"] == "true"
+	cookie := auth.ExtractCookie(request.Headers["Cookie"])
+
+	authURL, state, err := oidcLoginFunc(provider, link, cookie)
+	if err != nil {
+		log.Error(err)
+		return http.RedirectResponse(loginErrorURL(err), ""), nil
+	}
+	return http.RedirectResponse(authURL, loginStateHeader(provider, state)), nil
+}
+
+// HandleOIDCCallback completes a login with the identity provider named by the `provider` path parameter, which
+// sends the browser to this endpoint with the `code` and `state` query parameters once the user has logged in, or
+// with the `error` query parameter if the login failed. The request must contain the cookie set by HandleOIDCLogin.
+// If the request succeeds, the response redirects the browser to the frontend and the Set-Cookie headers contain
+// the user's auth token, unless the login linked an account to a logged in user. If the request fails, the response
+// redirects the browser to the login page of the frontend with an `error` query parameter. Either way, the cookie
+// set by HandleOIDCLogin is expired. This function always returns a nil error.
+func HandleOIDCCallback(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	query := request.QueryStringParameters
+	callbackRequest := oidcCallbackRequest{
+		Provider:   request.PathParameters["provider"],
+		Code:       query["code"],
+		State:      query["state"],
+		Error:      query["error"],
+		LoginState: extractLoginState(request.Headers),
+	}
+	client := clientInfo{
+		UserAgent: request.Headers["User-Agent"],
+		IP:        request.RequestContext.Identity.SourceIP,
+	}
+
+	// The login state can only be used once, so its cookie is expired whether or not the login succeeded
+	expireState := expiredLoginStateHeader(callbackRequest.Provider)
+	cookie, err := oidcCallbackFunc(callbackRequest, client)
+	if err != nil {
+		log.Error(err)
+		return http.RedirectResponse(loginErrorURL(err), expireState), nil
+	}
+	setCookie := ""
+	if cookie != "" {
+		setCookie = http.SessionCookie(cookie)
+	}
+	return http.RedirectResponse(os.Getenv("APP_URL")+"/", setCookie, expireState), nil
+}
+
+// loginStatePath returns the path of the cookie that stores the login state of the given provider, which is the path
+// of the callback endpoint of the provider.
+func loginStatePath(provider string) string {
+	if callback, err := url.Parse(callbackURL(provider)); err == nil && callback.Path != "" {
+		return callback.Path
+	}
+	return "/"
+}
+
+// loginStateHeader returns the value of the Set-Cookie header that stores the given signed login state of the given
+// provider. The cookie expires with the login state and is only sent to the callback endpoint of the provider, so
+// it never reaches the endpoints that read the session cookie.
+func loginStateHeader(provider string, state string) string {
+	return fmt.Sprintf("%s=%s;Path=%s;Max-Age=%d;HttpOnly;Secure;SameSite=Lax", loginStateCookie, state, loginStatePath(provider), int(auth.LoginStateLifetime.Seconds()))
+}
+
+// expiredLoginStateHeader returns the value of the Set-Cookie header that removes the login state cookie of the given
+// provider from the browser.
+func expiredLoginStateHeader(provider string) string {
+	return fmt.Sprintf("%s=;Path=%s;Max-Age=0;HttpOnly;Secure;SameSite=Lax", loginStateCookie, loginStatePath(provider))
+}
+
+// extractLoginState returns the signed login state stored in the cookies of the given request headers, or the
+// empty string if there is none.
+func extractLoginState(headers map[string]string) string {
+	for name, value := range headers {
+		if strings.EqualFold(name, "Cookie") {
+			request := nethttp.Request{Header: nethttp.Header{"Cookie": {value}}}
+			if cookie, err := request.Cookie(loginStateCookie); err == nil {
+				return cookie.Value
+			}
+		}
+	}
+	return ""
+}
+
+// loginErrorURL returns the URL of the login page of the frontend, which shows the user the message of the given
+// error. The frontend is located at the `APP_URL` environment variable.
+func loginErrorURL(err error) string {
+	message, _ := errors.UserDetails(err)
+	return os.Getenv("APP_URL") + "/login?error=" + url.QueryEscape(message)
+}
+
 // handleRequest is a helper for HandleSignupRequest and HandleLoginRequest. It parses the request object
 // from AWS APIGateway and forwards it to the specified actionFunc. It then marshals the response from the
 // actionFunc and returns the marshalled response to the caller.
@@ -75,20 +288,65 @@ func handleRequest(request events.APIGatewayProxyRequest, actionFunc portalFunc)
 	var portalRequest portalRequest
 	json.Unmarshal([]byte(request.Body), &portalRequest)
 
+	client := clientInfo{
+		UserAgent: request.Headers["User-Agent"],
+		IP:        request.RequestContext.Identity.SourceIP,
+	}
+
 	// Execute action
-	cookie, err := actionFunc(portalRequest.Email, portalRequest.Password)
+	cookie, err := actionFunc(portalRequest.Email, portalRequest.Password, client)
 
 	// Create response
 	return http.GatewayResponse(&portalResponse{}, cookie, err), nil
 }
 
 // actionFunc for the signup action.
-func handleSignup(email string, password string) (string, error) {
-	return signup(email, password, auth.GenerateToken, auth.GenerateCookie, dao.Dynamo)
+func handleSignup(email string, password string, client clientInfo) (string, error) {
+	return signup(email, password, client, auth.GenerateToken, auth.GenerateCookie, auth.NewSession, handleSendVerification, dao.Dynamo)
+}
+
+// sendVerificationFunc for the signup action.
+func handleSendVerification(email string) error {
+	return sendVerification(email, auth.GenerateEmailToken, mailer, dao.Dynamo)
+}
+
+// actionFunc for the forgot password action.
+func handleForgotPassword(email string) error {
+	return forgotPassword(email, auth.GenerateEmailToken, mailer, dao.Dynamo)
+}
+
+// actionFunc for the reset password action.
+func handleResetPassword(token string, password string) error {
+	return resetPassword(token, password, auth.VerifyEmailToken, dao.Dynamo)
+}
+
+// actionFunc for the verify email action.
+func handleVerifyEmail(token string) error {
+	return verifyEmail(token, auth.VerifyEmailToken, dao.Dynamo)
+}
+
+// oidcLoginActionFunc for the OIDC login action.
+func handleOIDCLogin(provider string, link bool, cookie string) (string, string, error) {
+	return oidcLogin(provider, link, cookie, auth.VerifySession, lookupProvider, auth.NewLoginState, auth.SignLoginState, dao.Dynamo)
+}
+
+// oidcCallbackActionFunc for the OIDC callback action.
+func handleOIDCCallback(request oidcCallbackRequest, client clientInfo) (string, error) {
+	return oidcCallback(request, client, lookupProvider, auth.VerifyLoginState, auth.GenerateToken, auth.GenerateCookie, auth.NewSession, dao.Dynamo)
+}
+
+// lookupProviderFunc for the OIDC actions. It converts the provider returned by oidc.Lookup to the oidcProvider
+// interface, so that a missing provider is a nil interface.
+func lookupProvider(name string) (oidcProvider, error) {
+	provider, err := oidc.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
 }
 
 // actionFunc for the login action. This must be separated from handleSignup because signup and login
 // take different interface types for the db parameter.
-func handleLogin(email string, password string) (string, error) {
-	return login(email, password, auth.GenerateToken, auth.GenerateCookie, dao.Dynamo)
+func handleLogin(email string, password string, client clientInfo) (string, error) {
+	return login(email, password, client, auth.GenerateToken, auth.GenerateCookie, auth.NewSession, dao.Dynamo)
 }
@@ -14,8 +14,8 @@ import (
 type handlerFunc func(events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
 
 func portalFuncMock(email string, password string, cookie string, err error) portalFunc {
-	return func(gotEmail string, gotPassword string) (string, error) {
-		if gotEmail != email || gotPassword != password {
+	return func(gotEmail string, gotPassword string, client clientInfo) (string, error) {
+		if gotEmail != email || gotPassword != password || client != testClient {
 			return "", errors.NewServer("Incorrect input to signup mock")
 		}
 		return cookie, err
@@ -24,7 +24,13 @@ func portalFuncMock(email string, password string, cookie string, err error) por
 
 func handlerRequest(email string, password string) events.APIGatewayProxyRequest {
 	json, _ := json.Marshal(&portalRequest{Email: email, Password: password})
-	return events.APIGatewayProxyRequest{Body: string(json)}
+	return events.APIGatewayProxyRequest{
+		Body:    string(json),
+		Headers: map[string]string{"User-Agent": testClient.UserAgent},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Identity: events.APIGatewayRequestIdentity{SourceIP: testClient.IP},
+		},
+	}
 }
 
 func handlerResponse(cookie string, err string, status int) events.APIGatewayProxyResponse {
@@ -113,3 +119,306 @@ func TestHandleRequest(t *testing.T) {
 		}
 	}
 }
+
+// tokenHandlerTests contains the test cases of the handlers that do not return a cookie. Each case calls the handler
+// with the given body and checks the arguments received by the action.
+var tokenHandlerTests = []struct {
+	name     string
+	function handlerFunc
+	body     string
+
+	// Mock data
+	mockErr error
+
+	// Expected output
+	wantArgs     []string
+	wantResponse events.APIGatewayProxyResponse
+}{
+	{
+		name:         "ForgotPassword/SuccessfulInvocation",
+		function:     HandleForgotPassword,
+		body:         `{"email": "test@example.com"}`,
+		wantArgs:     []string{"test@example.com"},
+		wantResponse: handlerResponse("", "", 200),
+	},
+	{
+		name:         "ForgotPassword/ServerError",
+		function:     HandleForgotPassword,
+		body:         `{"email": "test@example.com"}`,
+		mockErr:      errors.Wrap(errors.NewServer("SMTP failure"), "Failed to send password reset email"),
+		wantArgs:     []string{"test@example.com"},
+		wantResponse: handlerResponse("", "Failed to send password reset email", 500),
+	},
+	{
+		name:         "ResetPassword/SuccessfulInvocation",
+		function:     HandleResetPassword,
+		body:         `{"token": "email+token", "password": "12345678"}`,
+		wantArgs:     []string{"email+token", "12345678"},
+		wantResponse: handlerResponse("", "", 200),
+	},
+	{
+		name:         "ResetPassword/ClientError",
+		function:     HandleResetPassword,
+		body:         `{"token": "email+token", "password": "12345678"}`,
+		mockErr:      errors.Wrap(errors.NewClient("Invalid or expired token"), "Invalid password reset token"),
+		wantArgs:     []string{"email+token", "12345678"},
+		wantResponse: handlerResponse("", "Invalid or expired token", 400),
+	},
+	{
+		name:         "VerifyEmail/SuccessfulInvocation",
+		function:     HandleVerifyEmail,
+		body:         `{"token": "email+token"}`,
+		wantArgs:     []string{"email+token"},
+		wantResponse: handlerResponse("", "", 200),
+	},
+	{
+		name:         "VerifyEmail/ClientError",
+		function:     HandleVerifyEmail,
+		body:         `{"token": "email+token"}`,
+		mockErr:      errors.Wrap(errors.NewClient("Token is invalid or has already been used"), "Failed to verify email"),
+		wantArgs:     []string{"email+token"},
+		wantResponse: handlerResponse("", "Token is invalid or has already been used", 400),
+	},
+}
+
+func TestHandleTokenRequests(t *testing.T) {
+	for _, test := range tokenHandlerTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			var gotArgs []string
+			forgotPasswordFunc = func(email string) error {
+				gotArgs = []string{email}
+				return test.mockErr
+			}
+			resetPasswordFunc = func(token string, password string) error {
+				gotArgs = []string{token, password}
+				return test.mockErr
+			}
+			verifyEmailFunc = func(token string) error {
+				gotArgs = []string{token}
+				return test.mockErr
+			}
+			defer func() {
+				forgotPasswordFunc = handleForgotPassword
+				resetPasswordFunc = handleResetPassword
+				verifyEmailFunc = handleVerifyEmail
+			}()
+
+			// Execute
+			response, err := test.function(events.APIGatewayProxyRequest{Body: test.body})
+
+			// Verify
+			if !reflect.DeepEqual(gotArgs, test.wantArgs) {
+				t.Errorf("Got action arguments %v; want %v", gotArgs, test.wantArgs)
+			}
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if err != nil {
+				t.Errorf("Got error %v; want nil", err)
+			}
+		})
+	}
+}
+
+// redirectResponse returns the response that redirects the browser to the given location and sets the given cookies.
+func redirectResponse(location string, setCookies ...string) events.APIGatewayProxyResponse {
+	headers := map[string]string{
+		"Access-Control-Allow-Origin":      os.Getenv("CORS_ORIGIN"),
+		"Access-Control-Allow-Credentials": "true",
+		"Location":                         location,
+	}
+	response := events.APIGatewayProxyResponse{Headers: headers, StatusCode: 302}
+	for _, setCookie := range setCookies {
+		if setCookie != "" {
+			if response.MultiValueHeaders == nil {
+				response.MultiValueHeaders = map[string][]string{}
+			}
+			response.MultiValueHeaders["Set-Cookie"] = append(response.MultiValueHeaders["Set-Cookie"], setCookie)
+		}
+	}
+	return response
+}
+
+// expiredState is the Set-Cookie header that removes the login state cookie of the corp provider.
+const expiredState = "oidc_state=;Path=/dev/oidc/corp/callback;Max-Age=0;HttpOnly;Secure;SameSite=Lax"
+
+var oidcLoginHandlerTests = []struct {
+	name    string
+	request events.APIGatewayProxyRequest
+
+	// Mock data
+	mockErr error
+
+	// Expected output
+	wantArgs     []interface{}
+	wantResponse events.APIGatewayProxyResponse
+}{
+	{
+		name: "Login",
+		request: events.APIGatewayProxyRequest{
+			PathParameters: map[string]string{"provider": "corp"},
+		},
+		wantArgs:     []interface{}{"corp", false, ""},
+		wantResponse: redirectResponse("https://id.example.com/authorize", "oidc_state=signed;Path=/dev/oidc/corp/callback;Max-Age=600;HttpOnly;Secure;SameSite=Lax"),
+	},
+	{
+		name: "Link",
+		request: events.APIGatewayProxyRequest{
+			PathParameters:        map[string]string{"provider": "corp"},
+			QueryStringParameters: map[string]string{"link": "true"},
+			Headers:               map[string]string{"Cookie": "session=cookie"},
+		},
+		wantArgs:     []interface{}{"corp", true, "cookie"},
+		wantResponse: redirectResponse("https://id.example.com/authorize", "oidc_state=signed;Path=/dev/oidc/corp/callback;Max-Age=600;HttpOnly;Secure;SameSite=Lax"),
+	},
+	{
+		name: "ClientError",
+		request: events.APIGatewayProxyRequest{
+			PathParameters: map[string]string{"provider": "unknown"},
+		},
+		mockErr:      errors.Wrap(errors.NewClient("Unsupported login provider `unknown`"), "Failed to get login provider"),
+		wantArgs:     []interface{}{"unknown", false, ""},
+		wantResponse: redirectResponse("https://app.example.com/login?error=Unsupported+login+provider+%60unknown%60", ""),
+	},
+	{
+		name: "ServerError",
+		request: events.APIGatewayProxyRequest{
+			PathParameters: map[string]string{"provider": "corp"},
+		},
+		mockErr:      errors.Wrap(errors.NewServer("Key failure"), "Failed to sign login state"),
+		wantArgs:     []interface{}{"corp", false, ""},
+		wantResponse: redirectResponse("https://app.example.com/login?error=Failed+to+sign+login+state", ""),
+	},
+}
+
+func TestHandleOIDCLogin(t *testing.T) {
+	os.Setenv("API_URL", "https://api.example.com/dev")
+	os.Setenv("APP_URL", "https://app.example.com")
+	defer os.Unsetenv("API_URL")
+	defer os.Unsetenv("APP_URL")
+
+	for _, test := range oidcLoginHandlerTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			var gotArgs []interface{}
+			oidcLoginFunc = func(provider string, link bool, cookie string) (string, string, error) {
+				gotArgs = []interface{}{provider, link, cookie}
+				if test.mockErr != nil {
+					return "", "", test.mockErr
+				}
+				return "https://id.example.com/authorize", "signed", nil
+			}
+			defer func() {
+				oidcLoginFunc = handleOIDCLogin
+			}()
+
+			// Execute
+			response, err := HandleOIDCLogin(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(gotArgs, test.wantArgs) {
+				t.Errorf("Got action arguments %v; want %v", gotArgs, test.wantArgs)
+			}
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if err != nil {
+				t.Errorf("Got error %v; want nil", err)
+			}
+		})
+	}
+}
+
+var oidcCallbackHandlerTests = []struct {
+	name    string
+	request events.APIGatewayProxyRequest
+
+	// Mock data
+	mockCookie string
+	mockErr    error
+
+	// Expected output
+	wantRequest  oidcCallbackRequest
+	wantResponse events.APIGatewayProxyResponse
+}{
+	{
+		name: "Login",
+		request: events.APIGatewayProxyRequest{
+			PathParameters:        map[string]string{"provider": "corp"},
+			QueryStringParameters: map[string]string{"code": "code", "state": "state"},
+			Headers:               map[string]string{"cookie": "theme=dark; oidc_state=signed", "User-Agent": testClient.UserAgent},
+			RequestContext: events.APIGatewayProxyRequestContext{
+				Identity: events.APIGatewayRequestIdentity{SourceIP: testClient.IP},
+			},
+		},
+		mockCookie:   "cookie",
+		wantRequest:  oidcCallbackRequest{Provider: "corp", Code: "code", State: "state", LoginState: "signed"},
+		wantResponse: redirectResponse("https://app.example.com/", "session=cookie;HttpOnly;", expiredState),
+	},
+	{
+		name: "Link",
+		request: events.APIGatewayProxyRequest{
+			PathParameters:        map[string]string{"provider": "corp"},
+			QueryStringParameters: map[string]string{"code": "code", "state": "state"},
+			Headers:               map[string]string{"Cookie": "session=cookie; oidc_state=signed", "User-Agent": testClient.UserAgent},
+			RequestContext: events.APIGatewayProxyRequestContext{
+				Identity: events.APIGatewayRequestIdentity{SourceIP: testClient.IP},
+			},
+		},
+		wantRequest:  oidcCallbackRequest{Provider: "corp", Code: "code", State: "state", LoginState: "signed"},
+		wantResponse: redirectResponse("https://app.example.com/", expiredState),
+	},
+	{
+		name: "ProviderError",
+		request: events.APIGatewayProxyRequest{
+			PathParameters:        map[string]string{"provider": "corp"},
+			QueryStringParameters: map[string]string{"error": "access_denied", "state": "state"},
+			Headers:               map[string]string{"User-Agent": testClient.UserAgent},
+			RequestContext: events.APIGatewayProxyRequestContext{
+				Identity: events.APIGatewayRequestIdentity{SourceIP: testClient.IP},
+			},
+		},
+		mockErr:      errors.NewClient("Login with corp failed: access_denied"),
+		wantRequest:  oidcCallbackRequest{Provider: "corp", State: "state", Error: "access_denied"},
+		wantResponse: redirectResponse("https://app.example.com/login?error=Login+with+corp+failed%3A+access_denied", expiredState),
+	},
+}
+
+func TestHandleOIDCCallback(t *testing.T) {
+	os.Setenv("APP_URL", "https://app.example.com")
+	os.Setenv("API_URL", "https://api.example.com/dev")
+	defer os.Unsetenv("APP_URL")
+	defer os.Unsetenv("API_URL")
+
+	for _, test := range oidcCallbackHandlerTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			var gotRequest oidcCallbackRequest
+			oidcCallbackFunc = func(request oidcCallbackRequest, client clientInfo) (string, error) {
+				gotRequest = request
+				if client != testClient {
+					return "", errors.NewServer("Incorrect input to oidcCallback mock")
+				}
+				return test.mockCookie, test.mockErr
+			}
+			defer func() {
+				oidcCallbackFunc = handleOIDCCallback
+			}()
+
+			// Execute
+			response, err := HandleOIDCCallback(test.request)
+
+			// Verify
+			if gotRequest != test.wantRequest {
+				t.Errorf("Got action request %+v; want %+v", gotRequest, test.wantRequest)
+			}
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if err != nil {
+				t.Errorf("Got error %v; want nil", err)
+			}
+		})
+	}
+}
@@ -9,14 +9,14 @@ import (
 // loginDatabase wraps the database methods required to perform the login action.
 type loginDatabase interface {
 	GetUserInfo(string) (*dao.User, error)
-	UpdateUserToken(string, string) error
+	CreateSession(*dao.Session) error
 }
 
 // login performs the actual actions required to login a user. login checks the user's password against the
-// hashed password stored in the database, it generates an auth token and cookie, and it updates the user's
-// auth token in the database. If there are no errors, login returns the generated cookie. Otherwise, login
-// returns the empty string and the error.
-func login(email string, password string, generateToken generateTokenFunc, generateCookie generateCookieFunc, db loginDatabase) (string, error) {
+// hashed password stored in the database, it generates an auth token and cookie, and it stores a new session
+// for the token and the given client in the database. Other sessions of the user are not affected. If there
+// are no errors, login returns the generated cookie. Otherwise, login returns the empty string and the error.
+func login(email string, password string, client clientInfo, generateToken generateTokenFunc, generateCookie generateCookieFunc, newSession newSessionFunc, db loginDatabase) (string, error) {
 	if email == "" || password == "" {
 		return "", errors.NewClient("Email and password parameters are required")
 	}
@@ -41,9 +41,9 @@ func login(email string, password string, generateToken generateTokenFunc, gener
 		return "", errors.Wrap(err, "Failed to create cookie")
 	}
 
-	err = db.UpdateUserToken(email, token)
+	err = db.CreateSession(newSession(email, token, client.UserAgent, client.IP))
 	if err != nil {
-		return "", errors.Wrap(err, "Failed to update auth token")
+		return "", errors.Wrap(err, "Failed to create session")
 	}
 
 	return cookie, nil
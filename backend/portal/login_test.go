@@ -1,6 +1,7 @@
 package portal
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
@@ -8,11 +9,11 @@ import (
 )
 
 type loginDBMock struct {
-	email          string
-	token          string
-	user           *dao.User
-	getUserErr     error
-	updateTokenErr error
+	email            string
+	token            string
+	user             *dao.User
+	getUserErr       error
+	createSessionErr error
 }
 
 func (mock *loginDBMock) GetUserInfo(email string) (*dao.User, error) {
@@ -22,11 +23,11 @@ func (mock *loginDBMock) GetUserInfo(email string) (*dao.User, error) {
 	return mock.user, mock.getUserErr
 }
 
-func (mock *loginDBMock) UpdateUserToken(email string, token string) error {
-	if email != mock.email || token != mock.token {
-		return errors.NewServer("Incorrect input to UpdateUserToken mock")
+func (mock *loginDBMock) CreateSession(session *dao.Session) error {
+	if !reflect.DeepEqual(session, testSession(mock.email, mock.token)) {
+		return errors.NewServer("Incorrect input to CreateSession mock")
 	}
-	return mock.updateTokenErr
+	return mock.createSessionErr
 }
 
 var testUser = dao.User{Email: "test@example.com", Password: "$2a$14$MNkzNEv8Su7mHfLPIdWoU.t5lElbvlnDka11w27zgfy6Sw44zZsku"}
@@ -86,13 +87,13 @@ var loginTests = []struct {
 		wantErr:        errors.Wrap(errors.NewServer("GenerateCookie failure"), "Failed to create cookie"),
 	},
 	{
-		name:           "UpdateTokenError",
+		name:           "CreateSessionError",
 		email:          "test@example.com",
 		password:       "12345678",
-		db:             &loginDBMock{"test@example.com", "token", &testUser, nil, errors.NewServer("UpdateUserToken failure")},
+		db:             &loginDBMock{"test@example.com", "token", &testUser, nil, errors.NewServer("CreateSession failure")},
 		generateToken:  generateTokenMock("token", nil),
 		generateCookie: generateCookieMock("test@example.com", "token", "cookie", nil),
-		wantErr:        errors.Wrap(errors.NewServer("UpdateUserToken failure"), "Failed to update auth token"),
+		wantErr:        errors.Wrap(errors.NewServer("CreateSession failure"), "Failed to create session"),
 	},
 	{
 		name:           "SuccessfulInvocation",
@@ -109,7 +110,7 @@ func TestLogin(t *testing.T) {
 	for _, test := range loginTests {
 		t.Run(test.name, func(t *testing.T) {
 			// Execute
-			cookie, err := login(test.email, test.password, test.generateToken, test.generateCookie, test.db)
+			cookie, err := login(test.email, test.password, testClient, test.generateToken, test.generateCookie, newSessionMock, test.db)
 
 			// Verify
 			if cookie != test.wantCookie {
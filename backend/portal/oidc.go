@@ -0,0 +1,199 @@
+package portal
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/oidc"
+)
+
+// now returns the current time. It should only be changed inside a test.
+var now = time.Now
+
+// oidcProvider wraps the methods of oidc.Provider used to log in with an external identity provider.
+type oidcProvider interface {
+	AuthCodeURL(redirectURL string, state string, nonce string, verifier string) string
+	Exchange(code string, redirectURL string, verifier string, nonce string) (*oidc.Identity, error)
+}
+
+// lookupProviderFunc wraps the function signature for functions that look up identity providers by name.
+type lookupProviderFunc func(string) (oidcProvider, error)
+
+// newLoginStateFunc wraps the function signature for functions that start a login with an identity provider.
+type newLoginStateFunc func(provider string, link string) (*auth.LoginState, error)
+
+// signLoginStateFunc wraps the function signature for functions that sign login states.
+type signLoginStateFunc func(*auth.LoginState) (string, error)
+
+// verifyLoginStateFunc wraps the function signature for functions that verify signed login states.
+type verifyLoginStateFunc func(value string, provider string, state string) (*auth.LoginState, error)
+
+// oidcCallbackDatabase wraps the database methods required to complete a login with an identity provider.
+type oidcCallbackDatabase interface {
+	GetIdentity(string) (*dao.Identity, error)
+	CreateIdentity(*dao.Identity) error
+	GetUserInfo(string) (*dao.User, error)
+	CreateExternalUser(string, *dao.Project) error
+	CreateSession(*dao.Session) error
+}
+
+// oidcCallbackRequest contains the parameters that an identity provider sends the user back to the API with, along
+// with the signed login state stored in the user's browser when the login started.
+type oidcCallbackRequest struct {
+	Provider   string
+	Code       string
+	State      string
+	Error      string
+	LoginState string
+}
+
+// callbackURL returns the URL that the identity provider with the given name sends users back to after they log in.
+// The API is located at the `API_URL` environment variable.
+func callbackURL(provider string) string {
+	return os.Getenv("API_URL") + "/oidc/" + provider + "/callback"
+}
+
+// identityID returns the ID of the identity with the given subject at the given provider.
+func identityID(provider string, subject string) string {
+	return provider + "#" + subject
+}
+
+// oidcLogin starts a login with the identity provider with the given name. If link is true, the user must be logged
+// in with the given session cookie, and the account that they log in with at the provider will be linked to their
+// user. oidcLogin returns the URL of the provider that the user must be sent to and the signed login state that
+// must be stored in the user's browser until the provider sends the user back. If an error occurs, oidcLogin
+// returns empty strings and the error.
+func oidcLogin(providerName string, link bool, cookie string, verifyCookie auth.VerifyCookieFunc, lookupProvider lookupProviderFunc, newLoginState newLoginStateFunc, signLoginState signLoginStateFunc, db auth.CredentialStore) (string, string, error) {
+	provider, err := lookupProvider(providerName)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Failed to get login provider")
+	}
+
+	linkEmail := ""
+	if link {
+		linkEmail, err = verifyCookie(cookie, db)
+		if err != nil {
+			return "", "", errors.NewClient("Not authenticated")
+		}
+	}
+
+	state, err := newLoginState(providerName, linkEmail)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Failed to create login state")
+	}
+	signedState, err := signLoginState(state)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Failed to sign login state")
+	}
+	return provider.AuthCodeURL(callbackURL(providerName), state.State, state.Nonce, state.Verifier), signedState, nil
+}
+
+// oidcCallback completes a login with an identity provider. It checks the state returned by the provider against the
+// signed login state, exchanges the authorization code for the identity of the user and finds the user that the
+// identity is linked to, linking it if needed. It then generates an auth token and cookie and stores a new session
+// for the token and the given client in the database, like login. If the login was started to link the identity to
+// a logged in user, no session is created and the empty string is returned, since the user already has a session.
+// If an error occurs, oidcCallback returns the empty string and the error.
+func oidcCallback(request oidcCallbackRequest, client clientInfo, lookupProvider lookupProviderFunc, verifyLoginState verifyLoginStateFunc, generateToken generateTokenFunc, generateCookie generateCookieFunc, newSession newSessionFunc, db oidcCallbackDatabase) (string, error) {
+	if request.Error != "" {
+		return "", errors.NewClient(fmt.Sprintf("Login with %s failed: %s", request.Provider, request.Error))
+	}
+	if request.Code == "" {
+		return "", errors.NewClient("Parameter `code` is required")
+	}
+
+	state, err := verifyLoginState(request.LoginState, request.Provider, request.State)
+	if err != nil {
+		return "", errors.Wrap(err, "Invalid login state")
+	}
+
+	provider, err := lookupProvider(request.Provider)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to get login provider")
+	}
+	identity, err := provider.Exchange(request.Code, callbackURL(request.Provider), state.Verifier, state.Nonce)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to verify login")
+	}
+
+	email, err := linkIdentity(identity, state.Link, db)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to link account")
+	}
+	if state.Link != "" {
+		return "", nil
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create auth token")
+	}
+
+	cookie, err := generateCookie(email, token)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create cookie")
+	}
+
+	err = db.CreateSession(newSession(email, token, client.UserAgent, client.IP))
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create session")
+	}
+
+	return cookie, nil
+}
+
+// linkIdentity returns the email of the user that the given identity is linked to. If the identity is not linked
+// yet, it is linked to the user with the email in link or, if link is empty, to the user with the verified email of
+// the identity, who is created if needed. An identity is never linked to an existing user whose email is unverified,
+// since anyone could have signed up with that email.
+func linkIdentity(identity *oidc.Identity, link string, db oidcCallbackDatabase) (string, error) {
+	id := identityID(identity.Provider, identity.Subject)
+	existing, err := db.GetIdentity(id)
+	if err == nil {
+		if link != "" && existing.Email != link {
+			return "", errors.NewClient(fmt.Sprintf("This %s account is already linked to another user", identity.Provider))
+		}
+		return existing.Email, nil
+	}
+	if errors.UserError(err) == nil {
+		return "", errors.Wrap(err, "Failed to get identity")
+	}
+
+	email := link
+	if email == "" {
+		if identity.Email == "" || !identity.EmailVerified {
+			return "", errors.NewClient(fmt.Sprintf("The email of this %s account is not verified", identity.Provider))
+		}
+		email = identity.Email
+
+		user, err := db.GetUserInfo(email)
+		if err == nil && !user.EmailVerified {
+			return "", errors.NewClient(fmt.Sprintf("A user with this email already exists. Log in with your password to link your %s account", identity.Provider))
+		}
+		if err != nil && errors.UserError(err) == nil {
+			return "", errors.Wrap(err, "Failed to get user")
+		}
+		if err != nil {
+			err = db.CreateExternalUser(email, dao.DefaultProject())
+			if err != nil {
+				return "", errors.Wrap(err, "Failed to create user")
+			}
+		}
+	}
+
+	err = db.CreateIdentity(&dao.Identity{
+		ID:       id,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    email,
+		Created:  now().UTC(),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to save identity")
+	}
+	return email, nil
+}
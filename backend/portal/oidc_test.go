@@ -0,0 +1,496 @@
+package portal
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/oidc"
+)
+
+var currentTime = time.Date(2020, time.May, 1, 12, 30, 0, 0, time.UTC)
+
+// providerMock returns its authorization URL and identity, and records the arguments it was called with.
+type providerMock struct {
+	identity    *oidc.Identity
+	exchangeErr error
+	authArgs    []string
+	exchanged   []string
+}
+
+func (mock *providerMock) AuthCodeURL(redirectURL string, state string, nonce string, verifier string) string {
+	mock.authArgs = []string{redirectURL, state, nonce, verifier}
+	return "https://id.example.com/authorize"
+}
+
+func (mock *providerMock) Exchange(code string, redirectURL string, verifier string, nonce string) (*oidc.Identity, error) {
+	mock.exchanged = []string{code, redirectURL, verifier, nonce}
+	return mock.identity, mock.exchangeErr
+}
+
+func lookupProviderMock(provider oidcProvider, mockErr error) lookupProviderFunc {
+	return func(name string) (oidcProvider, error) {
+		if name != "corp" {
+			return nil, errors.NewServer("Incorrect input to lookupProvider mock")
+		}
+		if mockErr != nil {
+			return nil, mockErr
+		}
+		return provider, nil
+	}
+}
+
+// testLoginState returns the login state of a login with corp that links to the given email.
+func testLoginState(link string) *auth.LoginState {
+	return &auth.LoginState{Provider: "corp", State: "state", Nonce: "nonce", Verifier: "verifier", Link: link}
+}
+
+// identityStoreFake stores identities, users and sessions in memory, with the same conditions as dao.Dynamo.
+type identityStoreFake struct {
+	identities map[string]*dao.Identity
+	users      map[string]*dao.User
+	sessions   map[string]*dao.Session
+	projects   map[string]int
+	getErr     error
+	createErr  error
+}
+
+func newIdentityStore() *identityStoreFake {
+	return &identityStoreFake{
+		identities: make(map[string]*dao.Identity),
+		users:      make(map[string]*dao.User),
+		sessions:   make(map[string]*dao.Session),
+		projects:   make(map[string]int),
+	}
+}
+
+func (fake *identityStoreFake) GetIdentity(id string) (*dao.Identity, error) {
+	if fake.getErr != nil {
+		return nil, fake.getErr
+	}
+	identity, ok := fake.identities[id]
+	if !ok {
+		return nil, errors.NewClient("Identity '" + id + "' not found")
+	}
+	return identity, nil
+}
+
+func (fake *identityStoreFake) CreateIdentity(identity *dao.Identity) error {
+	if fake.createErr != nil {
+		return fake.createErr
+	}
+	if _, ok := fake.identities[identity.ID]; ok {
+		return errors.NewClient("This " + identity.Provider + " account is already linked to a user")
+	}
+	fake.identities[identity.ID] = identity
+	return nil
+}
+
+func (fake *identityStoreFake) GetUserInfo(email string) (*dao.User, error) {
+	user, ok := fake.users[email]
+	if !ok {
+		return nil, errors.NewClient("Email '" + email + "' not found")
+	}
+	return user, nil
+}
+
+func (fake *identityStoreFake) CreateExternalUser(email string, project *dao.Project) error {
+	if !reflect.DeepEqual(project, dao.DefaultProject()) {
+		return errors.NewServer("Incorrect input to CreateExternalUser fake")
+	}
+	if _, ok := fake.users[email]; ok {
+		return errors.NewClient("Email already in use")
+	}
+	fake.users[email] = &dao.User{Email: email, EmailVerified: true}
+	fake.projects[email]++
+	return nil
+}
+
+func (fake *identityStoreFake) CreateSession(session *dao.Session) error {
+	fake.sessions[session.ID] = session
+	return nil
+}
+
+func (fake *identityStoreFake) GetSession(id string) (*dao.Session, error) {
+	session, ok := fake.sessions[id]
+	if !ok {
+		return nil, errors.NewClient("Session not found")
+	}
+	return session, nil
+}
+
+func (fake *identityStoreFake) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (fake *identityStoreFake) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, errors.NewClient("Access token not found")
+}
+
+func (fake *identityStoreFake) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+var oidcLoginTests = []struct {
+	name      string
+	link      bool
+	cookie    string
+	lookupErr error
+	verifyErr error
+	stateErr  error
+	signErr   error
+
+	// Expected output
+	wantLink  string
+	wantURL   string
+	wantState string
+	wantErr   error
+}{
+	{
+		name:      "UnsupportedProvider",
+		lookupErr: errors.NewClient("Unsupported login provider `corp`"),
+		wantErr:   errors.Wrap(errors.NewClient("Unsupported login provider `corp`"), "Failed to get login provider"),
+	},
+	{
+		name:      "LinkNotAuthenticated",
+		link:      true,
+		cookie:    "cookie",
+		verifyErr: errors.NewClient("Not authenticated"),
+		wantErr:   errors.NewClient("Not authenticated"),
+	},
+	{
+		name:     "LoginStateError",
+		stateErr: errors.NewServer("Random failure"),
+		wantErr:  errors.Wrap(errors.NewServer("Random failure"), "Failed to create login state"),
+	},
+	{
+		name:    "SignError",
+		signErr: errors.NewServer("Key failure"),
+		wantErr: errors.Wrap(errors.NewServer("Key failure"), "Failed to sign login state"),
+	},
+	{
+		name:      "Login",
+		cookie:    "cookie",
+		wantURL:   "https://id.example.com/authorize",
+		wantState: "signed state",
+	},
+	{
+		name:      "Link",
+		link:      true,
+		cookie:    "cookie",
+		wantLink:  "test@example.com",
+		wantURL:   "https://id.example.com/authorize",
+		wantState: "signed state",
+	},
+}
+
+func TestOIDCLogin(t *testing.T) {
+	os.Setenv("API_URL", "https://api.example.com/dev")
+	defer os.Unsetenv("API_URL")
+
+	for _, test := range oidcLoginTests {
+		t.Run(test.name, func(t *testing.T) {
+			provider := &providerMock{}
+			verifyCookie := func(cookie string, db auth.CredentialStore) (string, error) {
+				if cookie != test.cookie {
+					return "", errors.NewServer("Incorrect input to verifyCookie mock")
+				}
+				return "test@example.com", test.verifyErr
+			}
+			newLoginState := func(name string, link string) (*auth.LoginState, error) {
+				if name != "corp" || link != test.wantLink {
+					return nil, errors.NewServer("Incorrect input to newLoginState mock")
+				}
+				return testLoginState(link), test.stateErr
+			}
+			signLoginState := func(state *auth.LoginState) (string, error) {
+				if !reflect.DeepEqual(state, testLoginState(test.wantLink)) {
+					return "", errors.NewServer("Incorrect input to signLoginState mock")
+				}
+				return "signed state", test.signErr
+			}
+
+			authURL, state, err := oidcLogin("corp", test.link, test.cookie, verifyCookie, lookupProviderMock(provider, test.lookupErr), newLoginState, signLoginState, newIdentityStore())
+
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+			if authURL != test.wantURL || state != test.wantState {
+				t.Errorf("Got URL %s and state %s; want %s and %s", authURL, state, test.wantURL, test.wantState)
+			}
+			if test.wantErr == nil {
+				want := []string{"https://api.example.com/dev/oidc/corp/callback", "state", "nonce", "verifier"}
+				if !reflect.DeepEqual(provider.authArgs, want) {
+					t.Errorf("Got AuthCodeURL arguments %v; want %v", provider.authArgs, want)
+				}
+			}
+		})
+	}
+}
+
+var oidcCallbackTests = []struct {
+	name        string
+	request     oidcCallbackRequest
+	link        string
+	stateErr    error
+	lookupErr   error
+	identity    *oidc.Identity
+	exchangeErr error
+	setup       func(*identityStoreFake)
+
+	// Expected output
+	wantCookie   string
+	wantEmail    string
+	wantProjects int
+	wantErr      error
+}{
+	{
+		name:    "ProviderError",
+		request: oidcCallbackRequest{Provider: "corp", Error: "access_denied"},
+		wantErr: errors.NewClient("Login with corp failed: access_denied"),
+	},
+	{
+		name:    "MissingCode",
+		request: oidcCallbackRequest{Provider: "corp", State: "state"},
+		wantErr: errors.NewClient("Parameter `code` is required"),
+	},
+	{
+		name:     "InvalidState",
+		stateErr: errors.NewClient("Invalid or expired login. Please try again"),
+		wantErr:  errors.Wrap(errors.NewClient("Invalid or expired login. Please try again"), "Invalid login state"),
+	},
+	{
+		name:      "LookupError",
+		lookupErr: errors.NewServer("Discovery failure"),
+		wantErr:   errors.Wrap(errors.NewServer("Discovery failure"), "Failed to get login provider"),
+	},
+	{
+		name:        "ExchangeError",
+		exchangeErr: errors.NewClient("ID token nonce does not match"),
+		wantErr:     errors.Wrap(errors.NewClient("ID token nonce does not match"), "Failed to verify login"),
+	},
+	{
+		name:     "UnverifiedEmail",
+		identity: &oidc.Identity{Provider: "corp", Subject: "1234", Email: "test@example.com"},
+		wantErr:  errors.Wrap(errors.NewClient("The email of this corp account is not verified"), "Failed to link account"),
+	},
+	{
+		name:     "GetIdentityError",
+		identity: &oidc.Identity{Provider: "corp", Subject: "1234", Email: "test@example.com", EmailVerified: true},
+		setup: func(db *identityStoreFake) {
+			db.getErr = errors.NewServer("DynamoDB failure")
+		},
+		wantErr: errors.Wrap(errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to get identity"), "Failed to link account"),
+	},
+	{
+		name:     "ExistingUnverifiedUser",
+		identity: &oidc.Identity{Provider: "corp", Subject: "1234", Email: "test@example.com", EmailVerified: true},
+		setup: func(db *identityStoreFake) {
+			db.users["test@example.com"] = &dao.User{Email: "test@example.com"}
+		},
+		wantErr: errors.Wrap(errors.NewClient("A user with this email already exists. Log in with your password to link your corp account"), "Failed to link account"),
+	},
+	{
+		name:     "CreateIdentityError",
+		identity: &oidc.Identity{Provider: "corp", Subject: "1234", Email: "test@example.com", EmailVerified: true},
+		setup: func(db *identityStoreFake) {
+			db.users["test@example.com"] = &dao.User{Email: "test@example.com", EmailVerified: true}
+			db.createErr = errors.NewServer("DynamoDB failure")
+		},
+		wantErr: errors.Wrap(errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to save identity"), "Failed to link account"),
+	},
+	{
+		name:         "NewUser",
+		identity:     &oidc.Identity{Provider: "corp", Subject: "1234", Email: "test@example.com", EmailVerified: true},
+		wantCookie:   "cookie",
+		wantEmail:    "test@example.com",
+		wantProjects: 1,
+	},
+	{
+		name:     "ExistingVerifiedUser",
+		identity: &oidc.Identity{Provider: "corp", Subject: "1234", Email: "test@example.com", EmailVerified: true},
+		setup: func(db *identityStoreFake) {
+			db.users["test@example.com"] = &dao.User{Email: "test@example.com", EmailVerified: true}
+		},
+		wantCookie: "cookie",
+		wantEmail:  "test@example.com",
+	},
+	{
+		name:     "LinkedIdentity",
+		identity: &oidc.Identity{Provider: "corp", Subject: "1234", Email: "changed@example.com"},
+		setup: func(db *identityStoreFake) {
+			db.identities["corp#1234"] = &dao.Identity{ID: "corp#1234", Provider: "corp", Subject: "1234", Email: "test@example.com"}
+		},
+		wantCookie: "cookie",
+		wantEmail:  "test@example.com",
+	},
+	{
+		name:      "Link",
+		link:      "test@example.com",
+		identity:  &oidc.Identity{Provider: "corp", Subject: "1234", Email: "other@example.com"},
+		wantEmail: "test@example.com",
+	},
+	{
+		name:     "LinkedToOtherUser",
+		link:     "test@example.com",
+		identity: &oidc.Identity{Provider: "corp", Subject: "1234"},
+		setup: func(db *identityStoreFake) {
+			db.identities["corp#1234"] = &dao.Identity{ID: "corp#1234", Provider: "corp", Subject: "1234", Email: "other@example.com"}
+		},
+		wantErr: errors.Wrap(errors.NewClient("This corp account is already linked to another user"), "Failed to link account"),
+	},
+}
+
+func TestOIDCCallback(t *testing.T) {
+	now = func() time.Time { return currentTime }
+	defer func() {
+		now = time.Now
+	}()
+	os.Setenv("API_URL", "https://api.example.com/dev")
+	defer os.Unsetenv("API_URL")
+
+	for _, test := range oidcCallbackTests {
+		t.Run(test.name, func(t *testing.T) {
+			db := newIdentityStore()
+			if test.setup != nil {
+				test.setup(db)
+			}
+			request := test.request
+			if request.Provider == "" {
+				request = oidcCallbackRequest{Provider: "corp", Code: "code", State: "state", LoginState: "signed state"}
+			}
+			provider := &providerMock{identity: test.identity, exchangeErr: test.exchangeErr}
+			verifyLoginState := func(value string, name string, state string) (*auth.LoginState, error) {
+				if value != "signed state" || name != "corp" || state != "state" {
+					return nil, errors.NewServer("Incorrect input to verifyLoginState mock")
+				}
+				if test.stateErr != nil {
+					return nil, test.stateErr
+				}
+				return testLoginState(test.link), nil
+			}
+
+			cookie, err := oidcCallback(request, testClient, lookupProviderMock(provider, test.lookupErr), verifyLoginState, generateTokenMock("token", nil), generateCookieMock("test@example.com", "token", "cookie", nil), newSessionMock, db)
+
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+			if cookie != test.wantCookie {
+				t.Errorf("Got cookie %s; want %s", cookie, test.wantCookie)
+			}
+			if test.identity != nil && !reflect.DeepEqual(provider.exchanged, []string{"code", "https://api.example.com/dev/oidc/corp/callback", "verifier", "nonce"}) {
+				t.Errorf("Got Exchange arguments %v", provider.exchanged)
+			}
+			if test.wantEmail != "" {
+				want := &dao.Identity{ID: "corp#1234", Provider: "corp", Subject: "1234", Email: test.wantEmail, Created: currentTime}
+				if identity := db.identities["corp#1234"]; identity.Email != test.wantEmail || (test.setup == nil && !reflect.DeepEqual(identity, want)) {
+					t.Errorf("Got identity %+v; want %+v", identity, want)
+				}
+			}
+			if db.projects["test@example.com"] != test.wantProjects {
+				t.Errorf("Got %d projects created; want %d", db.projects["test@example.com"], test.wantProjects)
+			}
+			wantSessions := 0
+			if test.wantCookie != "" {
+				wantSessions = 1
+				if session := db.sessions["id-token"]; !reflect.DeepEqual(session, testSession("test@example.com", "token")) {
+					t.Errorf("Got session %+v; want %+v", session, testSession("test@example.com", "token"))
+				}
+			}
+			if len(db.sessions) != wantSessions {
+				t.Errorf("Got %d sessions; want %d", len(db.sessions), wantSessions)
+			}
+		})
+	}
+}
+
+// TestOIDCFlow logs in with a fake OpenID Connect provider using real login states, cookies and sessions, then
+// links the account of a second provider to the same user.
+func TestOIDCFlow(t *testing.T) {
+	fake, err := oidc.NewFakeProvider("client", "secret")
+	if err != nil {
+		t.Fatalf("Failed to create fake provider: %v", err)
+	}
+	fake.Identity = oidc.Identity{Subject: "1234", Email: "test@example.com", EmailVerified: true}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	environment := map[string]string{
+		"API_URL":                      "https://api.example.com/dev",
+		"OIDC_PROVIDERS":               "fakecorp,fakeother",
+		"OIDC_FAKECORP_CLIENT_ID":      "client",
+		"OIDC_FAKECORP_CLIENT_SECRET":  "secret",
+		"OIDC_FAKECORP_ISSUER":         server.URL,
+		"OIDC_FAKEOTHER_CLIENT_ID":     "client",
+		"OIDC_FAKEOTHER_CLIENT_SECRET": "secret",
+		"OIDC_FAKEOTHER_ISSUER":        server.URL,
+		"SESSION_KEYS":                 "test:" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))),
+	}
+	for name, value := range environment {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+	db := newIdentityStore()
+
+	// login sends the browser through the login with the given provider and returns the session cookie.
+	login := func(provider string, link bool, sessionCookie string) (string, error) {
+		authURL, state, err := oidcLogin(provider, link, sessionCookie, auth.VerifySession, lookupProvider, auth.NewLoginState, auth.SignLoginState, db)
+		if err != nil {
+			return "", err
+		}
+		callback, err := fake.Authorize(authURL)
+		if err != nil {
+			return "", err
+		}
+		if want := "https://api.example.com/dev/oidc/" + provider + "/callback"; !strings.HasPrefix(callback.String(), want+"?") {
+			t.Errorf("Got callback %s; want %s", callback, want)
+		}
+		request := oidcCallbackRequest{
+			Provider:   provider,
+			Code:       callback.Query().Get("code"),
+			State:      callback.Query().Get("state"),
+			LoginState: state,
+		}
+		return oidcCallback(request, testClient, lookupProvider, auth.VerifyLoginState, auth.GenerateToken, auth.GenerateCookie, auth.NewSession, db)
+	}
+
+	cookie, err := login("fakecorp", false, "")
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if email, err := auth.VerifyCookie(cookie, db); email != "test@example.com" || err != nil {
+		t.Errorf("Got email %s and err %v; want the cookie of test@example.com", email, err)
+	}
+	if user := db.users["test@example.com"]; user == nil || !user.EmailVerified || db.projects["test@example.com"] != 1 {
+		t.Errorf("Got user %+v with %d projects; want a verified user with the default project", user, db.projects["test@example.com"])
+	}
+
+	// Linking requires a session
+	if _, err := login("fakeother", true, ""); !errors.Equal(err, errors.NewClient("Not authenticated")) {
+		t.Errorf("Got err %v for a link without a session; want `Not authenticated`", err)
+	}
+
+	// The account of the other provider has another email, but is linked to the logged in user
+	fake.Identity = oidc.Identity{Subject: "5678", Email: "other@example.com"}
+	linkCookie, err := login("fakeother", true, cookie)
+	if err != nil || linkCookie != "" {
+		t.Fatalf("Got cookie %s and err %v; want no cookie", linkCookie, err)
+	}
+	secondCookie, err := login("fakeother", false, "")
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if email, err := auth.VerifyCookie(secondCookie, db); email != "test@example.com" || err != nil {
+		t.Errorf("Got email %s and err %v; want the linked account to log in as test@example.com", email, err)
+	}
+	if len(db.users) != 1 || len(db.identities) != 2 {
+		t.Errorf("Got %d users and %d identities; want 1 user with 2 identities", len(db.users), len(db.identities))
+	}
+}
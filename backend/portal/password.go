@@ -0,0 +1,104 @@
+package portal
+
+import (
+	"fmt"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/mail"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// forgotPasswordDatabase wraps the database methods required to perform the forgotPassword action.
+type forgotPasswordDatabase interface {
+	SetPasswordResetNonce(string, string) error
+}
+
+// resetPasswordDatabase wraps the database methods required to perform the resetPassword action.
+type resetPasswordDatabase interface {
+	ResetPassword(string, string, string) error
+	ListSessions(string) ([]*dao.Session, error)
+	DeleteSession(string, string) error
+}
+
+// passwordResetEmailBody is the body of the email sent to reset a password. It is formatted with the link that
+// resets the password.
+const passwordResetEmailBody = `Someone requested a password reset for your CRUD Creator account.
+
+Open the link below to choose a new password. The link expires in one hour and can only be used once.
+
+%s
+
+If you did not request a password reset, you can ignore this email.`
+
+// forgotPassword emails the user with the given email a link to reset their password. The nonce of the new token is
+// saved first, so any older reset links stop working. If no user has the email, forgotPassword does nothing and
+// returns nil, so that the request cannot be used to find out which emails have accounts.
+func forgotPassword(email string, generateEmailToken generateEmailTokenFunc, mailer mail.Mailer, db forgotPasswordDatabase) error {
+	if email == "" {
+		return errors.NewClient("Parameter `email` is required")
+	}
+
+	token, nonce, err := generateEmailToken(auth.ResetPasswordPurpose, email, auth.PasswordResetLifetime)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create password reset token")
+	}
+
+	err = db.SetPasswordResetNonce(email, nonce)
+	if errors.UserError(err) != nil {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "Failed to save password reset token")
+	}
+
+	err = mailer.Send(&mail.Message{
+		To:      email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf(passwordResetEmailBody, emailLink("reset-password", token)),
+	})
+	return errors.Wrap(err, "Failed to send password reset email")
+}
+
+// resetPassword replaces the password of the user named by the given reset token with the given password. The token
+// must be valid, unexpired and the newest reset token of the user, and it cannot be used again afterwards. Every
+// session of the user is then revoked, so that a device logged in with the old password is logged out. Access
+// tokens are not affected.
+func resetPassword(token string, password string, verifyEmailToken verifyEmailTokenFunc, db resetPasswordDatabase) error {
+	if token == "" {
+		return errors.NewClient("Parameter `token` is required")
+	}
+
+	err := validatePassword(password)
+	if err != nil {
+		return errors.Wrap(err, "Invalid password")
+	}
+
+	claims, err := verifyEmailToken(token, auth.ResetPasswordPurpose)
+	if err != nil {
+		return errors.Wrap(err, "Invalid password reset token")
+	}
+
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	if err != nil {
+		return errors.Wrap(err, "Failed to hash password")
+	}
+
+	err = db.ResetPassword(claims.Email, claims.Nonce, string(bytes))
+	if err != nil {
+		return errors.Wrap(err, "Failed to reset password")
+	}
+
+	sessions, err := db.ListSessions(claims.Email)
+	if err != nil {
+		return errors.Wrap(err, "Failed to revoke sessions")
+	}
+	for _, session := range sessions {
+		err = db.DeleteSession(claims.Email, session.ID)
+		if err != nil && errors.UserError(err) == nil {
+			return errors.Wrap(err, "Failed to revoke sessions")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package portal
+
+import (
+	"encoding/base64"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/mail"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// userStoreFake stores the nonces, password and sessions of test@example.com in memory, with the same conditions
+// as dao.Dynamo.
+type userStoreFake struct {
+	exists     bool
+	resetNonce string
+	password   string
+	verified   bool
+	sessions   []*dao.Session
+	setErr     error
+	resetErr   error
+	listErr    error
+	deleteErr  error
+	deletedIDs []string
+}
+
+func (fake *userStoreFake) SetPasswordResetNonce(email string, nonce string) error {
+	if fake.setErr != nil {
+		return fake.setErr
+	}
+	if email != "test@example.com" || !fake.exists {
+		return errors.NewClient("Email '" + email + "' not found")
+	}
+	fake.resetNonce = nonce
+	return nil
+}
+
+func (fake *userStoreFake) ResetPassword(email string, nonce string, password string) error {
+	if fake.resetErr != nil {
+		return fake.resetErr
+	}
+	if email != "test@example.com" || fake.resetNonce == "" || nonce != fake.resetNonce {
+		return errors.NewClient("Token is invalid or has already been used")
+	}
+	fake.resetNonce, fake.password, fake.verified = "", password, true
+	return nil
+}
+
+func (fake *userStoreFake) ListSessions(email string) ([]*dao.Session, error) {
+	if email != "test@example.com" {
+		return nil, errors.NewServer("Incorrect input to ListSessions fake")
+	}
+	return fake.sessions, fake.listErr
+}
+
+func (fake *userStoreFake) DeleteSession(email string, sessionID string) error {
+	if email != "test@example.com" {
+		return errors.NewServer("Incorrect input to DeleteSession fake")
+	}
+	fake.deletedIDs = append(fake.deletedIDs, sessionID)
+	return fake.deleteErr
+}
+
+var forgotPasswordTests = []struct {
+	name     string
+	email    string
+	db       *userStoreFake
+	tokenErr error
+	mailErr  error
+
+	// Expected output
+	wantMessages int
+	wantErr      error
+}{
+	{
+		name:    "MissingEmail",
+		db:      &userStoreFake{exists: true},
+		wantErr: errors.NewClient("Parameter `email` is required"),
+	},
+	{
+		name:     "GenerateTokenError",
+		email:    "test@example.com",
+		db:       &userStoreFake{exists: true},
+		tokenErr: errors.NewServer("Key failure"),
+		wantErr:  errors.Wrap(errors.NewServer("Key failure"), "Failed to create password reset token"),
+	},
+	{
+		name:  "UnknownEmail",
+		email: "test@example.com",
+		db:    &userStoreFake{},
+	},
+	{
+		name:    "DatabaseError",
+		email:   "test@example.com",
+		db:      &userStoreFake{exists: true, setErr: errors.NewServer("DynamoDB failure")},
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to save password reset token"),
+	},
+	{
+		name:    "MailError",
+		email:   "test@example.com",
+		db:      &userStoreFake{exists: true},
+		mailErr: errors.NewServer("SMTP failure"),
+		wantErr: errors.Wrap(errors.NewServer("SMTP failure"), "Failed to send password reset email"),
+	},
+	{
+		name:         "SuccessfulInvocation",
+		email:        "test@example.com",
+		db:           &userStoreFake{exists: true},
+		wantMessages: 1,
+	},
+}
+
+func TestForgotPassword(t *testing.T) {
+	for _, test := range forgotPasswordTests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &mail.FakeMailer{Err: test.mailErr}
+			generateEmailToken := generateEmailTokenMock(auth.ResetPasswordPurpose, auth.PasswordResetLifetime, test.tokenErr)
+
+			err := forgotPassword(test.email, generateEmailToken, fake, test.db)
+
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+			messages := fake.Messages()
+			if len(messages) != test.wantMessages {
+				t.Fatalf("Got %d messages; want %d", len(messages), test.wantMessages)
+			}
+			if len(messages) > 0 && (messages[0].To != "test@example.com" || !strings.Contains(messages[0].Body, "/reset-password?token=email%2Btoken")) {
+				t.Errorf("Got message %+v; want a reset link sent to test@example.com", messages[0])
+			}
+		})
+	}
+}
+
+var resetPasswordTests = []struct {
+	name      string
+	token     string
+	password  string
+	verifyErr error
+	db        *userStoreFake
+
+	// Expected output
+	wantDeleted []string
+	wantErr     error
+}{
+	{
+		name:     "MissingToken",
+		password: "12345678",
+		wantErr:  errors.NewClient("Parameter `token` is required"),
+	},
+	{
+		name:     "ShortPassword",
+		token:    "email+token",
+		password: "1234567",
+		wantErr:  errors.Wrap(errors.NewClient("Password is too short"), "Invalid password"),
+	},
+	{
+		name:      "InvalidToken",
+		token:     "email+token",
+		password:  "12345678",
+		verifyErr: errors.NewClient("Invalid or expired token"),
+		wantErr:   errors.Wrap(errors.NewClient("Invalid or expired token"), "Invalid password reset token"),
+	},
+	{
+		name:     "UsedToken",
+		token:    "email+token",
+		password: "12345678",
+		db:       &userStoreFake{exists: true},
+		wantErr:  errors.Wrap(errors.NewClient("Token is invalid or has already been used"), "Failed to reset password"),
+	},
+	{
+		name:     "ListSessionsError",
+		token:    "email+token",
+		password: "12345678",
+		db:       &userStoreFake{exists: true, resetNonce: "nonce", listErr: errors.NewServer("DynamoDB failure")},
+		wantErr:  errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to revoke sessions"),
+	},
+	{
+		name:        "DeleteSessionError",
+		token:       "email+token",
+		password:    "12345678",
+		db:          &userStoreFake{exists: true, resetNonce: "nonce", sessions: []*dao.Session{{ID: "s1"}, {ID: "s2"}}, deleteErr: errors.NewServer("DynamoDB failure")},
+		wantDeleted: []string{"s1"},
+		wantErr:     errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to revoke sessions"),
+	},
+	{
+		name:        "SuccessfulInvocation",
+		token:       "email+token",
+		password:    "12345678",
+		db:          &userStoreFake{exists: true, resetNonce: "nonce", sessions: []*dao.Session{{ID: "s1"}, {ID: "s2"}}},
+		wantDeleted: []string{"s1", "s2"},
+	},
+}
+
+func TestResetPassword(t *testing.T) {
+	for _, test := range resetPasswordTests {
+		t.Run(test.name, func(t *testing.T) {
+			verifyEmailToken := verifyEmailTokenMock(auth.ResetPasswordPurpose, test.verifyErr)
+
+			err := resetPassword(test.token, test.password, verifyEmailToken, test.db)
+
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+			if test.db != nil && !reflect.DeepEqual(test.db.deletedIDs, test.wantDeleted) {
+				t.Errorf("Got deleted sessions %v; want %v", test.db.deletedIDs, test.wantDeleted)
+			}
+		})
+	}
+}
+
+// TestPasswordResetFlow resets a password with the link of the email sent by forgotPassword, using real tokens.
+func TestPasswordResetFlow(t *testing.T) {
+	os.Setenv("SESSION_KEYS", "test:"+base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	defer os.Unsetenv("SESSION_KEYS")
+	db := &userStoreFake{exists: true}
+	fake := &mail.FakeMailer{}
+
+	// Request two resets; only the newest link works
+	for i := 0; i < 2; i++ {
+		if err := forgotPassword("test@example.com", auth.GenerateEmailToken, fake, db); err != nil {
+			t.Fatalf("Got unexpected error: %v", err)
+		}
+	}
+	messages := fake.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("Got %d messages; want 2", len(messages))
+	}
+	tokens := make([]string, 2)
+	for i, message := range messages {
+		start := strings.Index(message.Body, "?token=") + len("?token=")
+		end := start + strings.Index(message.Body[start:], "\n")
+		tokens[i], _ = url.QueryUnescape(message.Body[start:end])
+	}
+
+	wantErr := errors.Wrap(errors.NewClient("Token is invalid or has already been used"), "Failed to reset password")
+	if err := resetPassword(tokens[0], "new password", auth.VerifyEmailToken, db); !errors.Equal(err, wantErr) {
+		t.Errorf("Got err %v for the older link; want %v", err, wantErr)
+	}
+	if err := resetPassword(tokens[1], "new password", auth.VerifyEmailToken, db); err != nil {
+		t.Fatalf("Got err %v for the newest link; want nil", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(db.password), []byte("new password")) != nil || !db.verified {
+		t.Errorf("Got password %s and verified %t; want the new password and a verified email", db.password, db.verified)
+	}
+
+	// The link cannot be used again
+	if err := resetPassword(tokens[1], "other password", auth.VerifyEmailToken, db); !errors.Equal(err, wantErr) {
+		t.Errorf("Got err %v for a used link; want %v", err, wantErr)
+	}
+
+	// A reset token cannot verify an email
+	claims, err := auth.VerifyEmailToken(tokens[1], auth.VerifyEmailPurpose)
+	if claims != nil || err == nil {
+		t.Errorf("Got claims %+v for a reset token used as a verification token; want an error", claims)
+	}
+}
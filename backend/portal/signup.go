@@ -4,13 +4,16 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // signupDatabase wraps the database methods required to perform the signup action.
 type signupDatabase interface {
-	CreateUser(string, string, string) error
+	CreateUser(string, string, *dao.Project) error
+	CreateSession(*dao.Session) error
 }
 
 // validateEmail returns true if the email is valid and false otherwise.
@@ -31,10 +34,12 @@ func validatePassword(password string) error {
 }
 
 // signup performs the actual actions required to create a new user. signup hashes the user's password, generates
-// an auth token and cookie, and stores the new user in the database. If there are no errors, signup returns the
-// generated cookie. Otherwise, signup returns the empty string and the error. If a user with the given email
-// already exists in the database, an error is returned.
-func signup(email string, password string, generateToken generateTokenFunc, generateCookie generateCookieFunc, db signupDatabase) (string, error) {
+// an auth token and cookie, and stores the new user in the database along with the default project and a session
+// for the token and the given client. It then emails the user a link to verify their address; since the account
+// already exists, a failure to send it is logged but does not fail the signup. If there are no errors, signup
+// returns the generated cookie. Otherwise, signup returns the empty string and the error. If a user with the given
+// email already exists in the database, an error is returned.
+func signup(email string, password string, client clientInfo, generateToken generateTokenFunc, generateCookie generateCookieFunc, newSession newSessionFunc, sendVerification sendVerificationFunc, db signupDatabase) (string, error) {
 	ok := validateEmail(email)
 	if !ok {
 		return "", errors.NewClient(fmt.Sprintf("Invalid email: '%s'", email))
@@ -60,10 +65,16 @@ func signup(email string, password string, generateToken generateTokenFunc, gene
 		return "", errors.Wrap(err, "Failed to create cookie")
 	}
 
-	err = db.CreateUser(email, string(bytes), token)
+	err = db.CreateUser(email, string(bytes), dao.DefaultProject())
 	if err != nil {
 		return "", errors.Wrap(err, "Failed to create user")
 	}
 
+	err = db.CreateSession(newSession(email, token, client.UserAgent, client.IP))
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create session")
+	}
+
+	log.Error(sendVerification(email))
 	return cookie, nil
 }
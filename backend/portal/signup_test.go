@@ -1,25 +1,55 @@
 package portal
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
 
 type signupDBMock struct {
-	email        string
-	plaintextPwd string
-	token        string
-	err          error
+	email            string
+	plaintextPwd     string
+	token            string
+	err              error
+	createSessionErr error
 }
 
-func (mock *signupDBMock) CreateUser(email string, hashedPwd string, token string) error {
-	if email != mock.email || hashedPwd == mock.plaintextPwd || token != mock.token {
+func (mock *signupDBMock) CreateUser(email string, hashedPwd string, project *dao.Project) error {
+	if email != mock.email || hashedPwd == mock.plaintextPwd || !reflect.DeepEqual(project, dao.DefaultProject()) {
 		return errors.NewServer("Incorrect input to CreateUser mock")
 	}
 	return mock.err
 }
 
+func (mock *signupDBMock) CreateSession(session *dao.Session) error {
+	if !reflect.DeepEqual(session, testSession(mock.email, mock.token)) {
+		return errors.NewServer("Incorrect input to CreateSession mock")
+	}
+	return mock.createSessionErr
+}
+
+var testClient = clientInfo{UserAgent: "Mozilla/5.0", IP: "203.0.113.1"}
+
+// testSession returns the session that newSessionMock creates for the given email and token with testClient.
+func testSession(email string, token string) *dao.Session {
+	return &dao.Session{ID: "id-" + token, Email: email, UserAgent: testClient.UserAgent, IP: testClient.IP}
+}
+
+func newSessionMock(email string, token string, userAgent string, ip string) *dao.Session {
+	return &dao.Session{ID: "id-" + token, Email: email, UserAgent: userAgent, IP: ip}
+}
+
+func sendVerificationMock(mockEmail string, mockErr error) sendVerificationFunc {
+	return func(email string) error {
+		if email != mockEmail {
+			return errors.NewServer("Incorrect input to sendVerification mock")
+		}
+		return mockErr
+	}
+}
+
 func generateTokenMock(mockToken string, mockErr error) generateTokenFunc {
 	return func() (string, error) {
 		return mockToken, mockErr
@@ -46,6 +76,7 @@ var signupTests = []struct {
 	tokenFunc  generateTokenFunc
 	cookieFunc generateCookieFunc
 	mockDB     *signupDBMock
+	verifyErr  error
 
 	// Expected output
 	wantCookie string
@@ -92,16 +123,35 @@ var signupTests = []struct {
 		password:   "12345678",
 		tokenFunc:  generateTokenMock("token", nil),
 		cookieFunc: generateCookieMock("test@example.com", "token", "cookie", nil),
-		mockDB:     &signupDBMock{"test@example.com", "12345678", "token", errors.NewClient("Email already exists")},
+		mockDB:     &signupDBMock{"test@example.com", "12345678", "token", errors.NewClient("Email already exists"), nil},
 		wantErr:    errors.Wrap(errors.NewClient("Email already exists"), "Failed to create user"),
 	},
+	{
+		name:       "CreateSessionError",
+		email:      "test@example.com",
+		password:   "12345678",
+		tokenFunc:  generateTokenMock("token", nil),
+		cookieFunc: generateCookieMock("test@example.com", "token", "cookie", nil),
+		mockDB:     &signupDBMock{"test@example.com", "12345678", "token", nil, errors.NewServer("DynamoDB failure")},
+		wantErr:    errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to create session"),
+	},
 	{
 		name:       "SuccessfulInvocation",
 		email:      "test@example.com",
 		password:   "12345678",
 		tokenFunc:  generateTokenMock("token", nil),
 		cookieFunc: generateCookieMock("test@example.com", "token", "cookie", nil),
-		mockDB:     &signupDBMock{"test@example.com", "12345678", "token", nil},
+		mockDB:     &signupDBMock{"test@example.com", "12345678", "token", nil, nil},
+		wantCookie: "cookie",
+	},
+	{
+		name:       "SendVerificationError",
+		email:      "test@example.com",
+		password:   "12345678",
+		tokenFunc:  generateTokenMock("token", nil),
+		cookieFunc: generateCookieMock("test@example.com", "token", "cookie", nil),
+		mockDB:     &signupDBMock{"test@example.com", "12345678", "token", nil, nil},
+		verifyErr:  errors.NewServer("Mail failure"),
 		wantCookie: "cookie",
 	},
 }
@@ -110,7 +160,7 @@ func TestSignup(t *testing.T) {
 	for _, test := range signupTests {
 		t.Run(test.name, func(t *testing.T) {
 			// Execute
-			cookie, err := signup(test.email, test.password, test.tokenFunc, test.cookieFunc, test.mockDB)
+			cookie, err := signup(test.email, test.password, testClient, test.tokenFunc, test.cookieFunc, newSessionMock, sendVerificationMock(test.email, test.verifyErr), test.mockDB)
 
 			// Verify
 			if cookie != test.wantCookie {
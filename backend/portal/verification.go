@@ -0,0 +1,74 @@
+package portal
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/mail"
+)
+
+// sendVerificationDatabase wraps the database methods required to send a verification email.
+type sendVerificationDatabase interface {
+	SetVerificationNonce(string, string) error
+}
+
+// verifyEmailDatabase wraps the database methods required to perform the verifyEmail action.
+type verifyEmailDatabase interface {
+	VerifyEmail(string, string) error
+}
+
+// verificationEmailBody is the body of the email sent to verify an email address. It is formatted with the link
+// that verifies the address.
+const verificationEmailBody = `Welcome to CRUD Creator!
+
+Please confirm your email address by opening the link below. The link expires in 48 hours.
+
+%s
+
+If you did not create an account, you can ignore this email.`
+
+// emailLink returns the link to the given page of the frontend with the given token, which is read from the page's
+// `token` query parameter. The frontend is located at the `APP_URL` environment variable.
+func emailLink(page string, token string) string {
+	return os.Getenv("APP_URL") + "/" + page + "?token=" + url.QueryEscape(token)
+}
+
+// sendVerification emails the user with the given email a link to verify their address. The nonce of the new token
+// is saved first, so any older verification links stop working.
+func sendVerification(email string, generateEmailToken generateEmailTokenFunc, mailer mail.Mailer, db sendVerificationDatabase) error {
+	token, nonce, err := generateEmailToken(auth.VerifyEmailPurpose, email, auth.EmailVerificationLifetime)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create verification token")
+	}
+
+	err = db.SetVerificationNonce(email, nonce)
+	if err != nil {
+		return errors.Wrap(err, "Failed to save verification token")
+	}
+
+	err = mailer.Send(&mail.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf(verificationEmailBody, emailLink("verify-email", token)),
+	})
+	return errors.Wrap(err, "Failed to send verification email")
+}
+
+// verifyEmail marks the email named by the given verification token as verified. The token must be valid, unexpired
+// and the newest verification token of the user, and it cannot be used again afterwards.
+func verifyEmail(token string, verifyEmailToken verifyEmailTokenFunc, db verifyEmailDatabase) error {
+	if token == "" {
+		return errors.NewClient("Parameter `token` is required")
+	}
+
+	claims, err := verifyEmailToken(token, auth.VerifyEmailPurpose)
+	if err != nil {
+		return errors.Wrap(err, "Invalid verification token")
+	}
+
+	err = db.VerifyEmail(claims.Email, claims.Nonce)
+	return errors.Wrap(err, "Failed to verify email")
+}
@@ -0,0 +1,155 @@
+package portal
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/mail"
+)
+
+func generateEmailTokenMock(wantPurpose auth.EmailTokenPurpose, wantLifetime time.Duration, mockErr error) generateEmailTokenFunc {
+	return func(purpose auth.EmailTokenPurpose, email string, lifetime time.Duration) (string, string, error) {
+		if purpose != wantPurpose || email != "test@example.com" || lifetime != wantLifetime {
+			return "", "", errors.NewServer("Incorrect input to GenerateEmailToken mock")
+		}
+		if mockErr != nil {
+			return "", "", mockErr
+		}
+		return "email+token", "nonce", nil
+	}
+}
+
+func verifyEmailTokenMock(wantPurpose auth.EmailTokenPurpose, mockErr error) verifyEmailTokenFunc {
+	return func(token string, purpose auth.EmailTokenPurpose) (*auth.EmailToken, error) {
+		if token != "email+token" || purpose != wantPurpose {
+			return nil, errors.NewServer("Incorrect input to VerifyEmailToken mock")
+		}
+		if mockErr != nil {
+			return nil, mockErr
+		}
+		return &auth.EmailToken{Purpose: purpose, Email: "test@example.com", Nonce: "nonce"}, nil
+	}
+}
+
+// nonceDBMock expects to be called with test@example.com and the nonce `nonce`.
+type nonceDBMock struct {
+	err error
+}
+
+func (mock *nonceDBMock) SetVerificationNonce(email string, nonce string) error {
+	if email != "test@example.com" || nonce != "nonce" {
+		return errors.NewServer("Incorrect input to SetVerificationNonce mock")
+	}
+	return mock.err
+}
+
+func (mock *nonceDBMock) VerifyEmail(email string, nonce string) error {
+	if email != "test@example.com" || nonce != "nonce" {
+		return errors.NewServer("Incorrect input to VerifyEmail mock")
+	}
+	return mock.err
+}
+
+var sendVerificationTests = []struct {
+	name     string
+	tokenErr error
+	dbErr    error
+	mailErr  error
+
+	// Expected output
+	wantMessages []*mail.Message
+	wantErr      error
+}{
+	{
+		name:     "GenerateTokenError",
+		tokenErr: errors.NewServer("Key failure"),
+		wantErr:  errors.Wrap(errors.NewServer("Key failure"), "Failed to create verification token"),
+	},
+	{
+		name:    "DatabaseError",
+		dbErr:   errors.NewServer("DynamoDB failure"),
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to save verification token"),
+	},
+	{
+		name:    "MailError",
+		mailErr: errors.NewServer("SMTP failure"),
+		wantErr: errors.Wrap(errors.NewServer("SMTP failure"), "Failed to send verification email"),
+	},
+	{
+		name: "SuccessfulInvocation",
+		wantMessages: []*mail.Message{{
+			To:      "test@example.com",
+			Subject: "Verify your email address",
+			Body: "Welcome to CRUD Creator!\n\nPlease confirm your email address by opening the link below. The link expires in 48 hours.\n\n" +
+				"https://app.example.com/verify-email?token=email%2Btoken\n\nIf you did not create an account, you can ignore this email.",
+		}},
+	},
+}
+
+func TestSendVerification(t *testing.T) {
+	os.Setenv("APP_URL", "https://app.example.com")
+	defer os.Unsetenv("APP_URL")
+
+	for _, test := range sendVerificationTests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &mail.FakeMailer{Err: test.mailErr}
+			generateEmailToken := generateEmailTokenMock(auth.VerifyEmailPurpose, auth.EmailVerificationLifetime, test.tokenErr)
+
+			err := sendVerification("test@example.com", generateEmailToken, fake, &nonceDBMock{test.dbErr})
+
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+			if messages := fake.Messages(); !reflect.DeepEqual(messages, test.wantMessages) {
+				t.Errorf("Got messages %v; want %v", messages, test.wantMessages)
+			}
+		})
+	}
+}
+
+var verifyEmailTests = []struct {
+	name      string
+	token     string
+	verifyErr error
+	dbErr     error
+	wantErr   error
+}{
+	{
+		name:    "MissingToken",
+		wantErr: errors.NewClient("Parameter `token` is required"),
+	},
+	{
+		name:      "InvalidToken",
+		token:     "email+token",
+		verifyErr: errors.NewClient("Invalid or expired token"),
+		wantErr:   errors.Wrap(errors.NewClient("Invalid or expired token"), "Invalid verification token"),
+	},
+	{
+		name:    "UsedToken",
+		token:   "email+token",
+		dbErr:   errors.NewClient("Token is invalid or has already been used"),
+		wantErr: errors.Wrap(errors.NewClient("Token is invalid or has already been used"), "Failed to verify email"),
+	},
+	{
+		name:  "SuccessfulInvocation",
+		token: "email+token",
+	},
+}
+
+func TestVerifyEmail(t *testing.T) {
+	for _, test := range verifyEmailTests {
+		t.Run(test.name, func(t *testing.T) {
+			verifyEmailToken := verifyEmailTokenMock(auth.VerifyEmailPurpose, test.verifyErr)
+
+			err := verifyEmail(test.token, verifyEmailToken, &nonceDBMock{test.dbErr})
+
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package putendpoint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/endpoints"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// putEndpointDatabase wraps the database methods required to perform the putEndpoint action.
+// This allows for dependency injection of the database.
+type putEndpointDatabase interface {
+	auth.CredentialStore
+	GetProject(string, string) (*dao.Project, error)
+	UpdateEndpoint(string, string, *dao.Endpoint) error
+}
+
+var isAlpha = regexp.MustCompile(`^[A-Za-z]+$`).MatchString
+var isEndpointID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`).MatchString
+var isParameter = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`).MatchString
+
+// lowerFirst returns the given name with its first letter converted to lowercase.
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[0:1]) + name[1:]
+}
+
+// validFilter checks that the given filter names an attribute and a valid query parameter. The Attribute
+// field is converted to an attribute code name, and the Parameter field defaults to that code name.
+func validFilter(filter *dao.Filter) error {
+	if filter == nil {
+		return errors.NewClient("Filter cannot be nil")
+	}
+	if filter.Attribute == "" {
+		return errors.NewClient("Filter must have an `attribute` field")
+	}
+
+	filter.Attribute = lowerFirst(filter.Attribute)
+	if filter.Parameter == "" {
+		filter.Parameter = filter.Attribute
+	}
+	if !isParameter(filter.Parameter) {
+		return errors.NewClient(fmt.Sprintf("Query parameter `%s` must start with a letter and contain only letters, digits and underscores", filter.Parameter))
+	}
+	return nil
+}
+
+// validEndpoint checks the fields of the given endpoint that do not depend on the rest of the project. If the
+// endpoint is valid, its CodeName field is set, its Method field is converted to uppercase and its Object field
+// is converted to an object ID. If the endpoint is invalid, an error is returned. An endpoint is invalid if:
+//		- Its Name field has length 0
+//		- Its Name field contains non-alphabetical characters
+//		- Any of its filters are invalid
+func validEndpoint(endpoint *dao.Endpoint) error {
+	if len(endpoint.Name) == 0 {
+		return errors.NewClient("Endpoint must have a `name` field")
+	}
+
+	if !isAlpha(endpoint.Name) {
+		return errors.NewClient(fmt.Sprintf("Endpoint name `%s` contains non-alphabetical characters", endpoint.Name))
+	}
+
+	for _, filter := range endpoint.Filters {
+		err := validFilter(filter)
+		if err != nil {
+			return errors.Wrap(err, "Endpoint must have valid filters")
+		}
+	}
+
+	endpoint.CodeName = lowerFirst(endpoint.Name)
+	endpoint.Method = strings.ToUpper(endpoint.Method)
+	endpoint.Object = strings.ToLower(endpoint.Object)
+	return nil
+}
+
+// putEndpoint either creates or replaces the endpoint with the given ID within the given project. The endpoint
+// must operate on an object of the project, and it cannot share its route or its name with another endpoint
+// of the project.
+func putEndpoint(cookie string, projectID string, endpointID string, endpoint *dao.Endpoint, verifyCookie auth.VerifyCookieFunc, db putEndpointDatabase) error {
+	if cookie == "" || projectID == "" || endpointID == "" || endpoint == nil {
+		return errors.NewClient("Parameters `cookie`, `projectId`, `endpointId` and `endpoint` are required")
+	}
+
+	if !isEndpointID(endpointID) {
+		return errors.NewClient(fmt.Sprintf("Endpoint ID `%s` must contain only letters, digits, underscores and hyphens", endpointID))
+	}
+	endpoint.ID = endpointID
+
+	err := validEndpoint(endpoint)
+	if err != nil {
+		return errors.Wrap(err, "Endpoint is invalid")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return errors.NewClient("Not authenticated")
+	}
+
+	project, err := db.GetProject(email, projectID)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get project")
+	}
+
+	err = endpoints.Validate(project.Objects, endpoint)
+	if err != nil {
+		return errors.Wrap(err, "Endpoint is invalid")
+	}
+
+	err = endpoints.ValidateConflicts(project.Endpoints, endpoint)
+	if err != nil {
+		return errors.Wrap(err, "Endpoint conflicts with another endpoint")
+	}
+
+	err = db.UpdateEndpoint(email, projectID, endpoint)
+	return errors.Wrap(err, "Failed database call to put endpoint")
+}
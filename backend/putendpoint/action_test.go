@@ -0,0 +1,257 @@
+package putendpoint
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email     string
+	projectID string
+	endpoint  *dao.Endpoint
+	err       error
+	project   *dao.Project
+	getErr    error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetProject(email string, projectID string) (*dao.Project, error) {
+	if email != mock.email || projectID != mock.projectID {
+		return nil, errors.NewServer("Incorrect input to GetProject mock.")
+	}
+	return mock.project, mock.getErr
+}
+
+func (mock *databaseMock) UpdateEndpoint(email string, projectID string, endpoint *dao.Endpoint) error {
+	if email != mock.email || projectID != mock.projectID || !reflect.DeepEqual(endpoint, mock.endpoint) {
+		return errors.NewServer("Incorrect input to UpdateEndpoint mock.")
+	}
+	return mock.err
+}
+
+// project contains a book object and an endpoint that lists books.
+var project = &dao.Project{
+	Objects: map[string]*dao.Object{
+		"book": {ID: "book", Name: "Book", CodeName: "Book", Attributes: []*dao.Attribute{
+			{Name: "title", CodeName: "title", Type: "Text"},
+			{Name: "pages", CodeName: "pages", Type: "Integer"},
+		}},
+	},
+	Endpoints: map[string]*dao.Endpoint{
+		"listBooks": {ID: "listBooks", Name: "ListBooks", CodeName: "listBooks", Method: "GET", Path: "/books", Object: "book", Operation: "list"},
+	},
+}
+
+// searchBooks returns the request body of an endpoint that searches books by title and minimum page count.
+func searchBooks() *dao.Endpoint {
+	return &dao.Endpoint{
+		Name:      "SearchBooks",
+		Method:    "get",
+		Path:      "/books/search",
+		Object:    "Book",
+		Operation: "list",
+		Filters: []*dao.Filter{
+			{Attribute: "Title", Operator: "contains", Parameter: "q"},
+			{Attribute: "pages", Operator: "gte", Parameter: "minPages"},
+		},
+		AuthRequired: true,
+	}
+}
+
+// normalizedSearchBooks returns the endpoint returned by searchBooks once it is validated.
+func normalizedSearchBooks() *dao.Endpoint {
+	endpoint := searchBooks()
+	endpoint.ID = "searchBooks"
+	endpoint.CodeName = "searchBooks"
+	endpoint.Method = "GET"
+	endpoint.Object = "book"
+	endpoint.Filters[0].Attribute = "title"
+	return endpoint
+}
+
+var putEndpointTests = []struct {
+	name string
+
+	// Input
+	cookie     string
+	projectID  string
+	endpointID string
+	endpoint   *dao.Endpoint
+
+	// Mock data
+	db        *databaseMock
+	email     string
+	verifyErr error
+
+	// Expected output
+	wantErr error
+}{
+	{
+		name:    "MissingParameters",
+		cookie:  "cookie",
+		wantErr: errors.NewClient("Parameters `cookie`, `projectId`, `endpointId` and `endpoint` are required"),
+	},
+	{
+		name:       "InvalidEndpointID",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "search books",
+		endpoint:   searchBooks(),
+		wantErr:    errors.NewClient("Endpoint ID `search books` must contain only letters, digits, underscores and hyphens"),
+	},
+	{
+		name:       "MissingName",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "searchBooks",
+		endpoint:   &dao.Endpoint{},
+		wantErr:    errors.Wrap(errors.NewClient("Endpoint must have a `name` field"), "Endpoint is invalid"),
+	},
+	{
+		name:       "NonAlphabeticalName",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "searchBooks",
+		endpoint:   &dao.Endpoint{Name: "search_books"},
+		wantErr:    errors.Wrap(errors.NewClient("Endpoint name `search_books` contains non-alphabetical characters"), "Endpoint is invalid"),
+	},
+	{
+		name:       "NilFilter",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "searchBooks",
+		endpoint:   &dao.Endpoint{Name: "SearchBooks", Filters: []*dao.Filter{nil}},
+		wantErr:    errors.Wrap(errors.Wrap(errors.NewClient("Filter cannot be nil"), "Endpoint must have valid filters"), "Endpoint is invalid"),
+	},
+	{
+		name:       "FilterMissingAttribute",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "searchBooks",
+		endpoint:   &dao.Endpoint{Name: "SearchBooks", Filters: []*dao.Filter{{Operator: "eq"}}},
+		wantErr:    errors.Wrap(errors.Wrap(errors.NewClient("Filter must have an `attribute` field"), "Endpoint must have valid filters"), "Endpoint is invalid"),
+	},
+	{
+		name:       "InvalidParameter",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "searchBooks",
+		endpoint:   &dao.Endpoint{Name: "SearchBooks", Filters: []*dao.Filter{{Attribute: "title", Operator: "eq", Parameter: "book-title"}}},
+		wantErr: errors.Wrap(errors.Wrap(errors.NewClient("Query parameter `book-title` must start with a letter and contain only letters, digits and underscores"),
+			"Endpoint must have valid filters"), "Endpoint is invalid"),
+	},
+	{
+		name:       "InvalidCookie",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "searchBooks",
+		endpoint:   searchBooks(),
+		verifyErr:  errors.NewClient("Invalid cookie"),
+		wantErr:    errors.NewClient("Not authenticated"),
+	},
+	{
+		name:       "GetProjectFailure",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "searchBooks",
+		endpoint:   searchBooks(),
+		db:         &databaseMock{email: "test@example.com", projectID: "projectID", getErr: errors.NewClient("Project 'projectID' not found")},
+		email:      "test@example.com",
+		wantErr:    errors.Wrap(errors.NewClient("Project 'projectID' not found"), "Failed to get project"),
+	},
+	{
+		name:       "MissingObject",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "listAuthors",
+		endpoint:   &dao.Endpoint{Name: "ListAuthors", Method: "GET", Path: "/authors", Object: "Author", Operation: "list"},
+		db:         &databaseMock{email: "test@example.com", projectID: "projectID", project: project},
+		email:      "test@example.com",
+		wantErr:    errors.Wrap(errors.NewClient("Object `author` does not exist"), "Endpoint is invalid"),
+	},
+	{
+		name:       "RouteConflict",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "allBooks",
+		endpoint:   &dao.Endpoint{Name: "AllBooks", Method: "GET", Path: "/books", Object: "book", Operation: "list"},
+		db:         &databaseMock{email: "test@example.com", projectID: "projectID", project: project},
+		email:      "test@example.com",
+		wantErr:    errors.Wrap(errors.NewClient("Endpoint `listBooks` already uses route `GET /books`"), "Endpoint conflicts with another endpoint"),
+	},
+	{
+		name:       "DatabaseFailure",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "searchBooks",
+		endpoint:   searchBooks(),
+		db:         &databaseMock{email: "test@example.com", projectID: "projectID", project: project, endpoint: normalizedSearchBooks(), err: errors.NewServer("Database failure")},
+		email:      "test@example.com",
+		wantErr:    errors.Wrap(errors.NewServer("Database failure"), "Failed database call to put endpoint"),
+	},
+	{
+		name:       "SuccessfulInvocation",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "searchBooks",
+		endpoint:   searchBooks(),
+		db:         &databaseMock{email: "test@example.com", projectID: "projectID", project: project, endpoint: normalizedSearchBooks()},
+		email:      "test@example.com",
+	},
+	{
+		name:       "ReplaceEndpoint",
+		cookie:     "cookie",
+		projectID:  "projectID",
+		endpointID: "listBooks",
+		endpoint:   &dao.Endpoint{Name: "ListBooks", Method: "GET", Path: "/books", Object: "book", Operation: "list"},
+		db: &databaseMock{email: "test@example.com", projectID: "projectID", project: project,
+			endpoint: &dao.Endpoint{ID: "listBooks", Name: "ListBooks", CodeName: "listBooks", Method: "GET", Path: "/books", Object: "book", Operation: "list"}},
+		email: "test@example.com",
+	},
+}
+
+func TestPutEndpoint(t *testing.T) {
+	for _, test := range putEndpointTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock("cookie", test.db, test.email, test.verifyErr)
+
+			// Execute
+			err := putEndpoint(test.cookie, test.projectID, test.endpointID, test.endpoint, verifyCookie, test.db)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
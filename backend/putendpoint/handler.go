@@ -0,0 +1,48 @@
+// Package putendpoint handles requests to the PUT /projects/{pid}/endpoints/{eid} REST API endpoint.
+package putendpoint
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// putEndpointResponse contains the fields returned in the API JSON response body.
+type putEndpointResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+func (response *putEndpointResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// putEndpointFunc points to the function used to perform the putEndpoint action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var putEndpointFunc = putEndpoint
+
+// HandlePutEndpoint parses the request from AWS APIGateway and passes it to the putEndpoint action. The
+// request must contain a valid cookie or access token, `pid` and `eid` path parameters, and an endpoint definition
+// in the body. If the request succeeds, the response will have a 200 status and an empty body. If the request
+// fails, the response will have either a 400 or 500 status and an `error` field in the body.
+func HandlePutEndpoint(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	cookie := auth.ExtractCredential(request.Headers)
+	projectID := request.PathParameters["pid"]
+	endpointID := request.PathParameters["eid"]
+	var endpoint *dao.Endpoint
+	json.Unmarshal([]byte(request.Body), &endpoint)
+	log.Info("Got request parameters. Cookie:", cookie, "projectID:", projectID, "endpointID:", endpointID)
+
+	// Perform the action
+	err := putEndpointFunc(cookie, projectID, endpointID, endpoint, auth.Verifier(), dao.Dynamo)
+	log.Error(err)
+
+	// Handle the output
+	return http.GatewayResponse(&putEndpointResponse{}, "", err), nil
+}
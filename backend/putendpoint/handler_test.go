@@ -0,0 +1,93 @@
+package putendpoint
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type putEndpointMockFunc func(string, string, string, *dao.Endpoint, auth.VerifyCookieFunc, putEndpointDatabase) error
+
+func putEndpointMock(wantCookie string, wantPID string, wantEID string, wantEndpoint *dao.Endpoint, err error) putEndpointMockFunc {
+	return func(cookie string, pid string, eid string, endpoint *dao.Endpoint, _ auth.VerifyCookieFunc, _ putEndpointDatabase) error {
+		if cookie != wantCookie || pid != wantPID || eid != wantEID || !reflect.DeepEqual(endpoint, wantEndpoint) {
+			return errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return err
+	}
+}
+
+func handlerRequest(cookie string, pid string, eid string, endpoint *dao.Endpoint) events.APIGatewayProxyRequest {
+	parameters := map[string]string{
+		"pid": pid,
+		"eid": eid,
+	}
+	headers := map[string]string{
+		"Cookie": cookie,
+	}
+	json, _ := json.Marshal(endpoint)
+	return events.APIGatewayProxyRequest{PathParameters: parameters, Headers: headers, Body: string(json)}
+}
+
+func handlerResponse(err string, status int) events.APIGatewayProxyResponse {
+	json, _ := json.Marshal(&putEndpointResponse{Error: err})
+	return events.APIGatewayProxyResponse{
+		Body:       string(json),
+		Headers:    map[string]string{"Access-Control-Allow-Origin": os.Getenv("CORS_ORIGIN"), "Access-Control-Allow-Credentials": "true"},
+		StatusCode: status,
+	}
+}
+
+var listBooks = &dao.Endpoint{Name: "ListBooks", Method: "GET", Path: "/books", Object: "book", Operation: "list"}
+
+var handlePutEndpointTests = []struct {
+	name string
+
+	request         events.APIGatewayProxyRequest
+	putEndpointMock putEndpointMockFunc
+
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:            "PutEndpointFailure",
+		request:         handlerRequest("session=cookievalue", "projectId", "listBooks", nil),
+		putEndpointMock: putEndpointMock("cookievalue", "projectId", "listBooks", nil, errors.NewClient("Parameters `cookie`, `projectId`, `endpointId` and `endpoint` are required")),
+		wantResponse:    handlerResponse("Parameters `cookie`, `projectId`, `endpointId` and `endpoint` are required", 400),
+	},
+	{
+		name:            "SuccessfulInvocation",
+		request:         handlerRequest("session=cookievalue", "projectId", "listBooks", listBooks),
+		putEndpointMock: putEndpointMock("cookievalue", "projectId", "listBooks", listBooks, nil),
+		wantResponse:    handlerResponse("", 200),
+	},
+}
+
+func TestHandlePutEndpoint(t *testing.T) {
+	for _, test := range handlePutEndpointTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			putEndpointFunc = test.putEndpointMock
+			defer func() {
+				putEndpointFunc = putEndpoint
+			}()
+
+			// Execute
+			response, err := HandlePutEndpoint(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -3,29 +3,34 @@ package putobject
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/endpoints"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/types"
 )
 
-// verifyCookieFunc wraps the function type used to check the validity of the user's cookie.
-// This allows for dependency injection of the function.
-type verifyCookieFunc func(string, auth.UserGetter) (string, error)
-
 // putObjectDatabase wraps the database methods required to perform the putObject action.
 // This allows for dependency injection of the database.
 type putObjectDatabase interface {
-	auth.UserGetter
-	UpdateObject(string, string, *dao.Object, string) error
+	auth.CredentialStore
+	GetProject(string, string) (*dao.Project, error)
+	UpdateObject(string, string, *dao.Object, string, int) error
 }
 
 // validAttribute checks that the given attribute has valid values. If the attribute is valid, its
-// CodeName field is set. If the attribute is invalid, an error is returned. An attribute is invalid if:
+// CodeName field is set. The Target and Through fields are converted to object IDs and the Via field
+// is converted to an attribute code name. If the attribute is invalid, an error is returned. An attribute
+// is invalid if:
 //		- Its Name field has length 0
 //		- Its Name field contains non-alphabetical characters
-// 		- Its Type field is neither `Text` nor `Integer`
+// 		- Its Type field is not in the types catalog
+// 		- It lists allowed values when its type does not accept them, or vice versa
+// 		- It names related objects when its type does not accept them, or vice versa
+// 		- It has constraints that its type does not accept or that are inconsistent, such as min > max
 func validAttribute(attribute *dao.Attribute) error {
 	isAlpha := regexp.MustCompile(`^[A-Za-z]+$`).MatchString
 
@@ -41,13 +46,17 @@ func validAttribute(attribute *dao.Attribute) error {
 		return errors.NewClient(fmt.Sprintf("Attribute name `%s` contains non-alphabetical characters", attribute.Name))
 	}
 
-	switch attribute.Type {
-	case "Text", "Integer":
-	default:
-		return errors.NewClient(fmt.Sprintf("Attribute type `%s` is not supported", attribute.Type))
+	_, err := types.Validate(attribute)
+	if err != nil {
+		return err
 	}
 
 	attribute.CodeName = fmt.Sprintf("%s%s", strings.ToLower(attribute.Name[0:1]), attribute.Name[1:])
+	attribute.Target = strings.ToLower(attribute.Target)
+	attribute.Through = strings.ToLower(attribute.Through)
+	if attribute.Via != "" {
+		attribute.Via = fmt.Sprintf("%s%s", strings.ToLower(attribute.Via[0:1]), attribute.Via[1:])
+	}
 	return nil
 }
 
@@ -78,11 +87,76 @@ func validObject(object *dao.Object) error {
 	return nil
 }
 
+// replaceObject returns the objects of the given project keyed by ID once the given object replaces the object
+// with the given original ID.
+func replaceObject(project *dao.Project, object *dao.Object, originalID string) map[string]*dao.Object {
+	objects := make(map[string]*dao.Object, len(project.Objects)+1)
+	for id, other := range project.Objects {
+		if id != originalID {
+			objects[id] = other
+		}
+	}
+	objects[object.ID] = object
+	return objects
+}
+
+// validRelationships checks that the relationships of the given object, and of every other object that
+// references it, are valid once the object replaces the object with the given original ID in the given project.
+// This prevents renaming an object that other objects reference and changing an attribute that another
+// object's relationship names in its `via` field.
+func validRelationships(project *dao.Project, object *dao.Object, originalID string) error {
+	objects := replaceObject(project, object, originalID)
+	err := types.ValidateRelationships(objects, object)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		other := objects[id]
+		if other == nil || other == object {
+			continue
+		}
+		if types.References(other, object.ID) == nil && (originalID == "" || types.References(other, originalID) == nil) {
+			continue
+		}
+		err = types.ValidateRelationships(objects, other)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Object `%s` references this object", other.Name))
+		}
+	}
+	return nil
+}
+
+// validEndpoints checks that the endpoints of the given project that operate on the given object, or on the
+// object with the given original ID, remain valid once the object replaces the object with the original ID.
+// This prevents renaming an object that endpoints operate on and removing an attribute that a filter uses.
+func validEndpoints(project *dao.Project, object *dao.Object, originalID string) error {
+	objects := replaceObject(project, object, originalID)
+	for _, endpoint := range endpoints.Sorted(project.Endpoints) {
+		if endpoint.Object != object.ID && (originalID == "" || endpoint.Object != originalID) {
+			continue
+		}
+		err := endpoints.Validate(objects, endpoint)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Endpoint `%s` uses this object", endpoint.ID))
+		}
+	}
+	return nil
+}
+
 // putObject either creates or replaces the given object within the given project. The object's ID is returned.
 // If an error occurs, it is returned. The object's `ID` field is set to the lowercase string of the object's name.
 // If an object with that ID value already exists in the project, the existing object will be replaced. If no object
-// with that ID value exists, then the object will be created.
-func putObject(cookie string, projectID string, object *dao.Object, verifyCookie verifyCookieFunc, db putObjectDatabase) (string, error) {
+// with that ID value exists, then the object will be created. Relationship attributes must reference objects in
+// the same project, and the endpoints that operate on the object must remain valid. If the project changes while
+// the object is being put, a client error asking the user to try again is returned.
+func putObject(cookie string, projectID string, object *dao.Object, verifyCookie auth.VerifyCookieFunc, db putObjectDatabase) (string, error) {
 	if cookie == "" || projectID == "" || object == nil {
 		return "", errors.NewClient("Parameters `cookie`, `projectId` and `object` are required")
 	}
@@ -100,7 +174,22 @@ func putObject(cookie string, projectID string, object *dao.Object, verifyCookie
 		return "", errors.NewClient("Not authenticated")
 	}
 
-	err = db.UpdateObject(email, projectID, object, originalID)
+	project, err := db.GetProject(email, projectID)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to get project")
+	}
+
+	err = validRelationships(project, object, originalID)
+	if err != nil {
+		return "", errors.Wrap(err, "Object has invalid relationships")
+	}
+
+	err = validEndpoints(project, object, originalID)
+	if err != nil {
+		return "", errors.Wrap(err, "Object would invalidate an endpoint")
+	}
+
+	err = db.UpdateObject(email, projectID, object, originalID, project.Version)
 	if err != nil {
 		return "", errors.Wrap(err, "Failed database call to put object")
 	}
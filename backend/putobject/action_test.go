@@ -4,14 +4,15 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jackstenglein/rest_api_creator/backend/auth"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
 
-func verifyCookieMock(mockCookie string, mockDB auth.UserGetter, mockEmail string, mockErr error) verifyCookieFunc {
-	return func(cookie string, db auth.UserGetter) (string, error) {
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
 		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
 			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
 		}
@@ -25,20 +26,69 @@ type databaseMock struct {
 	object     *dao.Object
 	originalID string
 	err        error
+	project    *dao.Project
+	getErr     error
+	version    int
 }
 
-func (mock *databaseMock) GetUserInfo(email string) (*dao.User, error) {
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
 	return nil, nil
 }
 
-func (mock *databaseMock) UpdateObject(email string, projectID string, object *dao.Object, originalID string) error {
-	if email != mock.email || projectID != mock.projectID || !reflect.DeepEqual(object, mock.object) || originalID != mock.originalID {
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetProject(email string, projectID string) (*dao.Project, error) {
+	if email != mock.email || projectID != mock.projectID {
+		return nil, errors.NewServer("Incorrect input to GetProject mock.")
+	}
+	return mock.project, mock.getErr
+}
+
+func (mock *databaseMock) UpdateObject(email string, projectID string, object *dao.Object, originalID string, version int) error {
+	if email != mock.email || projectID != mock.projectID || !reflect.DeepEqual(object, mock.object) || originalID != mock.originalID || version != mock.version {
 		fmt.Printf("Got %v; want %v", object, mock.object)
 		return errors.NewServer("Incorrect input to UpdateObject mock.")
 	}
 	return mock.err
 }
 
+var minPages = 1.0
+
+// relationshipProject contains an author and a book that belongs to the author.
+var relationshipProject = &dao.Project{
+	Version: 2,
+	Objects: map[string]*dao.Object{
+		"author": {ID: "author", Name: "Author", CodeName: "Author"},
+		"book": {ID: "book", Name: "Book", CodeName: "Book", Attributes: []*dao.Attribute{
+			{Name: "title", CodeName: "title", Type: "Text"},
+			{Name: "author", CodeName: "author", Type: "BelongsTo", Target: "author"},
+		}},
+	},
+}
+
+// endpointProject contains a book and an endpoint that lists books filtered by title.
+var endpointProject = &dao.Project{
+	Objects: map[string]*dao.Object{
+		"book": {ID: "book", Name: "Book", CodeName: "Book", Attributes: []*dao.Attribute{
+			{Name: "title", CodeName: "title", Type: "Text"},
+		}},
+	},
+	Endpoints: map[string]*dao.Endpoint{
+		"listBooks": {ID: "listBooks", Name: "ListBooks", CodeName: "listBooks", Method: "GET", Path: "/books", Object: "book", Operation: "list",
+			Filters: []*dao.Filter{{Attribute: "title", Operator: "eq", Parameter: "title"}}},
+	},
+}
+
 var putObjectTests = []struct {
 	name string
 
@@ -135,7 +185,56 @@ var putObjectTests = []struct {
 				{Name: "ValidName"},
 			},
 		},
-		wantErr: errors.Wrap(errors.Wrap(errors.NewClient("Attribute type `` is not supported"), "Object must have valid attributes"), "Object is invalid"),
+		wantErr: errors.Wrap(errors.Wrap(errors.NewClient("Attribute type `` is not supported. Supported types are: "+
+			"BelongsTo, Boolean, Date, DateTime, Decimal, Email, Enum, Float, HasMany, Integer, JSON, ManyToMany, Text, URL, UUID"), "Object must have valid attributes"), "Object is invalid"),
+	},
+	{
+		name:      "EnumWithoutValues",
+		cookie:    "cookie",
+		projectID: "projectId",
+		object: &dao.Object{
+			Name: "NewObject",
+			Attributes: []*dao.Attribute{
+				{Name: "status", Type: "Enum"},
+			},
+		},
+		wantErr: errors.Wrap(errors.Wrap(errors.NewClient("Attribute type `Enum` requires a list of allowed values"), "Object must have valid attributes"), "Object is invalid"),
+	},
+	{
+		name:      "ValuesOnTextAttribute",
+		cookie:    "cookie",
+		projectID: "projectId",
+		object: &dao.Object{
+			Name: "NewObject",
+			Attributes: []*dao.Attribute{
+				{Name: "status", Type: "Text", Values: []string{"draft"}},
+			},
+		},
+		wantErr: errors.Wrap(errors.Wrap(errors.NewClient("Attribute type `Text` does not accept allowed values"), "Object must have valid attributes"), "Object is invalid"),
+	},
+	{
+		name:      "RelationshipWithoutVia",
+		cookie:    "cookie",
+		projectID: "projectId",
+		object: &dao.Object{
+			Name: "Author",
+			Attributes: []*dao.Attribute{
+				{Name: "books", Type: "HasMany", Target: "book"},
+			},
+		},
+		wantErr: errors.Wrap(errors.Wrap(errors.NewClient("Attribute type `HasMany` requires a `via` field"), "Object must have valid attributes"), "Object is invalid"),
+	},
+	{
+		name:      "InconsistentConstraints",
+		cookie:    "cookie",
+		projectID: "projectId",
+		object: &dao.Object{
+			Name: "Book",
+			Attributes: []*dao.Attribute{
+				{Name: "pages", Type: "Integer", Min: &minPages, Max: &minPages, Default: "0"},
+			},
+		},
+		wantErr: errors.Wrap(errors.Wrap(errors.NewClient("Default value `0` is less than field `min`"), "Object must have valid attributes"), "Object is invalid"),
 	},
 	{
 		name:      "InvalidCookie",
@@ -147,15 +246,114 @@ var putObjectTests = []struct {
 		verifyErr: errors.NewClient("Invalid cookie"),
 		wantErr:   errors.NewClient("Not authenticated"),
 	},
+	{
+		name:      "GetProjectError",
+		cookie:    "cookie",
+		projectID: "projectId",
+		object:    &dao.Object{Name: "name", Description: "desc"},
+		db:        &databaseMock{email: "test@example.com", projectID: "projectId", getErr: errors.NewClient("Project 'projectId' not found")},
+		email:     "test@example.com",
+		wantErr:   errors.Wrap(errors.NewClient("Project 'projectId' not found"), "Failed to get project"),
+	},
+	{
+		name:      "TargetDoesNotExist",
+		cookie:    "cookie",
+		projectID: "projectId",
+		object: &dao.Object{
+			Name:       "Book",
+			Attributes: []*dao.Attribute{{Name: "author", Type: "BelongsTo", Target: "Author"}},
+		},
+		db:      &databaseMock{email: "test@example.com", projectID: "projectId", project: &dao.Project{}},
+		email:   "test@example.com",
+		wantErr: errors.Wrap(errors.NewClient("Target object `author` of attribute `author` does not exist"), "Object has invalid relationships"),
+	},
+	{
+		name:      "ViaIsNotBelongsTo",
+		cookie:    "cookie",
+		projectID: "projectId",
+		object: &dao.Object{
+			Name:       "Author",
+			Attributes: []*dao.Attribute{{Name: "books", Type: "HasMany", Target: "book", Via: "title"}},
+		},
+		db:    &databaseMock{email: "test@example.com", projectID: "projectId", project: relationshipProject},
+		email: "test@example.com",
+		wantErr: errors.Wrap(errors.NewClient("Via attribute `title` of attribute `books` must be a BelongsTo attribute of object `book` that references object `author`"),
+			"Object has invalid relationships"),
+	},
+	{
+		name:      "RenameReferencedObject",
+		cookie:    "cookie",
+		projectID: "projectId",
+		object:    &dao.Object{ID: "author", Name: "Writer"},
+		db:        &databaseMock{email: "test@example.com", projectID: "projectId", project: relationshipProject},
+		email:     "test@example.com",
+		wantErr: errors.Wrap(errors.Wrap(errors.NewClient("Target object `author` of attribute `author` does not exist"), "Object `Book` references this object"),
+			"Object has invalid relationships"),
+	},
+	{
+		name:      "RenameObjectUsedByEndpoint",
+		cookie:    "cookie",
+		projectID: "projectId",
+		object:    &dao.Object{ID: "book", Name: "Novel", Attributes: []*dao.Attribute{{Name: "title", Type: "Text"}}},
+		db:        &databaseMock{email: "test@example.com", projectID: "projectId", project: endpointProject},
+		email:     "test@example.com",
+		wantErr: errors.Wrap(errors.Wrap(errors.NewClient("Object `book` does not exist"), "Endpoint `listBooks` uses this object"),
+			"Object would invalidate an endpoint"),
+	},
+	{
+		name:      "RemoveFilteredAttribute",
+		cookie:    "cookie",
+		projectID: "projectId",
+		object:    &dao.Object{ID: "book", Name: "Book", Attributes: []*dao.Attribute{{Name: "pages", Type: "Integer"}}},
+		db:        &databaseMock{email: "test@example.com", projectID: "projectId", project: endpointProject},
+		email:     "test@example.com",
+		wantErr: errors.Wrap(errors.Wrap(errors.NewClient("Object `Book` does not have attribute `title`"), "Endpoint `listBooks` uses this object"),
+			"Object would invalidate an endpoint"),
+	},
+	{
+		name:      "SuccessfulRelationships",
+		cookie:    "cookie",
+		projectID: "projectId",
+		object: &dao.Object{
+			ID:         "author",
+			Name:       "Author",
+			Attributes: []*dao.Attribute{{Name: "books", Type: "HasMany", Target: "Book", Via: "Author"}},
+		},
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "projectId",
+			object: &dao.Object{
+				ID:         "author",
+				Name:       "Author",
+				CodeName:   "Author",
+				Attributes: []*dao.Attribute{{Name: "books", CodeName: "books", Type: "HasMany", Target: "book", Via: "author"}},
+			},
+			originalID: "author",
+			project:    relationshipProject,
+			version:    2,
+		},
+		email:  "test@example.com",
+		wantID: "author",
+	},
 	{
 		name:      "DatabaseError",
 		cookie:    "cookie",
 		projectID: "projectId",
 		object:    &dao.Object{Name: "name", Description: "desc"},
-		db:        &databaseMock{"test@example.com", "projectId", &dao.Object{ID: "name", Name: "name", CodeName: "Name", Description: "desc"}, "", errors.NewServer("DDB failure")},
+		db:        &databaseMock{"test@example.com", "projectId", &dao.Object{ID: "name", Name: "name", CodeName: "Name", Description: "desc"}, "", errors.NewServer("DDB failure"), &dao.Project{}, nil, 0},
 		email:     "test@example.com",
 		wantErr:   errors.Wrap(errors.NewServer("DDB failure"), "Failed database call to put object"),
 	},
+	{
+		name:      "ProjectChanged",
+		cookie:    "cookie",
+		projectID: "projectId",
+		object:    &dao.Object{Name: "name", Description: "desc"},
+		db: &databaseMock{"test@example.com", "projectId", &dao.Object{ID: "name", Name: "name", CodeName: "Name", Description: "desc"}, "",
+			errors.NewClient("Project 'projectId' was changed by another request. Please try again"), &dao.Project{Version: 5}, nil, 5},
+		email:   "test@example.com",
+		wantErr: errors.Wrap(errors.NewClient("Project 'projectId' was changed by another request. Please try again"), "Failed database call to put object"),
+	},
 	{
 		name:      "SuccessfulUpdate",
 		cookie:    "cookie",
@@ -166,6 +364,7 @@ var putObjectTests = []struct {
 			Description: "desc",
 			Attributes: []*dao.Attribute{
 				{Name: "ValidName", Type: "Text"},
+				{Name: "Status", Type: "Enum", Values: []string{"draft", "published"}},
 			},
 		},
 		db: &databaseMock{
@@ -178,10 +377,14 @@ var putObjectTests = []struct {
 				Description: "desc",
 				Attributes: []*dao.Attribute{
 					{Name: "ValidName", Type: "Text", CodeName: "validName"},
+					{Name: "Status", Type: "Enum", CodeName: "status", Values: []string{"draft", "published"}},
 				},
 			},
 			"id",
 			nil,
+			&dao.Project{Version: 5},
+			nil,
+			5,
 		},
 		email:  "test@example.com",
 		wantID: "name",
@@ -191,7 +394,7 @@ var putObjectTests = []struct {
 		cookie:    "cookie",
 		projectID: "projectId",
 		object:    &dao.Object{Name: "name", Description: "desc"},
-		db:        &databaseMock{"test@example.com", "projectId", &dao.Object{ID: "name", Name: "name", CodeName: "Name", Description: "desc"}, "", nil},
+		db:        &databaseMock{"test@example.com", "projectId", &dao.Object{ID: "name", Name: "name", CodeName: "Name", Description: "desc"}, "", nil, &dao.Project{}, nil, 0},
 		email:     "test@example.com",
 		wantID:    "name",
 	},
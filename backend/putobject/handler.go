@@ -27,19 +27,19 @@ func (response *putObjectResponse) SetError(err string) {
 var putObjectFunc = putObject
 
 // HandlePutObject parses the request object from AWS APIGateway and passes it to the putObject action. The
-// request must contain a valid `Cookie` header, a `pid` path parameter, and an object defintion in the body.
+// request must contain a valid cookie or access token, a `pid` path parameter, and an object defintion in the body.
 // If the request succeeds, the response will have a 200 status, and the body will be empty. If the request
 // fails, the response will have either a 400 or a 500 status, and the body will have an `error` field detailing
 // what went wrong. This function returns a non-nil error only if JSON marshaling of the response body fails.
 func HandlePutObject(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	// Get request parameters
-	cookie := auth.ExtractCookie(request.Headers["Cookie"])
+	cookie := auth.ExtractCredential(request.Headers)
 	projectID := request.PathParameters["pid"]
 	var object *dao.Object
 	json.Unmarshal([]byte(request.Body), &object)
 
 	// Perform the action
-	id, err := putObjectFunc(cookie, projectID, object, auth.VerifyCookie, dao.Dynamo)
+	id, err := putObjectFunc(cookie, projectID, object, auth.Verifier(), dao.Dynamo)
 
 	// Handle the output
 	return http.GatewayResponse(&putObjectResponse{ID: id}, "", err), nil
@@ -7,14 +7,15 @@ import (
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
 	"github.com/jackstenglein/rest_api_creator/backend/dao"
 	"github.com/jackstenglein/rest_api_creator/backend/errors"
 )
 
-type putObjectMockFunc func(string, string, *dao.Object, verifyCookieFunc, putObjectDatabase) (string, error)
+type putObjectMockFunc func(string, string, *dao.Object, auth.VerifyCookieFunc, putObjectDatabase) (string, error)
 
 func putObjectMock(wantCookie string, wantProjectID string, wantObject *dao.Object, wantID string, err error) putObjectMockFunc {
-	return func(cookie string, projectID string, object *dao.Object, verify verifyCookieFunc, db putObjectDatabase) (string, error) {
+	return func(cookie string, projectID string, object *dao.Object, verify auth.VerifyCookieFunc, db putObjectDatabase) (string, error) {
 		if cookie != wantCookie || projectID != projectID || !reflect.DeepEqual(object, wantObject) {
 			return "", errors.NewServer("Incorrect parameters passed to mock")
 		}
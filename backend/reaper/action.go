@@ -0,0 +1,132 @@
+package reaper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// gracePeriod is the minimum age of an instance that can be reaped. deployProject records a new instance
+// shortly after it launches it, so younger instances may simply not be recorded yet.
+const gracePeriod = 15 * time.Minute
+
+// reaperDatabase wraps the database functions used by the reapInstances action in order to allow dependency injection.
+type reaperDatabase interface {
+	GetProject(string, string) (*dao.Project, error)
+}
+
+// now points to the function used to get the current time. It should not be changed except in unit tests.
+var now = time.Now
+
+// reapedInstance is an orphaned instance that was terminated.
+type reapedInstance struct {
+	ID        string `json:"instanceId"`
+	Email     string `json:"email"`
+	ProjectID string `json:"projectId"`
+	Reason    string `json:"reason"`
+}
+
+// reaperReport summarizes a run of the reapInstances action.
+type reaperReport struct {
+	// Provider is the name of the provider whose instances were checked.
+	Provider string `json:"provider"`
+
+	// Checked is the number of instances that were checked.
+	Checked int `json:"checked"`
+
+	// Terminated lists the orphaned instances that were terminated.
+	Terminated []reapedInstance `json:"terminated"`
+
+	// Untracked lists the IDs of instances without an owner, which were launched before instances were tagged
+	// with their owner. They are never terminated, since it is not known which project they run.
+	Untracked []string `json:"untracked"`
+
+	// Errors lists the instances that could not be checked or terminated.
+	Errors []string `json:"errors"`
+}
+
+// orphanReason returns why the given instance is orphaned, or the empty string if it is still the deployment of
+// the project that owns it. An instance is orphaned if its project no longer exists or if its project records
+// a different instance.
+func orphanReason(instance *provider.Instance, db reaperDatabase) (string, error) {
+	project, err := db.GetProject(instance.Owner.Email, instance.Owner.ProjectID)
+	if err != nil {
+		if errors.UserError(err) == nil {
+			return "", err
+		}
+		return "Project no longer exists", nil
+	}
+	if project.InstanceID != instance.ID {
+		return "Instance is not the deployment of its project", nil
+	}
+	return "", nil
+}
+
+// describe returns a description of err for the report, which includes its cause.
+func describe(err error) string {
+	return errors.Message(err) + ": " + errors.Message(errors.Cause(err))
+}
+
+// reapInstances terminates the orphaned instances of the configured deployment provider, which is found with
+// providers. Instances are orphaned when their deployment could not be recorded or when they failed to terminate
+// after being replaced. Each instance is checked against the deployment of the project named by its owner, and
+// instances younger than gracePeriod are skipped. The returned report lists the terminated instances; if any
+// instance could not be checked or terminated, the report is returned along with an error.
+func reapInstances(db reaperDatabase, providers provider.LookupFunc) (*reaperReport, error) {
+	deployer, err := providers("")
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get deployment provider")
+	}
+
+	instances, err := deployer.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to list instances")
+	}
+
+	report := &reaperReport{Provider: deployer.Name(), Terminated: []reapedInstance{}, Untracked: []string{}, Errors: []string{}}
+	for _, instance := range instances {
+		if now().Sub(instance.LaunchTime) < gracePeriod {
+			continue
+		}
+		report.Checked++
+		if instance.Owner.Email == "" || instance.Owner.ProjectID == "" {
+			report.Untracked = append(report.Untracked, instance.ID)
+			continue
+		}
+
+		reason, err := orphanReason(instance, db)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("Failed to get project of instance %s", instance.ID))
+			log.Error(err)
+			report.Errors = append(report.Errors, describe(err))
+			continue
+		}
+		if reason == "" {
+			continue
+		}
+
+		log.Info("Terminating orphaned instance:", instance.ID, reason)
+		err = deployer.Terminate(instance.ID)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("Failed to terminate instance %s", instance.ID))
+			log.Error(err)
+			report.Errors = append(report.Errors, describe(err))
+			continue
+		}
+		report.Terminated = append(report.Terminated, reapedInstance{
+			ID:        instance.ID,
+			Email:     instance.Owner.Email,
+			ProjectID: instance.Owner.ProjectID,
+			Reason:    reason,
+		})
+	}
+
+	if len(report.Errors) > 0 {
+		return report, errors.NewServer(fmt.Sprintf("Failed to reap %d instances", len(report.Errors)))
+	}
+	return report, nil
+}
@@ -0,0 +1,198 @@
+package reaper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type databaseMock struct {
+	projects map[string]*dao.Project
+	getErr   error
+}
+
+func (mock *databaseMock) GetProject(email string, projectID string) (*dao.Project, error) {
+	if mock.getErr != nil {
+		return nil, mock.getErr
+	}
+	project, ok := mock.projects[email+"/"+projectID]
+	if !ok {
+		return nil, errors.Wrap(errors.NewClient("Project '"+projectID+"' not found"), "Failed to get user")
+	}
+	return project, nil
+}
+
+type providerMock struct {
+	instances    []*provider.Instance
+	listErr      error
+	terminateErr error
+	terminated   []string
+}
+
+func (mock *providerMock) lookup(name string) (provider.Provider, error) {
+	if name != "" {
+		return nil, errors.NewServer("Incorrect input to Lookup mock")
+	}
+	if mock == nil {
+		return nil, errors.NewServer("Unsupported deployment provider `gce`")
+	}
+	return mock, nil
+}
+
+func (mock *providerMock) Name() string {
+	return "ec2"
+}
+
+func (mock *providerMock) Launch(projectURL string, owner provider.Owner, config *dao.DeployConfig) (*provider.Instance, error) {
+	return nil, errors.NewServer("Unexpected call to Launch mock")
+}
+
+func (mock *providerMock) Describe(instanceID string) (*provider.Instance, error) {
+	return nil, errors.NewServer("Unexpected call to Describe mock")
+}
+
+func (mock *providerMock) Terminate(instanceID string) error {
+	mock.terminated = append(mock.terminated, instanceID)
+	return mock.terminateErr
+}
+
+func (mock *providerMock) Logs(instanceID string) (string, error) {
+	return "", errors.NewServer("Unexpected call to Logs mock")
+}
+
+func (mock *providerMock) List() ([]*provider.Instance, error) {
+	return mock.instances, mock.listErr
+}
+
+var currentTime = time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+func instance(id string, email string, projectID string) *provider.Instance {
+	return &provider.Instance{
+		ID:         id,
+		State:      provider.Running,
+		LaunchTime: currentTime.Add(-time.Hour),
+		Owner:      provider.Owner{Email: email, ProjectID: projectID},
+	}
+}
+
+func report(checked int, terminated []reapedInstance, untracked []string, errs []string) *reaperReport {
+	r := &reaperReport{Provider: "ec2", Checked: checked, Terminated: []reapedInstance{}, Untracked: []string{}, Errors: []string{}}
+	r.Terminated = append(r.Terminated, terminated...)
+	r.Untracked = append(r.Untracked, untracked...)
+	r.Errors = append(r.Errors, errs...)
+	return r
+}
+
+var deployedProjects = map[string]*dao.Project{
+	"test@example.com/project": {ID: "project", Deployment: dao.Deployment{InstanceID: "current"}},
+}
+
+var reapInstancesTests = []struct {
+	name string
+
+	// Mock data
+	db       *databaseMock
+	deployer *providerMock
+
+	// Expected output
+	wantReport     *reaperReport
+	wantTerminated []string
+	wantErr        error
+}{
+	{
+		name:    "ProviderLookupFailure",
+		wantErr: errors.Wrap(errors.NewServer("Unsupported deployment provider `gce`"), "Failed to get deployment provider"),
+	},
+	{
+		name:     "ListFailure",
+		deployer: &providerMock{listErr: errors.NewServer("EC2 failure")},
+		wantErr:  errors.Wrap(errors.NewServer("EC2 failure"), "Failed to list instances"),
+	},
+	{
+		name:       "NoInstances",
+		deployer:   &providerMock{},
+		wantReport: report(0, nil, nil, nil),
+	},
+	{
+		name:       "CurrentDeployment",
+		db:         &databaseMock{projects: deployedProjects},
+		deployer:   &providerMock{instances: []*provider.Instance{instance("current", "test@example.com", "project")}},
+		wantReport: report(1, nil, nil, nil),
+	},
+	{
+		name: "RecentInstance",
+		deployer: &providerMock{instances: []*provider.Instance{
+			{ID: "recent", LaunchTime: currentTime.Add(-time.Minute), Owner: provider.Owner{Email: "test@example.com", ProjectID: "project"}},
+		}},
+		wantReport: report(0, nil, nil, nil),
+	},
+	{
+		name:       "UntrackedInstance",
+		deployer:   &providerMock{instances: []*provider.Instance{instance("legacy", "", "")}},
+		wantReport: report(1, nil, []string{"legacy"}, nil),
+	},
+	{
+		name:     "GetProjectFailure",
+		db:       &databaseMock{getErr: errors.NewServer("DynamoDB failure")},
+		deployer: &providerMock{instances: []*provider.Instance{instance("replaced", "test@example.com", "project")}},
+		wantReport: report(1, nil, nil, []string{
+			"Failed to get project of instance replaced: DynamoDB failure",
+		}),
+		wantErr: errors.NewServer("Failed to reap 1 instances"),
+	},
+	{
+		name:     "TerminateFailure",
+		db:       &databaseMock{projects: deployedProjects},
+		deployer: &providerMock{instances: []*provider.Instance{instance("replaced", "test@example.com", "project")}, terminateErr: errors.NewServer("EC2 failure")},
+		wantReport: report(1, nil, nil, []string{
+			"Failed to terminate instance replaced: EC2 failure",
+		}),
+		wantTerminated: []string{"replaced"},
+		wantErr:        errors.NewServer("Failed to reap 1 instances"),
+	},
+	{
+		name: "SuccessfulInvocation",
+		db:   &databaseMock{projects: deployedProjects},
+		deployer: &providerMock{instances: []*provider.Instance{
+			instance("current", "test@example.com", "project"),
+			instance("replaced", "test@example.com", "project"),
+			instance("deleted", "test@example.com", "deletedproject"),
+			instance("legacy", "", ""),
+		}},
+		wantReport: report(4, []reapedInstance{
+			{ID: "replaced", Email: "test@example.com", ProjectID: "project", Reason: "Instance is not the deployment of its project"},
+			{ID: "deleted", Email: "test@example.com", ProjectID: "deletedproject", Reason: "Project no longer exists"},
+		}, []string{"legacy"}, nil),
+		wantTerminated: []string{"replaced", "deleted"},
+	},
+}
+
+func TestReapInstances(t *testing.T) {
+	for _, test := range reapInstancesTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			now = func() time.Time { return currentTime }
+			defer func() {
+				now = time.Now
+			}()
+
+			// Execute
+			report, err := reapInstances(test.db, test.deployer.lookup)
+
+			// Verify
+			if !reflect.DeepEqual(report, test.wantReport) {
+				t.Errorf("Got report %+v; want %+v", report, test.wantReport)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+			if test.deployer != nil && !reflect.DeepEqual(test.deployer.terminated, test.wantTerminated) {
+				t.Errorf("Got terminated %v; want %v", test.deployer.terminated, test.wantTerminated)
+			}
+		})
+	}
+}
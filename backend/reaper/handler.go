@@ -0,0 +1,27 @@
+// Package reaper handles the scheduled event that terminates orphaned deployment instances.
+package reaper
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+
+	// Register the supported deployment providers
+	_ "github.com/jackstenglein/rest_api_creator/backend/deploy/docker"
+	_ "github.com/jackstenglein/rest_api_creator/backend/deploy/ec2"
+)
+
+// reap points to the function used to perform the reapInstances action. It should
+// not be changed except in unit tests.
+var reap = reapInstances
+
+// HandleReap is invoked by a CloudWatch scheduled event and passes it to the reapInstances action. The report of
+// the run is logged and returned as the result of the invocation. If any instance could not be reaped, an error
+// is also returned so that the invocation is marked as failed.
+func HandleReap(event events.CloudWatchEvent) (*reaperReport, error) {
+	report, err := reap(dao.Dynamo, provider.Lookup)
+	log.Error(err)
+	log.Info("Reaper report:", report)
+	return report, err
+}
@@ -0,0 +1,67 @@
+package reaper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type reapMockFunc func(reaperDatabase, provider.LookupFunc) (*reaperReport, error)
+
+func reapMock(report *reaperReport, err error) reapMockFunc {
+	return func(reaperDatabase, provider.LookupFunc) (*reaperReport, error) {
+		return report, err
+	}
+}
+
+var handleReapTests = []struct {
+	name string
+
+	reapMock reapMockFunc
+
+	wantReport *reaperReport
+	wantErr    error
+}{
+	{
+		name:     "ReapFailure",
+		reapMock: reapMock(nil, errors.Wrap(errors.NewServer("EC2 failure"), "Failed to list instances")),
+		wantErr:  errors.Wrap(errors.NewServer("EC2 failure"), "Failed to list instances"),
+	},
+	{
+		name:       "PartialFailure",
+		reapMock:   reapMock(report(1, nil, nil, []string{"Failed to terminate instance i-1: EC2 failure"}), errors.NewServer("Failed to reap 1 instances")),
+		wantReport: report(1, nil, nil, []string{"Failed to terminate instance i-1: EC2 failure"}),
+		wantErr:    errors.NewServer("Failed to reap 1 instances"),
+	},
+	{
+		name:       "SuccessfulInvocation",
+		reapMock:   reapMock(report(2, []reapedInstance{{ID: "i-1", Email: "test@example.com", ProjectID: "project", Reason: "Project no longer exists"}}, nil, nil), nil),
+		wantReport: report(2, []reapedInstance{{ID: "i-1", Email: "test@example.com", ProjectID: "project", Reason: "Project no longer exists"}}, nil, nil),
+	},
+}
+
+func TestHandleReap(t *testing.T) {
+	for _, test := range handleReapTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			reap = test.reapMock
+			defer func() {
+				reap = reapInstances
+			}()
+
+			// Execute
+			report, err := HandleReap(events.CloudWatchEvent{})
+
+			// Verify
+			if !reflect.DeepEqual(report, test.wantReport) {
+				t.Errorf("Got report %+v; want %+v", report, test.wantReport)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
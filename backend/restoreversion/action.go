@@ -0,0 +1,41 @@
+package restoreversion
+
+import (
+	"strconv"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// restoreVersionDatabase wraps the database methods required to perform the restoreVersion action.
+// This allows for dependency injection of the database.
+type restoreVersionDatabase interface {
+	auth.CredentialStore
+	RestoreVersion(string, string, int) (*dao.Project, error)
+}
+
+// restoreVersion makes the given version of the given projectID the current project of the user associated
+// with the given cookie. Restoring records a new version, so it can itself be undone. The version must be a
+// positive integer. If the restore succeeds, the updated project is returned. Otherwise, restoreVersion returns
+// a nil project along with the error.
+func restoreVersion(cookie string, projectID string, version string, verifyCookie auth.VerifyCookieFunc, db restoreVersionDatabase) (*dao.Project, error) {
+	if projectID == "" {
+		return nil, errors.NewClient("Parameter `projectID` is required")
+	}
+	number, err := strconv.Atoi(version)
+	if err != nil || number < 1 {
+		return nil, errors.NewClient("Parameter `version` must be a positive integer")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return nil, errors.NewClient("Not authenticated")
+	}
+
+	project, err := db.RestoreVersion(email, projectID, number)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to restore project version")
+	}
+	return project, nil
+}
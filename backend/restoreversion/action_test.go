@@ -0,0 +1,131 @@
+package restoreversion
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email     string
+	projectID string
+	version   int
+	project   *dao.Project
+	err       error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) RestoreVersion(email string, projectID string, version int) (*dao.Project, error) {
+	if email != mock.email || projectID != mock.projectID || version != mock.version {
+		return nil, errors.NewServer("Incorrect input to RestoreVersion mock")
+	}
+	return mock.project, mock.err
+}
+
+var restoredProject = &dao.Project{ID: "projectID", Name: "Project", Version: 5, Objects: map[string]*dao.Object{}}
+
+var restoreVersionTests = []struct {
+	name string
+
+	// Input
+	cookie    string
+	projectID string
+	version   string
+
+	// Mock data
+	db        *databaseMock
+	email     string
+	verifyErr error
+
+	// Expected output
+	wantProject *dao.Project
+	wantErr     error
+}{
+	{
+		name:    "EmptyProjectID",
+		cookie:  "cookie",
+		version: "2",
+		wantErr: errors.NewClient("Parameter `projectID` is required"),
+	},
+	{
+		name:      "InvalidVersion",
+		cookie:    "cookie",
+		projectID: "projectID",
+		version:   "-1",
+		wantErr:   errors.NewClient("Parameter `version` must be a positive integer"),
+	},
+	{
+		name:      "InvalidCookie",
+		cookie:    "cookie",
+		projectID: "projectID",
+		version:   "2",
+		verifyErr: errors.NewClient("Invalid cookie"),
+		wantErr:   errors.NewClient("Not authenticated"),
+	},
+	{
+		name:      "DatabaseFailure",
+		cookie:    "cookie",
+		projectID: "projectID",
+		version:   "2",
+		db:        &databaseMock{email: "test@example.com", projectID: "projectID", version: 2, err: errors.NewServer("DynamoDB failure")},
+		email:     "test@example.com",
+		wantErr:   errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to restore project version"),
+	},
+	{
+		name:        "SuccessfulInvocation",
+		cookie:      "cookie",
+		projectID:   "projectID",
+		version:     "2",
+		db:          &databaseMock{email: "test@example.com", projectID: "projectID", version: 2, project: restoredProject},
+		email:       "test@example.com",
+		wantProject: restoredProject,
+	},
+}
+
+func TestRestoreVersion(t *testing.T) {
+	for _, test := range restoreVersionTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock(test.cookie, test.db, test.email, test.verifyErr)
+
+			// Execute
+			project, err := restoreVersion(test.cookie, test.projectID, test.version, verifyCookie, test.db)
+
+			// Verify
+			if !reflect.DeepEqual(project, test.wantProject) {
+				t.Errorf("Got project %v; want %v", project, test.wantProject)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Package restoreversion handles requests to the POST /projects/{pid}/versions/{version}/restore REST API endpoint.
+package restoreversion
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// restoreVersionResponse contains the fields returned in the API JSON response body.
+type restoreVersionResponse struct {
+	Project *dao.Project `json:"project,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+func (response *restoreVersionResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// restoreVersionFunc points to the function used to perform the restoreVersion action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var restoreVersionFunc = restoreVersion
+
+// HandleRestoreVersion parses the request object from AWS APIGateway and passes it to the restoreVersion action.
+// The request must contain a valid cookie or access token and the `pid` and `version` path parameters. If the request
+// succeeds, the response will have a 200 status, and the body will have a `project` field containing the restored
+// project. If the request fails, the response will have either a 400 or a 500 status, and the body will have an
+// `error` field detailing what went wrong.
+func HandleRestoreVersion(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	projectID := request.PathParameters["pid"]
+	version := request.PathParameters["version"]
+	cookie := auth.ExtractCredential(request.Headers)
+
+	// Perform the action
+	project, err := restoreVersionFunc(cookie, projectID, version, auth.Verifier(), dao.Dynamo)
+	log.Error(err)
+
+	// Return the response
+	return http.GatewayResponse(&restoreVersionResponse{Project: project}, "", err), nil
+}
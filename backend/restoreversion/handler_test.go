@@ -0,0 +1,90 @@
+package restoreversion
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type restoreVersionMockFunc func(string, string, string, auth.VerifyCookieFunc, restoreVersionDatabase) (*dao.Project, error)
+
+func restoreVersionMock(wantCookie string, wantProjectID string, wantVersion string, project *dao.Project, err error) restoreVersionMockFunc {
+	return func(cookie string, projectID string, version string, _ auth.VerifyCookieFunc, _ restoreVersionDatabase) (*dao.Project, error) {
+		if cookie != wantCookie || projectID != wantProjectID || version != wantVersion {
+			return nil, errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return project, err
+	}
+}
+
+func handlerRequest(cookie string, projectID string, version string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		Headers:        map[string]string{"Cookie": cookie},
+		PathParameters: map[string]string{"pid": projectID, "version": version},
+	}
+}
+
+func handlerResponse(project *dao.Project, err string, status int) events.APIGatewayProxyResponse {
+	json, _ := json.Marshal(&restoreVersionResponse{Project: project, Error: err})
+	return events.APIGatewayProxyResponse{
+		Body:       string(json),
+		Headers:    map[string]string{"Access-Control-Allow-Origin": os.Getenv("CORS_ORIGIN"), "Access-Control-Allow-Credentials": "true"},
+		StatusCode: status,
+	}
+}
+
+var handleRestoreVersionTests = []struct {
+	name string
+
+	// Input
+	request events.APIGatewayProxyRequest
+
+	// Mock data
+	restoreVersionMock restoreVersionMockFunc
+
+	// Expected output
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:               "RestoreVersionFailure",
+		request:            handlerRequest("session=cookievalue", "projectID", "2"),
+		restoreVersionMock: restoreVersionMock("cookievalue", "projectID", "2", nil, errors.NewServer("Failed database call")),
+		wantResponse:       handlerResponse(nil, "Failed database call", 500),
+	},
+	{
+		name:               "SuccessfulInvocation",
+		request:            handlerRequest("session=cookievalue", "projectID", "2"),
+		restoreVersionMock: restoreVersionMock("cookievalue", "projectID", "2", restoredProject, nil),
+		wantResponse:       handlerResponse(restoredProject, "", 200),
+	},
+}
+
+func TestHandleRestoreVersion(t *testing.T) {
+	for _, test := range handleRestoreVersionTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			restoreVersionFunc = test.restoreVersionMock
+			defer func() {
+				restoreVersionFunc = restoreVersion
+			}()
+
+			// Execute
+			response, err := HandleRestoreVersion(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package revokesession
+
+import (
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// revokeSessionDatabase wraps the database methods required to perform the revokeSession action.
+// This allows for dependency injection of the database.
+type revokeSessionDatabase interface {
+	auth.CredentialStore
+	DeleteSession(string, string) error
+}
+
+// revokeSession deletes the session with the given ID of the user associated with the given cookie, which logs
+// out the device of the session. The session of the cookie itself may be revoked, in which case the request
+// has the same effect as logging out. If the user does not have the session, a client error is returned.
+func revokeSession(cookie string, sessionID string, verifyCookie auth.VerifyCookieFunc, db revokeSessionDatabase) error {
+	if sessionID == "" {
+		return errors.NewClient("Parameter `sessionID` is required")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return errors.NewClient("Not authenticated")
+	}
+
+	err = db.DeleteSession(email, sessionID)
+	return errors.Wrap(err, "Failed to revoke session")
+}
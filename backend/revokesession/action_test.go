@@ -0,0 +1,110 @@
+package revokesession
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email     string
+	sessionID string
+	err       error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) DeleteSession(email string, sessionID string) error {
+	if email != mock.email || sessionID != mock.sessionID {
+		return errors.NewServer("Incorrect input to DeleteSession mock")
+	}
+	return mock.err
+}
+
+var revokeSessionTests = []struct {
+	name string
+
+	// Input
+	cookie    string
+	sessionID string
+
+	// Mock data
+	db        *databaseMock
+	email     string
+	verifyErr error
+
+	// Expected output
+	wantErr error
+}{
+	{
+		name:    "EmptySessionID",
+		cookie:  "cookie",
+		wantErr: errors.NewClient("Parameter `sessionID` is required"),
+	},
+	{
+		name:      "InvalidCookie",
+		cookie:    "cookie",
+		sessionID: "sessionID",
+		verifyErr: errors.NewClient("Invalid cookie"),
+		wantErr:   errors.NewClient("Not authenticated"),
+	},
+	{
+		name:      "SessionNotFound",
+		cookie:    "cookie",
+		sessionID: "sessionID",
+		db:        &databaseMock{"test@example.com", "sessionID", errors.NewClient("Session 'sessionID' not found")},
+		email:     "test@example.com",
+		wantErr:   errors.Wrap(errors.NewClient("Session 'sessionID' not found"), "Failed to revoke session"),
+	},
+	{
+		name:      "SuccessfulInvocation",
+		cookie:    "cookie",
+		sessionID: "sessionID",
+		db:        &databaseMock{"test@example.com", "sessionID", nil},
+		email:     "test@example.com",
+	},
+}
+
+func TestRevokeSession(t *testing.T) {
+	for _, test := range revokeSessionTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock(test.cookie, test.db, test.email, test.verifyErr)
+
+			// Execute
+			err := revokeSession(test.cookie, test.sessionID, verifyCookie, test.db)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// Package revokesession handles requests to the DELETE /sessions/{id} REST API endpoint.
+package revokesession
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// revokeSessionResponse contains the fields returned in the API JSON response body.
+type revokeSessionResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+func (response *revokeSessionResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// revokeSessionFunc points to the function used to perform the revokeSession action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var revokeSessionFunc = revokeSession
+
+// HandleRevokeSession parses the request from AWS APIGateway and passes it to the revokeSession action. The
+// request must contain a valid `Cookie` header and an `id` path parameter. If the request succeeds, the
+// response will have a 200 status and an empty body. If the request fails, the response will have either a
+// 400 or 500 status and an `error` field in the body.
+func HandleRevokeSession(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	cookie := auth.ExtractCookie(request.Headers["Cookie"])
+	sessionID := request.PathParameters["id"]
+
+	// Revoke the session
+	err := revokeSessionFunc(cookie, sessionID, auth.VerifySession, dao.Dynamo)
+	log.Error(err)
+
+	// Handle the output
+	return http.GatewayResponse(&revokeSessionResponse{}, "", err), nil
+}
@@ -0,0 +1,86 @@
+package revokesession
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type revokeSessionMockFunc func(string, string, auth.VerifyCookieFunc, revokeSessionDatabase) error
+
+func revokeSessionMock(wantCookie string, wantSessionID string, err error) revokeSessionMockFunc {
+	return func(cookie string, sessionID string, _ auth.VerifyCookieFunc, _ revokeSessionDatabase) error {
+		if cookie != wantCookie || sessionID != wantSessionID {
+			return errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return err
+	}
+}
+
+func handlerRequest(cookie string, sessionID string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		Headers:        map[string]string{"Cookie": cookie},
+		PathParameters: map[string]string{"id": sessionID},
+	}
+}
+
+func handlerResponse(body string, status int) events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		Body: body,
+		Headers: map[string]string{
+			"Access-Control-Allow-Origin":      os.Getenv("CORS_ORIGIN"),
+			"Access-Control-Allow-Credentials": "true",
+		},
+		StatusCode: status,
+	}
+}
+
+var handleRevokeSessionTests = []struct {
+	name string
+
+	request           events.APIGatewayProxyRequest
+	revokeSessionMock revokeSessionMockFunc
+
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:              "RevokeSessionFailure",
+		request:           handlerRequest("session=cookievalue", "sessionID"),
+		revokeSessionMock: revokeSessionMock("cookievalue", "sessionID", errors.Wrap(errors.NewClient("Session 'sessionID' not found"), "Failed to revoke session")),
+		wantResponse:      handlerResponse(`{"error":"Session 'sessionID' not found"}`, 400),
+	},
+	{
+		name:              "SuccessfulInvocation",
+		request:           handlerRequest("session=cookievalue", "sessionID"),
+		revokeSessionMock: revokeSessionMock("cookievalue", "sessionID", nil),
+		wantResponse:      handlerResponse("{}", 200),
+	},
+}
+
+func TestHandleRevokeSession(t *testing.T) {
+	for _, test := range handleRevokeSessionTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			revokeSessionFunc = test.revokeSessionMock
+			defer func() {
+				revokeSessionFunc = revokeSession
+			}()
+
+			// Execute
+			response, err := HandleRevokeSession(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
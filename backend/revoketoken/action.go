@@ -0,0 +1,30 @@
+package revoketoken
+
+import (
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// revokeTokenDatabase wraps the database methods required to perform the revokeToken action.
+// This allows for dependency injection of the database.
+type revokeTokenDatabase interface {
+	auth.CredentialStore
+	DeleteAccessToken(string, string) error
+}
+
+// revokeToken deletes the access token with the given ID of the user associated with the given cookie, after
+// which requests using the token are no longer authenticated. If the user does not have the access token, a
+// client error is returned.
+func revokeToken(cookie string, tokenID string, verifyCookie auth.VerifyCookieFunc, db revokeTokenDatabase) error {
+	if tokenID == "" {
+		return errors.NewClient("Parameter `tokenID` is required")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return errors.NewClient("Not authenticated")
+	}
+
+	err = db.DeleteAccessToken(email, tokenID)
+	return errors.Wrap(err, "Failed to revoke access token")
+}
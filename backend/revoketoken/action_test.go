@@ -0,0 +1,110 @@
+package revoketoken
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email   string
+	tokenID string
+	err     error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) DeleteAccessToken(email string, tokenID string) error {
+	if email != mock.email || tokenID != mock.tokenID {
+		return errors.NewServer("Incorrect input to DeleteAccessToken mock")
+	}
+	return mock.err
+}
+
+var revokeTokenTests = []struct {
+	name string
+
+	// Input
+	cookie  string
+	tokenID string
+
+	// Mock data
+	db        *databaseMock
+	email     string
+	verifyErr error
+
+	// Expected output
+	wantErr error
+}{
+	{
+		name:    "EmptyTokenID",
+		cookie:  "cookie",
+		wantErr: errors.NewClient("Parameter `tokenID` is required"),
+	},
+	{
+		name:      "InvalidCookie",
+		cookie:    "cookie",
+		tokenID:   "tokenID",
+		verifyErr: errors.NewClient("Invalid cookie"),
+		wantErr:   errors.NewClient("Not authenticated"),
+	},
+	{
+		name:    "TokenNotFound",
+		cookie:  "cookie",
+		tokenID: "tokenID",
+		db:      &databaseMock{"test@example.com", "tokenID", errors.NewClient("Access token 'tokenID' not found")},
+		email:   "test@example.com",
+		wantErr: errors.Wrap(errors.NewClient("Access token 'tokenID' not found"), "Failed to revoke access token"),
+	},
+	{
+		name:    "SuccessfulInvocation",
+		cookie:  "cookie",
+		tokenID: "tokenID",
+		db:      &databaseMock{"test@example.com", "tokenID", nil},
+		email:   "test@example.com",
+	},
+}
+
+func TestRevokeToken(t *testing.T) {
+	for _, test := range revokeTokenTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock(test.cookie, test.db, test.email, test.verifyErr)
+
+			// Execute
+			err := revokeToken(test.cookie, test.tokenID, verifyCookie, test.db)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// Package revoketoken handles requests to the DELETE /tokens/{id} REST API endpoint.
+package revoketoken
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// revokeTokenResponse contains the fields returned in the API JSON response body.
+type revokeTokenResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+func (response *revokeTokenResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// revokeTokenFunc points to the function used to perform the revokeToken action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var revokeTokenFunc = revokeToken
+
+// HandleRevokeToken parses the request from AWS APIGateway and passes it to the revokeToken action. The
+// request must contain a valid `Cookie` header and an `id` path parameter. If the request succeeds, the
+// response will have a 200 status and an empty body. If the request fails, the response will have either a
+// 400 or 500 status and an `error` field in the body.
+func HandleRevokeToken(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	cookie := auth.ExtractCookie(request.Headers["Cookie"])
+	tokenID := request.PathParameters["id"]
+
+	// Revoke the access token
+	err := revokeTokenFunc(cookie, tokenID, auth.VerifySession, dao.Dynamo)
+	log.Error(err)
+
+	// Handle the output
+	return http.GatewayResponse(&revokeTokenResponse{}, "", err), nil
+}
@@ -0,0 +1,86 @@
+package revoketoken
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type revokeTokenMockFunc func(string, string, auth.VerifyCookieFunc, revokeTokenDatabase) error
+
+func revokeTokenMock(wantCookie string, wantTokenID string, err error) revokeTokenMockFunc {
+	return func(cookie string, tokenID string, _ auth.VerifyCookieFunc, _ revokeTokenDatabase) error {
+		if cookie != wantCookie || tokenID != wantTokenID {
+			return errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return err
+	}
+}
+
+func handlerRequest(cookie string, tokenID string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		Headers:        map[string]string{"Cookie": cookie},
+		PathParameters: map[string]string{"id": tokenID},
+	}
+}
+
+func handlerResponse(body string, status int) events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		Body: body,
+		Headers: map[string]string{
+			"Access-Control-Allow-Origin":      os.Getenv("CORS_ORIGIN"),
+			"Access-Control-Allow-Credentials": "true",
+		},
+		StatusCode: status,
+	}
+}
+
+var handleRevokeTokenTests = []struct {
+	name string
+
+	request         events.APIGatewayProxyRequest
+	revokeTokenMock revokeTokenMockFunc
+
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:            "RevokeTokenFailure",
+		request:         handlerRequest("session=cookievalue", "tokenID"),
+		revokeTokenMock: revokeTokenMock("cookievalue", "tokenID", errors.Wrap(errors.NewClient("Access token 'tokenID' not found"), "Failed to revoke access token")),
+		wantResponse:    handlerResponse(`{"error":"Access token 'tokenID' not found"}`, 400),
+	},
+	{
+		name:            "SuccessfulInvocation",
+		request:         handlerRequest("session=cookievalue", "tokenID"),
+		revokeTokenMock: revokeTokenMock("cookievalue", "tokenID", nil),
+		wantResponse:    handlerResponse("{}", 200),
+	},
+}
+
+func TestHandleRevokeToken(t *testing.T) {
+	for _, test := range handleRevokeTokenTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			revokeTokenFunc = test.revokeTokenMock
+			defer func() {
+				revokeTokenFunc = revokeToken
+			}()
+
+			// Execute
+			response, err := HandleRevokeToken(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
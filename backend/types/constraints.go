@@ -0,0 +1,130 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// validateConstraints checks that the type of the given attribute accepts each of its constraints and that the
+// constraints are consistent with each other: min must not be greater than max, lengths must not be negative,
+// minLength must not be greater than maxLength, the pattern must compile and the default value must be a valid
+// value of the type that satisfies every other constraint.
+func validateConstraints(t *Type, attribute *dao.Attribute) error {
+	fields := []struct {
+		name     string
+		set      bool
+		accepted bool
+	}{
+		{"min", attribute.Min != nil, t.Constraints.Range},
+		{"max", attribute.Max != nil, t.Constraints.Range},
+		{"minLength", attribute.MinLength != nil, t.Constraints.Length},
+		{"maxLength", attribute.MaxLength != nil, t.Constraints.Length},
+		{"pattern", attribute.Pattern != "", t.Constraints.Pattern},
+		{"unique", attribute.Unique, t.Constraints.Unique},
+		{"default", attribute.Default != "", t.Constraints.Default},
+	}
+	for _, field := range fields {
+		if field.set && !field.accepted {
+			return errors.NewClient(fmt.Sprintf("Attribute type `%s` does not accept a `%s` field", t.Name, field.name))
+		}
+	}
+
+	if t.Name == "Integer" {
+		if (attribute.Min != nil && *attribute.Min != math.Trunc(*attribute.Min)) || (attribute.Max != nil && *attribute.Max != math.Trunc(*attribute.Max)) {
+			return errors.NewClient("Fields `min` and `max` of an `Integer` attribute must be whole numbers")
+		}
+	}
+	if attribute.Min != nil && attribute.Max != nil && *attribute.Min > *attribute.Max {
+		return errors.NewClient("Field `min` must not be greater than field `max`")
+	}
+	if (attribute.MinLength != nil && *attribute.MinLength < 0) || (attribute.MaxLength != nil && *attribute.MaxLength < 0) {
+		return errors.NewClient("Fields `minLength` and `maxLength` must not be negative")
+	}
+	if attribute.MinLength != nil && attribute.MaxLength != nil && *attribute.MinLength > *attribute.MaxLength {
+		return errors.NewClient("Field `minLength` must not be greater than field `maxLength`")
+	}
+
+	var pattern *regexp.Regexp
+	if attribute.Pattern != "" {
+		var err error
+		pattern, err = regexp.Compile(attribute.Pattern)
+		if err != nil {
+			return errors.NewClient(fmt.Sprintf("Field `pattern` is not a valid regular expression: %v", err))
+		}
+	}
+
+	if attribute.Default == "" {
+		return nil
+	}
+	return validateDefault(t, attribute, pattern)
+}
+
+// validateDefault checks that the default value of the given attribute is a valid value of its type that
+// satisfies the attribute's other constraints. The given pattern is the compiled Pattern field, or nil.
+func validateDefault(t *Type, attribute *dao.Attribute, pattern *regexp.Regexp) error {
+	value, ok := t.parse(attribute.Default)
+	if !ok {
+		return errors.NewClient(fmt.Sprintf("Default value `%s` is not a valid `%s`", attribute.Default, t.Name))
+	}
+
+	if t.HasValues && !contains(attribute.Values, attribute.Default) {
+		return errors.NewClient(fmt.Sprintf("Default value `%s` is not one of the allowed values", attribute.Default))
+	}
+
+	var number float64
+	switch value := value.(type) {
+	case int64:
+		number = float64(value)
+	case float64:
+		number = value
+	}
+	if attribute.Min != nil && number < *attribute.Min {
+		return errors.NewClient(fmt.Sprintf("Default value `%s` is less than field `min`", attribute.Default))
+	}
+	if attribute.Max != nil && number > *attribute.Max {
+		return errors.NewClient(fmt.Sprintf("Default value `%s` is greater than field `max`", attribute.Default))
+	}
+
+	length := utf8.RuneCountInString(attribute.Default)
+	if attribute.MinLength != nil && length < *attribute.MinLength {
+		return errors.NewClient(fmt.Sprintf("Default value `%s` is shorter than field `minLength`", attribute.Default))
+	}
+	if attribute.MaxLength != nil && length > *attribute.MaxLength {
+		return errors.NewClient(fmt.Sprintf("Default value `%s` is longer than field `maxLength`", attribute.Default))
+	}
+	if pattern != nil && !pattern.MatchString(attribute.Default) {
+		return errors.NewClient(fmt.Sprintf("Default value `%s` does not match field `pattern`", attribute.Default))
+	}
+	return nil
+}
+
+// contains returns true if the given values contain the given value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Default returns the default value of the given attribute of the type, converted to an int64, float64, bool or
+// string depending on the type. It returns false if the attribute does not have a valid default value.
+func (t *Type) Default(attribute *dao.Attribute) (interface{}, bool) {
+	if attribute.Default == "" || t.parse == nil {
+		return nil, false
+	}
+	return t.parse(attribute.Default)
+}
+
+// FormatNumber returns the shortest representation of the given min or max value that is a valid number
+// literal in JavaScript and SQL.
+func FormatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,197 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func integer(i int) *int {
+	return &i
+}
+
+var validateConstraintsTests = []struct {
+	name      string
+	attribute *dao.Attribute
+	wantErr   error
+}{
+	{
+		name:      "RangeOnText",
+		attribute: &dao.Attribute{Type: "Text", Min: float(1)},
+		wantErr:   errors.NewClient("Attribute type `Text` does not accept a `min` field"),
+	},
+	{
+		name:      "LengthOnInteger",
+		attribute: &dao.Attribute{Type: "Integer", MaxLength: integer(1)},
+		wantErr:   errors.NewClient("Attribute type `Integer` does not accept a `maxLength` field"),
+	},
+	{
+		name:      "PatternOnEmail",
+		attribute: &dao.Attribute{Type: "Email", Pattern: "^a"},
+		wantErr:   errors.NewClient("Attribute type `Email` does not accept a `pattern` field"),
+	},
+	{
+		name:      "UniqueOnBoolean",
+		attribute: &dao.Attribute{Type: "Boolean", Unique: true},
+		wantErr:   errors.NewClient("Attribute type `Boolean` does not accept a `unique` field"),
+	},
+	{
+		name:      "DefaultOnJSON",
+		attribute: &dao.Attribute{Type: "JSON", Default: "{}"},
+		wantErr:   errors.NewClient("Attribute type `JSON` does not accept a `default` field"),
+	},
+	{
+		name:      "FractionalIntegerBound",
+		attribute: &dao.Attribute{Type: "Integer", Max: float(1.5)},
+		wantErr:   errors.NewClient("Fields `min` and `max` of an `Integer` attribute must be whole numbers"),
+	},
+	{
+		name:      "MinGreaterThanMax",
+		attribute: &dao.Attribute{Type: "Float", Min: float(2), Max: float(1)},
+		wantErr:   errors.NewClient("Field `min` must not be greater than field `max`"),
+	},
+	{
+		name:      "NegativeLength",
+		attribute: &dao.Attribute{Type: "Text", MinLength: integer(-1)},
+		wantErr:   errors.NewClient("Fields `minLength` and `maxLength` must not be negative"),
+	},
+	{
+		name:      "MinLengthGreaterThanMaxLength",
+		attribute: &dao.Attribute{Type: "Text", MinLength: integer(5), MaxLength: integer(4)},
+		wantErr:   errors.NewClient("Field `minLength` must not be greater than field `maxLength`"),
+	},
+	{
+		name:      "InvalidPattern",
+		attribute: &dao.Attribute{Type: "Text", Pattern: "[a-"},
+		wantErr:   errors.NewClient("Field `pattern` is not a valid regular expression: error parsing regexp: missing closing ]: `[a-`"),
+	},
+	{
+		name:      "DefaultInvalidForType",
+		attribute: &dao.Attribute{Type: "Integer", Default: "1.5"},
+		wantErr:   errors.NewClient("Default value `1.5` is not a valid `Integer`"),
+	},
+	{
+		name:      "DefaultInvalidBoolean",
+		attribute: &dao.Attribute{Type: "Boolean", Default: "yes"},
+		wantErr:   errors.NewClient("Default value `yes` is not a valid `Boolean`"),
+	},
+	{
+		name:      "DefaultInvalidDate",
+		attribute: &dao.Attribute{Type: "Date", Default: "2020-13-01"},
+		wantErr:   errors.NewClient("Default value `2020-13-01` is not a valid `Date`"),
+	},
+	{
+		name:      "DefaultNotAllowedValue",
+		attribute: &dao.Attribute{Type: "Enum", Values: []string{"draft"}, Default: "published"},
+		wantErr:   errors.NewClient("Default value `published` is not one of the allowed values"),
+	},
+	{
+		name:      "DefaultBelowMin",
+		attribute: &dao.Attribute{Type: "Integer", Min: float(1), Default: "0"},
+		wantErr:   errors.NewClient("Default value `0` is less than field `min`"),
+	},
+	{
+		name:      "DefaultAboveMax",
+		attribute: &dao.Attribute{Type: "Decimal", Max: float(1), Default: "1.01"},
+		wantErr:   errors.NewClient("Default value `1.01` is greater than field `max`"),
+	},
+	{
+		name:      "DefaultTooShort",
+		attribute: &dao.Attribute{Type: "Text", MinLength: integer(3), Default: "ab"},
+		wantErr:   errors.NewClient("Default value `ab` is shorter than field `minLength`"),
+	},
+	{
+		name:      "DefaultTooLong",
+		attribute: &dao.Attribute{Type: "Email", MaxLength: integer(5), Default: "a@b.com"},
+		wantErr:   errors.NewClient("Default value `a@b.com` is longer than field `maxLength`"),
+	},
+	{
+		name:      "DefaultDoesNotMatchPattern",
+		attribute: &dao.Attribute{Type: "Text", Pattern: "^[A-Z]+$", Default: "abc"},
+		wantErr:   errors.NewClient("Default value `abc` does not match field `pattern`"),
+	},
+	{
+		name:      "ValidNumber",
+		attribute: &dao.Attribute{Type: "Integer", Min: float(0), Max: float(10), Unique: true, Default: "10"},
+	},
+	{
+		name:      "ValidText",
+		attribute: &dao.Attribute{Type: "Text", MinLength: integer(2), MaxLength: integer(2), Pattern: "^[A-Z]+$", Unique: true, Default: "US"},
+	},
+}
+
+func TestValidateConstraints(t *testing.T) {
+	for _, test := range validateConstraintsTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			_, err := Validate(test.attribute)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+var defaultTests = []struct {
+	name      string
+	attribute *dao.Attribute
+	want      interface{}
+	wantOK    bool
+}{
+	{
+		name:      "NoDefault",
+		attribute: &dao.Attribute{Type: "Text"},
+	},
+	{
+		name:      "Integer",
+		attribute: &dao.Attribute{Type: "Integer", Default: "-3"},
+		want:      int64(-3),
+		wantOK:    true,
+	},
+	{
+		name:      "Float",
+		attribute: &dao.Attribute{Type: "Float", Default: "2.5"},
+		want:      2.5,
+		wantOK:    true,
+	},
+	{
+		name:      "Boolean",
+		attribute: &dao.Attribute{Type: "Boolean", Default: "true"},
+		want:      true,
+		wantOK:    true,
+	},
+	{
+		name:      "Text",
+		attribute: &dao.Attribute{Type: "Text", Default: "hello"},
+		want:      "hello",
+		wantOK:    true,
+	},
+}
+
+func TestDefault(t *testing.T) {
+	for _, test := range defaultTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			typ, err := Lookup(test.attribute.Type)
+			if err != nil {
+				t.Fatalf("Got error looking up type: %v", err)
+			}
+
+			// Execute
+			got, ok := typ.Default(test.attribute)
+
+			// Verify
+			if ok != test.wantOK || (ok && !reflect.DeepEqual(got, test.want)) {
+				t.Errorf("Got %v, %v; want %v, %v", got, ok, test.want, test.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// findAttribute returns the attribute of the given object with the given code name, or nil if there is none.
+func findAttribute(object *dao.Object, codeName string) *dao.Attribute {
+	for _, attribute := range object.Attributes {
+		if attribute != nil && attribute.CodeName == codeName {
+			return attribute
+		}
+	}
+	return nil
+}
+
+// belongsTo returns true if the given attribute is a BelongsTo attribute that references the given object.
+func belongsTo(attribute *dao.Attribute, objectID string) bool {
+	return attribute != nil && attribute.Type == "BelongsTo" && attribute.Target == objectID
+}
+
+// ValidateRelationships checks that the relationship attributes of the given object reference objects in the
+// given map of object IDs to objects, which should contain the object itself. The Via attribute of a HasMany
+// attribute must be a BelongsTo attribute of the target object that references the given object. The Via
+// attribute of a ManyToMany attribute must be a BelongsTo attribute of the through object that references the
+// given object, and the through object must also belong to the target object.
+func ValidateRelationships(objects map[string]*dao.Object, object *dao.Object) error {
+	if object == nil {
+		return errors.NewServer("Object cannot be nil")
+	}
+
+	for _, attribute := range object.Attributes {
+		t, err := Validate(attribute)
+		if err != nil {
+			return err
+		}
+		if t.Relation == None {
+			continue
+		}
+
+		if objects[attribute.Target] == nil {
+			return errors.NewClient(fmt.Sprintf("Target object `%s` of attribute `%s` does not exist", attribute.Target, attribute.Name))
+		}
+		if t.Relation == HasMany && !belongsTo(findAttribute(objects[attribute.Target], attribute.Via), object.ID) {
+			return errors.NewClient(fmt.Sprintf("Via attribute `%s` of attribute `%s` must be a BelongsTo attribute of object `%s` that references object `%s`",
+				attribute.Via, attribute.Name, attribute.Target, object.ID))
+		}
+		if t.Relation != ManyToMany {
+			continue
+		}
+
+		through := objects[attribute.Through]
+		if through == nil {
+			return errors.NewClient(fmt.Sprintf("Through object `%s` of attribute `%s` does not exist", attribute.Through, attribute.Name))
+		}
+		if !belongsTo(findAttribute(through, attribute.Via), object.ID) {
+			return errors.NewClient(fmt.Sprintf("Via attribute `%s` of attribute `%s` must be a BelongsTo attribute of object `%s` that references object `%s`",
+				attribute.Via, attribute.Name, attribute.Through, object.ID))
+		}
+		if referencing(through, attribute.Target) == nil {
+			return errors.NewClient(fmt.Sprintf("Through object `%s` of attribute `%s` must have a BelongsTo attribute that references object `%s`",
+				attribute.Through, attribute.Name, attribute.Target))
+		}
+	}
+	return nil
+}
+
+// referencing returns the first BelongsTo attribute of the given object that references the object with
+// the given ID, or nil if there is none.
+func referencing(object *dao.Object, objectID string) *dao.Attribute {
+	for _, attribute := range object.Attributes {
+		if belongsTo(attribute, objectID) {
+			return attribute
+		}
+	}
+	return nil
+}
+
+// ReferencedBy returns the first attribute of an object other than the one with the given ID that names it as
+// its target or through object, along with the object that has the attribute. Objects are searched in order
+// of ID. If the object is not referenced, both return values are nil.
+func ReferencedBy(objects map[string]*dao.Object, objectID string) (*dao.Object, *dao.Attribute) {
+	ids := make([]string, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		object := objects[id]
+		if id == objectID || object == nil {
+			continue
+		}
+		if attribute := References(object, objectID); attribute != nil {
+			return object, attribute
+		}
+	}
+	return nil, nil
+}
+
+// References returns the first attribute of the given object that names the object with the given ID as its
+// target or through object, or nil if there is none.
+func References(object *dao.Object, objectID string) *dao.Attribute {
+	for _, attribute := range object.Attributes {
+		if attribute != nil && (attribute.Target == objectID || attribute.Through == objectID) {
+			return attribute
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+var author = &dao.Object{ID: "author", Name: "Author", CodeName: "Author", Attributes: []*dao.Attribute{
+	{Name: "name", CodeName: "name", Type: "Text"},
+	{Name: "books", CodeName: "books", Type: "ManyToMany", Target: "book", Via: "author", Through: "authorship"},
+	{Name: "reviews", CodeName: "reviews", Type: "HasMany", Target: "review", Via: "author"},
+}}
+var book = &dao.Object{ID: "book", Name: "Book", CodeName: "Book", Attributes: []*dao.Attribute{
+	{Name: "title", CodeName: "title", Type: "Text"},
+}}
+var authorship = &dao.Object{ID: "authorship", Name: "Authorship", CodeName: "Authorship", Attributes: []*dao.Attribute{
+	{Name: "author", CodeName: "author", Type: "BelongsTo", Target: "author"},
+	{Name: "book", CodeName: "book", Type: "BelongsTo", Target: "book"},
+}}
+var review = &dao.Object{ID: "review", Name: "Review", CodeName: "Review", Attributes: []*dao.Attribute{
+	{Name: "author", CodeName: "author", Type: "BelongsTo", Target: "author"},
+	{Name: "parent", CodeName: "parent", Type: "BelongsTo", Target: "review"},
+}}
+
+var objects = map[string]*dao.Object{"author": author, "book": book, "authorship": authorship, "review": review}
+
+// withAttribute returns a copy of the author object whose only attribute is the given attribute.
+func withAttribute(attribute *dao.Attribute) *dao.Object {
+	return &dao.Object{ID: "author", Name: "Author", CodeName: "Author", Attributes: []*dao.Attribute{attribute}}
+}
+
+var validateRelationshipsTests = []struct {
+	name    string
+	object  *dao.Object
+	wantErr error
+}{
+	{
+		name:    "NilObject",
+		wantErr: errors.NewServer("Object cannot be nil"),
+	},
+	{
+		name:    "InvalidAttribute",
+		object:  withAttribute(&dao.Attribute{Name: "books", Type: "HasMany", Target: "book"}),
+		wantErr: errors.NewClient("Attribute type `HasMany` requires a `via` field"),
+	},
+	{
+		name:    "MissingTarget",
+		object:  withAttribute(&dao.Attribute{Name: "publisher", Type: "BelongsTo", Target: "publisher"}),
+		wantErr: errors.NewClient("Target object `publisher` of attribute `publisher` does not exist"),
+	},
+	{
+		name:    "HasManyMissingVia",
+		object:  withAttribute(&dao.Attribute{Name: "reviews", Type: "HasMany", Target: "review", Via: "writer"}),
+		wantErr: errors.NewClient("Via attribute `writer` of attribute `reviews` must be a BelongsTo attribute of object `review` that references object `author`"),
+	},
+	{
+		name:    "HasManyViaReferencesOtherObject",
+		object:  withAttribute(&dao.Attribute{Name: "reviews", Type: "HasMany", Target: "review", Via: "parent"}),
+		wantErr: errors.NewClient("Via attribute `parent` of attribute `reviews` must be a BelongsTo attribute of object `review` that references object `author`"),
+	},
+	{
+		name:    "ManyToManyMissingThrough",
+		object:  withAttribute(&dao.Attribute{Name: "books", Type: "ManyToMany", Target: "book", Via: "author", Through: "contract"}),
+		wantErr: errors.NewClient("Through object `contract` of attribute `books` does not exist"),
+	},
+	{
+		name:    "ManyToManyViaNotBelongsTo",
+		object:  withAttribute(&dao.Attribute{Name: "books", Type: "ManyToMany", Target: "book", Via: "book", Through: "authorship"}),
+		wantErr: errors.NewClient("Via attribute `book` of attribute `books` must be a BelongsTo attribute of object `authorship` that references object `author`"),
+	},
+	{
+		name:    "ManyToManyThroughNotLinkedToTarget",
+		object:  withAttribute(&dao.Attribute{Name: "reviews", Type: "ManyToMany", Target: "review", Via: "author", Through: "authorship"}),
+		wantErr: errors.NewClient("Through object `authorship` of attribute `reviews` must have a BelongsTo attribute that references object `review`"),
+	},
+	{
+		name:   "Valid",
+		object: author,
+	},
+	{
+		name:   "SelfReference",
+		object: review,
+	},
+}
+
+func TestValidateRelationships(t *testing.T) {
+	for _, test := range validateRelationshipsTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			err := ValidateRelationships(objects, test.object)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+var referencedByTests = []struct {
+	name          string
+	objectID      string
+	wantObject    *dao.Object
+	wantAttribute string
+}{
+	{
+		name:     "NotReferenced",
+		objectID: "publisher",
+	},
+	{
+		name:          "Target",
+		objectID:      "book",
+		wantObject:    author,
+		wantAttribute: "books",
+	},
+	{
+		name:          "Through",
+		objectID:      "authorship",
+		wantObject:    author,
+		wantAttribute: "books",
+	},
+}
+
+func TestReferencedBy(t *testing.T) {
+	for _, test := range referencedByTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			object, attribute := ReferencedBy(objects, test.objectID)
+
+			// Verify
+			if object != test.wantObject {
+				t.Errorf("Got object %v; want %v", object, test.wantObject)
+			}
+			if (attribute == nil && test.wantAttribute != "") || (attribute != nil && attribute.Name != test.wantAttribute) {
+				t.Errorf("Got attribute %v; want %s", attribute, test.wantAttribute)
+			}
+		})
+	}
+}
+
+func TestReferencedBySelf(t *testing.T) {
+	// Execute
+	object, attribute := ReferencedBy(map[string]*dao.Object{"review": review}, "review")
+
+	// Verify
+	if object != nil || attribute != nil {
+		t.Errorf("Got object %v and attribute %v; want nil", object, attribute)
+	}
+}
@@ -0,0 +1,494 @@
+// Package types is the catalog of attribute types. Every attribute type that users can choose is defined
+// here along with its representation in each code generation target, so that validation and code
+// generation always agree on which types exist and what they mean.
+package types
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// Type describes an attribute type and how it is represented by each code generation target.
+type Type struct {
+	// Name is the value of dao.Attribute.Type that selects the type.
+	Name string
+
+	// HasValues is true if attributes of the type must list their allowed values in dao.Attribute.Values.
+	HasValues bool
+
+	// Relation is the kind of relationship between objects that attributes of the type describe.
+	Relation Relation
+
+	// Constraints lists the validation constraints that attributes of the type accept.
+	Constraints Constraints
+
+	// parse converts a default value of the type from its string form. It returns false if the value is
+	// not valid for the type. It is nil if the type does not accept default values.
+	parse func(string) (interface{}, bool)
+
+	Sails    Sails
+	Mongoose Mongoose
+	Go       Go
+	SQL      SQL
+	OpenAPI  OpenAPI
+}
+
+// Relation is a kind of relationship between objects.
+type Relation int
+
+// The kinds of relationships. Attributes of a relationship type name the related object in dao.Attribute.Target.
+const (
+	// None is the relation of types that store a value instead of referencing another object.
+	None Relation = iota
+
+	// BelongsTo attributes store the ID of a record of the target object.
+	BelongsTo
+
+	// HasMany attributes list the records of the target object whose BelongsTo attribute named by
+	// dao.Attribute.Via references the record.
+	HasMany
+
+	// ManyToMany attributes list the records of the target object that are linked to the record by records of
+	// the object named by dao.Attribute.Through. The Via attribute of the through object references the record.
+	ManyToMany
+)
+
+// Collection returns true if attributes of the type list related records instead of storing a value. Collections
+// are derived from the BelongsTo attributes of other objects, so they do not have a column or field of their own.
+func (t *Type) Collection() bool {
+	return t.Relation == HasMany || t.Relation == ManyToMany
+}
+
+// Constraints lists the validation constraints that attributes of a type accept.
+type Constraints struct {
+	// Range allows the Min and Max fields.
+	Range bool
+
+	// Length allows the MinLength and MaxLength fields.
+	Length bool
+
+	// Pattern allows the Pattern field.
+	Pattern bool
+
+	// Unique allows the Unique field.
+	Unique bool
+
+	// Default allows the Default field.
+	Default bool
+}
+
+// Sails describes the representation of a type in a Sails (Waterline) model.
+type Sails struct {
+	// Type is the Waterline attribute type.
+	Type string
+
+	// Rule is an optional Waterline validation rule, such as `isEmail: true`.
+	Rule string
+}
+
+// Mongoose describes the representation of a type in a Mongoose schema.
+type Mongoose struct {
+	// Type is the Mongoose schema type.
+	Type string
+
+	// Match is an optional regular expression literal that string values must match.
+	Match string
+}
+
+// Go describes the representation of a type in the generated Go module.
+type Go struct {
+	// Type is the type of the struct field.
+	Type string
+
+	// Empty is a format string that, given the field, returns an expression that is true when the field was
+	// not set. It is empty if every value of the type is valid, in which case required attributes are not checked.
+	Empty string
+
+	// Check is the optional name of the generated function that returns true if a value is valid.
+	Check string
+
+	// Message completes the sentence "Attribute `x` must be ..." when Check fails.
+	Message string
+
+	// SQLType is the type of the column in the tables created by the generated module.
+	SQLType string
+
+	// SQLDefault is the default value of the column in the tables created by the generated module.
+	SQLDefault string
+}
+
+// Column describes the column type and default value of a type in a SQL database.
+type Column struct {
+	// Type is the column type.
+	Type string
+
+	// Default is the value given to existing rows when a NOT NULL column of the type is added to a table.
+	Default string
+}
+
+// SQL describes the representation of a type in each SQL dialect.
+type SQL struct {
+	Postgres Column
+	MySQL    Column
+	SQLite   Column
+}
+
+// OpenAPI describes the representation of a type in an OpenAPI schema.
+type OpenAPI struct {
+	// Type is the schema type. It is empty if values of any type are allowed.
+	Type string
+
+	// Format is the optional format of the schema.
+	Format string
+}
+
+// Regular expressions shared by the targets that validate formats with a pattern.
+const (
+	DatePattern     = `^\d{4}-\d{2}-\d{2}$`
+	DateTimePattern = `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`
+	EmailPattern    = `^[^\s@]+@[^\s@]+\.[^\s@]+$`
+	URLPattern      = `^https?:\/\/\S+$`
+	UUIDPattern     = `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+)
+
+// catalog contains every attribute type, keyed by name.
+var catalog = map[string]*Type{
+	"Text": {
+		Name:        "Text",
+		Constraints: Constraints{Length: true, Pattern: true, Unique: true, Default: true},
+		parse:       parseString,
+		Sails:       Sails{Type: "string"},
+		Mongoose:    Mongoose{Type: "String"},
+		Go:          Go{Type: "string", Empty: `%s == ""`, SQLType: "TEXT", SQLDefault: "''"},
+		SQL: SQL{
+			Postgres: Column{Type: "TEXT", Default: "''"},
+			MySQL:    Column{Type: "TEXT", Default: "('')"},
+			SQLite:   Column{Type: "TEXT", Default: "''"},
+		},
+		OpenAPI: OpenAPI{Type: "string"},
+	},
+	"Integer": {
+		Name:        "Integer",
+		Constraints: Constraints{Range: true, Unique: true, Default: true},
+		parse:       parseInteger,
+		Sails:       Sails{Type: "number"},
+		Mongoose:    Mongoose{Type: "Number"},
+		Go:          Go{Type: "int64", Empty: "%s == 0", SQLType: "BIGINT", SQLDefault: "0"},
+		SQL: SQL{
+			Postgres: Column{Type: "BIGINT", Default: "0"},
+			MySQL:    Column{Type: "BIGINT", Default: "0"},
+			SQLite:   Column{Type: "INTEGER", Default: "0"},
+		},
+		OpenAPI: OpenAPI{Type: "integer", Format: "int64"},
+	},
+	"Boolean": {
+		Name:        "Boolean",
+		Constraints: Constraints{Default: true},
+		parse:       parseBoolean,
+		Sails:       Sails{Type: "boolean"},
+		Mongoose:    Mongoose{Type: "Boolean"},
+		Go:          Go{Type: "bool", SQLType: "BOOLEAN", SQLDefault: "FALSE"},
+		SQL: SQL{
+			Postgres: Column{Type: "BOOLEAN", Default: "FALSE"},
+			MySQL:    Column{Type: "BOOLEAN", Default: "FALSE"},
+			SQLite:   Column{Type: "INTEGER", Default: "0"},
+		},
+		OpenAPI: OpenAPI{Type: "boolean"},
+	},
+	"Float": {
+		Name:        "Float",
+		Constraints: Constraints{Range: true, Unique: true, Default: true},
+		parse:       parseFloat,
+		Sails:       Sails{Type: "number"},
+		Mongoose:    Mongoose{Type: "Number"},
+		Go:          Go{Type: "float64", Empty: "%s == 0", SQLType: "DOUBLE PRECISION", SQLDefault: "0"},
+		SQL: SQL{
+			Postgres: Column{Type: "DOUBLE PRECISION", Default: "0"},
+			MySQL:    Column{Type: "DOUBLE", Default: "0"},
+			SQLite:   Column{Type: "REAL", Default: "0"},
+		},
+		OpenAPI: OpenAPI{Type: "number", Format: "double"},
+	},
+	"Decimal": {
+		Name:        "Decimal",
+		Constraints: Constraints{Range: true, Unique: true, Default: true},
+		parse:       parseFloat,
+		Sails:       Sails{Type: "number"},
+		Mongoose:    Mongoose{Type: "Number"},
+		Go:          Go{Type: "float64", Empty: "%s == 0", SQLType: "NUMERIC", SQLDefault: "0"},
+		SQL: SQL{
+			Postgres: Column{Type: "NUMERIC", Default: "0"},
+			MySQL:    Column{Type: "DECIMAL(30, 10)", Default: "0"},
+			SQLite:   Column{Type: "NUMERIC", Default: "0"},
+		},
+		OpenAPI: OpenAPI{Type: "number"},
+	},
+	"Date": {
+		Name:        "Date",
+		Constraints: Constraints{Unique: true, Default: true},
+		parse:       parseTime("2006-01-02"),
+		Sails:       Sails{Type: "string", Rule: "regex: /" + DatePattern + "/"},
+		Mongoose:    Mongoose{Type: "Date"},
+		Go:          Go{Type: "string", Empty: `%s == ""`, Check: "validDate", Message: "a date in the format YYYY-MM-DD", SQLType: "TEXT", SQLDefault: "''"},
+		SQL: SQL{
+			Postgres: Column{Type: "DATE", Default: "'1970-01-01'"},
+			MySQL:    Column{Type: "DATE", Default: "'1970-01-01'"},
+			SQLite:   Column{Type: "TEXT", Default: "'1970-01-01'"},
+		},
+		OpenAPI: OpenAPI{Type: "string", Format: "date"},
+	},
+	"DateTime": {
+		Name:        "DateTime",
+		Constraints: Constraints{Unique: true, Default: true},
+		parse:       parseTime(time.RFC3339),
+		Sails:       Sails{Type: "string", Rule: "regex: /" + DateTimePattern + "/"},
+		Mongoose:    Mongoose{Type: "Date"},
+		Go:          Go{Type: "string", Empty: `%s == ""`, Check: "validDateTime", Message: "a date and time in RFC 3339 format", SQLType: "TEXT", SQLDefault: "''"},
+		SQL: SQL{
+			Postgres: Column{Type: "TIMESTAMP WITH TIME ZONE", Default: "'1970-01-01 00:00:00+00'"},
+			MySQL:    Column{Type: "DATETIME", Default: "'1970-01-01 00:00:00'"},
+			SQLite:   Column{Type: "TEXT", Default: "'1970-01-01T00:00:00Z'"},
+		},
+		OpenAPI: OpenAPI{Type: "string", Format: "date-time"},
+	},
+	"Email": {
+		Name:        "Email",
+		Constraints: Constraints{Length: true, Unique: true, Default: true},
+		parse:       parseMatch(EmailPattern),
+		Sails:       Sails{Type: "string", Rule: "isEmail: true"},
+		Mongoose:    Mongoose{Type: "String", Match: "/" + EmailPattern + "/"},
+		Go:          Go{Type: "string", Empty: `%s == ""`, Check: "validEmail", Message: "a valid email address", SQLType: "TEXT", SQLDefault: "''"},
+		SQL: SQL{
+			Postgres: Column{Type: "TEXT", Default: "''"},
+			MySQL:    Column{Type: "VARCHAR(320)", Default: "''"},
+			SQLite:   Column{Type: "TEXT", Default: "''"},
+		},
+		OpenAPI: OpenAPI{Type: "string", Format: "email"},
+	},
+	"URL": {
+		Name:        "URL",
+		Constraints: Constraints{Length: true, Unique: true, Default: true},
+		parse:       parseMatch(URLPattern),
+		Sails:       Sails{Type: "string", Rule: "isURL: true"},
+		Mongoose:    Mongoose{Type: "String", Match: "/" + URLPattern + "/"},
+		Go:          Go{Type: "string", Empty: `%s == ""`, Check: "validURL", Message: "a valid URL", SQLType: "TEXT", SQLDefault: "''"},
+		SQL: SQL{
+			Postgres: Column{Type: "TEXT", Default: "''"},
+			MySQL:    Column{Type: "TEXT", Default: "('')"},
+			SQLite:   Column{Type: "TEXT", Default: "''"},
+		},
+		OpenAPI: OpenAPI{Type: "string", Format: "uri"},
+	},
+	"UUID": {
+		Name:        "UUID",
+		Constraints: Constraints{Unique: true, Default: true},
+		parse:       parseMatch(UUIDPattern),
+		Sails:       Sails{Type: "string", Rule: "isUUID: true"},
+		Mongoose:    Mongoose{Type: "String", Match: "/" + UUIDPattern + "/"},
+		Go:          Go{Type: "string", Empty: `%s == ""`, Check: "validUUID", Message: "a valid UUID", SQLType: "TEXT", SQLDefault: "''"},
+		SQL: SQL{
+			Postgres: Column{Type: "UUID", Default: "'00000000-0000-0000-0000-000000000000'"},
+			MySQL:    Column{Type: "CHAR(36)", Default: "'00000000-0000-0000-0000-000000000000'"},
+			SQLite:   Column{Type: "TEXT", Default: "'00000000-0000-0000-0000-000000000000'"},
+		},
+		OpenAPI: OpenAPI{Type: "string", Format: "uuid"},
+	},
+	"JSON": {
+		Name:     "JSON",
+		Sails:    Sails{Type: "json"},
+		Mongoose: Mongoose{Type: "mongoose.Schema.Types.Mixed"},
+		Go:       Go{Type: "jsonValue", Empty: "len(%s) == 0", SQLType: "TEXT", SQLDefault: "'null'"},
+		SQL: SQL{
+			Postgres: Column{Type: "JSONB", Default: "'null'"},
+			MySQL:    Column{Type: "JSON", Default: "(CAST('null' AS JSON))"},
+			SQLite:   Column{Type: "TEXT", Default: "'null'"},
+		},
+	},
+	"BelongsTo": {
+		Name:        "BelongsTo",
+		Constraints: Constraints{Unique: true},
+		Relation:    BelongsTo,
+		Mongoose:    Mongoose{Type: "mongoose.Schema.Types.ObjectId"},
+		Go:          Go{Type: "int64", Empty: "%s == 0", SQLType: "BIGINT", SQLDefault: "0"},
+		SQL: SQL{
+			Postgres: Column{Type: "BIGINT", Default: "0"},
+			MySQL:    Column{Type: "BIGINT", Default: "0"},
+			SQLite:   Column{Type: "INTEGER", Default: "0"},
+		},
+		OpenAPI: OpenAPI{Type: "integer", Format: "int64"},
+	},
+	"HasMany": {
+		Name:     "HasMany",
+		Relation: HasMany,
+	},
+	"ManyToMany": {
+		Name:     "ManyToMany",
+		Relation: ManyToMany,
+	},
+	"Enum": {
+		Name:        "Enum",
+		Constraints: Constraints{Unique: true, Default: true},
+		parse:       parseString,
+		HasValues:   true,
+		Sails:       Sails{Type: "string"},
+		Mongoose:    Mongoose{Type: "String"},
+		Go:          Go{Type: "string", Empty: `%s == ""`, SQLType: "TEXT", SQLDefault: "''"},
+		SQL: SQL{
+			Postgres: Column{Type: "TEXT"},
+			MySQL:    Column{Type: "VARCHAR(255)"},
+			SQLite:   Column{Type: "TEXT"},
+		},
+		OpenAPI: OpenAPI{Type: "string"},
+	},
+}
+
+// parseString accepts any default value as a string.
+func parseString(value string) (interface{}, bool) {
+	return value, true
+}
+
+// parseInteger converts a default value to an int64.
+func parseInteger(value string) (interface{}, bool) {
+	i, err := strconv.ParseInt(value, 10, 64)
+	return i, err == nil
+}
+
+// parseFloat converts a default value to a float64.
+func parseFloat(value string) (interface{}, bool) {
+	f, err := strconv.ParseFloat(value, 64)
+	return f, err == nil && !math.IsInf(f, 0) && !math.IsNaN(f)
+}
+
+// parseBoolean converts a default value of `true` or `false` to a bool.
+func parseBoolean(value string) (interface{}, bool) {
+	return value == "true", value == "true" || value == "false"
+}
+
+// parseTime returns a function that accepts default values that are times in the given layout.
+func parseTime(layout string) func(string) (interface{}, bool) {
+	return func(value string) (interface{}, bool) {
+		_, err := time.Parse(layout, value)
+		return value, err == nil
+	}
+}
+
+// parseMatch returns a function that accepts default values that match the given regular expression.
+func parseMatch(pattern string) func(string) (interface{}, bool) {
+	match := regexp.MustCompile(pattern).MatchString
+	return func(value string) (interface{}, bool) {
+		return value, match(value)
+	}
+}
+
+// Lookup returns the type with the given name. If the type does not exist, a client error listing the
+// supported types is returned.
+func Lookup(name string) (*Type, error) {
+	t, ok := catalog[name]
+	if !ok {
+		return nil, errors.NewClient(fmt.Sprintf("Attribute type `%s` is not supported. Supported types are: %s", name, strings.Join(Names(), ", ")))
+	}
+	return t, nil
+}
+
+// Names returns the names of every type in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(catalog))
+	for name := range catalog {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validValue matches the allowed values of Enum attributes. Values are restricted so that they can be
+// written as string literals in every target without escaping.
+var validValue = regexp.MustCompile(`^[A-Za-z0-9_\- ]+$`).MatchString
+
+// Validate checks that the type of the given attribute exists, that the attribute lists allowed values if
+// and only if its type requires them, that it names the related objects its relation requires and that its
+// constraints are accepted by its type and consistent with each other. Allowed values must be unique,
+// non-empty and may only contain letters, digits, spaces, underscores and hyphens. The type of the
+// attribute is returned.
+func Validate(attribute *dao.Attribute) (*Type, error) {
+	if attribute == nil {
+		return nil, errors.NewClient("Attribute cannot be nil")
+	}
+
+	t, err := Lookup(attribute.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateRelation(t, attribute)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateConstraints(t, attribute)
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.HasValues {
+		if len(attribute.Values) > 0 {
+			return nil, errors.NewClient(fmt.Sprintf("Attribute type `%s` does not accept allowed values", t.Name))
+		}
+		return t, nil
+	}
+
+	if len(attribute.Values) == 0 {
+		return nil, errors.NewClient(fmt.Sprintf("Attribute type `%s` requires a list of allowed values", t.Name))
+	}
+	seen := make(map[string]bool, len(attribute.Values))
+	for _, value := range attribute.Values {
+		if !validValue(value) {
+			return nil, errors.NewClient(fmt.Sprintf("Allowed value `%s` may only contain letters, digits, spaces, underscores and hyphens", value))
+		}
+		if seen[value] {
+			return nil, errors.NewClient(fmt.Sprintf("Allowed value `%s` is listed more than once", value))
+		}
+		seen[value] = true
+	}
+	return t, nil
+}
+
+// validateRelation checks that the given attribute sets the Target, Via and Through fields if and only if its
+// type requires them.
+func validateRelation(t *Type, attribute *dao.Attribute) error {
+	fields := []struct {
+		name     string
+		value    string
+		required bool
+	}{
+		{"target", attribute.Target, t.Relation != None},
+		{"via", attribute.Via, t.Collection()},
+		{"through", attribute.Through, t.Relation == ManyToMany},
+	}
+	for _, field := range fields {
+		if field.required && field.value == "" {
+			return errors.NewClient(fmt.Sprintf("Attribute type `%s` requires a `%s` field", t.Name, field.name))
+		}
+		if !field.required && field.value != "" {
+			return errors.NewClient(fmt.Sprintf("Attribute type `%s` does not accept a `%s` field", t.Name, field.name))
+		}
+	}
+	return nil
+}
+
+// Quote returns the given allowed values as a comma-separated list of string literals enclosed in the given
+// quote character. Allowed values are validated to not need escaping.
+func Quote(values []string, quote string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quote + value + quote
+	}
+	return strings.Join(quoted, ", ")
+}
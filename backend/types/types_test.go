@@ -0,0 +1,195 @@
+package types
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+var validateTests = []struct {
+	name      string
+	attribute *dao.Attribute
+	wantType  string
+	wantErr   error
+}{
+	{
+		name:    "NilAttribute",
+		wantErr: errors.NewClient("Attribute cannot be nil"),
+	},
+	{
+		name:      "UnsupportedType",
+		attribute: &dao.Attribute{Type: "Money"},
+		wantErr: errors.NewClient("Attribute type `Money` is not supported. Supported types are: " +
+			"BelongsTo, Boolean, Date, DateTime, Decimal, Email, Enum, Float, HasMany, Integer, JSON, ManyToMany, Text, URL, UUID"),
+	},
+	{
+		name:      "ValuesOnPlainType",
+		attribute: &dao.Attribute{Type: "Integer", Values: []string{"1"}},
+		wantErr:   errors.NewClient("Attribute type `Integer` does not accept allowed values"),
+	},
+	{
+		name:      "PlainType",
+		attribute: &dao.Attribute{Type: "Email"},
+		wantType:  "Email",
+	},
+	{
+		name:      "EnumWithoutValues",
+		attribute: &dao.Attribute{Type: "Enum"},
+		wantErr:   errors.NewClient("Attribute type `Enum` requires a list of allowed values"),
+	},
+	{
+		name:      "EnumInvalidValue",
+		attribute: &dao.Attribute{Type: "Enum", Values: []string{"draft", "it's"}},
+		wantErr:   errors.NewClient("Allowed value `it's` may only contain letters, digits, spaces, underscores and hyphens"),
+	},
+	{
+		name:      "EnumEmptyValue",
+		attribute: &dao.Attribute{Type: "Enum", Values: []string{""}},
+		wantErr:   errors.NewClient("Allowed value `` may only contain letters, digits, spaces, underscores and hyphens"),
+	},
+	{
+		name:      "EnumDuplicateValue",
+		attribute: &dao.Attribute{Type: "Enum", Values: []string{"draft", "draft"}},
+		wantErr:   errors.NewClient("Allowed value `draft` is listed more than once"),
+	},
+	{
+		name:      "Enum",
+		attribute: &dao.Attribute{Type: "Enum", Values: []string{"draft", "in-review", "Published 2"}},
+		wantType:  "Enum",
+	},
+	{
+		name:      "TargetOnPlainType",
+		attribute: &dao.Attribute{Type: "Text", Target: "author"},
+		wantErr:   errors.NewClient("Attribute type `Text` does not accept a `target` field"),
+	},
+	{
+		name:      "BelongsToWithoutTarget",
+		attribute: &dao.Attribute{Type: "BelongsTo"},
+		wantErr:   errors.NewClient("Attribute type `BelongsTo` requires a `target` field"),
+	},
+	{
+		name:      "BelongsToWithVia",
+		attribute: &dao.Attribute{Type: "BelongsTo", Target: "author", Via: "books"},
+		wantErr:   errors.NewClient("Attribute type `BelongsTo` does not accept a `via` field"),
+	},
+	{
+		name:      "BelongsTo",
+		attribute: &dao.Attribute{Type: "BelongsTo", Target: "author"},
+		wantType:  "BelongsTo",
+	},
+	{
+		name:      "HasManyWithoutVia",
+		attribute: &dao.Attribute{Type: "HasMany", Target: "book"},
+		wantErr:   errors.NewClient("Attribute type `HasMany` requires a `via` field"),
+	},
+	{
+		name:      "HasManyWithThrough",
+		attribute: &dao.Attribute{Type: "HasMany", Target: "book", Via: "author", Through: "authorship"},
+		wantErr:   errors.NewClient("Attribute type `HasMany` does not accept a `through` field"),
+	},
+	{
+		name:      "ManyToManyWithoutThrough",
+		attribute: &dao.Attribute{Type: "ManyToMany", Target: "book", Via: "author"},
+		wantErr:   errors.NewClient("Attribute type `ManyToMany` requires a `through` field"),
+	},
+	{
+		name:      "ManyToMany",
+		attribute: &dao.Attribute{Type: "ManyToMany", Target: "book", Via: "author", Through: "authorship"},
+		wantType:  "ManyToMany",
+	},
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range validateTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Execute
+			got, err := Validate(test.attribute)
+
+			// Verify
+			if (got == nil && test.wantType != "") || (got != nil && got.Name != test.wantType) {
+				t.Errorf("Got type %v; want %s", got, test.wantType)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// TestCatalogComplete checks that every type is represented in every target, so that a type cannot be added to
+// the catalog without deciding how each generator handles it.
+func TestCatalogComplete(t *testing.T) {
+	for name, typ := range catalog {
+		t.Run(name, func(t *testing.T) {
+			if typ.Name != name {
+				t.Errorf("Got name %s; want %s", typ.Name, name)
+			}
+			// Sails writes associations for every relationship type.
+			if (typ.Sails.Type == "") != (typ.Relation != None) {
+				t.Errorf("Got Sails type %q; want a type if and only if the type is not a relationship", typ.Sails.Type)
+			}
+			// Collections do not have a column or field of their own.
+			if typ.Collection() {
+				if !reflect.DeepEqual(*typ, Type{Name: typ.Name, Relation: typ.Relation}) {
+					t.Errorf("Collection type has a target representation: %+v", typ)
+				}
+				return
+			}
+			if typ.Mongoose.Type == "" || typ.Go.Type == "" || typ.Go.SQLType == "" || typ.Go.SQLDefault == "" {
+				t.Errorf("Type is missing a target representation: %+v", typ)
+			}
+			if typ.Constraints.Default != (typ.parse != nil) {
+				t.Errorf("Got default constraint %v; want it set if and only if the type can parse default values", typ.Constraints.Default)
+			}
+			if (typ.Go.Check == "") != (typ.Go.Message == "") {
+				t.Errorf("Go check %q and message %q must be set together", typ.Go.Check, typ.Go.Message)
+			}
+			for dialect, column := range map[string]Column{"postgres": typ.SQL.Postgres, "mysql": typ.SQL.MySQL, "sqlite": typ.SQL.SQLite} {
+				if column.Type == "" {
+					t.Errorf("Type has no %s column type", dialect)
+				}
+				// The default value of types with allowed values is their first allowed value.
+				if (column.Default == "") != typ.HasValues {
+					t.Errorf("Got %s default %q; want a default if and only if the type has no allowed values", dialect, column.Default)
+				}
+			}
+		})
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		valid   []string
+		invalid []string
+	}{
+		{DatePattern, []string{"2020-05-01"}, []string{"2020-5-1", "2020-05-01T00:00:00Z"}},
+		{DateTimePattern, []string{"2020-05-01T12:30:00Z", "2020-05-01T12:30:00.123+02:00"}, []string{"2020-05-01", "2020-05-01 12:30:00"}},
+		{EmailPattern, []string{"test@example.com"}, []string{"test", "test@example", "a b@example.com"}},
+		{URLPattern, []string{"https://example.com/path", "http://localhost:8080"}, []string{"example.com", "ftp://example.com"}},
+		{UUIDPattern, []string{"123e4567-e89b-12d3-a456-426614174000"}, []string{"123e4567e89b12d3a456426614174000"}},
+	}
+	for _, test := range tests {
+		re := regexp.MustCompile(test.pattern)
+		for _, value := range test.valid {
+			if !re.MatchString(value) {
+				t.Errorf("Pattern %s does not match %q", test.pattern, value)
+			}
+		}
+		for _, value := range test.invalid {
+			if re.MatchString(value) {
+				t.Errorf("Pattern %s matches %q", test.pattern, value)
+			}
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	got := Quote([]string{"draft", "published"}, "'")
+	if want := "'draft', 'published'"; got != want {
+		t.Errorf("Got %s; want %s", got, want)
+	}
+}
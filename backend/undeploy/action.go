@@ -0,0 +1,66 @@
+package undeploy
+
+import (
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/codegen/s3"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// undeployDatabase wraps the database functions used by the undeployProject action in order to allow dependency injection.
+type undeployDatabase interface {
+	auth.CredentialStore
+	GetProject(string, string) (*dao.Project, error)
+	UpdateDeployment(string, string, *dao.Deployment) error
+}
+
+// deletePrefix points to the function used to delete the project's generated artifacts from S3. It
+// should not be changed except for dependency injection within unit tests.
+var deletePrefix = s3.DeletePrefix
+
+// undeployProject terminates the instance that runs the given project and clears the deployment of the project.
+// The instance is terminated by the deployment provider that launched it, which is found with providers. Projects
+// that are not deployed are left unchanged, so undeploying a project more than once is not an error. If
+// deleteArtifacts is true, the generated code of the project is also deleted from S3.
+func undeployProject(cookie string, projectID string, deleteArtifacts bool, verifyCookie auth.VerifyCookieFunc, db undeployDatabase, providers provider.LookupFunc) error {
+	if projectID == "" {
+		return errors.NewClient("Parameter `pid` is required")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return errors.Wrap(err, "Failed to verify cookie")
+	}
+
+	project, err := db.GetProject(email, projectID)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get project")
+	}
+
+	if project.InstanceID != "" {
+		log.Info("Terminating instance with id:", project.InstanceID)
+		deployer, err := providers(provider.NameOf(&project.Deployment))
+		if err != nil {
+			return errors.Wrap(err, "Failed to get deployment provider")
+		}
+		err = deployer.Terminate(project.InstanceID)
+		if err != nil {
+			return errors.Wrap(err, "Failed to terminate instance")
+		}
+	}
+
+	if project.Deployment != (dao.Deployment{}) {
+		err = db.UpdateDeployment(email, projectID, &dao.Deployment{})
+		if err != nil {
+			return errors.Wrap(err, "Failed to update deployment info")
+		}
+	}
+
+	if deleteArtifacts {
+		err = deletePrefix(s3.ProjectPrefix(email, projectID))
+		return errors.Wrap(err, "Failed to delete generated code")
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package undeploy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	project   *dao.Project
+	getErr    error
+	updateErr error
+	updated   bool
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetProject(email string, projectID string) (*dao.Project, error) {
+	if email != "test@example.com" || projectID != "project" {
+		return nil, errors.NewServer("Incorrect input to GetProject mock")
+	}
+	return mock.project, mock.getErr
+}
+
+func (mock *databaseMock) UpdateDeployment(email string, projectID string, deployment *dao.Deployment) error {
+	if email != "test@example.com" || projectID != "project" || !reflect.DeepEqual(deployment, &dao.Deployment{}) {
+		return errors.NewServer("Incorrect input to UpdateDeployment mock")
+	}
+	mock.updated = true
+	return mock.updateErr
+}
+
+type providerMock struct {
+	name       string
+	lookupErr  error
+	instanceID string
+	err        error
+	terminated bool
+}
+
+func (mock *providerMock) lookup(name string) (provider.Provider, error) {
+	if name != mock.name {
+		return nil, errors.NewServer("Incorrect input to Lookup mock")
+	}
+	if mock.lookupErr != nil {
+		return nil, mock.lookupErr
+	}
+	return mock, nil
+}
+
+func (mock *providerMock) Name() string {
+	return mock.name
+}
+
+func (mock *providerMock) Launch(projectURL string, owner provider.Owner, config *dao.DeployConfig) (*provider.Instance, error) {
+	return nil, errors.NewServer("Unexpected call to Launch mock")
+}
+
+func (mock *providerMock) Describe(instanceID string) (*provider.Instance, error) {
+	return nil, errors.NewServer("Unexpected call to Describe mock")
+}
+
+func (mock *providerMock) Terminate(instanceID string) error {
+	if instanceID != mock.instanceID {
+		return errors.NewServer("Incorrect input to Terminate mock")
+	}
+	mock.terminated = true
+	return mock.err
+}
+
+func (mock *providerMock) List() ([]*provider.Instance, error) {
+	return nil, errors.NewServer("Unexpected call to List mock")
+}
+
+func (mock *providerMock) Logs(instanceID string) (string, error) {
+	return "", errors.NewServer("Unexpected call to Logs mock")
+}
+
+func deletePrefixMock(wantPrefix string, err error) func(string) error {
+	return func(prefix string) error {
+		if prefix != wantPrefix {
+			return errors.NewServer("Incorrect input to DeletePrefix mock")
+		}
+		return err
+	}
+}
+
+func deployedProject(providerName string, instanceID string) *dao.Project {
+	return &dao.Project{
+		ID: "project",
+		Deployment: dao.Deployment{
+			Provider:   providerName,
+			InstanceID: instanceID,
+			URL:        "example.com",
+			Status:     dao.DeployHealthy,
+		},
+	}
+}
+
+var undeployTests = []struct {
+	name string
+
+	// Input
+	projectID       string
+	deleteArtifacts bool
+
+	// Mock data
+	db               *databaseMock
+	deployer         *providerMock
+	verifyErr        error
+	deletePrefixMock func(string) error
+
+	// Expected output
+	wantErr       error
+	wantTerminate bool
+	wantUpdate    bool
+}{
+	{
+		name:    "EmptyProjectID",
+		wantErr: errors.NewClient("Parameter `pid` is required"),
+	},
+	{
+		name:      "InvalidCookie",
+		projectID: "project",
+		verifyErr: errors.NewClient("Not authenticated"),
+		wantErr:   errors.Wrap(errors.NewClient("Not authenticated"), "Failed to verify cookie"),
+	},
+	{
+		name:      "GetProjectFailure",
+		projectID: "project",
+		db:        &databaseMock{getErr: errors.NewClient("Invalid project id")},
+		wantErr:   errors.Wrap(errors.NewClient("Invalid project id"), "Failed to get project"),
+	},
+	{
+		name:      "ProviderLookupFailure",
+		projectID: "project",
+		db:        &databaseMock{project: deployedProject("docker", "container")},
+		deployer:  &providerMock{name: "docker", lookupErr: errors.NewServer("Unsupported deployment provider `docker`")},
+		wantErr:   errors.Wrap(errors.NewServer("Unsupported deployment provider `docker`"), "Failed to get deployment provider"),
+	},
+	{
+		name:          "TerminateFailure",
+		projectID:     "project",
+		db:            &databaseMock{project: deployedProject("", "instance")},
+		deployer:      &providerMock{name: "ec2", instanceID: "instance", err: errors.NewServer("EC2 failure")},
+		wantErr:       errors.Wrap(errors.NewServer("EC2 failure"), "Failed to terminate instance"),
+		wantTerminate: true,
+	},
+	{
+		name:          "UpdateDeploymentFailure",
+		projectID:     "project",
+		db:            &databaseMock{project: deployedProject("ec2", "instance"), updateErr: errors.NewServer("DynamoDB failure")},
+		deployer:      &providerMock{name: "ec2", instanceID: "instance"},
+		wantErr:       errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to update deployment info"),
+		wantTerminate: true,
+		wantUpdate:    true,
+	},
+	{
+		name:             "DeletePrefixFailure",
+		projectID:        "project",
+		deleteArtifacts:  true,
+		db:               &databaseMock{project: deployedProject("ec2", "instance")},
+		deployer:         &providerMock{name: "ec2", instanceID: "instance"},
+		deletePrefixMock: deletePrefixMock("test@example.com/project/", errors.NewServer("S3 failure")),
+		wantErr:          errors.Wrap(errors.NewServer("S3 failure"), "Failed to delete generated code"),
+		wantTerminate:    true,
+		wantUpdate:       true,
+	},
+	{
+		name:      "NotDeployed",
+		projectID: "project",
+		db:        &databaseMock{project: &dao.Project{ID: "project"}},
+		deployer:  &providerMock{},
+	},
+	{
+		name:             "NotDeployedDeleteArtifacts",
+		projectID:        "project",
+		deleteArtifacts:  true,
+		db:               &databaseMock{project: &dao.Project{ID: "project"}},
+		deployer:         &providerMock{},
+		deletePrefixMock: deletePrefixMock("test@example.com/project/", nil),
+	},
+	{
+		name:       "FailedLaunch",
+		projectID:  "project",
+		db:         &databaseMock{project: &dao.Project{ID: "project", Deployment: dao.Deployment{Provider: "ec2", Status: dao.DeployFailed, Error: "Failed to launch instance"}}},
+		deployer:   &providerMock{},
+		wantUpdate: true,
+	},
+	{
+		name:          "DockerDeployment",
+		projectID:     "project",
+		db:            &databaseMock{project: deployedProject("docker", "container")},
+		deployer:      &providerMock{name: "docker", instanceID: "container"},
+		wantTerminate: true,
+		wantUpdate:    true,
+	},
+	{
+		name:             "SuccessfulInvocation",
+		projectID:        "project",
+		deleteArtifacts:  true,
+		db:               &databaseMock{project: deployedProject("ec2", "instance")},
+		deployer:         &providerMock{name: "ec2", instanceID: "instance"},
+		deletePrefixMock: deletePrefixMock("test@example.com/project/", nil),
+		wantTerminate:    true,
+		wantUpdate:       true,
+	},
+}
+
+func TestUndeployProject(t *testing.T) {
+	for _, test := range undeployTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock("cookievalue", test.db, "test@example.com", test.verifyErr)
+			deletePrefix = test.deletePrefixMock
+
+			// Execute
+			err := undeployProject("cookievalue", test.projectID, test.deleteArtifacts, verifyCookie, test.db, test.deployer.lookup)
+
+			// Verify
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+			if test.deployer != nil && test.deployer.terminated != test.wantTerminate {
+				t.Errorf("Got terminate %t; want %t", test.deployer.terminated, test.wantTerminate)
+			}
+			if test.db != nil && test.db.updated != test.wantUpdate {
+				t.Errorf("Got update %t; want %t", test.db.updated, test.wantUpdate)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Package undeploy handles requests to the DELETE /projects/{pid}/deploy REST API endpoint.
+package undeploy
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+
+	// Register the supported deployment providers
+	_ "github.com/jackstenglein/rest_api_creator/backend/deploy/docker"
+	_ "github.com/jackstenglein/rest_api_creator/backend/deploy/ec2"
+)
+
+// undeployResponse contains the fields returned in the API JSON response body.
+type undeployResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+func (response *undeployResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// undeploy points to the function used to perform the undeployProject action. It should
+// not be changed except in unit tests.
+var undeploy = undeployProject
+
+// HandleUndeploy parses the request object from AWS APIGateway and passes it to the undeployProject action.
+// The request must contain a valid cookie or access token and a `pid` path parameter. If the `artifacts` query
+// parameter is `true`, the generated code of the project is deleted along with its deployment. If the request
+// succeeds, the response will have a 200 status and an empty body, even if the project was not deployed. If the
+// request fails, the response body will have an `error` field.
+func HandleUndeploy(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	projectID := request.PathParameters["pid"]
+	cookie := auth.ExtractCredential(request.Headers)
+	deleteArtifacts := request.QueryStringParameters["artifacts"] == "true"
+
+	// Perform the action
+	err := undeploy(cookie, projectID, deleteArtifacts, auth.Verifier(auth.ScopeDeploy), dao.Dynamo, provider.Lookup)
+	log.Error(err)
+
+	// Return the response
+	return http.GatewayResponse(&undeployResponse{}, "", err), nil
+}
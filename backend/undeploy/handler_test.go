@@ -0,0 +1,100 @@
+package undeploy
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/deploy/provider"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type undeployMockFunc func(string, string, bool, auth.VerifyCookieFunc, undeployDatabase, provider.LookupFunc) error
+
+func undeployMock(wantCookie string, wantPID string, wantArtifacts bool, err error) undeployMockFunc {
+	return func(cookie string, pid string, deleteArtifacts bool, _ auth.VerifyCookieFunc, _ undeployDatabase, _ provider.LookupFunc) error {
+		if cookie != wantCookie || pid != wantPID || deleteArtifacts != wantArtifacts {
+			return errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return err
+	}
+}
+
+func handlerRequest(cookie string, pid string, artifacts string) events.APIGatewayProxyRequest {
+	parameters := map[string]string{
+		"pid": pid,
+	}
+	headers := map[string]string{
+		"Cookie": cookie,
+	}
+	query := map[string]string{}
+	if artifacts != "" {
+		query["artifacts"] = artifacts
+	}
+	return events.APIGatewayProxyRequest{PathParameters: parameters, Headers: headers, QueryStringParameters: query}
+}
+
+func handlerResponse(body string, status int) events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		Body: body,
+		Headers: map[string]string{
+			"Access-Control-Allow-Origin":      os.Getenv("CORS_ORIGIN"),
+			"Access-Control-Allow-Credentials": "true",
+		},
+		StatusCode: status,
+	}
+}
+
+var handleUndeployTests = []struct {
+	name string
+
+	request      events.APIGatewayProxyRequest
+	undeployMock undeployMockFunc
+
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:         "UndeployFailure",
+		request:      handlerRequest("session=cookievalue", "projectId", ""),
+		undeployMock: undeployMock("cookievalue", "projectId", false, errors.Wrap(errors.NewServer("EC2 failure"), "Failed to terminate instance")),
+		wantResponse: handlerResponse(`{"error":"Failed to terminate instance"}`, 500),
+	},
+	{
+		name:         "SuccessfulInvocation",
+		request:      handlerRequest("session=cookievalue", "projectId", ""),
+		undeployMock: undeployMock("cookievalue", "projectId", false, nil),
+		wantResponse: handlerResponse("{}", 200),
+	},
+	{
+		name:         "DeleteArtifacts",
+		request:      handlerRequest("session=cookievalue", "projectId", "true"),
+		undeployMock: undeployMock("cookievalue", "projectId", true, nil),
+		wantResponse: handlerResponse("{}", 200),
+	},
+}
+
+func TestHandleUndeploy(t *testing.T) {
+	for _, test := range handleUndeployTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			undeploy = test.undeployMock
+			defer func() {
+				undeploy = undeployProject
+			}()
+
+			// Execute
+			response, err := HandleUndeploy(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
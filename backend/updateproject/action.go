@@ -0,0 +1,50 @@
+package updateproject
+
+import (
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+// updateProjectDatabase wraps the database methods required to perform the updateProject action.
+// This allows for dependency injection of the database.
+type updateProjectDatabase interface {
+	auth.CredentialStore
+	GetProject(string, string) (*dao.Project, error)
+	UpdateProject(string, string, string, string) error
+}
+
+// updateProject changes the name and/or description of the given projectID. Fields that are nil in the request
+// are left unchanged. If the update succeeds, the updated project is returned. Otherwise, updateProject returns
+// a nil project along with the error.
+func updateProject(cookie string, projectID string, request updateProjectRequest, verifyCookie auth.VerifyCookieFunc, db updateProjectDatabase) (*dao.Project, error) {
+	if projectID == "" {
+		return nil, errors.NewClient("Parameter `projectID` is required")
+	}
+	if request.Name != nil && *request.Name == "" {
+		return nil, errors.NewClient("Project name cannot be empty")
+	}
+
+	email, err := verifyCookie(cookie, db)
+	if err != nil {
+		return nil, errors.NewClient("Not authenticated")
+	}
+
+	project, err := db.GetProject(email, projectID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get project")
+	}
+
+	if request.Name != nil {
+		project.Name = *request.Name
+	}
+	if request.Description != nil {
+		project.Description = *request.Description
+	}
+
+	err = db.UpdateProject(email, projectID, project.Name, project.Description)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to update project in database")
+	}
+	return project, nil
+}
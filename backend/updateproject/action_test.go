@@ -0,0 +1,184 @@
+package updateproject
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+func verifyCookieMock(mockCookie string, mockDB auth.CredentialStore, mockEmail string, mockErr error) auth.VerifyCookieFunc {
+	return func(cookie string, db auth.CredentialStore) (string, error) {
+		if cookie != mockCookie || !reflect.DeepEqual(db, mockDB) {
+			return "", errors.NewServer("Incorrect input to VerifyCookie mock")
+		}
+		return mockEmail, mockErr
+	}
+}
+
+type databaseMock struct {
+	email     string
+	projectID string
+
+	// GetProject mock data
+	project       *dao.Project
+	getProjectErr error
+
+	// UpdateProject mock data
+	name             string
+	description      string
+	updateProjectErr error
+}
+
+func (mock *databaseMock) GetSession(id string) (*dao.Session, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchSession(id string, lastSeen time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetAccessToken(id string) (*dao.AccessToken, error) {
+	return nil, nil
+}
+
+func (mock *databaseMock) TouchAccessToken(id string, lastUsed time.Time) error {
+	return nil
+}
+
+func (mock *databaseMock) GetProject(email string, projectID string) (*dao.Project, error) {
+	if email != mock.email || projectID != mock.projectID {
+		return nil, errors.NewServer("Incorrect input to GetProject mock")
+	}
+	return mock.project, mock.getProjectErr
+}
+
+func (mock *databaseMock) UpdateProject(email string, projectID string, name string, description string) error {
+	if email != mock.email || projectID != mock.projectID || name != mock.name || description != mock.description {
+		return errors.NewServer("Incorrect input to UpdateProject mock")
+	}
+	return mock.updateProjectErr
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+var updateProjectTests = []struct {
+	name string
+
+	// Input
+	cookie    string
+	projectID string
+	request   updateProjectRequest
+
+	// Mock data
+	db        *databaseMock
+	email     string
+	verifyErr error
+
+	// Expected output
+	wantProject *dao.Project
+	wantErr     error
+}{
+	{
+		name:    "EmptyProjectID",
+		cookie:  "cookie",
+		wantErr: errors.NewClient("Parameter `projectID` is required"),
+	},
+	{
+		name:      "EmptyName",
+		cookie:    "cookie",
+		projectID: "projectID",
+		request:   updateProjectRequest{Name: stringPtr("")},
+		wantErr:   errors.NewClient("Project name cannot be empty"),
+	},
+	{
+		name:      "InvalidCookie",
+		cookie:    "cookie",
+		projectID: "projectID",
+		verifyErr: errors.NewClient("Invalid cookie"),
+		wantErr:   errors.NewClient("Not authenticated"),
+	},
+	{
+		name:      "GetProjectFailure",
+		cookie:    "cookie",
+		projectID: "projectID",
+		request:   updateProjectRequest{Name: stringPtr("New Name")},
+		db: &databaseMock{
+			email:         "test@example.com",
+			projectID:     "projectID",
+			getProjectErr: errors.NewClient("Invalid project id"),
+		},
+		email:   "test@example.com",
+		wantErr: errors.Wrap(errors.NewClient("Invalid project id"), "Failed to get project"),
+	},
+	{
+		name:      "UpdateProjectFailure",
+		cookie:    "cookie",
+		projectID: "projectID",
+		request:   updateProjectRequest{Name: stringPtr("New Name")},
+		db: &databaseMock{
+			email:            "test@example.com",
+			projectID:        "projectID",
+			project:          &dao.Project{ID: "projectID", Name: "Old Name", Description: "Old description"},
+			name:             "New Name",
+			description:      "Old description",
+			updateProjectErr: errors.NewServer("DynamoDB failure"),
+		},
+		email:   "test@example.com",
+		wantErr: errors.Wrap(errors.NewServer("DynamoDB failure"), "Failed to update project in database"),
+	},
+	{
+		name:      "UpdateName",
+		cookie:    "cookie",
+		projectID: "projectID",
+		request:   updateProjectRequest{Name: stringPtr("New Name")},
+		db: &databaseMock{
+			email:       "test@example.com",
+			projectID:   "projectID",
+			project:     &dao.Project{ID: "projectID", Name: "Old Name", Description: "Old description"},
+			name:        "New Name",
+			description: "Old description",
+		},
+		email:       "test@example.com",
+		wantProject: &dao.Project{ID: "projectID", Name: "New Name", Description: "Old description"},
+	},
+	{
+		name:      "UpdateNameAndDescription",
+		cookie:    "cookie",
+		projectID: "projectID",
+		request:   updateProjectRequest{Name: stringPtr("New Name"), Description: stringPtr("")},
+		db: &databaseMock{
+			email:     "test@example.com",
+			projectID: "projectID",
+			project:   &dao.Project{ID: "projectID", Name: "Old Name", Description: "Old description"},
+			name:      "New Name",
+		},
+		email:       "test@example.com",
+		wantProject: &dao.Project{ID: "projectID", Name: "New Name"},
+	},
+}
+
+func TestUpdateProject(t *testing.T) {
+	for _, test := range updateProjectTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			verifyCookie := verifyCookieMock(test.cookie, test.db, test.email, test.verifyErr)
+
+			// Execute
+			project, err := updateProject(test.cookie, test.projectID, test.request, verifyCookie, test.db)
+
+			// Verify
+			if !reflect.DeepEqual(project, test.wantProject) {
+				t.Errorf("Got project %v; want %v", project, test.wantProject)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got err %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}
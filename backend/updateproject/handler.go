@@ -0,0 +1,55 @@
+// Package updateproject handles requests to the PATCH /projects/{pid} REST API endpoint.
+package updateproject
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/http"
+	"github.com/jackstenglein/rest_api_creator/backend/log"
+)
+
+// updateProjectRequest contains the fields passed in the API JSON request body. Fields that are
+// omitted from the body are left unchanged.
+type updateProjectRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// updateProjectResponse contains the fields returned in the API JSON response body.
+type updateProjectResponse struct {
+	Project *dao.Project `json:"project,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+func (response *updateProjectResponse) SetError(err string) {
+	if response != nil {
+		response.Error = err
+	}
+}
+
+// updateProjectFunc points to the function used to perform the updateProject action. It
+// should not be changed except in unit tests, when performing dependency injection.
+var updateProjectFunc = updateProject
+
+// HandleUpdateProject parses the request object from AWS APIGateway and passes it to the updateProject action.
+// The request must contain a valid cookie or access token and a `pid` path parameter. The body may contain `name` and
+// `description` fields. If the request succeeds, the response will have a 200 status, and the body will have a
+// `project` field containing the updated project. If the request fails, the response will have either a 400 or
+// a 500 status, and the body will have an `error` field detailing what went wrong.
+func HandleUpdateProject(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Get request parameters
+	projectID := request.PathParameters["pid"]
+	cookie := auth.ExtractCredential(request.Headers)
+	var updateRequest updateProjectRequest
+	json.Unmarshal([]byte(request.Body), &updateRequest)
+
+	// Perform the action
+	project, err := updateProjectFunc(cookie, projectID, updateRequest, auth.Verifier(), dao.Dynamo)
+	log.Error(err)
+
+	// Return the response
+	return http.GatewayResponse(&updateProjectResponse{Project: project}, "", err), nil
+}
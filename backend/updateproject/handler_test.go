@@ -0,0 +1,93 @@
+package updateproject
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jackstenglein/rest_api_creator/backend/auth"
+	"github.com/jackstenglein/rest_api_creator/backend/dao"
+	"github.com/jackstenglein/rest_api_creator/backend/errors"
+)
+
+type updateProjectMockFunc func(string, string, updateProjectRequest, auth.VerifyCookieFunc, updateProjectDatabase) (*dao.Project, error)
+
+func updateProjectMock(wantCookie string, wantPID string, wantRequest updateProjectRequest, project *dao.Project, err error) updateProjectMockFunc {
+	return func(cookie string, pid string, request updateProjectRequest, _ auth.VerifyCookieFunc, _ updateProjectDatabase) (*dao.Project, error) {
+		if cookie != wantCookie || pid != wantPID || !reflect.DeepEqual(request, wantRequest) {
+			return nil, errors.NewServer("Incorrect parameters passed to mock")
+		}
+		return project, err
+	}
+}
+
+func handlerRequest(cookie string, pid string, body string) events.APIGatewayProxyRequest {
+	parameters := map[string]string{
+		"pid": pid,
+	}
+	headers := map[string]string{
+		"Cookie": cookie,
+	}
+	return events.APIGatewayProxyRequest{PathParameters: parameters, Headers: headers, Body: body}
+}
+
+func handlerResponse(project *dao.Project, err string, status int) events.APIGatewayProxyResponse {
+	json, _ := json.Marshal(&updateProjectResponse{Project: project, Error: err})
+	return events.APIGatewayProxyResponse{
+		Body:       string(json),
+		Headers:    map[string]string{"Access-Control-Allow-Origin": os.Getenv("CORS_ORIGIN"), "Access-Control-Allow-Credentials": "true"},
+		StatusCode: status,
+	}
+}
+
+var handleUpdateProjectTests = []struct {
+	name string
+
+	// Input
+	request events.APIGatewayProxyRequest
+
+	// Mock data
+	updateProjectMock updateProjectMockFunc
+
+	// Expected output
+	wantResponse events.APIGatewayProxyResponse
+	wantErr      error
+}{
+	{
+		name:              "UpdateProjectFailure",
+		request:           handlerRequest("session=cookievalue", "projectID", `{"name":"New Name"}`),
+		updateProjectMock: updateProjectMock("cookievalue", "projectID", updateProjectRequest{Name: stringPtr("New Name")}, nil, errors.NewClient("Project 'projectID' not found")),
+		wantResponse:      handlerResponse(nil, "Project 'projectID' not found", 400),
+	},
+	{
+		name:              "SuccessfulInvocation",
+		request:           handlerRequest("session=cookievalue", "projectID", `{"description":"desc"}`),
+		updateProjectMock: updateProjectMock("cookievalue", "projectID", updateProjectRequest{Description: stringPtr("desc")}, &dao.Project{ID: "projectID", Name: "Name", Description: "desc"}, nil),
+		wantResponse:      handlerResponse(&dao.Project{ID: "projectID", Name: "Name", Description: "desc"}, "", 200),
+	},
+}
+
+func TestHandleUpdateProject(t *testing.T) {
+	for _, test := range handleUpdateProjectTests {
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			updateProjectFunc = test.updateProjectMock
+			defer func() {
+				updateProjectFunc = updateProject
+			}()
+
+			// Execute
+			response, err := HandleUpdateProject(test.request)
+
+			// Verify
+			if !reflect.DeepEqual(response, test.wantResponse) {
+				t.Errorf("Got response %v; want %v", response, test.wantResponse)
+			}
+			if !errors.Equal(err, test.wantErr) {
+				t.Errorf("Got error %v; want %v", err, test.wantErr)
+			}
+		})
+	}
+}